@@ -0,0 +1,96 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"net"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"golang.org/x/sys/unix"
+
+	"github.com/wmnsk/go-gtp/gtpcap"
+)
+
+// liveSource captures the packets on an interface with AF_PACKET socket.
+type liveSource struct {
+	fd    int
+	ports []int
+	buf   []byte
+}
+
+func openLive(name string, ports []int) (source, error) {
+	ifi, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	proto := htons(unix.ETH_P_ALL)
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW, int(proto))
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Bind(fd, &unix.SockaddrLinklayer{Protocol: proto, Ifindex: ifi.Index}); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+
+	return &liveSource{fd: fd, ports: ports, buf: make([]byte, 65536)}, nil
+}
+
+// Next returns the next GTP packet captured, skipping the others.
+func (s *liveSource) Next() (*gtpcap.Packet, error) {
+	for {
+		n, _, err := unix.Recvfrom(s.fd, s.buf, 0)
+		if err != nil {
+			if err == unix.EINTR {
+				continue
+			}
+			return nil, err
+		}
+
+		pkt := gopacket.NewPacket(s.buf[:n], layers.LayerTypeEthernet, gopacket.DecodeOptions{Lazy: true, NoCopy: true})
+		udp, ok := pkt.Layer(layers.LayerTypeUDP).(*layers.UDP)
+		if !ok || !(s.isGTPPort(int(udp.SrcPort)) || s.isGTPPort(int(udp.DstPort))) {
+			continue
+		}
+
+		var srcIP, dstIP net.IP
+		switch nw := pkt.NetworkLayer().(type) {
+		case *layers.IPv4:
+			srcIP, dstIP = nw.SrcIP, nw.DstIP
+		case *layers.IPv6:
+			srcIP, dstIP = nw.SrcIP, nw.DstIP
+		default:
+			continue
+		}
+
+		return gtpcap.NewPacket(
+			udp.Payload,
+			&net.UDPAddr{IP: copyIP(srcIP), Port: int(udp.SrcPort)},
+			&net.UDPAddr{IP: copyIP(dstIP), Port: int(udp.DstPort)},
+		), nil
+	}
+}
+
+func (s *liveSource) isGTPPort(port int) bool {
+	for _, p := range s.ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
+
+// copyIP copies ip as the buffer is reused for the next packet.
+func copyIP(ip net.IP) net.IP {
+	c := make(net.IP, len(ip))
+	copy(c, ip)
+	return c
+}
+
+func htons(v uint16) uint16 {
+	return v<<8 | v>>8
+}
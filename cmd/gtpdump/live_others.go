@@ -0,0 +1,14 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+//go:build !linux
+// +build !linux
+
+package main
+
+import "errors"
+
+func openLive(name string, ports []int) (source, error) {
+	return nil, errors.New("live capture is not supported on this platform")
+}
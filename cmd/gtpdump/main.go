@@ -0,0 +1,105 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Command gtpdump decodes GTP packets read from a pcap/pcapng file or captured
+// live on a network interface, and prints them with the dissection of the
+// library.
+//
+// Usage:
+//
+//	gtpdump -r capture.pcapng
+//	gtpdump -i eth0 -v
+//
+// The live capture is available only on Linux and requires the privilege to
+// open AF_PACKET sockets (CAP_NET_RAW).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/wmnsk/go-gtp/cmd/internal/dissect"
+	"github.com/wmnsk/go-gtp/gtpcap"
+)
+
+// source is where the packets come from.
+type source interface {
+	Next() (*gtpcap.Packet, error)
+}
+
+func main() {
+	var (
+		file    = flag.String("r", "", "read packets from pcap/pcapng file")
+		iface   = flag.String("i", "", "capture packets live on the interface (Linux only)")
+		ports   = flag.String("p", "2123,2152,3386", "comma-separated UDP ports to decode as GTP")
+		verbose = flag.Bool("v", false, "print the IEs and header fields of each packet")
+		count   = flag.Int("c", 0, "exit after printing this number of packets (0 means unlimited)")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+
+	pts, err := parsePorts(*ports)
+	if err != nil {
+		log.Fatalf("invalid -p: %v", err)
+	}
+
+	var src source
+	switch {
+	case *file != "" && *iface != "":
+		log.Fatal("-r and -i cannot be used together")
+	case *file != "":
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+
+		r, err := gtpcap.NewReader(f)
+		if err != nil {
+			log.Fatal(err)
+		}
+		r.Ports = pts
+		src = r
+	case *iface != "":
+		src, err = openLive(*iface, pts)
+		if err != nil {
+			log.Fatal(err)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	for n := 0; *count == 0 || n < *count; n++ {
+		p, err := src.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println(dissect.Summary(p))
+		if *verbose {
+			fmt.Println(dissect.Detail(p))
+		}
+	}
+}
+
+func parsePorts(s string) ([]int, error) {
+	var ports []int
+	for _, p := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return nil, err
+		}
+		ports = append(ports, n)
+	}
+	return ports, nil
+}
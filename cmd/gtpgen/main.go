@@ -0,0 +1,243 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Command gtpgen crafts GTP messages and sends them to a peer.
+//
+// The GTPv2-C messages are built from the templates of gtptemplate package,
+// or from the flags for quick one-off messages. The GTP-U T-PDU is built from
+// the TEID and payload given by the flags.
+//
+// Usage:
+//
+//	gtpgen -peer 127.0.0.1:2123 -t attach.yaml -var imsi=123451234567890 -wait 1s
+//	gtpgen -peer 127.0.0.1:2123 -type EchoRequest -ie Recovery=01
+//	gtpgen -peer 127.0.0.1:2152 -u -teid 0x11223344 -payload 4500001c...
+//
+// With -wait, gtpgen waits for the response to each message and prints it.
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wmnsk/go-gtp/cmd/internal/dissect"
+	"github.com/wmnsk/go-gtp/gtpcap"
+	"github.com/wmnsk/go-gtp/gtptemplate"
+	v1msg "github.com/wmnsk/go-gtp/gtpv1/message"
+	v2msg "github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// listFlag is a flag that can be given multiple times.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+var (
+	peer    = flag.String("peer", "", "address of the peer to send messages to (host:port)")
+	laddr   = flag.String("laddr", "", "local address to send messages from (host:port)")
+	tmpl    = flag.String("t", "", "build GTPv2-C messages from the template file (YAML or JSON)")
+	name    = flag.String("name", "", "send only the template with this name in the file")
+	msgType = flag.String("type", "", "build a GTPv2-C message of this type (name or number)")
+	teid    = flag.String("teid", "", "TEID of the message")
+	seq     = flag.String("seq", "", "sequence number of the message")
+	user    = flag.Bool("u", false, "send a GTP-U T-PDU instead of GTPv2-C message")
+	payload = flag.String("payload", "", "payload of the T-PDU in hex")
+	wait    = flag.Duration("wait", 0, "wait for the response to each message for this duration")
+	verbose = flag.Bool("v", false, "print the IEs and header fields of the messages")
+
+	vars listFlag
+	ies  listFlag
+)
+
+func main() {
+	flag.Var(&vars, "var", "variable for the templates in name=value format (repeatable)")
+	flag.Var(&ies, "ie", "IE of the message in type[:instance]=hex format (repeatable)")
+	flag.Parse()
+	log.SetFlags(0)
+
+	if *peer == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	msgs, err := build()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	raddr, err := net.ResolveUDPAddr("udp", *peer)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var local *net.UDPAddr
+	if *laddr != "" {
+		local, err = net.ResolveUDPAddr("udp", *laddr)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	conn, err := net.ListenUDP("udp", local)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 65535)
+	for _, b := range msgs {
+		if _, err := conn.WriteTo(b, raddr); err != nil {
+			log.Fatal(err)
+		}
+		show(gtpcap.NewPacket(b, conn.LocalAddr(), raddr))
+
+		if *wait == 0 {
+			continue
+		}
+		if err := conn.SetReadDeadline(time.Now().Add(*wait)); err != nil {
+			log.Fatal(err)
+		}
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				log.Printf("no response in %s", *wait)
+				continue
+			}
+			log.Fatal(err)
+		}
+		show(gtpcap.NewPacket(buf[:n], addr, conn.LocalAddr()))
+	}
+}
+
+func show(p *gtpcap.Packet) {
+	fmt.Println(dissect.Summary(p))
+	if *verbose {
+		fmt.Println(dissect.Detail(p))
+	}
+}
+
+// build returns the messages to send in bytes, built in the way chosen by
+// the flags.
+func build() ([][]byte, error) {
+	switch {
+	case *user:
+		return buildTPDU()
+	case *tmpl != "":
+		ts, err := gtptemplate.ParseFile(*tmpl)
+		if err != nil {
+			return nil, err
+		}
+		return buildTemplates(ts)
+	case *msgType != "":
+		doc, err := flagsToTemplate()
+		if err != nil {
+			return nil, err
+		}
+		ts, err := gtptemplate.Parse(doc)
+		if err != nil {
+			return nil, err
+		}
+		return buildTemplates(ts)
+	default:
+		return nil, errors.New("one of -t, -type or -u must be given")
+	}
+}
+
+func buildTPDU() ([][]byte, error) {
+	t, err := strconv.ParseUint(*teid, 0, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -teid: %w", err)
+	}
+	p, err := hex.DecodeString(*payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -payload: %w", err)
+	}
+
+	b, err := v1msg.Marshal(v1msg.NewTPDU(uint32(t), p))
+	if err != nil {
+		return nil, err
+	}
+	return [][]byte{b}, nil
+}
+
+func buildTemplates(ts []*gtptemplate.Template) ([][]byte, error) {
+	vs := map[string]string{}
+	for _, v := range vars {
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid -var %q: must be in name=value format", v)
+		}
+		vs[kv[0]] = kv[1]
+	}
+
+	var msgs [][]byte
+	for _, t := range ts {
+		if *name != "" && t.Name != *name {
+			continue
+		}
+
+		m, err := t.Build(vs)
+		if err != nil {
+			return nil, err
+		}
+		b, err := v2msg.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, b)
+	}
+
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("no template named %q", *name)
+	}
+	return msgs, nil
+}
+
+// flagsToTemplate converts the flags into a template in JSON, so that the
+// messages built from the flags are validated in the same way as templates.
+func flagsToTemplate() ([]byte, error) {
+	t := map[string]interface{}{"type": *msgType}
+	if *teid != "" {
+		t["teid"] = *teid
+	}
+	if *seq != "" {
+		t["sequence"] = *seq
+	}
+
+	var list []interface{}
+	for _, s := range ies {
+		kv := strings.SplitN(s, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid -ie %q: must be in type[:instance]=hex format", s)
+		}
+
+		i := map[string]interface{}{"payload": kv[1]}
+		typ := strings.SplitN(kv[0], ":", 2)
+		i["type"] = typ[0]
+		if len(typ) == 2 {
+			i["instance"] = typ[1]
+		}
+		list = append(list, i)
+	}
+	if len(list) > 0 {
+		t["ies"] = list
+	}
+
+	return json.Marshal(t)
+}
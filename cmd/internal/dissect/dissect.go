@@ -0,0 +1,127 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Package dissect formats the GTP packets in human readable text for the
+// command-line tools. The format is not stable.
+package dissect
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wmnsk/go-gtp/gtpcap"
+	v0ie "github.com/wmnsk/go-gtp/gtpv0/ie"
+	v0msg "github.com/wmnsk/go-gtp/gtpv0/message"
+	v1ie "github.com/wmnsk/go-gtp/gtpv1/ie"
+	v1msg "github.com/wmnsk/go-gtp/gtpv1/message"
+	v2msg "github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// timeFormat is the format of timestamp in Summary.
+const timeFormat = "15:04:05.000000"
+
+// Summary returns the one-line summary of a packet.
+func Summary(p *gtpcap.Packet) string {
+	s := fmt.Sprintf("%s %s > %s ", p.Timestamp.Format(timeFormat), p.Src, p.Dst)
+	if p.Message == nil {
+		return s + fmt.Sprintf("malformed GTP, length %d", len(p.Data))
+	}
+
+	s += fmt.Sprintf("GTPv%d %s", p.Message.Version(), p.Message.MessageTypeName())
+	switch m := p.Message.(type) {
+	case v2msg.Message:
+		s += fmt.Sprintf(", TEID: %#08x, Seq: %d", m.TEID(), m.Sequence())
+	case v1msg.Message:
+		s += fmt.Sprintf(", TEID: %#08x, Seq: %d", m.TEID(), m.Sequence())
+	case v0msg.Message:
+		s += fmt.Sprintf(", TID: %s", m.TID())
+	}
+	return s + fmt.Sprintf(", length %d", len(p.Data))
+}
+
+// Detail returns the multi-line dissection of a packet.
+//
+// The IEs in GTPv2 messages are decoded with message.Dump, while the ones in
+// GTPv0 and GTPv1 are shown with their types and values in hex.
+func Detail(p *gtpcap.Packet) string {
+	if p.Message == nil {
+		return indent(fmt.Sprintf("Data: %x", p.Data))
+	}
+
+	switch m := p.Message.(type) {
+	case v2msg.Message:
+		return strings.TrimRight(indent(v2msg.Dump(m)), "\n")
+	case v1msg.Message:
+		return detailV1(p.Data)
+	case v0msg.Message:
+		return detailV0(p.Data)
+	default:
+		return indent(fmt.Sprintf("Data: %x", p.Data))
+	}
+}
+
+func detailV1(b []byte) string {
+	h, err := v1msg.ParseHeader(b)
+	if err != nil {
+		return indent(fmt.Sprintf("Malformed: %v", err))
+	}
+
+	lines := []string{
+		fmt.Sprintf("Header: Flags: %#02x, Type: %d, Length: %d, TEID: %#08x, Seq: %d",
+			h.Flags, h.Type, h.Length, h.TEID, h.SequenceNumber),
+	}
+	for _, e := range h.ExtensionHeaders {
+		lines = append(lines, fmt.Sprintf("    Extension Header [Type: %#02x]: %x", e.Type, e.Content))
+	}
+
+	if h.Type == v1msg.MsgTypeTPDU {
+		lines = append(lines, fmt.Sprintf("Payload: %d bytes", len(h.Payload)))
+		return indent(strings.Join(lines, "\n"))
+	}
+
+	ies, err := v1ie.ParseMultiIEs(h.Payload)
+	if err != nil {
+		lines = append(lines, fmt.Sprintf("Malformed: %v", err))
+		return indent(strings.Join(lines, "\n"))
+	}
+	for _, i := range ies {
+		lines = append(lines, fmt.Sprintf("IE [Type: %d, Length: %d]: %x", i.Type, len(i.Payload), i.Payload))
+	}
+	return indent(strings.Join(lines, "\n"))
+}
+
+func detailV0(b []byte) string {
+	h, err := v0msg.ParseHeader(b)
+	if err != nil {
+		return indent(fmt.Sprintf("Malformed: %v", err))
+	}
+
+	lines := []string{
+		fmt.Sprintf("Header: Flags: %#02x, Type: %d, Length: %d, Seq: %d, Flow Label: %d, TID: %#016x",
+			h.Flags, h.Type, h.Length, h.SequenceNumber, h.FlowLabel, h.TID),
+	}
+
+	if h.Type == v0msg.MsgTypeTPDU {
+		lines = append(lines, fmt.Sprintf("Payload: %d bytes", len(h.Payload)))
+		return indent(strings.Join(lines, "\n"))
+	}
+
+	ies, err := v0ie.ParseMultiIEs(h.Payload)
+	if err != nil {
+		lines = append(lines, fmt.Sprintf("Malformed: %v", err))
+		return indent(strings.Join(lines, "\n"))
+	}
+	for _, i := range ies {
+		lines = append(lines, fmt.Sprintf("IE [Type: %d, Length: %d]: %x", i.Type, len(i.Payload), i.Payload))
+	}
+	return indent(strings.Join(lines, "\n"))
+}
+
+func indent(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for n, l := range lines {
+		lines[n] = "    " + l
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,77 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package dissect_test
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/wmnsk/go-gtp/cmd/internal/dissect"
+	"github.com/wmnsk/go-gtp/gtpcap"
+	v1ie "github.com/wmnsk/go-gtp/gtpv1/ie"
+	v1msg "github.com/wmnsk/go-gtp/gtpv1/message"
+	v2ie "github.com/wmnsk/go-gtp/gtpv2/ie"
+	v2msg "github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+var (
+	src = &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 2123}
+	dst = &net.UDPAddr{IP: net.ParseIP("127.0.0.2"), Port: 2123}
+)
+
+func TestDissect(t *testing.T) {
+	v2, err := v2msg.Marshal(v2msg.NewEchoRequest(1, v2ie.NewRecovery(5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1, err := v1msg.Marshal(v1msg.NewEchoRequest(2, v1ie.NewRecovery(6)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		description    string
+		data           []byte
+		summary, lines []string
+	}{
+		{
+			"GTPv2",
+			v2,
+			[]string{"127.0.0.1:2123 > 127.0.0.2:2123", "GTPv2 Echo Request", "Seq: 1", "length 13"},
+			[]string{"Recovery [Type: 3, Length: 1, Instance: 0]", "Restart Counter: 5"},
+		}, {
+			"GTPv1",
+			v1,
+			[]string{"GTPv1 Echo Request", "Seq: 2", "length 14"},
+			[]string{"Type: 1", "IE [Type: 14, Length: 1]: 06"},
+		}, {
+			"Malformed",
+			[]byte{0xde, 0xad},
+			[]string{"malformed GTP, length 2"},
+			[]string{"Data: dead"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			p := gtpcap.NewPacket(c.data, src, dst)
+
+			s := dissect.Summary(p)
+			for _, want := range c.summary {
+				if !strings.Contains(s, want) {
+					t.Errorf("summary %q does not contain %q", s, want)
+				}
+			}
+
+			d := dissect.Detail(p)
+			for _, want := range c.lines {
+				if !strings.Contains(d, want) {
+					t.Errorf("detail does not contain %q:\n%s", want, d)
+				}
+			}
+		})
+	}
+}
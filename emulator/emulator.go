@@ -0,0 +1,137 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Package emulator provides the behaviors of MME, SGW, PGW and eNB on the
+// GTPv2-C interfaces as libraries, which can be composed into the test tools
+// and simulators.
+//
+// The nodes are built on the *gtpv2.Conn given by the caller, so that they
+// can run over real UDP sockets or over the in-memory ones in gtptest. The
+// caller is responsible for starting the Conns with ListenAndServe after the
+// nodes are created, as the handlers are registered on creation.
+//
+// The procedures emulated are attach (Create Session), detach (Delete
+// Session), S1 release (Release Access Bearers), paging (Downlink Data
+// Notification) followed by service request, and X2-based handover (Modify
+// Bearer). The user plane is not handled; the tunnels set up are available
+// in ENB so that the caller can send the user traffic with gtpv1.UPlaneConn.
+//
+// This package is written separately from the programs under examples/,
+// which keep their own handlers and are not built on it.
+package emulator
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// Error definitions.
+var (
+	ErrInvalidConfig = errors.New("invalid config")
+	ErrUnknownUE     = errors.New("unknown UE")
+	ErrInvalidState  = errors.New("procedure not allowed in current state")
+	ErrPoolExhausted = errors.New("no UE IP address available in pool")
+)
+
+// DefaultTimeout is the time to wait for the response from the peer, used if
+// Timeout is not set in the config.
+const DefaultTimeout = 3 * time.Second
+
+// State is the state of a UE.
+type State uint8
+
+// State definitions.
+const (
+	// StateDetached is that the UE has no session.
+	StateDetached State = iota
+	// StateConnected is that the UE has the session and the S1-U tunnel.
+	StateConnected
+	// StateIdle is that the UE has the session but not the S1-U tunnel.
+	StateIdle
+)
+
+// String returns the name of State.
+func (s State) String() string {
+	switch s {
+	case StateDetached:
+		return "Detached"
+	case StateConnected:
+		return "Connected"
+	case StateIdle:
+		return "Idle"
+	default:
+		return fmt.Sprintf("State(%d)", uint8(s))
+	}
+}
+
+// hostIP returns the IP address of addr in string.
+func hostIP(addr net.Addr) (string, error) {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return "", err
+	}
+	return host, nil
+}
+
+// checkCause returns *gtpv2.CauseNotOKError if the Cause IE given does not
+// indicate that the request is accepted.
+func checkCause(msg message.Message, causeIE *ie.IE) error {
+	if causeIE == nil {
+		return &v2.RequiredIEMissingError{Type: ie.Cause}
+	}
+
+	cause, err := causeIE.Cause()
+	if err != nil {
+		return err
+	}
+
+	switch cause {
+	case v2.CauseRequestAccepted, v2.CauseRequestAcceptedPartially,
+		v2.CauseNewPDNTypeDueToNetworkPreference, v2.CauseNewPDNTypeDueToSingleAddressBearerOnly:
+		return nil
+	}
+	return &v2.CauseNotOKError{
+		MsgType: msg.MessageTypeName(),
+		Cause:   cause,
+		Msg:     "request rejected by peer",
+	}
+}
+
+// findIE returns the first IE with the type and instance in ies, or nil.
+func findIE(ies []*ie.IE, typ, instance uint8) *ie.IE {
+	for _, i := range ies {
+		if i != nil && i.Type == typ && i.Instance() == instance {
+			return i
+		}
+	}
+	return nil
+}
+
+// findChild returns the child IE of the grouped IE, or nil if not found.
+func findChild(grouped *ie.IE, typ, instance uint8) *ie.IE {
+	if grouped == nil {
+		return nil
+	}
+	child, err := grouped.FindByType(typ, instance)
+	if err != nil {
+		return nil
+	}
+	return child
+}
+
+// passToSession passes the message to the Session looked up by TEID and the
+// sender, which is expected to be waiting for it with WaitMessage.
+func passToSession(c *v2.Conn, senderAddr net.Addr, msg message.Message, timeout time.Duration) error {
+	sess, err := c.GetSessionByTEID(msg.TEID(), senderAddr)
+	if err != nil {
+		return err
+	}
+	return v2.PassMessageTo(sess, msg, timeout)
+}
@@ -0,0 +1,247 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package emulator_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/wmnsk/go-gtp/emulator"
+	"github.com/wmnsk/go-gtp/gtptest"
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+)
+
+type nodes struct {
+	mme      *emulator.MME
+	sgw      *emulator.SGW
+	pgwConn  *v2.Conn
+	sgwS11   *v2.Conn
+	enb1     *emulator.ENB
+	enb2     *emulator.ENB
+	onPaging func(ue *emulator.UE) bool
+}
+
+func setup(t *testing.T, pgwCfg *emulator.PGWConfig, mmeCfg *emulator.MMEConfig) *nodes {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	network := gtptest.NewNetwork()
+	listen := func(addr string, ifType uint8) *v2.Conn {
+		pc, err := network.ListenPacket("udp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn := v2.NewConnWithPacketConn(pc, ifType, 0)
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+	mmeConn := listen("127.0.0.1:2123", v2.IFTypeS11MMEGTPC)
+	sgwS11 := listen("127.0.0.2:2123", v2.IFTypeS11S4SGWGTPC)
+	sgwS5 := listen("127.0.0.3:2123", v2.IFTypeS5S8SGWGTPC)
+	pgwConn := listen("127.0.0.4:2123", v2.IFTypeS5S8PGWGTPC)
+
+	if _, err := emulator.NewPGW(pgwConn, pgwCfg); err != nil {
+		t.Fatal(err)
+	}
+	sgw, err := emulator.NewSGW(sgwS11, sgwS5, &emulator.SGWConfig{
+		PGWAddr: pgwConn.LocalAddr(),
+		Timeout: time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mmeCfg.SGWAddr = sgwS11.LocalAddr()
+	mmeCfg.Timeout = time.Second
+	mme, err := emulator.NewMME(mmeConn, mmeCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, conn := range []*v2.Conn{mmeConn, sgwS11, sgwS5, pgwConn} {
+		go func(conn *v2.Conn) {
+			if err := conn.ListenAndServe(ctx); err != nil {
+				t.Log(err)
+			}
+		}(conn)
+	}
+
+	return &nodes{
+		mme:     mme,
+		sgw:     sgw,
+		pgwConn: pgwConn,
+		sgwS11:  sgwS11,
+		enb1:    emulator.NewENB("enb1", "127.0.1.1"),
+		enb2:    emulator.NewENB("enb2", "127.0.1.2"),
+	}
+}
+
+func waitState(t *testing.T, ue *emulator.UE, want emulator.State) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for ue.State() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("UE %s is %s, want %s", ue.IMSI, ue.State(), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestEmulator(t *testing.T) {
+	n := setup(t, &emulator.PGWConfig{UEPool: "10.45.0.0/24"}, &emulator.MMEConfig{})
+
+	imsis := []string{"001010000000001", "001010000000002"}
+	var ues []*emulator.UE
+	for _, imsi := range imsis {
+		ue, err := n.mme.Attach(&emulator.Subscriber{IMSI: imsi, MSISDN: "8130900000" + imsi[13:], APN: "internet"}, n.enb1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ues = append(ues, ue)
+	}
+	if got, want := ues[0].IP, "10.45.0.1"; got != want {
+		t.Errorf("wrong UE IP, want %s, got %s", want, got)
+	}
+	if got, want := ues[1].IP, "10.45.0.2"; got != want {
+		t.Errorf("wrong UE IP, want %s, got %s", want, got)
+	}
+	if got := len(n.enb1.Tunnels()); got != 2 {
+		t.Errorf("want 2 tunnels on enb1, got %d", got)
+	}
+	if got := n.pgwConn.SessionCount(); got != 2 {
+		t.Errorf("want 2 sessions on PGW, got %d", got)
+	}
+
+	tun, err := n.enb1.Tunnel(imsis[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sgwSess, err := n.sgwS11.GetSessionByIMSI(imsis[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := sgwSess.GetTEID(v2.IFTypeS1UeNodeBGTPU); got != tun.ENBTEID {
+		t.Errorf("SGW has wrong eNB TEID, want %#x, got %#x", tun.ENBTEID, got)
+	}
+
+	t.Run("Idle/Paging", func(t *testing.T) {
+		if err := n.mme.Release(imsis[0]); err != nil {
+			t.Fatal(err)
+		}
+		if ues[0].State() != emulator.StateIdle {
+			t.Errorf("want Idle, got %s", ues[0].State())
+		}
+		if idle, err := n.sgw.IsIdle(imsis[0]); err != nil || !idle {
+			t.Errorf("want idle on SGW, got %v, %v", idle, err)
+		}
+		if _, err := n.enb1.Tunnel(imsis[0]); !errors.Is(err, emulator.ErrUnknownUE) {
+			t.Errorf("want tunnel released, got %v", err)
+		}
+		if err := n.mme.Handover(imsis[0], n.enb2); !errors.Is(err, emulator.ErrInvalidState) {
+			t.Errorf("want ErrInvalidState for handover of idle UE, got %v", err)
+		}
+
+		if err := n.sgw.NotifyDownlinkData(imsis[0]); err != nil {
+			t.Fatal(err)
+		}
+		waitState(t, ues[0], emulator.StateConnected)
+		if _, err := n.enb1.Tunnel(imsis[0]); err != nil {
+			t.Errorf("want tunnel set up again on enb1, got %v", err)
+		}
+		if idle, _ := n.sgw.IsIdle(imsis[0]); idle {
+			t.Error("want connected on SGW")
+		}
+	})
+
+	t.Run("Handover", func(t *testing.T) {
+		if err := n.mme.Handover(imsis[1], n.enb2); err != nil {
+			t.Fatal(err)
+		}
+		if ues[1].ENB() != n.enb2 {
+			t.Errorf("want UE on enb2, got %s", ues[1].ENB().Name)
+		}
+		if _, err := n.enb1.Tunnel(imsis[1]); !errors.Is(err, emulator.ErrUnknownUE) {
+			t.Errorf("want tunnel released on enb1, got %v", err)
+		}
+		tun, err := n.enb2.Tunnel(imsis[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sess, err := n.sgwS11.GetSessionByIMSI(imsis[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := sess.GetDefaultBearer().RemoteAddress().String(); got != "127.0.1.2"+v2.GTPUPort {
+			t.Errorf("SGW sends downlink to wrong eNB: %s", got)
+		}
+		if got := sess.GetDefaultBearer().OutgoingTEID(); got != tun.ENBTEID {
+			t.Errorf("SGW has wrong eNB TEID, want %#x, got %#x", tun.ENBTEID, got)
+		}
+	})
+
+	t.Run("Detach", func(t *testing.T) {
+		for _, imsi := range imsis {
+			if err := n.mme.Detach(imsi); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := len(n.mme.UEs()); got != 0 {
+			t.Errorf("want no UE on MME, got %d", got)
+		}
+		if got := n.sgwS11.SessionCount(); got != 0 {
+			t.Errorf("want no session on SGW, got %d", got)
+		}
+		if got := n.pgwConn.SessionCount(); got != 0 {
+			t.Errorf("want no session on PGW, got %d", got)
+		}
+		if got := len(n.enb1.Tunnels()) + len(n.enb2.Tunnels()); got != 0 {
+			t.Errorf("want no tunnel on eNBs, got %d", got)
+		}
+	})
+}
+
+func TestEmulatorRejected(t *testing.T) {
+	n := setup(t, &emulator.PGWConfig{
+		Admit: func(sess *v2.Session) uint8 {
+			if sess.IMSI == "001010000000009" {
+				return v2.CauseUserAuthenticationFailed
+			}
+			return v2.CauseRequestAccepted
+		},
+	}, &emulator.MMEConfig{
+		OnPaging: func(ue *emulator.UE) bool { return false },
+	})
+
+	_, err := n.mme.Attach(&emulator.Subscriber{IMSI: "001010000000009", APN: "internet"}, n.enb1)
+	var causeErr *v2.CauseNotOKError
+	if !errors.As(err, &causeErr) || causeErr.Cause != v2.CauseUserAuthenticationFailed {
+		t.Errorf("want CauseNotOKError with %d, got %v", v2.CauseUserAuthenticationFailed, err)
+	}
+	if _, err := n.mme.UE("001010000000009"); !errors.Is(err, emulator.ErrUnknownUE) {
+		t.Errorf("want rejected UE removed, got %v", err)
+	}
+	if got := n.pgwConn.SessionCount(); got != 0 {
+		t.Errorf("want no session on PGW, got %d", got)
+	}
+
+	ue, err := n.mme.Attach(&emulator.Subscriber{IMSI: "001010000000001", APN: "internet"}, n.enb1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.mme.Release(ue.IMSI); err != nil {
+		t.Fatal(err)
+	}
+	err = n.sgw.NotifyDownlinkData(ue.IMSI)
+	if !errors.As(err, &causeErr) || causeErr.Cause != v2.CauseUnableToPageUE {
+		t.Errorf("want CauseNotOKError with %d, got %v", v2.CauseUnableToPageUE, err)
+	}
+	if ue.State() != emulator.StateIdle {
+		t.Errorf("want UE idle, got %s", ue.State())
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package emulator
+
+import (
+	"sort"
+	"sync"
+
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+// Tunnel is an S1-U tunnel between an eNB and the SGW for a UE.
+type Tunnel struct {
+	IMSI string
+	EBI  uint8
+
+	// ENBTEID is the TEID allocated by the eNB, with which the SGW sends the
+	// downlink packets.
+	ENBTEID uint32
+	// SGWTEID and SGWIP are the S1-U F-TEID of the SGW, to which the eNB
+	// sends the uplink packets.
+	SGWTEID uint32
+	SGWIP   string
+}
+
+// ENB is an emulated eNB, which holds the S1-U tunnels of the UEs it serves.
+//
+// It sends no messages by itself; the tunnels are set up and released by MME
+// on attach, service request, S1 release and handover.
+type ENB struct {
+	Name  string
+	S1UIP string
+
+	mu      sync.Mutex
+	teid    uint32
+	tunnels map[string]*Tunnel
+}
+
+// NewENB creates a new ENB with the IP address of S1-U interface.
+func NewENB(name, s1uIP string) *ENB {
+	return &ENB{
+		Name:    name,
+		S1UIP:   s1uIP,
+		tunnels: map[string]*Tunnel{},
+	}
+}
+
+// Tunnel returns the copy of the S1-U tunnel of the UE.
+func (e *ENB) Tunnel(imsi string) (*Tunnel, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	t, ok := e.tunnels[imsi]
+	if !ok {
+		return nil, ErrUnknownUE
+	}
+	tun := *t
+	return &tun, nil
+}
+
+// Tunnels returns the copies of all the S1-U tunnels sorted by IMSI.
+func (e *ENB) Tunnels() []*Tunnel {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	tunnels := make([]*Tunnel, 0, len(e.tunnels))
+	for _, t := range e.tunnels {
+		tun := *t
+		tunnels = append(tunnels, &tun)
+	}
+	sort.Slice(tunnels, func(i, j int) bool { return tunnels[i].IMSI < tunnels[j].IMSI })
+	return tunnels
+}
+
+// setupTunnel allocates the TEID for the UE and returns the S1-U eNB F-TEID
+// to be sent to the SGW.
+func (e *ENB) setupTunnel(imsi string, ebi uint8, sgwTEID uint32, sgwIP string) *ie.IE {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.teid++
+	if e.teid == 0 {
+		e.teid++
+	}
+	e.tunnels[imsi] = &Tunnel{
+		IMSI:    imsi,
+		EBI:     ebi,
+		ENBTEID: e.teid,
+		SGWTEID: sgwTEID,
+		SGWIP:   sgwIP,
+	}
+	return ie.NewFullyQualifiedTEID(v2.IFTypeS1UeNodeBGTPU, e.teid, e.S1UIP, "")
+}
+
+func (e *ENB) releaseTunnel(imsi string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.tunnels, imsi)
+}
@@ -0,0 +1,466 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package emulator
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// Subscriber is the subscription of a UE used on attach.
+type Subscriber struct {
+	IMSI   string
+	MSISDN string
+	IMEI   string
+	APN    string
+
+	// EBI is the EPS Bearer ID of the default bearer. 5 is used if zero.
+	EBI uint8
+}
+
+// UE is a UE attached to MME.
+type UE struct {
+	Subscriber
+
+	// IP is the IP address allocated by the PGW.
+	IP string
+
+	mu       sync.Mutex
+	state    State
+	enb      *ENB
+	sess     *v2.Session
+	sgwS1UIP string
+}
+
+// State returns the current state of the UE.
+func (u *UE) State() State {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.state
+}
+
+// ENB returns the eNB serving the UE, or the one that served it last if the
+// UE is idle.
+func (u *UE) ENB() *ENB {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.enb
+}
+
+// Session returns the S11 Session of the UE.
+func (u *UE) Session() *v2.Session {
+	return u.sess
+}
+
+func (u *UE) set(state State, enb *ENB) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.state = state
+	u.enb = enb
+}
+
+// MMEConfig is the configuration of MME.
+type MMEConfig struct {
+	// SGWAddr is the address of the S11 interface of the SGW.
+	SGWAddr net.Addr
+
+	// MCC and MNC are put in Serving Network IE.
+	MCC, MNC string
+
+	// Timeout is the time to wait for the response. DefaultTimeout is used
+	// if zero.
+	Timeout time.Duration
+
+	// OnPaging is called when the Downlink Data Notification comes for an
+	// idle UE, and reports whether the UE responds to the paging with the
+	// service request. If nil, the UE always responds.
+	OnPaging func(ue *UE) bool
+}
+
+// MME is an emulated MME on S11 interface.
+type MME struct {
+	conn *v2.Conn
+	cfg  MMEConfig
+	ip   string
+
+	mu  sync.Mutex
+	ues map[string]*UE
+}
+
+// NewMME creates a new MME and registers the handlers to conn, which should
+// be created with gtpv2.IFTypeS11MMEGTPC.
+func NewMME(conn *v2.Conn, cfg *MMEConfig) (*MME, error) {
+	if conn == nil || cfg == nil || cfg.SGWAddr == nil {
+		return nil, fmt.Errorf("%w: Conn and SGWAddr are required", ErrInvalidConfig)
+	}
+	ip, err := hostIP(conn.LocalAddr())
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MME{
+		conn: conn,
+		cfg:  *cfg,
+		ip:   ip,
+		ues:  map[string]*UE{},
+	}
+	if m.cfg.Timeout == 0 {
+		m.cfg.Timeout = DefaultTimeout
+	}
+	if m.cfg.MCC == "" || m.cfg.MNC == "" {
+		m.cfg.MCC, m.cfg.MNC = "001", "01"
+	}
+
+	conn.AddHandlers(map[uint8]v2.HandlerFunc{
+		message.MsgTypeCreateSessionResponse:        m.passToSession,
+		message.MsgTypeDeleteSessionResponse:        m.passToSession,
+		message.MsgTypeReleaseAccessBearersResponse: m.passToSession,
+		message.MsgTypeDownlinkDataNotification:     m.handleDownlinkDataNotification,
+	})
+	return m, nil
+}
+
+// UE returns the UE attached.
+func (m *MME) UE(imsi string) (*UE, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ue, ok := m.ues[imsi]
+	if !ok || ue == nil {
+		return nil, ErrUnknownUE
+	}
+	return ue, nil
+}
+
+// UEs returns all the UEs attached sorted by IMSI.
+func (m *MME) UEs() []*UE {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ues := make([]*UE, 0, len(m.ues))
+	for _, ue := range m.ues {
+		if ue == nil {
+			continue
+		}
+		ues = append(ues, ue)
+	}
+	sort.Slice(ues, func(i, j int) bool { return ues[i].IMSI < ues[j].IMSI })
+	return ues
+}
+
+// Attach creates the session for the subscriber with Create Session Request,
+// and then sets up the S1-U tunnel on enb with Modify Bearer Request.
+//
+// If the SGW rejects the request, it returns *gtpv2.CauseNotOKError.
+func (m *MME) Attach(sub *Subscriber, enb *ENB) (*UE, error) {
+	ue := &UE{Subscriber: *sub}
+	if ue.EBI == 0 {
+		ue.EBI = 5
+	}
+
+	m.mu.Lock()
+	if _, ok := m.ues[ue.IMSI]; ok {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s is already attached", ErrInvalidState, ue.IMSI)
+	}
+	// reserve the IMSI until the attach completes.
+	m.ues[ue.IMSI] = nil
+	m.mu.Unlock()
+
+	if err := m.createSession(ue); err != nil {
+		m.removeUE(ue)
+		return nil, err
+	}
+	if err := m.connect(ue, enb); err != nil {
+		m.removeUE(ue)
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.ues[ue.IMSI] = ue
+	m.mu.Unlock()
+	return ue, nil
+}
+
+func (m *MME) createSession(ue *UE) error {
+	ies := []*ie.IE{
+		ie.NewIMSI(ue.IMSI),
+		ie.NewServingNetwork(m.cfg.MCC, m.cfg.MNC),
+		ie.NewRATType(v2.RATTypeEUTRAN),
+		m.conn.NewSenderFTEID(m.ip, ""),
+		ie.NewAccessPointName(ue.APN),
+		ie.NewSelectionMode(v2.SelectionModeMSorNetworkProvidedAPNSubscribedVerified),
+		ie.NewPDNType(v2.PDNTypeIPv4),
+		ie.NewPDNAddressAllocation("0.0.0.0"),
+		ie.NewAPNRestriction(v2.APNRestrictionNoExistingContextsorRestriction),
+		ie.NewAggregateMaximumBitRate(0, 0),
+		ie.NewBearerContext(
+			ie.NewEPSBearerID(ue.EBI),
+			ie.NewBearerQoS(1, 2, 1, 9, 0, 0, 0, 0),
+		),
+	}
+	if ue.MSISDN != "" {
+		ies = append(ies, ie.NewMSISDN(ue.MSISDN))
+	}
+	if ue.IMEI != "" {
+		ies = append(ies, ie.NewMobileEquipmentIdentity(ue.IMEI))
+	}
+
+	sess, seq, err := m.conn.CreateSession(m.cfg.SGWAddr, ies...)
+	if err != nil {
+		return err
+	}
+	ue.sess = sess
+
+	incoming, err := sess.WaitMessage(seq, m.cfg.Timeout)
+	if err != nil {
+		return err
+	}
+	res, ok := incoming.(*message.CreateSessionResponse)
+	if !ok {
+		return &v2.UnexpectedTypeError{Msg: incoming}
+	}
+	if err := checkCause(res, res.Cause); err != nil {
+		return err
+	}
+
+	if res.SenderFTEIDC == nil {
+		return &v2.RequiredIEMissingError{Type: ie.FullyQualifiedTEID}
+	}
+	sgwTEID, err := res.SenderFTEIDC.TEID()
+	if err != nil {
+		return err
+	}
+	sess.AddTEID(v2.IFTypeS11S4SGWGTPC, sgwTEID)
+
+	if res.PAA == nil {
+		return &v2.RequiredIEMissingError{Type: ie.PDNAddressAllocation}
+	}
+	ue.IP, err = res.PAA.IPAddress()
+	if err != nil {
+		return err
+	}
+	sess.GetDefaultBearer().SubscriberIP = ue.IP
+
+	var s1uFTEID *ie.IE
+	if len(res.BearerContextsCreated) != 0 {
+		s1uFTEID = findChild(res.BearerContextsCreated[0], ie.FullyQualifiedTEID, 0)
+	}
+	if s1uFTEID == nil {
+		return &v2.RequiredIEMissingError{Type: ie.FullyQualifiedTEID}
+	}
+	s1uTEID, err := s1uFTEID.TEID()
+	if err != nil {
+		return err
+	}
+	sess.AddTEID(v2.IFTypeS1USGWGTPU, s1uTEID)
+	ue.sgwS1UIP, err = s1uFTEID.IPAddress()
+	if err != nil {
+		return err
+	}
+
+	return sess.Activate()
+}
+
+// connect sets up the S1-U tunnel on enb and sends the F-TEID of it to the
+// SGW with Modify Bearer Request.
+func (m *MME) connect(ue *UE, enb *ENB) error {
+	sgwTEID, err := ue.sess.GetTEID(v2.IFTypeS11S4SGWGTPC)
+	if err != nil {
+		return err
+	}
+	s1uTEID, err := ue.sess.GetTEID(v2.IFTypeS1USGWGTPU)
+	if err != nil {
+		return err
+	}
+
+	fteid := enb.setupTunnel(ue.IMSI, ue.EBI, s1uTEID, ue.sgwS1UIP)
+	if _, err := m.conn.ModifyBearerWithWait(
+		sgwTEID, ue.sess, m.cfg.Timeout,
+		ie.NewBearerContext(ie.NewEPSBearerID(ue.EBI), fteid),
+	); err != nil {
+		enb.releaseTunnel(ue.IMSI)
+		return err
+	}
+
+	ue.set(StateConnected, enb)
+	return nil
+}
+
+// Detach deletes the session of the UE with Delete Session Request.
+//
+// The UE is removed even if the SGW does not accept the request, and the
+// error is returned in that case.
+func (m *MME) Detach(imsi string) error {
+	ue, err := m.UE(imsi)
+	if err != nil {
+		return err
+	}
+	defer m.removeUE(ue)
+
+	sgwTEID, err := ue.sess.GetTEID(v2.IFTypeS11S4SGWGTPC)
+	if err != nil {
+		return err
+	}
+	seq, err := m.conn.DeleteSession(sgwTEID, ue.sess, ie.NewEPSBearerID(ue.EBI))
+	if err != nil {
+		return err
+	}
+
+	incoming, err := ue.sess.WaitMessage(seq, m.cfg.Timeout)
+	if err != nil {
+		return err
+	}
+	res, ok := incoming.(*message.DeleteSessionResponse)
+	if !ok {
+		return &v2.UnexpectedTypeError{Msg: incoming}
+	}
+	return checkCause(res, res.Cause)
+}
+
+// Release releases the S1-U tunnel of the connected UE with Release Access
+// Bearers Request, which makes the UE idle.
+func (m *MME) Release(imsi string) error {
+	ue, err := m.UE(imsi)
+	if err != nil {
+		return err
+	}
+	if st := ue.State(); st != StateConnected {
+		return fmt.Errorf("%w: release in %s", ErrInvalidState, st)
+	}
+
+	sgwTEID, err := ue.sess.GetTEID(v2.IFTypeS11S4SGWGTPC)
+	if err != nil {
+		return err
+	}
+	seq, err := m.conn.SendMessageTo(message.NewReleaseAccessBearersRequest(sgwTEID, 0), ue.sess.PeerAddr())
+	if err != nil {
+		return err
+	}
+
+	incoming, err := ue.sess.WaitMessage(seq, m.cfg.Timeout)
+	if err != nil {
+		return err
+	}
+	res, ok := incoming.(*message.ReleaseAccessBearersResponse)
+	if !ok {
+		return &v2.UnexpectedTypeError{Msg: incoming}
+	}
+	if err := checkCause(res, res.Cause); err != nil {
+		return err
+	}
+
+	enb := ue.ENB()
+	enb.releaseTunnel(imsi)
+	ue.set(StateIdle, enb)
+	return nil
+}
+
+// ServiceRequest sets up the S1-U tunnel of the idle UE on enb again. If enb
+// is nil, the one that served the UE last is used.
+func (m *MME) ServiceRequest(imsi string, enb *ENB) error {
+	ue, err := m.UE(imsi)
+	if err != nil {
+		return err
+	}
+	if st := ue.State(); st != StateIdle {
+		return fmt.Errorf("%w: service request in %s", ErrInvalidState, st)
+	}
+	if enb == nil {
+		enb = ue.ENB()
+	}
+	return m.connect(ue, enb)
+}
+
+// Handover moves the S1-U tunnel of the connected UE to target as X2-based
+// handover without SGW relocation does, by sending the F-TEID of target with
+// Modify Bearer Request.
+func (m *MME) Handover(imsi string, target *ENB) error {
+	ue, err := m.UE(imsi)
+	if err != nil {
+		return err
+	}
+	if st := ue.State(); st != StateConnected {
+		return fmt.Errorf("%w: handover in %s", ErrInvalidState, st)
+	}
+
+	source := ue.ENB()
+	if source == target {
+		return nil
+	}
+	if err := m.connect(ue, target); err != nil {
+		return err
+	}
+	source.releaseTunnel(imsi)
+	return nil
+}
+
+func (m *MME) removeUE(ue *UE) {
+	m.mu.Lock()
+	delete(m.ues, ue.IMSI)
+	m.mu.Unlock()
+
+	if ue.sess != nil {
+		m.conn.RemoveSession(ue.sess)
+	}
+	if enb := ue.ENB(); enb != nil {
+		enb.releaseTunnel(ue.IMSI)
+	}
+	ue.set(StateDetached, nil)
+}
+
+func (m *MME) passToSession(c *v2.Conn, sgwAddr net.Addr, msg message.Message) error {
+	return passToSession(c, sgwAddr, msg, m.cfg.Timeout)
+}
+
+// handleDownlinkDataNotification acknowledges the Downlink Data Notification
+// and lets the UE respond to the paging with the service request.
+//
+// Downlink Data Notification is handled as message.Generic, as the message
+// package does not have the dedicated type for it.
+func (m *MME) handleDownlinkDataNotification(c *v2.Conn, sgwAddr net.Addr, msg message.Message) error {
+	sess, err := c.GetSessionByTEID(msg.TEID(), sgwAddr)
+	if err != nil {
+		return err
+	}
+	ue, err := m.UE(sess.IMSI)
+	if err != nil {
+		return err
+	}
+	sgwTEID, err := sess.GetTEID(v2.IFTypeS11S4SGWGTPC)
+	if err != nil {
+		return err
+	}
+
+	page := ue.State() == StateIdle
+	cause := v2.CauseRequestAccepted
+	if page && m.cfg.OnPaging != nil && !m.cfg.OnPaging(ue) {
+		page = false
+		cause = v2.CauseUnableToPageUE
+	}
+
+	ack := message.NewGeneric(
+		message.MsgTypeDownlinkDataNotificationAcknowledge, sgwTEID, 0,
+		ie.NewCause(cause, 0, 0, 0, nil),
+	)
+	if err := c.RespondTo(sgwAddr, msg, ack); err != nil {
+		return err
+	}
+
+	if !page {
+		return nil
+	}
+	return m.ServiceRequest(ue.IMSI, nil)
+}
@@ -0,0 +1,254 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package emulator
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// PGWConfig is the configuration of PGW.
+type PGWConfig struct {
+	// S5UIP is the IP address put in the F-TEID of S5/S8-U interface. The IP
+	// address of the Conn is used if empty.
+	S5UIP string
+
+	// UEPool is the IPv4 network in CIDR notation from which the IP
+	// addresses of the UEs are allocated. "10.45.0.0/16" is used if empty.
+	UEPool string
+
+	// Admit is called on Create Session Request with the Session parsed, and
+	// returns the Cause to respond with. If nil, all the requests are
+	// accepted.
+	Admit func(sess *v2.Session) uint8
+}
+
+// PGW is an emulated PGW on S5/S8-C interface.
+type PGW struct {
+	conn *v2.Conn
+	cfg  PGWConfig
+	ip   string
+	pool *ipPool
+
+	mu         sync.Mutex
+	teid       uint32
+	chargingID uint32
+}
+
+// NewPGW creates a new PGW and registers the handlers to conn, which should
+// be created with gtpv2.IFTypeS5S8PGWGTPC.
+func NewPGW(conn *v2.Conn, cfg *PGWConfig) (*PGW, error) {
+	if conn == nil {
+		return nil, fmt.Errorf("%w: Conn is required", ErrInvalidConfig)
+	}
+	if cfg == nil {
+		cfg = &PGWConfig{}
+	}
+	ip, err := hostIP(conn.LocalAddr())
+	if err != nil {
+		return nil, err
+	}
+
+	p := &PGW{
+		conn: conn,
+		cfg:  *cfg,
+		ip:   ip,
+	}
+	if p.cfg.S5UIP == "" {
+		p.cfg.S5UIP = ip
+	}
+	if p.cfg.UEPool == "" {
+		p.cfg.UEPool = "10.45.0.0/16"
+	}
+	p.pool, err = newIPPool(p.cfg.UEPool)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.AddHandlers(map[uint8]v2.HandlerFunc{
+		message.MsgTypeCreateSessionRequest: p.handleCreateSessionRequest,
+		message.MsgTypeDeleteSessionRequest: p.handleDeleteSessionRequest,
+	})
+	return p, nil
+}
+
+func (p *PGW) allocTEID() uint32 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.teid++
+	if p.teid == 0 {
+		p.teid++
+	}
+	return p.teid
+}
+
+func (p *PGW) allocChargingID() uint32 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.chargingID++
+	return p.chargingID
+}
+
+func (p *PGW) handleCreateSessionRequest(c *v2.Conn, sgwAddr net.Addr, msg message.Message) error {
+	req, ok := msg.(*message.CreateSessionRequest)
+	if !ok {
+		return &v2.UnexpectedTypeError{Msg: msg}
+	}
+	if req.IMSI == nil {
+		return &v2.RequiredIEMissingError{Type: ie.IMSI}
+	}
+	if req.SenderFTEIDC == nil {
+		return &v2.RequiredIEMissingError{Type: ie.FullyQualifiedTEID}
+	}
+
+	ies := []*ie.IE{
+		req.IMSI, req.MSISDN, req.MEI, req.ServingNetwork,
+		req.RATType, req.SenderFTEIDC, req.APN,
+	}
+	sess, err := c.ParseCreateSession(sgwAddr, append(ies, req.BearerContextsToBeCreated...)...)
+	if err != nil {
+		return err
+	}
+	sgwTEID, err := sess.GetTEID(v2.IFTypeS5S8SGWGTPC)
+	if err != nil {
+		return err
+	}
+
+	// remove the previous session for the same subscriber if exists.
+	if old, err := c.GetSessionByIMSI(sess.IMSI); err == nil {
+		p.removeSession(old)
+	}
+
+	cause := v2.CauseRequestAccepted
+	if p.cfg.Admit != nil {
+		cause = p.cfg.Admit(sess)
+	}
+	br := sess.GetDefaultBearer()
+	if cause == v2.CauseRequestAccepted {
+		br.SubscriberIP, err = p.pool.allocate()
+		if err != nil {
+			cause = v2.CauseAllDynamicAddressesAreOccupied
+		}
+	}
+	if cause != v2.CauseRequestAccepted {
+		return c.RespondTo(sgwAddr, req, message.NewCreateSessionResponse(
+			sgwTEID, 0, ie.NewCause(cause, 0, 0, 0, nil),
+		))
+	}
+
+	s5cFTEID := c.NewSenderFTEID(p.ip, "").WithInstance(1)
+	s5uTEID := p.allocTEID()
+	br.ChargingID = p.allocChargingID()
+	sess.AddTEID(v2.IFTypeS5S8PGWGTPU, s5uTEID)
+	c.RegisterSession(s5cFTEID.MustTEID(), sess)
+	if err := sess.Activate(); err != nil {
+		return err
+	}
+
+	return c.RespondTo(sgwAddr, req, message.NewCreateSessionResponse(
+		sgwTEID, 0,
+		ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+		s5cFTEID,
+		ie.NewPDNAddressAllocation(br.SubscriberIP),
+		ie.NewAPNRestriction(v2.APNRestrictionPublic2),
+		ie.NewBearerContext(
+			ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+			ie.NewEPSBearerID(br.EBI),
+			ie.NewFullyQualifiedTEID(v2.IFTypeS5S8PGWGTPU, s5uTEID, p.cfg.S5UIP, "").WithInstance(2),
+			ie.NewChargingID(br.ChargingID),
+		),
+	))
+}
+
+func (p *PGW) handleDeleteSessionRequest(c *v2.Conn, sgwAddr net.Addr, msg message.Message) error {
+	sess, err := c.GetSessionByTEID(msg.TEID(), sgwAddr)
+	if err != nil {
+		return err
+	}
+	sgwTEID, err := sess.GetTEID(v2.IFTypeS5S8SGWGTPC)
+	if err != nil {
+		return err
+	}
+
+	p.removeSession(sess)
+	return c.RespondTo(sgwAddr, msg, message.NewDeleteSessionResponse(
+		sgwTEID, 0, ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+	))
+}
+
+func (p *PGW) removeSession(sess *v2.Session) {
+	p.pool.release(sess.GetDefaultBearer().SubscriberIP)
+	p.conn.RemoveSession(sess)
+}
+
+// ipPool allocates the IPv4 addresses in a network, excluding the network
+// and broadcast addresses.
+type ipPool struct {
+	mu    sync.Mutex
+	base  uint32
+	size  uint32
+	next  uint32
+	inUse map[uint32]bool
+}
+
+func newIPPool(cidr string) (*ipPool, error) {
+	_, n, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	ip := n.IP.To4()
+	if ip == nil {
+		return nil, fmt.Errorf("%w: UEPool %s is not IPv4", ErrInvalidConfig, cidr)
+	}
+	ones, bits := n.Mask.Size()
+	if bits-ones < 2 {
+		return nil, fmt.Errorf("%w: UEPool %s is too small", ErrInvalidConfig, cidr)
+	}
+
+	return &ipPool{
+		base:  binary.BigEndian.Uint32(ip),
+		size:  1<<uint(bits-ones) - 2,
+		inUse: map[uint32]bool{},
+	}, nil
+}
+
+func (p *ipPool) allocate() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i := uint32(0); i < p.size; i++ {
+		offset := (p.next+i)%p.size + 1
+		if p.inUse[offset] {
+			continue
+		}
+		p.inUse[offset] = true
+		p.next = offset % p.size
+
+		ip := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(ip, p.base+offset)
+		return ip.String(), nil
+	}
+	return "", ErrPoolExhausted
+}
+
+func (p *ipPool) release(addr string) {
+	ip := net.ParseIP(addr).To4()
+	if ip == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.inUse, binary.BigEndian.Uint32(ip)-p.base)
+}
@@ -0,0 +1,429 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package emulator
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// SGWConfig is the configuration of SGW.
+type SGWConfig struct {
+	// PGWAddr is the address of the S5/S8-C interface of the PGW.
+	PGWAddr net.Addr
+
+	// S1UIP and S5UIP are the IP addresses put in the F-TEIDs of S1-U and
+	// S5/S8-U interfaces.
+	S1UIP, S5UIP string
+
+	// Timeout is the time to wait for the response. DefaultTimeout is used
+	// if zero.
+	Timeout time.Duration
+}
+
+// SGW is an emulated SGW on S11 and S5/S8-C interfaces.
+//
+// The Create Session Request and Delete Session Request from the MME are
+// relayed to the PGW, and the Modify Bearer Request and Release Access
+// Bearers Request are handled by SGW itself.
+type SGW struct {
+	s11, s5     *v2.Conn
+	cfg         SGWConfig
+	s11IP, s5IP string
+
+	mu   sync.Mutex
+	teid uint32
+	idle map[string]bool
+}
+
+// NewSGW creates a new SGW and registers the handlers to s11 and s5, which
+// should be created with gtpv2.IFTypeS11S4SGWGTPC and
+// gtpv2.IFTypeS5S8SGWGTPC respectively.
+func NewSGW(s11, s5 *v2.Conn, cfg *SGWConfig) (*SGW, error) {
+	if s11 == nil || s5 == nil || cfg == nil || cfg.PGWAddr == nil {
+		return nil, fmt.Errorf("%w: Conns and PGWAddr are required", ErrInvalidConfig)
+	}
+	s11IP, err := hostIP(s11.LocalAddr())
+	if err != nil {
+		return nil, err
+	}
+	s5IP, err := hostIP(s5.LocalAddr())
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SGW{
+		s11:   s11,
+		s5:    s5,
+		cfg:   *cfg,
+		s11IP: s11IP,
+		s5IP:  s5IP,
+		idle:  map[string]bool{},
+	}
+	if s.cfg.Timeout == 0 {
+		s.cfg.Timeout = DefaultTimeout
+	}
+	if s.cfg.S1UIP == "" {
+		s.cfg.S1UIP = s11IP
+	}
+	if s.cfg.S5UIP == "" {
+		s.cfg.S5UIP = s5IP
+	}
+
+	s11.AddHandlers(map[uint8]v2.HandlerFunc{
+		message.MsgTypeCreateSessionRequest:                s.handleCreateSessionRequest,
+		message.MsgTypeDeleteSessionRequest:                s.handleDeleteSessionRequest,
+		message.MsgTypeModifyBearerRequest:                 s.handleModifyBearerRequest,
+		message.MsgTypeReleaseAccessBearersRequest:         s.handleReleaseAccessBearersRequest,
+		message.MsgTypeDownlinkDataNotificationAcknowledge: s.passToSession,
+	})
+	s5.AddHandlers(map[uint8]v2.HandlerFunc{
+		message.MsgTypeCreateSessionResponse: s.passToSession,
+		message.MsgTypeDeleteSessionResponse: s.passToSession,
+	})
+	return s, nil
+}
+
+// IsIdle reports whether the UE has no S1-U tunnel.
+func (s *SGW) IsIdle(imsi string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idle, ok := s.idle[imsi]
+	if !ok {
+		return false, ErrUnknownUE
+	}
+	return idle, nil
+}
+
+// NotifyDownlinkData emulates the arrival of the downlink packets for the
+// UE. If the UE is idle, it sends Downlink Data Notification to the MME and
+// waits for the acknowledgement. It does nothing if the UE is connected.
+//
+// If the MME does not accept it, e.g., the UE does not respond to the
+// paging, it returns *gtpv2.CauseNotOKError.
+//
+// Downlink Data Notification is sent as message.Generic, as the message
+// package does not have the dedicated type for it.
+func (s *SGW) NotifyDownlinkData(imsi string) error {
+	idle, err := s.IsIdle(imsi)
+	if err != nil {
+		return err
+	}
+	if !idle {
+		return nil
+	}
+
+	sess, err := s.s11.GetSessionByIMSI(imsi)
+	if err != nil {
+		return err
+	}
+	mmeTEID, err := sess.GetTEID(v2.IFTypeS11MMEGTPC)
+	if err != nil {
+		return err
+	}
+
+	ddn := message.NewGeneric(
+		message.MsgTypeDownlinkDataNotification, mmeTEID, 0,
+		ie.NewEPSBearerID(sess.GetDefaultBearer().EBI),
+	)
+	seq, err := s.s11.SendMessageTo(ddn, sess.PeerAddr())
+	if err != nil {
+		return err
+	}
+
+	incoming, err := sess.WaitMessage(seq, s.cfg.Timeout)
+	if err != nil {
+		return err
+	}
+	ack, ok := incoming.(*message.Generic)
+	if !ok || ack.MessageType() != message.MsgTypeDownlinkDataNotificationAcknowledge {
+		return &v2.UnexpectedTypeError{Msg: incoming}
+	}
+	return checkCause(ack, findIE(ack.IEs, ie.Cause, 0))
+}
+
+func (s *SGW) allocTEID() uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.teid++
+	if s.teid == 0 {
+		s.teid++
+	}
+	return s.teid
+}
+
+func (s *SGW) setIdle(imsi string, idle bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.idle[imsi] = idle
+}
+
+func (s *SGW) passToSession(c *v2.Conn, senderAddr net.Addr, msg message.Message) error {
+	return passToSession(c, senderAddr, msg, s.cfg.Timeout)
+}
+
+func (s *SGW) handleCreateSessionRequest(c *v2.Conn, mmeAddr net.Addr, msg message.Message) error {
+	req, ok := msg.(*message.CreateSessionRequest)
+	if !ok {
+		return &v2.UnexpectedTypeError{Msg: msg}
+	}
+	if req.IMSI == nil {
+		return &v2.RequiredIEMissingError{Type: ie.IMSI}
+	}
+	if req.SenderFTEIDC == nil {
+		return &v2.RequiredIEMissingError{Type: ie.FullyQualifiedTEID}
+	}
+	if len(req.BearerContextsToBeCreated) == 0 {
+		return &v2.RequiredIEMissingError{Type: ie.BearerContext}
+	}
+	brCtx := req.BearerContextsToBeCreated[0]
+
+	s11Sess, err := c.ParseCreateSession(
+		mmeAddr, req.IMSI, req.MSISDN, req.MEI, req.ServingNetwork,
+		req.RATType, req.SenderFTEIDC, req.APN, brCtx,
+	)
+	if err != nil {
+		return err
+	}
+	s.removeSessions(s11Sess.IMSI)
+
+	ebi := s11Sess.GetDefaultBearer().EBI
+	s5uTEID := s.allocTEID()
+	s5Sess, seq, err := s.s5.CreateSession(
+		s.cfg.PGWAddr,
+		req.IMSI, req.MSISDN, req.MEI, req.ServingNetwork, req.RATType,
+		s.s5.NewSenderFTEID(s.s5IP, ""),
+		req.APN, req.SelectionMode, req.PDNType, req.PAA, req.APNRestriction, req.AMBR,
+		ie.NewBearerContext(
+			ie.NewEPSBearerID(ebi),
+			ie.NewFullyQualifiedTEID(v2.IFTypeS5S8SGWGTPU, s5uTEID, s.cfg.S5UIP, "").WithInstance(2),
+			findChild(brCtx, ie.BearerQoS, 0),
+		),
+	)
+	if err != nil {
+		return err
+	}
+	s5Sess.AddTEID(v2.IFTypeS5S8SGWGTPU, s5uTEID)
+
+	incoming, err := s5Sess.WaitMessage(seq, s.cfg.Timeout)
+	if err != nil {
+		s.s5.RemoveSession(s5Sess)
+		return err
+	}
+	pgwRes, ok := incoming.(*message.CreateSessionResponse)
+	if !ok {
+		s.s5.RemoveSession(s5Sess)
+		return &v2.UnexpectedTypeError{Msg: incoming}
+	}
+
+	mmeTEID, err := s11Sess.GetTEID(v2.IFTypeS11MMEGTPC)
+	if err != nil {
+		s.s5.RemoveSession(s5Sess)
+		return err
+	}
+
+	// relay the rejection from the PGW as it is.
+	if err := checkCause(pgwRes, pgwRes.Cause); err != nil {
+		s.s5.RemoveSession(s5Sess)
+		if pgwRes.Cause == nil {
+			return err
+		}
+		return c.RespondTo(mmeAddr, req, message.NewCreateSessionResponse(mmeTEID, 0, pgwRes.Cause))
+	}
+
+	if pgwRes.PGWS5S8FTEIDC != nil {
+		teid, err := pgwRes.PGWS5S8FTEIDC.TEID()
+		if err != nil {
+			return err
+		}
+		s5Sess.AddTEID(v2.IFTypeS5S8PGWGTPC, teid)
+	}
+	var chargingID *ie.IE
+	if len(pgwRes.BearerContextsCreated) != 0 {
+		pgwBrCtx := pgwRes.BearerContextsCreated[0]
+		if fteid := findChild(pgwBrCtx, ie.FullyQualifiedTEID, 2); fteid != nil {
+			teid, err := fteid.TEID()
+			if err != nil {
+				return err
+			}
+			s5Sess.AddTEID(v2.IFTypeS5S8PGWGTPU, teid)
+		}
+		chargingID = findChild(pgwBrCtx, ie.ChargingID, 0)
+	}
+
+	s11FTEID := c.NewSenderFTEID(s.s11IP, "")
+	s1uTEID := s.allocTEID()
+	s11Sess.AddTEID(v2.IFTypeS1USGWGTPU, s1uTEID)
+	c.RegisterSession(s11FTEID.MustTEID(), s11Sess)
+	if err := s11Sess.Activate(); err != nil {
+		return err
+	}
+	if err := s5Sess.Activate(); err != nil {
+		return err
+	}
+	s.setIdle(s11Sess.IMSI, true)
+
+	return c.RespondTo(mmeAddr, req, message.NewCreateSessionResponse(
+		mmeTEID, 0,
+		ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+		s11FTEID,
+		pgwRes.PGWS5S8FTEIDC,
+		pgwRes.PAA,
+		pgwRes.APNRestriction,
+		ie.NewBearerContext(
+			ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+			ie.NewEPSBearerID(ebi),
+			ie.NewFullyQualifiedTEID(v2.IFTypeS1USGWGTPU, s1uTEID, s.cfg.S1UIP, ""),
+			chargingID,
+		),
+	))
+}
+
+func (s *SGW) handleModifyBearerRequest(c *v2.Conn, mmeAddr net.Addr, msg message.Message) error {
+	req, ok := msg.(*message.ModifyBearerRequest)
+	if !ok {
+		return &v2.UnexpectedTypeError{Msg: msg}
+	}
+	sess, err := c.GetSessionByTEID(req.TEID(), mmeAddr)
+	if err != nil {
+		return err
+	}
+
+	brCtx := req.BearerContextsToBeModified
+	if brCtx == nil {
+		return &v2.RequiredIEMissingError{Type: ie.BearerContext}
+	}
+	ebiIE := findChild(brCtx, ie.EPSBearerID, 0)
+	if ebiIE == nil {
+		return &v2.RequiredIEMissingError{Type: ie.EPSBearerID}
+	}
+	ebi, err := ebiIE.EPSBearerID()
+	if err != nil {
+		return err
+	}
+	br, err := sess.LookupBearerByEBI(ebi)
+	if err != nil {
+		return err
+	}
+
+	if fteid := findChild(brCtx, ie.FullyQualifiedTEID, 0); fteid != nil {
+		teid, err := fteid.TEID()
+		if err != nil {
+			return err
+		}
+		ip, err := fteid.IPAddress()
+		if err != nil {
+			return err
+		}
+		addr, err := net.ResolveUDPAddr("udp", ip+v2.GTPUPort)
+		if err != nil {
+			return err
+		}
+		sess.AddTEID(v2.IFTypeS1UeNodeBGTPU, teid)
+		br.SetOutgoingTEID(teid)
+		br.SetRemoteAddress(addr)
+		s.setIdle(sess.IMSI, false)
+	}
+
+	mmeTEID, err := sess.GetTEID(v2.IFTypeS11MMEGTPC)
+	if err != nil {
+		return err
+	}
+	s1uTEID, err := sess.GetTEID(v2.IFTypeS1USGWGTPU)
+	if err != nil {
+		return err
+	}
+	return c.RespondTo(mmeAddr, req, message.NewModifyBearerResponse(
+		mmeTEID, 0,
+		ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+		ie.NewBearerContext(
+			ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+			ie.NewEPSBearerID(ebi),
+			ie.NewFullyQualifiedTEID(v2.IFTypeS1USGWGTPU, s1uTEID, s.cfg.S1UIP, ""),
+		),
+	))
+}
+
+func (s *SGW) handleReleaseAccessBearersRequest(c *v2.Conn, mmeAddr net.Addr, msg message.Message) error {
+	sess, err := c.GetSessionByTEID(msg.TEID(), mmeAddr)
+	if err != nil {
+		return err
+	}
+	mmeTEID, err := sess.GetTEID(v2.IFTypeS11MMEGTPC)
+	if err != nil {
+		return err
+	}
+
+	s.setIdle(sess.IMSI, true)
+	return c.RespondTo(mmeAddr, msg, message.NewReleaseAccessBearersResponse(
+		mmeTEID, 0, ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+	))
+}
+
+func (s *SGW) handleDeleteSessionRequest(c *v2.Conn, mmeAddr net.Addr, msg message.Message) error {
+	req, ok := msg.(*message.DeleteSessionRequest)
+	if !ok {
+		return &v2.UnexpectedTypeError{Msg: msg}
+	}
+	s11Sess, err := c.GetSessionByTEID(req.TEID(), mmeAddr)
+	if err != nil {
+		return err
+	}
+	mmeTEID, err := s11Sess.GetTEID(v2.IFTypeS11MMEGTPC)
+	if err != nil {
+		return err
+	}
+	defer s.removeSessions(s11Sess.IMSI)
+
+	s5Sess, err := s.s5.GetSessionByIMSI(s11Sess.IMSI)
+	if err != nil {
+		return err
+	}
+	pgwTEID, err := s5Sess.GetTEID(v2.IFTypeS5S8PGWGTPC)
+	if err != nil {
+		return err
+	}
+	seq, err := s.s5.DeleteSession(pgwTEID, s5Sess, req.LinkedEBI)
+	if err != nil {
+		return err
+	}
+
+	incoming, err := s5Sess.WaitMessage(seq, s.cfg.Timeout)
+	if err != nil {
+		return err
+	}
+	pgwRes, ok := incoming.(*message.DeleteSessionResponse)
+	if !ok {
+		return &v2.UnexpectedTypeError{Msg: incoming}
+	}
+	if pgwRes.Cause == nil {
+		return &v2.RequiredIEMissingError{Type: ie.Cause}
+	}
+
+	return c.RespondTo(mmeAddr, req, message.NewDeleteSessionResponse(mmeTEID, 0, pgwRes.Cause))
+}
+
+// removeSessions removes the sessions of the UE on both S11 and S5/S8.
+func (s *SGW) removeSessions(imsi string) {
+	if sess, err := s.s11.GetSessionByIMSI(imsi); err == nil {
+		s.s11.RemoveSession(sess)
+	}
+	if sess, err := s.s5.GetSessionByIMSI(imsi); err == nil {
+		s.s5.RemoveSession(sess)
+	}
+
+	s.mu.Lock()
+	delete(s.idle, imsi)
+	s.mu.Unlock()
+}
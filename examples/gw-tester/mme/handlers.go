@@ -78,8 +78,8 @@ func (m *mme) handleCreateSessionResponse(c *v2.Conn, sgwAddr net.Addr, msg mess
 		return err
 	}
 
-	if brCtxIE := csRspFromSGW.BearerContextsCreated; brCtxIE != nil {
-		for _, childIE := range brCtxIE.ChildIEs {
+	if brCtxIEs := csRspFromSGW.BearerContextsCreated; len(brCtxIEs) != 0 {
+		for _, childIE := range brCtxIEs[0].ChildIEs {
 			switch childIE.Type {
 			case ie.EPSBearerID:
 				bearer.EBI, err = childIE.EPSBearerID()
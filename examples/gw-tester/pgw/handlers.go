@@ -108,8 +108,8 @@ func (p *pgw) handleCreateSessionRequest(c *v2.Conn, sgwAddr net.Addr, msg messa
 
 	var s5sgwuIP string
 	var oteiU uint32
-	if brCtxIE := csReqFromSGW.BearerContextsToBeCreated; brCtxIE != nil {
-		for _, childIE := range brCtxIE.ChildIEs {
+	if brCtxIEs := csReqFromSGW.BearerContextsToBeCreated; len(brCtxIEs) != 0 {
+		for _, childIE := range brCtxIEs[0].ChildIEs {
 			switch childIE.Type {
 			case ie.EPSBearerID:
 				bearer.EBI, err = childIE.EPSBearerID()
@@ -216,8 +216,8 @@ func (s *sgw) handleCreateSessionRequest(s11Conn *v2.Conn, mmeAddr net.Addr, msg
 	csRspFromSGW = csRspFromPGW
 	csRspFromSGW.SenderFTEIDC = s11sgwFTEID
 	csRspFromSGW.SGWFQCSID = ie.NewFullyQualifiedCSID(s.s1uIP, 1).WithInstance(1)
-	csRspFromSGW.BearerContextsCreated.Add(s1usgwFTEID)
-	csRspFromSGW.BearerContextsCreated.Remove(ie.ChargingID, 0)
+	csRspFromSGW.BearerContextsCreated[0].Add(s1usgwFTEID)
+	csRspFromSGW.BearerContextsCreated[0].Remove(ie.ChargingID, 0)
 	csRspFromSGW.SetTEID(s11mmeTEID)
 	csRspFromSGW.SetLength()
 
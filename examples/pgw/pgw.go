@@ -129,8 +129,8 @@ func handleCreateSessionRequest(c *v2.Conn, sgwAddr net.Addr, msg message.Messag
 	}
 
 	var teidOut uint32
-	if brCtxIE := csReqFromSGW.BearerContextsToBeCreated; brCtxIE != nil {
-		for _, childIE := range brCtxIE.ChildIEs {
+	if brCtxIEs := csReqFromSGW.BearerContextsToBeCreated; len(brCtxIEs) != 0 {
+		for _, childIE := range brCtxIEs[0].ChildIEs {
 			switch childIE.Type {
 			case ie.EPSBearerID:
 				bearer.EBI, err = childIE.EPSBearerID()
@@ -213,8 +213,8 @@ func handleCreateSessionRequest(s11Conn *v2.Conn, mmeAddr net.Addr, msg message.
 	csRspFromSGW = csRspFromPGW
 	csRspFromSGW.SenderFTEIDC = senderFTEID
 	csRspFromSGW.SGWFQCSID = ie.NewFullyQualifiedCSID(s1uIP, 1).WithInstance(1)
-	csRspFromSGW.BearerContextsCreated.Add(s1usgwFTEID)
-	csRspFromSGW.BearerContextsCreated.Remove(ie.ChargingID, 0)
+	csRspFromSGW.BearerContextsCreated[0].Add(s1usgwFTEID)
+	csRspFromSGW.BearerContextsCreated[0].Remove(ie.ChargingID, 0)
 	csRspFromSGW.SetTEID(s11mmeTEID)
 	csRspFromSGW.SetLength()
 
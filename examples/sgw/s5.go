@@ -74,8 +74,8 @@ func handleCreateSessionResponse(s5cConn *v2.Conn, pgwAddr net.Addr, msg message
 		return &v2.RequiredIEMissingError{Type: ie.FullyQualifiedTEID}
 	}
 
-	if brCtxIE := csRspFromPGW.BearerContextsCreated; brCtxIE != nil {
-		for _, childIE := range brCtxIE.ChildIEs {
+	if brCtxIEs := csRspFromPGW.BearerContextsCreated; len(brCtxIEs) != 0 {
+		for _, childIE := range brCtxIEs[0].ChildIEs {
 			switch childIE.Type {
 			case ie.Cause:
 				cause, err := childIE.Cause()
@@ -5,12 +5,14 @@ go 1.14
 require (
 	github.com/golang/protobuf v1.4.2
 	github.com/google/go-cmp v0.5.0
+	github.com/google/gopacket v1.1.19
 	github.com/pascaldekloe/goe v0.1.0
 	github.com/pkg/errors v0.9.1
 	github.com/vishvananda/netlink v1.1.0
-	golang.org/x/net v0.0.0-20200202094626-16171245cfb2 // indirect
-	golang.org/x/sys v0.0.0-20200202164722-d101bd2416d5 // indirect
+	golang.org/x/net v0.0.0-20200202094626-16171245cfb2
+	golang.org/x/sys v0.0.0-20200202164722-d101bd2416d5
 	golang.org/x/text v0.3.2 // indirect
 	google.golang.org/genproto v0.0.0-20200210034751-acff78025515 // indirect
 	google.golang.org/grpc v1.30.0
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -8,6 +8,7 @@ import (
 	v0msg "github.com/wmnsk/go-gtp/gtpv0/message"
 	v1msg "github.com/wmnsk/go-gtp/gtpv1/message"
 	v2msg "github.com/wmnsk/go-gtp/gtpv2/message"
+	"github.com/wmnsk/go-gtp/utils"
 )
 
 // Message is an interface that defines all versions of GTP message.
@@ -35,6 +36,19 @@ func Marshal(m Message) ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from a Message instance to b
+// and returns the extended buffer. b is reallocated only when its capacity is
+// not enough, so the buffers can be reused without allocating.
+func AppendTo(b []byte, m Message) ([]byte, error) {
+	l := len(b)
+	b = utils.Grow(b, m.MarshalLen())
+	if err := m.MarshalTo(b[l:]); err != nil {
+		return b[:l], err
+	}
+
+	return b, nil
+}
+
 // Parse decodes given bytes as Message.
 func Parse(b []byte) (Message, error) {
 	if len(b) < 8 {
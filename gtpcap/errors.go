@@ -0,0 +1,25 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpcap
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedVersion indicates that the version of the packet cannot be replayed.
+var ErrUnsupportedVersion = errors.New("unsupported version of GTP to replay")
+
+// NoResponseError indicates that the live peer did not send the message
+// corresponding to the one in the capture within the timeout.
+type NoResponseError struct {
+	Index   int
+	MsgType uint8
+}
+
+// Error returns the index of the packet and the type of message that did not come.
+func (e *NoResponseError) Error() string {
+	return fmt.Sprintf("no message with type %d received for packet #%d", e.MsgType, e.Index)
+}
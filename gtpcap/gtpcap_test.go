@@ -0,0 +1,286 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpcap_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/wmnsk/go-gtp/gtpcap"
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+const (
+	mmeIP = "127.0.0.53"
+	sgwIP = "127.0.0.54"
+)
+
+type udpPacket struct {
+	src, dst         string
+	srcPort, dstPort int
+	payload          []byte
+}
+
+func marshal(t *testing.T, m message.Message) []byte {
+	t.Helper()
+
+	b, err := message.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// capturedSequence returns the packets in the capture, which has Create Session
+// and Modify Bearer exchanged between MME and S-GW, with some noises.
+func capturedSequence(t *testing.T) []udpPacket {
+	return []udpPacket{
+		{mmeIP, sgwIP, 2123, 2123, marshal(t, message.NewCreateSessionRequest(
+			0, 100,
+			ie.NewIMSI("123451234567890"),
+			ie.NewFullyQualifiedTEID(v2.IFTypeS11MMEGTPC, 0x11111111, mmeIP, ""),
+		))},
+		{mmeIP, "127.0.0.1", 12345, 53, []byte{0xde, 0xad, 0xbe, 0xef}},
+		{sgwIP, mmeIP, 2123, 2123, marshal(t, message.NewCreateSessionResponse(
+			0x11111111, 100,
+			ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+			ie.NewFullyQualifiedTEID(v2.IFTypeS11S4SGWGTPC, 0x22222222, sgwIP, ""),
+		))},
+		{sgwIP, "127.0.0.1", 2152, 2152, []byte{0x30, 0xff, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}},
+		{mmeIP, sgwIP, 2123, 2123, marshal(t, message.NewModifyBearerRequest(0x22222222, 101))},
+		{sgwIP, mmeIP, 2123, 2123, marshal(t, message.NewModifyBearerResponse(
+			0x11111111, 101,
+			ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+		))},
+	}
+}
+
+func frames(t *testing.T, pkts []udpPacket) [][]byte {
+	t.Helper()
+
+	var fs [][]byte
+	for _, p := range pkts {
+		eth := &layers.Ethernet{
+			SrcMAC:       net.HardwareAddr{0, 0, 0, 0, 0, 1},
+			DstMAC:       net.HardwareAddr{0, 0, 0, 0, 0, 2},
+			EthernetType: layers.EthernetTypeIPv4,
+		}
+		ip := &layers.IPv4{
+			Version: 4, TTL: 64, Protocol: layers.IPProtocolUDP,
+			SrcIP: net.ParseIP(p.src), DstIP: net.ParseIP(p.dst),
+		}
+		udp := &layers.UDP{SrcPort: layers.UDPPort(p.srcPort), DstPort: layers.UDPPort(p.dstPort)}
+		if err := udp.SetNetworkLayerForChecksum(ip); err != nil {
+			t.Fatal(err)
+		}
+
+		buf := gopacket.NewSerializeBuffer()
+		opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+		if err := gopacket.SerializeLayers(buf, opts, eth, ip, udp, gopacket.Payload(p.payload)); err != nil {
+			t.Fatal(err)
+		}
+		fs = append(fs, buf.Bytes())
+	}
+	return fs
+}
+
+func writePcap(t *testing.T, fs [][]byte) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	w := pcapgo.NewWriter(buf)
+	if err := w.WriteFileHeader(65535, layers.LinkTypeEthernet); err != nil {
+		t.Fatal(err)
+	}
+	for i, f := range fs {
+		ci := gopacket.CaptureInfo{Timestamp: time.Unix(int64(i), 0), CaptureLength: len(f), Length: len(f)}
+		if err := w.WritePacket(ci, f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func writePcapng(t *testing.T, fs [][]byte) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	w, err := pcapgo.NewNgWriter(buf, layers.LinkTypeEthernet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, f := range fs {
+		ci := gopacket.CaptureInfo{Timestamp: time.Unix(int64(i), 0), CaptureLength: len(f), Length: len(f)}
+		if err := w.WritePacket(ci, f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func readAll(t *testing.T, b []byte) []*gtpcap.Packet {
+	t.Helper()
+
+	r, err := gtpcap.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkts, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkts
+}
+
+func TestReader(t *testing.T) {
+	fs := frames(t, capturedSequence(t))
+	cases := []struct {
+		description string
+		file        []byte
+	}{
+		{"pcap", writePcap(t, fs)},
+		{"pcapng", writePcapng(t, fs)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			pkts := readAll(t, c.file)
+			if len(pkts) != 5 {
+				t.Fatalf("wrong number of packets, want: 5, got: %d", len(pkts))
+			}
+
+			first := pkts[0]
+			if !first.Src.IP.Equal(net.ParseIP(mmeIP)) || first.Dst.Port != 2123 {
+				t.Errorf("wrong addresses: %s -> %s", first.Src, first.Dst)
+			}
+			if !first.Timestamp.Equal(time.Unix(0, 0)) {
+				t.Errorf("wrong timestamp: %s", first.Timestamp)
+			}
+			if first.Version() != 2 || !first.IsControlPlane() {
+				t.Errorf("wrong packet: version %d, control plane %v", first.Version(), first.IsControlPlane())
+			}
+			if _, ok := first.Message.(*message.CreateSessionRequest); !ok {
+				t.Errorf("wrong message: %v", first.Message)
+			}
+
+			if pkts[2].IsControlPlane() {
+				t.Error("U-Plane packet is regarded as C-Plane")
+			}
+		})
+	}
+}
+
+func TestReplayer(t *testing.T) {
+	pkts := readAll(t, writePcap(t, frames(t, capturedSequence(t))))
+
+	sgw, err := net.ListenPacket("udp", sgwIP+":2123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sgw.Close()
+
+	errCh := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1500)
+		for i := 0; i < 2; i++ {
+			n, raddr, err := sgw.ReadFrom(buf)
+			if err != nil {
+				errCh <- err
+				return
+			}
+			msg, err := message.Parse(buf[:n])
+			if err != nil {
+				errCh <- err
+				return
+			}
+
+			var res message.Message
+			switch m := msg.(type) {
+			case *message.CreateSessionRequest:
+				if m.Sequence() == 100 {
+					t.Error("sequence is not rewritten")
+				}
+				res = message.NewCreateSessionResponse(
+					m.SenderFTEIDC.MustTEID(), m.Sequence(),
+					ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+					ie.NewFullyQualifiedTEID(v2.IFTypeS11S4SGWGTPC, 0x99999999, sgwIP, ""),
+				)
+			case *message.ModifyBearerRequest:
+				if m.TEID() != 0x99999999 {
+					t.Errorf("TEID is not rewritten: %#x", m.TEID())
+				}
+				res = message.NewModifyBearerResponse(
+					0x11111111, m.Sequence(),
+					ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+				)
+			default:
+				t.Errorf("unexpected message: %v", msg)
+				continue
+			}
+
+			if _, err := sgw.WriteTo(marshal(t, res), raddr); err != nil {
+				errCh <- err
+				return
+			}
+		}
+		errCh <- nil
+	}()
+
+	mme, err := net.ListenPacket("udp", mmeIP+":2123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mme.Close()
+
+	r := gtpcap.NewReplayer(mme, sgw.LocalAddr(), net.ParseIP(mmeIP))
+	r.Timeout = 500 * time.Millisecond
+	if err := r.Replay(context.Background(), pkts); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+
+	if teid, ok := r.TEID(0x22222222); !ok || teid != 0x99999999 {
+		t.Errorf("wrong TEID learned: %#x, %v", teid, ok)
+	}
+}
+
+func TestReplayerNoResponse(t *testing.T) {
+	pkts := readAll(t, writePcap(t, frames(t, capturedSequence(t))))
+
+	mme, err := net.ListenPacket("udp", mmeIP+":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mme.Close()
+
+	raddr, err := net.ResolveUDPAddr("udp", "127.0.0.55:2123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := gtpcap.NewReplayer(mme, raddr, net.ParseIP(mmeIP))
+	r.Timeout = 100 * time.Millisecond
+
+	err = r.Replay(context.Background(), pkts)
+	nerr, ok := err.(*gtpcap.NoResponseError)
+	if !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nerr.Index != 1 || nerr.MsgType != message.MsgTypeCreateSessionResponse {
+		t.Errorf("wrong error: %v", nerr)
+	}
+}
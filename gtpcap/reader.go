@@ -0,0 +1,185 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Package gtpcap provides the utilities to read GTP packets from pcap/pcapng
+// files and to replay the control-plane sequence in them against a live peer.
+package gtpcap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/wmnsk/go-gtp"
+)
+
+// Well-known UDP ports of GTP.
+const (
+	PortGTPC  = 2123
+	PortGTPU  = 2152
+	PortGTPv0 = 3386
+)
+
+// pcapngMagic is the block type of Section Header Block, which comes first in pcapng.
+const pcapngMagic = 0x0a0d0d0a
+
+// Packet is a GTP packet extracted from a capture file.
+type Packet struct {
+	Timestamp time.Time
+	Src, Dst  *net.UDPAddr
+
+	// Data is the payload of UDP, which is the whole GTP message.
+	Data []byte
+
+	// Message is the message decoded from Data, which is nil if it failed to decode.
+	Message gtp.Message
+}
+
+// Version returns the GTP version in the header of the packet.
+func (p *Packet) Version() int {
+	if len(p.Data) < 1 {
+		return -1
+	}
+	return int(p.Data[0] >> 5)
+}
+
+// IsControlPlane reports whether the packet is sent from or to the port of GTPv1-C/GTPv2-C.
+func (p *Packet) IsControlPlane() bool {
+	return p.Src.Port == PortGTPC || p.Dst.Port == PortGTPC
+}
+
+type packetDataSource interface {
+	ReadPacketData() ([]byte, gopacket.CaptureInfo, error)
+}
+
+// Reader reads GTP packets from a pcap or pcapng file.
+//
+// The UDP packets sent from or to the ports in Ports are regarded as GTP. Ports
+// are the well-known ports of all the versions of GTP by default.
+type Reader struct {
+	Ports []int
+
+	src       packetDataSource
+	linkTypes func(ci gopacket.CaptureInfo) layers.LinkType
+}
+
+// NewReader creates a new Reader that reads from r.
+// The format of the file, pcap or pcapng, is detected automatically.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(4)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &Reader{Ports: []int{PortGTPC, PortGTPU, PortGTPv0}}
+	if binary.BigEndian.Uint32(magic) == pcapngMagic {
+		ng, err := pcapgo.NewNgReader(br, pcapgo.DefaultNgReaderOptions)
+		if err != nil {
+			return nil, err
+		}
+		reader.src = ng
+		reader.linkTypes = func(ci gopacket.CaptureInfo) layers.LinkType {
+			intf, err := ng.Interface(ci.InterfaceIndex)
+			if err != nil {
+				return ng.LinkType()
+			}
+			return intf.LinkType
+		}
+		return reader, nil
+	}
+
+	p, err := pcapgo.NewReader(br)
+	if err != nil {
+		return nil, err
+	}
+	reader.src = p
+	reader.linkTypes = func(gopacket.CaptureInfo) layers.LinkType {
+		return p.LinkType()
+	}
+	return reader, nil
+}
+
+// Next returns the next GTP packet in the file, skipping the others.
+// It returns io.EOF when there are no more packets.
+func (r *Reader) Next() (*Packet, error) {
+	for {
+		data, ci, err := r.src.ReadPacketData()
+		if err != nil {
+			return nil, err
+		}
+
+		pkt := gopacket.NewPacket(data, r.linkTypes(ci), gopacket.DecodeOptions{Lazy: true, NoCopy: true})
+		udp, ok := pkt.Layer(layers.LayerTypeUDP).(*layers.UDP)
+		if !ok || !(r.isGTPPort(int(udp.SrcPort)) || r.isGTPPort(int(udp.DstPort))) {
+			continue
+		}
+
+		var srcIP, dstIP net.IP
+		switch nw := pkt.NetworkLayer().(type) {
+		case *layers.IPv4:
+			srcIP, dstIP = nw.SrcIP, nw.DstIP
+		case *layers.IPv6:
+			srcIP, dstIP = nw.SrcIP, nw.DstIP
+		default:
+			continue
+		}
+
+		p := &Packet{
+			Timestamp: ci.Timestamp,
+			Src:       &net.UDPAddr{IP: srcIP, Port: int(udp.SrcPort)},
+			Dst:       &net.UDPAddr{IP: dstIP, Port: int(udp.DstPort)},
+			Data:      udp.Payload,
+		}
+		if msg, err := gtp.Parse(p.Data); err == nil {
+			p.Message = msg
+		}
+		return p, nil
+	}
+}
+
+// ReadAll reads all the GTP packets in the file.
+func (r *Reader) ReadAll() ([]*Packet, error) {
+	var pkts []*Packet
+	for {
+		p, err := r.Next()
+		if err != nil {
+			if err == io.EOF {
+				return pkts, nil
+			}
+			return pkts, err
+		}
+		pkts = append(pkts, p)
+	}
+}
+
+// ReadFile reads all the GTP packets in the pcap or pcapng file at path.
+func ReadFile(path string) ([]*Packet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	return r.ReadAll()
+}
+
+func (r *Reader) isGTPPort(port int) bool {
+	for _, p := range r.Ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,301 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpcap
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	v1ie "github.com/wmnsk/go-gtp/gtpv1/ie"
+	v1msg "github.com/wmnsk/go-gtp/gtpv1/message"
+	v2ie "github.com/wmnsk/go-gtp/gtpv2/ie"
+	v2msg "github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// Replayer replays the GTPv1-C/GTPv2-C sequence in the capture against a live peer.
+//
+// The messages sent from Local in the capture are sent to the peer, and the ones
+// sent to Local are awaited from the peer. The sequence numbers of the requests
+// sent are newly allocated, and the ones of the responses are the same as the
+// requests received from the peer. The TEIDs in the header of the messages sent
+// are rewritten with the ones the peer allocated, which are learned from the
+// TEID IEs in the messages received by comparing them with the ones in the capture.
+type Replayer struct {
+	// Local is the IP address of the node to be played in the capture.
+	Local net.IP
+	// Timeout is the time to wait for each message from the peer.
+	Timeout time.Duration
+
+	conn net.PacketConn
+	peer net.Addr
+
+	mu       sync.Mutex
+	seq      uint32
+	teidMap  map[uint32]uint32
+	ourSeqs  map[uint32]uint32
+	peerSeqs map[uint32]uint32
+}
+
+// NewReplayer creates a new Replayer that sends the messages to peer with conn,
+// playing the node with local IP address in the capture.
+func NewReplayer(conn net.PacketConn, peer net.Addr, local net.IP) *Replayer {
+	return &Replayer{
+		Local:    local,
+		Timeout:  3 * time.Second,
+		conn:     conn,
+		peer:     peer,
+		seq:      1,
+		teidMap:  map[uint32]uint32{},
+		ourSeqs:  map[uint32]uint32{},
+		peerSeqs: map[uint32]uint32{},
+	}
+}
+
+// TEID returns the TEID allocated by the live peer which corresponds to the
+// one in the capture.
+func (r *Replayer) TEID(captured uint32) (uint32, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	teid, ok := r.teidMap[captured]
+	return teid, ok
+}
+
+// Replay replays the control-plane packets in pkts in order.
+//
+// The packets that are neither from nor to Local are ignored, as well as the ones
+// of user plane. It returns *NoResponseError if the peer does not send the message
+// expected within Timeout.
+func (r *Replayer) Replay(ctx context.Context, pkts []*Packet) error {
+	for i, p := range pkts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !p.IsControlPlane() {
+			continue
+		}
+
+		switch {
+		case p.Src.IP.Equal(r.Local):
+			if err := r.send(p); err != nil {
+				return err
+			}
+		case p.Dst.IP.Equal(r.Local):
+			if err := r.receive(ctx, i, p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Replayer) send(p *Packet) error {
+	h, err := parseHeader(p.Data)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	if teid, ok := r.teidMap[h.teid]; ok && h.hasTEID {
+		h.teid = teid
+	}
+	if seq, ok := r.peerSeqs[h.seq]; ok {
+		delete(r.peerSeqs, h.seq)
+		h.seq = seq
+	} else {
+		seq := r.seq & h.seqMask()
+		r.seq++
+		r.ourSeqs[h.seq] = seq
+		h.seq = seq
+	}
+	r.mu.Unlock()
+
+	b, err := h.rewrite(p.Data)
+	if err != nil {
+		return err
+	}
+	_, err = r.conn.WriteTo(b, r.peer)
+	return err
+}
+
+func (r *Replayer) receive(ctx context.Context, index int, p *Packet) error {
+	want, err := parseHeader(p.Data)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	seq, isResponse := r.ourSeqs[want.seq]
+	r.mu.Unlock()
+
+	deadline := time.Now().Add(r.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := r.conn.SetReadDeadline(deadline); err != nil {
+		return err
+	}
+	defer func() { _ = r.conn.SetReadDeadline(time.Time{}) }()
+
+	buf := make([]byte, 1600)
+	for {
+		n, _, err := r.conn.ReadFrom(buf)
+		if err != nil {
+			if nerr, ok := err.(net.Error); ok && nerr.Timeout() {
+				return &NoResponseError{Index: index, MsgType: want.msgType}
+			}
+			return err
+		}
+
+		got, err := parseHeader(buf[:n])
+		if err != nil || got.version != want.version || got.msgType != want.msgType {
+			continue
+		}
+		if isResponse && got.seq != seq {
+			continue
+		}
+
+		r.mu.Lock()
+		if isResponse {
+			delete(r.ourSeqs, want.seq)
+		} else {
+			r.peerSeqs[want.seq] = got.seq
+		}
+		r.learnTEIDs(p.Data, buf[:n])
+		r.mu.Unlock()
+		return nil
+	}
+}
+
+// learnTEIDs pairs the TEIDs in the message in the capture with the ones in
+// the message received, in the order of appearance.
+func (r *Replayer) learnTEIDs(captured, received []byte) {
+	want, got := teids(captured), teids(received)
+	for i := 0; i < len(want) && i < len(got); i++ {
+		if want[i] == 0 {
+			continue
+		}
+		r.teidMap[want[i]] = got[i]
+	}
+}
+
+// header is the fields in GTPv1/GTPv2 header that are rewritten in replaying.
+type header struct {
+	version int
+	msgType uint8
+	hasTEID bool
+	teid    uint32
+	seq     uint32
+	payload []byte
+}
+
+func parseHeader(b []byte) (*header, error) {
+	if len(b) < 1 {
+		return nil, v2msg.ErrTooShortToParse
+	}
+
+	switch b[0] >> 5 {
+	case 1:
+		h, err := v1msg.ParseHeader(b)
+		if err != nil {
+			return nil, err
+		}
+		return &header{
+			version: 1, msgType: h.Type, hasTEID: true, teid: h.TEID,
+			seq: uint32(h.SequenceNumber), payload: h.Payload,
+		}, nil
+	case 2:
+		h, err := v2msg.ParseHeader(b)
+		if err != nil {
+			return nil, err
+		}
+		return &header{
+			version: 2, msgType: h.Type, hasTEID: h.HasTEID(), teid: h.TEID,
+			seq: h.SequenceNumber, payload: h.Payload,
+		}, nil
+	default:
+		return nil, ErrUnsupportedVersion
+	}
+}
+
+func (h *header) seqMask() uint32 {
+	if h.version == 1 {
+		return 0xffff
+	}
+	return 0xffffff
+}
+
+// rewrite returns a copy of b with the TEID and sequence number in h.
+func (h *header) rewrite(b []byte) ([]byte, error) {
+	switch h.version {
+	case 1:
+		v1h, err := v1msg.ParseHeader(b)
+		if err != nil {
+			return nil, err
+		}
+		v1h.SetTEID(h.teid)
+		v1h.SetSequenceNumber(uint16(h.seq))
+		return v1h.Marshal()
+	case 2:
+		v2h, err := v2msg.ParseHeader(b)
+		if err != nil {
+			return nil, err
+		}
+		if v2h.HasTEID() {
+			v2h.SetTEID(h.teid)
+		}
+		v2h.SetSequenceNumber(h.seq)
+		return v2h.Marshal()
+	default:
+		return nil, ErrUnsupportedVersion
+	}
+}
+
+// teids returns the TEIDs in the TEID IEs of the message, including the ones
+// in grouped IEs.
+func teids(b []byte) []uint32 {
+	h, err := parseHeader(b)
+	if err != nil {
+		return nil
+	}
+
+	var ts []uint32
+	switch h.version {
+	case 1:
+		ies, err := v1ie.ParseMultiIEs(h.payload)
+		if err != nil {
+			return nil
+		}
+		for _, i := range ies {
+			if teid, err := i.TEID(); err == nil {
+				ts = append(ts, teid)
+			}
+		}
+	case 2:
+		ies, err := v2ie.ParseMultiIEs(h.payload)
+		if err != nil {
+			return nil
+		}
+		var walk func(ies []*v2ie.IE)
+		walk = func(ies []*v2ie.IE) {
+			for _, i := range ies {
+				if i.IsGrouped() {
+					walk(i.ChildIEs)
+					continue
+				}
+				if i.Type != v2ie.FullyQualifiedTEID {
+					continue
+				}
+				if teid, err := i.TEID(); err == nil {
+					ts = append(ts, teid)
+				}
+			}
+		}
+		walk(ies)
+	}
+	return ts
+}
@@ -0,0 +1,185 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpcap
+
+import (
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/wmnsk/go-gtp"
+)
+
+// Hook is called with every packet sent and received on the Conn it is set to.
+//
+// It is called synchronously in sending and receiving, so it should not block.
+// The Packet given can be retained, as it has the copy of the data.
+type Hook func(p *Packet)
+
+// NewPacket creates a new Packet sent from src to dst at the current time,
+// with the copy of data and the message decoded from it.
+//
+// The addresses are converted to *net.UDPAddr, and they are unspecified ones
+// if failed to convert, e.g., the ones of in-memory net.PacketConn.
+func NewPacket(data []byte, src, dst net.Addr) *Packet {
+	p := &Packet{
+		Timestamp: time.Now(),
+		Src:       udpAddr(src),
+		Dst:       udpAddr(dst),
+		Data:      make([]byte, len(data)),
+	}
+	copy(p.Data, data)
+
+	if msg, err := gtp.Parse(p.Data); err == nil {
+		p.Message = msg
+	}
+	return p
+}
+
+func udpAddr(addr net.Addr) *net.UDPAddr {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a
+	case nil:
+		return &net.UDPAddr{IP: net.IPv4zero}
+	}
+
+	a, err := net.ResolveUDPAddr("udp", addr.String())
+	if err != nil {
+		return &net.UDPAddr{IP: net.IPv4zero}
+	}
+	return a
+}
+
+// Writer writes Packets to a pcapng file, with IP and UDP headers built from
+// the addresses of the Packets.
+//
+// Writer is safe for concurrent use, so that the Hook returned by Hook can be
+// set to multiple Conns.
+type Writer struct {
+	mu     sync.Mutex
+	w      *pcapgo.NgWriter
+	closer io.Closer
+	err    error
+}
+
+// NewWriter creates a new Writer that writes to w in pcapng format.
+// Flush or Close should be called to write out the buffered packets.
+func NewWriter(w io.Writer) (*Writer, error) {
+	ng, err := pcapgo.NewNgWriter(w, layers.LinkTypeRaw)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{w: ng}, nil
+}
+
+// CreateFile creates the pcapng file at path and returns the Writer to it.
+// The file is closed with Close.
+func CreateFile(path string) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := NewWriter(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	w.closer = f
+	return w, nil
+}
+
+// WritePacket writes a Packet.
+func (w *Writer) WritePacket(p *Packet) error {
+	b, err := serialize(p)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	ci := gopacket.CaptureInfo{Timestamp: p.Timestamp, CaptureLength: len(b), Length: len(b)}
+	return w.w.WritePacket(ci, b)
+}
+
+// Hook returns the Hook that writes the packets with the Writer.
+// The first error in writing is returned by Flush or Close.
+func (w *Writer) Hook() Hook {
+	return func(p *Packet) {
+		if err := w.WritePacket(p); err != nil {
+			w.mu.Lock()
+			if w.err == nil {
+				w.err = err
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+// Flush writes out the buffered packets.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	return w.err
+}
+
+// Close flushes the Writer and closes the file if it is created with CreateFile.
+func (w *Writer) Close() error {
+	err := w.Flush()
+	if w.closer != nil {
+		if cerr := w.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// serialize builds the IP packet that contains the UDP datagram of the Packet.
+func serialize(p *Packet) ([]byte, error) {
+	udp := &layers.UDP{SrcPort: layers.UDPPort(p.Src.Port), DstPort: layers.UDPPort(p.Dst.Port)}
+
+	var ip gopacket.NetworkLayer
+	if p.Src.IP.To4() != nil || p.Dst.IP.To4() != nil {
+		ip = &layers.IPv4{
+			Version: 4, TTL: 64, Protocol: layers.IPProtocolUDP,
+			SrcIP: ipOrZero(p.Src.IP.To4(), net.IPv4zero), DstIP: ipOrZero(p.Dst.IP.To4(), net.IPv4zero),
+		}
+	} else {
+		ip = &layers.IPv6{
+			Version: 6, HopLimit: 64, NextHeader: layers.IPProtocolUDP,
+			SrcIP: ipOrZero(p.Src.IP, net.IPv6zero), DstIP: ipOrZero(p.Dst.IP, net.IPv6zero),
+		}
+	}
+	if err := udp.SetNetworkLayerForChecksum(ip); err != nil {
+		return nil, err
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if err := gopacket.SerializeLayers(
+		buf, opts, ip.(gopacket.SerializableLayer), udp, gopacket.Payload(p.Data),
+	); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func ipOrZero(ip, zero net.IP) net.IP {
+	if ip == nil {
+		return zero
+	}
+	return ip
+}
@@ -0,0 +1,10 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Package gtpkernel keeps the tunnels in Linux Kernel GTP-U in sync with the
+// Sessions managed by gtpv2.Conn.
+//
+// The tunnels are programmed through a TunnelProgrammer, which is satisfied by
+// *gtpv1.UPlaneConn on Linux after EnableKernelGTP is called on it.
+package gtpkernel
@@ -0,0 +1,144 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpkernel
+
+import (
+	"fmt"
+	"net"
+
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// TunnelProgrammer adds and deletes the tunnels in Kernel GTP-U.
+//
+// *gtpv1.UPlaneConn implements this on Linux.
+type TunnelProgrammer interface {
+	AddTunnelOverride(peerIP, msIP net.IP, otei, itei uint32) error
+	DelTunnelByITEI(itei uint32) error
+}
+
+// TunnelSync keeps the tunnels in Kernel GTP-U in sync with the Bearers of the
+// Sessions on a gtpv2.Conn.
+//
+// A tunnel is added for each Bearer that has the subscriber's IP, the remote
+// address and the TEIDs when the Session is activated, and deleted when the Bearer
+// or the Session is removed. The routing entries to the GTP device are not managed.
+type TunnelSync struct {
+	tp TunnelProgrammer
+
+	// OnError is called with the errors in updating the tunnels from the hooks
+	// returned by Hooks. The errors are dropped if nil.
+	OnError func(sess *v2.Session, err error)
+}
+
+// NewTunnelSync creates a new TunnelSync that updates the tunnels with tp.
+func NewTunnelSync(tp TunnelProgrammer) *TunnelSync {
+	return &TunnelSync{tp: tp}
+}
+
+// AddBearer adds the tunnel for the Bearer to Kernel GTP-U, overriding the one
+// with the same subscriber's IP or incoming TEID if exists.
+func (k *TunnelSync) AddBearer(br *v2.Bearer) error {
+	msIP := net.ParseIP(br.SubscriberIP)
+	if msIP == nil {
+		return fmt.Errorf("invalid subscriber's IP in Bearer %d: %q", br.EBI, br.SubscriberIP)
+	}
+
+	raddr, ok := br.RemoteAddress().(*net.UDPAddr)
+	if !ok || raddr == nil {
+		return fmt.Errorf("no remote address in Bearer %d", br.EBI)
+	}
+
+	if br.IncomingTEID() == 0 || br.OutgoingTEID() == 0 {
+		return fmt.Errorf("no TEIDs in Bearer %d", br.EBI)
+	}
+
+	return k.tp.AddTunnelOverride(raddr.IP, msIP, br.OutgoingTEID(), br.IncomingTEID())
+}
+
+// RemoveBearer deletes the tunnel for the Bearer from Kernel GTP-U.
+func (k *TunnelSync) RemoveBearer(br *v2.Bearer) error {
+	return k.tp.DelTunnelByITEI(br.IncomingTEID())
+}
+
+// AddSession adds the tunnels for all the Bearers in the Session to Kernel GTP-U.
+// It tries all the Bearers even if some of them fail, and returns the first error.
+func (k *TunnelSync) AddSession(sess *v2.Session) error {
+	var err error
+	for _, br := range sess.Bearers() {
+		if e := k.AddBearer(br); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// RemoveSession deletes the tunnels for all the Bearers in the Session from Kernel
+// GTP-U. It tries all the Bearers even if some of them fail, and returns the first error.
+func (k *TunnelSync) RemoveSession(sess *v2.Session) error {
+	var err error
+	for _, br := range sess.Bearers() {
+		if e := k.RemoveBearer(br); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Hooks returns the LifecycleHooks that call AddSession, RemoveSession, AddBearer
+// and RemoveBearer on the events, which should be set to Conn with SetLifecycleHooks.
+//
+// The hooks in next, if given, are called after the tunnels are updated. The
+// errors in updating the tunnels are passed to OnError, if set, and do not prevent next
+// from being called.
+//
+// The Bearers added to a Session are added to Kernel GTP-U only if the Session is
+// active, as the TEIDs are not known until then in most cases. The tunnels of an
+// expired Session are deleted on OnSessionDeleted, which follows OnSessionExpired.
+func (k *TunnelSync) Hooks(next *v2.LifecycleHooks) *v2.LifecycleHooks {
+	if next == nil {
+		next = &v2.LifecycleHooks{}
+	}
+
+	sessionHook := func(fn func(*v2.Session) error, nextFn v2.SessionHookFunc) v2.SessionHookFunc {
+		return func(c *v2.Conn, sess *v2.Session, msg message.Message) {
+			if err := fn(sess); err != nil {
+				k.handleError(sess, fmt.Errorf("failed to update tunnels in Kernel GTP-U for Session %s: %w", sess.IMSI, err))
+			}
+			if nextFn != nil {
+				nextFn(c, sess, msg)
+			}
+		}
+	}
+	bearerHook := func(fn func(*v2.Bearer) error, activeOnly bool, nextFn v2.BearerHookFunc) v2.BearerHookFunc {
+		return func(c *v2.Conn, sess *v2.Session, br *v2.Bearer, msg message.Message) {
+			if !activeOnly || sess.IsActive() {
+				if err := fn(br); err != nil {
+					k.handleError(sess, fmt.Errorf("failed to update tunnel in Kernel GTP-U for Bearer %d of Session %s: %w", br.EBI, sess.IMSI, err))
+				}
+			}
+			if nextFn != nil {
+				nextFn(c, sess, br, msg)
+			}
+		}
+	}
+
+	return &v2.LifecycleHooks{
+		OnSessionCreated:   next.OnSessionCreated,
+		OnSessionActivated: sessionHook(k.AddSession, next.OnSessionActivated),
+		OnSessionDeleted:   sessionHook(k.RemoveSession, next.OnSessionDeleted),
+		OnSessionExpired:   next.OnSessionExpired,
+		OnBearerAdded:      bearerHook(k.AddBearer, true, next.OnBearerAdded),
+		OnBearerModified:   bearerHook(k.AddBearer, true, next.OnBearerModified),
+		OnBearerRemoved:    bearerHook(k.RemoveBearer, false, next.OnBearerRemoved),
+	}
+}
+
+func (k *TunnelSync) handleError(sess *v2.Session, err error) {
+	if k.OnError != nil {
+		k.OnError(sess, err)
+	}
+}
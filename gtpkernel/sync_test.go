@@ -0,0 +1,185 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpkernel_test
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/wmnsk/go-gtp/gtpkernel"
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// fakeProgrammer records the tunnels added and deleted instead of programming the Kernel.
+type fakeProgrammer struct {
+	mu      sync.Mutex
+	tunnels map[uint32]string
+	calls   []string
+}
+
+func newFakeProgrammer() *fakeProgrammer {
+	return &fakeProgrammer{tunnels: map[uint32]string{}}
+}
+
+func (f *fakeProgrammer) AddTunnelOverride(peerIP, msIP net.IP, otei, itei uint32) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.tunnels[itei] = msIP.String()
+	f.calls = append(f.calls, "add "+msIP.String())
+	return nil
+}
+
+func (f *fakeProgrammer) DelTunnelByITEI(itei uint32) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	msIP, ok := f.tunnels[itei]
+	if !ok {
+		return errors.New("no such tunnel")
+	}
+	delete(f.tunnels, itei)
+	f.calls = append(f.calls, "del "+msIP)
+	return nil
+}
+
+func (f *fakeProgrammer) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string{}, f.calls...)
+}
+
+func newBearer(ebi uint8, msIP string, itei, otei uint32) *v2.Bearer {
+	br := v2.NewBearer(ebi, "", &v2.QoSProfile{})
+	br.SubscriberIP = msIP
+	br.SetRemoteAddress(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 2), Port: 2152})
+	br.SetIncomingTEID(itei)
+	br.SetOutgoingTEID(otei)
+	return br
+}
+
+func TestTunnelSyncHooks(t *testing.T) {
+	laddr, err := net.ResolveUDPAddr("udp", "127.0.0.1"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fp := newFakeProgrammer()
+	ts := gtpkernel.NewTunnelSync(fp)
+	var errs []error
+	ts.OnError = func(sess *v2.Session, err error) {
+		errs = append(errs, err)
+	}
+
+	var nextCalled []string
+	conn := v2.NewConn(laddr, v2.IFTypeS5S8PGWGTPC, 0)
+	conn.SetLifecycleHooks(ts.Hooks(&v2.LifecycleHooks{
+		OnSessionActivated: func(c *v2.Conn, sess *v2.Session, msg message.Message) {
+			nextCalled = append(nextCalled, "activated")
+		},
+		OnSessionDeleted: func(c *v2.Conn, sess *v2.Session, msg message.Message) {
+			nextCalled = append(nextCalled, "deleted")
+		},
+		OnBearerRemoved: func(c *v2.Conn, sess *v2.Session, br *v2.Bearer, msg message.Message) {
+			nextCalled = append(nextCalled, "bearer removed")
+		},
+	}))
+
+	sess := v2.NewSession(laddr, &v2.Subscriber{IMSI: "123451234567890", Location: &v2.Location{}})
+	sess.SetDefaultBearer(newBearer(5, "10.0.0.1", 0x11111111, 0x22222222))
+	conn.RegisterSession(0x11111111, sess)
+
+	// not added before the Session is activated.
+	sess.AddBearer("dedicated1", newBearer(6, "10.0.0.2", 0x11111112, 0x22222223))
+	if err := sess.Activate(); err != nil {
+		t.Fatal(err)
+	}
+	sess.AddBearer("dedicated2", newBearer(7, "10.0.0.3", 0x11111113, 0x22222224))
+	sess.RemoveBearer("dedicated2")
+	conn.RemoveSession(sess)
+
+	calls := fp.Calls()
+	if got, want := len(calls), 6; got != want {
+		t.Fatalf("wrong number of calls. want: %d, got: %d: %v", want, got, calls)
+	}
+	for _, c := range calls[:2] {
+		if c != "add 10.0.0.1" && c != "add 10.0.0.2" {
+			t.Errorf("unexpected call on activation: %s", c)
+		}
+	}
+	if diff := cmp.Diff([]string{"add 10.0.0.3", "del 10.0.0.3"}, calls[2:4]); diff != "" {
+		t.Error(diff)
+	}
+	if got := len(fp.tunnels); got != 0 {
+		t.Errorf("tunnels left after the Session is removed: %v", fp.tunnels)
+	}
+	if len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if diff := cmp.Diff([]string{"activated", "bearer removed", "deleted"}, nextCalled); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestTunnelSyncExpiry(t *testing.T) {
+	laddr, err := net.ResolveUDPAddr("udp", "127.0.0.1"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fp := newFakeProgrammer()
+	ts := gtpkernel.NewTunnelSync(fp)
+	errCh := make(chan error, 1)
+	ts.OnError = func(sess *v2.Session, err error) {
+		errCh <- err
+	}
+
+	expiredCh := make(chan struct{}, 1)
+	deletedCh := make(chan struct{}, 1)
+	conn := v2.NewConn(laddr, v2.IFTypeS5S8PGWGTPC, 0)
+	conn.SetLifecycleHooks(ts.Hooks(&v2.LifecycleHooks{
+		OnSessionExpired: func(c *v2.Conn, sess *v2.Session, msg message.Message) {
+			expiredCh <- struct{}{}
+		},
+		OnSessionDeleted: func(c *v2.Conn, sess *v2.Session, msg message.Message) {
+			deletedCh <- struct{}{}
+		},
+	}))
+
+	sess := v2.NewSession(laddr, &v2.Subscriber{IMSI: "123451234567890", Location: &v2.Location{}})
+	sess.SetDefaultBearer(newBearer(5, "10.0.0.1", 0x11111111, 0x22222222))
+	if err := sess.Activate(); err != nil {
+		t.Fatal(err)
+	}
+	conn.RegisterSession(0x11111111, sess)
+
+	conn.EnableSessionExpiry(10*time.Millisecond, 10*time.Millisecond)
+	defer conn.DisableSessionExpiry()
+
+	for _, ch := range []chan struct{}{expiredCh, deletedCh} {
+		select {
+		case <-ch:
+		case err := <-errCh:
+			t.Fatal(err)
+		case <-time.After(3 * time.Second):
+			t.Fatal("timed out waiting for the Session to expire")
+		}
+	}
+
+	if diff := cmp.Diff([]string{"add 10.0.0.1", "del 10.0.0.1"}, fp.Calls()); diff != "" {
+		t.Error(diff)
+	}
+	select {
+	case err := <-errCh:
+		t.Errorf("unexpected error: %v", err)
+	default:
+	}
+}
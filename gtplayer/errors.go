@@ -0,0 +1,15 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtplayer
+
+import "errors"
+
+var (
+	// ErrTruncated indicates that the packet is shorter than the length in the header.
+	ErrTruncated = errors.New("packet is truncated")
+
+	// ErrNothingToSerialize indicates that the layer has neither Header nor Message.
+	ErrNothingToSerialize = errors.New("nothing to serialize")
+)
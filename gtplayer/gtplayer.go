@@ -0,0 +1,66 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Package gtplayer provides the gopacket layers of GTPv1 and GTPv2, which use
+// the encoders/decoders in this module instead of the GTP layer built in gopacket.
+//
+// The layers implement gopacket.DecodingLayer and gopacket.SerializableLayer,
+// so that they can be used with gopacket.DecodingLayerParser and gopacket.SerializeLayers.
+// The payload of T-PDU is chained to IPv4 or IPv6 layer by the version in it.
+//
+// RegisterUDPPorts replaces the layers of the well-known GTP ports in gopacket/layers
+// to decode the packets with gopacket.NewPacket.
+package gtplayer
+
+import (
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// Layer types of GTP.
+//
+// The decoder of LayerTypeGTPv1 falls through to GTPv2 if the version in the
+// header is 2, as the port 2123 is shared by GTPv1-C and GTPv2-C.
+var (
+	LayerTypeGTPv1 = gopacket.RegisterLayerType(2152, gopacket.LayerTypeMetadata{Name: "GTPv1", Decoder: gopacket.DecodeFunc(decodeGTPv1)})
+	LayerTypeGTPv2 = gopacket.RegisterLayerType(2123, gopacket.LayerTypeMetadata{Name: "GTPv2", Decoder: gopacket.DecodeFunc(decodeGTPv2)})
+)
+
+// Well-known UDP ports of GTP.
+const (
+	UDPPortGTPC layers.UDPPort = 2123
+	UDPPortGTPU layers.UDPPort = 2152
+)
+
+// RegisterUDPPorts makes gopacket decode the packets on the UDP ports of GTP-C
+// and GTP-U with the layers in this package.
+//
+// This affects all the packets decoded with gopacket/layers in the process.
+func RegisterUDPPorts() {
+	layers.RegisterUDPPortLayerType(UDPPortGTPC, LayerTypeGTPv1)
+	layers.RegisterUDPPortLayerType(UDPPortGTPU, LayerTypeGTPv1)
+}
+
+// version returns the version in the first octet of GTP header.
+func version(b []byte) int {
+	if len(b) < 1 {
+		return 0
+	}
+	return int(b[0] >> 5)
+}
+
+// nextLayerTypeOfTPDU returns the layer type of the payload in T-PDU.
+func nextLayerTypeOfTPDU(payload []byte) gopacket.LayerType {
+	if len(payload) < 1 {
+		return gopacket.LayerTypeZero
+	}
+	switch payload[0] >> 4 {
+	case 4:
+		return layers.LayerTypeIPv4
+	case 6:
+		return layers.LayerTypeIPv6
+	default:
+		return gopacket.LayerTypePayload
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtplayer_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/wmnsk/go-gtp/gtplayer"
+	v1message "github.com/wmnsk/go-gtp/gtpv1/message"
+	v2ie "github.com/wmnsk/go-gtp/gtpv2/ie"
+	v2message "github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+func init() {
+	gtplayer.RegisterUDPPorts()
+}
+
+func serialize(t *testing.T, port layers.UDPPort, ls ...gopacket.SerializableLayer) []byte {
+	t.Helper()
+
+	ip := &layers.IPv4{
+		Version: 4, TTL: 64, Protocol: layers.IPProtocolUDP,
+		SrcIP: net.ParseIP("127.0.0.1"), DstIP: net.ParseIP("127.0.0.2"),
+	}
+	udp := &layers.UDP{SrcPort: port, DstPort: port}
+	if err := udp.SetNetworkLayerForChecksum(ip); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if err := gopacket.SerializeLayers(buf, opts, append([]gopacket.SerializableLayer{ip, udp}, ls...)...); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func innerPacket() []gopacket.SerializableLayer {
+	ip := &layers.IPv4{
+		Version: 4, TTL: 64, Protocol: layers.IPProtocolICMPv4,
+		SrcIP: net.ParseIP("10.0.0.1"), DstIP: net.ParseIP("10.0.0.2"),
+	}
+	icmp := &layers.ICMPv4{TypeCode: layers.CreateICMPv4TypeCode(layers.ICMPv4TypeEchoRequest, 0), Id: 1, Seq: 1}
+	return []gopacket.SerializableLayer{ip, icmp, gopacket.Payload([]byte{0xde, 0xad, 0xbe, 0xef})}
+}
+
+func TestGTPv1TPDU(t *testing.T) {
+	g := &gtplayer.GTPv1{
+		Header: v1message.NewHeader(0x30, v1message.MsgTypeTPDU, 0xdeadbeef, 0, nil),
+	}
+	b := serialize(t, gtplayer.UDPPortGTPU, append([]gopacket.SerializableLayer{g}, innerPacket()...)...)
+
+	pkt := gopacket.NewPacket(b, layers.LayerTypeIPv4, gopacket.Default)
+	if err := pkt.ErrorLayer(); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	got, ok := pkt.Layer(gtplayer.LayerTypeGTPv1).(*gtplayer.GTPv1)
+	if !ok {
+		t.Fatalf("GTPv1 layer not found: %v", pkt)
+	}
+	if got.Header.TEID != 0xdeadbeef {
+		t.Errorf("wrong TEID: %#x", got.Header.TEID)
+	}
+	if got.Header.Length != 32 {
+		t.Errorf("wrong length: %d", got.Header.Length)
+	}
+	if got.Message != nil {
+		t.Errorf("unexpected message: %v", got.Message)
+	}
+
+	var wantTypes = []gopacket.LayerType{
+		layers.LayerTypeIPv4, layers.LayerTypeUDP, gtplayer.LayerTypeGTPv1,
+		layers.LayerTypeIPv4, layers.LayerTypeICMPv4, gopacket.LayerTypePayload,
+	}
+	var gotTypes []gopacket.LayerType
+	for _, l := range pkt.Layers() {
+		gotTypes = append(gotTypes, l.LayerType())
+	}
+	if diff := cmp.Diff(wantTypes, gotTypes); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestGTPv1Message(t *testing.T) {
+	g := &gtplayer.GTPv1{Message: v1message.NewEchoRequest(0x1234)}
+	b := serialize(t, gtplayer.UDPPortGTPC, g)
+
+	pkt := gopacket.NewPacket(b, layers.LayerTypeIPv4, gopacket.Default)
+	if err := pkt.ErrorLayer(); err != nil {
+		t.Fatal(err.Error())
+	}
+	got, ok := pkt.Layer(gtplayer.LayerTypeGTPv1).(*gtplayer.GTPv1)
+	if !ok {
+		t.Fatalf("GTPv1 layer not found: %v", pkt)
+	}
+	if _, ok := got.Message.(*v1message.EchoRequest); !ok {
+		t.Fatalf("wrong message: %v", got.Message)
+	}
+	if got.Message.Sequence() != 0x1234 {
+		t.Errorf("wrong sequence: %#x", got.Message.Sequence())
+	}
+	if got.NextLayerType() != gopacket.LayerTypeZero {
+		t.Errorf("unexpected next layer: %v", got.NextLayerType())
+	}
+}
+
+func TestGTPv2(t *testing.T) {
+	g := &gtplayer.GTPv2{Message: v2message.NewEchoRequest(0x123456, v2ie.NewRecovery(0x80))}
+	b := serialize(t, gtplayer.UDPPortGTPC, g)
+
+	pkt := gopacket.NewPacket(b, layers.LayerTypeIPv4, gopacket.Default)
+	if err := pkt.ErrorLayer(); err != nil {
+		t.Fatal(err.Error())
+	}
+	if pkt.Layer(gtplayer.LayerTypeGTPv1) != nil {
+		t.Error("unexpected GTPv1 layer")
+	}
+	got, ok := pkt.Layer(gtplayer.LayerTypeGTPv2).(*gtplayer.GTPv2)
+	if !ok {
+		t.Fatalf("GTPv2 layer not found: %v", pkt)
+	}
+	req, ok := got.Message.(*v2message.EchoRequest)
+	if !ok {
+		t.Fatalf("wrong message: %v", got.Message)
+	}
+	if req.Sequence() != 0x123456 {
+		t.Errorf("wrong sequence: %#x", req.Sequence())
+	}
+	if v := req.Recovery.MustRecovery(); v != 0x80 {
+		t.Errorf("wrong Recovery: %d", v)
+	}
+}
+
+func TestDecodingLayerParser(t *testing.T) {
+	g := &gtplayer.GTPv1{
+		Header: v1message.NewHeader(0x30, v1message.MsgTypeTPDU, 0x11223344, 0, nil),
+	}
+	b := serialize(t, gtplayer.UDPPortGTPU, append([]gopacket.SerializableLayer{g}, innerPacket()...)...)
+
+	var (
+		ip   layers.IPv4
+		udp  layers.UDP
+		gtp  gtplayer.GTPv1
+		icmp layers.ICMPv4
+	)
+	parser := gopacket.NewDecodingLayerParser(layers.LayerTypeIPv4, &ip, &udp, &gtp, &icmp)
+	parser.IgnoreUnsupported = true
+
+	decoded := []gopacket.LayerType{}
+	if err := parser.DecodeLayers(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []gopacket.LayerType{
+		layers.LayerTypeIPv4, layers.LayerTypeUDP, gtplayer.LayerTypeGTPv1,
+		layers.LayerTypeIPv4, layers.LayerTypeICMPv4,
+	}
+	if diff := cmp.Diff(want, decoded); diff != "" {
+		t.Error(diff)
+	}
+	if gtp.Header.TEID != 0x11223344 {
+		t.Errorf("wrong TEID: %#x", gtp.Header.TEID)
+	}
+
+	// the IPv4 layer is overwritten with the inner one.
+	if !ip.DstIP.Equal(net.ParseIP("10.0.0.2")) {
+		t.Errorf("wrong inner destination: %s", ip.DstIP)
+	}
+	if icmp.Id != 1 {
+		t.Errorf("wrong ICMP ID: %d", icmp.Id)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtplayer
+
+import (
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
+)
+
+// GTPv1 is a gopacket layer of GTPv1 packet.
+//
+// For T-PDU, Header has the header including the extension headers and the
+// payload of the layer is the user packet. For the other types of message,
+// Message has the whole message decoded and the layer has no payload.
+type GTPv1 struct {
+	layers.BaseLayer
+	Header  *message.Header
+	Message message.Message
+}
+
+// LayerType returns LayerTypeGTPv1.
+func (g *GTPv1) LayerType() gopacket.LayerType {
+	return LayerTypeGTPv1
+}
+
+// CanDecode returns the set of layer types that GTPv1 can decode.
+func (g *GTPv1) CanDecode() gopacket.LayerClass {
+	return LayerTypeGTPv1
+}
+
+// NextLayerType returns the layer type of the payload, which is IPv4 or IPv6
+// for T-PDU and LayerTypeZero for the other types of message.
+func (g *GTPv1) NextLayerType() gopacket.LayerType {
+	if g.Header == nil || g.Header.Type != message.MsgTypeTPDU {
+		return gopacket.LayerTypeZero
+	}
+	return nextLayerTypeOfTPDU(g.Payload)
+}
+
+// DecodeFromBytes decodes the given bytes as GTPv1 packet.
+func (g *GTPv1) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	h, err := message.ParseHeader(data)
+	if err != nil {
+		df.SetTruncated()
+		return err
+	}
+
+	l := 8 + int(h.Length)
+	if len(data) < l {
+		df.SetTruncated()
+		return ErrTruncated
+	}
+	hlen := l - len(h.Payload)
+
+	g.Header = h
+	g.Message = nil
+	if h.Type == message.MsgTypeTPDU {
+		g.Contents = data[:hlen]
+		g.Payload = data[hlen:l]
+		return nil
+	}
+
+	m, err := message.Parse(data[:l])
+	if err != nil {
+		return err
+	}
+	g.Message = m
+	g.Contents = data[:l]
+	g.Payload = nil
+	return nil
+}
+
+// SerializeTo writes the serialized form of GTPv1 to the SerializeBuffer.
+//
+// If Message is set, the Message is written as it is. Otherwise, Header is
+// prepended to the bytes already in the buffer, and the length in Header is
+// updated with them if opts.FixLengths is true.
+func (g *GTPv1) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	if g.Message != nil {
+		bytes, err := b.PrependBytes(g.Message.MarshalLen())
+		if err != nil {
+			return err
+		}
+		return g.Message.MarshalTo(bytes)
+	}
+
+	if g.Header == nil {
+		return ErrNothingToSerialize
+	}
+	h := *g.Header
+	h.Payload = b.Bytes()
+	if opts.FixLengths {
+		h.SetLength()
+		g.Header.Length = h.Length
+	}
+
+	buf, err := h.Marshal()
+	if err != nil {
+		return err
+	}
+	hlen := len(buf) - len(h.Payload)
+	bytes, err := b.PrependBytes(hlen)
+	if err != nil {
+		return err
+	}
+	copy(bytes, buf[:hlen])
+	return nil
+}
+
+func decodeGTPv1(data []byte, p gopacket.PacketBuilder) error {
+	if version(data) == 2 {
+		return decodeGTPv2(data, p)
+	}
+
+	g := &GTPv1{}
+	if err := g.DecodeFromBytes(data, p); err != nil {
+		return err
+	}
+	p.AddLayer(g)
+	if len(g.Payload) == 0 {
+		return nil
+	}
+	return p.NextDecoder(g.NextLayerType())
+}
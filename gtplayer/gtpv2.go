@@ -0,0 +1,92 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtplayer
+
+import (
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// GTPv2 is a gopacket layer of GTPv2 packet.
+//
+// Message has the whole message decoded. If the P flag is set in the header,
+// the payload of the layer is the piggybacked message, which is decoded as
+// another GTPv2 layer.
+type GTPv2 struct {
+	layers.BaseLayer
+	Message message.Message
+}
+
+// LayerType returns LayerTypeGTPv2.
+func (g *GTPv2) LayerType() gopacket.LayerType {
+	return LayerTypeGTPv2
+}
+
+// CanDecode returns the set of layer types that GTPv2 can decode.
+func (g *GTPv2) CanDecode() gopacket.LayerClass {
+	return LayerTypeGTPv2
+}
+
+// NextLayerType returns LayerTypeGTPv2 if the message has a piggybacked message,
+// otherwise LayerTypeZero.
+func (g *GTPv2) NextLayerType() gopacket.LayerType {
+	if len(g.Payload) == 0 {
+		return gopacket.LayerTypeZero
+	}
+	return LayerTypeGTPv2
+}
+
+// DecodeFromBytes decodes the given bytes as GTPv2 packet.
+func (g *GTPv2) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	h, err := message.ParseHeader(data)
+	if err != nil {
+		df.SetTruncated()
+		return err
+	}
+
+	l := 4 + int(h.Length)
+	if len(data) < l {
+		df.SetTruncated()
+		return ErrTruncated
+	}
+
+	m, err := message.Parse(data[:l])
+	if err != nil {
+		return err
+	}
+	g.Message = m
+	g.Contents = data[:l]
+	g.Payload = nil
+	if h.IsPiggybacking() {
+		g.Payload = data[l:]
+	}
+	return nil
+}
+
+// SerializeTo writes the serialized form of GTPv2 to the SerializeBuffer.
+// The piggybacked message should be serialized as another GTPv2 layer after this.
+func (g *GTPv2) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	if g.Message == nil {
+		return ErrNothingToSerialize
+	}
+	bytes, err := b.PrependBytes(g.Message.MarshalLen())
+	if err != nil {
+		return err
+	}
+	return g.Message.MarshalTo(bytes)
+}
+
+func decodeGTPv2(data []byte, p gopacket.PacketBuilder) error {
+	g := &GTPv2{}
+	if err := g.DecodeFromBytes(data, p); err != nil {
+		return err
+	}
+	p.AddLayer(g)
+	if len(g.Payload) == 0 {
+		return nil
+	}
+	return decodeGTPv2(g.Payload, p)
+}
@@ -0,0 +1,118 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpload
+
+import (
+	"net"
+	"strconv"
+
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// Subscriber is the parameters of a session generated by Generator, which are
+// given to the functions that build the requests.
+type Subscriber struct {
+	// Index is the 0-origin index of the session.
+	Index int
+	// IMSI and MSISDN are the ones in Config incremented by Index.
+	IMSI, MSISDN string
+	// EBI is the EPS Bearer ID of the default bearer.
+	EBI uint8
+	// APN, MCC and MNC are the ones in Config.
+	APN, MCC, MNC string
+
+	// IFType is the interface type of the sender F-TEID, from Config.
+	IFType uint8
+	// LocalIP is the IP address of Generator used in F-TEIDs.
+	LocalIP net.IP
+	// LocalTEID is the TEID allocated by Generator for the session, which is
+	// used both for C-Plane and U-Plane.
+	LocalTEID uint32
+	// RemoteTEID is the C-Plane TEID of the peer, which is known after Create
+	// Session procedure succeeds.
+	RemoteTEID uint32
+}
+
+// RequestFunc builds a request for the Subscriber given. The Sequence Number is
+// overwritten by Generator.
+type RequestFunc func(sub *Subscriber) (message.Message, error)
+
+// DefaultCreateSessionRequest builds a Create Session Request with the minimal
+// IEs for the Subscriber given.
+//
+// If IFType is IFTypeS5S8SGWGTPC, it is built as the one from SGW on S5/S8
+// with S5/S8-U SGW F-TEID in the Bearer Context. Otherwise, it is the one from
+// MME on S11.
+func DefaultCreateSessionRequest(sub *Subscriber) (message.Message, error) {
+	brCtx := ie.NewBearerContext(
+		ie.NewEPSBearerID(sub.EBI),
+		ie.NewBearerQoS(1, 2, 1, 9, 0, 0, 0, 0),
+	)
+	if sub.IFType == v2.IFTypeS5S8SGWGTPC {
+		brCtx.Add(newFTEID(v2.IFTypeS5S8SGWGTPU, sub.LocalTEID, sub.LocalIP).WithInstance(2))
+	}
+
+	return message.NewCreateSessionRequest(
+		0, 0,
+		ie.NewIMSI(sub.IMSI),
+		ie.NewMSISDN(sub.MSISDN),
+		ie.NewRATType(v2.RATTypeEUTRAN),
+		ie.NewServingNetwork(sub.MCC, sub.MNC),
+		newFTEID(sub.IFType, sub.LocalTEID, sub.LocalIP),
+		ie.NewAccessPointName(sub.APN),
+		ie.NewSelectionMode(v2.SelectionModeMSorNetworkProvidedAPNSubscribedVerified),
+		ie.NewPDNType(v2.PDNTypeIPv4),
+		ie.NewPDNAddressAllocation("0.0.0.0"),
+		ie.NewAPNRestriction(v2.APNRestrictionNoExistingContextsorRestriction),
+		ie.NewAggregateMaximumBitRate(0, 0),
+		brCtx,
+	), nil
+}
+
+// DefaultModifyBearerRequest builds a Modify Bearer Request for the Subscriber
+// given.
+//
+// On S11, it has S1-U eNodeB F-TEID in the Bearer Context with LocalTEID and
+// LocalIP, as if the UE is attached to the eNodeB at the same address.
+func DefaultModifyBearerRequest(sub *Subscriber) (message.Message, error) {
+	brCtx := ie.NewBearerContext(ie.NewEPSBearerID(sub.EBI))
+	if sub.IFType != v2.IFTypeS5S8SGWGTPC {
+		brCtx.Add(newFTEID(v2.IFTypeS1UeNodeBGTPU, sub.LocalTEID, sub.LocalIP))
+	}
+
+	return message.NewModifyBearerRequest(sub.RemoteTEID, 0, brCtx), nil
+}
+
+// DefaultDeleteSessionRequest builds a Delete Session Request for the
+// Subscriber given.
+func DefaultDeleteSessionRequest(sub *Subscriber) (message.Message, error) {
+	return message.NewDeleteSessionRequest(
+		sub.RemoteTEID, 0,
+		ie.NewEPSBearerID(sub.EBI),
+	), nil
+}
+
+func newFTEID(ifType uint8, teid uint32, ip net.IP) *ie.IE {
+	if ip.To4() == nil {
+		return ie.NewFullyQualifiedTEIDNetIP(ifType, teid, nil, ip)
+	}
+	return ie.NewFullyQualifiedTEIDNetIP(ifType, teid, ip, nil)
+}
+
+// addDigits adds n to the decimal digits given, keeping the number of digits.
+func addDigits(digits string, n int) (string, error) {
+	v, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return "", err
+	}
+
+	s := strconv.FormatUint(v+uint64(n), 10)
+	for len(s) < len(digits) {
+		s = "0" + s
+	}
+	return s, nil
+}
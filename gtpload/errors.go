@@ -0,0 +1,83 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpload
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error definitions.
+var (
+	ErrInvalidConfig = errors.New("invalid config")
+	ErrTimeout       = errors.New("no response after retransmissions")
+	ErrMissingIE     = errors.New("required IE missing in response")
+)
+
+// FailureClass is the class of the reason why a procedure failed.
+type FailureClass uint8
+
+// FailureClass definitions.
+const (
+	// FailureTimeout is that no response is received after all the
+	// retransmissions.
+	FailureTimeout FailureClass = iota
+	// FailureRejected is that the response has the Cause not in the range of
+	// acceptance.
+	FailureRejected
+	// FailureMalformed is that the response cannot be decoded or lacks the IEs
+	// required to continue the session.
+	FailureMalformed
+	// FailureUnexpected is that the response has the type not expected for the
+	// request.
+	FailureUnexpected
+	// FailureSend is that the request cannot be built or sent.
+	FailureSend
+	// FailureCanceled is that the procedure is aborted as the context is done.
+	FailureCanceled
+)
+
+// String returns the name of FailureClass.
+func (c FailureClass) String() string {
+	switch c {
+	case FailureTimeout:
+		return "timeout"
+	case FailureRejected:
+		return "rejected"
+	case FailureMalformed:
+		return "malformed"
+	case FailureUnexpected:
+		return "unexpected"
+	case FailureSend:
+		return "send"
+	case FailureCanceled:
+		return "canceled"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(c))
+	}
+}
+
+// ProcedureError indicates that a procedure of a session failed.
+type ProcedureError struct {
+	Procedure Procedure
+	Class     FailureClass
+	// Cause is the Cause value in the response, which is set only if Class is
+	// FailureRejected.
+	Cause uint8
+	Err   error
+}
+
+// Error returns the procedure and the reason of the failure.
+func (e *ProcedureError) Error() string {
+	if e.Class == FailureRejected {
+		return fmt.Sprintf("%s %s: cause %d", e.Procedure, e.Class, e.Cause)
+	}
+	return fmt.Sprintf("%s %s: %v", e.Procedure, e.Class, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ProcedureError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,557 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Package gtpload generates the load of GTPv2-C sessions against SGW/PGW.
+//
+// Generator establishes, modifies and deletes the sessions at the rate and
+// concurrency configured, and reports the latency and the failures of each
+// procedure. The peer can be a real node or an emulator over gtptest.Pipe.
+package gtpload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// Stage is a stage of the ramp profile.
+type Stage struct {
+	// Duration is the length of the stage.
+	Duration time.Duration
+	// Rate is the number of sessions started per second at the end of the
+	// stage. The rate changes linearly from the one at the end of the previous
+	// stage during the stage.
+	Rate float64
+}
+
+// Config is the configuration of Generator.
+type Config struct {
+	// Sessions is the number of sessions to be generated.
+	Sessions int
+	// Concurrency is the maximum number of requests waiting for response at a
+	// time. Default is 100.
+	Concurrency int
+
+	// Rate is the number of sessions started per second. If zero and Ramp is
+	// empty, the sessions are started as fast as Concurrency allows.
+	Rate float64
+	// Ramp is the ramp profile of the rate, which starts from Rate. After the
+	// last stage, the rate at the end of it is kept.
+	Ramp []Stage
+
+	// Modifies is the number of Modify Bearer procedures run for each session.
+	Modifies int
+	// HoldTime is the time to wait between the procedures of a session.
+	HoldTime time.Duration
+	// KeepSessions makes Generator leave the sessions established, without
+	// running Delete Session procedure.
+	KeepSessions bool
+
+	// Timeout is the time to wait for the response before retransmitting the
+	// request (T3-RESPONSE). Default is 3 seconds.
+	Timeout time.Duration
+	// Retries is the number of retransmissions (N3-REQUESTS).
+	Retries int
+
+	// IMSI and MSISDN are the ones of the first session, which are incremented
+	// for each session. Default is "001010000000001" and "819000000001".
+	IMSI, MSISDN string
+	// APN, MCC and MNC are used in the requests. Default is "internet", "001"
+	// and "01".
+	APN, MCC, MNC string
+	// EBI is the EPS Bearer ID of the default bearer. Default is 5.
+	EBI uint8
+	// IFType is the interface type of the sender F-TEID. Default is
+	// IFTypeS11MMEGTPC.
+	IFType uint8
+	// LocalIP is the IP address used in F-TEIDs. Default is the one of the
+	// local address of the net.PacketConn.
+	LocalIP net.IP
+
+	// CreateSessionRequest, ModifyBearerRequest and DeleteSessionRequest
+	// build the requests. Default is the corresponding Default* function.
+	CreateSessionRequest, ModifyBearerRequest, DeleteSessionRequest RequestFunc
+}
+
+// RateAt returns the number of sessions started per second at the elapsed
+// time given, according to Rate and Ramp.
+func (c *Config) RateAt(elapsed time.Duration) float64 {
+	rate := c.Rate
+	for _, s := range c.Ramp {
+		if elapsed < s.Duration {
+			return rate + (s.Rate-rate)*float64(elapsed)/float64(s.Duration)
+		}
+		elapsed -= s.Duration
+		rate = s.Rate
+	}
+	return rate
+}
+
+func (c *Config) validate() error {
+	if c.Sessions <= 0 {
+		return fmt.Errorf("%w: Sessions should be positive: %d", ErrInvalidConfig, c.Sessions)
+	}
+	if c.Rate < 0 {
+		return fmt.Errorf("%w: Rate should not be negative: %f", ErrInvalidConfig, c.Rate)
+	}
+	for i, s := range c.Ramp {
+		if s.Duration <= 0 || s.Rate < 0 {
+			return fmt.Errorf("%w: invalid Ramp[%d]: %+v", ErrInvalidConfig, i, s)
+		}
+	}
+	return nil
+}
+
+// withDefaults returns the copy of Config with the default values filled.
+func (c *Config) withDefaults(laddr net.Addr) *Config {
+	cfg := *c
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 100
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 3 * time.Second
+	}
+	if cfg.IMSI == "" {
+		cfg.IMSI = "001010000000001"
+	}
+	if cfg.MSISDN == "" {
+		cfg.MSISDN = "819000000001"
+	}
+	if cfg.APN == "" {
+		cfg.APN = "internet"
+	}
+	if cfg.MCC == "" {
+		cfg.MCC = "001"
+	}
+	if cfg.MNC == "" {
+		cfg.MNC = "01"
+	}
+	if cfg.EBI == 0 {
+		cfg.EBI = 5
+	}
+	if cfg.IFType == 0 {
+		cfg.IFType = v2.IFTypeS11MMEGTPC
+	}
+	if cfg.LocalIP == nil {
+		if a, ok := laddr.(*net.UDPAddr); ok {
+			cfg.LocalIP = a.IP
+		}
+	}
+	if cfg.CreateSessionRequest == nil {
+		cfg.CreateSessionRequest = DefaultCreateSessionRequest
+	}
+	if cfg.ModifyBearerRequest == nil {
+		cfg.ModifyBearerRequest = DefaultModifyBearerRequest
+	}
+	if cfg.DeleteSessionRequest == nil {
+		cfg.DeleteSessionRequest = DefaultDeleteSessionRequest
+	}
+	return &cfg
+}
+
+// Generator generates the load of GTPv2-C sessions toward a peer.
+type Generator struct {
+	pc    net.PacketConn
+	raddr net.Addr
+	cfg   *Config
+
+	seq uint32
+	sem chan struct{}
+	rec *recorder
+
+	mu      sync.Mutex
+	pending map[uint32]chan *result
+}
+
+// result is the response to a request, or the error if it cannot be decoded.
+type result struct {
+	msg message.Message
+	err error
+}
+
+// NewGenerator creates a new Generator that sends the requests to raddr over
+// the net.PacketConn given.
+//
+// The net.PacketConn should not be read by others while Generator is running.
+func NewGenerator(pc net.PacketConn, raddr net.Addr, cfg *Config) (*Generator, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	c := cfg.withDefaults(pc.LocalAddr())
+	return &Generator{
+		pc:      pc,
+		raddr:   raddr,
+		cfg:     c,
+		sem:     make(chan struct{}, c.Concurrency),
+		rec:     newRecorder(),
+		pending: map[uint32]chan *result{},
+	}, nil
+}
+
+// Report returns the statistics so far. It can be called while Run is running.
+func (g *Generator) Report() *Report {
+	return g.rec.report()
+}
+
+// Run generates the load and blocks until all the sessions finish their
+// procedures or ctx is canceled. It returns the Report with ctx.Err() in the
+// latter case.
+//
+// Run should be called only once for a Generator.
+func (g *Generator) Run(ctx context.Context) (*Report, error) {
+	readCtx, stopRead := context.WithCancel(context.Background())
+	readDone := make(chan error, 1)
+	go func() {
+		readDone <- g.readLoop(readCtx)
+	}()
+
+	g.rec.mu.Lock()
+	g.rec.start = time.Now()
+	g.rec.mu.Unlock()
+
+	var wg sync.WaitGroup
+	err := g.pace(ctx, func(i int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g.runSession(ctx, i)
+		}()
+	})
+	wg.Wait()
+
+	stopRead()
+	// unblock ReadFrom without closing the net.PacketConn owned by the caller.
+	_ = g.pc.SetReadDeadline(time.Now())
+	if rerr := <-readDone; rerr != nil && err == nil {
+		err = rerr
+	}
+	_ = g.pc.SetReadDeadline(time.Time{})
+
+	return g.rec.report(), err
+}
+
+// pace calls start for each session at the rate configured.
+func (g *Generator) pace(ctx context.Context, start func(i int)) error {
+	unlimited := g.cfg.Rate == 0 && len(g.cfg.Ramp) == 0
+	begin := time.Now()
+
+	for i := 0; i < g.cfg.Sessions; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		start(i)
+		if unlimited || i == g.cfg.Sessions-1 {
+			continue
+		}
+
+		// wait for the interval at the current rate, polling while the rate
+		// is zero in the middle of the ramp.
+		var wait time.Duration
+		for {
+			rate := g.cfg.RateAt(time.Since(begin))
+			if rate > 0 {
+				wait = time.Duration(float64(time.Second) / rate)
+				break
+			}
+			if err := sleep(ctx, 10*time.Millisecond); err != nil {
+				return err
+			}
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (g *Generator) runSession(ctx context.Context, i int) {
+	g.rec.sessionStarted()
+
+	sub, err := g.newSubscriber(i)
+	if err != nil {
+		g.rec.attempt(ProcedureCreateSession)
+		g.rec.failure(&ProcedureError{Procedure: ProcedureCreateSession, Class: FailureSend, Err: err})
+		return
+	}
+
+	if err := g.procedure(ctx, ProcedureCreateSession, sub); err != nil {
+		return
+	}
+
+	for n := 0; n < g.cfg.Modifies; n++ {
+		if sleep(ctx, g.cfg.HoldTime) != nil {
+			break
+		}
+		// go on to Delete Session even if Modify Bearer fails, so as not to
+		// leave the session on the peer.
+		if err := g.procedure(ctx, ProcedureModifyBearer, sub); err != nil {
+			break
+		}
+	}
+
+	if g.cfg.KeepSessions {
+		return
+	}
+	// Delete Session is attempted even after ctx is canceled in the middle
+	// of the procedures, as long as Create Session succeeded.
+	if ctx.Err() == nil {
+		_ = sleep(ctx, g.cfg.HoldTime)
+	}
+	_ = g.procedure(context.Background(), ProcedureDeleteSession, sub)
+}
+
+func (g *Generator) newSubscriber(i int) (*Subscriber, error) {
+	imsi, err := addDigits(g.cfg.IMSI, i)
+	if err != nil {
+		return nil, err
+	}
+	msisdn, err := addDigits(g.cfg.MSISDN, i)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Subscriber{
+		Index:     i,
+		IMSI:      imsi,
+		MSISDN:    msisdn,
+		EBI:       g.cfg.EBI,
+		APN:       g.cfg.APN,
+		MCC:       g.cfg.MCC,
+		MNC:       g.cfg.MNC,
+		IFType:    g.cfg.IFType,
+		LocalIP:   g.cfg.LocalIP,
+		LocalTEID: uint32(i + 1),
+	}, nil
+}
+
+// procedure runs a procedure of the session and records the result.
+func (g *Generator) procedure(ctx context.Context, p Procedure, sub *Subscriber) error {
+	g.rec.attempt(p)
+
+	res, elapsed, err := g.transact(ctx, p, sub)
+	if err != nil {
+		var perr *ProcedureError
+		if !errors.As(err, &perr) {
+			perr = &ProcedureError{Procedure: p, Class: FailureSend, Err: err}
+		}
+		g.rec.failure(perr)
+		return perr
+	}
+
+	if perr := checkResponse(p, res, sub); perr != nil {
+		g.rec.failure(perr)
+		return perr
+	}
+
+	g.rec.success(p, elapsed)
+	return nil
+}
+
+// transact sends the request and waits for the response, retransmitting it on
+// timeout. It returns the time elapsed from the first transmission to the response,
+// which does not include the time waiting for the concurrency limit.
+func (g *Generator) transact(ctx context.Context, p Procedure, sub *Subscriber) (message.Message, time.Duration, error) {
+	req, err := g.build(p, sub)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	seq := atomic.AddUint32(&g.seq, 1) & 0xffffff
+	req.SetSequenceNumber(seq)
+	b, err := message.Marshal(req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ch := make(chan *result, 1)
+	g.mu.Lock()
+	g.pending[seq] = ch
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		delete(g.pending, seq)
+		g.mu.Unlock()
+	}()
+
+	select {
+	case g.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, 0, &ProcedureError{Procedure: p, Class: FailureCanceled, Err: ctx.Err()}
+	}
+	defer func() { <-g.sem }()
+
+	started := time.Now()
+	for attempt := 0; attempt <= g.cfg.Retries; attempt++ {
+		if attempt > 0 {
+			g.rec.retransmit(p)
+		}
+		if _, err := g.pc.WriteTo(b, g.raddr); err != nil {
+			return nil, 0, err
+		}
+
+		t := time.NewTimer(g.cfg.Timeout)
+		select {
+		case res := <-ch:
+			t.Stop()
+			if res.err != nil {
+				return nil, 0, &ProcedureError{Procedure: p, Class: FailureMalformed, Err: res.err}
+			}
+			return res.msg, time.Since(started), nil
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return nil, 0, &ProcedureError{Procedure: p, Class: FailureCanceled, Err: ctx.Err()}
+		}
+	}
+	return nil, 0, &ProcedureError{Procedure: p, Class: FailureTimeout, Err: ErrTimeout}
+}
+
+func (g *Generator) build(p Procedure, sub *Subscriber) (message.Message, error) {
+	switch p {
+	case ProcedureCreateSession:
+		return g.cfg.CreateSessionRequest(sub)
+	case ProcedureModifyBearer:
+		return g.cfg.ModifyBearerRequest(sub)
+	default:
+		return g.cfg.DeleteSessionRequest(sub)
+	}
+}
+
+// checkResponse checks the type and the Cause of the response, and updates
+// the Subscriber with the peer's TEID in Create Session Response.
+func checkResponse(p Procedure, res message.Message, sub *Subscriber) *ProcedureError {
+	var (
+		want  uint8
+		cause *ie.IE
+	)
+	switch r := res.(type) {
+	case *message.CreateSessionResponse:
+		want, cause = message.MsgTypeCreateSessionResponse, r.Cause
+	case *message.ModifyBearerResponse:
+		want, cause = message.MsgTypeModifyBearerResponse, r.Cause
+	case *message.DeleteSessionResponse:
+		want, cause = message.MsgTypeDeleteSessionResponse, r.Cause
+	}
+
+	if want == 0 || want != responseType(p) {
+		return &ProcedureError{
+			Procedure: p,
+			Class:     FailureUnexpected,
+			Err:       fmt.Errorf("got %s", res.MessageTypeName()),
+		}
+	}
+	if cause == nil {
+		return &ProcedureError{Procedure: p, Class: FailureMalformed, Err: &ie.IENotFoundError{Type: ie.Cause}}
+	}
+
+	c, err := cause.Cause()
+	if err != nil {
+		return &ProcedureError{Procedure: p, Class: FailureMalformed, Err: err}
+	}
+	// the values from 16 to 63 are acceptance in response (TS 29.274 8.4).
+	if c < 16 || c > 63 {
+		return &ProcedureError{Procedure: p, Class: FailureRejected, Cause: c}
+	}
+
+	if r, ok := res.(*message.CreateSessionResponse); ok {
+		if r.SenderFTEIDC == nil {
+			return &ProcedureError{Procedure: p, Class: FailureMalformed, Err: &ie.IENotFoundError{Type: ie.FullyQualifiedTEID}}
+		}
+		teid, err := r.SenderFTEIDC.TEID()
+		if err != nil {
+			return &ProcedureError{Procedure: p, Class: FailureMalformed, Err: err}
+		}
+		sub.RemoteTEID = teid
+	}
+	return nil
+}
+
+func responseType(p Procedure) uint8 {
+	switch p {
+	case ProcedureCreateSession:
+		return message.MsgTypeCreateSessionResponse
+	case ProcedureModifyBearer:
+		return message.MsgTypeModifyBearerResponse
+	default:
+		return message.MsgTypeDeleteSessionResponse
+	}
+}
+
+// readLoop passes the responses to the requests waiting for them, and responds
+// to Echo Request from the peer.
+func (g *Generator) readLoop(ctx context.Context) error {
+	buf := make([]byte, 1500)
+	for {
+		n, raddr, err := g.pc.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		// the message is parsed from a copy, as it refers to the bytes given.
+		raw := make([]byte, n)
+		copy(raw, buf)
+		msg, err := message.Parse(raw)
+		if err != nil {
+			// pass the error to the request with the same Sequence Number,
+			// if the header can be decoded at least.
+			h, herr := message.ParseHeader(raw)
+			if herr != nil || !g.deliver(h.SequenceNumber, &result{err: err}) {
+				g.rec.unmatchedMessage()
+			}
+			continue
+		}
+
+		if msg.MessageType() == message.MsgTypeEchoRequest {
+			b, err := message.Marshal(message.NewEchoResponse(msg.Sequence(), ie.NewRecovery(0)))
+			if err == nil {
+				_, _ = g.pc.WriteTo(b, raddr)
+			}
+			continue
+		}
+
+		if !g.deliver(msg.Sequence(), &result{msg: msg}) {
+			g.rec.unmatchedMessage()
+		}
+	}
+}
+
+func (g *Generator) deliver(seq uint32, res *result) bool {
+	g.mu.Lock()
+	ch, ok := g.pending[seq]
+	g.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	// the duplicated responses to the retransmitted request are dropped.
+	select {
+	case ch <- res:
+	default:
+	}
+	return true
+}
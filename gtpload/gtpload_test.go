@@ -0,0 +1,273 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpload_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wmnsk/go-gtp/gtpload"
+	"github.com/wmnsk/go-gtp/gtptest"
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// newPeer starts a MockPeer that accepts all the sessions, except the ones
+// rejected by reject.
+func newPeer(t *testing.T, ctx context.Context, cfg *gtpload.Config, reject func(imsi string) bool, noDelete bool) (*gtptest.MockPeer, *gtpload.Generator) {
+	t.Helper()
+
+	genPC, peerPC, err := gtptest.Pipe("127.0.0.1:2123", "127.0.0.2:2123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		genPC.Close()
+		peerPC.Close()
+	})
+
+	sessions := cfg.Sessions
+	peer := gtptest.NewMockPeer(peerPC)
+	peer.Expect(message.MsgTypeCreateSessionRequest, func(req message.Message) message.Message {
+		csReq := req.(*message.CreateSessionRequest)
+		teid, err := csReq.SenderFTEIDC.TEID()
+		if err != nil {
+			t.Error(err)
+			return nil
+		}
+		imsi, err := csReq.IMSI.IMSI()
+		if err != nil {
+			t.Error(err)
+			return nil
+		}
+		if reject != nil && reject(imsi) {
+			return message.NewCreateSessionResponse(
+				teid, 0, ie.NewCause(v2.CauseNoResourcesAvailable, 0, 0, 0, nil),
+			)
+		}
+		return message.NewCreateSessionResponse(
+			teid, 0,
+			ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+			ie.NewFullyQualifiedTEID(v2.IFTypeS11S4SGWGTPC, teid+0x10000, "127.0.0.2", "").WithInstance(0),
+		)
+	}).Times(sessions)
+	peer.Expect(message.MsgTypeModifyBearerRequest, func(req message.Message) message.Message {
+		return message.NewModifyBearerResponse(
+			req.TEID()-0x10000, 0, ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+		)
+	}).Times(sessions)
+	peer.Expect(message.MsgTypeDeleteSessionRequest, func(req message.Message) message.Message {
+		if noDelete {
+			return nil
+		}
+		return message.NewDeleteSessionResponse(
+			req.TEID()-0x10000, 0, ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+		)
+	}).Times(sessions * (cfg.Retries + 1))
+	go func() {
+		if err := peer.Serve(ctx); err != nil {
+			t.Log(err)
+		}
+	}()
+
+	g, err := gtpload.NewGenerator(genPC, peerPC.LocalAddr(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return peer, g
+}
+
+func newConfig(sessions int) *gtpload.Config {
+	return &gtpload.Config{
+		Sessions:    sessions,
+		Concurrency: 4,
+		Modifies:    1,
+		Timeout:     50 * time.Millisecond,
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	peer, g := newPeer(t, ctx, newConfig(20), nil, false)
+	rep, err := g.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []gtpload.Procedure{
+		gtpload.ProcedureCreateSession, gtpload.ProcedureModifyBearer, gtpload.ProcedureDeleteSession,
+	} {
+		s := rep.Stats(p)
+		if s.Attempts != 20 || s.Successes != 20 {
+			t.Errorf("%s: want 20 attempts and successes, got %d and %d", p, s.Attempts, s.Successes)
+		}
+		if got := s.Latency.Count(); got != 20 {
+			t.Errorf("%s: want 20 latency samples, got %d", p, got)
+		}
+	}
+	if rep.Started != 20 || rep.Active != 0 || rep.PeakActive == 0 {
+		t.Errorf("unexpected session counts: %+v", rep)
+	}
+
+	imsis := map[string]bool{}
+	for _, msg := range peer.Received() {
+		if csReq, ok := msg.(*message.CreateSessionRequest); ok {
+			imsis[csReq.IMSI.MustIMSI()] = true
+		}
+	}
+	if len(imsis) != 20 || !imsis["001010000000001"] || !imsis["001010000000020"] {
+		t.Errorf("IMSIs are not incremented for each session: %v", imsis)
+	}
+}
+
+func TestGeneratorRate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := newConfig(5)
+	cfg.Rate = 100
+	_, g := newPeer(t, ctx, cfg, nil, false)
+	rep, err := g.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 4 intervals of 10ms between 5 sessions.
+	if rep.Elapsed < 40*time.Millisecond {
+		t.Errorf("sessions are started faster than Rate: %s", rep.Elapsed)
+	}
+}
+
+func TestGeneratorFailures(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reject := func(imsi string) bool {
+		return strings.HasSuffix(imsi, "2")
+	}
+	_, g := newPeer(t, ctx, newConfig(4), reject, true)
+	rep, err := g.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs := rep.Stats(gtpload.ProcedureCreateSession)
+	if cs.Successes != 3 || cs.Failures[gtpload.FailureRejected] != 1 || cs.Causes[v2.CauseNoResourcesAvailable] != 1 {
+		t.Errorf("unexpected Create Session stats: %+v", cs)
+	}
+
+	ds := rep.Stats(gtpload.ProcedureDeleteSession)
+	if ds.Attempts != 3 || ds.Failures[gtpload.FailureTimeout] != 3 {
+		t.Errorf("unexpected Delete Session stats: %+v", ds)
+	}
+	if rep.Active != 0 {
+		t.Errorf("sessions failed to be deleted should not be active: %d", rep.Active)
+	}
+
+	if !strings.Contains(rep.String(), "cause 73: 1") {
+		t.Errorf("Report does not have the Cause:\n%s", rep)
+	}
+}
+
+func TestGeneratorRetransmission(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := newConfig(2)
+	cfg.Retries = 2
+	peer, g := newPeer(t, ctx, cfg, nil, true)
+	rep, err := g.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ds := rep.Stats(gtpload.ProcedureDeleteSession)
+	if ds.Retransmissions != 4 || ds.Failures[gtpload.FailureTimeout] != 2 {
+		t.Errorf("unexpected Delete Session stats: %+v", ds)
+	}
+	if err := peer.Verify(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	for _, cfg := range []*gtpload.Config{
+		{},
+		{Sessions: 1, Rate: -1},
+		{Sessions: 1, Ramp: []gtpload.Stage{{Rate: 10}}},
+	} {
+		if _, err := gtpload.NewGenerator(nil, nil, cfg); !errors.Is(err, gtpload.ErrInvalidConfig) {
+			t.Errorf("%+v: want ErrInvalidConfig, got %v", cfg, err)
+		}
+	}
+}
+
+func TestRateAt(t *testing.T) {
+	cfg := &gtpload.Config{
+		Rate: 10,
+		Ramp: []gtpload.Stage{
+			{Duration: 10 * time.Second, Rate: 110},
+			{Duration: 10 * time.Second, Rate: 110},
+			{Duration: 5 * time.Second, Rate: 0},
+		},
+	}
+
+	cases := []struct {
+		elapsed time.Duration
+		want    float64
+	}{
+		{0, 10},
+		{5 * time.Second, 60},
+		{10 * time.Second, 110},
+		{15 * time.Second, 110},
+		{22500 * time.Millisecond, 55},
+		{time.Minute, 0},
+	}
+	for _, c := range cases {
+		if got := cfg.RateAt(c.elapsed); got != c.want {
+			t.Errorf("RateAt(%s): want %f, got %f", c.elapsed, c.want, got)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := gtpload.NewHistogram(time.Millisecond, 10*time.Millisecond, 100*time.Millisecond)
+	if got := h.Quantile(0.5); got != 0 {
+		t.Errorf("empty Histogram should return zero, got %s", got)
+	}
+
+	for _, d := range []time.Duration{
+		500 * time.Microsecond, 2 * time.Millisecond, 3 * time.Millisecond, 5 * time.Millisecond, 200 * time.Millisecond,
+	} {
+		h.Observe(d)
+	}
+
+	if got, want := h.Count(), uint64(5); got != want {
+		t.Errorf("Count: want %d, got %d", want, got)
+	}
+	if got, want := h.Min(), 500*time.Microsecond; got != want {
+		t.Errorf("Min: want %s, got %s", want, got)
+	}
+	if got, want := h.Mean(), 42100*time.Microsecond; got != want {
+		t.Errorf("Mean: want %s, got %s", want, got)
+	}
+	if got, want := h.Quantile(0.5), 10*time.Millisecond; got != want {
+		t.Errorf("p50: want %s, got %s", want, got)
+	}
+	if got, want := h.Quantile(0.99), 200*time.Millisecond; got != want {
+		t.Errorf("p99: want %s, got %s", want, got)
+	}
+
+	buckets := h.Buckets()
+	if len(buckets) != 4 || buckets[0].Count != 1 || buckets[1].Count != 3 || buckets[2].Count != 0 || buckets[3].Count != 1 {
+		t.Errorf("unexpected buckets: %+v", buckets)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpload
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets is the upper bounds of the buckets used in Histogram by default.
+var DefaultBuckets = []time.Duration{
+	500 * time.Microsecond,
+	1 * time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	200 * time.Millisecond,
+	500 * time.Millisecond,
+	1 * time.Second,
+	2 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Bucket is a bucket in Histogram.
+type Bucket struct {
+	// UpperBound is the inclusive upper bound of the bucket. The last bucket,
+	// which has the samples that exceed all the bounds, has zero.
+	UpperBound time.Duration
+	Count      uint64
+}
+
+// Histogram is a latency histogram with the fixed buckets.
+//
+// It is safe for concurrent use.
+type Histogram struct {
+	mu       sync.Mutex
+	bounds   []time.Duration
+	counts   []uint64
+	count    uint64
+	sum      time.Duration
+	min, max time.Duration
+}
+
+// NewHistogram creates a new Histogram with the upper bounds of the buckets
+// given in ascending order. DefaultBuckets is used if none is given.
+func NewHistogram(bounds ...time.Duration) *Histogram {
+	if len(bounds) == 0 {
+		bounds = DefaultBuckets
+	}
+	return &Histogram{
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)+1),
+	}
+}
+
+// Observe adds a sample to the Histogram.
+func (h *Histogram) Observe(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := 0
+	for i < len(h.bounds) && d > h.bounds[i] {
+		i++
+	}
+	h.counts[i]++
+
+	if h.count == 0 || d < h.min {
+		h.min = d
+	}
+	if d > h.max {
+		h.max = d
+	}
+	h.count++
+	h.sum += d
+}
+
+// Count returns the number of the samples.
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.count
+}
+
+// Min returns the smallest sample, or zero if there is no sample.
+func (h *Histogram) Min() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.min
+}
+
+// Max returns the largest sample, or zero if there is no sample.
+func (h *Histogram) Max() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.max
+}
+
+// Mean returns the average of the samples, or zero if there is no sample.
+func (h *Histogram) Mean() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.count == 0 {
+		return 0
+	}
+	return h.sum / time.Duration(h.count)
+}
+
+// Quantile returns the upper bound of the bucket that has the q-quantile of
+// the samples, where q is in the range of [0, 1]. The result never exceeds the
+// largest sample. It returns zero if there is no sample.
+func (h *Histogram) Quantile(q float64) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.count == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(q * float64(h.count)))
+	if rank == 0 {
+		rank = 1
+	}
+	if rank > h.count {
+		rank = h.count
+	}
+
+	var seen uint64
+	for i, c := range h.counts {
+		seen += c
+		if seen < rank {
+			continue
+		}
+		if i < len(h.bounds) && h.bounds[i] < h.max {
+			return h.bounds[i]
+		}
+		return h.max
+	}
+	return h.max
+}
+
+// Buckets returns the buckets of the Histogram, including the last one for the
+// samples that exceed all the bounds.
+func (h *Histogram) Buckets() []Bucket {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	buckets := make([]Bucket, len(h.counts))
+	for i, c := range h.counts {
+		buckets[i].Count = c
+		if i < len(h.bounds) {
+			buckets[i].UpperBound = h.bounds[i]
+		}
+	}
+	return buckets
+}
+
+func (h *Histogram) clone() *Histogram {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	counts := make([]uint64, len(h.counts))
+	copy(counts, h.counts)
+	return &Histogram{
+		bounds: h.bounds,
+		counts: counts,
+		count:  h.count,
+		sum:    h.sum,
+		min:    h.min,
+		max:    h.max,
+	}
+}
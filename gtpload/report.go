@@ -0,0 +1,265 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpload
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Procedure is the procedure run by Generator for each session.
+type Procedure uint8
+
+// Procedure definitions.
+const (
+	ProcedureCreateSession Procedure = iota
+	ProcedureModifyBearer
+	ProcedureDeleteSession
+
+	numProcedures
+)
+
+// String returns the name of Procedure.
+func (p Procedure) String() string {
+	switch p {
+	case ProcedureCreateSession:
+		return "Create Session"
+	case ProcedureModifyBearer:
+		return "Modify Bearer"
+	case ProcedureDeleteSession:
+		return "Delete Session"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(p))
+	}
+}
+
+// ProcedureStats is the statistics of a Procedure.
+type ProcedureStats struct {
+	Procedure Procedure
+	// Attempts is the number of the procedures started.
+	Attempts int
+	// Successes is the number of the procedures completed with acceptance.
+	Successes int
+	// Retransmissions is the number of the requests sent again on timeout.
+	Retransmissions int
+	// Failures is the number of the failed procedures by class.
+	Failures map[FailureClass]int
+	// Causes is the number of the rejected procedures by Cause value.
+	Causes map[uint8]int
+	// Latency is the time from the first request sent to the response
+	// received, of the successful procedures.
+	Latency *Histogram
+}
+
+func newProcedureStats(p Procedure) *ProcedureStats {
+	return &ProcedureStats{
+		Procedure: p,
+		Failures:  map[FailureClass]int{},
+		Causes:    map[uint8]int{},
+		Latency:   NewHistogram(),
+	}
+}
+
+// FailureCount returns the total number of the failed procedures.
+func (s *ProcedureStats) FailureCount() int {
+	var n int
+	for _, c := range s.Failures {
+		n += c
+	}
+	return n
+}
+
+func (s *ProcedureStats) clone() *ProcedureStats {
+	c := &ProcedureStats{
+		Procedure:       s.Procedure,
+		Attempts:        s.Attempts,
+		Successes:       s.Successes,
+		Retransmissions: s.Retransmissions,
+		Failures:        make(map[FailureClass]int, len(s.Failures)),
+		Causes:          make(map[uint8]int, len(s.Causes)),
+		Latency:         s.Latency.clone(),
+	}
+	for k, v := range s.Failures {
+		c.Failures[k] = v
+	}
+	for k, v := range s.Causes {
+		c.Causes[k] = v
+	}
+	return c
+}
+
+// Report is the result of the load generated by Generator.
+type Report struct {
+	// Elapsed is the time since Generator started.
+	Elapsed time.Duration
+	// Started is the number of the sessions started.
+	Started int
+	// Active is the number of the sessions established and not deleted yet.
+	Active int
+	// PeakActive is the largest number of Active so far.
+	PeakActive int
+	// Unmatched is the number of the messages received that do not match any
+	// request waiting for response.
+	Unmatched int
+	// Procedures is the statistics of each Procedure, in the order of
+	// Create Session, Modify Bearer and Delete Session.
+	Procedures []*ProcedureStats
+}
+
+// Stats returns the statistics of the Procedure given.
+func (r *Report) Stats(p Procedure) *ProcedureStats {
+	for _, s := range r.Procedures {
+		if s.Procedure == p {
+			return s
+		}
+	}
+	return nil
+}
+
+// String returns the summary of the Report in human-readable form.
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "elapsed: %s, sessions started: %d, active: %d (peak %d)",
+		r.Elapsed.Round(time.Millisecond), r.Started, r.Active, r.PeakActive)
+	if r.Unmatched > 0 {
+		fmt.Fprintf(&b, ", unmatched messages: %d", r.Unmatched)
+	}
+	b.WriteString("\n")
+
+	for _, s := range r.Procedures {
+		if s.Attempts == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: attempts: %d, successes: %d, failures: %d, retransmissions: %d\n",
+			s.Procedure, s.Attempts, s.Successes, s.FailureCount(), s.Retransmissions)
+		if s.Latency.Count() > 0 {
+			fmt.Fprintf(&b, "  latency: min %s, mean %s, p50 %s, p90 %s, p99 %s, max %s\n",
+				s.Latency.Min(), s.Latency.Mean(), s.Latency.Quantile(0.5),
+				s.Latency.Quantile(0.9), s.Latency.Quantile(0.99), s.Latency.Max())
+		}
+
+		var classes []int
+		for c := range s.Failures {
+			classes = append(classes, int(c))
+		}
+		sort.Ints(classes)
+		for _, c := range classes {
+			fmt.Fprintf(&b, "  %s: %d\n", FailureClass(c), s.Failures[FailureClass(c)])
+		}
+
+		var causes []int
+		for c := range s.Causes {
+			causes = append(causes, int(c))
+		}
+		sort.Ints(causes)
+		for _, c := range causes {
+			fmt.Fprintf(&b, "  cause %d: %d\n", c, s.Causes[uint8(c)])
+		}
+	}
+	return b.String()
+}
+
+// recorder accumulates the statistics while Generator is running.
+type recorder struct {
+	mu         sync.Mutex
+	start      time.Time
+	started    int
+	active     int
+	peakActive int
+	unmatched  int
+	procs      [numProcedures]*ProcedureStats
+}
+
+func newRecorder() *recorder {
+	r := &recorder{start: time.Now()}
+	for p := range r.procs {
+		r.procs[p] = newProcedureStats(Procedure(p))
+	}
+	return r
+}
+
+func (r *recorder) sessionStarted() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.started++
+}
+
+func (r *recorder) attempt(p Procedure) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.procs[p].Attempts++
+}
+
+func (r *recorder) retransmit(p Procedure) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.procs[p].Retransmissions++
+}
+
+func (r *recorder) success(p Procedure, latency time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.procs[p].Successes++
+	r.procs[p].Latency.Observe(latency)
+
+	switch p {
+	case ProcedureCreateSession:
+		r.active++
+		if r.active > r.peakActive {
+			r.peakActive = r.active
+		}
+	case ProcedureDeleteSession:
+		r.active--
+	}
+}
+
+func (r *recorder) failure(err *ProcedureError) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.procs[err.Procedure]
+	s.Failures[err.Class]++
+	if err.Class == FailureRejected {
+		s.Causes[err.Cause]++
+	}
+
+	// the session is considered gone if Delete Session fails, as nothing can
+	// be done for it any more.
+	if err.Procedure == ProcedureDeleteSession {
+		r.active--
+	}
+}
+
+func (r *recorder) unmatchedMessage() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.unmatched++
+}
+
+func (r *recorder) report() *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rep := &Report{
+		Elapsed:    time.Since(r.start),
+		Started:    r.started,
+		Active:     r.active,
+		PeakActive: r.peakActive,
+		Unmatched:  r.unmatched,
+		Procedures: make([]*ProcedureStats, len(r.procs)),
+	}
+	for i, s := range r.procs {
+		rep.Procedures[i] = s.clone()
+	}
+	return rep
+}
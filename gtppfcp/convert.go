@@ -0,0 +1,264 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtppfcp
+
+import (
+	"math"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+// FTEIDFromGTP converts the F-TEID IE of GTPv2 into FTEID of PFCP, to be used
+// in PDI for the packets received on the tunnel.
+func FTEIDFromGTP(fteid *ie.IE) (*FTEID, error) {
+	f, err := fteid.FullyQualifiedTEID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &FTEID{
+		TEID:        f.TEIDGREKey,
+		IPv4Address: f.IPv4Address,
+		IPv6Address: f.IPv6Address,
+	}, nil
+}
+
+// FTEIDToGTP converts FTEID of PFCP assigned by UP function into the F-TEID IE
+// of GTPv2 with the interface type given, to be sent to the peer.
+//
+// It returns ErrChooseFTEID if FTEID is the one to be chosen by UP function.
+func FTEIDToGTP(f *FTEID, ifType uint8) (*ie.IE, error) {
+	if f.Choose {
+		return nil, ErrChooseFTEID
+	}
+	if f.IPv4Address == nil && f.IPv6Address == nil {
+		return nil, ErrNoAddress
+	}
+	return ie.NewFullyQualifiedTEIDNetIP(ifType, f.TEID, f.IPv4Address, f.IPv6Address), nil
+}
+
+// OuterHeaderCreationFromGTP converts the F-TEID IE of GTPv2 into
+// OuterHeaderCreation of PFCP, to be used in FAR for the packets sent to the
+// peer of the tunnel. IPv4 is used if F-TEID has both IPv4 and IPv6 address.
+func OuterHeaderCreationFromGTP(fteid *ie.IE) (*OuterHeaderCreation, error) {
+	f, err := fteid.FullyQualifiedTEID()
+	if err != nil {
+		return nil, err
+	}
+
+	o := &OuterHeaderCreation{TEID: f.TEIDGREKey}
+	switch {
+	case f.IPv4Address != nil:
+		o.Description = OuterHeaderCreationGTPUUDPIPv4
+		o.IPv4Address = f.IPv4Address
+	case f.IPv6Address != nil:
+		o.Description = OuterHeaderCreationGTPUUDPIPv6
+		o.IPv6Address = f.IPv6Address
+	default:
+		return nil, ErrNoAddress
+	}
+	return o, nil
+}
+
+// OuterHeaderCreationToGTP converts OuterHeaderCreation of PFCP into the
+// F-TEID IE of GTPv2 with the interface type given.
+func OuterHeaderCreationToGTP(o *OuterHeaderCreation, ifType uint8) (*ie.IE, error) {
+	if !o.hasTEID() {
+		return nil, ErrNoAddress
+	}
+	return ie.NewFullyQualifiedTEIDNetIP(ifType, o.TEID, o.IPv4Address, o.IPv6Address), nil
+}
+
+// QERFromBearerQoS converts the Bearer QoS IE (or Flow QoS IE) of GTPv2 into
+// QER of PFCP with MBR and GBR. GBR is nil if both uplink and downlink GBR are
+// zero, i.e., for non-GBR bearers.
+func QERFromBearerQoS(qos *ie.IE) (*QER, error) {
+	mbrUL, err := qos.MBRForUplink()
+	if err != nil {
+		return nil, err
+	}
+	mbrDL, err := qos.MBRForDownlink()
+	if err != nil {
+		return nil, err
+	}
+	gbrUL, err := qos.GBRForUplink()
+	if err != nil {
+		return nil, err
+	}
+	gbrDL, err := qos.GBRForDownlink()
+	if err != nil {
+		return nil, err
+	}
+
+	q := &QER{
+		GateStatus: &GateStatus{UL: GateOpen, DL: GateOpen},
+		MBR:        &Bitrate{UL: mbrUL, DL: mbrDL},
+	}
+	if gbrUL != 0 || gbrDL != 0 {
+		q.GBR = &Bitrate{UL: gbrUL, DL: gbrDL}
+	}
+	return q, nil
+}
+
+// BearerQoSFromQER converts QER of PFCP into the Bearer QoS IE of GTPv2 with
+// the ARP and QCI given, which QER does not have.
+func BearerQoSFromQER(q *QER, pci, pl, pvi, qci uint8) *ie.IE {
+	var mbr, gbr Bitrate
+	if q.MBR != nil {
+		mbr = *q.MBR
+	}
+	if q.GBR != nil {
+		gbr = *q.GBR
+	}
+	return ie.NewBearerQoS(pci, pl, pvi, qci, mbr.UL, mbr.DL, gbr.UL, gbr.DL)
+}
+
+// QERFromAMBR converts the AMBR IE of GTPv2 into QER of PFCP for APN-AMBR,
+// which is shared by all the bearers in the PDN connection.
+func QERFromAMBR(ambr *ie.IE) (*QER, error) {
+	f, err := ambr.AggregateMaximumBitRate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &QER{
+		GateStatus: &GateStatus{UL: GateOpen, DL: GateOpen},
+		MBR: &Bitrate{
+			UL: uint64(f.APNAMBRForUplink),
+			DL: uint64(f.APNAMBRForDownlink),
+		},
+	}, nil
+}
+
+// AMBRFromQER converts QER of PFCP into the AMBR IE of GTPv2. The bitrates
+// that exceed the range of AMBR are capped at the maximum.
+func AMBRFromQER(q *QER) *ie.IE {
+	var mbr Bitrate
+	if q.MBR != nil {
+		mbr = *q.MBR
+	}
+	return ie.NewAggregateMaximumBitRate(capUint32(mbr.UL), capUint32(mbr.DL))
+}
+
+func capUint32(v uint64) uint32 {
+	if v > math.MaxUint32 {
+		return math.MaxUint32
+	}
+	return uint32(v)
+}
+
+// PDRsFromTFT converts the packet filters in the Bearer TFT IE of GTPv2 that
+// apply to the direction given into PDRs of PFCP, one for each filter. dir
+// should be either ie.TFTPFUplinkOnly or ie.TFTPFDownlinkOnly, and the
+// bidirectional filters are included in both. The pre-Release 7 filters are
+// treated as downlink only as defined in TS 24.008.
+//
+// The PDRs have PDI with SDF Filter and Precedence from the packet filter,
+// and the other parameters are left for the caller to fill.
+func PDRsFromTFT(tft *ie.IE, dir uint8) ([]*PDR, error) {
+	f, err := tft.TrafficFlowTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	var pdrs []*PDR
+	for _, pf := range f.PacketFilters {
+		if !appliesTo(pf.Direction, dir) {
+			continue
+		}
+
+		sdf, err := SDFFilterFromPacketFilter(pf)
+		if err != nil {
+			return nil, err
+		}
+		pdrs = append(pdrs, &PDR{
+			Precedence: uint32(pf.Precedence),
+			PDI: &PDI{
+				SourceInterface: sourceInterface(dir),
+				SDFFilters:      []*SDFFilter{sdf},
+			},
+		})
+	}
+	return pdrs, nil
+}
+
+func appliesTo(pfDir, dir uint8) bool {
+	switch pfDir {
+	case ie.TFTPFBidirectional:
+		return true
+	case ie.TFTPFPreRel7TFTFilter:
+		return dir == ie.TFTPFDownlinkOnly
+	default:
+		return pfDir == dir
+	}
+}
+
+func sourceInterface(dir uint8) uint8 {
+	if dir == ie.TFTPFUplinkOnly {
+		return InterfaceAccess
+	}
+	return InterfaceCore
+}
+
+// SDFFilterFromPacketFilter converts the packet filter in TFT of GTPv2 into
+// SDFFilter of PFCP. SDF Filter ID is the packet filter identifier.
+//
+// The flow description is written in the form defined in TS 29.212 5.4.2,
+// "permit out <protocol> from <remote> [ports] to <local> [ports]", which is
+// the same for both directions. The UE address is "assigned" if the filter
+// does not specify it.
+func SDFFilterFromPacketFilter(pf *ie.TFTPacketFilter) (*SDFFilter, error) {
+	c, err := pf.Components()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SDFFilter{
+		FlowDescription: flowDescription(c),
+		HasSDFFilterID:  true,
+		SDFFilterID:     uint32(pf.Identifier),
+	}
+	if c.HasTypeOfService {
+		s.HasToSTrafficClass = true
+		s.ToSTrafficClass = uint16(c.TypeOfService)<<8 | uint16(c.TypeOfServiceMask)
+	}
+	if c.HasSecurityParameterIndex {
+		s.HasSecurityParameterIndex = true
+		s.SecurityParameterIndex = c.SecurityParameterIndex
+	}
+	if c.HasFlowLabel {
+		s.HasFlowLabel = true
+		s.FlowLabel = c.FlowLabel
+	}
+	return s, nil
+}
+
+// PacketFilterFromSDFFilter converts SDFFilter of PFCP into the packet filter
+// in TFT of GTPv2 with the identifier, direction and precedence given.
+//
+// It returns ErrUnsupportedFlowDescription if the flow description cannot be
+// represented with the packet filter components.
+func PacketFilterFromSDFFilter(s *SDFFilter, id, dir, prec uint8) (*ie.TFTPacketFilter, error) {
+	c := &ie.PacketFilterComponents{}
+	if s.FlowDescription != "" {
+		if err := parseFlowDescription(s.FlowDescription, c); err != nil {
+			return nil, err
+		}
+	}
+	if s.HasToSTrafficClass {
+		c.HasTypeOfService = true
+		c.TypeOfService = uint8(s.ToSTrafficClass >> 8)
+		c.TypeOfServiceMask = uint8(s.ToSTrafficClass)
+	}
+	if s.HasSecurityParameterIndex {
+		c.HasSecurityParameterIndex = true
+		c.SecurityParameterIndex = s.SecurityParameterIndex
+	}
+	if s.HasFlowLabel {
+		c.HasFlowLabel = true
+		c.FlowLabel = s.FlowLabel
+	}
+	return ie.NewTFTPacketFilter(id, dir, prec, c), nil
+}
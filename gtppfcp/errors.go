@@ -0,0 +1,16 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtppfcp
+
+import "errors"
+
+// Error definitions.
+var (
+	ErrTooShortToParse = errors.New("too short to decode as PFCP IE")
+	ErrChooseFTEID     = errors.New("F-TEID to be chosen by UP function has no TEID")
+	ErrNoAddress       = errors.New("no IP address given")
+
+	ErrUnsupportedFlowDescription = errors.New("unsupported flow description")
+)
@@ -0,0 +1,151 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtppfcp
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+// flowDescription builds the IPFilterRule in the form used in PCC and PFCP
+// from the packet filter components.
+func flowDescription(c *ie.PacketFilterComponents) string {
+	proto := "ip"
+	if c.HasProtocol {
+		proto = strconv.Itoa(int(c.Protocol))
+	}
+
+	from := "any"
+	if c.RemoteAddress != nil {
+		from = c.RemoteAddress.String()
+	}
+	to := "assigned"
+	if c.LocalAddress != nil {
+		to = c.LocalAddress.String()
+	}
+
+	return fmt.Sprintf(
+		"permit out %s from %s%s to %s%s",
+		proto, from, ports(c.RemotePortLow, c.RemotePortHigh), to, ports(c.LocalPortLow, c.LocalPortHigh),
+	)
+}
+
+func ports(low, high uint16) string {
+	switch {
+	case low == 0 && high == 0:
+		return ""
+	case high == 0 || low == high:
+		return fmt.Sprintf(" %d", low)
+	default:
+		return fmt.Sprintf(" %d-%d", low, high)
+	}
+}
+
+// parseFlowDescription parses the IPFilterRule in the form of flowDescription
+// into the packet filter components.
+func parseFlowDescription(fd string, c *ie.PacketFilterComponents) error {
+	unsupported := func(reason string) error {
+		return fmt.Errorf("%w: %s: %q", ErrUnsupportedFlowDescription, reason, fd)
+	}
+
+	f := strings.Fields(fd)
+	if len(f) < 7 || f[0] != "permit" || f[1] != "out" || f[3] != "from" {
+		return unsupported("not in the form of \"permit out <proto> from <src> to <dst>\"")
+	}
+
+	if f[2] != "ip" {
+		p, err := strconv.ParseUint(f[2], 10, 8)
+		if err != nil {
+			return unsupported("invalid protocol")
+		}
+		c.HasProtocol = true
+		c.Protocol = uint8(p)
+	}
+
+	var err error
+	f = f[4:]
+	if c.RemoteAddress, err = parseAddress(f[0]); err != nil {
+		return unsupported(err.Error())
+	}
+	f = f[1:]
+	if f[0] != "to" {
+		if c.RemotePortLow, c.RemotePortHigh, err = parsePorts(f[0]); err != nil {
+			return unsupported(err.Error())
+		}
+		f = f[1:]
+	}
+
+	if len(f) < 2 || f[0] != "to" {
+		return unsupported("missing destination")
+	}
+	if c.LocalAddress, err = parseAddress(f[1]); err != nil {
+		return unsupported(err.Error())
+	}
+	f = f[2:]
+	if len(f) > 0 {
+		if c.LocalPortLow, c.LocalPortHigh, err = parsePorts(f[0]); err != nil {
+			return unsupported(err.Error())
+		}
+		f = f[1:]
+	}
+	if len(f) > 0 {
+		return unsupported("options are not supported")
+	}
+	return nil
+}
+
+// parseAddress parses the address in IPFilterRule. It returns nil for "any"
+// and "assigned".
+func parseAddress(s string) (*net.IPNet, error) {
+	switch s {
+	case "any", "assigned":
+		return nil, nil
+	}
+
+	if strings.Contains(s, "/") {
+		ip, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, err
+		}
+		if v4 := ip.To4(); v4 != nil {
+			ip = v4
+		}
+		return &net.IPNet{IP: ip, Mask: n.Mask}, nil
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid address %q", s)
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return &net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// parsePorts parses the single port or the range of ports in IPFilterRule.
+func parsePorts(s string) (low, high uint16, err error) {
+	if strings.Contains(s, ",") {
+		return 0, 0, fmt.Errorf("list of ports %q", s)
+	}
+
+	lo, hi := s, s
+	if n := strings.Index(s, "-"); n >= 0 {
+		lo, hi = s[:n], s[n+1:]
+	}
+	l, err := strconv.ParseUint(lo, 10, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port %q", s)
+	}
+	h, err := strconv.ParseUint(hi, 10, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port %q", s)
+	}
+	return uint16(l), uint16(h), nil
+}
@@ -0,0 +1,348 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtppfcp_test
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/wmnsk/go-gtp/gtppfcp"
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+type serializable interface {
+	Marshal() ([]byte, error)
+}
+
+func TestValues(t *testing.T) {
+	cases := []struct {
+		description string
+		structured  serializable
+		serialized  []byte
+		parse       func([]byte) (serializable, error)
+	}{
+		{
+			"FTEID/IPv4",
+			&gtppfcp.FTEID{TEID: 0x11223344, IPv4Address: net.IP{10, 0, 0, 1}},
+			[]byte{0x01, 0x11, 0x22, 0x33, 0x44, 0x0a, 0x00, 0x00, 0x01},
+			func(b []byte) (serializable, error) { return gtppfcp.ParseFTEID(b) },
+		}, {
+			"FTEID/Choose",
+			&gtppfcp.FTEID{Choose: true, IPv4Address: net.IPv4zero, HasChooseID: true, ChooseID: 5},
+			[]byte{0x0d, 0x05},
+			func(b []byte) (serializable, error) { return gtppfcp.ParseFTEID(b) },
+		}, {
+			"Bitrate",
+			&gtppfcp.Bitrate{UL: 0x1111111111, DL: 0x2222222222},
+			[]byte{0x11, 0x11, 0x11, 0x11, 0x11, 0x22, 0x22, 0x22, 0x22, 0x22},
+			func(b []byte) (serializable, error) { return gtppfcp.ParseBitrate(b) },
+		}, {
+			"GateStatus",
+			&gtppfcp.GateStatus{UL: gtppfcp.GateClosed, DL: gtppfcp.GateOpen},
+			[]byte{0x04},
+			func(b []byte) (serializable, error) { return gtppfcp.ParseGateStatus(b) },
+		}, {
+			"OuterHeaderCreation",
+			&gtppfcp.OuterHeaderCreation{
+				Description: gtppfcp.OuterHeaderCreationGTPUUDPIPv4,
+				TEID:        0x11223344,
+				IPv4Address: net.IP{10, 0, 0, 1},
+			},
+			[]byte{0x01, 0x00, 0x11, 0x22, 0x33, 0x44, 0x0a, 0x00, 0x00, 0x01},
+			func(b []byte) (serializable, error) { return gtppfcp.ParseOuterHeaderCreation(b) },
+		}, {
+			"SDFFilter",
+			&gtppfcp.SDFFilter{
+				FlowDescription: "permit out ip from any to assigned",
+				HasFlowLabel:    true,
+				FlowLabel:       0x12345,
+				HasSDFFilterID:  true,
+				SDFFilterID:     1,
+			},
+			[]byte{
+				0x19, 0x00,
+				0x00, 0x22,
+				0x70, 0x65, 0x72, 0x6d, 0x69, 0x74, 0x20, 0x6f, 0x75, 0x74, 0x20, 0x69, 0x70, 0x20, 0x66, 0x72, 0x6f,
+				0x6d, 0x20, 0x61, 0x6e, 0x79, 0x20, 0x74, 0x6f, 0x20, 0x61, 0x73, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64,
+				0x01, 0x23, 0x45,
+				0x00, 0x00, 0x00, 0x01,
+			},
+			func(b []byte) (serializable, error) { return gtppfcp.ParseSDFFilter(b) },
+		},
+	}
+
+	for _, c := range cases {
+		t.Run("serialize/"+c.description, func(t *testing.T) {
+			got, err := c.structured.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(got, c.serialized); diff != "" {
+				t.Error(diff)
+			}
+		})
+
+		t.Run("decode/"+c.description, func(t *testing.T) {
+			got, err := c.parse(c.serialized)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(got, c.structured); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestFTEID(t *testing.T) {
+	s1u := ie.NewFullyQualifiedTEID(v2.IFTypeS1UeNodeBGTPU, 0x11223344, "10.0.0.1", "")
+
+	f, err := gtppfcp.FTEIDFromGTP(s1u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(f, &gtppfcp.FTEID{TEID: 0x11223344, IPv4Address: net.IP{10, 0, 0, 1}}); diff != "" {
+		t.Error(diff)
+	}
+
+	got, err := gtppfcp.FTEIDToGTP(f, v2.IFTypeS1UeNodeBGTPU)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got.Payload, s1u.Payload); diff != "" {
+		t.Error(diff)
+	}
+
+	if _, err := gtppfcp.FTEIDToGTP(&gtppfcp.FTEID{Choose: true}, v2.IFTypeS1USGWGTPU); !errors.Is(err, gtppfcp.ErrChooseFTEID) {
+		t.Errorf("got %v, want ErrChooseFTEID", err)
+	}
+
+	o, err := gtppfcp.OuterHeaderCreationFromGTP(s1u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &gtppfcp.OuterHeaderCreation{
+		Description: gtppfcp.OuterHeaderCreationGTPUUDPIPv4,
+		TEID:        0x11223344,
+		IPv4Address: net.IP{10, 0, 0, 1},
+	}
+	if diff := cmp.Diff(o, want); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestQER(t *testing.T) {
+	t.Run("BearerQoS", func(t *testing.T) {
+		qos := ie.NewBearerQoS(1, 2, 1, 1, 0x1111, 0x2222, 0x3333, 0x4444)
+		q, err := gtppfcp.QERFromBearerQoS(qos)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := &gtppfcp.QER{
+			GateStatus: &gtppfcp.GateStatus{},
+			MBR:        &gtppfcp.Bitrate{UL: 0x1111, DL: 0x2222},
+			GBR:        &gtppfcp.Bitrate{UL: 0x3333, DL: 0x4444},
+		}
+		if diff := cmp.Diff(q, want); diff != "" {
+			t.Error(diff)
+		}
+
+		if diff := cmp.Diff(gtppfcp.BearerQoSFromQER(q, 1, 2, 1, 1).Payload, qos.Payload); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("NonGBR", func(t *testing.T) {
+		q, err := gtppfcp.QERFromBearerQoS(ie.NewBearerQoS(1, 2, 1, 9, 0x1111, 0x2222, 0, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if q.GBR != nil {
+			t.Errorf("GBR should be nil for non-GBR bearer: %v", q.GBR)
+		}
+	})
+
+	t.Run("AMBR", func(t *testing.T) {
+		ambr := ie.NewAggregateMaximumBitRate(0x11111111, 0x22222222)
+		q, err := gtppfcp.QERFromAMBR(ambr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(q.MBR, &gtppfcp.Bitrate{UL: 0x11111111, DL: 0x22222222}); diff != "" {
+			t.Error(diff)
+		}
+
+		if diff := cmp.Diff(gtppfcp.AMBRFromQER(q).Payload, ambr.Payload); diff != "" {
+			t.Error(diff)
+		}
+
+		capped := gtppfcp.AMBRFromQER(&gtppfcp.QER{MBR: &gtppfcp.Bitrate{UL: 0x1ffffffff, DL: 1}})
+		if got := capped.MustAggregateMaximumBitRateUp(); got != 0xffffffff {
+			t.Errorf("AMBR should be capped: got %#x", got)
+		}
+	})
+}
+
+func TestPDRsFromTFT(t *testing.T) {
+	tft := ie.NewBearerTFT(ie.TFTOpCreateNewTFT, []*ie.TFTPacketFilter{
+		ie.NewTFTPacketFilter(1, ie.TFTPFUplinkOnly, 10, &ie.PacketFilterComponents{
+			RemoteAddress:  &net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)},
+			HasProtocol:    true,
+			Protocol:       17,
+			RemotePortLow:  5060,
+			RemotePortHigh: 5061,
+		}),
+		ie.NewTFTPacketFilter(2, ie.TFTPFDownlinkOnly, 20, &ie.PacketFilterComponents{
+			LocalAddress:      &net.IPNet{IP: net.IP{192, 168, 0, 1}, Mask: net.CIDRMask(32, 32)},
+			LocalPortLow:      8080,
+			HasTypeOfService:  true,
+			TypeOfService:     0xb8,
+			TypeOfServiceMask: 0xfc,
+		}),
+		ie.NewTFTPacketFilter(3, ie.TFTPFBidirectional, 30, &ie.PacketFilterComponents{}),
+	}, nil)
+
+	cases := []struct {
+		description string
+		dir         uint8
+		want        []*gtppfcp.PDR
+	}{
+		{
+			"Uplink",
+			ie.TFTPFUplinkOnly,
+			[]*gtppfcp.PDR{
+				{
+					Precedence: 10,
+					PDI: &gtppfcp.PDI{
+						SourceInterface: gtppfcp.InterfaceAccess,
+						SDFFilters: []*gtppfcp.SDFFilter{{
+							FlowDescription: "permit out 17 from 10.0.0.0/8 5060-5061 to assigned",
+							HasSDFFilterID:  true,
+							SDFFilterID:     1,
+						}},
+					},
+				}, {
+					Precedence: 30,
+					PDI: &gtppfcp.PDI{
+						SourceInterface: gtppfcp.InterfaceAccess,
+						SDFFilters: []*gtppfcp.SDFFilter{{
+							FlowDescription: "permit out ip from any to assigned",
+							HasSDFFilterID:  true,
+							SDFFilterID:     3,
+						}},
+					},
+				},
+			},
+		}, {
+			"Downlink",
+			ie.TFTPFDownlinkOnly,
+			[]*gtppfcp.PDR{
+				{
+					Precedence: 20,
+					PDI: &gtppfcp.PDI{
+						SourceInterface: gtppfcp.InterfaceCore,
+						SDFFilters: []*gtppfcp.SDFFilter{{
+							FlowDescription:    "permit out ip from any to 192.168.0.1/32 8080",
+							HasToSTrafficClass: true,
+							ToSTrafficClass:    0xb8fc,
+							HasSDFFilterID:     true,
+							SDFFilterID:        2,
+						}},
+					},
+				}, {
+					Precedence: 30,
+					PDI: &gtppfcp.PDI{
+						SourceInterface: gtppfcp.InterfaceCore,
+						SDFFilters: []*gtppfcp.SDFFilter{{
+							FlowDescription: "permit out ip from any to assigned",
+							HasSDFFilterID:  true,
+							SDFFilterID:     3,
+						}},
+					},
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			got, err := gtppfcp.PDRsFromTFT(tft, c.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(got, c.want); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestPacketFilterFromSDFFilter(t *testing.T) {
+	cases := []struct {
+		description string
+		flowDesc    string
+		want        *ie.PacketFilterComponents
+	}{
+		{
+			"Any",
+			"permit out ip from any to assigned",
+			&ie.PacketFilterComponents{},
+		}, {
+			"Full",
+			"permit out 6 from 10.0.0.0/8 443 to 192.168.0.1 1000-2000",
+			&ie.PacketFilterComponents{
+				RemoteAddress:  &net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)},
+				LocalAddress:   &net.IPNet{IP: net.IP{192, 168, 0, 1}, Mask: net.CIDRMask(32, 32)},
+				HasProtocol:    true,
+				Protocol:       6,
+				LocalPortLow:   1000,
+				LocalPortHigh:  2000,
+				RemotePortLow:  443,
+				RemotePortHigh: 443,
+			},
+		}, {
+			"IPv6",
+			"permit out 17 from 2001:db8::/32 to assigned 53",
+			&ie.PacketFilterComponents{
+				RemoteAddress: &net.IPNet{IP: net.ParseIP("2001:db8::"), Mask: net.CIDRMask(32, 128)},
+				HasProtocol:   true,
+				Protocol:      17,
+				LocalPortLow:  53,
+				LocalPortHigh: 53,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			pf, err := gtppfcp.PacketFilterFromSDFFilter(&gtppfcp.SDFFilter{FlowDescription: c.flowDesc}, 1, ie.TFTPFBidirectional, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := pf.Components()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(got, c.want); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	for _, fd := range []string{
+		"deny out ip from any to assigned",
+		"permit out ip from any to assigned 80,443",
+		"permit out ip from any to assigned frag",
+	} {
+		_, err := gtppfcp.PacketFilterFromSDFFilter(&gtppfcp.SDFFilter{FlowDescription: fd}, 1, ie.TFTPFBidirectional, 10)
+		if !errors.Is(err, gtppfcp.ErrUnsupportedFlowDescription) {
+			t.Errorf("%q: got %v, want ErrUnsupportedFlowDescription", fd, err)
+		}
+	}
+}
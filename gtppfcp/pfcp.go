@@ -0,0 +1,577 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Package gtppfcp converts the GTPv2-C constructs to the parameters of PFCP
+// (TS 29.244) rules and back, for the control plane of CUPS that talks GTPv2-C
+// on S11/S5-C and PFCP on Sxa/Sxb.
+//
+// The mappings are:
+//
+//	Bearer QoS (MBR/GBR)   <-> QER (MBR/GBR)
+//	AMBR                   <-> QER (MBR) for APN-AMBR
+//	F-TEID                 <-> F-TEID in PDI, Outer Header Creation in FAR
+//	TFT packet filters     <-> SDF Filters in PDI, with precedence
+//
+// The values of the PFCP IEs, such as FTEID, Bitrate and SDFFilter, can be
+// serialized into and decoded from the payload of the corresponding IE, so
+// they can be used with any PFCP implementation. With go-pfcp, for example:
+//
+//	f, err := gtppfcp.FTEIDFromGTP(s1uFTEID)
+//	b, err := f.Marshal()
+//	fteid := pfcpie.New(pfcpie.FTEID, b)
+//
+// The grouped IEs like Create PDR/FAR/QER are left to the PFCP implementation,
+// and PDI, FAR and QER in this package just hold the parameters for them.
+package gtppfcp
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+
+	"github.com/wmnsk/go-gtp/utils"
+)
+
+// Interface value definitions used in Source Interface and Destination
+// Interface IEs.
+const (
+	InterfaceAccess uint8 = iota
+	InterfaceCore
+	InterfaceSGiLAN
+	InterfaceCPFunction
+)
+
+// Apply Action flag definitions.
+const (
+	ApplyActionDROP uint8 = 1 << iota
+	ApplyActionFORW
+	ApplyActionBUFF
+	ApplyActionNOCP
+	ApplyActionDUPL
+)
+
+// Gate Status value definitions.
+const (
+	GateOpen uint8 = iota
+	GateClosed
+)
+
+// Outer Header Creation Description definitions.
+const (
+	OuterHeaderCreationGTPUUDPIPv4 uint16 = 0x0100 << iota
+	OuterHeaderCreationGTPUUDPIPv6
+	OuterHeaderCreationUDPIPv4
+	OuterHeaderCreationUDPIPv6
+)
+
+// Outer Header Removal Description definitions.
+const (
+	OuterHeaderRemovalGTPUUDPIPv4 uint8 = iota
+	OuterHeaderRemovalGTPUUDPIPv6
+)
+
+// PDI is a set of parameters in PDI IE of Create PDR.
+type PDI struct {
+	SourceInterface uint8
+	FTEID           *FTEID
+	NetworkInstance string
+	UEIPAddress     net.IP
+	SDFFilters      []*SDFFilter
+}
+
+// PDR is a set of parameters in Create PDR IE, with the packet filters of TFT
+// mapped to the SDF Filters in PDI and their precedence.
+type PDR struct {
+	PDRID      uint16
+	Precedence uint32
+	PDI        *PDI
+	FARID      uint32
+	QERIDs     []uint32
+}
+
+// FAR is a set of parameters in Create FAR IE.
+type FAR struct {
+	FARID                uint32
+	ApplyAction          uint8
+	DestinationInterface uint8
+	NetworkInstance      string
+	OuterHeaderCreation  *OuterHeaderCreation
+}
+
+// QER is a set of parameters in Create QER IE. MBR and GBR are nil if not
+// present.
+type QER struct {
+	QERID      uint32
+	GateStatus *GateStatus
+	MBR        *Bitrate
+	GBR        *Bitrate
+}
+
+// FTEID is the value of F-TEID IE defined in TS 29.244 8.2.3.
+type FTEID struct {
+	TEID        uint32
+	IPv4Address net.IP
+	IPv6Address net.IP
+
+	// Choose requests the UP function to assign the TEID and addresses. The
+	// TEID is ignored when it is set, and the non-nil address (typically the
+	// unspecified address) requests the address of the family to be assigned.
+	Choose      bool
+	HasChooseID bool
+	ChooseID    uint8
+}
+
+// Marshal serializes FTEID.
+func (f *FTEID) Marshal() ([]byte, error) {
+	b := make([]byte, f.MarshalLen())
+	if err := f.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalTo serializes FTEID.
+func (f *FTEID) MarshalTo(b []byte) error {
+	if len(b) < f.MarshalLen() {
+		return io.ErrUnexpectedEOF
+	}
+
+	if f.Choose {
+		b[0] = 0x04
+		if f.IPv4Address != nil {
+			b[0] |= 0x01
+		}
+		if f.IPv6Address != nil {
+			b[0] |= 0x02
+		}
+		if f.HasChooseID {
+			b[0] |= 0x08
+			b[1] = f.ChooseID
+		}
+		return nil
+	}
+
+	binary.BigEndian.PutUint32(b[1:5], f.TEID)
+	offset := 5
+	if v4 := f.IPv4Address.To4(); v4 != nil {
+		b[0] |= 0x01
+		copy(b[offset:offset+4], v4)
+		offset += 4
+	}
+	if f.IPv6Address != nil {
+		b[0] |= 0x02
+		copy(b[offset:offset+16], f.IPv6Address.To16())
+	}
+	return nil
+}
+
+// ParseFTEID decodes FTEID.
+func ParseFTEID(b []byte) (*FTEID, error) {
+	f := &FTEID{}
+	if err := f.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// UnmarshalBinary decodes given bytes into FTEID.
+func (f *FTEID) UnmarshalBinary(b []byte) error {
+	l := len(b)
+	if l < 1 {
+		return ErrTooShortToParse
+	}
+
+	flags := b[0]
+	f.Choose = flags&0x04 != 0
+	if f.Choose {
+		if flags&0x01 != 0 {
+			f.IPv4Address = net.IPv4zero
+		}
+		if flags&0x02 != 0 {
+			f.IPv6Address = net.IPv6unspecified
+		}
+		if flags&0x08 != 0 {
+			if l < 2 {
+				return ErrTooShortToParse
+			}
+			f.HasChooseID = true
+			f.ChooseID = b[1]
+		}
+		return nil
+	}
+
+	if l < 5 {
+		return ErrTooShortToParse
+	}
+	f.TEID = binary.BigEndian.Uint32(b[1:5])
+	offset := 5
+	if flags&0x01 != 0 {
+		if l < offset+4 {
+			return ErrTooShortToParse
+		}
+		f.IPv4Address = net.IP(b[offset : offset+4])
+		offset += 4
+	}
+	if flags&0x02 != 0 {
+		if l < offset+16 {
+			return ErrTooShortToParse
+		}
+		f.IPv6Address = net.IP(b[offset : offset+16])
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of FTEID.
+func (f *FTEID) MarshalLen() int {
+	if f.Choose {
+		if f.HasChooseID {
+			return 2
+		}
+		return 1
+	}
+
+	l := 5
+	if f.IPv4Address.To4() != nil {
+		l += 4
+	}
+	if f.IPv6Address != nil {
+		l += 16
+	}
+	return l
+}
+
+// Bitrate is the value of MBR IE and GBR IE defined in TS 29.244 8.2.8 and
+// 8.2.9, in kbps.
+type Bitrate struct {
+	UL uint64 // 40 bits
+	DL uint64 // 40 bits
+}
+
+// Marshal serializes Bitrate.
+func (r *Bitrate) Marshal() ([]byte, error) {
+	b := make([]byte, r.MarshalLen())
+	if err := r.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalTo serializes Bitrate.
+func (r *Bitrate) MarshalTo(b []byte) error {
+	if len(b) < 10 {
+		return io.ErrUnexpectedEOF
+	}
+	copy(b[0:5], utils.Uint64To40(r.UL))
+	copy(b[5:10], utils.Uint64To40(r.DL))
+	return nil
+}
+
+// ParseBitrate decodes Bitrate.
+func ParseBitrate(b []byte) (*Bitrate, error) {
+	r := &Bitrate{}
+	if err := r.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// UnmarshalBinary decodes given bytes into Bitrate.
+func (r *Bitrate) UnmarshalBinary(b []byte) error {
+	if len(b) < 10 {
+		return ErrTooShortToParse
+	}
+	r.UL = utils.Uint40To64(b[0:5])
+	r.DL = utils.Uint40To64(b[5:10])
+	return nil
+}
+
+// MarshalLen returns the serial length of Bitrate.
+func (r *Bitrate) MarshalLen() int {
+	return 10
+}
+
+// GateStatus is the value of Gate Status IE defined in TS 29.244 8.2.7.
+type GateStatus struct {
+	UL uint8
+	DL uint8
+}
+
+// Marshal serializes GateStatus.
+func (g *GateStatus) Marshal() ([]byte, error) {
+	return []byte{(g.UL&0x03)<<2 | g.DL&0x03}, nil
+}
+
+// ParseGateStatus decodes GateStatus.
+func ParseGateStatus(b []byte) (*GateStatus, error) {
+	if len(b) < 1 {
+		return nil, ErrTooShortToParse
+	}
+	return &GateStatus{UL: (b[0] >> 2) & 0x03, DL: b[0] & 0x03}, nil
+}
+
+// OuterHeaderCreation is the value of Outer Header Creation IE defined in
+// TS 29.244 8.2.56.
+type OuterHeaderCreation struct {
+	Description uint16
+	TEID        uint32
+	IPv4Address net.IP
+	IPv6Address net.IP
+	PortNumber  uint16
+}
+
+// Marshal serializes OuterHeaderCreation.
+func (o *OuterHeaderCreation) Marshal() ([]byte, error) {
+	b := make([]byte, o.MarshalLen())
+	if err := o.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalTo serializes OuterHeaderCreation.
+func (o *OuterHeaderCreation) MarshalTo(b []byte) error {
+	if len(b) < o.MarshalLen() {
+		return io.ErrUnexpectedEOF
+	}
+
+	binary.BigEndian.PutUint16(b[0:2], o.Description)
+	offset := 2
+	if o.hasTEID() {
+		binary.BigEndian.PutUint32(b[offset:offset+4], o.TEID)
+		offset += 4
+	}
+	if o.hasIPv4() {
+		copy(b[offset:offset+4], o.IPv4Address.To4())
+		offset += 4
+	}
+	if o.hasIPv6() {
+		copy(b[offset:offset+16], o.IPv6Address.To16())
+		offset += 16
+	}
+	if o.hasPort() {
+		binary.BigEndian.PutUint16(b[offset:offset+2], o.PortNumber)
+	}
+	return nil
+}
+
+// ParseOuterHeaderCreation decodes OuterHeaderCreation.
+func ParseOuterHeaderCreation(b []byte) (*OuterHeaderCreation, error) {
+	o := &OuterHeaderCreation{}
+	if err := o.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// UnmarshalBinary decodes given bytes into OuterHeaderCreation.
+func (o *OuterHeaderCreation) UnmarshalBinary(b []byte) error {
+	if len(b) < 2 {
+		return ErrTooShortToParse
+	}
+	o.Description = binary.BigEndian.Uint16(b[0:2])
+	if len(b) < o.MarshalLen() {
+		return ErrTooShortToParse
+	}
+
+	offset := 2
+	if o.hasTEID() {
+		o.TEID = binary.BigEndian.Uint32(b[offset : offset+4])
+		offset += 4
+	}
+	if o.hasIPv4() {
+		o.IPv4Address = net.IP(b[offset : offset+4])
+		offset += 4
+	}
+	if o.hasIPv6() {
+		o.IPv6Address = net.IP(b[offset : offset+16])
+		offset += 16
+	}
+	if o.hasPort() {
+		o.PortNumber = binary.BigEndian.Uint16(b[offset : offset+2])
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of OuterHeaderCreation.
+func (o *OuterHeaderCreation) MarshalLen() int {
+	l := 2
+	if o.hasTEID() {
+		l += 4
+	}
+	if o.hasIPv4() {
+		l += 4
+	}
+	if o.hasIPv6() {
+		l += 16
+	}
+	if o.hasPort() {
+		l += 2
+	}
+	return l
+}
+
+func (o *OuterHeaderCreation) hasTEID() bool {
+	return o.Description&(OuterHeaderCreationGTPUUDPIPv4|OuterHeaderCreationGTPUUDPIPv6) != 0
+}
+
+func (o *OuterHeaderCreation) hasIPv4() bool {
+	return o.Description&(OuterHeaderCreationGTPUUDPIPv4|OuterHeaderCreationUDPIPv4) != 0
+}
+
+func (o *OuterHeaderCreation) hasIPv6() bool {
+	return o.Description&(OuterHeaderCreationGTPUUDPIPv6|OuterHeaderCreationUDPIPv6) != 0
+}
+
+func (o *OuterHeaderCreation) hasPort() bool {
+	return o.Description&(OuterHeaderCreationUDPIPv4|OuterHeaderCreationUDPIPv6) != 0
+}
+
+// SDFFilter is the value of SDF Filter IE defined in TS 29.244 8.2.5.
+//
+// The fields other than FlowDescription are present only when the Has* field
+// is set.
+type SDFFilter struct {
+	FlowDescription string
+
+	HasToSTrafficClass bool
+	ToSTrafficClass    uint16 // value and mask
+
+	HasSecurityParameterIndex bool
+	SecurityParameterIndex    uint32
+
+	HasFlowLabel bool
+	FlowLabel    uint32 // 20 bits
+
+	HasSDFFilterID bool
+	SDFFilterID    uint32
+}
+
+// Marshal serializes SDFFilter.
+func (s *SDFFilter) Marshal() ([]byte, error) {
+	b := make([]byte, s.MarshalLen())
+	if err := s.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalTo serializes SDFFilter.
+func (s *SDFFilter) MarshalTo(b []byte) error {
+	if len(b) < s.MarshalLen() {
+		return io.ErrUnexpectedEOF
+	}
+
+	b[0], b[1] = 0, 0
+	offset := 2
+	if s.FlowDescription != "" {
+		b[0] |= 0x01
+		binary.BigEndian.PutUint16(b[offset:offset+2], uint16(len(s.FlowDescription)))
+		copy(b[offset+2:], s.FlowDescription)
+		offset += 2 + len(s.FlowDescription)
+	}
+	if s.HasToSTrafficClass {
+		b[0] |= 0x02
+		binary.BigEndian.PutUint16(b[offset:offset+2], s.ToSTrafficClass)
+		offset += 2
+	}
+	if s.HasSecurityParameterIndex {
+		b[0] |= 0x04
+		binary.BigEndian.PutUint32(b[offset:offset+4], s.SecurityParameterIndex)
+		offset += 4
+	}
+	if s.HasFlowLabel {
+		b[0] |= 0x08
+		copy(b[offset:offset+3], utils.Uint32To24(s.FlowLabel&0xfffff))
+		offset += 3
+	}
+	if s.HasSDFFilterID {
+		b[0] |= 0x10
+		binary.BigEndian.PutUint32(b[offset:offset+4], s.SDFFilterID)
+	}
+	return nil
+}
+
+// ParseSDFFilter decodes SDFFilter.
+func ParseSDFFilter(b []byte) (*SDFFilter, error) {
+	s := &SDFFilter{}
+	if err := s.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// UnmarshalBinary decodes given bytes into SDFFilter.
+func (s *SDFFilter) UnmarshalBinary(b []byte) error {
+	l := len(b)
+	if l < 2 {
+		return ErrTooShortToParse
+	}
+
+	flags := b[0]
+	offset := 2
+	if flags&0x01 != 0 {
+		if l < offset+2 {
+			return ErrTooShortToParse
+		}
+		n := int(binary.BigEndian.Uint16(b[offset : offset+2]))
+		if l < offset+2+n {
+			return ErrTooShortToParse
+		}
+		s.FlowDescription = string(b[offset+2 : offset+2+n])
+		offset += 2 + n
+	}
+	if flags&0x02 != 0 {
+		if l < offset+2 {
+			return ErrTooShortToParse
+		}
+		s.HasToSTrafficClass = true
+		s.ToSTrafficClass = binary.BigEndian.Uint16(b[offset : offset+2])
+		offset += 2
+	}
+	if flags&0x04 != 0 {
+		if l < offset+4 {
+			return ErrTooShortToParse
+		}
+		s.HasSecurityParameterIndex = true
+		s.SecurityParameterIndex = binary.BigEndian.Uint32(b[offset : offset+4])
+		offset += 4
+	}
+	if flags&0x08 != 0 {
+		if l < offset+3 {
+			return ErrTooShortToParse
+		}
+		s.HasFlowLabel = true
+		s.FlowLabel = utils.Uint24To32(b[offset:offset+3]) & 0xfffff
+		offset += 3
+	}
+	if flags&0x10 != 0 {
+		if l < offset+4 {
+			return ErrTooShortToParse
+		}
+		s.HasSDFFilterID = true
+		s.SDFFilterID = binary.BigEndian.Uint32(b[offset : offset+4])
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of SDFFilter.
+func (s *SDFFilter) MarshalLen() int {
+	l := 2
+	if s.FlowDescription != "" {
+		l += 2 + len(s.FlowDescription)
+	}
+	if s.HasToSTrafficClass {
+		l += 2
+	}
+	if s.HasSecurityParameterIndex {
+		l += 4
+	}
+	if s.HasFlowLabel {
+		l += 3
+	}
+	if s.HasSDFFilterID {
+		l += 4
+	}
+	return l
+}
@@ -0,0 +1,44 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpprime
+
+// Registered port, which is used for both UDP and TCP.
+const GTPPort = ":3386"
+
+// Cause definitions.
+const (
+	CauseSystemFailure                  uint8 = 59
+	CauseTransmitBuffersAreBecomingFull uint8 = 60
+	CauseReceiveBuffersAreBecomingFull  uint8 = 61
+	CauseAnotherNodeIsAboutToGoDown     uint8 = 62
+	CauseThisNodeIsAboutToGoDown        uint8 = 63
+
+	CauseRequestAccepted uint8 = 128
+
+	CauseCDRDecodingError                                          uint8 = 177
+	CauseNonExistent                                               uint8 = 192
+	CauseInvalidMessageFormat                                      uint8 = 193
+	CauseRequestRelatedToPossiblyDuplicatedPacketsAlreadyFulfilled uint8 = 252
+	CauseRequestAlreadyFulfilled                                   uint8 = 253
+	CauseSequenceNumbersOfReleasedCancelledPacketsIEIncorrect      uint8 = 254
+	CauseRequestNotFulfilled                                       uint8 = 255
+)
+
+// Packet Transfer Command definitions.
+const (
+	_ uint8 = iota
+	PacketTransferCommandSendDataRecordPacket
+	PacketTransferCommandSendPossiblyDuplicatedDataRecordPacket
+	PacketTransferCommandCancelDataRecordPacket
+	PacketTransferCommandReleaseDataRecordPacket
+)
+
+// Data Record Format definitions.
+const (
+	_ uint8 = iota
+	DataRecordFormatBER
+	DataRecordFormatUnalignedPER
+	DataRecordFormatAlignedPER
+)
@@ -0,0 +1,10 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Package gtpprime provides the simple and painless handling of GTP' protocol in pure Golang.
+//
+// GTP' is used to transfer the data records(e.g., CDRs) from CDF to CGF, which is
+// defined in 3GPP TS 32.295. This package provides the messages and IEs only, with
+// which the nodes can be implemented over any transport. See message and ie directory.
+package gtpprime
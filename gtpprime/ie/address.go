@@ -0,0 +1,75 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+import (
+	"io"
+	"net"
+)
+
+// NewChargingGatewayAddress creates a new ChargingGatewayAddress IE from string.
+//
+// In Node Alive Request, this is used as Node Address and Alternative Node Address.
+func NewChargingGatewayAddress(addr string) *IE {
+	return newAddressIE(ChargingGatewayAddress, addr)
+}
+
+// NewAddressOfRecommendedNode creates a new AddressOfRecommendedNode IE from string.
+func NewAddressOfRecommendedNode(addr string) *IE {
+	return newAddressIE(AddressOfRecommendedNode, addr)
+}
+
+// IP returns IP in net.IP if type matches.
+//
+// This can be used for ChargingGatewayAddress and AddressOfRecommendedNode.
+func (i *IE) IP() (net.IP, error) {
+	switch i.Type {
+	case ChargingGatewayAddress, AddressOfRecommendedNode:
+	default:
+		return nil, &InvalidTypeError{Type: i.Type}
+	}
+
+	switch len(i.Payload) {
+	case 4, 16:
+		return net.IP(i.Payload), nil
+	case 0:
+		return nil, io.ErrUnexpectedEOF
+	default:
+		return nil, ErrMalformed
+	}
+}
+
+// MustIP returns IP in net.IP if type matches.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustIP() net.IP {
+	v, _ := i.IP()
+	return v
+}
+
+// IPAddress returns IP in string if type matches.
+//
+// This can be used for ChargingGatewayAddress and AddressOfRecommendedNode.
+func (i *IE) IPAddress() (string, error) {
+	ip, err := i.IP()
+	if err != nil {
+		return "", err
+	}
+	return ip.String(), nil
+}
+
+// MustIPAddress returns IP in string if type matches.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustIPAddress() string {
+	v, _ := i.IPAddress()
+	return v
+}
+
+func newAddressIE(t uint8, addr string) *IE {
+	ip := net.ParseIP(addr)
+	if v4 := ip.To4(); v4 != nil {
+		return New(t, v4)
+	}
+	return New(t, ip)
+}
@@ -0,0 +1,144 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// NewDataRecordPacket creates a new DataRecordPacket IE that contains the data
+// records(e.g., CDRs encoded in ASN.1 BER) given.
+func NewDataRecordPacket(format, appID, releaseID, versionID uint8, records ...[]byte) *IE {
+	return NewDataRecordPacketFromFields(&DataRecordPacketFields{
+		Format:                format,
+		ApplicationIdentifier: appID,
+		ReleaseIdentifier:     releaseID,
+		VersionIdentifier:     versionID,
+		Records:               records,
+	})
+}
+
+// NewDataRecordPacketFromFields creates a new DataRecordPacket IE from DataRecordPacketFields.
+func NewDataRecordPacketFromFields(f *DataRecordPacketFields) *IE {
+	b, err := f.Marshal()
+	if err != nil {
+		return nil
+	}
+	return New(DataRecordPacket, b)
+}
+
+// DataRecordPacket returns DataRecordPacket in DataRecordPacketFields type if the type of IE matches.
+func (i *IE) DataRecordPacket() (*DataRecordPacketFields, error) {
+	if i.Type != DataRecordPacket {
+		return nil, &InvalidTypeError{Type: i.Type}
+	}
+	return ParseDataRecordPacketFields(i.Payload)
+}
+
+// MustDataRecordPacket returns DataRecordPacket in DataRecordPacketFields type if the type of IE matches.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustDataRecordPacket() *DataRecordPacketFields {
+	v, _ := i.DataRecordPacket()
+	return v
+}
+
+// DataRecordPacketFields is a set of fields in DataRecordPacket IE.
+//
+// Format is the encoding of the data records, e.g., DataRecordFormatBER. The
+// identifiers are the ones in Data Record Format Version, which are the application
+// and the release of 3GPP specifications that define the data records.
+// The Number of Data Records is the length of Records.
+type DataRecordPacketFields struct {
+	Format                uint8
+	ApplicationIdentifier uint8
+	ReleaseIdentifier     uint8
+	VersionIdentifier     uint8
+	Records               [][]byte
+}
+
+// Marshal serializes DataRecordPacketFields.
+func (f *DataRecordPacketFields) Marshal() ([]byte, error) {
+	b := make([]byte, f.MarshalLen())
+	if err := f.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalTo serializes DataRecordPacketFields.
+func (f *DataRecordPacketFields) MarshalTo(b []byte) error {
+	if len(b) < f.MarshalLen() {
+		return io.ErrUnexpectedEOF
+	}
+	if len(f.Records) > 0xff {
+		return ErrInvalidLength
+	}
+
+	b[0] = uint8(len(f.Records))
+	b[1] = f.Format
+	b[2] = (f.ApplicationIdentifier&0x0f)<<4 | (f.ReleaseIdentifier & 0x0f)
+	b[3] = f.VersionIdentifier
+	offset := 4
+
+	for _, r := range f.Records {
+		if len(r) > 0xffff {
+			return ErrInvalidLength
+		}
+		binary.BigEndian.PutUint16(b[offset:offset+2], uint16(len(r)))
+		copy(b[offset+2:], r)
+		offset += 2 + len(r)
+	}
+	return nil
+}
+
+// ParseDataRecordPacketFields decodes DataRecordPacketFields.
+func ParseDataRecordPacketFields(b []byte) (*DataRecordPacketFields, error) {
+	f := &DataRecordPacketFields{}
+	if err := f.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// UnmarshalBinary decodes given bytes into DataRecordPacketFields.
+func (f *DataRecordPacketFields) UnmarshalBinary(b []byte) error {
+	l := len(b)
+	if l < 4 {
+		return io.ErrUnexpectedEOF
+	}
+
+	n := int(b[0])
+	f.Format = b[1]
+	f.ApplicationIdentifier = b[2] >> 4
+	f.ReleaseIdentifier = b[2] & 0x0f
+	f.VersionIdentifier = b[3]
+	offset := 4
+
+	f.Records = make([][]byte, 0, n)
+	for j := 0; j < n; j++ {
+		if l < offset+2 {
+			return io.ErrUnexpectedEOF
+		}
+		rlen := int(binary.BigEndian.Uint16(b[offset : offset+2]))
+		offset += 2
+
+		if l < offset+rlen {
+			return io.ErrUnexpectedEOF
+		}
+		f.Records = append(f.Records, b[offset:offset+rlen])
+		offset += rlen
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of DataRecordPacketFields.
+func (f *DataRecordPacketFields) MarshalLen() int {
+	l := 4
+	for _, r := range f.Records {
+		l += 2 + len(r)
+	}
+	return l
+}
@@ -0,0 +1,185 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Package ie provides encoding/decoding feature of GTP' Information Elements.
+*/
+package ie
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// TV IE definitions.
+const (
+	Cause                 uint8 = 1
+	Recovery              uint8 = 14
+	PacketTransferCommand uint8 = 126
+)
+
+// TLV IE definitions.
+const (
+	SequenceNumbersOfReleasedPackets  uint8 = 249
+	SequenceNumbersOfCancelledPackets uint8 = 250
+	ChargingGatewayAddress            uint8 = 251
+	DataRecordPacket                  uint8 = 252
+	RequestsResponded                 uint8 = 253
+	AddressOfRecommendedNode          uint8 = 254
+	PrivateExtension                  uint8 = 255
+)
+
+// IE is a GTP' Information Element.
+type IE struct {
+	Type    uint8
+	Length  uint16
+	Payload []byte
+}
+
+// New creates new IE.
+func New(t uint8, p []byte) *IE {
+	i := &IE{Type: t, Payload: p}
+	i.SetLength()
+	return i
+}
+
+// Marshal returns the byte sequence generated from an IE instance.
+func (i *IE) Marshal() ([]byte, error) {
+	b := make([]byte, i.MarshalLen())
+	if err := i.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (i *IE) MarshalTo(b []byte) error {
+	if len(b) < i.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+
+	var offset = 1
+	b[0] = i.Type
+	if !i.IsTV() {
+		binary.BigEndian.PutUint16(b[1:3], i.Length)
+		offset += 2
+	}
+	copy(b[offset:i.MarshalLen()], i.Payload)
+	return nil
+}
+
+// Parse Parses given byte sequence as a GTP' Information Element.
+func Parse(b []byte) (*IE, error) {
+	i := &IE{}
+	if err := i.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in GTP' IE.
+func (i *IE) UnmarshalBinary(b []byte) error {
+	if len(b) < 2 {
+		return ErrTooShortToParse
+	}
+
+	i.Type = b[0]
+	if i.IsTV() {
+		return parseTVFromBytes(i, b)
+	}
+	return parseTLVFromBytes(i, b)
+}
+
+func parseTVFromBytes(i *IE, b []byte) error {
+	if _, ok := tvLengthMap[i.Type]; !ok {
+		return &InvalidTypeError{Type: i.Type}
+	}
+	if i.MarshalLen() > len(b) {
+		return ErrInvalidLength
+	}
+	i.Length = 0
+	i.Payload = b[1:i.MarshalLen()]
+
+	return nil
+}
+
+func parseTLVFromBytes(i *IE, b []byte) error {
+	l := len(b)
+	if l < 3 {
+		return ErrTooShortToParse
+	}
+
+	i.Length = binary.BigEndian.Uint16(b[1:3])
+	if int(i.Length)+3 > l {
+		return ErrInvalidLength
+	}
+
+	i.Payload = b[3 : 3+int(i.Length)]
+	return nil
+}
+
+var tvLengthMap = map[uint8]int{
+	1:   1, // Cause
+	14:  1, // Recovery
+	126: 1, // Packet Transfer Command
+}
+
+// IsTV checks if a IE is TV format. If false, it indicates the IE has Length inside.
+func (i *IE) IsTV() bool {
+	return int(i.Type) < 0x80
+}
+
+// MarshalLen returns the serial length of IE.
+func (i *IE) MarshalLen() int {
+	if l, ok := tvLengthMap[i.Type]; ok {
+		return l + 1
+	}
+	if i.IsTV() {
+		return 1 + len(i.Payload)
+	}
+	return 3 + len(i.Payload)
+}
+
+// SetLength sets the length in Length field.
+func (i *IE) SetLength() {
+	if i.IsTV() {
+		i.Length = 0
+		return
+	}
+
+	i.Length = uint16(len(i.Payload))
+}
+
+// String returns the GTP' IE values in human readable format.
+func (i *IE) String() string {
+	return fmt.Sprintf("{Type: %d, Length: %d, Payload: %#v}",
+		i.Type,
+		i.Length,
+		i.Payload,
+	)
+}
+
+// ParseMultiIEs Parses multiple (unspecified number of) IEs to []*IE at a time.
+func ParseMultiIEs(b []byte) ([]*IE, error) {
+	var ies []*IE
+	for {
+		if len(b) == 0 {
+			break
+		}
+
+		i, err := Parse(b)
+		if err != nil {
+			return nil, err
+		}
+
+		ies = append(ies, i)
+		b = b[i.MarshalLen():]
+		continue
+	}
+	return ies, nil
+}
+
+func newUint8ValIE(t, v uint8) *IE {
+	return New(t, []byte{v})
+}
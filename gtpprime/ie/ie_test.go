@@ -0,0 +1,150 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/wmnsk/go-gtp/gtpprime"
+	"github.com/wmnsk/go-gtp/gtpprime/ie"
+)
+
+func TestIE(t *testing.T) {
+	cases := []struct {
+		description string
+		structured  *ie.IE
+		serialized  []byte
+	}{
+		{
+			"Cause",
+			ie.NewCause(gtpprime.CauseRequestAccepted),
+			[]byte{0x01, 0x80},
+		}, {
+			"Recovery",
+			ie.NewRecovery(0x80),
+			[]byte{0x0e, 0x80},
+		}, {
+			"PacketTransferCommand",
+			ie.NewPacketTransferCommand(gtpprime.PacketTransferCommandSendDataRecordPacket),
+			[]byte{0x7e, 0x01},
+		}, {
+			"SequenceNumbersOfReleasedPackets",
+			ie.NewSequenceNumbersOfReleasedPackets(0x0001, 0x0002),
+			[]byte{0xf9, 0x00, 0x04, 0x00, 0x01, 0x00, 0x02},
+		}, {
+			"SequenceNumbersOfCancelledPackets",
+			ie.NewSequenceNumbersOfCancelledPackets(0x0003),
+			[]byte{0xfa, 0x00, 0x02, 0x00, 0x03},
+		}, {
+			"ChargingGatewayAddress",
+			ie.NewChargingGatewayAddress("1.1.1.1"),
+			[]byte{0xfb, 0x00, 0x04, 0x01, 0x01, 0x01, 0x01},
+		}, {
+			"DataRecordPacket",
+			ie.NewDataRecordPacket(
+				gtpprime.DataRecordFormatBER, 1, 8, 0x10,
+				[]byte{0xde, 0xad}, []byte{0xbe, 0xef, 0x00},
+			),
+			[]byte{
+				0xfc, 0x00, 0x0d,
+				// Number of Data Records, Data Record Format
+				0x02, 0x01,
+				// Data Record Format Version
+				0x18, 0x10,
+				// Data Records
+				0x00, 0x02, 0xde, 0xad,
+				0x00, 0x03, 0xbe, 0xef, 0x00,
+			},
+		}, {
+			"RequestsResponded",
+			ie.NewRequestsResponded(0x0001),
+			[]byte{0xfd, 0x00, 0x02, 0x00, 0x01},
+		}, {
+			"AddressOfRecommendedNode",
+			ie.NewAddressOfRecommendedNode("2001::1"),
+			[]byte{
+				0xfe, 0x00, 0x10,
+				0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+			},
+		}, {
+			"PrivateExtension",
+			ie.NewPrivateExtension(0x0080, []byte{0xde, 0xad, 0xbe, 0xef}),
+			[]byte{0xff, 0x00, 0x06, 0x00, 0x80, 0xde, 0xad, 0xbe, 0xef},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run("serialize/"+c.description, func(t *testing.T) {
+			got, err := c.structured.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(got, c.serialized); diff != "" {
+				t.Error(diff)
+			}
+		})
+
+		t.Run("decode/"+c.description, func(t *testing.T) {
+			got, err := ie.Parse(c.serialized)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(got, c.structured); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetters(t *testing.T) {
+	seqs, err := ie.NewRequestsResponded(0x0001, 0xffff).SequenceNumbers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(seqs, []uint16{0x0001, 0xffff}); diff != "" {
+		t.Error(diff)
+	}
+
+	ip, err := ie.NewChargingGatewayAddress("1.1.1.1").IP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ip.Equal(net.ParseIP("1.1.1.1")) {
+		t.Errorf("wrong IP: %s", ip)
+	}
+
+	if _, err := ie.NewCause(gtpprime.CauseRequestAccepted).SequenceNumbers(); err == nil {
+		t.Error("expected error with invalid type")
+	}
+}
+
+func TestDataRecordPacket(t *testing.T) {
+	records := [][]byte{{0xde, 0xad}, {0xbe, 0xef, 0x00}}
+	f, err := ie.NewDataRecordPacket(gtpprime.DataRecordFormatBER, 1, 8, 0x10, records...).DataRecordPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &ie.DataRecordPacketFields{
+		Format:                gtpprime.DataRecordFormatBER,
+		ApplicationIdentifier: 1,
+		ReleaseIdentifier:     8,
+		VersionIdentifier:     0x10,
+		Records:               records,
+	}
+	if diff := cmp.Diff(f, want); diff != "" {
+		t.Error(diff)
+	}
+
+	// the number of records exceeds the ones in the payload.
+	if _, err := ie.ParseDataRecordPacketFields([]byte{0x03, 0x01, 0x18, 0x10, 0x00, 0x01, 0xff}); err == nil {
+		t.Error("expected error with truncated records")
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+import "encoding/binary"
+
+// NewSequenceNumbersOfReleasedPackets creates a new SequenceNumbersOfReleasedPackets IE.
+func NewSequenceNumbersOfReleasedPackets(seqs ...uint16) *IE {
+	return newSequenceNumbersIE(SequenceNumbersOfReleasedPackets, seqs...)
+}
+
+// NewSequenceNumbersOfCancelledPackets creates a new SequenceNumbersOfCancelledPackets IE.
+func NewSequenceNumbersOfCancelledPackets(seqs ...uint16) *IE {
+	return newSequenceNumbersIE(SequenceNumbersOfCancelledPackets, seqs...)
+}
+
+// NewRequestsResponded creates a new RequestsResponded IE.
+func NewRequestsResponded(seqs ...uint16) *IE {
+	return newSequenceNumbersIE(RequestsResponded, seqs...)
+}
+
+// SequenceNumbers returns the list of sequence numbers if type matches.
+//
+// This can be used for SequenceNumbersOfReleasedPackets, SequenceNumbersOfCancelledPackets
+// and RequestsResponded.
+func (i *IE) SequenceNumbers() ([]uint16, error) {
+	switch i.Type {
+	case SequenceNumbersOfReleasedPackets, SequenceNumbersOfCancelledPackets, RequestsResponded:
+	default:
+		return nil, &InvalidTypeError{Type: i.Type}
+	}
+	if len(i.Payload)%2 != 0 {
+		return nil, ErrMalformed
+	}
+
+	seqs := make([]uint16, len(i.Payload)/2)
+	for n := range seqs {
+		seqs[n] = binary.BigEndian.Uint16(i.Payload[n*2 : n*2+2])
+	}
+	return seqs, nil
+}
+
+// MustSequenceNumbers returns the list of sequence numbers if type matches.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustSequenceNumbers() []uint16 {
+	v, _ := i.SequenceNumbers()
+	return v
+}
+
+func newSequenceNumbersIE(t uint8, seqs ...uint16) *IE {
+	b := make([]byte, len(seqs)*2)
+	for n, seq := range seqs {
+		binary.BigEndian.PutUint16(b[n*2:n*2+2], seq)
+	}
+	return New(t, b)
+}
@@ -0,0 +1,203 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpprime/ie"
+)
+
+// DataRecordTransferRequest is a DataRecordTransferRequest Header and its IEs above.
+type DataRecordTransferRequest struct {
+	*Header
+	PacketTransferCommand             *ie.IE
+	DataRecordPacket                  *ie.IE
+	SequenceNumbersOfReleasedPackets  *ie.IE
+	SequenceNumbersOfCancelledPackets *ie.IE
+	PrivateExtension                  *ie.IE
+	AdditionalIEs                     []*ie.IE
+}
+
+// NewDataRecordTransferRequest creates a new DataRecordTransferRequest.
+func NewDataRecordTransferRequest(seq uint16, IEs ...*ie.IE) *DataRecordTransferRequest {
+	d := &DataRecordTransferRequest{
+		Header: NewHeader(
+			DefaultHeaderFlags, MsgTypeDataRecordTransferRequest, seq, nil,
+		),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.PacketTransferCommand:
+			d.PacketTransferCommand = i
+		case ie.DataRecordPacket:
+			d.DataRecordPacket = i
+		case ie.SequenceNumbersOfReleasedPackets:
+			d.SequenceNumbersOfReleasedPackets = i
+		case ie.SequenceNumbersOfCancelledPackets:
+			d.SequenceNumbersOfCancelledPackets = i
+		case ie.PrivateExtension:
+			d.PrivateExtension = i
+		default:
+			d.AdditionalIEs = append(d.AdditionalIEs, i)
+		}
+	}
+
+	d.SetLength()
+	return d
+}
+
+// Marshal returns the byte sequence generated from a DataRecordTransferRequest.
+func (d *DataRecordTransferRequest) Marshal() ([]byte, error) {
+	b := make([]byte, d.MarshalLen())
+	if err := d.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (d *DataRecordTransferRequest) MarshalTo(b []byte) error {
+	if len(b) < d.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+	d.Header.Payload = make([]byte, d.MarshalLen()-d.Header.HeaderLen())
+
+	offset := 0
+	if ie := d.PacketTransferCommand; ie != nil {
+		if err := ie.MarshalTo(d.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := d.DataRecordPacket; ie != nil {
+		if err := ie.MarshalTo(d.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := d.SequenceNumbersOfReleasedPackets; ie != nil {
+		if err := ie.MarshalTo(d.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := d.SequenceNumbersOfCancelledPackets; ie != nil {
+		if err := ie.MarshalTo(d.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := d.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(d.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range d.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(d.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	d.Header.SetLength()
+	return d.Header.MarshalTo(b)
+}
+
+// ParseDataRecordTransferRequest parses a given byte sequence as a DataRecordTransferRequest.
+func ParseDataRecordTransferRequest(b []byte) (*DataRecordTransferRequest, error) {
+	d := &DataRecordTransferRequest{}
+	if err := d.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// UnmarshalBinary parses a given byte sequence as a DataRecordTransferRequest.
+func (d *DataRecordTransferRequest) UnmarshalBinary(b []byte) error {
+	var err error
+	d.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(d.Header.Payload) < 2 {
+		return nil
+	}
+
+	IEs, err := ie.ParseMultiIEs(d.Header.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.PacketTransferCommand:
+			d.PacketTransferCommand = i
+		case ie.DataRecordPacket:
+			d.DataRecordPacket = i
+		case ie.SequenceNumbersOfReleasedPackets:
+			d.SequenceNumbersOfReleasedPackets = i
+		case ie.SequenceNumbersOfCancelledPackets:
+			d.SequenceNumbersOfCancelledPackets = i
+		case ie.PrivateExtension:
+			d.PrivateExtension = i
+		default:
+			d.AdditionalIEs = append(d.AdditionalIEs, i)
+		}
+	}
+
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (d *DataRecordTransferRequest) MarshalLen() int {
+	l := d.Header.HeaderLen()
+
+	if ie := d.PacketTransferCommand; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := d.DataRecordPacket; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := d.SequenceNumbersOfReleasedPackets; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := d.SequenceNumbersOfCancelledPackets; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := d.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range d.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (d *DataRecordTransferRequest) SetLength() {
+	d.Header.Length = uint16(d.MarshalLen() - d.Header.HeaderLen())
+}
+
+// MessageTypeName returns the name of protocol.
+func (d *DataRecordTransferRequest) MessageTypeName() string {
+	return "Data Record Transfer Request"
+}
@@ -0,0 +1,76 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	"github.com/wmnsk/go-gtp/gtpprime"
+	"github.com/wmnsk/go-gtp/gtpprime/ie"
+	"github.com/wmnsk/go-gtp/gtpprime/message"
+	"github.com/wmnsk/go-gtp/gtpprime/testutils"
+)
+
+func TestDataRecordTransferRequest(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "send",
+			Structured: message.NewDataRecordTransferRequest(
+				testutils.TestSeq,
+				ie.NewPacketTransferCommand(gtpprime.PacketTransferCommandSendDataRecordPacket),
+				ie.NewDataRecordPacket(gtpprime.DataRecordFormatBER, 1, 8, 0x10, []byte{0xde, 0xad}),
+			),
+			Serialized: []byte{
+				// Header
+				0x4f, 0xf0, 0x00, 0x0d, 0x00, 0x01,
+				// PacketTransferCommand
+				0x7e, 0x01,
+				// DataRecordPacket
+				0xfc, 0x00, 0x08, 0x01, 0x01, 0x18, 0x10, 0x00, 0x02, 0xde, 0xad,
+			},
+		},
+		{
+			Description: "release",
+			Structured: message.NewDataRecordTransferRequest(
+				testutils.TestSeq,
+				ie.NewPacketTransferCommand(gtpprime.PacketTransferCommandReleaseDataRecordPacket),
+				ie.NewSequenceNumbersOfReleasedPackets(0x0001, 0x0002),
+			),
+			Serialized: []byte{
+				// Header
+				0x4f, 0xf0, 0x00, 0x09, 0x00, 0x01,
+				// PacketTransferCommand
+				0x7e, 0x04,
+				// SequenceNumbersOfReleasedPackets
+				0xf9, 0x00, 0x04, 0x00, 0x01, 0x00, 0x02,
+			},
+		},
+		{
+			Description: "cancel",
+			Structured: message.NewDataRecordTransferRequest(
+				testutils.TestSeq,
+				ie.NewPacketTransferCommand(gtpprime.PacketTransferCommandCancelDataRecordPacket),
+				ie.NewSequenceNumbersOfCancelledPackets(0x0001),
+			),
+			Serialized: []byte{
+				// Header
+				0x4f, 0xf0, 0x00, 0x07, 0x00, 0x01,
+				// PacketTransferCommand
+				0x7e, 0x03,
+				// SequenceNumbersOfCancelledPackets
+				0xfa, 0x00, 0x02, 0x00, 0x01,
+			},
+		},
+	}
+
+	testutils.Run(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseDataRecordTransferRequest(b)
+		if err != nil {
+			return nil, err
+		}
+		v.Payload = nil
+		return v, nil
+	})
+}
@@ -0,0 +1,40 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	"github.com/wmnsk/go-gtp/gtpprime/ie"
+	"github.com/wmnsk/go-gtp/gtpprime/message"
+	"github.com/wmnsk/go-gtp/gtpprime/testutils"
+)
+
+func TestGeneric(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "normal",
+			Structured: message.NewGeneric(
+				message.MsgTypeEchoResponse, testutils.TestSeq,
+				ie.NewRecovery(0x80),
+			),
+			Serialized: []byte{
+				// Header
+				0x4f, 0x02, 0x00, 0x02, 0x00, 0x01,
+				// Recovery
+				0x0e, 0x80,
+			},
+		},
+	}
+
+	testutils.Run(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseGeneric(b)
+		if err != nil {
+			return nil, err
+		}
+		v.Payload = nil
+		return v, nil
+	})
+}
@@ -0,0 +1,157 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// DefaultHeaderFlags is the flags set in the header by the constructors of the
+// messages, which indicates GTP' version 2 with the 6-octet header.
+const DefaultHeaderFlags uint8 = 0x4f
+
+// Header is a GTP' header.
+//
+// The header is 6 octets if the Header Length bit in Flags is 1, and 20 octets
+// otherwise. In the 20-octet header, which is used by the GTP' version 0, the
+// octets after the Sequence Number are spare and filled with 0xff.
+type Header struct {
+	Flags          uint8
+	Type           uint8
+	Length         uint16
+	SequenceNumber uint16
+	Payload        []byte
+}
+
+// NewHeader creates a new Header.
+func NewHeader(flags, mtype uint8, seq uint16, payload []byte) *Header {
+	h := &Header{
+		Flags:          flags,
+		Type:           mtype,
+		SequenceNumber: seq,
+		Payload:        payload,
+	}
+	h.SetLength()
+
+	return h
+}
+
+// HeaderFlags returns a Header Flag built by its components given as arguments.
+//
+// h is the Header Length bit, which is 1 for the 6-octet header and 0 for the
+// 20-octet header. The Protocol Type is always 0 in GTP'.
+func HeaderFlags(v, h int) uint8 {
+	return uint8(
+		((v & 0x7) << 5) | 0x0e | (h & 0x1),
+	)
+}
+
+// Marshal returns the byte sequence generated from a Header instance.
+func (h *Header) Marshal() ([]byte, error) {
+	b := make([]byte, h.MarshalLen())
+	if err := h.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (h *Header) MarshalTo(b []byte) error {
+	if len(b) < h.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+
+	b[0] = h.Flags
+	b[1] = h.Type
+	binary.BigEndian.PutUint16(b[2:4], h.Length)
+	binary.BigEndian.PutUint16(b[4:6], h.SequenceNumber)
+	for n := 6; n < h.HeaderLen(); n++ {
+		b[n] = 0xff
+	}
+	copy(b[h.HeaderLen():h.MarshalLen()], h.Payload)
+	return nil
+}
+
+// ParseHeader Parses given byte sequence as a GTP' header.
+func ParseHeader(b []byte) (*Header, error) {
+	h := &Header{}
+	if err := h.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in GTP' header.
+func (h *Header) UnmarshalBinary(b []byte) error {
+	l := len(b)
+	if l < 6 {
+		return ErrTooShortToParse
+	}
+	h.Flags = b[0]
+	h.Type = b[1]
+	h.Length = binary.BigEndian.Uint16(b[2:4])
+	h.SequenceNumber = binary.BigEndian.Uint16(b[4:6])
+
+	hlen := h.HeaderLen()
+	if l < hlen {
+		return ErrTooShortToParse
+	}
+	if int(h.Length)+hlen > l {
+		return ErrInvalidLength
+	}
+	h.Payload = b[hlen : hlen+int(h.Length)]
+	return nil
+}
+
+// HeaderLen returns the length of Header without Payload, which is 6 or 20
+// depending on the Header Length bit in Flags.
+func (h *Header) HeaderLen() int {
+	if h.Flags&0x01 == 1 {
+		return 6
+	}
+	return 20
+}
+
+// MarshalLen returns the serial length of Header.
+func (h *Header) MarshalLen() int {
+	return h.HeaderLen() + len(h.Payload)
+}
+
+// SetLength sets the length in Length field.
+func (h *Header) SetLength() {
+	h.Length = uint16(len(h.Payload))
+}
+
+// String returns the GTP' header values in human readable format.
+func (h *Header) String() string {
+	return fmt.Sprintf("{Flags: %#x, Type: %#x, Length: %d, SequenceNumber: %#04x, Payload: %#v}",
+		h.Flags,
+		h.Type,
+		h.Length,
+		h.SequenceNumber,
+		h.Payload,
+	)
+}
+
+// Version returns the GTP' version.
+func (h *Header) Version() int {
+	return int(h.Flags >> 5)
+}
+
+// MessageType returns the type of message.
+func (h *Header) MessageType() uint8 {
+	return h.Type
+}
+
+// Sequence returns SequenceNumber in uint16.
+func (h *Header) Sequence() uint16 {
+	return h.SequenceNumber
+}
+
+// SetSequenceNumber sets the SequenceNumber in Header.
+func (h *Header) SetSequenceNumber(seq uint16) {
+	h.SequenceNumber = seq
+}
@@ -0,0 +1,179 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpprime/ie"
+)
+
+// NodeAliveRequest is a NodeAliveRequest Header and its IEs above.
+type NodeAliveRequest struct {
+	*Header
+	NodeAddress            *ie.IE
+	AlternativeNodeAddress *ie.IE
+	PrivateExtension       *ie.IE
+	AdditionalIEs          []*ie.IE
+}
+
+// NewNodeAliveRequest creates a new NodeAliveRequest.
+func NewNodeAliveRequest(seq uint16, IEs ...*ie.IE) *NodeAliveRequest {
+	n := &NodeAliveRequest{
+		Header: NewHeader(
+			DefaultHeaderFlags, MsgTypeNodeAliveRequest, seq, nil,
+		),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.ChargingGatewayAddress:
+			if n.NodeAddress == nil {
+				n.NodeAddress = i
+			} else {
+				n.AlternativeNodeAddress = i
+			}
+		case ie.PrivateExtension:
+			n.PrivateExtension = i
+		default:
+			n.AdditionalIEs = append(n.AdditionalIEs, i)
+		}
+	}
+
+	n.SetLength()
+	return n
+}
+
+// Marshal returns the byte sequence generated from a NodeAliveRequest.
+func (n *NodeAliveRequest) Marshal() ([]byte, error) {
+	b := make([]byte, n.MarshalLen())
+	if err := n.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (n *NodeAliveRequest) MarshalTo(b []byte) error {
+	if len(b) < n.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+	n.Header.Payload = make([]byte, n.MarshalLen()-n.Header.HeaderLen())
+
+	offset := 0
+	if ie := n.NodeAddress; ie != nil {
+		if err := ie.MarshalTo(n.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := n.AlternativeNodeAddress; ie != nil {
+		if err := ie.MarshalTo(n.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := n.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(n.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range n.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(n.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	n.Header.SetLength()
+	return n.Header.MarshalTo(b)
+}
+
+// ParseNodeAliveRequest parses a given byte sequence as a NodeAliveRequest.
+func ParseNodeAliveRequest(b []byte) (*NodeAliveRequest, error) {
+	n := &NodeAliveRequest{}
+	if err := n.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// UnmarshalBinary parses a given byte sequence as a NodeAliveRequest.
+func (n *NodeAliveRequest) UnmarshalBinary(b []byte) error {
+	var err error
+	n.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(n.Header.Payload) < 2 {
+		return nil
+	}
+
+	IEs, err := ie.ParseMultiIEs(n.Header.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.ChargingGatewayAddress:
+			if n.NodeAddress == nil {
+				n.NodeAddress = i
+			} else {
+				n.AlternativeNodeAddress = i
+			}
+		case ie.PrivateExtension:
+			n.PrivateExtension = i
+		default:
+			n.AdditionalIEs = append(n.AdditionalIEs, i)
+		}
+	}
+
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (n *NodeAliveRequest) MarshalLen() int {
+	l := n.Header.HeaderLen()
+
+	if ie := n.NodeAddress; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := n.AlternativeNodeAddress; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := n.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range n.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (n *NodeAliveRequest) SetLength() {
+	n.Header.Length = uint16(n.MarshalLen() - n.Header.HeaderLen())
+}
+
+// MessageTypeName returns the name of protocol.
+func (n *NodeAliveRequest) MessageTypeName() string {
+	return "Node Alive Request"
+}
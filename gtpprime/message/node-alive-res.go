@@ -0,0 +1,147 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpprime/ie"
+)
+
+// NodeAliveResponse is a NodeAliveResponse Header and its IEs above.
+type NodeAliveResponse struct {
+	*Header
+	PrivateExtension *ie.IE
+	AdditionalIEs    []*ie.IE
+}
+
+// NewNodeAliveResponse creates a new NodeAliveResponse.
+func NewNodeAliveResponse(seq uint16, IEs ...*ie.IE) *NodeAliveResponse {
+	n := &NodeAliveResponse{
+		Header: NewHeader(
+			DefaultHeaderFlags, MsgTypeNodeAliveResponse, seq, nil,
+		),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.PrivateExtension:
+			n.PrivateExtension = i
+		default:
+			n.AdditionalIEs = append(n.AdditionalIEs, i)
+		}
+	}
+
+	n.SetLength()
+	return n
+}
+
+// Marshal returns the byte sequence generated from a NodeAliveResponse.
+func (n *NodeAliveResponse) Marshal() ([]byte, error) {
+	b := make([]byte, n.MarshalLen())
+	if err := n.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (n *NodeAliveResponse) MarshalTo(b []byte) error {
+	if len(b) < n.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+	n.Header.Payload = make([]byte, n.MarshalLen()-n.Header.HeaderLen())
+
+	offset := 0
+	if ie := n.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(n.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range n.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(n.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	n.Header.SetLength()
+	return n.Header.MarshalTo(b)
+}
+
+// ParseNodeAliveResponse parses a given byte sequence as a NodeAliveResponse.
+func ParseNodeAliveResponse(b []byte) (*NodeAliveResponse, error) {
+	n := &NodeAliveResponse{}
+	if err := n.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// UnmarshalBinary parses a given byte sequence as a NodeAliveResponse.
+func (n *NodeAliveResponse) UnmarshalBinary(b []byte) error {
+	var err error
+	n.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(n.Header.Payload) < 2 {
+		return nil
+	}
+
+	IEs, err := ie.ParseMultiIEs(n.Header.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.PrivateExtension:
+			n.PrivateExtension = i
+		default:
+			n.AdditionalIEs = append(n.AdditionalIEs, i)
+		}
+	}
+
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (n *NodeAliveResponse) MarshalLen() int {
+	l := n.Header.HeaderLen()
+
+	if ie := n.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range n.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (n *NodeAliveResponse) SetLength() {
+	n.Header.Length = uint16(n.MarshalLen() - n.Header.HeaderLen())
+}
+
+// MessageTypeName returns the name of protocol.
+func (n *NodeAliveResponse) MessageTypeName() string {
+	return "Node Alive Response"
+}
@@ -0,0 +1,48 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	"github.com/wmnsk/go-gtp/gtpprime/ie"
+	"github.com/wmnsk/go-gtp/gtpprime/message"
+	"github.com/wmnsk/go-gtp/gtpprime/testutils"
+)
+
+func TestNodeAliveResponse(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "no-ie",
+			Structured:  message.NewNodeAliveResponse(testutils.TestSeq),
+			Serialized: []byte{
+				// Header
+				0x4f, 0x05, 0x00, 0x00, 0x00, 0x01,
+			},
+		},
+		{
+			Description: "with-private-extension",
+			Structured: message.NewNodeAliveResponse(
+				testutils.TestSeq,
+				ie.NewPrivateExtension(0x0080, []byte{0xde, 0xad, 0xbe, 0xef}),
+			),
+			Serialized: []byte{
+				// Header
+				0x4f, 0x05, 0x00, 0x09, 0x00, 0x01,
+				// PrivateExtension
+				0xff, 0x00, 0x06, 0x00, 0x80, 0xde, 0xad, 0xbe, 0xef,
+			},
+		},
+	}
+
+	testutils.Run(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseNodeAliveResponse(b)
+		if err != nil {
+			return nil, err
+		}
+		v.Payload = nil
+		return v, nil
+	})
+}
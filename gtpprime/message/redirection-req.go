@@ -0,0 +1,193 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpprime/ie"
+)
+
+// RedirectionRequest is a RedirectionRequest Header and its IEs above.
+type RedirectionRequest struct {
+	*Header
+	Cause                               *ie.IE
+	AddressOfRecommendedNode            *ie.IE
+	AlternativeAddressOfRecommendedNode *ie.IE
+	PrivateExtension                    *ie.IE
+	AdditionalIEs                       []*ie.IE
+}
+
+// NewRedirectionRequest creates a new RedirectionRequest.
+func NewRedirectionRequest(seq uint16, IEs ...*ie.IE) *RedirectionRequest {
+	r := &RedirectionRequest{
+		Header: NewHeader(
+			DefaultHeaderFlags, MsgTypeRedirectionRequest, seq, nil,
+		),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			r.Cause = i
+		case ie.AddressOfRecommendedNode:
+			if r.AddressOfRecommendedNode == nil {
+				r.AddressOfRecommendedNode = i
+			} else {
+				r.AlternativeAddressOfRecommendedNode = i
+			}
+		case ie.PrivateExtension:
+			r.PrivateExtension = i
+		default:
+			r.AdditionalIEs = append(r.AdditionalIEs, i)
+		}
+	}
+
+	r.SetLength()
+	return r
+}
+
+// Marshal returns the byte sequence generated from a RedirectionRequest.
+func (r *RedirectionRequest) Marshal() ([]byte, error) {
+	b := make([]byte, r.MarshalLen())
+	if err := r.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (r *RedirectionRequest) MarshalTo(b []byte) error {
+	if len(b) < r.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+	r.Header.Payload = make([]byte, r.MarshalLen()-r.Header.HeaderLen())
+
+	offset := 0
+	if ie := r.Cause; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := r.AddressOfRecommendedNode; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := r.AlternativeAddressOfRecommendedNode; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := r.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range r.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	r.Header.SetLength()
+	return r.Header.MarshalTo(b)
+}
+
+// ParseRedirectionRequest parses a given byte sequence as a RedirectionRequest.
+func ParseRedirectionRequest(b []byte) (*RedirectionRequest, error) {
+	r := &RedirectionRequest{}
+	if err := r.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// UnmarshalBinary parses a given byte sequence as a RedirectionRequest.
+func (r *RedirectionRequest) UnmarshalBinary(b []byte) error {
+	var err error
+	r.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(r.Header.Payload) < 2 {
+		return nil
+	}
+
+	IEs, err := ie.ParseMultiIEs(r.Header.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			r.Cause = i
+		case ie.AddressOfRecommendedNode:
+			if r.AddressOfRecommendedNode == nil {
+				r.AddressOfRecommendedNode = i
+			} else {
+				r.AlternativeAddressOfRecommendedNode = i
+			}
+		case ie.PrivateExtension:
+			r.PrivateExtension = i
+		default:
+			r.AdditionalIEs = append(r.AdditionalIEs, i)
+		}
+	}
+
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (r *RedirectionRequest) MarshalLen() int {
+	l := r.Header.HeaderLen()
+
+	if ie := r.Cause; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := r.AddressOfRecommendedNode; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := r.AlternativeAddressOfRecommendedNode; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := r.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range r.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (r *RedirectionRequest) SetLength() {
+	r.Header.Length = uint16(r.MarshalLen() - r.Header.HeaderLen())
+}
+
+// MessageTypeName returns the name of protocol.
+func (r *RedirectionRequest) MessageTypeName() string {
+	return "Redirection Request"
+}
@@ -0,0 +1,47 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	"github.com/wmnsk/go-gtp/gtpprime"
+	"github.com/wmnsk/go-gtp/gtpprime/ie"
+	"github.com/wmnsk/go-gtp/gtpprime/message"
+	"github.com/wmnsk/go-gtp/gtpprime/testutils"
+)
+
+func TestRedirectionRequest(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "normal",
+			Structured: message.NewRedirectionRequest(
+				testutils.TestSeq,
+				ie.NewCause(gtpprime.CauseThisNodeIsAboutToGoDown),
+				ie.NewAddressOfRecommendedNode("1.1.1.1"),
+				ie.NewAddressOfRecommendedNode("2.2.2.2"),
+			),
+			Serialized: []byte{
+				// Header
+				0x4f, 0x06, 0x00, 0x10, 0x00, 0x01,
+				// Cause
+				0x01, 0x3f,
+				// AddressOfRecommendedNode
+				0xfe, 0x00, 0x04, 0x01, 0x01, 0x01, 0x01,
+				// AlternativeAddressOfRecommendedNode
+				0xfe, 0x00, 0x04, 0x02, 0x02, 0x02, 0x02,
+			},
+		},
+	}
+
+	testutils.Run(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseRedirectionRequest(b)
+		if err != nil {
+			return nil, err
+		}
+		v.Payload = nil
+		return v, nil
+	})
+}
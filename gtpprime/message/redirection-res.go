@@ -0,0 +1,161 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpprime/ie"
+)
+
+// RedirectionResponse is a RedirectionResponse Header and its IEs above.
+type RedirectionResponse struct {
+	*Header
+	Cause            *ie.IE
+	PrivateExtension *ie.IE
+	AdditionalIEs    []*ie.IE
+}
+
+// NewRedirectionResponse creates a new RedirectionResponse.
+func NewRedirectionResponse(seq uint16, IEs ...*ie.IE) *RedirectionResponse {
+	r := &RedirectionResponse{
+		Header: NewHeader(
+			DefaultHeaderFlags, MsgTypeRedirectionResponse, seq, nil,
+		),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			r.Cause = i
+		case ie.PrivateExtension:
+			r.PrivateExtension = i
+		default:
+			r.AdditionalIEs = append(r.AdditionalIEs, i)
+		}
+	}
+
+	r.SetLength()
+	return r
+}
+
+// Marshal returns the byte sequence generated from a RedirectionResponse.
+func (r *RedirectionResponse) Marshal() ([]byte, error) {
+	b := make([]byte, r.MarshalLen())
+	if err := r.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (r *RedirectionResponse) MarshalTo(b []byte) error {
+	if len(b) < r.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+	r.Header.Payload = make([]byte, r.MarshalLen()-r.Header.HeaderLen())
+
+	offset := 0
+	if ie := r.Cause; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := r.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range r.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	r.Header.SetLength()
+	return r.Header.MarshalTo(b)
+}
+
+// ParseRedirectionResponse parses a given byte sequence as a RedirectionResponse.
+func ParseRedirectionResponse(b []byte) (*RedirectionResponse, error) {
+	r := &RedirectionResponse{}
+	if err := r.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// UnmarshalBinary parses a given byte sequence as a RedirectionResponse.
+func (r *RedirectionResponse) UnmarshalBinary(b []byte) error {
+	var err error
+	r.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(r.Header.Payload) < 2 {
+		return nil
+	}
+
+	IEs, err := ie.ParseMultiIEs(r.Header.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			r.Cause = i
+		case ie.PrivateExtension:
+			r.PrivateExtension = i
+		default:
+			r.AdditionalIEs = append(r.AdditionalIEs, i)
+		}
+	}
+
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (r *RedirectionResponse) MarshalLen() int {
+	l := r.Header.HeaderLen()
+
+	if ie := r.Cause; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := r.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range r.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (r *RedirectionResponse) SetLength() {
+	r.Header.Length = uint16(r.MarshalLen() - r.Header.HeaderLen())
+}
+
+// MessageTypeName returns the name of protocol.
+func (r *RedirectionResponse) MessageTypeName() string {
+	return "Redirection Response"
+}
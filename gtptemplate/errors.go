@@ -0,0 +1,45 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtptemplate
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error definitions.
+var (
+	ErrMissingField = errors.New("missing field")
+	ErrUnknownField = errors.New("unknown field")
+	ErrInvalidValue = errors.New("invalid value")
+	ErrNoBuilder    = errors.New("IE cannot be built from fields; use payload instead")
+)
+
+// FieldError indicates that a field in a template is invalid.
+type FieldError struct {
+	// Path is the location of the field, e.g., "ies[2].ies[0].teid".
+	Path string
+	Err  error
+}
+
+// Error returns the path to the field and the reason.
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// UndefinedVariableError indicates that a variable in a template is not given.
+type UndefinedVariableError struct {
+	Name string
+}
+
+// Error returns the name of the variable not given.
+func (e *UndefinedVariableError) Error() string {
+	return fmt.Sprintf("undefined variable: ${%s}", e.Name)
+}
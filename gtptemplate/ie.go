@@ -0,0 +1,124 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtptemplate
+
+import (
+	"time"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+// ieBuilders is the constructors of IEs that take the values from the fields
+// in a template. The IEs not listed here can be built with "payload" in hex.
+var ieBuilders = map[uint8]func(f *fields) *ie.IE{
+	ie.IMSI: func(f *fields) *ie.IE {
+		return ie.NewIMSI(f.string("value"))
+	},
+	ie.MSISDN: func(f *fields) *ie.IE {
+		return ie.NewMSISDN(f.string("value"))
+	},
+	ie.MobileEquipmentIdentity: func(f *fields) *ie.IE {
+		return ie.NewMobileEquipmentIdentity(f.string("value"))
+	},
+	ie.AccessPointName: func(f *fields) *ie.IE {
+		return ie.NewAccessPointName(f.string("value"))
+	},
+	ie.FullyQualifiedDomainName: func(f *fields) *ie.IE {
+		return ie.NewFullyQualifiedDomainName(f.string("value"))
+	},
+	ie.Cause: func(f *fields) *ie.IE {
+		return ie.NewCause(
+			uint8(f.uint("value", 8, nil)),
+			uint8(f.optUint("pce", 1, 0)),
+			uint8(f.optUint("bce", 1, 0)),
+			uint8(f.optUint("cs", 1, 0)),
+			nil,
+		)
+	},
+	ie.Recovery: func(f *fields) *ie.IE {
+		return ie.NewRecovery(uint8(f.uint("value", 8, nil)))
+	},
+	ie.EPSBearerID: func(f *fields) *ie.IE {
+		return ie.NewEPSBearerID(uint8(f.uint("value", 8, nil)))
+	},
+	ie.RATType: func(f *fields) *ie.IE {
+		return ie.NewRATType(uint8(f.uint("value", 8, nil)))
+	},
+	ie.PDNType: func(f *fields) *ie.IE {
+		return ie.NewPDNType(uint8(f.uint("value", 8, nil)))
+	},
+	ie.SelectionMode: func(f *fields) *ie.IE {
+		return ie.NewSelectionMode(uint8(f.uint("value", 8, nil)))
+	},
+	ie.APNRestriction: func(f *fields) *ie.IE {
+		return ie.NewAPNRestriction(uint8(f.uint("value", 8, nil)))
+	},
+	ie.ChargingID: func(f *fields) *ie.IE {
+		return ie.NewChargingID(uint32(f.uint("value", 32, nil)))
+	},
+	ie.DelayValue: func(f *fields) *ie.IE {
+		s := f.string("value")
+		if f.err != nil {
+			return nil
+		}
+
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			f.fail("value", ErrInvalidValue)
+			return nil
+		}
+		return ie.NewDelayValue(d)
+	},
+	ie.FullyQualifiedTEID: func(f *fields) *ie.IE {
+		return ie.NewFullyQualifiedTEID(
+			uint8(f.uint("interface", 6, nil)),
+			uint32(f.uint("teid", 32, nil)),
+			f.optString("ipv4", ""),
+			f.optString("ipv6", ""),
+		)
+	},
+	ie.AggregateMaximumBitRate: func(f *fields) *ie.IE {
+		return ie.NewAggregateMaximumBitRate(
+			uint32(f.uint("uplink", 32, nil)),
+			uint32(f.uint("downlink", 32, nil)),
+		)
+	},
+	ie.AllocationRetensionPriority: func(f *fields) *ie.IE {
+		return ie.NewAllocationRetensionPriority(
+			uint8(f.optUint("pci", 1, 0)),
+			uint8(f.uint("pl", 4, nil)),
+			uint8(f.optUint("pvi", 1, 0)),
+		)
+	},
+	ie.BearerQoS: func(f *fields) *ie.IE {
+		return ie.NewBearerQoS(
+			uint8(f.optUint("pci", 1, 0)),
+			uint8(f.uint("pl", 4, nil)),
+			uint8(f.optUint("pvi", 1, 0)),
+			uint8(f.uint("qci", 8, nil)),
+			f.optUint("mbr-ul", 40, 0),
+			f.optUint("mbr-dl", 40, 0),
+			f.optUint("gbr-ul", 40, 0),
+			f.optUint("gbr-dl", 40, 0),
+		)
+	},
+	ie.PDNAddressAllocation: func(f *fields) *ie.IE {
+		v4, v6 := f.optString("ipv4", ""), f.optString("ipv6", "")
+		if v4 != "" && v6 != "" {
+			return ie.NewPDNAddressAllocationDual(v4, v6)
+		}
+		return ie.NewPDNAddressAllocation(v4 + v6)
+	},
+	ie.ServingNetwork: func(f *fields) *ie.IE {
+		return ie.NewServingNetwork(f.string("mcc"), f.string("mnc"))
+	},
+	ie.UserLocationInformation: func(f *fields) *ie.IE {
+		return ie.NewUserLocationInformationLazy(
+			f.string("mcc"), f.string("mnc"),
+			f.optInt("lac", -1), f.optInt("ci", -1), f.optInt("sac", -1), f.optInt("rac", -1),
+			f.optInt("tac", -1), f.optInt("eci", -1), f.optInt("menbi", -1), f.optInt("emenbi", -1),
+		)
+	},
+}
@@ -0,0 +1,534 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Package gtptemplate builds GTPv2-C messages from the declarative templates
+// written in YAML or JSON, so that the signalling scenarios for testing can be
+// defined without writing Go code for every variation.
+//
+// A template describes the type of message, the header fields and the IEs.
+// The types of message and IE can be given by their names (case, spaces and
+// symbols are ignored; "CreateSessionRequest" and "Create Session Request" are
+// the same) or by their numbers. The IEs take their values in the fields named
+// after the arguments of their constructors, or the raw payload in hex with
+// "payload", and the grouped IEs take their children in "ies".
+//
+//	name: attach
+//	type: CreateSessionRequest
+//	teid: 0
+//	sequence: ${seq}
+//	ies:
+//	  - type: IMSI
+//	    value: ${imsi}
+//	  - type: F-TEID
+//	    interface: 10
+//	    teid: ${teid}
+//	    ipv4: 10.0.0.1
+//	  - type: BearerContext
+//	    ies:
+//	      - type: EBI
+//	        value: 5
+//	  - type: 255
+//	    payload: 00 0a de ad be ef
+//
+// Any scalar in a template can contain ${name}, which is substituted with the
+// variable given to Build. A file can also contain a list of templates.
+package gtptemplate
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+	"gopkg.in/yaml.v2"
+)
+
+// Template is a template of a GTPv2-C message.
+type Template struct {
+	// Name is the optional name of the template, which is useful to
+	// refer to a step when a file contains multiple templates.
+	Name string
+
+	node map[string]interface{}
+}
+
+// Parse parses the templates written in YAML or JSON.
+//
+// The document can be either a template or a list of templates.
+func Parse(b []byte) ([]*Template, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	switch v := normalize(doc).(type) {
+	case map[string]interface{}:
+		t, err := newTemplate("", v)
+		if err != nil {
+			return nil, err
+		}
+		return []*Template{t}, nil
+	case []interface{}:
+		var ts []*Template
+		for n, e := range v {
+			path := fmt.Sprintf("[%d]", n)
+			m, ok := e.(map[string]interface{})
+			if !ok {
+				return nil, &FieldError{Path: path, Err: ErrInvalidValue}
+			}
+			t, err := newTemplate(path, m)
+			if err != nil {
+				return nil, err
+			}
+			ts = append(ts, t)
+		}
+		return ts, nil
+	default:
+		return nil, &FieldError{Path: "", Err: ErrInvalidValue}
+	}
+}
+
+// ParseFile reads the file at path and parses the templates in it.
+func ParseFile(path string) ([]*Template, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(b)
+}
+
+func newTemplate(path string, node map[string]interface{}) (*Template, error) {
+	if _, ok := node["type"]; !ok {
+		return nil, &FieldError{Path: join(path, "type"), Err: ErrMissingField}
+	}
+
+	t := &Template{node: node}
+	if v, ok := node["name"]; ok {
+		name, ok := v.(string)
+		if !ok {
+			return nil, &FieldError{Path: join(path, "name"), Err: ErrInvalidValue}
+		}
+		t.Name = name
+	}
+	return t, nil
+}
+
+// Build creates a message from the template, substituting ${name} in the
+// template with vars.
+func (t *Template) Build(vars map[string]string) (message.Message, error) {
+	b := &builder{vars: vars}
+	f := b.fields("", t.node)
+	f.used["name"] = true
+
+	typ := uint8(f.uint("type", 8, msgTypeByName))
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	var teid uint32
+	withTEID := !noTEIDTypes[typ]
+	if withTEID {
+		teid = uint32(f.optUint("teid", 32, 0))
+	}
+	seq := uint32(f.optUint("sequence", 24, 0))
+	ies := b.ies(f, "ies")
+	if err := f.finish(); err != nil {
+		return nil, err
+	}
+
+	var g *message.Generic
+	if withTEID {
+		g = message.NewGeneric(typ, teid, seq, ies...)
+	} else {
+		g = message.NewGenericWithoutTEID(typ, teid, seq, ies...)
+	}
+
+	raw, err := message.Marshal(g)
+	if err != nil {
+		return nil, err
+	}
+	return message.Parse(raw)
+}
+
+// noTEIDTypes is the message types that do not have TEID in the header.
+var noTEIDTypes = map[uint8]bool{
+	message.MsgTypeEchoRequest:                   true,
+	message.MsgTypeEchoResponse:                  true,
+	message.MsgTypeVersionNotSupportedIndication: true,
+}
+
+type builder struct {
+	vars map[string]string
+}
+
+func (b *builder) fields(path string, n map[string]interface{}) *fields {
+	return &fields{b: b, path: path, n: n, used: map[string]bool{}}
+}
+
+// ies builds the list of IEs in the field key of f.
+func (b *builder) ies(f *fields, key string) []*ie.IE {
+	v, ok := f.n[key]
+	f.used[key] = true
+	if !ok || f.err != nil {
+		return nil
+	}
+
+	list, ok := v.([]interface{})
+	if !ok {
+		f.fail(key, ErrInvalidValue)
+		return nil
+	}
+
+	var ies []*ie.IE
+	for n, e := range list {
+		path := fmt.Sprintf("%s[%d]", join(f.path, key), n)
+		m, ok := e.(map[string]interface{})
+		if !ok {
+			f.err = &FieldError{Path: path, Err: ErrInvalidValue}
+			return nil
+		}
+
+		i, err := b.ie(path, m)
+		if err != nil {
+			f.err = err
+			return nil
+		}
+		ies = append(ies, i)
+	}
+	return ies
+}
+
+func (b *builder) ie(path string, n map[string]interface{}) (*ie.IE, error) {
+	f := b.fields(path, n)
+	typ := uint8(f.uint("type", 8, ieTypeByName))
+	ins := uint8(f.optUint("instance", 4, 0))
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	var i *ie.IE
+	switch {
+	case has(n, "ies"):
+		children := b.ies(f, "ies")
+		if f.err != nil {
+			return nil, f.err
+		}
+
+		i = ie.New(typ, ins, nil)
+		if i.IsGrouped() {
+			i.Add(children...)
+			break
+		}
+		for _, c := range children {
+			cb, err := c.Marshal()
+			if err != nil {
+				return nil, &FieldError{Path: path, Err: err}
+			}
+			i.Payload = append(i.Payload, cb...)
+		}
+		i.SetLength()
+	case has(n, "payload"):
+		i = ie.New(typ, ins, f.hex("payload"))
+	default:
+		build, ok := ieBuilders[typ]
+		if !ok {
+			return nil, &FieldError{Path: join(path, "type"), Err: ErrNoBuilder}
+		}
+		i = build(f)
+		if f.err == nil && i != nil {
+			i.SetInstance(ins)
+		}
+	}
+
+	if err := f.finish(); err != nil {
+		return nil, err
+	}
+	if i == nil {
+		return nil, &FieldError{Path: path, Err: ErrInvalidValue}
+	}
+	return i, nil
+}
+
+var varPattern = regexp.MustCompile(`\$\{([A-Za-z0-9_.-]+)\}`)
+
+// substitute replaces ${name} in s with the variables.
+func (b *builder) substitute(s string) (string, error) {
+	var missing string
+	s = varPattern.ReplaceAllStringFunc(s, func(m string) string {
+		name := m[2 : len(m)-1]
+		v, ok := b.vars[name]
+		if !ok && missing == "" {
+			missing = name
+		}
+		return v
+	})
+	if missing != "" {
+		return "", &UndefinedVariableError{Name: missing}
+	}
+	return s, nil
+}
+
+// fields is a set of fields in a mapping in a template. The first error
+// in getting the fields is kept in err and the subsequent calls do nothing,
+// so that the builders of IEs can get the fields without checking errors
+// one by one.
+type fields struct {
+	b    *builder
+	path string
+	n    map[string]interface{}
+	used map[string]bool
+	err  error
+}
+
+func (f *fields) fail(key string, err error) {
+	if f.err != nil {
+		return
+	}
+	f.err = &FieldError{Path: join(f.path, key), Err: err}
+}
+
+// scalar returns the value of key in string after substitution.
+func (f *fields) scalar(key string) (string, bool) {
+	f.used[key] = true
+	if f.err != nil {
+		return "", false
+	}
+
+	v, ok := f.n[key]
+	if !ok {
+		return "", false
+	}
+
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case int:
+		s = strconv.Itoa(v)
+	case uint64:
+		s = strconv.FormatUint(v, 10)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		s = strconv.FormatBool(v)
+	default:
+		f.fail(key, ErrInvalidValue)
+		return "", false
+	}
+
+	s, err := f.b.substitute(s)
+	if err != nil {
+		f.fail(key, err)
+		return "", false
+	}
+	return s, true
+}
+
+func (f *fields) string(key string) string {
+	s, ok := f.scalar(key)
+	if !ok {
+		f.fail(key, ErrMissingField)
+	}
+	return s
+}
+
+func (f *fields) optString(key, def string) string {
+	s, ok := f.scalar(key)
+	if !ok {
+		return def
+	}
+	return s
+}
+
+// uint returns the value of key in uint64. The value can be a name to be
+// looked up with names if names is not nil.
+func (f *fields) uint(key string, bits int, names func(string) (uint8, bool)) uint64 {
+	s, ok := f.scalar(key)
+	if !ok {
+		f.fail(key, ErrMissingField)
+		return 0
+	}
+
+	v, err := strconv.ParseUint(s, 0, bits)
+	if err == nil {
+		return v
+	}
+	if names != nil {
+		if t, ok := names(s); ok {
+			return uint64(t)
+		}
+	}
+	f.fail(key, ErrInvalidValue)
+	return 0
+}
+
+func (f *fields) optUint(key string, bits int, def uint64) uint64 {
+	if _, ok := f.n[key]; !ok {
+		f.used[key] = true
+		return def
+	}
+	return f.uint(key, bits, nil)
+}
+
+func (f *fields) optInt(key string, def int) int {
+	s, ok := f.scalar(key)
+	if !ok {
+		return def
+	}
+
+	v, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		f.fail(key, ErrInvalidValue)
+		return def
+	}
+	return int(v)
+}
+
+func (f *fields) hex(key string) []byte {
+	s, ok := f.scalar(key)
+	if !ok {
+		f.fail(key, ErrMissingField)
+		return nil
+	}
+
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == ':' {
+			return -1
+		}
+		return r
+	}, strings.TrimPrefix(s, "0x"))
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		f.fail(key, ErrInvalidValue)
+		return nil
+	}
+	return b
+}
+
+// finish returns the error occurred while getting the fields, or the error
+// for the fields that are not used, which are likely to be typos.
+func (f *fields) finish() error {
+	if f.err != nil {
+		return f.err
+	}
+
+	var unknown []string
+	for k := range f.n {
+		if !f.used[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return &FieldError{Path: join(f.path, unknown[0]), Err: ErrUnknownField}
+	}
+	return nil
+}
+
+func has(n map[string]interface{}, key string) bool {
+	_, ok := n[key]
+	return ok
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// normalize converts the maps decoded by yaml into map[string]interface{}.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = normalize(e)
+		}
+		return m
+	case []interface{}:
+		for n, e := range v {
+			v[n] = normalize(e)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// normalizeName makes the names comparable regardless of case, spaces and symbols.
+func normalizeName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
+
+var (
+	namesOnce    sync.Once
+	msgTypeNames map[string]uint8
+	ieTypeNames  map[string]uint8
+)
+
+// ieAliases is the abbreviations of the IE names that are commonly used.
+var ieAliases = map[string]uint8{
+	"ambr": ie.AggregateMaximumBitRate,
+	"apn":  ie.AccessPointName,
+	"arp":  ie.AllocationRetensionPriority,
+	"ebi":  ie.EPSBearerID,
+	"mei":  ie.MobileEquipmentIdentity,
+	"paa":  ie.PDNAddressAllocation,
+	"uli":  ie.UserLocationInformation,
+
+	"fullyqualifiedteid": ie.FullyQualifiedTEID,
+}
+
+func initNames() {
+	msgTypeNames = map[string]uint8{}
+	for t := 1; t <= 0xff; t++ {
+		g := message.NewGeneric(uint8(t), 0, 0)
+		b, err := message.Marshal(g)
+		if err != nil {
+			continue
+		}
+		m, err := message.Parse(b)
+		if err != nil {
+			continue
+		}
+		if _, ok := m.(*message.Generic); ok {
+			continue
+		}
+		msgTypeNames[normalizeName(m.MessageTypeName())] = uint8(t)
+	}
+
+	ieTypeNames = map[string]uint8{}
+	for t := 1; t <= 0xff; t++ {
+		ieTypeNames[normalizeName(ie.TypeName(uint8(t)))] = uint8(t)
+	}
+	for k, v := range ieAliases {
+		ieTypeNames[k] = v
+	}
+}
+
+func msgTypeByName(name string) (uint8, bool) {
+	namesOnce.Do(initNames)
+	t, ok := msgTypeNames[normalizeName(name)]
+	return t, ok
+}
+
+func ieTypeByName(name string) (uint8, bool) {
+	namesOnce.Do(initNames)
+	t, ok := ieTypeNames[normalizeName(name)]
+	return t, ok
+}
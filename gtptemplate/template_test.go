@@ -0,0 +1,188 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtptemplate_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/wmnsk/go-gtp/gtptemplate"
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+var vars = map[string]string{
+	"imsi": "123451234567890",
+	"teid": "0xdeadbeef",
+	"seq":  "10",
+}
+
+func TestParseFile(t *testing.T) {
+	ts, err := gtptemplate.ParseFile("testdata/attach.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 2 {
+		t.Fatalf("got %d templates, want 2", len(ts))
+	}
+
+	cases := []struct {
+		description string
+		template    *gtptemplate.Template
+		expected    message.Message
+	}{
+		{
+			"CreateSessionRequest",
+			ts[0],
+			message.NewCreateSessionRequest(
+				0, 10,
+				ie.NewIMSI("123451234567890"),
+				ie.NewFullyQualifiedTEID(v2.IFTypeS11MMEGTPC, 0xdeadbeef, "127.0.0.1", ""),
+				ie.NewAccessPointName("some.apn.example"),
+				ie.NewRATType(v2.RATTypeEUTRAN),
+				ie.NewBearerContext(
+					ie.NewEPSBearerID(5),
+					ie.NewBearerQoS(0, 2, 0, 9, 0, 0, 0, 0),
+				),
+			),
+		}, {
+			"EchoRequest",
+			ts[1],
+			message.NewEchoRequest(10, ie.NewRecovery(1)),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			m, err := c.template.Build(vars)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := message.Marshal(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := message.Marshal(c.expected)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(got, want); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	ts, err := gtptemplate.Parse([]byte(`{
+		"type": "Delete Session Request",
+		"teid": 4660,
+		"sequence": 1,
+		"ies": [
+			{"type": "ebi", "value": 5},
+			{"type": "Private Extension", "payload": "00 0a de ad be ef"}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := ts[0].Build(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dsr, ok := m.(*message.DeleteSessionRequest)
+	if !ok {
+		t.Fatalf("got %T, want *message.DeleteSessionRequest", m)
+	}
+	if got, want := dsr.TEID(), uint32(0x1234); got != want {
+		t.Errorf("got TEID %#x, want %#x", got, want)
+	}
+	if got, want := dsr.LinkedEBI.MustEPSBearerID(), uint8(5); got != want {
+		t.Errorf("got EBI %d, want %d", got, want)
+	}
+	if got, want := dsr.PrivateExtension.Payload, []byte{0x00, 0x0a, 0xde, 0xad, 0xbe, 0xef}; !cmp.Equal(got, want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	cases := []struct {
+		description string
+		template    string
+		path        string
+		err         error
+	}{
+		{
+			"UndefinedVariable",
+			"{type: 32, ies: [{type: IMSI, value: '${msin}'}]}",
+			"ies[0].value",
+			&gtptemplate.UndefinedVariableError{Name: "msin"},
+		}, {
+			"UnknownField",
+			"{type: 32, ies: [{type: F-TEID, interface: 10, teid: 1, ipv5: 1.1.1.1}]}",
+			"ies[0].ipv5",
+			gtptemplate.ErrUnknownField,
+		}, {
+			"MissingField",
+			"{type: 32, ies: [{type: BearerContext, ies: [{type: EBI}]}]}",
+			"ies[0].ies[0].value",
+			gtptemplate.ErrMissingField,
+		}, {
+			"InvalidValue",
+			"{type: 32, ies: [{type: EBI, value: 256}]}",
+			"ies[0].value",
+			gtptemplate.ErrInvalidValue,
+		}, {
+			"UnknownMessage",
+			"{type: CreateSomethingRequest}",
+			"type",
+			gtptemplate.ErrInvalidValue,
+		}, {
+			"NoBuilder",
+			"{type: 32, ies: [{type: Indication, value: 1}]}",
+			"ies[0].type",
+			gtptemplate.ErrNoBuilder,
+		}, {
+			"TEIDInEcho",
+			"{type: EchoRequest, teid: 1}",
+			"teid",
+			gtptemplate.ErrUnknownField,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			ts, err := gtptemplate.Parse([]byte(c.template))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = ts[0].Build(vars)
+			var fe *gtptemplate.FieldError
+			if !errors.As(err, &fe) {
+				t.Fatalf("got %v, want FieldError", err)
+			}
+			if fe.Path != c.path {
+				t.Errorf("got path %q, want %q", fe.Path, c.path)
+			}
+
+			var ue *gtptemplate.UndefinedVariableError
+			if errors.As(c.err, &ue) {
+				if diff := cmp.Diff(fe.Err, c.err); diff != "" {
+					t.Error(diff)
+				}
+				return
+			}
+			if !errors.Is(err, c.err) {
+				t.Errorf("got %v, want %v", err, c.err)
+			}
+		})
+	}
+}
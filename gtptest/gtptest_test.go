@@ -0,0 +1,121 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtptest_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/wmnsk/go-gtp/gtptest"
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+func TestPipe(t *testing.T) {
+	pc1, pc2, err := gtptest.Pipe("127.0.0.1:2123", "127.0.0.2:2123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc1.Close()
+	defer pc2.Close()
+
+	payload := []byte{0xde, 0xad, 0xbe, 0xef}
+	if _, err := pc1.WriteTo(payload, pc2.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 1500)
+	n, from, err := pc2.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(payload, buf[:n]); diff != "" {
+		t.Error(diff)
+	}
+	if got, want := from.String(), "127.0.0.1:2123"; got != want {
+		t.Errorf("wrong sender. want: %s, got: %s", want, got)
+	}
+
+	if err := pc2.SetReadDeadline(time.Now().Add(10 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = pc2.ReadFrom(buf)
+	if nerr, ok := err.(net.Error); !ok || !nerr.Timeout() {
+		t.Errorf("expected timeout, got: %v", err)
+	}
+}
+
+func TestMockPeer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mmePC, sgwPC, err := gtptest.Pipe("127.0.0.1:2123", "127.0.0.2:2123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	csRes := message.NewCreateSessionResponse(
+		0x11111111, 0,
+		ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+		ie.NewFullyQualifiedTEID(v2.IFTypeS11S4SGWGTPC, 0x22222222, "127.0.0.2", "").WithInstance(0),
+	)
+	sgw := gtptest.NewMockPeer(sgwPC)
+	sgw.Expect(message.MsgTypeCreateSessionRequest, gtptest.RespondWith(csRes))
+	go func() {
+		if err := sgw.Serve(ctx); err != nil {
+			t.Log(err)
+		}
+	}()
+
+	mmeConn := v2.NewConnWithPacketConn(mmePC, v2.IFTypeS11MMEGTPC, 0)
+	resCh := make(chan *message.CreateSessionResponse)
+	mmeConn.AddHandler(
+		message.MsgTypeCreateSessionResponse,
+		func(c *v2.Conn, senderAddr net.Addr, msg message.Message) error {
+			resCh <- msg.(*message.CreateSessionResponse)
+			return nil
+		},
+	)
+	go func() {
+		if err := mmeConn.ListenAndServe(ctx); err != nil {
+			t.Log(err)
+		}
+	}()
+
+	if _, _, err := mmeConn.CreateSession(
+		sgwPC.LocalAddr(),
+		ie.NewIMSI("123451234567890"),
+		mmeConn.NewSenderFTEID("127.0.0.1", ""),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case res := <-resCh:
+		if got, want := res.TEID(), uint32(0x11111111); got != want {
+			t.Errorf("wrong TEID. want: %#x, got: %#x", want, got)
+		}
+		if got, want := res.Sequence(), uint32(1); got != want {
+			t.Errorf("wrong Sequence Number. want: %d, got: %d", want, got)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for Create Session Response")
+	}
+
+	if got := csRes.Sequence(); got != 0 {
+		t.Errorf("template given to RespondWith modified: Sequence Number %d", got)
+	}
+
+	if err := sgw.Verify(); err != nil {
+		t.Error(err)
+	}
+	if got := len(sgw.Received()); got != 1 {
+		t.Errorf("wrong number of messages received. want: 1, got: %d", got)
+	}
+}
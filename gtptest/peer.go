@@ -0,0 +1,222 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtptest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// ResponseFunc returns the message to be sent in response to the request given.
+// If it returns nil, nothing is sent.
+type ResponseFunc func(req message.Message) message.Message
+
+// RespondWith returns a ResponseFunc that always responds with the message given.
+//
+// Each response is a copy of res with the Sequence Number of the request, and res
+// itself is not modified, so that it can be shared by the concurrent requests.
+func RespondWith(res message.Message) ResponseFunc {
+	return func(message.Message) message.Message {
+		b, err := message.Marshal(res)
+		if err != nil {
+			// the error is reported when res is sent.
+			return res
+		}
+		cp, err := message.Parse(b)
+		if err != nil {
+			return res
+		}
+		return cp
+	}
+}
+
+// Expectation is a request expected to be received by MockPeer.
+type Expectation struct {
+	msgType uint8
+	fn      ResponseFunc
+
+	times  int
+	called int
+}
+
+// Times sets how many times the request is expected. Default is 1.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+func (e *Expectation) satisfied() bool {
+	return e.called >= e.times
+}
+
+// MockPeer is a scriptable GTPv2-C peer.
+//
+// The requests are handled by the first Expectation registered with Expect that is
+// not satisfied yet and has the same message type. Echo Request is responded
+// automatically unless it is registered explicitly, so that gtpv2.Dial works.
+type MockPeer struct {
+	pc net.PacketConn
+
+	mu           sync.Mutex
+	expectations []*Expectation
+	received     []message.Message
+	unexpected   []message.Message
+	errs         []error
+}
+
+// NewMockPeer creates a new MockPeer over the net.PacketConn given, which is
+// typically the one created with Network.
+func NewMockPeer(pc net.PacketConn) *MockPeer {
+	return &MockPeer{pc: pc}
+}
+
+// Expect registers a request expected to be received and how to respond to it.
+func (p *MockPeer) Expect(msgType uint8, fn ResponseFunc) *Expectation {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e := &Expectation{msgType: msgType, fn: fn, times: 1}
+	p.expectations = append(p.expectations, e)
+	return e
+}
+
+// Serve starts handling the incoming messages. This blocks until ctx is canceled or
+// the underlying net.PacketConn is closed.
+func (p *MockPeer) Serve(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		_ = p.pc.Close()
+	}()
+
+	buf := make([]byte, 1500)
+	for {
+		n, raddr, err := p.pc.ReadFrom(buf)
+		if err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		// the message is parsed from a copy, as it refers to the bytes given
+		// and is kept in received.
+		raw := make([]byte, n)
+		copy(raw, buf)
+		msg, err := message.Parse(raw)
+		if err != nil {
+			p.addError(err)
+			continue
+		}
+		if err := p.handle(raddr, msg); err != nil {
+			p.addError(err)
+		}
+	}
+}
+
+func (p *MockPeer) handle(raddr net.Addr, msg message.Message) error {
+	p.mu.Lock()
+	p.received = append(p.received, msg)
+
+	var fn ResponseFunc
+	for _, e := range p.expectations {
+		if e.msgType != msg.MessageType() || e.satisfied() {
+			continue
+		}
+		e.called++
+		fn = e.fn
+		break
+	}
+
+	if fn == nil {
+		switch msg.MessageType() {
+		case message.MsgTypeEchoRequest:
+			fn = RespondWith(message.NewEchoResponse(0, ie.NewRecovery(0)))
+		default:
+			p.unexpected = append(p.unexpected, msg)
+			p.mu.Unlock()
+			return nil
+		}
+	}
+	p.mu.Unlock()
+
+	res := fn(msg)
+	if res == nil {
+		return nil
+	}
+	res.SetSequenceNumber(msg.Sequence())
+	return p.Send(res, raddr)
+}
+
+// Send sends a message to raddr, which is used to send the requests from MockPeer.
+func (p *MockPeer) Send(msg message.Message, raddr net.Addr) error {
+	b, err := message.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.pc.WriteTo(b, raddr)
+	return err
+}
+
+// Received returns all the messages received so far, including unexpected ones.
+func (p *MockPeer) Received() []message.Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	msgs := make([]message.Message, len(p.received))
+	copy(msgs, p.received)
+	return msgs
+}
+
+// Verify returns error if any of the Expectations is not satisfied, or any message
+// not expected or failed to be handled is received.
+func (p *MockPeer) Verify() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var reasons []string
+	for _, e := range p.expectations {
+		if !e.satisfied() {
+			reasons = append(reasons, fmt.Sprintf(
+				"expected message type %d %d time(s), got %d", e.msgType, e.times, e.called,
+			))
+		}
+	}
+	for _, msg := range p.unexpected {
+		reasons = append(reasons, fmt.Sprintf("unexpected message: %s", msg.MessageTypeName()))
+	}
+	for _, err := range p.errs {
+		reasons = append(reasons, err.Error())
+	}
+
+	if len(reasons) == 0 {
+		return nil
+	}
+	return &VerificationError{Reasons: reasons}
+}
+
+func (p *MockPeer) addError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.errs = append(p.errs, err)
+}
+
+// VerificationError indicates that MockPeer did not get the messages as expected.
+type VerificationError struct {
+	Reasons []string
+}
+
+// Error returns all the reasons of the failure.
+func (e *VerificationError) Error() string {
+	return "mock peer verification failed: " + strings.Join(e.Reasons, "; ")
+}
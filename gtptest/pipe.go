@@ -0,0 +1,251 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Package gtptest provides utilities for testing the applications built on go-gtp
+// without real UDP sockets.
+//
+// Network is an in-memory network that provides net.PacketConn, which can be passed to
+// gtpv2.NewConnWithPacketConn or gtpv1.NewUPlaneConnWithPacketConn. MockPeer is a
+// scriptable GTPv2-C peer that responds to the requests with the canned responses.
+package gtptest
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// queueSize is the number of packets that can be queued in a PacketConn.
+// Packets are dropped silently when the queue is full, as UDP does.
+const queueSize = 1024
+
+var (
+	// ErrAddrInUse indicates that the address is already used in the Network.
+	ErrAddrInUse = errors.New("address already in use")
+
+	// ErrClosed indicates that the PacketConn is already closed.
+	ErrClosed = errors.New("use of closed connection")
+)
+
+// timeoutError is returned when the deadline is exceeded, which implements net.Error.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// Network is an in-memory network that delivers packets between PacketConns.
+type Network struct {
+	mu    sync.Mutex
+	conns map[string]*PacketConn
+}
+
+// NewNetwork creates a new Network.
+func NewNetwork() *Network {
+	return &Network{conns: map[string]*PacketConn{}}
+}
+
+// ListenPacket creates a new PacketConn bound to the address given in "IP:Port" format.
+//
+// network is just passed to net.ResolveUDPAddr, so it should be "udp", "udp4" or "udp6".
+func (n *Network) ListenPacket(network, address string) (*PacketConn, error) {
+	laddr, err := net.ResolveUDPAddr(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	key := laddr.String()
+	if _, ok := n.conns[key]; ok {
+		return nil, ErrAddrInUse
+	}
+
+	pc := &PacketConn{
+		network: n,
+		laddr:   laddr,
+		rxCh:    make(chan *packet, queueSize),
+		closeCh: make(chan struct{}),
+		rdlCh:   make(chan struct{}),
+	}
+	n.conns[key] = pc
+	return pc, nil
+}
+
+// Pipe creates a new Network and returns two PacketConns bound to the addresses given.
+func Pipe(addr1, addr2 string) (*PacketConn, *PacketConn, error) {
+	n := NewNetwork()
+	pc1, err := n.ListenPacket("udp", addr1)
+	if err != nil {
+		return nil, nil, err
+	}
+	pc2, err := n.ListenPacket("udp", addr2)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pc1, pc2, nil
+}
+
+func (n *Network) lookup(addr net.Addr) (*PacketConn, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	pc, ok := n.conns[addr.String()]
+	return pc, ok
+}
+
+func (n *Network) remove(pc *PacketConn) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.conns[pc.laddr.String()] == pc {
+		delete(n.conns, pc.laddr.String())
+	}
+}
+
+type packet struct {
+	payload []byte
+	from    net.Addr
+}
+
+// PacketConn is an in-memory net.PacketConn that belongs to a Network.
+type PacketConn struct {
+	network *Network
+	laddr   *net.UDPAddr
+
+	rxCh      chan *packet
+	closeCh   chan struct{}
+	closeOnce sync.Once
+
+	mu sync.Mutex
+	// rdlCh is closed and replaced when the read deadline is changed, to wake up
+	// the blocking ReadFrom.
+	rdlCh         chan struct{}
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+// ReadFrom reads a packet from the PacketConn.
+//
+// It returns io.EOF after the PacketConn is closed, so that the Conns in go-gtp
+// stop serving without errors.
+func (p *PacketConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	for {
+		p.mu.Lock()
+		deadline, rdlCh := p.readDeadline, p.rdlCh
+		p.mu.Unlock()
+
+		var timeoutCh <-chan time.Time
+		var timer *time.Timer
+		if !deadline.IsZero() {
+			d := time.Until(deadline)
+			if d <= 0 {
+				return 0, nil, timeoutError{}
+			}
+			timer = time.NewTimer(d)
+			timeoutCh = timer.C
+		}
+
+		n, from, retry, err := p.read(b, timeoutCh, rdlCh)
+		if timer != nil {
+			timer.Stop()
+		}
+		if !retry {
+			return n, from, err
+		}
+	}
+}
+
+// read waits for a packet, or returns with retry=true if the read deadline is changed.
+func (p *PacketConn) read(b []byte, timeoutCh <-chan time.Time, rdlCh <-chan struct{}) (n int, from net.Addr, retry bool, err error) {
+	select {
+	case pkt := <-p.rxCh:
+		return copy(b, pkt.payload), pkt.from, false, nil
+	case <-p.closeCh:
+		return 0, nil, false, io.EOF
+	case <-timeoutCh:
+		return 0, nil, false, timeoutError{}
+	case <-rdlCh:
+		return 0, nil, true, nil
+	}
+}
+
+// WriteTo writes a packet to the PacketConn bound to addr in the same Network.
+//
+// Like UDP, the packet is dropped silently if there is no PacketConn bound to addr or
+// its queue is full.
+func (p *PacketConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	select {
+	case <-p.closeCh:
+		return 0, ErrClosed
+	default:
+	}
+
+	p.mu.Lock()
+	deadline := p.writeDeadline
+	p.mu.Unlock()
+	if !deadline.IsZero() && !time.Now().Before(deadline) {
+		return 0, timeoutError{}
+	}
+
+	dst, ok := p.network.lookup(addr)
+	if !ok {
+		return len(b), nil
+	}
+
+	payload := make([]byte, len(b))
+	copy(payload, b)
+	select {
+	case dst.rxCh <- &packet{payload: payload, from: p.laddr}:
+	default:
+	}
+	return len(b), nil
+}
+
+// Close closes the PacketConn and releases the address in the Network.
+func (p *PacketConn) Close() error {
+	err := ErrClosed
+	p.closeOnce.Do(func() {
+		close(p.closeCh)
+		p.network.remove(p)
+		err = nil
+	})
+	return err
+}
+
+// LocalAddr returns the address the PacketConn is bound to.
+func (p *PacketConn) LocalAddr() net.Addr {
+	return p.laddr
+}
+
+// SetDeadline sets the read and write deadlines.
+func (p *PacketConn) SetDeadline(t time.Time) error {
+	if err := p.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return p.SetWriteDeadline(t)
+}
+
+// SetReadDeadline sets the deadline for ReadFrom, including the one currently blocked.
+func (p *PacketConn) SetReadDeadline(t time.Time) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.readDeadline = t
+	close(p.rdlCh)
+	p.rdlCh = make(chan struct{})
+	return nil
+}
+
+// SetWriteDeadline sets the deadline for WriteTo.
+func (p *PacketConn) SetWriteDeadline(t time.Time) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.writeDeadline = t
+	return nil
+}
@@ -0,0 +1,247 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Package gtptft classifies user IP packets to the bearers with the packet
+// filters in TFTs, as UE and PGW do.
+package gtptft
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+// Error definitions.
+var (
+	ErrNoMatch       = errors.New("no packet filter matches and no default bearer")
+	ErrInvalidPacket = errors.New("not a valid IPv4 or IPv6 packet")
+	ErrNoTFT         = errors.New("bearer has no TFT to be modified")
+	ErrInvalidTFT    = errors.New("invalid TFT operation")
+)
+
+// filter is a packet filter installed for a bearer.
+type filter struct {
+	ebi        uint8
+	id         uint8
+	direction  uint8
+	precedence uint8
+	seq        uint64
+	components *ie.PacketFilterComponents
+}
+
+// Classifier classifies IP packets to the bearers by the packet filters in the
+// TFTs applied, evaluated in the order of precedence (smaller value first).
+//
+// It is safe for concurrent use.
+type Classifier struct {
+	mu         sync.RWMutex
+	filters    map[uint8][]*filter
+	sorted     []*filter
+	seq        uint64
+	defaultEBI uint8
+	hasDefault bool
+}
+
+// NewClassifier creates a new Classifier with no TFT.
+func NewClassifier() *Classifier {
+	return &Classifier{filters: map[uint8][]*filter{}}
+}
+
+// SetDefaultBearer sets the bearer to which the packets that match no packet
+// filter are classified, which is typically the default bearer without TFT.
+func (c *Classifier) SetDefaultBearer(ebi uint8) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.defaultEBI = ebi
+	c.hasDefault = true
+}
+
+// Apply applies the TFT operation in the Bearer TFT IE to the packet filters
+// of the bearer identified by ebi.
+func (c *Classifier) Apply(ebi uint8, tft *ie.IE) error {
+	f, err := tft.TrafficFlowTemplate()
+	if err != nil {
+		return err
+	}
+	return c.ApplyFields(ebi, f)
+}
+
+// ApplyFields applies the TFT operation in TrafficFlowTemplateFields to the
+// packet filters of the bearer identified by ebi, as defined in TS 24.008
+// 10.5.6.12.
+//
+// It returns ErrNoTFT if the operation modifies the existing TFT and the
+// bearer has none.
+func (c *Classifier) ApplyFields(ebi uint8, tft *ie.TrafficFlowTemplateFields) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	existing, hasTFT := c.filters[ebi]
+	switch tft.OperationCode {
+	case ie.TFTOpCreateNewTFT:
+		filters, err := c.newFilters(ebi, tft.PacketFilters)
+		if err != nil {
+			return err
+		}
+		c.filters[ebi] = filters
+	case ie.TFTOpDeleteExistingTFT:
+		delete(c.filters, ebi)
+	case ie.TFTOpAddPacketFiltersToExistingTFT, ie.TFTOpReplacePacketFiltersInExistingTFT:
+		if !hasTFT {
+			return ErrNoTFT
+		}
+		filters, err := c.newFilters(ebi, tft.PacketFilters)
+		if err != nil {
+			return err
+		}
+		c.filters[ebi] = append(withoutIDs(existing, filters), filters...)
+	case ie.TFTOpDeletePacketFiltersFromExistingTFT:
+		if !hasTFT {
+			return ErrNoTFT
+		}
+		var ids []*filter
+		for _, pf := range tft.PacketFilters {
+			ids = append(ids, &filter{id: pf.Identifier})
+		}
+		remaining := withoutIDs(existing, ids)
+		if len(remaining) == 0 {
+			delete(c.filters, ebi)
+		} else {
+			c.filters[ebi] = remaining
+		}
+	case ie.TFTOpNoTFTOperation:
+		return nil
+	default:
+		return fmt.Errorf("%w: operation code %d", ErrInvalidTFT, tft.OperationCode)
+	}
+
+	c.sort()
+	return nil
+}
+
+// RemoveBearer removes all the packet filters of the bearer, and unsets the
+// default bearer if it is the one.
+func (c *Classifier) RemoveBearer(ebi uint8) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.filters, ebi)
+	if c.hasDefault && c.defaultEBI == ebi {
+		c.hasDefault = false
+	}
+	c.sort()
+}
+
+// Bearers returns the EBIs of the bearers that have TFT in ascending order.
+func (c *Classifier) Bearers() []uint8 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ebis := make([]uint8, 0, len(c.filters))
+	for ebi := range c.filters {
+		ebis = append(ebis, ebi)
+	}
+	sort.Slice(ebis, func(i, j int) bool { return ebis[i] < ebis[j] })
+	return ebis
+}
+
+// Classify returns the EBI of the bearer that the IP packet belongs to.
+//
+// dir is the direction of the packet, either ie.TFTPFUplinkOnly for the ones
+// sent by the UE or ie.TFTPFDownlinkOnly for the ones sent to the UE. The
+// pre-Release 7 filters are applied only to the downlink packets.
+//
+// It returns ErrNoMatch if no packet filter matches and the default bearer
+// is not set.
+func (c *Classifier) Classify(pkt []byte, dir uint8) (uint8, error) {
+	p, err := parsePacket(pkt)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, f := range c.sorted {
+		if !appliesTo(f.direction, dir) {
+			continue
+		}
+		if p.matches(f.components, dir) {
+			return f.ebi, nil
+		}
+	}
+
+	if c.hasDefault {
+		return c.defaultEBI, nil
+	}
+	return 0, ErrNoMatch
+}
+
+func (c *Classifier) newFilters(ebi uint8, pfs []*ie.TFTPacketFilter) ([]*filter, error) {
+	filters := make([]*filter, 0, len(pfs))
+	for _, pf := range pfs {
+		comps, err := pf.Components()
+		if err != nil {
+			return nil, err
+		}
+		c.seq++
+		filters = append(filters, &filter{
+			ebi:        ebi,
+			id:         pf.Identifier,
+			direction:  pf.Direction,
+			precedence: pf.Precedence,
+			seq:        c.seq,
+			components: comps,
+		})
+	}
+	return filters, nil
+}
+
+// withoutIDs returns the filters that do not have the identifiers in ids.
+func withoutIDs(filters, ids []*filter) []*filter {
+	var remaining []*filter
+	for _, f := range filters {
+		found := false
+		for _, id := range ids {
+			if f.id == id.id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			remaining = append(remaining, f)
+		}
+	}
+	return remaining
+}
+
+// sort rebuilds the list of the filters ordered by precedence. The filters
+// with the same precedence are evaluated in the order they are installed.
+func (c *Classifier) sort() {
+	c.sorted = c.sorted[:0]
+	for _, filters := range c.filters {
+		c.sorted = append(c.sorted, filters...)
+	}
+	sort.Slice(c.sorted, func(i, j int) bool {
+		if c.sorted[i].precedence != c.sorted[j].precedence {
+			return c.sorted[i].precedence < c.sorted[j].precedence
+		}
+		return c.sorted[i].seq < c.sorted[j].seq
+	})
+}
+
+func appliesTo(pfDir, dir uint8) bool {
+	switch pfDir {
+	case ie.TFTPFBidirectional:
+		return true
+	case ie.TFTPFPreRel7TFTFilter:
+		return dir == ie.TFTPFDownlinkOnly
+	default:
+		return pfDir == dir
+	}
+}
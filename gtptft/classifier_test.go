@@ -0,0 +1,258 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtptft_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/wmnsk/go-gtp/gtptft"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+var (
+	ueIPv4 = net.IP{10, 0, 0, 1}
+	ueIPv6 = net.ParseIP("2001:db8::1")
+)
+
+func ipv4Packet(src, dst net.IP, proto, tos uint8, sport, dport uint16) []byte {
+	b := make([]byte, 28)
+	b[0] = 0x45
+	b[1] = tos
+	binary.BigEndian.PutUint16(b[2:4], uint16(len(b)))
+	b[8] = 64
+	b[9] = proto
+	copy(b[12:16], src.To4())
+	copy(b[16:20], dst.To4())
+	binary.BigEndian.PutUint16(b[20:22], sport)
+	binary.BigEndian.PutUint16(b[22:24], dport)
+	return b
+}
+
+func ipv6Packet(src, dst net.IP, proto uint8, flowLabel uint32, sport, dport uint16) []byte {
+	b := make([]byte, 48)
+	binary.BigEndian.PutUint32(b[0:4], 6<<28|flowLabel)
+	binary.BigEndian.PutUint16(b[4:6], 8)
+	b[6] = proto
+	b[7] = 64
+	copy(b[8:24], src.To16())
+	copy(b[24:40], dst.To16())
+	binary.BigEndian.PutUint16(b[40:42], sport)
+	binary.BigEndian.PutUint16(b[42:44], dport)
+	return b
+}
+
+func ipNet(cidr string) *net.IPNet {
+	ip, n, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	if v4 := ip.To4(); v4 != nil {
+		n.IP = v4
+	}
+	return n
+}
+
+func newClassifier(t *testing.T) *gtptft.Classifier {
+	t.Helper()
+
+	c := gtptft.NewClassifier()
+	c.SetDefaultBearer(5)
+
+	// EBI 6: SIP signalling to the P-CSCF, both directions.
+	if err := c.Apply(6, ie.NewBearerTFT(ie.TFTOpCreateNewTFT, []*ie.TFTPacketFilter{
+		ie.NewTFTPacketFilter(1, ie.TFTPFBidirectional, 10, &ie.PacketFilterComponents{
+			RemoteAddress:  ipNet("192.0.2.10/32"),
+			HasProtocol:    true,
+			Protocol:       17,
+			RemotePortLow:  5060,
+			RemotePortHigh: 5060,
+		}),
+	}, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	// EBI 7: RTP media, uplink and downlink filters for any remote in
+	// 192.0.2.0/24 with local port range, and an IPv6 flow label.
+	if err := c.Apply(7, ie.NewBearerTFT(ie.TFTOpCreateNewTFT, []*ie.TFTPacketFilter{
+		ie.NewTFTPacketFilter(1, ie.TFTPFUplinkOnly, 20, &ie.PacketFilterComponents{
+			RemoteAddress: ipNet("192.0.2.0/24"),
+			LocalPortLow:  40000,
+			LocalPortHigh: 40100,
+		}),
+		ie.NewTFTPacketFilter(2, ie.TFTPFDownlinkOnly, 21, &ie.PacketFilterComponents{
+			RemoteAddress:     ipNet("192.0.2.0/24"),
+			HasTypeOfService:  true,
+			TypeOfService:     0xb8,
+			TypeOfServiceMask: 0xfc,
+		}),
+		ie.NewTFTPacketFilter(3, ie.TFTPFBidirectional, 22, &ie.PacketFilterComponents{
+			RemoteAddress: ipNet("2001:db8:ffff::/48"),
+			HasFlowLabel:  true,
+			FlowLabel:     0x12345,
+		}),
+	}, nil)); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestClassify(t *testing.T) {
+	c := newClassifier(t)
+
+	cases := []struct {
+		description string
+		pkt         []byte
+		dir         uint8
+		want        uint8
+	}{
+		{
+			"SIP/Uplink",
+			ipv4Packet(ueIPv4, net.IP{192, 0, 2, 10}, 17, 0, 5060, 5060),
+			ie.TFTPFUplinkOnly, 6,
+		}, {
+			"SIP/Downlink",
+			ipv4Packet(net.IP{192, 0, 2, 10}, ueIPv4, 17, 0, 5060, 5060),
+			ie.TFTPFDownlinkOnly, 6,
+		}, {
+			"SIP/TCP",
+			ipv4Packet(ueIPv4, net.IP{192, 0, 2, 10}, 6, 0, 5060, 5060),
+			ie.TFTPFUplinkOnly, 5,
+		}, {
+			"RTP/Uplink",
+			ipv4Packet(ueIPv4, net.IP{192, 0, 2, 20}, 17, 0, 40050, 30000),
+			ie.TFTPFUplinkOnly, 7,
+		}, {
+			"RTP/UplinkOutOfRange",
+			ipv4Packet(ueIPv4, net.IP{192, 0, 2, 20}, 17, 0, 40101, 30000),
+			ie.TFTPFUplinkOnly, 5,
+		}, {
+			"RTP/DownlinkByDSCP",
+			ipv4Packet(net.IP{192, 0, 2, 20}, ueIPv4, 17, 0xba, 30000, 40050),
+			ie.TFTPFDownlinkOnly, 7,
+		}, {
+			"RTP/DownlinkWrongDSCP",
+			ipv4Packet(net.IP{192, 0, 2, 20}, ueIPv4, 17, 0x00, 30000, 40050),
+			ie.TFTPFDownlinkOnly, 5,
+		}, {
+			"IPv6/FlowLabel",
+			ipv6Packet(ueIPv6, net.ParseIP("2001:db8:ffff::1"), 17, 0x12345, 1000, 2000),
+			ie.TFTPFUplinkOnly, 7,
+		}, {
+			"IPv6/WrongFlowLabel",
+			ipv6Packet(ueIPv6, net.ParseIP("2001:db8:ffff::1"), 17, 0x54321, 1000, 2000),
+			ie.TFTPFUplinkOnly, 5,
+		}, {
+			"IPv6/NotMatchingIPv4Filter",
+			ipv6Packet(ueIPv6, net.ParseIP("::ffff:192.0.2.10"), 17, 0, 5060, 5060),
+			ie.TFTPFUplinkOnly, 5,
+		},
+	}
+
+	for _, c2 := range cases {
+		t.Run(c2.description, func(t *testing.T) {
+			got, err := c.Classify(c2.pkt, c2.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c2.want {
+				t.Errorf("want EBI %d, got %d", c2.want, got)
+			}
+		})
+	}
+}
+
+func TestClassifyPrecedence(t *testing.T) {
+	c := gtptft.NewClassifier()
+	udp := &ie.PacketFilterComponents{HasProtocol: true, Protocol: 17}
+
+	if err := c.Apply(6, ie.NewBearerTFT(ie.TFTOpCreateNewTFT, []*ie.TFTPacketFilter{
+		ie.NewTFTPacketFilter(1, ie.TFTPFBidirectional, 200, udp),
+	}, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Apply(7, ie.NewBearerTFT(ie.TFTOpCreateNewTFT, []*ie.TFTPacketFilter{
+		ie.NewTFTPacketFilter(1, ie.TFTPFBidirectional, 100, udp),
+	}, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	pkt := ipv4Packet(ueIPv4, net.IP{192, 0, 2, 1}, 17, 0, 1, 2)
+	if got, err := c.Classify(pkt, ie.TFTPFUplinkOnly); err != nil || got != 7 {
+		t.Errorf("want EBI 7 with higher precedence, got %d, %v", got, err)
+	}
+
+	// replace the filter of EBI 6 with the one with higher precedence.
+	if err := c.Apply(6, ie.NewBearerTFT(ie.TFTOpReplacePacketFiltersInExistingTFT, []*ie.TFTPacketFilter{
+		ie.NewTFTPacketFilter(1, ie.TFTPFBidirectional, 50, udp),
+	}, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := c.Classify(pkt, ie.TFTPFUplinkOnly); err != nil || got != 6 {
+		t.Errorf("want EBI 6 after replaced, got %d, %v", got, err)
+	}
+
+	// no default bearer.
+	c.RemoveBearer(6)
+	c.RemoveBearer(7)
+	if _, err := c.Classify(pkt, ie.TFTPFUplinkOnly); !errors.Is(err, gtptft.ErrNoMatch) {
+		t.Errorf("want ErrNoMatch, got %v", err)
+	}
+	if _, err := c.Classify([]byte{0x00}, ie.TFTPFUplinkOnly); !errors.Is(err, gtptft.ErrInvalidPacket) {
+		t.Errorf("want ErrInvalidPacket, got %v", err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	c := newClassifier(t)
+
+	if diff := cmp.Diff(c.Bearers(), []uint8{6, 7}); diff != "" {
+		t.Error(diff)
+	}
+
+	// delete the RTP filters but the IPv6 one.
+	if err := c.Apply(7, ie.NewBearerTFT(ie.TFTOpDeletePacketFiltersFromExistingTFT, []*ie.TFTPacketFilter{
+		{Identifier: 1}, {Identifier: 2},
+	}, nil)); err != nil {
+		t.Fatal(err)
+	}
+	pkt := ipv4Packet(ueIPv4, net.IP{192, 0, 2, 20}, 17, 0, 40050, 30000)
+	if got, _ := c.Classify(pkt, ie.TFTPFUplinkOnly); got != 5 {
+		t.Errorf("want EBI 5 after the filter deleted, got %d", got)
+	}
+
+	// add it back.
+	if err := c.Apply(7, ie.NewBearerTFT(ie.TFTOpAddPacketFiltersToExistingTFT, []*ie.TFTPacketFilter{
+		ie.NewTFTPacketFilter(1, ie.TFTPFUplinkOnly, 20, &ie.PacketFilterComponents{LocalPortLow: 40050}),
+	}, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.Classify(pkt, ie.TFTPFUplinkOnly); got != 7 {
+		t.Errorf("want EBI 7 after the filter added, got %d", got)
+	}
+
+	// deleting all the filters removes the TFT.
+	if err := c.Apply(7, ie.NewBearerTFT(ie.TFTOpDeletePacketFiltersFromExistingTFT, []*ie.TFTPacketFilter{
+		{Identifier: 1}, {Identifier: 3},
+	}, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Apply(6, ie.NewBearerTFT(ie.TFTOpDeleteExistingTFT, nil, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Bearers(); len(got) != 0 {
+		t.Errorf("want no bearer with TFT, got %v", got)
+	}
+
+	err := c.Apply(6, ie.NewBearerTFT(ie.TFTOpAddPacketFiltersToExistingTFT, []*ie.TFTPacketFilter{
+		ie.NewTFTPacketFilter(1, ie.TFTPFUplinkOnly, 20, &ie.PacketFilterComponents{}),
+	}, nil))
+	if !errors.Is(err, gtptft.ErrNoTFT) {
+		t.Errorf("want ErrNoTFT, got %v", err)
+	}
+}
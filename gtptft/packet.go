@@ -0,0 +1,191 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtptft
+
+import (
+	"encoding/binary"
+	"net"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+// IP protocol numbers used in classification.
+const (
+	protoHopByHop = 0
+	protoTCP      = 6
+	protoUDP      = 17
+	protoRouting  = 43
+	protoFragment = 44
+	protoESP      = 50
+	protoAH       = 51
+	protoDestOpts = 60
+	protoSCTP     = 132
+	protoUDPLite  = 136
+)
+
+const (
+	ipv4MinHeaderLen = 20
+	ipv6HeaderLen    = 40
+	// maxExtHeaders is the number of IPv6 extension headers to be skipped at
+	// most, which is enough for the legitimate packets.
+	maxExtHeaders = 8
+)
+
+// packet is the fields of an IP packet used in classification.
+type packet struct {
+	src, dst  net.IP
+	protocol  uint8
+	tos       uint8
+	isIPv6    bool
+	flowLabel uint32
+
+	hasPorts         bool
+	srcPort, dstPort uint16
+	hasSPI           bool
+	spi              uint32
+}
+
+func parsePacket(b []byte) (*packet, error) {
+	if len(b) < 1 {
+		return nil, ErrInvalidPacket
+	}
+
+	p := &packet{}
+	var payload []byte
+	switch b[0] >> 4 {
+	case 4:
+		ihl := int(b[0]&0x0f) * 4
+		if ihl < ipv4MinHeaderLen || len(b) < ihl {
+			return nil, ErrInvalidPacket
+		}
+		p.tos = b[1]
+		p.protocol = b[9]
+		p.src = net.IP(b[12:16])
+		p.dst = net.IP(b[16:20])
+
+		// the ports are only in the first fragment.
+		if binary.BigEndian.Uint16(b[6:8])&0x1fff == 0 {
+			payload = b[ihl:]
+		}
+	case 6:
+		if len(b) < ipv6HeaderLen {
+			return nil, ErrInvalidPacket
+		}
+		p.isIPv6 = true
+		v := binary.BigEndian.Uint32(b[0:4])
+		p.tos = uint8(v >> 20)
+		p.flowLabel = v & 0x000fffff
+		p.src = net.IP(b[8:24])
+		p.dst = net.IP(b[24:40])
+		p.protocol, payload = skipExtHeaders(b[6], b[ipv6HeaderLen:])
+	default:
+		return nil, ErrInvalidPacket
+	}
+
+	switch p.protocol {
+	case protoTCP, protoUDP, protoSCTP, protoUDPLite:
+		if len(payload) >= 4 {
+			p.hasPorts = true
+			p.srcPort = binary.BigEndian.Uint16(payload[0:2])
+			p.dstPort = binary.BigEndian.Uint16(payload[2:4])
+		}
+	case protoESP:
+		if len(payload) >= 4 {
+			p.hasSPI = true
+			p.spi = binary.BigEndian.Uint32(payload[0:4])
+		}
+	case protoAH:
+		if len(payload) >= 8 {
+			p.hasSPI = true
+			p.spi = binary.BigEndian.Uint32(payload[4:8])
+		}
+	}
+	return p, nil
+}
+
+// skipExtHeaders skips the IPv6 extension headers and returns the upper-layer
+// protocol and its payload. The payload is nil if it is not the first fragment
+// or the headers are truncated.
+func skipExtHeaders(next uint8, b []byte) (uint8, []byte) {
+	for i := 0; i < maxExtHeaders; i++ {
+		switch next {
+		case protoHopByHop, protoRouting, protoDestOpts:
+			if len(b) < 2 || len(b) < (int(b[1])+1)*8 {
+				return next, nil
+			}
+			next, b = b[0], b[(int(b[1])+1)*8:]
+		case protoFragment:
+			if len(b) < 8 {
+				return next, nil
+			}
+			next = b[0]
+			if binary.BigEndian.Uint16(b[2:4])&0xfff8 != 0 {
+				return next, nil
+			}
+			b = b[8:]
+		default:
+			return next, b
+		}
+	}
+	return next, nil
+}
+
+// matches reports whether the packet matches all the components in the
+// filter. The local side is the UE, which is the source in uplink and the
+// destination in downlink.
+func (p *packet) matches(c *ie.PacketFilterComponents, dir uint8) bool {
+	local, remote := p.dst, p.src
+	localPort, remotePort := p.dstPort, p.srcPort
+	if dir == ie.TFTPFUplinkOnly {
+		local, remote = p.src, p.dst
+		localPort, remotePort = p.srcPort, p.dstPort
+	}
+
+	if c.RemoteAddress != nil && !containsIP(c.RemoteAddress, remote) {
+		return false
+	}
+	if c.LocalAddress != nil && !containsIP(c.LocalAddress, local) {
+		return false
+	}
+	if c.HasProtocol && c.Protocol != p.protocol {
+		return false
+	}
+	if c.LocalPortLow != 0 || c.LocalPortHigh != 0 {
+		if !p.hasPorts || !inRange(localPort, c.LocalPortLow, c.LocalPortHigh) {
+			return false
+		}
+	}
+	if c.RemotePortLow != 0 || c.RemotePortHigh != 0 {
+		if !p.hasPorts || !inRange(remotePort, c.RemotePortLow, c.RemotePortHigh) {
+			return false
+		}
+	}
+	if c.HasSecurityParameterIndex && (!p.hasSPI || c.SecurityParameterIndex != p.spi) {
+		return false
+	}
+	if c.HasTypeOfService && c.TypeOfService&c.TypeOfServiceMask != p.tos&c.TypeOfServiceMask {
+		return false
+	}
+	if c.HasFlowLabel && (!p.isIPv6 || c.FlowLabel != p.flowLabel) {
+		return false
+	}
+	return true
+}
+
+// containsIP reports whether the network contains the IP, which never matches
+// if the address families are different.
+func containsIP(n *net.IPNet, ip net.IP) bool {
+	if (n.IP.To4() != nil) != (len(ip) == net.IPv4len) {
+		return false
+	}
+	return n.Contains(ip)
+}
+
+func inRange(port, low, high uint16) bool {
+	if high == 0 {
+		high = low
+	}
+	return port >= low && port <= high
+}
@@ -4,6 +4,9 @@
 
 package gtpv0
 
+// Registered UDP port, which is used for both C-Plane and U-Plane.
+const GTPPort = ":3386"
+
 // Cause definitions.
 const (
 	CauseRequestIMSI              uint8 = 0
@@ -0,0 +1,19 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv0
+
+import "errors"
+
+var (
+	// ErrConnNotOpened indicates that some operation is failed due to the status of
+	// Conn is not valid.
+	ErrConnNotOpened = errors.New("connection is not opened")
+
+	// ErrTunnelNotFound indicates that no tunnel is found with the TID given.
+	ErrTunnelNotFound = errors.New("no tunnel found with the TID")
+
+	// ErrInvalidIMSI indicates that the IMSI cannot be put in TID.
+	ErrInvalidIMSI = errors.New("invalid IMSI")
+)
@@ -43,7 +43,7 @@ func (i *IE) AccessPointName() (string, error) {
 			break
 		}
 		l := int(i.Payload[offset])
-		if offset+l+1 >= max {
+		if offset+l+1 > max {
 			return "", io.ErrUnexpectedEOF
 		}
 		apn = append(apn, string(i.Payload[offset+1:offset+l+1]))
@@ -34,6 +34,24 @@ func NewEndUserAddress(addr string) *IE {
 	return newEUAddrV6(ip)
 }
 
+// NewEndUserAddressByIP creates a new EndUserAddress IE from net.IP.
+//
+// Unlike NewEndUserAddress, this returns nil if ip is nil.
+func NewEndUserAddressByIP(ip net.IP) *IE {
+	if ip == nil {
+		return nil
+	}
+
+	v4 := ip.To4()
+
+	// IPv4
+	if v4 != nil {
+		return newEUAddrV4(v4)
+	}
+
+	return newEUAddrV6(ip)
+}
+
 // NewEndUserAddressIPv4 creates a new EndUserAddress IE with IPv4.
 func NewEndUserAddressIPv4(addr string) *IE {
 	v4 := net.ParseIP(addr).To4()
@@ -10,6 +10,8 @@ package ie
 import (
 	"encoding/binary"
 	"fmt"
+
+	"github.com/wmnsk/go-gtp/utils"
 )
 
 // TV IE definitions.
@@ -73,6 +75,18 @@ func (i *IE) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from an IE instance to b and
+// returns the extended buffer. b is reallocated only when its capacity is not
+// enough, so the buffers can be reused without allocating.
+func (i *IE) AppendTo(b []byte) ([]byte, error) {
+	l := len(b)
+	b = utils.Grow(b, i.MarshalLen())
+	if err := i.MarshalTo(b[l:]); err != nil {
+		return b[:l], err
+	}
+	return b, nil
+}
+
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (i *IE) MarshalTo(b []byte) error {
 	if len(b) < i.MarshalLen() {
@@ -5,6 +5,7 @@
 package ie_test
 
 import (
+	"net"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -12,213 +13,268 @@ import (
 	"github.com/wmnsk/go-gtp/gtpv0/ie"
 )
 
-func TestIE(t *testing.T) {
-	cases := []struct {
-		description string
-		structured  *ie.IE
-		Serialized  []byte
-	}{
-		{
-			"Cause",
-			ie.NewCause(v0.CauseRequestAccepted),
-			[]byte{0x01, 0x80},
-		}, {
-			"IMSI",
-			ie.NewIMSI("123450123456789"),
-			[]byte{0x02, 0x21, 0x43, 0x05, 0x21, 0x43, 0x65, 0x87, 0xf9},
-		}, {
-			"RAI",
-			ie.NewRouteingAreaIdentity("123", "45", 0x1111, 0x22),
-			[]byte{0x03, 0x21, 0xf3, 0x54, 0x11, 0x11, 0x22},
-		}, {
-			"TLLI",
-			ie.NewTemporaryLogicalLinkIdentity(0xff00ff00),
-			[]byte{0x04, 0xff, 0x00, 0xff, 0x00},
-		}, {
-			"PacketTMSI",
-			ie.NewPacketTMSI(0xdeadbeef),
-			[]byte{0x05, 0xde, 0xad, 0xbe, 0xef},
-		}, { // XXX - not implemented fully
-			"QoS Profile",
-			ie.NewQualityOfServiceProfile(1, 1, 1, 1, 1),
-			[]byte{0x06, 0x09, 0x11, 0x01},
-		}, {
-			"ReorderingRequired",
-			ie.NewReorderingRequired(false),
-			[]byte{0x08, 0xfe},
+var testIEs = []struct {
+	description string
+	structured  *ie.IE
+	Serialized  []byte
+}{
+	{
+		"Cause",
+		ie.NewCause(v0.CauseRequestAccepted),
+		[]byte{0x01, 0x80},
+	}, {
+		"IMSI",
+		ie.NewIMSI("123450123456789"),
+		[]byte{0x02, 0x21, 0x43, 0x05, 0x21, 0x43, 0x65, 0x87, 0xf9},
+	}, {
+		"RAI",
+		ie.NewRouteingAreaIdentity("123", "45", 0x1111, 0x22),
+		[]byte{0x03, 0x21, 0xf3, 0x54, 0x11, 0x11, 0x22},
+	}, {
+		"TLLI",
+		ie.NewTemporaryLogicalLinkIdentity(0xff00ff00),
+		[]byte{0x04, 0xff, 0x00, 0xff, 0x00},
+	}, {
+		"PacketTMSI",
+		ie.NewPacketTMSI(0xdeadbeef),
+		[]byte{0x05, 0xde, 0xad, 0xbe, 0xef},
+	}, {
+		"QoS Profile",
+		ie.NewQualityOfServiceProfile(1, 1, 1, 1, 1),
+		[]byte{0x06, 0x09, 0x11, 0x01},
+	}, {
+		"ReorderingRequired",
+		ie.NewReorderingRequired(false),
+		[]byte{0x08, 0xfe},
+	}, {
+		"AuthenticationTriplet",
+		ie.NewAuthenticationTriplet(
+			[]byte{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01},
+			[]byte{0x02, 0x02, 0x02, 0x02},
+			[]byte{0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03},
+		),
+		[]byte{
+			0x09,
+			// RAND
+			0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+			// SRES
+			0x02, 0x02, 0x02, 0x02,
+			// Kc
+			0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03,
 		},
-		/* XXX - not implemented
-		{
-			"AuthenticationTriplet",
-			ie.NewAuthenticationTriplet(),
-			[]byte{},
-		}, {
-			"MAPCause",
-			ie.NewMAPCause(),
-			[]byte{},
-		}, {
-			"PacketTMSISignature",
-			ie.NewPacketTMSISignature(),
-			[]byte{},
-		}, {
-			"MSValidated",
-			ie.NewMSValidated(),
-			[]byte{},
-		},*/
-		{
-			"PTMSISignature",
-			ie.NewPTMSISignature(0xbeebee),
-			[]byte{0x0c, 0xbe, 0xeb, 0xee},
-		}, {
-			"Recovery",
-			ie.NewRecovery(0x80),
-			[]byte{0x0e, 0x80},
-		}, {
-			"SelectionMode",
-			ie.NewSelectionMode(0xff),
-			[]byte{0x0f, 0xff},
-		}, {
-			"FlowLabelDataI",
-			ie.NewFlowLabelDataI(0x0001),
-			[]byte{0x10, 0x00, 0x01},
-		}, {
-			"FlowLabelSignalling",
-			ie.NewFlowLabelSignalling(0x0001),
-			[]byte{0x11, 0x00, 0x01},
-		}, {
-			"FlowLabelDataII",
-			ie.NewFlowLabelDataII(5, 0x0001),
-			[]byte{0x12, 0xf5, 0x00, 0x01},
-		}, {
-			"MSNotReachableReason",
-			ie.NewMSNotReachableReason(0xff),
-			[]byte{0x13, 0xff},
-		}, {
-			"ChargingID",
-			ie.NewChargingID(0xff00ff00),
-			[]byte{0x7f, 0xff, 0x00, 0xff, 0x00},
-		}, {
-			"EndUserAddress/v4",
-			ie.NewEndUserAddressIPv4("1.1.1.1"),
-			[]byte{
-				// Type, Length
-				0x80, 0x00, 0x06,
-				// Value
-				0xf1, 0x21, 0x01, 0x01, 0x01, 0x01,
-			},
-		}, {
-			"EndUserAddress/v6",
-			ie.NewEndUserAddressIPv6("2001::1"),
-			[]byte{
-				// Type, Length
-				0x80, 0x00, 0x12,
-				// Value
-				0xf1, 0x57, 0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
-			},
-		}, {
-			"EndUserAddress/ppp",
-			ie.NewEndUserAddressPPP(),
-			[]byte{
-				// Type, Length
-				0x80, 0x00, 0x02,
-				// Value
-				0xf0, 0xf1,
-			},
+	}, {
+		"MAPCause",
+		ie.NewMAPCause(1),
+		[]byte{0x0b, 0x01},
+	}, {
+		"MSValidated",
+		ie.NewMSValidated(true),
+		[]byte{0x0d, 0xff},
+	}, {
+		"PTMSISignature",
+		ie.NewPTMSISignature(0xbeebee),
+		[]byte{0x0c, 0xbe, 0xeb, 0xee},
+	}, {
+		"Recovery",
+		ie.NewRecovery(0x80),
+		[]byte{0x0e, 0x80},
+	}, {
+		"SelectionMode",
+		ie.NewSelectionMode(0xff),
+		[]byte{0x0f, 0xff},
+	}, {
+		"FlowLabelDataI",
+		ie.NewFlowLabelDataI(0x0001),
+		[]byte{0x10, 0x00, 0x01},
+	}, {
+		"FlowLabelSignalling",
+		ie.NewFlowLabelSignalling(0x0001),
+		[]byte{0x11, 0x00, 0x01},
+	}, {
+		"FlowLabelDataII",
+		ie.NewFlowLabelDataII(5, 0x0001),
+		[]byte{0x12, 0xf5, 0x00, 0x01},
+	}, {
+		"MSNotReachableReason",
+		ie.NewMSNotReachableReason(0xff),
+		[]byte{0x13, 0xff},
+	}, {
+		"ChargingID",
+		ie.NewChargingID(0xff00ff00),
+		[]byte{0x7f, 0xff, 0x00, 0xff, 0x00},
+	}, {
+		"EndUserAddress/v4",
+		ie.NewEndUserAddressIPv4("1.1.1.1"),
+		[]byte{
+			// Type, Length
+			0x80, 0x00, 0x06,
+			// Value
+			0xf1, 0x21, 0x01, 0x01, 0x01, 0x01,
 		},
-		/* XXX - not implemented
-		{
-			"MMContext",
-			ie.NewMMContext(),
-			[]byte{
-				// Type, Length
-				0x81, 0x00, 0x00,
-				// Value
-			},
-		}, {
-			"PDPContext",
-			ie.NewPDPContext(),
-			[]byte{
-				// Type, Length
-				0x81, 0x00, 0x00,
-				// Value
-			},
+	}, {
+		"EndUserAddress/v6",
+		ie.NewEndUserAddressIPv6("2001::1"),
+		[]byte{
+			// Type, Length
+			0x80, 0x00, 0x12,
+			// Value
+			0xf1, 0x57, 0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
 		},
-		*/
-		{
-			"AccessPointName",
-			ie.NewAccessPointName("some.apn.example"),
-			[]byte{
-				// Type, Length
-				0x83, 0x00, 0x11,
-				// Value
-				0x04, 0x73, 0x6f, 0x6d, 0x65, 0x03, 0x61, 0x70, 0x6e, 0x07, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65,
-			},
+	}, {
+		"EndUserAddress/ppp",
+		ie.NewEndUserAddressPPP(),
+		[]byte{
+			// Type, Length
+			0x80, 0x00, 0x02,
+			// Value
+			0xf0, 0xf1,
 		},
-		/* XXX - not implemented
-		{
-			"PCO",
-			ie.NewProtocolConfigurationOption(),
-			[]byte{},
-		}, */
-		{
-			"GSNAddress/v4",
-			ie.NewGSNAddress("1.1.1.1"),
-			[]byte{
-				// Type, Length
-				0x85, 0x00, 0x04,
-				// Value
-				0x01, 0x01, 0x01, 0x01,
-			},
-		}, {
-			"GSNAddress/v6",
-			ie.NewGSNAddress("2001::1"),
-			[]byte{
-				// Type, Length
-				0x85, 0x00, 0x10,
-				// Value
-				0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
-			},
-		}, {
-			"MSISDN",
-			ie.NewMSISDN("819012345678"),
-			[]byte{
-				// Type, Length
-				0x86, 0x00, 0x07,
-				// Value
-				0x91, 0x18, 0x09, 0x21, 0x43, 0x65, 0x87,
-			},
-		}, {
-			"ChargingGatewayAddress/v4",
-			ie.NewChargingGatewayAddress("1.1.1.1"),
-			[]byte{
-				// Type, Length
-				0xfb, 0x00, 0x04,
-				// Value
-				0x01, 0x01, 0x01, 0x01,
-			},
-		}, {
-			"ChargingGatewayAddress/v6",
-			ie.NewChargingGatewayAddress("2001::1"),
-			[]byte{
-				// Type, Length
-				0xfb, 0x00, 0x10,
-				// Value
-				0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
-			},
-		}, {
-			"PrivateExtension",
-			ie.NewPrivateExtension(0x0080, []byte{0xde, 0xad, 0xbe, 0xef}),
-			[]byte{
-				// Type, Length
-				0xff, 0x00, 0x06,
-				// Value
-				0x00, 0x80, 0xde, 0xad, 0xbe, 0xef,
-			},
+	}, {
+		"EndUserAddress/ByIP",
+		ie.NewEndUserAddressByIP(net.ParseIP("1.1.1.1")),
+		[]byte{
+			// Type, Length
+			0x80, 0x00, 0x06,
+			// Value
+			0xf1, 0x21, 0x01, 0x01, 0x01, 0x01,
 		},
-	}
+	}, {
+		"MMContext",
+		ie.NewMMContextGSMKeyAndTriplets(
+			1, 2, []byte{0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03},
+			[]byte{0x09, 0x10}, []byte{0xe5, 0xe0},
+		),
+		[]byte{
+			// Type, Length
+			0x81, 0x00, 0x0f,
+			// CKSN, Triplets, Ciphering Algorithm
+			0xf9, 0xc2,
+			// Kc
+			0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03,
+			// DRX Parameter
+			0x09, 0x10,
+			// MS Network Capability
+			0x02, 0xe5, 0xe0,
+		},
+	}, {
+		"PDPContext",
+		ie.NewPDPContext(&ie.PDPContextFields{
+			Flags:                     0x80,
+			NSAPI:                     5,
+			SAPI:                      3,
+			QoSSubscribed:             []byte{0x09, 0x11, 0x01},
+			QoSRequested:              []byte{0x09, 0x11, 0x01},
+			QoSNegotiated:             []byte{0x09, 0x11, 0x01},
+			SequenceNumberDown:        1,
+			SequenceNumberUp:          2,
+			SendNPDUNumber:            3,
+			ReceiveNPDUNumber:         4,
+			UplinkFlowLabelSignalling: 0x1111,
+			PDPContextIdentifier:      1,
+			PDPTypeOrganization:       1,
+			PDPTypeNumber:             0x21,
+			PDPAddress:                net.ParseIP("1.1.1.1"),
+			GGSNAddress:               net.ParseIP("2.2.2.2"),
+			APN:                       "apn",
+		}),
+		[]byte{
+			// Type, Length
+			0x82, 0x00, 0x25,
+			// Flags, NSAPI, SAPI
+			0xb5, 0xf3,
+			// QoS Subscribed, Requested, Negotiated
+			0x09, 0x11, 0x01, 0x09, 0x11, 0x01, 0x09, 0x11, 0x01,
+			// SND, SNU
+			0x00, 0x01, 0x00, 0x02,
+			// Send/Receive N-PDU Number
+			0x03, 0x04,
+			// Uplink Flow Label Signalling
+			0x11, 0x11,
+			// PDP Context Identifier, PDP Type Organization, PDP Type Number
+			0x01, 0xf1, 0x21,
+			// PDP Address
+			0x04, 0x01, 0x01, 0x01, 0x01,
+			// GGSN Address
+			0x04, 0x02, 0x02, 0x02, 0x02,
+			// APN
+			0x04, 0x03, 0x61, 0x70, 0x6e,
+		},
+	}, {
+		"AccessPointName",
+		ie.NewAccessPointName("some.apn.example"),
+		[]byte{
+			// Type, Length
+			0x83, 0x00, 0x11,
+			// Value
+			0x04, 0x73, 0x6f, 0x6d, 0x65, 0x03, 0x61, 0x70, 0x6e, 0x07, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65,
+		},
+	},
+	/* XXX - not implemented
+	{
+		"PCO",
+		ie.NewProtocolConfigurationOption(),
+		[]byte{},
+	}, */
+	{
+		"GSNAddress/v4",
+		ie.NewGSNAddress("1.1.1.1"),
+		[]byte{
+			// Type, Length
+			0x85, 0x00, 0x04,
+			// Value
+			0x01, 0x01, 0x01, 0x01,
+		},
+	}, {
+		"GSNAddress/v6",
+		ie.NewGSNAddress("2001::1"),
+		[]byte{
+			// Type, Length
+			0x85, 0x00, 0x10,
+			// Value
+			0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+		},
+	}, {
+		"MSISDN",
+		ie.NewMSISDN("819012345678"),
+		[]byte{
+			// Type, Length
+			0x86, 0x00, 0x07,
+			// Value
+			0x91, 0x18, 0x09, 0x21, 0x43, 0x65, 0x87,
+		},
+	}, {
+		"ChargingGatewayAddress/v4",
+		ie.NewChargingGatewayAddress("1.1.1.1"),
+		[]byte{
+			// Type, Length
+			0xfb, 0x00, 0x04,
+			// Value
+			0x01, 0x01, 0x01, 0x01,
+		},
+	}, {
+		"ChargingGatewayAddress/v6",
+		ie.NewChargingGatewayAddress("2001::1"),
+		[]byte{
+			// Type, Length
+			0xfb, 0x00, 0x10,
+			// Value
+			0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+		},
+	}, {
+		"PrivateExtension",
+		ie.NewPrivateExtension(0x0080, []byte{0xde, 0xad, 0xbe, 0xef}),
+		[]byte{
+			// Type, Length
+			0xff, 0x00, 0x06,
+			// Value
+			0x00, 0x80, 0xde, 0xad, 0xbe, 0xef,
+		},
+	},
+}
 
-	for _, c := range cases {
+func TestIE(t *testing.T) {
+	for _, c := range testIEs {
 		t.Run("Marshal/"+c.description, func(t *testing.T) {
 			got, err := c.structured.Marshal()
 			if err != nil {
@@ -242,3 +298,78 @@ func TestIE(t *testing.T) {
 		})
 	}
 }
+
+func TestQualityOfServiceProfile(t *testing.T) {
+	i := ie.NewQualityOfServiceProfile(4, 3, 9, 2, 31)
+	for _, c := range []struct {
+		description string
+		got, want   uint8
+	}{
+		{"Delay", i.MustQoSDelay(), 4},
+		{"Reliability", i.MustQoSReliability(), 3},
+		{"Peak", i.MustQoSPeak(), 9},
+		{"Precedence", i.MustQoSPrecedence(), 2},
+		{"Mean", i.MustQoSMean(), 31},
+	} {
+		if c.got != c.want {
+			t.Errorf("wrong %s: got %d, want %d", c.description, c.got, c.want)
+		}
+	}
+}
+
+func TestContextFields(t *testing.T) {
+	t.Run("MMContext", func(t *testing.T) {
+		f := &ie.MMContextFields{
+			CKSN:                2,
+			CipheringAlgorithm:  1,
+			Kc:                  []byte{0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03},
+			DRXParameter:        []byte{0x09, 0x10},
+			MSNetworkCapability: []byte{0xe5, 0xe0},
+			Triplets: []*ie.IE{
+				ie.NewAuthenticationTriplet(make([]byte, 16), []byte{0xde, 0xad, 0xbe, 0xef}, make([]byte, 8)),
+				ie.NewAuthenticationTriplet(make([]byte, 16), []byte{0xbe, 0xef, 0xde, 0xad}, make([]byte, 8)),
+			},
+		}
+
+		got, err := ie.NewMMContext(f).MMContext()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(got, f); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("PDPContext", func(t *testing.T) {
+		f := &ie.PDPContextFields{
+			Flags:                     0x40,
+			NSAPI:                     5,
+			QoSSubscribed:             []byte{0x09, 0x11, 0x01},
+			QoSRequested:              []byte{0x09, 0x11, 0x01},
+			QoSNegotiated:             []byte{0x09, 0x11, 0x01},
+			SequenceNumberDown:        1,
+			SequenceNumberUp:          2,
+			UplinkFlowLabelSignalling: 0x2222,
+			PDPTypeOrganization:       1,
+			PDPTypeNumber:             0x57,
+			PDPAddress:                net.ParseIP("2001::1"),
+			GGSNAddress:               net.ParseIP("1.1.1.1").To4(),
+			APN:                       "some.apn.example",
+		}
+
+		got, err := ie.NewPDPContext(f).PDPContext()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(got, f); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
+
+func TestAccessPointName(t *testing.T) {
+	apn := "some.apn.example"
+	if got := ie.NewAccessPointName(apn).MustAccessPointName(); got != apn {
+		t.Errorf("wrong APN: got %s, want %s", got, apn)
+	}
+}
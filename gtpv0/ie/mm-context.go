@@ -0,0 +1,143 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+import "io"
+
+// NewMMContextGSMKeyAndTriplets creates a new MMContext IE.
+//
+// triplets should be the AuthenticationTriplet IEs.
+func NewMMContextGSMKeyAndTriplets(cksn, cipher uint8, kc, drxParam, msNetworkCapability []byte, triplets ...*IE) *IE {
+	return NewMMContext(&MMContextFields{
+		CKSN:                cksn,
+		CipheringAlgorithm:  cipher,
+		Kc:                  kc,
+		Triplets:            triplets,
+		DRXParameter:        drxParam,
+		MSNetworkCapability: msNetworkCapability,
+	})
+}
+
+// NewMMContext creates a new MMContext IE from MMContextFields.
+func NewMMContext(f *MMContextFields) *IE {
+	b, err := f.Marshal()
+	if err != nil {
+		return nil
+	}
+	return New(MMContext, b)
+}
+
+// MMContext returns MMContext in MMContextFields type if the type of IE matches.
+func (i *IE) MMContext() (*MMContextFields, error) {
+	if i.Type != MMContext {
+		return nil, &InvalidTypeError{Type: i.Type}
+	}
+	return ParseMMContextFields(i.Payload)
+}
+
+// MustMMContext returns MMContext in MMContextFields type if the type of IE matches.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustMMContext() *MMContextFields {
+	v, _ := i.MMContext()
+	return v
+}
+
+// MMContextFields is a set of fields in MMContext IE.
+//
+// Unlike GTPv1, GTPv0 MMContext always has GSM Key and Triplets.
+type MMContextFields struct {
+	CKSN                uint8
+	CipheringAlgorithm  uint8
+	Kc                  []byte
+	Triplets            []*IE
+	DRXParameter        []byte
+	MSNetworkCapability []byte
+}
+
+// Marshal serializes MMContextFields.
+func (f *MMContextFields) Marshal() ([]byte, error) {
+	b := make([]byte, f.MarshalLen())
+	if err := f.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalTo serializes MMContextFields.
+func (f *MMContextFields) MarshalTo(b []byte) error {
+	if len(b) < f.MarshalLen() {
+		return io.ErrUnexpectedEOF
+	}
+	if len(f.Triplets) > 7 {
+		return ErrInvalidLength
+	}
+
+	b[0] = 0xf8 | (f.CKSN & 0x07)
+	b[1] = 0xc0 | uint8(len(f.Triplets))<<3 | (f.CipheringAlgorithm & 0x07)
+	copy(b[2:10], f.Kc)
+	offset := 10
+
+	for _, t := range f.Triplets {
+		copy(b[offset:offset+28], t.Payload)
+		offset += 28
+	}
+
+	copy(b[offset:offset+2], f.DRXParameter)
+	offset += 2
+
+	b[offset] = uint8(len(f.MSNetworkCapability))
+	copy(b[offset+1:], f.MSNetworkCapability)
+	return nil
+}
+
+// ParseMMContextFields decodes MMContextFields.
+func ParseMMContextFields(b []byte) (*MMContextFields, error) {
+	f := &MMContextFields{}
+	if err := f.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// UnmarshalBinary decodes given bytes into MMContextFields.
+func (f *MMContextFields) UnmarshalBinary(b []byte) error {
+	l := len(b)
+	if l < 10 {
+		return io.ErrUnexpectedEOF
+	}
+
+	f.CKSN = b[0] & 0x07
+	n := int(b[1]>>3) & 0x07
+	f.CipheringAlgorithm = b[1] & 0x07
+	f.Kc = b[2:10]
+	offset := 10
+
+	if l < offset+28*n {
+		return io.ErrUnexpectedEOF
+	}
+	for j := 0; j < n; j++ {
+		f.Triplets = append(f.Triplets, New(AuthenticationTriplet, b[offset:offset+28]))
+		offset += 28
+	}
+
+	if l < offset+3 {
+		return io.ErrUnexpectedEOF
+	}
+	f.DRXParameter = b[offset : offset+2]
+	offset += 2
+
+	mlen := int(b[offset])
+	offset++
+	if l < offset+mlen {
+		return io.ErrUnexpectedEOF
+	}
+	f.MSNetworkCapability = b[offset : offset+mlen]
+	return nil
+}
+
+// MarshalLen returns the serial length of MMContextFields.
+func (f *MMContextFields) MarshalLen() int {
+	return 2 + 8 + 28*len(f.Triplets) + 2 + 1 + len(f.MSNetworkCapability)
+}
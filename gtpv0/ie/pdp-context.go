@@ -0,0 +1,208 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+)
+
+// NewPDPContext creates a new PDPContext IE from PDPContextFields.
+func NewPDPContext(f *PDPContextFields) *IE {
+	b, err := f.Marshal()
+	if err != nil {
+		return nil
+	}
+	return New(PDPContext, b)
+}
+
+// PDPContext returns PDPContext in PDPContextFields type if the type of IE matches.
+func (i *IE) PDPContext() (*PDPContextFields, error) {
+	if i.Type != PDPContext {
+		return nil, &InvalidTypeError{Type: i.Type}
+	}
+	return ParsePDPContextFields(i.Payload)
+}
+
+// MustPDPContext returns PDPContext in PDPContextFields type if the type of IE matches.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustPDPContext() *PDPContextFields {
+	v, _ := i.PDPContext()
+	return v
+}
+
+// PDPContextFields is a set of fields in PDPContext IE.
+//
+// Flags has Reordering Required and VPLMN Address Allowed in the 8-7th bits in the
+// same octet as NSAPI. QoS profiles are the value of QualityOfServiceProfile IE, which
+// is always 3 octets in GTPv0. PDPAddress is nil if no address is assigned, e.g., for PPP.
+type PDPContextFields struct {
+	Flags                     uint8
+	NSAPI                     uint8
+	SAPI                      uint8
+	QoSSubscribed             []byte
+	QoSRequested              []byte
+	QoSNegotiated             []byte
+	SequenceNumberDown        uint16
+	SequenceNumberUp          uint16
+	SendNPDUNumber            uint8
+	ReceiveNPDUNumber         uint8
+	UplinkFlowLabelSignalling uint16
+	PDPContextIdentifier      uint8
+	PDPTypeOrganization       uint8
+	PDPTypeNumber             uint8
+	PDPAddress                net.IP
+	GGSNAddress               net.IP
+	APN                       string
+}
+
+// Marshal serializes PDPContextFields.
+func (f *PDPContextFields) Marshal() ([]byte, error) {
+	b := make([]byte, f.MarshalLen())
+	if err := f.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalTo serializes PDPContextFields.
+func (f *PDPContextFields) MarshalTo(b []byte) error {
+	if len(b) < f.MarshalLen() {
+		return io.ErrUnexpectedEOF
+	}
+
+	b[0] = (f.Flags & 0xc0) | 0x30 | (f.NSAPI & 0x0f)
+	b[1] = 0xf0 | (f.SAPI & 0x0f)
+	copy(b[2:5], f.QoSSubscribed)
+	copy(b[5:8], f.QoSRequested)
+	copy(b[8:11], f.QoSNegotiated)
+	binary.BigEndian.PutUint16(b[11:13], f.SequenceNumberDown)
+	binary.BigEndian.PutUint16(b[13:15], f.SequenceNumberUp)
+	b[15] = f.SendNPDUNumber
+	b[16] = f.ReceiveNPDUNumber
+	binary.BigEndian.PutUint16(b[17:19], f.UplinkFlowLabelSignalling)
+	b[19] = f.PDPContextIdentifier
+	b[20] = 0xf0 | (f.PDPTypeOrganization & 0x0f)
+	b[21] = f.PDPTypeNumber
+	offset := 22
+
+	for _, ip := range []net.IP{f.PDPAddress, f.GGSNAddress} {
+		v := ipBytes(ip)
+		b[offset] = uint8(len(v))
+		copy(b[offset+1:], v)
+		offset += 1 + len(v)
+	}
+
+	apn := encodeAPN(f.APN)
+	b[offset] = uint8(len(apn))
+	copy(b[offset+1:], apn)
+	return nil
+}
+
+// ParsePDPContextFields decodes PDPContextFields.
+func ParsePDPContextFields(b []byte) (*PDPContextFields, error) {
+	f := &PDPContextFields{}
+	if err := f.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// UnmarshalBinary decodes given bytes into PDPContextFields.
+func (f *PDPContextFields) UnmarshalBinary(b []byte) error {
+	if len(b) < 22 {
+		return io.ErrUnexpectedEOF
+	}
+
+	f.Flags = b[0] & 0xc0
+	f.NSAPI = b[0] & 0x0f
+	f.SAPI = b[1] & 0x0f
+	f.QoSSubscribed = b[2:5]
+	f.QoSRequested = b[5:8]
+	f.QoSNegotiated = b[8:11]
+	f.SequenceNumberDown = binary.BigEndian.Uint16(b[11:13])
+	f.SequenceNumberUp = binary.BigEndian.Uint16(b[13:15])
+	f.SendNPDUNumber = b[15]
+	f.ReceiveNPDUNumber = b[16]
+	f.UplinkFlowLabelSignalling = binary.BigEndian.Uint16(b[17:19])
+	f.PDPContextIdentifier = b[19]
+	f.PDPTypeOrganization = b[20] & 0x0f
+	f.PDPTypeNumber = b[21]
+	offset := 22
+
+	for _, ip := range []*net.IP{&f.PDPAddress, &f.GGSNAddress} {
+		v, n, err := lengthPrefixed(b[offset:])
+		if err != nil {
+			return err
+		}
+		if v != nil {
+			*ip = net.IP(v)
+		}
+		offset += n
+	}
+
+	apn, _, err := lengthPrefixed(b[offset:])
+	if err != nil {
+		return err
+	}
+	f.APN = decodeAPN(apn)
+	return nil
+}
+
+// MarshalLen returns the serial length of PDPContextFields.
+func (f *PDPContextFields) MarshalLen() int {
+	l := 22
+	for _, ip := range []net.IP{f.PDPAddress, f.GGSNAddress} {
+		l += 1 + len(ipBytes(ip))
+	}
+	return l + 1 + len(encodeAPN(f.APN))
+}
+
+// ipBytes returns IPv4 address in 4 octets, or IPv6 address in 16 octets.
+func ipBytes(ip net.IP) []byte {
+	if ip == nil {
+		return nil
+	}
+	if v := ip.To4(); v != nil {
+		return v
+	}
+	return ip.To16()
+}
+
+// lengthPrefixed returns the value that has the length in the first octet, and
+// the number of octets consumed.
+func lengthPrefixed(b []byte) ([]byte, int, error) {
+	if len(b) < 1 {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	l := int(b[0])
+	if len(b) < 1+l {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	if l == 0 {
+		return nil, 1, nil
+	}
+	return b[1 : 1+l], 1 + l, nil
+}
+
+func encodeAPN(apn string) []byte {
+	if apn == "" {
+		return nil
+	}
+	return NewAccessPointName(apn).Payload
+}
+
+func decodeAPN(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	apn, err := New(AccessPointName, b).AccessPointName()
+	if err != nil {
+		return strings.TrimSpace(string(b))
+	}
+	return apn
+}
@@ -40,7 +40,7 @@ func (i *IE) QoSDelay() (uint8, error) {
 		return 0, io.ErrUnexpectedEOF
 	}
 
-	return i.Payload[0] & 0x38, nil
+	return (i.Payload[0] >> 3) & 0x07, nil
 }
 
 // MustQoSDelay returns QoSDelay in uint8 if type matches.
@@ -78,7 +78,7 @@ func (i *IE) QoSPeak() (uint8, error) {
 		return 0, io.ErrUnexpectedEOF
 	}
 
-	return i.Payload[1] & 0xf0, nil
+	return i.Payload[1] >> 4, nil
 }
 
 // MustQoSPeak returns QoSPeak in uint8 if type matches.
@@ -116,7 +116,7 @@ func (i *IE) QoSMean() (uint8, error) {
 		return 0, io.ErrUnexpectedEOF
 	}
 
-	return i.Payload[2] & 0x0f, nil
+	return i.Payload[2] & 0x1f, nil
 }
 
 // MustQoSMean returns QoSMean in uint8 if type matches.
@@ -87,12 +87,18 @@ func (c *CreatePDPContextResponse) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from CreatePDPContextResponse to b and returns the extended buffer.
+func (c *CreatePDPContextResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, c)
+}
+
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (c *CreatePDPContextResponse) MarshalTo(b []byte) error {
-	if c.Header.Payload != nil {
-		c.Header.Payload = nil
+	var err error
+	c.Header.Payload, err = c.Header.payloadIn(b, c.MarshalLen())
+	if err != nil {
+		return err
 	}
-	c.Header.Payload = make([]byte, c.MarshalLen()-c.Header.MarshalLen())
 
 	offset := 0
 	if ie := c.Cause; ie != nil {
@@ -50,12 +50,18 @@ func (d *DeletePDPContextRequest) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from DeletePDPContextRequest to b and returns the extended buffer.
+func (d *DeletePDPContextRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, d)
+}
+
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (d *DeletePDPContextRequest) MarshalTo(b []byte) error {
-	if d.Header.Payload != nil {
-		d.Header.Payload = nil
+	var err error
+	d.Header.Payload, err = d.Header.payloadIn(b, d.MarshalLen())
+	if err != nil {
+		return err
 	}
-	d.Header.Payload = make([]byte, d.MarshalLen()-d.Header.MarshalLen())
 
 	offset := 0
 	if ie := d.PrivateExtension; ie != nil {
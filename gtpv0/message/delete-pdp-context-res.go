@@ -52,11 +52,20 @@ func (d *DeletePDPContextResponse) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from DeletePDPContextResponse to b and returns the extended buffer.
+func (d *DeletePDPContextResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, d)
+}
+
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (d *DeletePDPContextResponse) MarshalTo(b []byte) error {
 	// XXX - add validation!
 
-	d.Header.Payload = make([]byte, d.MarshalLen()-d.Header.MarshalLen())
+	var err error
+	d.Header.Payload, err = d.Header.payloadIn(b, d.MarshalLen())
+	if err != nil {
+		return err
+	}
 
 	offset := 0
 	if ie := d.Cause; ie != nil {
@@ -0,0 +1,69 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package message_test
+
+import (
+	"testing"
+
+	v0 "github.com/wmnsk/go-gtp/gtpv0"
+	"github.com/wmnsk/go-gtp/gtpv0/ie"
+	"github.com/wmnsk/go-gtp/gtpv0/message"
+	"github.com/wmnsk/go-gtp/gtpv0/testutils"
+)
+
+// fuzzIEs is the set of IEs put in every message used as a seed so that
+// the per-message IE dispatching gets exercised for all the known types.
+var fuzzIEs = []*ie.IE{
+	ie.NewCause(v0.CauseRequestAccepted),
+	ie.NewIMSI("123450123456789"),
+	ie.NewRouteingAreaIdentity("123", "45", 0x1111, 0x22),
+	ie.NewQualityOfServiceProfile(1, 1, 1, 1, 1),
+	ie.NewRecovery(254),
+	ie.NewSelectionMode(v0.SelectionModeMSorNetworkProvidedAPNSubscribedVerified),
+	ie.NewFlowLabelDataI(11),
+	ie.NewFlowLabelSignalling(22),
+	ie.NewChargingID(0xdeadbeef),
+	ie.NewEndUserAddress("1.1.1.1"),
+	ie.NewAccessPointName("some.apn.example"),
+	ie.NewGSNAddress("2.2.2.2"),
+	ie.NewGSNAddress("3.3.3.3"),
+	ie.NewMSISDN("819012345678"),
+}
+
+func FuzzParse(f *testing.F) {
+	for typ := 1; typ <= 0xff; typ++ {
+		b, err := message.Marshal(message.NewGeneric(
+			uint8(typ), testutils.TestFlow.Seq, testutils.TestFlow.Label, testutils.TestFlow.TID, fuzzIEs...,
+		))
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		// Seed only the types that have their own implementation.
+		m, err := message.Parse(b)
+		if err != nil {
+			continue
+		}
+		if _, ok := m.(*message.Generic); ok {
+			continue
+		}
+		f.Add(b)
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		m, err := message.Parse(b)
+		if err != nil {
+			return
+		}
+
+		if _, err := message.Marshal(m); err != nil {
+			t.Fatalf("failed to marshal parsed message: %v", err)
+		}
+		_ = m.String()
+	})
+}
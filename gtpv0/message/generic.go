@@ -43,12 +43,18 @@ func (g *Generic) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from Generic to b and returns the extended buffer.
+func (g *Generic) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, g)
+}
+
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (g *Generic) MarshalTo(b []byte) error {
-	if g.Header.Payload != nil {
-		g.Header.Payload = nil
+	var err error
+	g.Header.Payload, err = g.Header.payloadIn(b, g.MarshalLen())
+	if err != nil {
+		return err
 	}
-	g.Header.Payload = make([]byte, g.MarshalLen()-g.Header.MarshalLen())
 
 	offset := 0
 	for _, ie := range g.IEs {
@@ -73,6 +73,17 @@ func (h *Header) MarshalTo(b []byte) error {
 	return nil
 }
 
+// payloadIn returns the part of b that the payload of a message of length l
+// occupies, so that the IEs are marshaled directly into b without allocating
+// an intermediate buffer. The Payload of the Header refers to b after that.
+func (h *Header) payloadIn(b []byte, l int) ([]byte, error) {
+	offset := h.MarshalLen() - len(h.Payload)
+	if len(b) < l || l < offset {
+		return nil, ErrTooShortToMarshal
+	}
+	return b[offset:l], nil
+}
+
 // ParseHeader Parses given byte sequence as a GTPv1 header.
 func ParseHeader(b []byte) (*Header, error) {
 	h := &Header{}
@@ -85,7 +96,7 @@ func ParseHeader(b []byte) (*Header, error) {
 // UnmarshalBinary sets the values retrieved from byte sequence in GTPv1 header.
 func (h *Header) UnmarshalBinary(b []byte) error {
 	l := len(b)
-	if l < 11 {
+	if l < 20 {
 		return ErrTooShortToParse
 	}
 	h.Flags = b[0]
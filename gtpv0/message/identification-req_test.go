@@ -0,0 +1,52 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	"github.com/wmnsk/go-gtp/gtpv0/ie"
+	"github.com/wmnsk/go-gtp/gtpv0/message"
+	"github.com/wmnsk/go-gtp/gtpv0/testutils"
+)
+
+func TestIdentificationRequest(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "normal",
+			Structured: message.NewIdentificationRequest(
+				testutils.TestFlow.Seq, testutils.TestFlow.Label, testutils.TestFlow.TID,
+				ie.NewRouteingAreaIdentity("123", "45", 0x1111, 0x22),
+				ie.NewPacketTMSI(0xdeadbeef),
+				ie.NewPTMSISignature(0xbeef),
+			),
+			Serialized: []byte{
+				// Header
+				0x1e, 0x30, 0x00, 0x10,
+				// SequenceNumber
+				0x00, 0x01, 0x00, 0x00,
+				// Sndpd
+				0xff, 0xff, 0xff, 0xff,
+				// TID
+				0x21, 0x43, 0x65, 0x87, 0x09, 0x21, 0x43, 0x55,
+				// RouteingAreaIdentity
+				0x03, 0x21, 0xf3, 0x54, 0x11, 0x11, 0x22,
+				// PacketTMSI
+				0x05, 0xde, 0xad, 0xbe, 0xef,
+				// PTMSISignature
+				0x0c, 0x00, 0xbe, 0xef,
+			},
+		},
+	}
+
+	testutils.Run(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseIdentificationRequest(b)
+		if err != nil {
+			return nil, err
+		}
+		v.Payload = nil
+		return v, nil
+	})
+}
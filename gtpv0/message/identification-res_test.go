@@ -0,0 +1,63 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	v0 "github.com/wmnsk/go-gtp/gtpv0"
+	"github.com/wmnsk/go-gtp/gtpv0/ie"
+	"github.com/wmnsk/go-gtp/gtpv0/message"
+	"github.com/wmnsk/go-gtp/gtpv0/testutils"
+)
+
+func TestIdentificationResponse(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "normal",
+			Structured: message.NewIdentificationResponse(
+				testutils.TestFlow.Seq, testutils.TestFlow.Label, testutils.TestFlow.TID,
+				ie.NewCause(v0.CauseRequestAccepted),
+				ie.NewIMSI("123451234567890"),
+				ie.New(ie.AuthenticationTriplet, []byte{
+					// RAND
+					0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+					// SRES
+					0x02, 0x02, 0x02, 0x02,
+					// Kc
+					0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03,
+				}),
+			),
+			Serialized: []byte{
+				// Header
+				0x1e, 0x31, 0x00, 0x28,
+				// SequenceNumber
+				0x00, 0x01, 0x00, 0x00,
+				// Sndpd
+				0xff, 0xff, 0xff, 0xff,
+				// TID
+				0x21, 0x43, 0x65, 0x87, 0x09, 0x21, 0x43, 0x55,
+				// Cause
+				0x01, 0x80,
+				// IMSI
+				0x02, 0x21, 0x43, 0x15, 0x32, 0x54, 0x76, 0x98, 0xf0,
+				// AuthenticationTriplet
+				0x09,
+				0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+				0x02, 0x02, 0x02, 0x02,
+				0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03,
+			},
+		},
+	}
+
+	testutils.Run(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseIdentificationResponse(b)
+		if err != nil {
+			return nil, err
+		}
+		v.Payload = nil
+		return v, nil
+	})
+}
@@ -9,6 +9,7 @@ package message
 
 import (
 	"github.com/pkg/errors"
+	"github.com/wmnsk/go-gtp/utils"
 )
 
 // MessageType definitions.
@@ -98,8 +99,25 @@ func Marshal(g Message) ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from a Message instance to b
+// and returns the extended buffer. b is reallocated only when its capacity is
+// not enough, so the buffers can be reused without allocating.
+func AppendTo(b []byte, m Message) ([]byte, error) {
+	l := len(b)
+	b = utils.Grow(b, m.MarshalLen())
+	if err := m.MarshalTo(b[l:]); err != nil {
+		return b[:l], err
+	}
+
+	return b, nil
+}
+
 // Parse Parses the given bytes as Message.
 func Parse(b []byte) (Message, error) {
+	if len(b) < 2 {
+		return nil, ErrTooShortToParse
+	}
+
 	var g Message
 
 	switch b[1] {
@@ -107,9 +125,9 @@ func Parse(b []byte) (Message, error) {
 		g = &EchoRequest{}
 	case MsgTypeEchoResponse:
 		g = &EchoResponse{}
-	/* XXX - Implement!
 	case MsgTypeVersionNotSupported:
-		g = &VerNotSupported{}
+		g = &VersionNotSupported{}
+	/* XXX - Implement!
 	case MsgTypeNodeAliveRequest:
 		g = &NodeAliveReq{}
 	case MsgTypeNodeAliveResponse:
@@ -140,12 +158,14 @@ func Parse(b []byte) (Message, error) {
 		g = &DeleteAAPDPContextReq{}
 	case MsgTypeDeleteAAPDPContextResponse:
 		g = &DeleteAAPDPContextRes{}
+	*/
 	case MsgTypeErrorIndication:
-		g = &ErrorInd{}
+		g = &ErrorIndication{}
 	case MsgTypePDUNotificationRequest:
-		g = &PDUNotificationReq{}
+		g = &PDUNotificationRequest{}
 	case MsgTypePDUNotificationResponse:
-		g = &PDUNotificationRes{}
+		g = &PDUNotificationResponse{}
+	/* XXX - Implement!
 	case MsgTypePDUNotificationRejectRequest:
 		g = &PDUNotificationRejectReq{}
 	case MsgTypePDUNotificationRejectResponse:
@@ -162,16 +182,18 @@ func Parse(b []byte) (Message, error) {
 		g = &NoteMSGPRSPresentReq{}
 	case MsgTypeNoteMSGPRSPresentResponse:
 		g = &NoteMSGPRSPresentRes{}
+	*/
 	case MsgTypeIdentificationRequest:
-		g = &IdentificationReq{}
+		g = &IdentificationRequest{}
 	case MsgTypeIdentificationResponse:
-		g = &IdentificationRes{}
+		g = &IdentificationResponse{}
 	case MsgTypeSGSNContextRequest:
-		g = &SGSNContextReq{}
+		g = &SGSNContextRequest{}
 	case MsgTypeSGSNContextResponse:
-		g = &SGSNContextRes{}
+		g = &SGSNContextResponse{}
 	case MsgTypeSGSNContextAcknowledge:
-		g = &SGSNContextAck{}
+		g = &SGSNContextAcknowledge{}
+	/* XXX - Implement!
 	case MsgTypeDataRecordTransferRequest:
 		g = &DataRecordTransferReq{}
 	case MsgTypeDataRecordTransferResponse:
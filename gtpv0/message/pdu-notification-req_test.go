@@ -0,0 +1,54 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	"github.com/wmnsk/go-gtp/gtpv0/ie"
+	"github.com/wmnsk/go-gtp/gtpv0/message"
+	"github.com/wmnsk/go-gtp/gtpv0/testutils"
+)
+
+func TestPDUNotificationRequest(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "normal",
+			Structured: message.NewPDUNotificationRequest(
+				testutils.TestFlow.Seq, testutils.TestFlow.Label, testutils.TestFlow.TID,
+				ie.NewEndUserAddress("1.1.1.1"),
+				ie.NewAccessPointName("some.apn.example"),
+				ie.NewGSNAddress("2.2.2.2"),
+			),
+			Serialized: []byte{
+				// Header
+				0x1e, 0x1b, 0x00, 0x24,
+				// SequenceNumber
+				0x00, 0x01, 0x00, 0x00,
+				// Sndpd
+				0xff, 0xff, 0xff, 0xff,
+				// TID
+				0x21, 0x43, 0x65, 0x87, 0x09, 0x21, 0x43, 0x55,
+				// EndUserAddress
+				0x80, 0x00, 0x06, 0xf1, 0x21, 0x01, 0x01, 0x01, 0x01,
+				// AccessPointName
+				0x83, 0x00, 0x11, 0x04, 0x73, 0x6f, 0x6d, 0x65,
+				0x03, 0x61, 0x70, 0x6e, 0x07, 0x65, 0x78, 0x61,
+				0x6d, 0x70, 0x6c, 0x65,
+				// GGSNAddressForSignalling
+				0x85, 0x00, 0x04, 0x02, 0x02, 0x02, 0x02,
+			},
+		},
+	}
+
+	testutils.Run(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParsePDUNotificationRequest(b)
+		if err != nil {
+			return nil, err
+		}
+		v.Payload = nil
+		return v, nil
+	})
+}
@@ -0,0 +1,171 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpv0/ie"
+)
+
+// PDUNotificationResponse is a PDUNotificationResponse Header and its IEs above.
+type PDUNotificationResponse struct {
+	*Header
+	Cause            *ie.IE
+	PrivateExtension *ie.IE
+	AdditionalIEs    []*ie.IE
+}
+
+// NewPDUNotificationResponse creates a new PDUNotificationResponse.
+func NewPDUNotificationResponse(seq, label uint16, tid uint64, IEs ...*ie.IE) *PDUNotificationResponse {
+	r := &PDUNotificationResponse{
+		Header: NewHeader(
+			0x1e, MsgTypePDUNotificationResponse, seq, label, tid, nil,
+		),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			r.Cause = i
+		case ie.PrivateExtension:
+			r.PrivateExtension = i
+		default:
+			r.AdditionalIEs = append(r.AdditionalIEs, i)
+		}
+	}
+
+	r.SetLength()
+	return r
+}
+
+// Marshal returns the byte sequence generated from a PDUNotificationResponse.
+func (r *PDUNotificationResponse) Marshal() ([]byte, error) {
+	b := make([]byte, r.MarshalLen())
+	if err := r.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// AppendTo appends the byte sequence generated from PDUNotificationResponse to b and returns the extended buffer.
+func (r *PDUNotificationResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, r)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (r *PDUNotificationResponse) MarshalTo(b []byte) error {
+	var err error
+	r.Header.Payload, err = r.Header.payloadIn(b, r.MarshalLen())
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if ie := r.Cause; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := r.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range r.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	r.Header.SetLength()
+	return r.Header.MarshalTo(b)
+}
+
+// ParsePDUNotificationResponse parses a given byte sequence as a PDUNotificationResponse.
+func ParsePDUNotificationResponse(b []byte) (*PDUNotificationResponse, error) {
+	r := &PDUNotificationResponse{}
+	if err := r.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// UnmarshalBinary parses a given byte sequence as a PDUNotificationResponse.
+func (r *PDUNotificationResponse) UnmarshalBinary(b []byte) error {
+	var err error
+	r.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(r.Header.Payload) < 2 {
+		return nil
+	}
+
+	IEs, err := ie.ParseMultiIEs(r.Header.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			r.Cause = i
+		case ie.PrivateExtension:
+			r.PrivateExtension = i
+		default:
+			r.AdditionalIEs = append(r.AdditionalIEs, i)
+		}
+	}
+
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (r *PDUNotificationResponse) MarshalLen() int {
+	l := r.Header.MarshalLen() - len(r.Header.Payload)
+
+	if ie := r.Cause; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := r.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range r.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (r *PDUNotificationResponse) SetLength() {
+	r.Header.Length = uint16(r.MarshalLen() - 20)
+}
+
+// MessageTypeName returns the name of protocol.
+func (r *PDUNotificationResponse) MessageTypeName() string {
+	return "PDU Notification Response"
+}
+
+// TID returns the TID in human-readable string.
+func (r *PDUNotificationResponse) TID() string {
+	return r.tid()
+}
@@ -0,0 +1,56 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	v0 "github.com/wmnsk/go-gtp/gtpv0"
+	"github.com/wmnsk/go-gtp/gtpv0/ie"
+	"github.com/wmnsk/go-gtp/gtpv0/message"
+	"github.com/wmnsk/go-gtp/gtpv0/testutils"
+)
+
+func TestSGSNContextAcknowledge(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "normal",
+			Structured: message.NewSGSNContextAcknowledge(
+				testutils.TestFlow.Seq, testutils.TestFlow.Label, testutils.TestFlow.TID,
+				ie.NewCause(v0.CauseRequestAccepted),
+				ie.NewFlowLabelDataII(5, 22),
+				ie.NewFlowLabelDataII(6, 33),
+				ie.NewGSNAddress("1.1.1.1"),
+			),
+			Serialized: []byte{
+				// Header
+				0x1e, 0x34, 0x00, 0x11,
+				// SequenceNumber
+				0x00, 0x01, 0x00, 0x00,
+				// Sndpd
+				0xff, 0xff, 0xff, 0xff,
+				// TID
+				0x21, 0x43, 0x65, 0x87, 0x09, 0x21, 0x43, 0x55,
+				// Cause
+				0x01, 0x80,
+				// FlowLabelDataII
+				0x12, 0xf5, 0x00, 0x16,
+				// FlowLabelDataII
+				0x12, 0xf6, 0x00, 0x21,
+				// SGSNAddressForUserTraffic
+				0x85, 0x00, 0x04, 0x01, 0x01, 0x01, 0x01,
+			},
+		},
+	}
+
+	testutils.Run(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseSGSNContextAcknowledge(b)
+		if err != nil {
+			return nil, err
+		}
+		v.Payload = nil
+		return v, nil
+	})
+}
@@ -0,0 +1,233 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpv0/ie"
+)
+
+// SGSNContextResponse is a SGSNContextResponse Header and its IEs above.
+type SGSNContextResponse struct {
+	*Header
+	Cause               *ie.IE
+	IMSI                *ie.IE
+	FlowLabelSignalling *ie.IE
+	MMContext           *ie.IE
+	PDPContexts         []*ie.IE
+	PrivateExtension    *ie.IE
+	AdditionalIEs       []*ie.IE
+}
+
+// NewSGSNContextResponse creates a new SGSNContextResponse.
+func NewSGSNContextResponse(seq, label uint16, tid uint64, IEs ...*ie.IE) *SGSNContextResponse {
+	s := &SGSNContextResponse{
+		Header: NewHeader(
+			0x1e, MsgTypeSGSNContextResponse, seq, label, tid, nil,
+		),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			s.Cause = i
+		case ie.IMSI:
+			s.IMSI = i
+		case ie.FlowLabelSignalling:
+			s.FlowLabelSignalling = i
+		case ie.MMContext:
+			s.MMContext = i
+		case ie.PDPContext:
+			s.PDPContexts = append(s.PDPContexts, i)
+		case ie.PrivateExtension:
+			s.PrivateExtension = i
+		default:
+			s.AdditionalIEs = append(s.AdditionalIEs, i)
+		}
+	}
+
+	s.SetLength()
+	return s
+}
+
+// Marshal returns the byte sequence generated from a SGSNContextResponse.
+func (s *SGSNContextResponse) Marshal() ([]byte, error) {
+	b := make([]byte, s.MarshalLen())
+	if err := s.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// AppendTo appends the byte sequence generated from SGSNContextResponse to b and returns the extended buffer.
+func (s *SGSNContextResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, s)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (s *SGSNContextResponse) MarshalTo(b []byte) error {
+	var err error
+	s.Header.Payload, err = s.Header.payloadIn(b, s.MarshalLen())
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if ie := s.Cause; ie != nil {
+		if err := ie.MarshalTo(s.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := s.IMSI; ie != nil {
+		if err := ie.MarshalTo(s.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := s.FlowLabelSignalling; ie != nil {
+		if err := ie.MarshalTo(s.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := s.MMContext; ie != nil {
+		if err := ie.MarshalTo(s.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	for _, ie := range s.PDPContexts {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(s.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := s.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(s.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range s.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(s.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	s.Header.SetLength()
+	return s.Header.MarshalTo(b)
+}
+
+// ParseSGSNContextResponse parses a given byte sequence as a SGSNContextResponse.
+func ParseSGSNContextResponse(b []byte) (*SGSNContextResponse, error) {
+	s := &SGSNContextResponse{}
+	if err := s.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// UnmarshalBinary parses a given byte sequence as a SGSNContextResponse.
+func (s *SGSNContextResponse) UnmarshalBinary(b []byte) error {
+	var err error
+	s.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(s.Header.Payload) < 2 {
+		return nil
+	}
+
+	IEs, err := ie.ParseMultiIEs(s.Header.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			s.Cause = i
+		case ie.IMSI:
+			s.IMSI = i
+		case ie.FlowLabelSignalling:
+			s.FlowLabelSignalling = i
+		case ie.MMContext:
+			s.MMContext = i
+		case ie.PDPContext:
+			s.PDPContexts = append(s.PDPContexts, i)
+		case ie.PrivateExtension:
+			s.PrivateExtension = i
+		default:
+			s.AdditionalIEs = append(s.AdditionalIEs, i)
+		}
+	}
+
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (s *SGSNContextResponse) MarshalLen() int {
+	l := s.Header.MarshalLen() - len(s.Header.Payload)
+
+	if ie := s.Cause; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := s.IMSI; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := s.FlowLabelSignalling; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := s.MMContext; ie != nil {
+		l += ie.MarshalLen()
+	}
+	for _, ie := range s.PDPContexts {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	if ie := s.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range s.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (s *SGSNContextResponse) SetLength() {
+	s.Header.Length = uint16(s.MarshalLen() - 20)
+}
+
+// MessageTypeName returns the name of protocol.
+func (s *SGSNContextResponse) MessageTypeName() string {
+	return "SGSN Context Response"
+}
+
+// TID returns the TID in human-readable string.
+func (s *SGSNContextResponse) TID() string {
+	return s.tid()
+}
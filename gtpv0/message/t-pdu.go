@@ -30,6 +30,11 @@ func (t *TPDU) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from TPDU to b and returns the extended buffer.
+func (t *TPDU) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, t)
+}
+
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (t *TPDU) MarshalTo(b []byte) error {
 	if len(b) < t.MarshalLen() {
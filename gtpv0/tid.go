@@ -0,0 +1,38 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv0
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/wmnsk/go-gtp/utils"
+)
+
+// NewTID returns the TID that identifies the tunnel of the PDP context with IMSI
+// and NSAPI. The IMSI shorter than 15 digits is padded with "f".
+func NewTID(imsi string, nsapi uint8) (uint64, error) {
+	if len(imsi) == 0 || len(imsi) > 15 {
+		return 0, ErrInvalidIMSI
+	}
+
+	b, err := utils.StrToSwappedBytes(
+		fmt.Sprintf("%s%s%x", imsi, strings.Repeat("f", 15-len(imsi)), nsapi&0x0f), "f",
+	)
+	if err != nil {
+		return 0, ErrInvalidIMSI
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// ParseTID returns the IMSI and NSAPI in the TID.
+func ParseTID(tid uint64) (imsi string, nsapi uint8) {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, tid)
+
+	s := utils.SwappedBytesToStr(b, true)
+	return strings.TrimRight(s, "f"), b[7] >> 4
+}
@@ -0,0 +1,44 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv0_test
+
+import (
+	"testing"
+
+	v0 "github.com/wmnsk/go-gtp/gtpv0"
+)
+
+func TestTID(t *testing.T) {
+	cases := []struct {
+		description string
+		imsi        string
+		nsapi       uint8
+		tid         uint64
+	}{
+		{"15-digits", "123456789012345", 5, 0x2143658709214355},
+		{"14-digits", "12345678901234", 1, 0x214365870921431f},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			tid, err := v0.NewTID(c.imsi, c.nsapi)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tid != c.tid {
+				t.Errorf("wrong TID, want: %#016x, got: %#016x", c.tid, tid)
+			}
+
+			imsi, nsapi := v0.ParseTID(tid)
+			if imsi != c.imsi || nsapi != c.nsapi {
+				t.Errorf("wrong IMSI/NSAPI, want: %s/%d, got: %s/%d", c.imsi, c.nsapi, imsi, nsapi)
+			}
+		})
+	}
+
+	if _, err := v0.NewTID("1234567890123456", 5); err == nil {
+		t.Error("expected error with too long IMSI")
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv0
+
+import (
+	"encoding/binary"
+	"net"
+)
+
+// Tunnel is a GTPv0 tunnel of a PDP context on UPlaneConn.
+//
+// The T-PDUs with TID are received from and sent to PeerAddr. FlowLabel is the
+// one assigned by the peer, which is set in the header of the T-PDUs sent.
+type Tunnel struct {
+	TID       uint64
+	FlowLabel uint16
+	PeerAddr  net.Addr
+
+	seq uint16
+}
+
+// AddTunnel adds a tunnel with the TID given to UPlaneConn.
+// If the tunnel with the same TID exists, it is replaced.
+//
+// The T-PDUs with the TID are passed to ReadFromGTP, and the ones with unknown
+// TID are responded with Error Indication unless DisableErrorIndication is called.
+func (u *UPlaneConn) AddTunnel(tid uint64, peerAddr net.Addr, label uint16) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.tunnelMap[tid] = &Tunnel{TID: tid, FlowLabel: label, PeerAddr: peerAddr}
+	return nil
+}
+
+// DelTunnel deletes the tunnel with the TID given from UPlaneConn.
+func (u *UPlaneConn) DelTunnel(tid uint64) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if _, ok := u.tunnelMap[tid]; !ok {
+		return ErrTunnelNotFound
+	}
+	delete(u.tunnelMap, tid)
+	return nil
+}
+
+// Tunnel returns a copy of the tunnel with the TID given.
+func (u *UPlaneConn) Tunnel(tid uint64) (*Tunnel, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	t, ok := u.tunnelMap[tid]
+	if !ok {
+		return nil, false
+	}
+	c := *t
+	return &c, true
+}
+
+// WriteToTunnel writes a packet with payload p to the peer of the tunnel with
+// the TID given. The sequence number in the header is incremented per tunnel.
+func (u *UPlaneConn) WriteToTunnel(tid uint64, p []byte) (n int, err error) {
+	u.mu.Lock()
+	t, ok := u.tunnelMap[tid]
+	if !ok {
+		u.mu.Unlock()
+		return 0, ErrTunnelNotFound
+	}
+	seq, label, addr := t.seq, t.FlowLabel, t.PeerAddr
+	t.seq++
+	u.mu.Unlock()
+
+	return u.writeTPDU(seq, label, tid, p, addr)
+}
+
+type peer struct {
+	tid     uint64
+	label   uint16
+	addr    net.Addr
+	srcConn *UPlaneConn
+}
+
+// forward sends the T-PDU to the peer, rewriting the flow label and TID in the header.
+func (p *peer) forward(b []byte) error {
+	binary.BigEndian.PutUint16(b[6:8], p.label)
+	binary.BigEndian.PutUint64(b[12:20], p.tid)
+	_, err := p.srcConn.WriteTo(b, p.addr)
+	return err
+}
+
+// RelayTo relays T-PDU type of packet to peer node(specified by raddr) from the UPlaneConn given.
+// The TID and the flow label in the header are rewritten with tidOut and labelOut.
+//
+// By using this, owner of UPlaneConn won't be able to Read the packets that has tidIn.
+func (u *UPlaneConn) RelayTo(c *UPlaneConn, tidIn, tidOut uint64, labelOut uint16, raddr net.Addr) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.relayMap == nil {
+		u.relayMap = map[uint64]*peer{}
+	}
+	u.relayMap[tidIn] = &peer{tid: tidOut, label: labelOut, addr: raddr, srcConn: c}
+	return nil
+}
+
+// CloseRelay stops relaying T-PDU from a conn to conn.
+func (u *UPlaneConn) CloseRelay(tidIn uint64) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	delete(u.relayMap, tidIn)
+	return nil
+}
+
+func (u *UPlaneConn) relayPeer(tidIn uint64) (*peer, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	p, ok := u.relayMap[tidIn]
+	return p, ok
+}
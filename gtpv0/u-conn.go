@@ -0,0 +1,387 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv0
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wmnsk/go-gtp/gtpv0/ie"
+	"github.com/wmnsk/go-gtp/gtpv0/message"
+)
+
+// headerLen is the length of GTPv0 header, which is always 20 octets.
+const headerLen = 20
+
+type tpduSet struct {
+	raddr   net.Addr
+	tid     uint64
+	seq     uint16
+	payload []byte
+}
+
+// UPlaneConn represents a U-Plane Connection of GTPv0.
+//
+// Unlike GTPv1, the tunnels are identified by TID, which consists of IMSI and NSAPI,
+// in the header of T-PDU. See NewTID for how to make it.
+type UPlaneConn struct {
+	mu      sync.Mutex
+	laddr   net.Addr
+	pktConn net.PacketConn
+
+	tpduCh  chan *tpduSet
+	closeCh chan struct{}
+
+	tunnelMap map[uint64]*Tunnel
+	relayMap  map[uint64]*peer
+
+	errIndFn      ErrorIndicationFunc
+	errIndEnabled bool
+}
+
+// NewUPlaneConn creates a new UPlaneConn used for server.
+func NewUPlaneConn(laddr net.Addr) *UPlaneConn {
+	return &UPlaneConn{
+		mu:    sync.Mutex{},
+		laddr: laddr,
+
+		tpduCh:  make(chan *tpduSet),
+		closeCh: make(chan struct{}),
+
+		tunnelMap: map[uint64]*Tunnel{},
+
+		errIndEnabled: true,
+	}
+}
+
+// NewUPlaneConnWithPacketConn creates a new UPlaneConn over the net.PacketConn given,
+// instead of opening a UDP socket by itself. The local address is taken from pc.
+func NewUPlaneConnWithPacketConn(pc net.PacketConn) *UPlaneConn {
+	u := NewUPlaneConn(pc.LocalAddr())
+	u.pktConn = pc
+	return u
+}
+
+// ListenAndServe starts serving on UPlaneConn.
+// This blocks, and returns error only if it face the fatal one. Non-fatal errors are logged
+// with logger. See SetLogger/EnableLogger/DisableLogger for handling of those logs.
+func (u *UPlaneConn) ListenAndServe(ctx context.Context) error {
+	if u.pktConn == nil {
+		var err error
+		u.pktConn, err = net.ListenPacket(u.laddr.Network(), u.laddr.String())
+		if err != nil {
+			return err
+		}
+	}
+
+	return u.serve(ctx)
+}
+
+func (u *UPlaneConn) serve(ctx context.Context) error {
+	buf := make([]byte, 1600)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-u.closed():
+			return nil
+		default:
+			// do nothing and go forward.
+		}
+
+		n, raddr, err := u.pktConn.ReadFrom(buf)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return errors.Errorf("error reading from UPlaneConn %s: %s", u.LocalAddr(), err)
+		}
+
+		// the buffer is reused for the next one, while the packet may be handled
+		// in another goroutine.
+		b := make([]byte, n)
+		copy(b, buf[:n])
+		u.handlePacket(b, raddr)
+	}
+}
+
+func (u *UPlaneConn) handlePacket(b []byte, raddr net.Addr) {
+	// GTPv1 packets may come on the same port, which should not be handled here.
+	if len(b) < headerLen || b[0]>>5 != 0 {
+		logf("discarded invalid packet from %s on UPlaneConn %s", raddr, u.LocalAddr())
+		return
+	}
+
+	if b[1] == message.MsgTypeTPDU {
+		u.handleTPDU(b, raddr)
+		return
+	}
+
+	msg, err := message.Parse(b)
+	if err != nil {
+		logf("error parsing message on UPlaneConn %s: %s", u.LocalAddr(), err)
+		return
+	}
+
+	if err := u.handleMessage(raddr, msg); err != nil {
+		// should not stop serving with this error
+		logf("error handling message on UPlaneConn %s: %s", u.LocalAddr(), err)
+	}
+}
+
+func (u *UPlaneConn) handleTPDU(b []byte, raddr net.Addr) {
+	tid := binary.BigEndian.Uint64(b[12:20])
+
+	// just forward T-PDU instead of passing it to reader if relayer is
+	// configured for the TID.
+	if peer, ok := u.relayPeer(tid); ok {
+		if err := peer.forward(b); err != nil {
+			// should not stop serving with this error
+			logf("error sending on UPlaneConn %s: %s", u.LocalAddr(), err)
+		}
+		return
+	}
+
+	u.mu.Lock()
+	_, ok := u.tunnelMap[tid]
+	errIndEnabled := u.errIndEnabled
+	u.mu.Unlock()
+
+	if !ok && errIndEnabled {
+		if err := u.SendErrorIndication(tid, raddr); err != nil {
+			logf("error sending Error Indication on UPlaneConn %s: %s", u.LocalAddr(), err)
+		}
+		return
+	}
+
+	end := headerLen + int(binary.BigEndian.Uint16(b[2:4]))
+	if end > len(b) {
+		end = len(b)
+	}
+
+	// wait for the T-PDU passed to u.tpduCh to be read by ReadFromGTP.
+	// if it got stuck for 3 seconds, it discards the T-PDU received.
+	go u.sendTPDU(&tpduSet{
+		raddr:   raddr,
+		tid:     tid,
+		seq:     binary.BigEndian.Uint16(b[4:6]),
+		payload: b[headerLen:end],
+	})
+}
+
+func (u *UPlaneConn) sendTPDU(tpdu *tpduSet) {
+	select {
+	case u.tpduCh <- tpdu:
+	case <-u.closed():
+	case <-time.After(3 * time.Second):
+	}
+}
+
+func (u *UPlaneConn) handleMessage(senderAddr net.Addr, msg message.Message) error {
+	switch m := msg.(type) {
+	case *message.EchoRequest:
+		res := message.NewEchoResponse(m.SequenceNumber, 0, 0, ie.NewRecovery(0))
+		return u.writeMessage(res, senderAddr)
+	case *message.EchoResponse:
+		// nothing to do; the peer is alive.
+		return nil
+	case *message.ErrorIndication:
+		u.handleErrorIndication(senderAddr, m.Header.TID)
+		return nil
+	default:
+		return errors.Errorf("got unexpected type of message: %s", msg.MessageTypeName())
+	}
+}
+
+// ErrorIndicationFunc is called when an Error Indication is received on UPlaneConn.
+//
+// tid is the TID in the header of Error Indication. found is true if the tunnel
+// or the relay with the TID existed, which has already been removed when fn is called.
+type ErrorIndicationFunc func(u *UPlaneConn, senderAddr net.Addr, tid uint64, found bool)
+
+// SetErrorIndicationHandler sets the function called when an Error Indication is
+// received. Giving nil removes it.
+func (u *UPlaneConn) SetErrorIndicationHandler(fn ErrorIndicationFunc) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.errIndFn = fn
+}
+
+func (u *UPlaneConn) handleErrorIndication(senderAddr net.Addr, tid uint64) {
+	u.mu.Lock()
+	_, foundTunnel := u.tunnelMap[tid]
+	_, foundRelay := u.relayMap[tid]
+	delete(u.tunnelMap, tid)
+	delete(u.relayMap, tid)
+	fn := u.errIndFn
+	u.mu.Unlock()
+
+	logf("got Error Indication from %s for TID %#016x", senderAddr, tid)
+	if fn != nil {
+		fn(u, senderAddr, tid, foundTunnel || foundRelay)
+	}
+}
+
+// ReadFrom reads a packet from the connection,
+// copying the payload into p. It returns the number of
+// bytes copied into p and the return address that
+// was on the packet.
+// ReadFrom can be made to time out and return
+// an Error with Timeout() == true after a fixed time limit;
+// see SetDeadline and SetReadDeadline.
+func (u *UPlaneConn) ReadFrom(p []byte) (n int, addr net.Addr, err error) {
+	return u.pktConn.ReadFrom(p)
+}
+
+// ReadFromGTP reads a packet from the connection, copying the payload without
+// GTP header into p. It returns the number of bytes copied into p, the return
+// address that was on the packet, TID in the GTP header.
+//
+// Only the T-PDUs with the TID added by AddTunnel are read by this, unless
+// the Error Indication is disabled by DisableErrorIndication.
+func (u *UPlaneConn) ReadFromGTP(p []byte) (n int, addr net.Addr, tid uint64, err error) {
+	select {
+	case <-u.closed():
+		err = ErrConnNotOpened
+		return
+	case tpdu := <-u.tpduCh:
+		n = copy(p, tpdu.payload)
+		addr = tpdu.raddr
+		tid = tpdu.tid
+		return
+	}
+}
+
+// WriteTo writes a packet with payload p to addr.
+// WriteTo can be made to time out and return
+// an Error with Timeout() == true after a fixed time limit;
+// see SetDeadline and SetWriteDeadline.
+// On packet-oriented connections, write timeouts are rare.
+func (u *UPlaneConn) WriteTo(p []byte, addr net.Addr) (n int, err error) {
+	return u.pktConn.WriteTo(p, addr)
+}
+
+// WriteToGTP writes a packet with TID and payload to addr.
+// The sequence number and the flow label are set to zero; use WriteToTunnel
+// to send on the tunnel added by AddTunnel.
+func (u *UPlaneConn) WriteToGTP(tid uint64, p []byte, addr net.Addr) (n int, err error) {
+	return u.writeTPDU(0, 0, tid, p, addr)
+}
+
+func (u *UPlaneConn) writeTPDU(seq, label uint16, tid uint64, p []byte, addr net.Addr) (n int, err error) {
+	b, err := message.NewTPDU(seq, label, tid, p).Marshal()
+	if err != nil {
+		return
+	}
+
+	if _, err = u.pktConn.WriteTo(b, addr); err != nil {
+		return
+	}
+	return len(b), nil
+}
+
+func (u *UPlaneConn) writeMessage(msg message.Message, addr net.Addr) error {
+	b, err := message.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := u.pktConn.WriteTo(b, addr); err != nil {
+		return err
+	}
+	return nil
+}
+
+// closed would be used in multiple goroutines.
+// never send struct{}{} to it; instead, use close(u.closeCh).
+func (u *UPlaneConn) closed() <-chan struct{} {
+	return u.closeCh
+}
+
+// Close closes the connection.
+// Any blocked Read or Write operations will be unblocked and return errors.
+func (u *UPlaneConn) Close() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.relayMap = nil
+	u.tunnelMap = map[uint64]*Tunnel{}
+	close(u.closeCh)
+
+	if err := u.pktConn.Close(); err != nil {
+		logf("error closing the underlying conn: %s", err)
+	}
+	return nil
+}
+
+// LocalAddr returns the local network address.
+func (u *UPlaneConn) LocalAddr() net.Addr {
+	return u.pktConn.LocalAddr()
+}
+
+// SetDeadline sets the read and write deadlines associated
+// with the connection. It is equivalent to calling both
+// SetReadDeadline and SetWriteDeadline.
+//
+// A zero value for t means I/O operations will not time out.
+func (u *UPlaneConn) SetDeadline(t time.Time) error {
+	return u.pktConn.SetDeadline(t)
+}
+
+// SetReadDeadline sets the deadline for future Read calls
+// and any currently-blocked Read call.
+// A zero value for t means Read will not time out.
+func (u *UPlaneConn) SetReadDeadline(t time.Time) error {
+	return u.pktConn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets the deadline for future Write calls
+// and any currently-blocked Write call.
+// A zero value for t means Write will not time out.
+func (u *UPlaneConn) SetWriteDeadline(t time.Time) error {
+	return u.pktConn.SetWriteDeadline(t)
+}
+
+// EchoRequest sends a EchoRequest.
+func (u *UPlaneConn) EchoRequest(raddr net.Addr) error {
+	return u.writeMessage(message.NewEchoRequest(0, 0, 0), raddr)
+}
+
+// SendErrorIndication sends Error Indication to raddr, to notify that the T-PDU
+// with the TID given has been received but no tunnel is found for it.
+//
+// This is done automatically on receiving T-PDU unless it is disabled by
+// DisableErrorIndication.
+func (u *UPlaneConn) SendErrorIndication(tid uint64, raddr net.Addr) error {
+	return u.writeMessage(message.NewErrorIndication(0, 0, tid), raddr)
+}
+
+// EnableErrorIndication re-enables automatic sending of
+// Error Indication to the T-PDU with unknown TID, which is
+// enabled by default.
+//
+// See also: DisableErrorIndication.
+func (u *UPlaneConn) EnableErrorIndication() {
+	u.mu.Lock()
+	u.errIndEnabled = true
+	u.mu.Unlock()
+}
+
+// DisableErrorIndication makes UPlaneConn stop responding with
+// Error Indication in case of receiving T-PDU with unknown TID.
+//
+// When disabled, it passes the T-PDU to user who calls
+// ReadFromGTP instead.
+func (u *UPlaneConn) DisableErrorIndication() {
+	u.mu.Lock()
+	u.errIndEnabled = false
+	u.mu.Unlock()
+}
@@ -0,0 +1,202 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv0_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	v0 "github.com/wmnsk/go-gtp/gtpv0"
+)
+
+var payload = []byte{0xde, 0xad, 0xbe, 0xef}
+
+func listen(ctx context.Context, t *testing.T, addr string) *v0.UPlaneConn {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", addr+v0.GTPPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u := v0.NewUPlaneConnWithPacketConn(pc)
+	go func() {
+		if err := u.ListenAndServe(ctx); err != nil {
+			return
+		}
+	}()
+	return u
+}
+
+func readFromGTP(t *testing.T, u *v0.UPlaneConn) (int, []byte, uint64) {
+	t.Helper()
+
+	type result struct {
+		n   int
+		tid uint64
+		err error
+	}
+
+	buf := make([]byte, 1600)
+	ch := make(chan result)
+	go func() {
+		n, _, tid, err := u.ReadFromGTP(buf)
+		ch <- result{n, tid, err}
+	}()
+
+	select {
+	case r := <-ch:
+		if r.err != nil {
+			t.Fatal(r.err)
+		}
+		return r.n, buf[:r.n], r.tid
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out")
+	}
+	return 0, nil, 0
+}
+
+func TestUPlaneConnTunnel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cliConn := listen(ctx, t, "127.0.0.1")
+	defer cliConn.Close()
+	srvConn := listen(ctx, t, "127.0.0.2")
+	defer srvConn.Close()
+
+	tid, err := v0.NewTID("123456789012345", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cliConn.AddTunnel(tid, srvConn.LocalAddr(), 0x1111); err != nil {
+		t.Fatal(err)
+	}
+	if err := srvConn.AddTunnel(tid, cliConn.LocalAddr(), 0x2222); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := cliConn.WriteToTunnel(tid, payload); err != nil {
+			t.Fatal(err)
+		}
+		_, got, gotTID := readFromGTP(t, srvConn)
+		if diff := cmp.Diff(payload, got); diff != "" {
+			t.Error(diff)
+		}
+		if gotTID != tid {
+			t.Errorf("wrong TID, want: %#016x, got: %#016x", tid, gotTID)
+		}
+	}
+
+	if _, err := srvConn.WriteToTunnel(tid, payload); err != nil {
+		t.Fatal(err)
+	}
+	if _, got, _ := readFromGTP(t, cliConn); !cmp.Equal(payload, got) {
+		t.Errorf("wrong payload, want: %x, got: %x", payload, got)
+	}
+
+	if err := srvConn.DelTunnel(tid); err != nil {
+		t.Fatal(err)
+	}
+	if err := srvConn.DelTunnel(tid); err != v0.ErrTunnelNotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := srvConn.WriteToTunnel(tid, payload); err != v0.ErrTunnelNotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestUPlaneConnErrorIndication(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cliConn := listen(ctx, t, "127.0.0.3")
+	defer cliConn.Close()
+	srvConn := listen(ctx, t, "127.0.0.4")
+	defer srvConn.Close()
+
+	tid, err := v0.NewTID("123456789012345", 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cliConn.AddTunnel(tid, srvConn.LocalAddr(), 0x1111); err != nil {
+		t.Fatal(err)
+	}
+
+	type indication struct {
+		tid   uint64
+		found bool
+	}
+	indCh := make(chan indication)
+	cliConn.SetErrorIndicationHandler(func(u *v0.UPlaneConn, senderAddr net.Addr, tid uint64, found bool) {
+		indCh <- indication{tid, found}
+	})
+
+	// srvConn does not know the TID.
+	if _, err := cliConn.WriteToTunnel(tid, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case ind := <-indCh:
+		if ind.tid != tid || !ind.found {
+			t.Errorf("wrong Error Indication, want: %#016x/true, got: %#016x/%v", tid, ind.tid, ind.found)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out")
+	}
+
+	if _, ok := cliConn.Tunnel(tid); ok {
+		t.Error("tunnel is not removed on Error Indication")
+	}
+}
+
+func TestUPlaneConnRelay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sgsnConn := listen(ctx, t, "127.0.0.5")
+	defer sgsnConn.Close()
+	relayConn := listen(ctx, t, "127.0.0.6")
+	defer relayConn.Close()
+	ggsnConn := listen(ctx, t, "127.0.0.7")
+	defer ggsnConn.Close()
+
+	tidIn, err := v0.NewTID("123456789012345", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tidOut, err := v0.NewTID("123456789012345", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := relayConn.RelayTo(relayConn, tidIn, tidOut, 0x3333, ggsnConn.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+	if err := ggsnConn.AddTunnel(tidOut, relayConn.LocalAddr(), 0x4444); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sgsnConn.WriteToGTP(tidIn, payload, relayConn.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+	_, got, gotTID := readFromGTP(t, ggsnConn)
+	if diff := cmp.Diff(payload, got); diff != "" {
+		t.Error(diff)
+	}
+	if gotTID != tidOut {
+		t.Errorf("wrong TID, want: %#016x, got: %#016x", tidOut, gotTID)
+	}
+
+	if err := relayConn.CloseRelay(tidIn); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv1_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	v1 "github.com/wmnsk/go-gtp/gtpv1"
+)
+
+func TestBatchIO(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srvPC, err := net.ListenPacket("udp", "127.0.0.23:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srvConn := v1.NewUPlaneConnWithPacketConn(srvPC)
+	srvConn.DisableErrorIndication()
+	srvConn.EnableBatchIO(8)
+	defer srvConn.Close()
+	go func() {
+		if err := srvConn.ListenAndServe(ctx); err != nil {
+			return
+		}
+	}()
+
+	cliPC, err := net.ListenPacket("udp", "127.0.0.24:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cliConn := v1.NewUPlaneConnWithPacketConn(cliPC)
+	cliConn.EnableBatchIO(8)
+	defer cliConn.Close()
+
+	payloads := make([][]byte, 20)
+	for i := range payloads {
+		payloads[i] = []byte{0xde, 0xad, 0xbe, 0xef, uint8(i)}
+	}
+
+	n, err := cliConn.WriteBatchToGTP(0x11111111, payloads, srvConn.LocalAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(payloads) {
+		t.Fatalf("wrong number of packets written. want: %d, got: %d", len(payloads), n)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		// the order is not kept, as each T-PDU is handled in its own goroutine.
+		got := make([][]byte, len(payloads))
+		buf := make([]byte, 1500)
+		for range payloads {
+			n, addr, teid, err := srvConn.ReadFromGTP(buf)
+			if err != nil {
+				errCh <- err
+				return
+			}
+
+			if diff := cmp.Diff(addr.String(), cliConn.LocalAddr().String()); diff != "" {
+				t.Error(diff)
+			}
+			if diff := cmp.Diff(teid, uint32(0x11111111)); diff != "" {
+				t.Error(diff)
+			}
+			if n != 5 || int(buf[4]) >= len(got) {
+				t.Errorf("unexpected payload: %x", buf[:n])
+				continue
+			}
+			got[buf[4]] = append([]byte{}, buf[:n]...)
+		}
+
+		if diff := cmp.Diff(got, payloads); diff != "" {
+			t.Error(diff)
+		}
+		errCh <- nil
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out while waiting for packets to come")
+	}
+}
+
+func BenchmarkWriteToGTP(b *testing.B) {
+	for _, size := range []int{64, 256, 1024} {
+		b.Run(fmt.Sprintf("single/%d", size), func(b *testing.B) {
+			benchmarkWrite(b, size, 0)
+		})
+		b.Run(fmt.Sprintf("batch/%d", size), func(b *testing.B) {
+			benchmarkWrite(b, size, 32)
+		})
+	}
+}
+
+func benchmarkWrite(b *testing.B, size, batch int) {
+	sink, err := net.ListenPacket("udp", "127.0.0.25:2152")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer sink.Close()
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			if _, _, err := sink.ReadFrom(buf); err != nil {
+				return
+			}
+		}
+	}()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.26:2152")
+	if err != nil {
+		b.Fatal(err)
+	}
+	uConn := v1.NewUPlaneConnWithPacketConn(pc)
+	defer uConn.Close()
+
+	payload := make([]byte, size)
+	b.SetBytes(int64(size))
+	b.ResetTimer()
+
+	if batch == 0 {
+		for i := 0; i < b.N; i++ {
+			if _, err := uConn.WriteToGTP(0x11111111, payload, sink.LocalAddr()); err != nil {
+				b.Fatal(err)
+			}
+		}
+		return
+	}
+
+	uConn.EnableBatchIO(batch)
+	ps := make([][]byte, batch)
+	for i := range ps {
+		ps[i] = payload
+	}
+	for i := 0; i < b.N; i += batch {
+		if b.N-i < batch {
+			ps = ps[:b.N-i]
+		}
+		if _, err := uConn.WriteBatchToGTP(0x11111111, ps, sink.LocalAddr()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
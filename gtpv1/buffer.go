@@ -0,0 +1,271 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv1
+
+import (
+	"encoding/binary"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
+)
+
+const (
+	// bufferSize is the size of the whole Buffer including headroom.
+	bufferSize = 2048
+	// bufferHeadroom is the space reserved in front of the content of the Buffer to
+	// put the GTP header without copying the payload.
+	bufferHeadroom = 64
+)
+
+var bufPool = sync.Pool{
+	New: func() interface{} {
+		return &Buffer{buf: make([]byte, bufferSize)}
+	},
+}
+
+// Buffer is a packet buffer taken from the pool shared among UPlaneConns, which
+// is used to read and write T-PDUs without allocating memory for each packet.
+//
+// The Buffer should be returned to the pool with Release when it is no longer
+// used, and must not be used after that.
+type Buffer struct {
+	buf      []byte
+	off, end int
+}
+
+// GetBuffer takes an empty Buffer from the pool.
+func GetBuffer() *Buffer {
+	b := bufPool.Get().(*Buffer)
+	b.off, b.end = bufferHeadroom, bufferHeadroom
+	return b
+}
+
+// Release returns the Buffer to the pool.
+func (b *Buffer) Release() {
+	bufPool.Put(b)
+}
+
+// Bytes returns the content of the Buffer. The slice is valid only until the
+// Buffer is released.
+func (b *Buffer) Bytes() []byte {
+	return b.buf[b.off:b.end]
+}
+
+// Len returns the length of the content of the Buffer.
+func (b *Buffer) Len() int {
+	return b.end - b.off
+}
+
+// Extend extends the content of the Buffer by n bytes and returns the extended
+// part to be filled by the caller. The length of the returned slice is less than
+// n if the Buffer does not have enough space, e.g.,
+//
+//	b := gtpv1.GetBuffer()
+//	n, err := tun.Read(b.Extend(1500))
+//	b.Truncate(n)
+func (b *Buffer) Extend(n int) []byte {
+	if n > len(b.buf)-b.end {
+		n = len(b.buf) - b.end
+	}
+	p := b.buf[b.end : b.end+n]
+	b.end += n
+	return p
+}
+
+// Truncate discards all but the first n bytes of the content of the Buffer.
+func (b *Buffer) Truncate(n int) {
+	if n < 0 {
+		n = 0
+	}
+	if n < b.Len() {
+		b.end = b.off + n
+	}
+}
+
+// readSpace returns the whole space after the headroom to read a packet into.
+func (b *Buffer) readSpace() []byte {
+	return b.buf[bufferHeadroom:]
+}
+
+// prepend extends the content of the Buffer by n bytes to the front and returns
+// the extended part, or nil if the headroom is not enough.
+func (b *Buffer) prepend(n int) []byte {
+	if n > b.off {
+		return nil
+	}
+	b.off -= n
+	return b.buf[b.off : b.off+n]
+}
+
+// TPDUHandlerFunc is called when a T-PDU is received on UPlaneConn, with the
+// TEID in the GTP header and the Buffer that contains the payload.
+//
+// The payload is not copied from the buffer the packet is read into, and the
+// handler owns it. The handler should call payload.Release when it is done, which
+// can be done in another goroutine.
+type TPDUHandlerFunc func(u *UPlaneConn, senderAddr net.Addr, teid uint32, payload *Buffer)
+
+// SetTPDUHandler sets the function called for each T-PDU received on UPlaneConn
+// except the ones relayed with RelayTo. With this handler, T-PDUs are handled
+// without allocating memory for each packet.
+//
+// The handler is called in the goroutine that reads packets from the connection,
+// so it should not block. The T-PDUs are no longer passed to ReadFromGTP, and
+// Error Indication, reordering by EnableReordering are not performed for them.
+//
+// Passing nil to fn restores the default behavior.
+func (u *UPlaneConn) SetTPDUHandler(fn TPDUHandlerFunc) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.tpduFn = fn
+}
+
+// AddTPDUHandler sets the function called for each T-PDU received with the incoming
+// TEID given, so that the T-PDUs on different bearers can be handled in different
+// ways. The handler is called in the same way as the one set by SetTPDUHandler,
+// which is used as the fallback for the T-PDUs with the other TEIDs.
+//
+// The TEID is marked as taken so that NewFTEID does not allocate it. The handler is
+// removed by RemoveTPDUHandler, or when the tunnel with the TEID is deleted with
+// CloseRelay, DelTunnelByITEI or DelTunnelByMSAddress.
+func (u *UPlaneConn) AddTPDUHandler(itei uint32, fn TPDUHandlerFunc) error {
+	if u.kernGTPEnabled {
+		return errors.New("cannot call AddTPDUHandler when using Kernel GTP-U")
+	}
+	if fn == nil {
+		return errors.New("cannot add nil TPDUHandlerFunc")
+	}
+
+	u.mu.Lock()
+	if u.tpduFnMap == nil {
+		u.tpduFnMap = map[uint32]TPDUHandlerFunc{}
+	}
+	u.tpduFnMap[itei] = fn
+	u.mu.Unlock()
+
+	u.iteiMap.tryStore(itei, time.Now())
+	return nil
+}
+
+// RemoveTPDUHandler removes the function set by AddTPDUHandler for the incoming
+// TEID given, and releases the TEID.
+func (u *UPlaneConn) RemoveTPDUHandler(itei uint32) {
+	u.releaseTEID(itei)
+}
+
+// releaseTEID removes the handler and the statistics for the incoming TEID, and
+// makes the TEID available for NewFTEID again.
+func (u *UPlaneConn) releaseTEID(itei uint32) {
+	u.mu.Lock()
+	delete(u.tpduFnMap, itei)
+	delete(u.statsMap, itei)
+	u.mu.Unlock()
+
+	u.iteiMap.delete(itei)
+}
+
+// tpduHandler returns the handler for the incoming TEID, or the fallback one.
+func (u *UPlaneConn) tpduHandler(teid uint32) TPDUHandlerFunc {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if fn, ok := u.tpduFnMap[teid]; ok {
+		return fn
+	}
+	return u.tpduFn
+}
+
+// borrowTPDU passes the T-PDU in b to the handler set by AddTPDUHandler or
+// SetTPDUHandler, and returns true if the handler took the buffer. n is the length
+// of the packet in b.
+func (u *UPlaneConn) borrowTPDU(b *Buffer, n int, raddr net.Addr) bool {
+	pkt := b.readSpace()[:n]
+	teid, off, ok := tpduPayloadOffset(pkt)
+	if !ok {
+		return false
+	}
+
+	fn := u.tpduHandler(teid)
+	if fn == nil {
+		return false
+	}
+
+	b.off = bufferHeadroom + off
+	b.end = bufferHeadroom + n
+	fn(u, raddr, teid, b)
+	return true
+}
+
+// tpduPayloadOffset returns the TEID and the offset of the payload in the T-PDU
+// given, skipping the optional fields and the extension headers.
+func tpduPayloadOffset(b []byte) (uint32, int, bool) {
+	if len(b) < 8 || b[0]>>5 != 1 || b[1] != message.MsgTypeTPDU {
+		return 0, 0, false
+	}
+
+	end := 8 + int(binary.BigEndian.Uint16(b[2:4]))
+	if end > len(b) {
+		return 0, 0, false
+	}
+	teid := binary.BigEndian.Uint32(b[4:8])
+
+	// none of E, S, PN flags are set.
+	if b[0]&0x07 == 0 {
+		return teid, 8, true
+	}
+	if end < 12 {
+		return 0, 0, false
+	}
+
+	off := 12
+	next := b[11]
+	if b[0]&0x04 == 0 {
+		next = 0
+	}
+	for next != 0 {
+		if off >= end {
+			return 0, 0, false
+		}
+		l := int(b[off]) * 4
+		if l == 0 || off+l > end {
+			return 0, 0, false
+		}
+		next = b[off+l-1]
+		off += l
+	}
+
+	return teid, off, true
+}
+
+// WriteBufferToGTP writes the payload in b with TEID to addr. The GTP header is
+// put in the headroom of b, so that the payload is not copied.
+//
+// b is released after written, regardless of whether it succeeds or not.
+func (u *UPlaneConn) WriteBufferToGTP(teid uint32, b *Buffer, addr net.Addr) (n int, err error) {
+	defer b.Release()
+
+	seq, hasSeq := u.nextSequence(teid)
+	l := b.Len()
+	if hasSeq {
+		h := b.prepend(12)
+		h[0] = 0x32
+		binary.BigEndian.PutUint16(h[8:10], seq)
+		h[10], h[11] = 0, 0
+		l += 4
+	} else {
+		b.prepend(8)[0] = 0x30
+	}
+
+	h := b.Bytes()
+	h[1] = message.MsgTypeTPDU
+	binary.BigEndian.PutUint16(h[2:4], uint16(l))
+	binary.BigEndian.PutUint32(h[4:8], teid)
+
+	return u.writeTPDU(teid, h, addr)
+}
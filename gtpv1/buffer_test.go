@@ -0,0 +1,267 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv1_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	v1 "github.com/wmnsk/go-gtp/gtpv1"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
+)
+
+func TestBuffer(t *testing.T) {
+	b := v1.GetBuffer()
+	defer b.Release()
+
+	if got := b.Len(); got != 0 {
+		t.Fatalf("buffer taken is not empty: %d", got)
+	}
+
+	n := copy(b.Extend(8), []byte{0xde, 0xad, 0xbe, 0xef})
+	b.Truncate(n)
+	if diff := cmp.Diff(b.Bytes(), []byte{0xde, 0xad, 0xbe, 0xef}); diff != "" {
+		t.Error(diff)
+	}
+
+	// cannot be extended beyond its capacity.
+	if got := len(b.Extend(1 << 16)); got >= 1<<16 {
+		t.Errorf("buffer extended too much: %d", got)
+	}
+}
+
+func TestTPDUHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type received struct {
+		teid    uint32
+		payload []byte
+	}
+	recvCh := make(chan *received)
+
+	srvPC, err := net.ListenPacket("udp", "127.0.0.27:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srvConn := v1.NewUPlaneConnWithPacketConn(srvPC)
+	defer srvConn.Close()
+	srvConn.SetTPDUHandler(func(u *v1.UPlaneConn, senderAddr net.Addr, teid uint32, payload *v1.Buffer) {
+		go func() {
+			defer payload.Release()
+			recvCh <- &received{teid, append([]byte{}, payload.Bytes()...)}
+		}()
+	})
+	go func() {
+		if err := srvConn.ListenAndServe(ctx); err != nil {
+			return
+		}
+	}()
+
+	cliPC, err := net.ListenPacket("udp", "127.0.0.28:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cliConn := v1.NewUPlaneConnWithPacketConn(cliPC)
+	defer cliConn.Close()
+
+	e, err := message.NewDLPDUSessionInformation(9, false).ToExtensionHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := []byte{0xde, 0xad, 0xbe, 0xef}
+	cases := []struct {
+		description string
+		teid        uint32
+		write       func() error
+	}{
+		{
+			"WriteToGTP", 0x11111111,
+			func() error {
+				_, err := cliConn.WriteToGTP(0x11111111, payload, srvConn.LocalAddr())
+				return err
+			},
+		}, {
+			"WithExtensionHeaders", 0x22222222,
+			func() error {
+				_, err := cliConn.WriteToGTPWithExtensionHeaders(0x22222222, payload, srvConn.LocalAddr(), e)
+				return err
+			},
+		}, {
+			"WriteBufferToGTP", 0x33333333,
+			func() error {
+				b := v1.GetBuffer()
+				copy(b.Extend(len(payload)), payload)
+				_, err := cliConn.WriteBufferToGTP(0x33333333, b, srvConn.LocalAddr())
+				return err
+			},
+		}, {
+			"WriteBufferToGTPWithSequence", 0x44444444,
+			func() error {
+				cliConn.EnableSequenceNumber(0x44444444)
+				b := v1.GetBuffer()
+				copy(b.Extend(len(payload)), payload)
+				_, err := cliConn.WriteBufferToGTP(0x44444444, b, srvConn.LocalAddr())
+				return err
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if err := c.write(); err != nil {
+				t.Fatal(err)
+			}
+
+			select {
+			case got := <-recvCh:
+				if diff := cmp.Diff(got.teid, c.teid); diff != "" {
+					t.Error(diff)
+				}
+				if diff := cmp.Diff(got.payload, payload); diff != "" {
+					t.Error(diff)
+				}
+			case <-time.After(3 * time.Second):
+				t.Fatal("timed out while waiting for T-PDU to come")
+			}
+		})
+	}
+}
+
+func TestPerTEIDTPDUHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type received struct {
+		pipeline string
+		teid     uint32
+	}
+	recvCh := make(chan *received, 10)
+	handler := func(pipeline string) v1.TPDUHandlerFunc {
+		return func(u *v1.UPlaneConn, senderAddr net.Addr, teid uint32, payload *v1.Buffer) {
+			payload.Release()
+			recvCh <- &received{pipeline, teid}
+		}
+	}
+
+	srvPC, err := net.ListenPacket("udp", "127.0.0.32:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srvConn := v1.NewUPlaneConnWithPacketConn(srvPC)
+	defer srvConn.Close()
+	srvConn.SetTPDUHandler(handler("default"))
+	if err := srvConn.AddTPDUHandler(0x11111111, handler("ims")); err != nil {
+		t.Fatal(err)
+	}
+	if err := srvConn.AddTPDUHandler(0x22222222, handler("internet")); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := srvConn.ListenAndServe(ctx); err != nil {
+			return
+		}
+	}()
+
+	cliPC, err := net.ListenPacket("udp", "127.0.0.33:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cliConn := v1.NewUPlaneConnWithPacketConn(cliPC)
+	defer cliConn.Close()
+
+	check := func(teid uint32, want string) {
+		t.Helper()
+		if _, err := cliConn.WriteToGTP(teid, []byte{0xde, 0xad, 0xbe, 0xef}, srvConn.LocalAddr()); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case got := <-recvCh:
+			if got.pipeline != want || got.teid != teid {
+				t.Errorf("wrong handler called. want: %s(%#08x), got: %s(%#08x)", want, teid, got.pipeline, got.teid)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatal("timed out while waiting for T-PDU to come")
+		}
+	}
+
+	check(0x11111111, "ims")
+	check(0x22222222, "internet")
+	check(0x33333333, "default")
+
+	// the T-PDUs are passed to the fallback after the handler is removed.
+	srvConn.RemoveTPDUHandler(0x11111111)
+	check(0x11111111, "default")
+	check(0x22222222, "internet")
+}
+
+func BenchmarkReadTPDU(b *testing.B) {
+	b.Run("ReadFromGTP", func(b *testing.B) {
+		benchmarkReadTPDU(b, "127.0.0.29:2152", false)
+	})
+	b.Run("TPDUHandler", func(b *testing.B) {
+		benchmarkReadTPDU(b, "127.0.0.30:2152", true)
+	})
+}
+
+func benchmarkReadTPDU(b *testing.B, addr string, borrow bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pc, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		b.Fatal(err)
+	}
+	uConn := v1.NewUPlaneConnWithPacketConn(pc)
+	uConn.DisableErrorIndication()
+	defer uConn.Close()
+
+	doneCh := make(chan struct{})
+	if borrow {
+		uConn.SetTPDUHandler(func(u *v1.UPlaneConn, senderAddr net.Addr, teid uint32, payload *v1.Buffer) {
+			payload.Release()
+			doneCh <- struct{}{}
+		})
+	}
+	go func() {
+		if err := uConn.ListenAndServe(ctx); err != nil {
+			return
+		}
+	}()
+
+	sender, err := net.ListenPacket("udp", "127.0.0.31:2152")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer sender.Close()
+
+	pkt, err := message.NewTPDU(0x11111111, make([]byte, 64)).Marshal()
+	if err != nil {
+		b.Fatal(err)
+	}
+	buf := make([]byte, 1500)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := sender.WriteTo(pkt, pc.LocalAddr()); err != nil {
+			b.Fatal(err)
+		}
+
+		if borrow {
+			<-doneCh
+			continue
+		}
+		if _, _, _, err := uConn.ReadFromGTP(buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
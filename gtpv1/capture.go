@@ -0,0 +1,42 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv1
+
+import (
+	"net"
+
+	"github.com/wmnsk/go-gtp/gtpcap"
+)
+
+// SetCaptureHook sets the function called with every packet sent and received on
+// the UPlaneConn, with the timestamp and the addresses. Giving nil disables capturing.
+//
+// The Hook returned by (*gtpcap.Writer).Hook writes the packets to a pcapng file,
+// which lets the traces be collected without capturing on the host, e.g.,
+//
+//	w, _ := gtpcap.CreateFile("s1u.pcapng")
+//	defer w.Close()
+//	u.SetCaptureHook(w.Hook())
+//
+// The packets received are passed to the hook in serving, including the ones
+// relayed. Note that the packets handled by Kernel GTP-U are not passed, and
+// that the hook is called for every T-PDU, which affects the throughput.
+func (u *UPlaneConn) SetCaptureHook(fn gtpcap.Hook) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.captureHook = fn
+}
+
+func (u *UPlaneConn) capture(b []byte, src, dst net.Addr) {
+	u.mu.Lock()
+	fn := u.captureHook
+	u.mu.Unlock()
+
+	if fn == nil {
+		return
+	}
+	fn(gtpcap.NewPacket(b, src, dst))
+}
@@ -0,0 +1,140 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv1
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// dscpMarking is the DSCP marking of the outer IP header for a tunnel.
+type dscpMarking struct {
+	dscp    uint8
+	reflect bool
+}
+
+// SetDSCP sets the DSCP in the outer IP header of the T-PDUs sent with the outgoing
+// TEID given, which makes it possible to map the QCI of the bearer to the DSCP in
+// the transport network. This is applied to the T-PDUs written by WriteToGTP and its
+// variants, and the ones forwarded with RelayTo to the TEID.
+//
+// The DSCP is set per packet with IP_TOS or IPV6_TCLASS control message, which is
+// available only on Linux.
+func (u *UPlaneConn) SetDSCP(otei uint32, dscp uint8) error {
+	if dscp > 0x3f {
+		return ErrInvalidDSCP
+	}
+	return u.setDSCPMarking(otei, func(m *dscpMarking) { m.dscp = dscp })
+}
+
+// EnableDSCPReflection makes the DSCP in the inner IP packet copied to the outer
+// IP header of the T-PDUs sent with the outgoing TEID given. The one set by SetDSCP,
+// or 0 if not set, is used for the T-PDUs whose payload is not an IP packet.
+func (u *UPlaneConn) EnableDSCPReflection(otei uint32) error {
+	return u.setDSCPMarking(otei, func(m *dscpMarking) { m.reflect = true })
+}
+
+// ClearDSCP stops marking the T-PDUs sent with the outgoing TEID given, which is
+// set by SetDSCP or EnableDSCPReflection.
+func (u *UPlaneConn) ClearDSCP(otei uint32) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	delete(u.dscpMap, otei)
+}
+
+func (u *UPlaneConn) setDSCPMarking(otei uint32, fn func(m *dscpMarking)) error {
+	if !dscpSupported {
+		return errors.New("DSCP marking is not supported on this platform")
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.dscpMap == nil {
+		u.dscpMap = map[uint32]*dscpMarking{}
+	}
+	m, ok := u.dscpMap[otei]
+	if !ok {
+		m = &dscpMarking{}
+		u.dscpMap[otei] = m
+	}
+	fn(m)
+	return nil
+}
+
+func (u *UPlaneConn) dscpMarking(otei uint32) (dscpMarking, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	m, ok := u.dscpMap[otei]
+	if !ok {
+		return dscpMarking{}, false
+	}
+	return *m, true
+}
+
+// tos returns the value of TOS or Traffic Class field for the T-PDU given, which
+// has the DSCP in the upper 6 bits and the ECN bits cleared.
+func (m dscpMarking) tos(pkt []byte) uint8 {
+	dscp := m.dscp
+	if m.reflect {
+		if _, off, ok := tpduPayloadOffset(pkt); ok {
+			if inner, ok := innerDSCP(pkt[off:]); ok {
+				dscp = inner
+			}
+		}
+	}
+	return dscp << 2
+}
+
+// innerDSCP returns the DSCP of the IPv4 or IPv6 packet given.
+func innerDSCP(p []byte) (uint8, bool) {
+	if len(p) < 2 {
+		return 0, false
+	}
+
+	switch p[0] >> 4 {
+	case 4:
+		return p[1] >> 2, true
+	case 6:
+		return (p[0]&0x0f)<<2 | p[1]>>6, true
+	default:
+		return 0, false
+	}
+}
+
+// writeTPDU writes the T-PDU with the outgoing TEID given to addr, with the outer
+// DSCP marked if configured for the TEID, and counts it in the statistics.
+func (u *UPlaneConn) writeTPDU(otei uint32, b []byte, addr net.Addr) (int, error) {
+	n, err := u.writeTPDUWithDSCP(otei, b, addr)
+	if err != nil {
+		return n, err
+	}
+
+	u.countOut(otei, n)
+	u.capture(b, u.LocalAddr(), addr)
+	return n, nil
+}
+
+func (u *UPlaneConn) writeTPDUWithDSCP(otei uint32, b []byte, addr net.Addr) (int, error) {
+	m, ok := u.dscpMarking(otei)
+	if !ok {
+		return u.pktConn.WriteTo(b, addr)
+	}
+
+	uc, ok := u.pktConn.(*net.UDPConn)
+	if !ok {
+		return u.pktConn.WriteTo(b, addr)
+	}
+	raddr, ok := addr.(*net.UDPAddr)
+	if !ok {
+		return u.pktConn.WriteTo(b, addr)
+	}
+
+	n, _, err := uc.WriteMsgUDP(b, tosControlMessage(m.tos(b), raddr.IP), raddr)
+	return n, err
+}
@@ -0,0 +1,34 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package gtpv1
+
+import (
+	"net"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const dscpSupported = true
+
+// tosControlMessage returns the control message to set the TOS of IPv4 or the
+// Traffic Class of IPv6 for the packet sent to the IP given.
+func tosControlMessage(tos uint8, dst net.IP) []byte {
+	level, typ := unix.IPPROTO_IPV6, unix.IPV6_TCLASS
+	if dst.To4() != nil {
+		level, typ = unix.IPPROTO_IP, unix.IP_TOS
+	}
+
+	b := make([]byte, unix.CmsgSpace(4))
+	h := (*unix.Cmsghdr)(unsafe.Pointer(&b[0]))
+	h.Level = int32(level)
+	h.Type = int32(typ)
+	h.SetLen(unix.CmsgLen(4))
+	*(*int32)(unsafe.Pointer(&b[unix.CmsgLen(0)])) = int32(tos)
+	return b
+}
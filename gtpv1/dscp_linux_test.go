@@ -0,0 +1,141 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package gtpv1_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	v1 "github.com/wmnsk/go-gtp/gtpv1"
+)
+
+func TestDSCP(t *testing.T) {
+	srv, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.37"), Port: 2152})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	rc, err := srv.SyscallConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var serr error
+	if err := rc.Control(func(fd uintptr) {
+		serr = unix.SetsockoptInt(int(fd), unix.IPPROTO_IP, unix.IP_RECVTOS, 1)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if serr != nil {
+		t.Fatal(serr)
+	}
+
+	cliPC, err := net.ListenPacket("udp", "127.0.0.38:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cliConn := v1.NewUPlaneConnWithPacketConn(cliPC)
+	defer cliConn.Close()
+
+	if err := cliConn.SetDSCP(0x11111111, 0x40); err != v1.ErrInvalidDSCP {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := cliConn.SetDSCP(0x11111111, 46); err != nil {
+		t.Fatal(err)
+	}
+	if err := cliConn.SetDSCP(0x22222222, 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := cliConn.EnableDSCPReflection(0x22222222); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		// DSCP 26 in IPv4 header and 34 in IPv6 header.
+		v4Packet = []byte{0x45, 0x68, 0x00, 0x14}
+		v6Packet = []byte{0x68, 0x80, 0x00, 0x00}
+		nonIP    = []byte{0xde, 0xad, 0xbe, 0xef}
+	)
+	cases := []struct {
+		description string
+		teid        uint32
+		payload     []byte
+		tos         uint8
+	}{
+		{"Marked", 0x11111111, v4Packet, 46 << 2},
+		{"ReflectedIPv4", 0x22222222, v4Packet, 26 << 2},
+		{"ReflectedIPv6", 0x22222222, v6Packet, 34 << 2},
+		{"ReflectedNonIP", 0x22222222, nonIP, 10 << 2},
+		{"NotMarked", 0x33333333, v4Packet, 0},
+	}
+
+	readTOS := func() uint8 {
+		t.Helper()
+		if err := srv.SetReadDeadline(time.Now().Add(3 * time.Second)); err != nil {
+			t.Fatal(err)
+		}
+		b, oob := make([]byte, 1500), make([]byte, 128)
+		_, oobn, _, _, err := srv.ReadMsgUDP(b, oob)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cmsgs, err := unix.ParseSocketControlMessage(oob[:oobn])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range cmsgs {
+			if c.Header.Level == unix.IPPROTO_IP && c.Header.Type == unix.IP_TOS && len(c.Data) > 0 {
+				return c.Data[0]
+			}
+		}
+		t.Fatal("no TOS in control messages")
+		return 0
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if _, err := cliConn.WriteToGTP(c.teid, c.payload, srv.LocalAddr()); err != nil {
+				t.Fatal(err)
+			}
+			if got := readTOS(); got != c.tos {
+				t.Errorf("wrong TOS. want: %#02x, got: %#02x", c.tos, got)
+			}
+
+			b := v1.GetBuffer()
+			copy(b.Extend(len(c.payload)), c.payload)
+			if _, err := cliConn.WriteBufferToGTP(c.teid, b, srv.LocalAddr()); err != nil {
+				t.Fatal(err)
+			}
+			if got := readTOS(); got != c.tos {
+				t.Errorf("wrong TOS with Buffer. want: %#02x, got: %#02x", c.tos, got)
+			}
+		})
+	}
+
+	// the T-PDUs written at once are marked as well.
+	cliConn.EnableBatchIO(8)
+	if _, err := cliConn.WriteBatchToGTP(0x22222222, [][]byte{v4Packet, v6Packet}, srv.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []uint8{26 << 2, 34 << 2} {
+		if got := readTOS(); got != want {
+			t.Errorf("wrong TOS in batch. want: %#02x, got: %#02x", want, got)
+		}
+	}
+
+	cliConn.ClearDSCP(0x11111111)
+	if _, err := cliConn.WriteToGTP(0x11111111, v4Packet, srv.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+	if got := readTOS(); got != 0 {
+		t.Errorf("TOS not cleared: %#02x", got)
+	}
+}
@@ -0,0 +1,17 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+//go:build !linux
+// +build !linux
+
+package gtpv1
+
+import "net"
+
+const dscpSupported = false
+
+// tosControlMessage always returns nil, as DSCP marking is available only on Linux.
+func tosControlMessage(tos uint8, dst net.IP) []byte {
+	return nil
+}
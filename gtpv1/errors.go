@@ -26,6 +26,32 @@ var (
 	// ErrConnNotOpened indicates that some operation is failed due to the status of
 	// Conn is not valid.
 	ErrConnNotOpened = errors.New("connection is not opened")
+
+	// ErrRequiredIEMissing indicates that the IE required to handle the incoming
+	// message is missing.
+	ErrRequiredIEMissing = errors.New("required IE missing")
+
+	// ErrInvalidNSAPI indicates that the NSAPI is out of the range for PDP contexts.
+	ErrInvalidNSAPI = errors.New("invalid NSAPI")
+
+	// ErrPDPContextAlreadyExists indicates that the PDP context with the same NSAPI,
+	// or the primary one, already exists in the Session.
+	ErrPDPContextAlreadyExists = errors.New("PDP context already exists")
+
+	// ErrLinkedPDPContextNotFound indicates that the primary PDP context that the
+	// secondary one is linked to is not found in the Session.
+	ErrLinkedPDPContextNotFound = errors.New("linked PDP context not found")
+
+	// ErrInvalidDSCP indicates that the DSCP is out of the range of 6 bits.
+	ErrInvalidDSCP = errors.New("invalid DSCP")
+
+	// ErrNoResponse indicates that no response is received for the request sent
+	// within the retransmission timer and count.
+	ErrNoResponse = errors.New("no response received")
+
+	// ErrVersionNotSupported indicates that the peer responded with Version Not
+	// Supported to the message sent.
+	ErrVersionNotSupported = errors.New("version not supported by peer")
 )
 
 // ErrorIndicatedError indicates that Error Indication message is received on U-Plane Connection.
@@ -0,0 +1,417 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv1
+
+import (
+	"encoding/binary"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/wmnsk/go-gtp/gtpv0"
+	v0ie "github.com/wmnsk/go-gtp/gtpv0/ie"
+	v0message "github.com/wmnsk/go-gtp/gtpv0/message"
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
+)
+
+// the ports used by FallbackClient, which are GTPCPort and gtpv0.GTPPort.
+const (
+	fallbackV1Port = 2123
+	fallbackV0Port = 3386
+)
+
+// FallbackSession is the PDP context created by FallbackClient, which keeps the
+// mapping between the GTPv1 TEIDs and the GTPv0 TID and flow labels.
+//
+// The flow labels are valid only if Version is 0.
+type FallbackSession struct {
+	// Version is the version of GTP the PDP context is created with.
+	Version int
+
+	IMSI  string
+	NSAPI uint8
+	TID   uint64
+
+	// TEIDCPlane and TEIDDataI are the ones in CreatePDPContextRequest given by
+	// the caller, which are mapped to FlowLabelSignalling and FlowLabelDataI in GTPv0.
+	TEIDCPlane          uint32
+	TEIDDataI           uint32
+	FlowLabelSignalling uint16
+	FlowLabelDataI      uint16
+
+	// PeerFlowLabelSignalling and PeerFlowLabelDataI are the ones assigned by the
+	// GGSN, which are also put in the TEID IEs in the response converted to GTPv1.
+	PeerFlowLabelSignalling uint16
+	PeerFlowLabelDataI      uint16
+}
+
+// FallbackClient sends Create PDP Context Request in GTPv1-C, and falls back to
+// GTPv0 on port 3386 if the GGSN does not respond or responds with Version Not
+// Supported. The peers that fell back are remembered, and the requests to them are
+// sent in GTPv0 from the beginning afterwards.
+//
+// The response in GTPv0 is converted into GTPv1 so that the caller does not have
+// to care about which version is used. The T-PDUs of the PDP contexts created in
+// GTPv0 should be exchanged with gtpv0.UPlaneConn, using the TID in FallbackSession.
+//
+// The requests are sent one at a time, and the messages other than the expected
+// response are discarded while waiting for it.
+type FallbackClient struct {
+	// T3 is the time to wait for a response, and N3 is the number of times
+	// a request is sent before it is given up, in each version.
+	T3 time.Duration
+	N3 int
+
+	mu        sync.Mutex
+	v1Conn    net.PacketConn
+	v0Conn    net.PacketConn
+	seq       uint16
+	label     uint16
+	v0Peers   map[string]struct{}
+	sessions  map[uint64]*FallbackSession
+	closeOnce sync.Once
+}
+
+// NewFallbackClient creates a new FallbackClient that sends GTPv1-C from port 2123
+// and GTPv0 from port 3386 of the IP given.
+//
+// As GTPv0 uses port 3386 for both C-Plane and U-Plane, gtpv0.UPlaneConn should be
+// opened on the other IP, or use NewFallbackClientWithPacketConns.
+func NewFallbackClient(ip net.IP) (*FallbackClient, error) {
+	v1Conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: ip, Port: fallbackV1Port})
+	if err != nil {
+		return nil, err
+	}
+	v0Conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: ip, Port: fallbackV0Port})
+	if err != nil {
+		_ = v1Conn.Close()
+		return nil, err
+	}
+
+	return NewFallbackClientWithPacketConns(v1Conn, v0Conn), nil
+}
+
+// NewFallbackClientWithPacketConns creates a new FallbackClient over the net.PacketConns
+// given, instead of opening the UDP sockets by itself.
+func NewFallbackClientWithPacketConns(v1Conn, v0Conn net.PacketConn) *FallbackClient {
+	return &FallbackClient{
+		T3:       3 * time.Second,
+		N3:       3,
+		v1Conn:   v1Conn,
+		v0Conn:   v0Conn,
+		v0Peers:  map[string]struct{}{},
+		sessions: map[uint64]*FallbackSession{},
+	}
+}
+
+// Close closes the underlying connections.
+func (f *FallbackClient) Close() error {
+	var err error
+	f.closeOnce.Do(func() {
+		if e := f.v1Conn.Close(); e != nil {
+			err = e
+		}
+		if e := f.v0Conn.Close(); e != nil {
+			err = e
+		}
+	})
+	return err
+}
+
+// CreatePDPContext sends CreatePDPContextRequest to the GGSN with the IP given,
+// and returns the response and the FallbackSession created.
+//
+// req must have IMSI and NSAPI IEs, which are used to make the TID in GTPv0. The
+// sequence number in req is overwritten. If it falls back to GTPv0, the response
+// is the one converted from CreatePDPContextResponse in GTPv0, which has the flow
+// labels assigned by the GGSN in TEIDDataI and TEIDCPlane IEs.
+func (f *FallbackClient) CreatePDPContext(ggsn net.IP, req *message.CreatePDPContextRequest) (*message.CreatePDPContextResponse, *FallbackSession, error) {
+	s, err := newFallbackSession(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.v0Peers[ggsn.String()]; !ok {
+		res, err := f.createPDPContextV1(ggsn, req)
+		switch err {
+		case nil:
+			s.Version = 1
+			f.sessions[s.TID] = s
+			return res, s.copy(), nil
+		case ErrNoResponse, ErrVersionNotSupported:
+			logf("falling back to GTPv0 for %s: %s", ggsn, err)
+			f.v0Peers[ggsn.String()] = struct{}{}
+		default:
+			return nil, nil, err
+		}
+	}
+
+	res, err := f.createPDPContextV0(ggsn, req, s)
+	if err != nil {
+		return nil, nil, err
+	}
+	f.sessions[s.TID] = s
+	return res, s.copy(), nil
+}
+
+// Session returns a copy of the FallbackSession with the IMSI and NSAPI given.
+func (f *FallbackClient) Session(imsi string, nsapi uint8) (*FallbackSession, bool) {
+	tid, err := gtpv0.NewTID(imsi, nsapi)
+	if err != nil {
+		return nil, false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.sessions[tid]
+	if !ok {
+		return nil, false
+	}
+	return s.copy(), true
+}
+
+// RemoveSession removes the FallbackSession with the IMSI and NSAPI given, e.g.,
+// after the PDP context is deleted.
+func (f *FallbackClient) RemoveSession(imsi string, nsapi uint8) {
+	tid, err := gtpv0.NewTID(imsi, nsapi)
+	if err != nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.sessions, tid)
+}
+
+// IsFallenBack reports whether the GGSN with the IP given is known to support only GTPv0.
+func (f *FallbackClient) IsFallenBack(ggsn net.IP) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, ok := f.v0Peers[ggsn.String()]
+	return ok
+}
+
+func newFallbackSession(req *message.CreatePDPContextRequest) (*FallbackSession, error) {
+	if req.IMSI == nil || req.NSAPI == nil {
+		return nil, ErrRequiredIEMissing
+	}
+
+	s := &FallbackSession{}
+	var err error
+	if s.IMSI, err = req.IMSI.IMSI(); err != nil {
+		return nil, err
+	}
+	if s.NSAPI, err = req.NSAPI.NSAPI(); err != nil {
+		return nil, err
+	}
+	if s.TID, err = gtpv0.NewTID(s.IMSI, s.NSAPI); err != nil {
+		return nil, err
+	}
+	if i := req.TEIDCPlane; i != nil {
+		if s.TEIDCPlane, err = i.TEID(); err != nil {
+			return nil, err
+		}
+	}
+	if i := req.TEIDDataI; i != nil {
+		if s.TEIDDataI, err = i.TEID(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *FallbackSession) copy() *FallbackSession {
+	c := *s
+	return &c
+}
+
+func (f *FallbackClient) nextSeq() uint16 {
+	f.seq++
+	return f.seq
+}
+
+// nextLabel returns the flow label that is not zero, as zero is used for the
+// messages without any PDP context.
+func (f *FallbackClient) nextLabel() uint16 {
+	f.label++
+	if f.label == 0 {
+		f.label++
+	}
+	return f.label
+}
+
+func (f *FallbackClient) createPDPContextV1(ggsn net.IP, req *message.CreatePDPContextRequest) (*message.CreatePDPContextResponse, error) {
+	seq := f.nextSeq()
+	req.SetSequenceNumber(seq)
+	b, err := req.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	raddr := &net.UDPAddr{IP: ggsn, Port: fallbackV1Port}
+	rb, err := f.transact(f.v1Conn, raddr, b, func(rb []byte) (bool, error) {
+		// the GGSN that does not support GTPv1 may respond in GTPv0 header.
+		if len(rb) >= 2 && rb[1] == message.MsgTypeVersionNotSupported {
+			return true, ErrVersionNotSupported
+		}
+		return len(rb) >= 12 && rb[0]>>5 == 1 && rb[0]&0x02 != 0 &&
+			rb[1] == message.MsgTypeCreatePDPContextResponse &&
+			binary.BigEndian.Uint16(rb[8:10]) == seq, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return message.ParseCreatePDPContextResponse(rb)
+}
+
+func (f *FallbackClient) createPDPContextV0(ggsn net.IP, req *message.CreatePDPContextRequest, s *FallbackSession) (*message.CreatePDPContextResponse, error) {
+	s.Version = 0
+	s.FlowLabelSignalling = f.nextLabel()
+	s.FlowLabelDataI = f.nextLabel()
+
+	seq := f.nextSeq()
+	b, err := toV0CreatePDPContextRequest(req, s, seq).Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	raddr := &net.UDPAddr{IP: ggsn, Port: fallbackV0Port}
+	rb, err := f.transact(f.v0Conn, raddr, b, func(rb []byte) (bool, error) {
+		return len(rb) >= 20 && rb[0]>>5 == 0 &&
+			rb[1] == v0message.MsgTypeCreatePDPContextResponse &&
+			binary.BigEndian.Uint16(rb[4:6]) == seq &&
+			binary.BigEndian.Uint64(rb[12:20]) == s.TID, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := v0message.ParseCreatePDPContextResponse(rb)
+	if err != nil {
+		return nil, err
+	}
+	if i := res.FlowLabelSignalling; i != nil {
+		if s.PeerFlowLabelSignalling, err = i.FlowLabelSignalling(); err != nil {
+			return nil, err
+		}
+	}
+	if i := res.FlowLabelDataI; i != nil {
+		if s.PeerFlowLabelDataI, err = i.FlowLabelDataI(); err != nil {
+			return nil, err
+		}
+	}
+
+	return toV1CreatePDPContextResponse(res, req), nil
+}
+
+// transact sends b to raddr and waits for the packet that matches with fn, which
+// may return an error to stop waiting, retransmitting b every T3 up to N3 times.
+func (f *FallbackClient) transact(pc net.PacketConn, raddr *net.UDPAddr, b []byte, fn func([]byte) (bool, error)) ([]byte, error) {
+	buf := make([]byte, 1600)
+	for i := 0; i < f.N3; i++ {
+		if _, err := pc.WriteTo(b, raddr); err != nil {
+			return nil, err
+		}
+		if err := pc.SetReadDeadline(time.Now().Add(f.T3)); err != nil {
+			return nil, err
+		}
+
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				if nerr, ok := err.(net.Error); ok && nerr.Timeout() {
+					break
+				}
+				return nil, err
+			}
+
+			if ua, ok := addr.(*net.UDPAddr); !ok || !ua.IP.Equal(raddr.IP) {
+				continue
+			}
+			ok, err := fn(buf[:n])
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+
+			if err := pc.SetReadDeadline(time.Time{}); err != nil {
+				return nil, err
+			}
+			rb := make([]byte, n)
+			copy(rb, buf[:n])
+			return rb, nil
+		}
+	}
+	return nil, ErrNoResponse
+}
+
+// toV0CreatePDPContextRequest converts CreatePDPContextRequest in GTPv1 into GTPv0.
+// The IEs that have the same format in both versions are copied as they are.
+func toV0CreatePDPContextRequest(req *message.CreatePDPContextRequest, s *FallbackSession, seq uint16) *v0message.CreatePDPContextRequest {
+	ies := []*v0ie.IE{
+		v0ie.NewFlowLabelDataI(s.FlowLabelDataI),
+		v0ie.NewFlowLabelSignalling(s.FlowLabelSignalling),
+	}
+	for _, i := range []*ie.IE{
+		req.RAI, req.Recovery, req.SelectionMode, req.EndUserAddress, req.APN, req.PCO,
+		req.SGSNAddressForSignalling, req.SGSNAddressForUserTraffic, req.MSISDN,
+	} {
+		if i != nil {
+			ies = append(ies, v0ie.New(i.Type, i.Payload))
+		}
+	}
+
+	// QoS Profile in GTPv1 has Allocation/Retention Priority before the one in GTPv0.
+	if i := req.QoSProfile; i != nil && len(i.Payload) >= 4 {
+		ies = append(ies, v0ie.New(v0ie.QualityOfServiceProfile, i.Payload[1:4]))
+	}
+
+	return v0message.NewCreatePDPContextRequest(seq, 0, s.TID, ies...)
+}
+
+// toV1CreatePDPContextResponse converts CreatePDPContextResponse in GTPv0 into GTPv1,
+// with the TEID and sequence number that match with req.
+func toV1CreatePDPContextResponse(res *v0message.CreatePDPContextResponse, req *message.CreatePDPContextRequest) *message.CreatePDPContextResponse {
+	var teid uint32
+	if req.TEIDCPlane != nil {
+		teid = req.TEIDCPlane.MustTEID()
+	}
+
+	var ies []*ie.IE
+	for _, i := range []*v0ie.IE{
+		res.Cause, res.ReorderingRequired, res.Recovery, res.ChargingID, res.EndUserAddress,
+		res.PCO, res.GGSNAddressForSignalling, res.GGSNAddressForUserTraffic, res.ChargingGatewayAddress,
+	} {
+		if i != nil {
+			ies = append(ies, ie.New(i.Type, i.Payload))
+		}
+	}
+	if i := res.FlowLabelDataI; i != nil {
+		ies = append(ies, ie.NewTEIDDataI(uint32(i.MustFlowLabelDataI())))
+	}
+	if i := res.FlowLabelSignalling; i != nil {
+		ies = append(ies, ie.NewTEIDCPlane(uint32(i.MustFlowLabelSignalling())))
+	}
+	if i := res.QoSProfile; i != nil {
+		// take Allocation/Retention Priority from the request, as GTPv0 does not have it.
+		var arp uint8
+		if q := req.QoSProfile; q != nil && len(q.Payload) > 0 {
+			arp = q.Payload[0]
+		}
+		ies = append(ies, ie.NewQoSProfile(append([]byte{arp}, i.Payload...)))
+	}
+
+	return message.NewCreatePDPContextResponse(teid, req.Sequence(), ies...)
+}
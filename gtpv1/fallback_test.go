@@ -0,0 +1,236 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv1_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	v0ie "github.com/wmnsk/go-gtp/gtpv0/ie"
+	v0message "github.com/wmnsk/go-gtp/gtpv0/message"
+	v1 "github.com/wmnsk/go-gtp/gtpv1"
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
+)
+
+const (
+	fallbackIMSI  = "123451234567890"
+	fallbackNSAPI = 5
+)
+
+func newFallbackClient(t *testing.T) *v1.FallbackClient {
+	t.Helper()
+
+	v1Conn, err := net.ListenPacket("udp", "127.0.0.48:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v0Conn, err := net.ListenPacket("udp", "127.0.0.48:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := v1.NewFallbackClientWithPacketConns(v1Conn, v0Conn)
+	f.T3 = 100 * time.Millisecond
+	f.N3 = 2
+	return f
+}
+
+func newFallbackRequest() *message.CreatePDPContextRequest {
+	return message.NewCreatePDPContextRequest(
+		0, 0,
+		ie.NewIMSI(fallbackIMSI),
+		ie.NewSelectionMode(0xf0),
+		ie.NewTEIDDataI(0x11111111),
+		ie.NewTEIDCPlane(0x22222222),
+		ie.NewNSAPI(fallbackNSAPI),
+		ie.NewAccessPointName("some.apn.example"),
+		ie.NewGSNAddress("127.0.0.48"),
+		ie.NewGSNAddress("127.0.0.48"),
+		ie.NewQoSProfile([]byte{0x01, 0x23, 0x45, 0x67}),
+	)
+}
+
+// serveGGSN responds to the requests on the address given with the function given,
+// until pc is closed.
+func serveGGSN(t *testing.T, addr string, fn func(b []byte) []byte) net.PacketConn {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		buf := make([]byte, 1600)
+		for {
+			n, raddr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if res := fn(buf[:n]); res != nil {
+				if _, err := pc.WriteTo(res, raddr); err != nil {
+					return
+				}
+			}
+		}
+	}()
+	return pc
+}
+
+func respondV0(t *testing.T) func(b []byte) []byte {
+	return func(b []byte) []byte {
+		req, err := v0message.ParseCreatePDPContextRequest(b)
+		if err != nil {
+			t.Error(err)
+			return nil
+		}
+		if q := req.QoSProfile; q == nil || len(q.Payload) != 3 || q.Payload[0] != 0x23 {
+			t.Errorf("wrong QoS Profile in GTPv0 request: %v", q)
+		}
+
+		res, err := v0message.NewCreatePDPContextResponse(
+			req.SequenceNumber, req.FlowLabelSignalling.MustFlowLabelSignalling(), req.Header.TID,
+			v0ie.NewCause(v1.ResCauseRequestAccepted),
+			v0ie.NewQualityOfServiceProfile(1, 2, 3, 4, 5),
+			v0ie.NewFlowLabelDataI(0x3333),
+			v0ie.NewFlowLabelSignalling(0x4444),
+			v0ie.NewEndUserAddress("10.10.10.10"),
+			v0ie.NewGSNAddress("127.0.0.50"),
+			v0ie.NewGSNAddress("127.0.0.50"),
+		).Marshal()
+		if err != nil {
+			t.Error(err)
+			return nil
+		}
+		return res
+	}
+}
+
+func TestFallbackClientV1(t *testing.T) {
+	f := newFallbackClient(t)
+	defer f.Close()
+
+	ggsn := serveGGSN(t, "127.0.0.49:2123", func(b []byte) []byte {
+		req, err := message.ParseCreatePDPContextRequest(b)
+		if err != nil {
+			t.Error(err)
+			return nil
+		}
+		res, err := message.NewCreatePDPContextResponse(
+			req.TEIDCPlane.MustTEID(), req.Sequence(),
+			ie.NewCause(v1.ResCauseRequestAccepted),
+			ie.NewTEIDDataI(0x33333333),
+			ie.NewTEIDCPlane(0x44444444),
+		).Marshal()
+		if err != nil {
+			t.Error(err)
+			return nil
+		}
+		return res
+	})
+	defer ggsn.Close()
+
+	res, s, err := f.CreatePDPContext(net.ParseIP("127.0.0.49"), newFallbackRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Version != 1 {
+		t.Errorf("wrong version, want: 1, got: %d", s.Version)
+	}
+	if got := res.TEIDDataI.MustTEID(); got != 0x33333333 {
+		t.Errorf("wrong TEIDDataI, want: %#x, got: %#x", 0x33333333, got)
+	}
+	if f.IsFallenBack(net.ParseIP("127.0.0.49")) {
+		t.Error("unexpected fallback")
+	}
+}
+
+func TestFallbackClientVersionNotSupported(t *testing.T) {
+	f := newFallbackClient(t)
+	defer f.Close()
+
+	v1GGSN := serveGGSN(t, "127.0.0.50:2123", func(b []byte) []byte {
+		res, err := v0message.NewGeneric(
+			v0message.MsgTypeVersionNotSupported, 0, 0, 0,
+		).Marshal()
+		if err != nil {
+			t.Error(err)
+			return nil
+		}
+		return res
+	})
+	defer v1GGSN.Close()
+	v0GGSN := serveGGSN(t, "127.0.0.50:3386", respondV0(t))
+	defer v0GGSN.Close()
+
+	ggsnIP := net.ParseIP("127.0.0.50")
+	for i := 0; i < 2; i++ {
+		res, s, err := f.CreatePDPContext(ggsnIP, newFallbackRequest())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if s.Version != 0 {
+			t.Errorf("wrong version, want: 0, got: %d", s.Version)
+		}
+		if s.TID != 0x2143153254769850 {
+			t.Errorf("wrong TID: %#016x", s.TID)
+		}
+		if s.PeerFlowLabelDataI != 0x3333 || s.PeerFlowLabelSignalling != 0x4444 {
+			t.Errorf("wrong flow labels: %#x, %#x", s.PeerFlowLabelDataI, s.PeerFlowLabelSignalling)
+		}
+
+		if got := res.TEID(); got != 0x22222222 {
+			t.Errorf("wrong TEID in header, want: %#x, got: %#x", 0x22222222, got)
+		}
+		if got := res.Cause.MustCause(); got != v1.ResCauseRequestAccepted {
+			t.Errorf("wrong Cause: %d", got)
+		}
+		if got := res.TEIDDataI.MustTEID(); got != 0x3333 {
+			t.Errorf("wrong TEIDDataI, want: %#x, got: %#x", 0x3333, got)
+		}
+		if got := res.QoSProfile.MustQoSProfile(); len(got) != 4 || got[0] != 0x01 {
+			t.Errorf("wrong QoS Profile: %x", got)
+		}
+		if got := res.GGSNAddressForUserTraffic.MustIPAddress(); got != "127.0.0.50" {
+			t.Errorf("wrong GGSN Address: %s", got)
+		}
+	}
+
+	if !f.IsFallenBack(ggsnIP) {
+		t.Error("fallback is not remembered")
+	}
+	if _, ok := f.Session(fallbackIMSI, fallbackNSAPI); !ok {
+		t.Error("session not found")
+	}
+	f.RemoveSession(fallbackIMSI, fallbackNSAPI)
+	if _, ok := f.Session(fallbackIMSI, fallbackNSAPI); ok {
+		t.Error("session not removed")
+	}
+}
+
+func TestFallbackClientNoResponse(t *testing.T) {
+	f := newFallbackClient(t)
+	defer f.Close()
+
+	v0GGSN := serveGGSN(t, "127.0.0.51:3386", respondV0(t))
+	defer v0GGSN.Close()
+
+	_, s, err := f.CreatePDPContext(net.ParseIP("127.0.0.51"), newFallbackRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Version != 0 {
+		t.Errorf("wrong version, want: 0, got: %d", s.Version)
+	}
+
+	// no one responds in both versions.
+	if _, _, err := f.CreatePDPContext(net.ParseIP("127.0.0.52"), newFallbackRequest()); err != v1.ErrNoResponse {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -7,7 +7,6 @@ package gtpv1
 import (
 	"net"
 	"sync"
-	"time"
 
 	"github.com/wmnsk/go-gtp/gtpv1/ie"
 	"github.com/wmnsk/go-gtp/gtpv1/message"
@@ -42,14 +41,20 @@ func newMsgHandlerMap(m map[uint8]HandlerFunc) *msgHandlerMap {
 	return mhm
 }
 
-var defaultHandlerMap = newMsgHandlerMap(
-	map[uint8]HandlerFunc{
-		message.MsgTypeTPDU:            handleTPDU,
-		message.MsgTypeEchoRequest:     handleEchoRequest,
-		message.MsgTypeEchoResponse:    handleEchoResponse,
-		message.MsgTypeErrorIndication: handleErrorIndication,
-	},
-)
+// newDefaultMsgHandlerMap returns the msgHandlerMap with the default handlers,
+// which is created for each connection so that the handlers added to one do not
+// affect the others.
+func newDefaultMsgHandlerMap() *msgHandlerMap {
+	return newMsgHandlerMap(
+		map[uint8]HandlerFunc{
+			message.MsgTypeTPDU:            handleTPDU,
+			message.MsgTypeEchoRequest:     handleEchoRequest,
+			message.MsgTypeEchoResponse:    handleEchoResponse,
+			message.MsgTypeErrorIndication: handleErrorIndication,
+			message.MsgTypeEndMarker:       handleEndMarker,
+		},
+	)
+}
 
 // handleTPDU responds to sender with ErrorIndication by default.
 // By disabling it(DisableErrorIndication), it passes unhandled T-PDU to
@@ -68,6 +73,7 @@ func handleTPDU(c Conn, senderAddr net.Addr, msg message.Message) error {
 	}
 
 	if u.errIndEnabled {
+		u.countDropped(pdu.TEID())
 		if err := u.ErrorIndication(senderAddr, pdu); err != nil {
 			logf("failed to send Error Indication to %s: %v", senderAddr, err)
 		}
@@ -81,16 +87,7 @@ func handleTPDU(c Conn, senderAddr net.Addr, msg message.Message) error {
 		payload: pdu.Payload,
 	}
 
-	// wait for the T-PDU passed to u.tpduCh to be read by ReadFromGTP.
-	// if it got stuck for 3 seconds, it discards the T-PDU received.
-	go func() {
-		select {
-		case u.tpduCh <- tpdu:
-			return
-		case <-time.After(3 * time.Second):
-			return
-		}
-	}()
+	u.passTPDU(tpdu, pdu.HasSequence())
 	return nil
 }
 
@@ -118,6 +115,9 @@ func handleEchoResponse(c Conn, senderAddr net.Addr, msg message.Message) error
 	return nil
 }
 
+// handleErrorIndication looks up the tunnel affected by Error Indication, calls
+// the function set by SetErrorIndicationHandler, and removes the tunnel if
+// EnableTeardownOnErrorIndication is called.
 func handleErrorIndication(c Conn, senderAddr net.Addr, msg message.Message) error {
 	// this should never happen, as the type should have been assured by
 	// msgHandlerMap before this function is called.
@@ -126,9 +126,71 @@ func handleErrorIndication(c Conn, senderAddr net.Addr, msg message.Message) err
 		return ErrUnexpectedType
 	}
 
+	if ind.TEIDDataI == nil || ind.GTPUPeerAddress == nil {
+		return ErrRequiredIEMissing
+	}
+	teid, err := ind.TEIDDataI.TEID()
+	if err != nil {
+		return err
+	}
+	peer, err := ind.GTPUPeerAddress.IPAddress()
+	if err != nil {
+		return err
+	}
+	indErr := &ErrorIndicatedError{TEID: teid, Peer: peer}
+
+	u, ok := c.(*UPlaneConn)
+	if !ok {
+		return indErr
+	}
+
+	itei, teardown, found := u.tunnelByOTEI(teid, peer)
+
+	u.mu.Lock()
+	fn := u.errIndFn
+	teardownEnabled := u.errIndTeardown
+	u.mu.Unlock()
+
+	if fn != nil {
+		fn(u, senderAddr, indErr, itei, found)
+	}
+	if found && teardownEnabled {
+		if err := teardown(); err != nil {
+			logf("failed to remove tunnel %#x on Error Indication: %v", itei, err)
+		}
+	}
+
 	// let's just return err anyway.
-	return &ErrorIndicatedError{
-		TEID: ind.TEIDDataI.MustTEID(),
-		Peer: ind.GTPUPeerAddress.MustIPAddress(),
+	return indErr
+}
+
+// handleEndMarker forwards End Marker to the peer if the TEID is relayed, and
+// calls the function set by SetEndMarkerHandler.
+func handleEndMarker(c Conn, senderAddr net.Addr, msg message.Message) error {
+	// this should never happen, as the type should have been assured by
+	// msgHandlerMap before this function is called.
+	if _, ok := msg.(*message.EndMarker); !ok {
+		return ErrUnexpectedType
 	}
+
+	u, ok := c.(*UPlaneConn)
+	if !ok {
+		return ErrInvalidConnection
+	}
+
+	u.mu.Lock()
+	peer, relayed := u.relayMap[msg.TEID()]
+	fn := u.endMarkerFn
+	u.mu.Unlock()
+
+	if relayed {
+		if err := peer.srcConn.SendEndMarker(peer.teid, peer.addr); err != nil {
+			logf("failed to forward End Marker to %s: %v", peer.addr, err)
+		}
+	}
+
+	if fn != nil {
+		fn(u, senderAddr, msg.TEID())
+	}
+	return nil
 }
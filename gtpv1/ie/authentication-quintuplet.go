@@ -130,15 +130,20 @@ func (i *IE) MustIK() []byte {
 func (i *IE) AUTN() ([]byte, error) {
 	switch i.Type {
 	case AuthenticationQuintuplet:
-		if len(i.Payload) < 50 {
+		if len(i.Payload) < 17 {
 			return nil, io.ErrUnexpectedEOF
 		}
+
 		offset := 49 + int(i.Payload[16])
-		autnLen := i.Payload[50]
-		if len(i.Payload) < offset+int(autnLen) {
+		if len(i.Payload) < offset+1 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		autnLen := int(i.Payload[offset])
+		offset++
+		if len(i.Payload) < offset+autnLen {
 			return nil, io.ErrUnexpectedEOF
 		}
-		return i.Payload[offset : offset+int(autnLen)], nil
+		return i.Payload[offset : offset+autnLen], nil
 	default:
 		return nil, &InvalidTypeError{Type: i.Type}
 	}
@@ -0,0 +1,54 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package ie_test
+
+import (
+	"testing"
+
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+)
+
+func FuzzParse(f *testing.F) {
+	for _, c := range testIEs {
+		f.Add(c.serialized)
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		i, err := ie.Parse(b)
+		if err != nil {
+			return
+		}
+
+		if _, err := i.Marshal(); err != nil {
+			t.Fatalf("failed to marshal parsed IE: %v", err)
+		}
+		_ = i.String()
+	})
+}
+
+func FuzzParseMultiIEs(f *testing.F) {
+	var b []byte
+	for _, c := range testIEs {
+		f.Add(c.serialized)
+		b = append(b, c.serialized...)
+	}
+	f.Add(b)
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		ies, err := ie.ParseMultiIEs(b)
+		if err != nil {
+			return
+		}
+
+		for _, i := range ies {
+			if _, err := i.Marshal(); err != nil {
+				t.Fatalf("failed to marshal parsed IE: %v", err)
+			}
+		}
+	})
+}
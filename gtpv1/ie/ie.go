@@ -10,6 +10,8 @@ package ie
 import (
 	"encoding/binary"
 	"fmt"
+
+	"github.com/wmnsk/go-gtp/utils"
 )
 
 // TV IE definitions.
@@ -203,6 +205,18 @@ func (i *IE) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from an IE instance to b and
+// returns the extended buffer. b is reallocated only when its capacity is not
+// enough, so the buffers can be reused without allocating.
+func (i *IE) AppendTo(b []byte) ([]byte, error) {
+	l := len(b)
+	b = utils.Grow(b, i.MarshalLen())
+	if err := i.MarshalTo(b[l:]); err != nil {
+		return b[:l], err
+	}
+	return b, nil
+}
+
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (i *IE) MarshalTo(b []byte) error {
 	if len(b) < i.MarshalLen() {
@@ -211,7 +225,12 @@ func (i *IE) MarshalTo(b []byte) error {
 
 	var offset = 1
 	b[0] = i.Type
-	if !i.IsTV() {
+	switch {
+	case i.IsTV():
+	case i.hasOneOctetLength():
+		b[1] = uint8(i.Length)
+		offset++
+	default:
 		binary.BigEndian.PutUint16(b[1:3], i.Length)
 		offset += 2
 	}
@@ -238,6 +257,9 @@ func (i *IE) UnmarshalBinary(b []byte) error {
 	if i.IsTV() {
 		return decodeTVFromBytes(i, b)
 	}
+	if i.hasOneOctetLength() {
+		return decodeOneOctetLengthTLVFromBytes(i, b)
+	}
 	return decodeTLVFromBytes(i, b)
 }
 
@@ -271,6 +293,21 @@ func decodeTLVFromBytes(i *IE, b []byte) error {
 	return nil
 }
 
+func decodeOneOctetLengthTLVFromBytes(i *IE, b []byte) error {
+	l := len(b)
+	if l < 2 {
+		return ErrTooShortToParse
+	}
+
+	i.Length = uint16(b[1])
+	if int(i.Length)+2 > l {
+		return ErrInvalidLength
+	}
+
+	i.Payload = b[2 : 2+int(i.Length)]
+	return nil
+}
+
 var tvLengthMap = map[int]int{
 	0:   0,  // Reserved
 	1:   1,  // Cause
@@ -307,6 +344,12 @@ func (i *IE) IsTV() bool {
 	return int(i.Type) < 0x80
 }
 
+// hasOneOctetLength reports whether the IE has the Length field of 1 octet, which
+// is the exceptional format of TLV used only by Extension Header Type List.
+func (i *IE) hasOneOctetLength() bool {
+	return i.Type == ExtensionHeaderTypeList
+}
+
 // MarshalLen returns the serial length of IE.
 func (i *IE) MarshalLen() int {
 	if l, ok := tvLengthMap[int(i.Type)]; ok {
@@ -315,6 +358,9 @@ func (i *IE) MarshalLen() int {
 	if i.Type < 128 {
 		return 1 + len(i.Payload)
 	}
+	if i.hasOneOctetLength() {
+		return 2 + len(i.Payload)
+	}
 
 	return 3 + len(i.Payload)
 }
@@ -5,6 +5,7 @@
 package ie_test
 
 import (
+	"net"
 	"testing"
 	"time"
 
@@ -13,173 +14,264 @@ import (
 	"github.com/wmnsk/go-gtp/gtpv1/ie"
 )
 
-func TestIEs(t *testing.T) {
-	cases := []struct {
-		description string
-		structured  *ie.IE
-		serialized  []byte
-	}{
-		{
-			"IMSI",
-			ie.NewIMSI("123451234567890"),
-			[]byte{0x02, 0x21, 0x43, 0x15, 0x32, 0x54, 0x76, 0x98, 0xf0},
-		}, {
-			"PacketTMSI",
-			ie.NewPacketTMSI(0xbeebee),
-			[]byte{0x05, 0x00, 0xbe, 0xeb, 0xee},
-		}, {
-			"AuthenticationTriplet",
-			ie.NewAuthenticationTriplet(
-				[]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
-				[]byte{0xde, 0xad, 0xbe, 0xef},
-				[]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77},
-			),
-			[]byte{
-				0x09,
-				0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
-				0xde, 0xad, 0xbe, 0xef,
-				0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77,
-			},
-		}, {
-			"MAPCause",
-			ie.NewMAPCause(v1.MAPCauseSystemFailure),
-			[]byte{0x0b, 0x22},
-		}, {
-			"PTMSISignature",
-			ie.NewPTMSISignature(0xbeebee),
-			[]byte{0x0c, 0xbe, 0xeb, 0xee},
-		}, {
-			"MSValidated",
-			ie.NewMSValidated(true),
-			[]byte{0x0d, 0xff},
-		}, {
-			"Recovery",
-			ie.NewRecovery(1),
-			[]byte{0x0e, 0x01},
-		}, {
-			"SelectionMode",
-			ie.NewSelectionMode(v1.SelectionModeMSorNetworkProvidedAPNSubscribedVerified),
-			[]byte{0x0f, 0xf0},
-		}, {
-			"TEIDDataI",
-			ie.NewTEIDDataI(0xdeadbeef),
-			[]byte{0x10, 0xde, 0xad, 0xbe, 0xef},
-		}, {
-			"TEIDCPlane",
-			ie.NewTEIDCPlane(0xdeadbeef),
-			[]byte{0x11, 0xde, 0xad, 0xbe, 0xef},
-		}, {
-			"TEIDDataII",
-			ie.NewTEIDDataII(0xdeadbeef),
-			[]byte{0x12, 0xde, 0xad, 0xbe, 0xef},
-		}, {
-			"TeardownInd",
-			ie.NewTeardownInd(true),
-			[]byte{0x13, 0xff},
-		}, {
-			"NSAPI",
-			ie.NewNSAPI(0x05),
-			[]byte{0x14, 0x05},
-		}, {
-			"RANAPCause",
-			ie.NewRANAPCause(v1.MAPCauseUnknownSubscriber),
-			[]byte{0x15, 0x01},
-		}, {
-			"EndUserAddress/v4",
-			ie.NewEndUserAddress("1.1.1.1"),
-			[]byte{0x80, 0x00, 0x06, 0xf1, 0x21, 0x01, 0x01, 0x01, 0x01},
-		}, {
-			"EndUserAddress/v6",
-			ie.NewEndUserAddress("2001::1"),
-			[]byte{
-				0x80, 0x00, 0x12, 0x00,
-				0x57, 0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
-			},
-		}, {
-			"AccessPointName",
-			ie.NewAccessPointName("some.apn.example"),
-			[]byte{
-				0x83, 0x00, 0x11,
-				0x04, 0x73, 0x6f, 0x6d, 0x65, 0x03, 0x61, 0x70, 0x6e, 0x07, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65,
-			},
-		}, {
-			"GSNAddressV4",
-			ie.NewGSNAddress("1.1.1.1"),
-			[]byte{0x85, 0x00, 0x04, 0x01, 0x01, 0x01, 0x01},
-		}, {
-			"GSNAddressV6",
-			ie.NewGSNAddress("2001::1"),
-			[]byte{
-				0x85, 0x00, 0x10,
-				0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
-			},
-		}, {
-			"MSISDN",
-			ie.NewMSISDN("818012345678"),
-			[]byte{0x86, 0x00, 0x07, 0x91, 0x18, 0x08, 0x21, 0x43, 0x65, 0x87},
-		}, {
-			"AuthenticationQuintuplet",
-			ie.NewAuthenticationQuintuplet(
-				[]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
-				[]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
-				[]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
-				[]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
-				[]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
-			),
-			[]byte{
-				0x88, 0x00, 0x52,
-				0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
-				0x10,
-				0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
-				0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
-				0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
-				0x10,
-				0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
-			},
-		}, {
-			"CommonFlags",
-			ie.NewCommonFlags(0, 1, 0, 0, 0, 0, 0, 0),
-			[]byte{0x94, 0x00, 0x01, 0x40},
-		}, {
-			"APNRestriction",
-			ie.NewAPNRestriction(v1.APNRestrictionPrivate1),
-			[]byte{0x95, 0x00, 0x01, 0x03},
-		}, {
-			"RATType",
-			ie.NewRATType(v1.RatTypeEUTRAN),
-			[]byte{0x97, 0x00, 0x01, 0x06},
-		}, {
-			"UserLocationInformationWithCGI",
-			ie.NewUserLocationInformationWithCGI("123", "45", 0xff, 0),
-			[]byte{0x98, 0x00, 0x08, 0x00, 0x21, 0xf3, 0x54, 0x00, 0xff, 0x00, 0x00},
-		}, {
-			"UserLocationInformationWithSAI",
-			ie.NewUserLocationInformationWithSAI("123", "45", 0xff, 0),
-			[]byte{0x98, 0x00, 0x08, 0x01, 0x21, 0xf3, 0x54, 0x00, 0xff, 0x00, 0x00},
-		}, {
-			"UserLocationInformationWithRAI",
-			ie.NewUserLocationInformationWithRAI("123", "45", 0xff, 0),
-			[]byte{0x98, 0x00, 0x07, 0x02, 0x21, 0xf3, 0x54, 0x00, 0xff, 0x00},
-		}, {
-			"MSTimeZone",
-			ie.NewMSTimeZone(9*time.Hour, 0), // XXX - should be updated with more realistic value
-			[]byte{0x99, 0x00, 0x02, 0x63, 0x00},
-		}, {
-			"IMEISV",
-			ie.NewIMEISV("123450123456789"),
-			[]byte{0x9a, 0x00, 0x08, 0x21, 0x43, 0x05, 0x21, 0x43, 0x65, 0x87, 0xf9},
-		}, {
-			"ULITimestamp",
-			ie.NewULITimestamp(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)),
-			[]byte{0xd6, 0x00, 0x04, 0xdf, 0xd5, 0x2c, 0x00},
-		}, {
-			"ChargingID",
-			ie.NewChargingID(0xffffffff),
-			[]byte{0x7f, 0xff, 0xff, 0xff, 0xff},
+var testIEs = []struct {
+	description string
+	structured  *ie.IE
+	serialized  []byte
+}{
+	{
+		"IMSI",
+		ie.NewIMSI("123451234567890"),
+		[]byte{0x02, 0x21, 0x43, 0x15, 0x32, 0x54, 0x76, 0x98, 0xf0},
+	}, {
+		"PacketTMSI",
+		ie.NewPacketTMSI(0xbeebee),
+		[]byte{0x05, 0x00, 0xbe, 0xeb, 0xee},
+	}, {
+		"AuthenticationTriplet",
+		ie.NewAuthenticationTriplet(
+			[]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
+			[]byte{0xde, 0xad, 0xbe, 0xef},
+			[]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77},
+		),
+		[]byte{
+			0x09,
+			0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
+			0xde, 0xad, 0xbe, 0xef,
+			0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77,
 		},
-	}
+	}, {
+		"MAPCause",
+		ie.NewMAPCause(v1.MAPCauseSystemFailure),
+		[]byte{0x0b, 0x22},
+	}, {
+		"PTMSISignature",
+		ie.NewPTMSISignature(0xbeebee),
+		[]byte{0x0c, 0xbe, 0xeb, 0xee},
+	}, {
+		"MSValidated",
+		ie.NewMSValidated(true),
+		[]byte{0x0d, 0xff},
+	}, {
+		"Recovery",
+		ie.NewRecovery(1),
+		[]byte{0x0e, 0x01},
+	}, {
+		"SelectionMode",
+		ie.NewSelectionMode(v1.SelectionModeMSorNetworkProvidedAPNSubscribedVerified),
+		[]byte{0x0f, 0xf0},
+	}, {
+		"TEIDDataI",
+		ie.NewTEIDDataI(0xdeadbeef),
+		[]byte{0x10, 0xde, 0xad, 0xbe, 0xef},
+	}, {
+		"TEIDCPlane",
+		ie.NewTEIDCPlane(0xdeadbeef),
+		[]byte{0x11, 0xde, 0xad, 0xbe, 0xef},
+	}, {
+		"TEIDDataII",
+		ie.NewTEIDDataII(0xdeadbeef),
+		[]byte{0x12, 0xde, 0xad, 0xbe, 0xef},
+	}, {
+		"TeardownInd",
+		ie.NewTeardownInd(true),
+		[]byte{0x13, 0xff},
+	}, {
+		"NSAPI",
+		ie.NewNSAPI(0x05),
+		[]byte{0x14, 0x05},
+	}, {
+		"RANAPCause",
+		ie.NewRANAPCause(v1.MAPCauseUnknownSubscriber),
+		[]byte{0x15, 0x01},
+	}, {
+		"EndUserAddress/v4",
+		ie.NewEndUserAddress("1.1.1.1"),
+		[]byte{0x80, 0x00, 0x06, 0xf1, 0x21, 0x01, 0x01, 0x01, 0x01},
+	}, {
+		"EndUserAddress/v6",
+		ie.NewEndUserAddress("2001::1"),
+		[]byte{
+			0x80, 0x00, 0x12, 0x00,
+			0x57, 0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+		},
+	}, {
+		"AccessPointName",
+		ie.NewAccessPointName("some.apn.example"),
+		[]byte{
+			0x83, 0x00, 0x11,
+			0x04, 0x73, 0x6f, 0x6d, 0x65, 0x03, 0x61, 0x70, 0x6e, 0x07, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65,
+		},
+	}, {
+		"GSNAddressV4",
+		ie.NewGSNAddress("1.1.1.1"),
+		[]byte{0x85, 0x00, 0x04, 0x01, 0x01, 0x01, 0x01},
+	}, {
+		"GSNAddressV6",
+		ie.NewGSNAddress("2001::1"),
+		[]byte{
+			0x85, 0x00, 0x10,
+			0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+		},
+	}, {
+		"MSISDN",
+		ie.NewMSISDN("818012345678"),
+		[]byte{0x86, 0x00, 0x07, 0x91, 0x18, 0x08, 0x21, 0x43, 0x65, 0x87},
+	}, {
+		"AuthenticationQuintuplet",
+		ie.NewAuthenticationQuintuplet(
+			[]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
+			[]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
+			[]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
+			[]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
+			[]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff},
+		),
+		[]byte{
+			0x88, 0x00, 0x52,
+			0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
+			0x10,
+			0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
+			0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
+			0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
+			0x10,
+			0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff,
+		},
+	}, {
+		"CommonFlags",
+		ie.NewCommonFlags(0, 1, 0, 0, 0, 0, 0, 0),
+		[]byte{0x94, 0x00, 0x01, 0x40},
+	}, {
+		"APNRestriction",
+		ie.NewAPNRestriction(v1.APNRestrictionPrivate1),
+		[]byte{0x95, 0x00, 0x01, 0x03},
+	}, {
+		"RATType",
+		ie.NewRATType(v1.RatTypeEUTRAN),
+		[]byte{0x97, 0x00, 0x01, 0x06},
+	}, {
+		"UserLocationInformationWithCGI",
+		ie.NewUserLocationInformationWithCGI("123", "45", 0xff, 0),
+		[]byte{0x98, 0x00, 0x08, 0x00, 0x21, 0xf3, 0x54, 0x00, 0xff, 0x00, 0x00},
+	}, {
+		"UserLocationInformationWithSAI",
+		ie.NewUserLocationInformationWithSAI("123", "45", 0xff, 0),
+		[]byte{0x98, 0x00, 0x08, 0x01, 0x21, 0xf3, 0x54, 0x00, 0xff, 0x00, 0x00},
+	}, {
+		"UserLocationInformationWithRAI",
+		ie.NewUserLocationInformationWithRAI("123", "45", 0xff, 0),
+		[]byte{0x98, 0x00, 0x07, 0x02, 0x21, 0xf3, 0x54, 0x00, 0xff, 0x00},
+	}, {
+		"MSTimeZone",
+		ie.NewMSTimeZone(9*time.Hour, 0), // XXX - should be updated with more realistic value
+		[]byte{0x99, 0x00, 0x02, 0x63, 0x00},
+	}, {
+		"IMEISV",
+		ie.NewIMEISV("123450123456789"),
+		[]byte{0x9a, 0x00, 0x08, 0x21, 0x43, 0x05, 0x21, 0x43, 0x65, 0x87, 0xf9},
+	}, {
+		"ULITimestamp",
+		ie.NewULITimestamp(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)),
+		[]byte{0xd6, 0x00, 0x04, 0xdf, 0xd5, 0x2c, 0x00},
+	}, {
+		"ChargingID",
+		ie.NewChargingID(0xffffffff),
+		[]byte{0x7f, 0xff, 0xff, 0xff, 0xff},
+	}, {
+		"RABContext",
+		ie.NewRABContext(5, 0x1111, 0x2222, 0x3333, 0x4444),
+		[]byte{0x16, 0x05, 0x11, 0x11, 0x22, 0x22, 0x33, 0x33, 0x44, 0x44},
+	}, {
+		"TrafficFlowTemplate/CreateNewTFT",
+		ie.NewTrafficFlowTemplate(ie.TFTOpCreateNewTFT, []*ie.TFTPacketFilter{
+			{Identifier: 1, Direction: ie.TFTPFBidirectional, Precedence: 0x10, Contents: []byte{0x30, 0x11}},
+		}, nil),
+		[]byte{0x89, 0x00, 0x06, 0x21, 0x31, 0x10, 0x02, 0x30, 0x11},
+	}, {
+		"TrafficFlowTemplate/DeletePacketFilters",
+		ie.NewTrafficFlowTemplate(ie.TFTOpDeletePacketFiltersFromExistingTFT, []*ie.TFTPacketFilter{
+			{Identifier: 1}, {Identifier: 2},
+		}, nil),
+		[]byte{0x89, 0x00, 0x03, 0xa2, 0x01, 0x02},
+	}, {
+		"ExtensionHeaderTypeList",
+		ie.NewExtensionHeaderTypeList(0x40, 0xc0),
+		[]byte{0x8d, 0x02, 0x40, 0xc0},
+	}, {
+		"RANTransparentContainer",
+		ie.NewRANTransparentContainer([]byte{0xde, 0xad, 0xbe, 0xef}),
+		[]byte{0x90, 0x00, 0x04, 0xde, 0xad, 0xbe, 0xef},
+	}, {
+		"RIMRoutingAddress",
+		ie.NewRIMRoutingAddress([]byte{0x21, 0xf3, 0x54, 0x11, 0x11, 0x22, 0x00, 0x01}),
+		[]byte{0x9e, 0x00, 0x08, 0x21, 0xf3, 0x54, 0x11, 0x11, 0x22, 0x00, 0x01},
+	}, {
+		"RIMRoutingAddressDiscriminator",
+		ie.NewRIMRoutingAddressDiscriminator(ie.RIMRoutingAddressDiscriminatorENBID),
+		[]byte{0xb2, 0x00, 0x01, 0x02},
+	}, {
+		"TemporaryMobileGroupIdentity",
+		ie.NewTemporaryMobileGroupIdentity(0x123456, "123", "45"),
+		[]byte{0x9d, 0x00, 0x06, 0x12, 0x34, 0x56, 0x21, 0xf3, 0x54},
+	}, {
+		"MBMSSessionDuration",
+		ie.NewMBMSSessionDuration(25 * time.Hour),
+		[]byte{0xa8, 0x00, 0x03, 0x07, 0x08, 0x01},
+	}, {
+		"MBMS2G3GIndicator",
+		ie.NewMBMS2G3GIndicator(ie.MBMS2G3GIndicator3GOnly),
+		[]byte{0xa6, 0x00, 0x01, 0x01},
+	}, {
+		"MMContext/GSMKeyAndTriplets",
+		ie.NewMMContextGSMKeyAndTriplets(
+			1, 1, []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08},
+			[]byte{0x09, 0x10}, []byte{0xe5, 0xe0},
+		),
+		[]byte{
+			0x81, 0x00, 0x11,
+			0xf9, 0x41,
+			0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+			0x09, 0x10,
+			0x02, 0xe5, 0xe0,
+			0x00, 0x00,
+		},
+	}, {
+		"PDPContext",
+		ie.NewPDPContext(&ie.PDPContextFields{
+			NSAPI:                5,
+			SAPI:                 3,
+			QoSSubscribed:        []byte{0x01, 0x02, 0x03},
+			QoSRequested:         []byte{0x01, 0x02, 0x03},
+			QoSNegotiated:        []byte{0x01, 0x02, 0x03},
+			UplinkTEIDCPlane:     0x11111111,
+			UplinkTEIDDataI:      0x22222222,
+			PDPContextIdentifier: 1,
+			PDPTypeOrganization:  1,
+			PDPTypeNumber:        0x21,
+			PDPAddress:           net.ParseIP("10.0.0.1"),
+			GGSNAddressForCPlane: net.ParseIP("1.1.1.1"),
+			APN:                  "some.apn.example",
+		}),
+		[]byte{
+			0x82, 0x00, 0x3e,
+			0x05, 0x03,
+			0x03, 0x01, 0x02, 0x03,
+			0x03, 0x01, 0x02, 0x03,
+			0x03, 0x01, 0x02, 0x03,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x11, 0x11, 0x11, 0x11,
+			0x22, 0x22, 0x22, 0x22,
+			0x01, 0xf1, 0x21,
+			0x04, 0x0a, 0x00, 0x00, 0x01,
+			0x04, 0x01, 0x01, 0x01, 0x01,
+			0x00,
+			0x11, 0x04, 0x73, 0x6f, 0x6d, 0x65, 0x03, 0x61, 0x70, 0x6e, 0x07, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65,
+			0xf0, 0x00,
+		},
+	},
+}
 
-	for _, c := range cases {
+func TestIEs(t *testing.T) {
+	for _, c := range testIEs {
 		t.Run("Marshal/"+c.description, func(t *testing.T) {
 			got, err := c.structured.Marshal()
 			if err != nil {
@@ -204,3 +296,130 @@ func TestIEs(t *testing.T) {
 		})
 	}
 }
+
+func TestContextFields(t *testing.T) {
+	t.Run("MMContext", func(t *testing.T) {
+		f := &ie.MMContextFields{
+			SecurityMode:        ie.SecurityModeUMTSKeysAndQuintuplets,
+			CKSN:                2,
+			CK:                  make([]byte, 16),
+			IK:                  make([]byte, 16),
+			DRXParameter:        []byte{0x09, 0x10},
+			MSNetworkCapability: []byte{0xe5, 0xe0},
+			Quintuplets: []*ie.IE{
+				ie.NewAuthenticationQuintuplet(
+					make([]byte, 16), []byte{0xde, 0xad, 0xbe, 0xef},
+					make([]byte, 16), make([]byte, 16), make([]byte, 16),
+				),
+			},
+		}
+
+		got, err := ie.NewMMContext(f).MMContext()
+		if err != nil {
+			t.Fatal(err)
+		}
+		opt := cmp.AllowUnexported(ie.IE{})
+		if diff := cmp.Diff(got, f, opt); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("PDPContext", func(t *testing.T) {
+		f := &ie.PDPContextFields{
+			Flags:                     0x80,
+			NSAPI:                     5,
+			QoSSubscribed:             []byte{0x01, 0x02, 0x03},
+			SequenceNumberDown:        1,
+			SequenceNumberUp:          2,
+			UplinkTEIDCPlane:          0x11111111,
+			UplinkTEIDDataI:           0x22222222,
+			PDPTypeOrganization:       1,
+			PDPTypeNumber:             0x57,
+			PDPAddress:                net.ParseIP("2001::1"),
+			GGSNAddressForCPlane:      net.ParseIP("1.1.1.1").To4(),
+			GGSNAddressForUserTraffic: net.ParseIP("2.2.2.2").To4(),
+			APN:                       "some.apn.example",
+			TransactionIdentifier:     0x123,
+		}
+
+		got, err := ie.NewPDPContext(f).PDPContext()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(got, f); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
+
+func TestTrafficFlowTemplate(t *testing.T) {
+	f := &ie.TrafficFlowTemplateFields{
+		OperationCode: ie.TFTOpAddPacketFiltersToExistingTFT,
+		PacketFilters: []*ie.TFTPacketFilter{
+			{Identifier: 1, Direction: ie.TFTPFUplinkOnly, Precedence: 0x10, Contents: []byte{0x30, 0x11}},
+			{Identifier: 2, Direction: ie.TFTPFDownlinkOnly, Precedence: 0x20, Contents: []byte{0x50, 0x00, 0x50}},
+		},
+		Parameters: []*ie.TFTParameter{
+			{Identifier: 0x03, Contents: []byte{0x05}},
+		},
+	}
+
+	got, err := ie.NewTrafficFlowTemplateFromFields(f).TrafficFlowTemplate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, f); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestMBMSGetters(t *testing.T) {
+	tmgi := ie.NewTemporaryMobileGroupIdentity(0x123456, "123", "45")
+	if got := tmgi.MustMBMSServiceID(); got != 0x123456 {
+		t.Errorf("wrong MBMS Service ID: got %#x, want %#x", got, 0x123456)
+	}
+
+	d := 3*24*time.Hour + 90*time.Minute
+	if got := ie.NewMBMSSessionDuration(d).MustMBMSSessionDuration(); got != d {
+		t.Errorf("wrong MBMS Session Duration: got %v, want %v", got, d)
+	}
+}
+
+func TestAuthenticationVectors(t *testing.T) {
+	rand := []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}
+	xres := []byte{0xde, 0xad, 0xbe, 0xef, 0xde, 0xad, 0xbe, 0xef}
+	ck := []byte{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01}
+	ik := []byte{0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02}
+	autn := []byte{0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03}
+
+	q := ie.NewAuthenticationQuintuplet(rand, xres, ck, ik, autn)
+	for _, c := range []struct {
+		description string
+		got, want   []byte
+	}{
+		{"RAND", q.MustRAND(), rand},
+		{"XRES", q.MustXRES(), xres},
+		{"CK", q.MustCK(), ck},
+		{"IK", q.MustIK(), ik},
+		{"AUTN", q.MustAUTN(), autn},
+	} {
+		if diff := cmp.Diff(c.got, c.want); diff != "" {
+			t.Errorf("%s: %s", c.description, diff)
+		}
+	}
+
+	rab := ie.NewRABContext(5, 0x1111, 0x2222, 0x3333, 0x4444)
+	if got := rab.MustNSAPI(); got != 5 {
+		t.Errorf("wrong NSAPI in RABContext: got %d, want %d", got, 5)
+	}
+	want := &ie.RABContextFields{
+		NSAPI:                      5,
+		DownlinkGTPUSequenceNumber: 0x1111,
+		UplinkGTPUSequenceNumber:   0x2222,
+		DownlinkPDCPSequenceNumber: 0x3333,
+		UplinkPDCPSequenceNumber:   0x4444,
+	}
+	if diff := cmp.Diff(rab.MustRABContext(), want); diff != "" {
+		t.Error(diff)
+	}
+}
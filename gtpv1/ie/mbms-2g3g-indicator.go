@@ -0,0 +1,38 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+import "io"
+
+// MBMS 2G/3G Indicator definitions.
+const (
+	MBMS2G3GIndicator2GOnly uint8 = 0
+	MBMS2G3GIndicator3GOnly uint8 = 1
+	MBMS2G3GIndicatorBoth   uint8 = 2
+)
+
+// NewMBMS2G3GIndicator creates a new MBMS2G3GIndicator IE.
+func NewMBMS2G3GIndicator(indicator uint8) *IE {
+	return newUint8ValIE(MBMS2G3GIndicator, indicator)
+}
+
+// MBMS2G3GIndicator returns MBMS2G3GIndicator in uint8 if type matches.
+func (i *IE) MBMS2G3GIndicator() (uint8, error) {
+	if i.Type != MBMS2G3GIndicator {
+		return 0, &InvalidTypeError{Type: i.Type}
+	}
+	if len(i.Payload) == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	return i.Payload[0], nil
+}
+
+// MustMBMS2G3GIndicator returns MBMS2G3GIndicator in uint8 if type matches.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustMBMS2G3GIndicator() uint8 {
+	v, _ := i.MBMS2G3GIndicator()
+	return v
+}
@@ -0,0 +1,27 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+// NewMBMSFlowIdentifier creates a new MBMSFlowIdentifier IE.
+//
+// The id should be the value of MBMS-Flow-Identifier AVP defined in 3GPP TS 29.061.
+func NewMBMSFlowIdentifier(id []byte) *IE {
+	return New(MBMSFlowIdentifier, id)
+}
+
+// MBMSFlowIdentifier returns MBMSFlowIdentifier in []byte if type matches.
+func (i *IE) MBMSFlowIdentifier() ([]byte, error) {
+	if i.Type != MBMSFlowIdentifier {
+		return nil, &InvalidTypeError{Type: i.Type}
+	}
+	return i.Payload, nil
+}
+
+// MustMBMSFlowIdentifier returns MBMSFlowIdentifier in []byte if type matches.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustMBMSFlowIdentifier() []byte {
+	v, _ := i.MBMSFlowIdentifier()
+	return v
+}
@@ -0,0 +1,27 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+// NewMBMSServiceArea creates a new MBMSServiceArea IE.
+//
+// The area should be the value of MBMS-Service-Area AVP defined in 3GPP TS 29.061.
+func NewMBMSServiceArea(area []byte) *IE {
+	return New(MBMSServiceArea, area)
+}
+
+// MBMSServiceArea returns MBMSServiceArea in []byte if type matches.
+func (i *IE) MBMSServiceArea() ([]byte, error) {
+	if i.Type != MBMSServiceArea {
+		return nil, &InvalidTypeError{Type: i.Type}
+	}
+	return i.Payload, nil
+}
+
+// MustMBMSServiceArea returns MBMSServiceArea in []byte if type matches.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustMBMSServiceArea() []byte {
+	v, _ := i.MBMSServiceArea()
+	return v
+}
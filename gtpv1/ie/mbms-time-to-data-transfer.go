@@ -0,0 +1,31 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+import "io"
+
+// NewMBMSTimeToDataTransfer creates a new MBMSTimeToDataTransfer IE.
+func NewMBMSTimeToDataTransfer(t uint8) *IE {
+	return newUint8ValIE(MBMSTimeToDataTransfer, t)
+}
+
+// MBMSTimeToDataTransfer returns MBMSTimeToDataTransfer in uint8 if type matches.
+func (i *IE) MBMSTimeToDataTransfer() (uint8, error) {
+	if i.Type != MBMSTimeToDataTransfer {
+		return 0, &InvalidTypeError{Type: i.Type}
+	}
+	if len(i.Payload) == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	return i.Payload[0], nil
+}
+
+// MustMBMSTimeToDataTransfer returns MBMSTimeToDataTransfer in uint8 if type matches.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustMBMSTimeToDataTransfer() uint8 {
+	v, _ := i.MBMSTimeToDataTransfer()
+	return v
+}
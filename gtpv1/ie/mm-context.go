@@ -0,0 +1,293 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// Security Mode definitions used in MMContext IE.
+const (
+	SecurityModeUsedCipherValueUMTSKeysAndQuintuplets uint8 = 0
+	SecurityModeGSMKeyAndTriplets                     uint8 = 1
+	SecurityModeUMTSKeysAndQuintuplets                uint8 = 2
+	SecurityModeGSMKeyAndQuintuplets                  uint8 = 3
+)
+
+// NewMMContextGSMKeyAndTriplets creates a new MMContext IE with GSM Key and Triplets.
+//
+// triplets should be the AuthenticationTriplet IEs.
+func NewMMContextGSMKeyAndTriplets(cksn, usedCipher uint8, kc, drxParam, msNetworkCapability []byte, triplets ...*IE) *IE {
+	return NewMMContext(&MMContextFields{
+		SecurityMode:        SecurityModeGSMKeyAndTriplets,
+		CKSN:                cksn,
+		UsedCipher:          usedCipher,
+		Kc:                  kc,
+		Triplets:            triplets,
+		DRXParameter:        drxParam,
+		MSNetworkCapability: msNetworkCapability,
+	})
+}
+
+// NewMMContextUMTSKeysAndQuintuplets creates a new MMContext IE with UMTS Keys and Quintuplets.
+//
+// quintuplets should be the AuthenticationQuintuplet IEs.
+func NewMMContextUMTSKeysAndQuintuplets(ksi uint8, ck, ik, drxParam, msNetworkCapability []byte, quintuplets ...*IE) *IE {
+	return NewMMContext(&MMContextFields{
+		SecurityMode:        SecurityModeUMTSKeysAndQuintuplets,
+		CKSN:                ksi,
+		CK:                  ck,
+		IK:                  ik,
+		Quintuplets:         quintuplets,
+		DRXParameter:        drxParam,
+		MSNetworkCapability: msNetworkCapability,
+	})
+}
+
+// NewMMContext creates a new MMContext IE from MMContextFields.
+func NewMMContext(f *MMContextFields) *IE {
+	b, err := f.Marshal()
+	if err != nil {
+		return nil
+	}
+	return New(MMContext, b)
+}
+
+// MMContext returns MMContext in MMContextFields type if the type of IE matches.
+func (i *IE) MMContext() (*MMContextFields, error) {
+	if i.Type != MMContext {
+		return nil, &InvalidTypeError{Type: i.Type}
+	}
+	return ParseMMContextFields(i.Payload)
+}
+
+// MustMMContext returns MMContext in MMContextFields type if the type of IE matches.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustMMContext() *MMContextFields {
+	v, _ := i.MMContext()
+	return v
+}
+
+// MMContextFields is a set of fields in MMContext IE.
+//
+// Which of the keys(Kc or CK/IK) and vectors(Triplets or Quintuplets) are used
+// depends on SecurityMode. CKSN is used as KSI when the UMTS keys are used, and
+// UsedCipher is ignored with SecurityModeUMTSKeysAndQuintuplets.
+type MMContextFields struct {
+	SecurityMode        uint8
+	CKSN                uint8
+	UsedCipher          uint8
+	Kc                  []byte
+	CK                  []byte
+	IK                  []byte
+	Triplets            []*IE
+	Quintuplets         []*IE
+	DRXParameter        []byte
+	MSNetworkCapability []byte
+	Container           []byte
+}
+
+func (f *MMContextFields) hasUMTSKeys() bool {
+	return f.SecurityMode == SecurityModeUMTSKeysAndQuintuplets ||
+		f.SecurityMode == SecurityModeUsedCipherValueUMTSKeysAndQuintuplets
+}
+
+func (f *MMContextFields) numVectors() int {
+	if f.SecurityMode == SecurityModeGSMKeyAndTriplets {
+		return len(f.Triplets)
+	}
+	return len(f.Quintuplets)
+}
+
+// Marshal serializes MMContextFields.
+func (f *MMContextFields) Marshal() ([]byte, error) {
+	b := make([]byte, f.MarshalLen())
+	if err := f.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalTo serializes MMContextFields.
+func (f *MMContextFields) MarshalTo(b []byte) error {
+	if len(b) < f.MarshalLen() {
+		return io.ErrUnexpectedEOF
+	}
+	if f.numVectors() > 7 {
+		return ErrInvalidLength
+	}
+
+	b[0] = 0xf8 | (f.CKSN & 0x07)
+	b[1] = (f.SecurityMode&0x03)<<6 | uint8(f.numVectors())<<3
+	if f.SecurityMode == SecurityModeUMTSKeysAndQuintuplets {
+		b[1] |= 0x07 // spare
+	} else {
+		b[1] |= f.UsedCipher & 0x07
+	}
+	offset := 2
+
+	if f.hasUMTSKeys() {
+		copy(b[offset:offset+16], f.CK)
+		copy(b[offset+16:offset+32], f.IK)
+		offset += 32
+	} else {
+		copy(b[offset:offset+8], f.Kc)
+		offset += 8
+	}
+
+	if f.SecurityMode == SecurityModeGSMKeyAndTriplets {
+		for _, t := range f.Triplets {
+			copy(b[offset:offset+28], t.Payload)
+			offset += 28
+		}
+	} else {
+		binary.BigEndian.PutUint16(b[offset:offset+2], uint16(f.quintupletsLen()))
+		offset += 2
+		// each quintuplet is encoded as AuthenticationQuintuplet IE without Type.
+		for _, q := range f.Quintuplets {
+			binary.BigEndian.PutUint16(b[offset:offset+2], uint16(len(q.Payload)))
+			copy(b[offset+2:], q.Payload)
+			offset += 2 + len(q.Payload)
+		}
+	}
+
+	copy(b[offset:offset+2], f.DRXParameter)
+	offset += 2
+
+	b[offset] = uint8(len(f.MSNetworkCapability))
+	copy(b[offset+1:], f.MSNetworkCapability)
+	offset += 1 + len(f.MSNetworkCapability)
+
+	binary.BigEndian.PutUint16(b[offset:offset+2], uint16(len(f.Container)))
+	copy(b[offset+2:], f.Container)
+	return nil
+}
+
+func (f *MMContextFields) quintupletsLen() int {
+	l := 0
+	for _, q := range f.Quintuplets {
+		l += 2 + len(q.Payload)
+	}
+	return l
+}
+
+// ParseMMContextFields decodes MMContextFields.
+func ParseMMContextFields(b []byte) (*MMContextFields, error) {
+	f := &MMContextFields{}
+	if err := f.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// UnmarshalBinary decodes given bytes into MMContextFields.
+func (f *MMContextFields) UnmarshalBinary(b []byte) error {
+	l := len(b)
+	if l < 2 {
+		return io.ErrUnexpectedEOF
+	}
+
+	f.CKSN = b[0] & 0x07
+	f.SecurityMode = b[1] >> 6
+	n := int(b[1]>>3) & 0x07
+	if f.SecurityMode != SecurityModeUMTSKeysAndQuintuplets {
+		f.UsedCipher = b[1] & 0x07
+	}
+	offset := 2
+
+	if f.hasUMTSKeys() {
+		if l < offset+32 {
+			return io.ErrUnexpectedEOF
+		}
+		f.CK = b[offset : offset+16]
+		f.IK = b[offset+16 : offset+32]
+		offset += 32
+	} else {
+		if l < offset+8 {
+			return io.ErrUnexpectedEOF
+		}
+		f.Kc = b[offset : offset+8]
+		offset += 8
+	}
+
+	if f.SecurityMode == SecurityModeGSMKeyAndTriplets {
+		if l < offset+28*n {
+			return io.ErrUnexpectedEOF
+		}
+		for j := 0; j < n; j++ {
+			f.Triplets = append(f.Triplets, New(AuthenticationTriplet, b[offset:offset+28]))
+			offset += 28
+		}
+	} else {
+		if l < offset+2 {
+			return io.ErrUnexpectedEOF
+		}
+		qlen := int(binary.BigEndian.Uint16(b[offset : offset+2]))
+		offset += 2
+		if l < offset+qlen {
+			return io.ErrUnexpectedEOF
+		}
+
+		end := offset + qlen
+		for offset < end {
+			if end < offset+2 {
+				return ErrMalformed
+			}
+			vlen := int(binary.BigEndian.Uint16(b[offset : offset+2]))
+			if end < offset+2+vlen {
+				return ErrMalformed
+			}
+			f.Quintuplets = append(f.Quintuplets, New(AuthenticationQuintuplet, b[offset+2:offset+2+vlen]))
+			offset += 2 + vlen
+		}
+	}
+
+	if l < offset+3 {
+		return io.ErrUnexpectedEOF
+	}
+	f.DRXParameter = b[offset : offset+2]
+	offset += 2
+
+	mlen := int(b[offset])
+	offset++
+	if l < offset+mlen {
+		return io.ErrUnexpectedEOF
+	}
+	f.MSNetworkCapability = b[offset : offset+mlen]
+	offset += mlen
+
+	// Container and the fields after it are not always present.
+	if l < offset+2 {
+		return nil
+	}
+	clen := int(binary.BigEndian.Uint16(b[offset : offset+2]))
+	offset += 2
+	if l < offset+clen {
+		return io.ErrUnexpectedEOF
+	}
+	if clen > 0 {
+		f.Container = b[offset : offset+clen]
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of MMContextFields.
+func (f *MMContextFields) MarshalLen() int {
+	l := 2
+	if f.hasUMTSKeys() {
+		l += 32
+	} else {
+		l += 8
+	}
+
+	if f.SecurityMode == SecurityModeGSMKeyAndTriplets {
+		l += 28 * len(f.Triplets)
+	} else {
+		l += 2 + f.quintupletsLen()
+	}
+
+	return l + 2 + 1 + len(f.MSNetworkCapability) + 2 + len(f.Container)
+}
@@ -13,14 +13,19 @@ func NewNSAPI(nsapi uint8) *IE {
 
 // NSAPI returns NSAPI value if type matches.
 func (i *IE) NSAPI() (uint8, error) {
-	if i.Type != NSAPI {
+	switch i.Type {
+	case NSAPI, RABContext, PDPContext:
+	default:
 		return 0, &InvalidTypeError{Type: i.Type}
 	}
 	if len(i.Payload) == 0 {
 		return 0, io.ErrUnexpectedEOF
 	}
 
-	return i.Payload[0], nil
+	if i.Type == NSAPI {
+		return i.Payload[0], nil
+	}
+	return i.Payload[0] & 0x0f, nil
 }
 
 // MustNSAPI returns NSAPI in uint8 if type matches.
@@ -0,0 +1,240 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+)
+
+// NewPDPContext creates a new PDPContext IE from PDPContextFields.
+func NewPDPContext(f *PDPContextFields) *IE {
+	b, err := f.Marshal()
+	if err != nil {
+		return nil
+	}
+	return New(PDPContext, b)
+}
+
+// PDPContext returns PDPContext in PDPContextFields type if the type of IE matches.
+func (i *IE) PDPContext() (*PDPContextFields, error) {
+	if i.Type != PDPContext {
+		return nil, &InvalidTypeError{Type: i.Type}
+	}
+	return ParsePDPContextFields(i.Payload)
+}
+
+// MustPDPContext returns PDPContext in PDPContextFields type if the type of IE matches.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustPDPContext() *PDPContextFields {
+	v, _ := i.PDPContext()
+	return v
+}
+
+// PDPContextFields is a set of fields in PDPContext IE.
+//
+// Flags has EA, VAA, ASI and Order in the 8-5th bits in the same octet as NSAPI.
+// QoS profiles are the value of QoSProfile IE. PDPAddress is nil if no address is
+// assigned, e.g., for PPP.
+type PDPContextFields struct {
+	Flags                     uint8
+	NSAPI                     uint8
+	SAPI                      uint8
+	QoSSubscribed             []byte
+	QoSRequested              []byte
+	QoSNegotiated             []byte
+	SequenceNumberDown        uint16
+	SequenceNumberUp          uint16
+	SendNPDUNumber            uint8
+	ReceiveNPDUNumber         uint8
+	UplinkTEIDCPlane          uint32
+	UplinkTEIDDataI           uint32
+	PDPContextIdentifier      uint8
+	PDPTypeOrganization       uint8
+	PDPTypeNumber             uint8
+	PDPAddress                net.IP
+	GGSNAddressForCPlane      net.IP
+	GGSNAddressForUserTraffic net.IP
+	APN                       string
+	TransactionIdentifier     uint16
+}
+
+// Marshal serializes PDPContextFields.
+func (f *PDPContextFields) Marshal() ([]byte, error) {
+	b := make([]byte, f.MarshalLen())
+	if err := f.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalTo serializes PDPContextFields.
+func (f *PDPContextFields) MarshalTo(b []byte) error {
+	if len(b) < f.MarshalLen() {
+		return io.ErrUnexpectedEOF
+	}
+
+	b[0] = (f.Flags & 0xf0) | (f.NSAPI & 0x0f)
+	b[1] = f.SAPI & 0x0f
+	offset := 2
+
+	for _, qos := range [][]byte{f.QoSSubscribed, f.QoSRequested, f.QoSNegotiated} {
+		b[offset] = uint8(len(qos))
+		copy(b[offset+1:], qos)
+		offset += 1 + len(qos)
+	}
+
+	binary.BigEndian.PutUint16(b[offset:offset+2], f.SequenceNumberDown)
+	binary.BigEndian.PutUint16(b[offset+2:offset+4], f.SequenceNumberUp)
+	b[offset+4] = f.SendNPDUNumber
+	b[offset+5] = f.ReceiveNPDUNumber
+	binary.BigEndian.PutUint32(b[offset+6:offset+10], f.UplinkTEIDCPlane)
+	binary.BigEndian.PutUint32(b[offset+10:offset+14], f.UplinkTEIDDataI)
+	b[offset+14] = f.PDPContextIdentifier
+	b[offset+15] = 0xf0 | (f.PDPTypeOrganization & 0x0f)
+	b[offset+16] = f.PDPTypeNumber
+	offset += 17
+
+	for _, ip := range []net.IP{f.PDPAddress, f.GGSNAddressForCPlane, f.GGSNAddressForUserTraffic} {
+		v := ipBytes(ip)
+		b[offset] = uint8(len(v))
+		copy(b[offset+1:], v)
+		offset += 1 + len(v)
+	}
+
+	apn := encodeAPN(f.APN)
+	b[offset] = uint8(len(apn))
+	copy(b[offset+1:], apn)
+	offset += 1 + len(apn)
+
+	b[offset] = 0xf0 | uint8(f.TransactionIdentifier>>8)&0x0f
+	b[offset+1] = uint8(f.TransactionIdentifier)
+	return nil
+}
+
+// ParsePDPContextFields decodes PDPContextFields.
+func ParsePDPContextFields(b []byte) (*PDPContextFields, error) {
+	f := &PDPContextFields{}
+	if err := f.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// UnmarshalBinary decodes given bytes into PDPContextFields.
+func (f *PDPContextFields) UnmarshalBinary(b []byte) error {
+	l := len(b)
+	if l < 2 {
+		return io.ErrUnexpectedEOF
+	}
+
+	f.Flags = b[0] & 0xf0
+	f.NSAPI = b[0] & 0x0f
+	f.SAPI = b[1] & 0x0f
+	offset := 2
+
+	for _, qos := range []*[]byte{&f.QoSSubscribed, &f.QoSRequested, &f.QoSNegotiated} {
+		v, n, err := lengthPrefixed(b[offset:])
+		if err != nil {
+			return err
+		}
+		*qos = v
+		offset += n
+	}
+
+	if l < offset+17 {
+		return io.ErrUnexpectedEOF
+	}
+	f.SequenceNumberDown = binary.BigEndian.Uint16(b[offset : offset+2])
+	f.SequenceNumberUp = binary.BigEndian.Uint16(b[offset+2 : offset+4])
+	f.SendNPDUNumber = b[offset+4]
+	f.ReceiveNPDUNumber = b[offset+5]
+	f.UplinkTEIDCPlane = binary.BigEndian.Uint32(b[offset+6 : offset+10])
+	f.UplinkTEIDDataI = binary.BigEndian.Uint32(b[offset+10 : offset+14])
+	f.PDPContextIdentifier = b[offset+14]
+	f.PDPTypeOrganization = b[offset+15] & 0x0f
+	f.PDPTypeNumber = b[offset+16]
+	offset += 17
+
+	for _, ip := range []*net.IP{&f.PDPAddress, &f.GGSNAddressForCPlane, &f.GGSNAddressForUserTraffic} {
+		v, n, err := lengthPrefixed(b[offset:])
+		if err != nil {
+			return err
+		}
+		if v != nil {
+			*ip = net.IP(v)
+		}
+		offset += n
+	}
+
+	apn, n, err := lengthPrefixed(b[offset:])
+	if err != nil {
+		return err
+	}
+	f.APN = decodeAPN(apn)
+	offset += n
+
+	if l < offset+2 {
+		return io.ErrUnexpectedEOF
+	}
+	f.TransactionIdentifier = uint16(b[offset]&0x0f)<<8 | uint16(b[offset+1])
+	return nil
+}
+
+// MarshalLen returns the serial length of PDPContextFields.
+func (f *PDPContextFields) MarshalLen() int {
+	l := 2 + 3 + len(f.QoSSubscribed) + len(f.QoSRequested) + len(f.QoSNegotiated) + 17
+	for _, ip := range []net.IP{f.PDPAddress, f.GGSNAddressForCPlane, f.GGSNAddressForUserTraffic} {
+		l += 1 + len(ipBytes(ip))
+	}
+	return l + 1 + len(encodeAPN(f.APN)) + 2
+}
+
+// ipBytes returns IPv4 address in 4 octets, or IPv6 address in 16 octets.
+func ipBytes(ip net.IP) []byte {
+	if ip == nil {
+		return nil
+	}
+	if v := ip.To4(); v != nil {
+		return v
+	}
+	return ip.To16()
+}
+
+// lengthPrefixed returns the value that has the length in the first octet, and
+// the number of octets consumed.
+func lengthPrefixed(b []byte) ([]byte, int, error) {
+	if len(b) < 1 {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	l := int(b[0])
+	if len(b) < 1+l {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	if l == 0 {
+		return nil, 1, nil
+	}
+	return b[1 : 1+l], 1 + l, nil
+}
+
+func encodeAPN(apn string) []byte {
+	if apn == "" {
+		return nil
+	}
+	return NewAccessPointName(apn).Payload
+}
+
+func decodeAPN(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	apn, err := New(AccessPointName, b).AccessPointName()
+	if err != nil {
+		return strings.TrimSpace(string(b))
+	}
+	return apn
+}
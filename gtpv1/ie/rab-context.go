@@ -0,0 +1,105 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// NewRABContext creates a new RABContext IE.
+func NewRABContext(nsapi uint8, dlGTPUSeq, ulGTPUSeq, dlPDCPSeq, ulPDCPSeq uint16) *IE {
+	return NewRABContextFromFields(&RABContextFields{
+		NSAPI:                      nsapi,
+		DownlinkGTPUSequenceNumber: dlGTPUSeq,
+		UplinkGTPUSequenceNumber:   ulGTPUSeq,
+		DownlinkPDCPSequenceNumber: dlPDCPSeq,
+		UplinkPDCPSequenceNumber:   ulPDCPSeq,
+	})
+}
+
+// NewRABContextFromFields creates a new RABContext IE from RABContextFields.
+func NewRABContextFromFields(f *RABContextFields) *IE {
+	b, err := f.Marshal()
+	if err != nil {
+		return nil
+	}
+	return New(RABContext, b)
+}
+
+// RABContext returns RABContext in RABContextFields type if the type of IE matches.
+func (i *IE) RABContext() (*RABContextFields, error) {
+	if i.Type != RABContext {
+		return nil, &InvalidTypeError{Type: i.Type}
+	}
+	return ParseRABContextFields(i.Payload)
+}
+
+// MustRABContext returns RABContext in RABContextFields type if the type of IE matches.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustRABContext() *RABContextFields {
+	v, _ := i.RABContext()
+	return v
+}
+
+// RABContextFields is a set of fields in RABContext IE.
+type RABContextFields struct {
+	NSAPI                      uint8
+	DownlinkGTPUSequenceNumber uint16
+	UplinkGTPUSequenceNumber   uint16
+	DownlinkPDCPSequenceNumber uint16
+	UplinkPDCPSequenceNumber   uint16
+}
+
+// Marshal serializes RABContextFields.
+func (f *RABContextFields) Marshal() ([]byte, error) {
+	b := make([]byte, f.MarshalLen())
+	if err := f.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalTo serializes RABContextFields.
+func (f *RABContextFields) MarshalTo(b []byte) error {
+	if len(b) < f.MarshalLen() {
+		return io.ErrUnexpectedEOF
+	}
+
+	b[0] = f.NSAPI & 0x0f
+	binary.BigEndian.PutUint16(b[1:3], f.DownlinkGTPUSequenceNumber)
+	binary.BigEndian.PutUint16(b[3:5], f.UplinkGTPUSequenceNumber)
+	binary.BigEndian.PutUint16(b[5:7], f.DownlinkPDCPSequenceNumber)
+	binary.BigEndian.PutUint16(b[7:9], f.UplinkPDCPSequenceNumber)
+	return nil
+}
+
+// ParseRABContextFields decodes RABContextFields.
+func ParseRABContextFields(b []byte) (*RABContextFields, error) {
+	f := &RABContextFields{}
+	if err := f.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// UnmarshalBinary decodes given bytes into RABContextFields.
+func (f *RABContextFields) UnmarshalBinary(b []byte) error {
+	if len(b) < 9 {
+		return io.ErrUnexpectedEOF
+	}
+
+	f.NSAPI = b[0] & 0x0f
+	f.DownlinkGTPUSequenceNumber = binary.BigEndian.Uint16(b[1:3])
+	f.UplinkGTPUSequenceNumber = binary.BigEndian.Uint16(b[3:5])
+	f.DownlinkPDCPSequenceNumber = binary.BigEndian.Uint16(b[5:7])
+	f.UplinkPDCPSequenceNumber = binary.BigEndian.Uint16(b[7:9])
+	return nil
+}
+
+// MarshalLen returns the serial length of RABContextFields.
+func (f *RABContextFields) MarshalLen() int {
+	return 9
+}
@@ -0,0 +1,28 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+// NewRANTransparentContainer creates a new RANTransparentContainer IE.
+//
+// The container is the RIM PDU defined in 3GPP TS 48.018, which should be given
+// as it is.
+func NewRANTransparentContainer(container []byte) *IE {
+	return New(RANTransparentContainer, container)
+}
+
+// RANTransparentContainer returns RANTransparentContainer in []byte if type matches.
+func (i *IE) RANTransparentContainer() ([]byte, error) {
+	if i.Type != RANTransparentContainer {
+		return nil, &InvalidTypeError{Type: i.Type}
+	}
+	return i.Payload, nil
+}
+
+// MustRANTransparentContainer returns RANTransparentContainer in []byte if type matches.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustRANTransparentContainer() []byte {
+	v, _ := i.RANTransparentContainer()
+	return v
+}
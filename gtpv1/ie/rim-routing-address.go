@@ -0,0 +1,62 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+import "io"
+
+// RIM Routing Address Discriminator definitions.
+const (
+	RIMRoutingAddressDiscriminatorGERANCellID   uint8 = 0
+	RIMRoutingAddressDiscriminatorRNCID         uint8 = 1
+	RIMRoutingAddressDiscriminatorENBID         uint8 = 2
+	RIMRoutingAddressDiscriminatorEHRPDSectorID uint8 = 3
+)
+
+// NewRIMRoutingAddress creates a new RIMRoutingAddress IE.
+//
+// The address is encoded as the Destination Cell Identifier or Target RNC-ID
+// defined in 3GPP TS 48.018 and 25.413, depending on RIMRoutingAddressDiscriminator.
+func NewRIMRoutingAddress(addr []byte) *IE {
+	return New(RIMRoutingAddress, addr)
+}
+
+// RIMRoutingAddress returns RIMRoutingAddress in []byte if type matches.
+func (i *IE) RIMRoutingAddress() ([]byte, error) {
+	if i.Type != RIMRoutingAddress {
+		return nil, &InvalidTypeError{Type: i.Type}
+	}
+	return i.Payload, nil
+}
+
+// MustRIMRoutingAddress returns RIMRoutingAddress in []byte if type matches.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustRIMRoutingAddress() []byte {
+	v, _ := i.RIMRoutingAddress()
+	return v
+}
+
+// NewRIMRoutingAddressDiscriminator creates a new RIMRoutingAddressDiscriminator IE.
+func NewRIMRoutingAddressDiscriminator(discriminator uint8) *IE {
+	return newUint8ValIE(RIMRoutingAddressDiscriminator, discriminator&0x0f)
+}
+
+// RIMRoutingAddressDiscriminator returns RIMRoutingAddressDiscriminator in uint8 if type matches.
+func (i *IE) RIMRoutingAddressDiscriminator() (uint8, error) {
+	if i.Type != RIMRoutingAddressDiscriminator {
+		return 0, &InvalidTypeError{Type: i.Type}
+	}
+	if len(i.Payload) == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	return i.Payload[0] & 0x0f, nil
+}
+
+// MustRIMRoutingAddressDiscriminator returns RIMRoutingAddressDiscriminator in uint8 if type matches.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustRIMRoutingAddressDiscriminator() uint8 {
+	v, _ := i.RIMRoutingAddressDiscriminator()
+	return v
+}
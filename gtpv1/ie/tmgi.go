@@ -0,0 +1,57 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+import (
+	"io"
+
+	"github.com/wmnsk/go-gtp/utils"
+)
+
+// NewTemporaryMobileGroupIdentity creates a new TemporaryMobileGroupIdentity IE.
+func NewTemporaryMobileGroupIdentity(serviceID uint32, mcc, mnc string) *IE {
+	plmn, err := utils.EncodePLMN(mcc, mnc)
+	if err != nil {
+		return nil
+	}
+
+	i := New(TemporaryMobileGroupIdentity, make([]byte, 6))
+	copy(i.Payload[0:3], utils.Uint32To24(serviceID))
+	copy(i.Payload[3:6], plmn)
+	return i
+}
+
+// TemporaryMobileGroupIdentity returns TemporaryMobileGroupIdentity in []byte if type matches.
+func (i *IE) TemporaryMobileGroupIdentity() ([]byte, error) {
+	if i.Type != TemporaryMobileGroupIdentity {
+		return nil, &InvalidTypeError{Type: i.Type}
+	}
+	return i.Payload, nil
+}
+
+// MustTemporaryMobileGroupIdentity returns TemporaryMobileGroupIdentity in []byte if type matches.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustTemporaryMobileGroupIdentity() []byte {
+	v, _ := i.TemporaryMobileGroupIdentity()
+	return v
+}
+
+// MBMSServiceID returns MBMS Service ID in uint32 if type matches.
+func (i *IE) MBMSServiceID() (uint32, error) {
+	if i.Type != TemporaryMobileGroupIdentity {
+		return 0, &InvalidTypeError{Type: i.Type}
+	}
+	if len(i.Payload) < 3 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return utils.Uint24To32(i.Payload[0:3]), nil
+}
+
+// MustMBMSServiceID returns MBMS Service ID in uint32 if type matches.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustMBMSServiceID() uint32 {
+	v, _ := i.MBMSServiceID()
+	return v
+}
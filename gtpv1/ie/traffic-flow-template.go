@@ -0,0 +1,223 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+import "io"
+
+// TFT operation code definitions.
+const (
+	TFTOpSpare uint8 = iota
+	TFTOpCreateNewTFT
+	TFTOpDeleteExistingTFT
+	TFTOpAddPacketFiltersToExistingTFT
+	TFTOpReplacePacketFiltersInExistingTFT
+	TFTOpDeletePacketFiltersFromExistingTFT
+	TFTOpNoTFTOperation
+)
+
+// TFT packet filter direction definitions.
+const (
+	TFTPFPreRel7TFTFilter uint8 = iota
+	TFTPFDownlinkOnly
+	TFTPFUplinkOnly
+	TFTPFBidirectional
+)
+
+// NewTrafficFlowTemplate creates a new TrafficFlowTemplate IE.
+//
+// For TFTOpDeletePacketFiltersFromExistingTFT, only the Identifier of each filter
+// is used. params can be nil if no parameters are needed.
+func NewTrafficFlowTemplate(op uint8, filters []*TFTPacketFilter, params []*TFTParameter) *IE {
+	return NewTrafficFlowTemplateFromFields(&TrafficFlowTemplateFields{
+		OperationCode: op,
+		PacketFilters: filters,
+		Parameters:    params,
+	})
+}
+
+// NewTrafficFlowTemplateFromFields creates a new TrafficFlowTemplate IE from
+// TrafficFlowTemplateFields.
+func NewTrafficFlowTemplateFromFields(f *TrafficFlowTemplateFields) *IE {
+	b, err := f.Marshal()
+	if err != nil {
+		return nil
+	}
+	return New(TrafficFlowTemplate, b)
+}
+
+// TrafficFlowTemplate returns TrafficFlowTemplate in TrafficFlowTemplateFields
+// type if the type of IE matches.
+func (i *IE) TrafficFlowTemplate() (*TrafficFlowTemplateFields, error) {
+	if i.Type != TrafficFlowTemplate {
+		return nil, &InvalidTypeError{Type: i.Type}
+	}
+	return ParseTrafficFlowTemplateFields(i.Payload)
+}
+
+// MustTrafficFlowTemplate returns TrafficFlowTemplate in TrafficFlowTemplateFields
+// type if the type of IE matches.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustTrafficFlowTemplate() *TrafficFlowTemplateFields {
+	v, _ := i.TrafficFlowTemplate()
+	return v
+}
+
+// TFTPacketFilter is a packet filter in TrafficFlowTemplate IE.
+//
+// Contents are the packet filter components encoded as defined in 3GPP TS 24.008.
+type TFTPacketFilter struct {
+	Identifier uint8
+	Direction  uint8
+	Precedence uint8
+	Contents   []byte
+}
+
+// TFTParameter is a parameter in TrafficFlowTemplate IE.
+type TFTParameter struct {
+	Identifier uint8
+	Contents   []byte
+}
+
+// TrafficFlowTemplateFields is a set of fields in TrafficFlowTemplate IE.
+type TrafficFlowTemplateFields struct {
+	OperationCode uint8
+	PacketFilters []*TFTPacketFilter
+	Parameters    []*TFTParameter
+}
+
+// Marshal serializes TrafficFlowTemplateFields.
+func (f *TrafficFlowTemplateFields) Marshal() ([]byte, error) {
+	b := make([]byte, f.MarshalLen())
+	if err := f.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalTo serializes TrafficFlowTemplateFields.
+func (f *TrafficFlowTemplateFields) MarshalTo(b []byte) error {
+	if len(b) < f.MarshalLen() {
+		return io.ErrUnexpectedEOF
+	}
+
+	b[0] = f.OperationCode<<5 | uint8(len(f.PacketFilters))&0x0f
+	if len(f.Parameters) > 0 {
+		b[0] |= 0x10
+	}
+	offset := 1
+
+	for _, pf := range f.PacketFilters {
+		if f.hasIdentifiersOnly() {
+			b[offset] = pf.Identifier & 0x0f
+			offset++
+			continue
+		}
+		b[offset] = (pf.Direction&0x03)<<4 | pf.Identifier&0x0f
+		b[offset+1] = pf.Precedence
+		b[offset+2] = uint8(len(pf.Contents))
+		copy(b[offset+3:], pf.Contents)
+		offset += 3 + len(pf.Contents)
+	}
+
+	for _, p := range f.Parameters {
+		b[offset] = p.Identifier
+		b[offset+1] = uint8(len(p.Contents))
+		copy(b[offset+2:], p.Contents)
+		offset += 2 + len(p.Contents)
+	}
+	return nil
+}
+
+// ParseTrafficFlowTemplateFields decodes TrafficFlowTemplateFields.
+func ParseTrafficFlowTemplateFields(b []byte) (*TrafficFlowTemplateFields, error) {
+	f := &TrafficFlowTemplateFields{}
+	if err := f.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// UnmarshalBinary decodes given bytes into TrafficFlowTemplateFields.
+func (f *TrafficFlowTemplateFields) UnmarshalBinary(b []byte) error {
+	l := len(b)
+	if l < 1 {
+		return io.ErrUnexpectedEOF
+	}
+
+	f.OperationCode = b[0] >> 5
+	hasParams := b[0]&0x10 != 0
+	n := int(b[0] & 0x0f)
+	offset := 1
+
+	f.PacketFilters = nil
+	for i := 0; i < n; i++ {
+		if f.hasIdentifiersOnly() {
+			if l < offset+1 {
+				return io.ErrUnexpectedEOF
+			}
+			f.PacketFilters = append(f.PacketFilters, &TFTPacketFilter{Identifier: b[offset] & 0x0f})
+			offset++
+			continue
+		}
+
+		if l < offset+3 {
+			return io.ErrUnexpectedEOF
+		}
+		pf := &TFTPacketFilter{
+			Identifier: b[offset] & 0x0f,
+			Direction:  (b[offset] >> 4) & 0x03,
+			Precedence: b[offset+1],
+		}
+		cl := int(b[offset+2])
+		offset += 3
+		if l < offset+cl {
+			return io.ErrUnexpectedEOF
+		}
+		pf.Contents = b[offset : offset+cl]
+		offset += cl
+		f.PacketFilters = append(f.PacketFilters, pf)
+	}
+
+	f.Parameters = nil
+	if !hasParams {
+		return nil
+	}
+	for offset < l {
+		if l < offset+2 {
+			return io.ErrUnexpectedEOF
+		}
+		p := &TFTParameter{Identifier: b[offset]}
+		cl := int(b[offset+1])
+		offset += 2
+		if l < offset+cl {
+			return io.ErrUnexpectedEOF
+		}
+		p.Contents = b[offset : offset+cl]
+		offset += cl
+		f.Parameters = append(f.Parameters, p)
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of TrafficFlowTemplateFields.
+func (f *TrafficFlowTemplateFields) MarshalLen() int {
+	l := 1
+	for _, pf := range f.PacketFilters {
+		if f.hasIdentifiersOnly() {
+			l++
+			continue
+		}
+		l += 3 + len(pf.Contents)
+	}
+	for _, p := range f.Parameters {
+		l += 2 + len(p.Contents)
+	}
+	return l
+}
+
+// hasIdentifiersOnly reports whether the packet filters have only the identifiers.
+func (f *TrafficFlowTemplateFields) hasIdentifiersOnly() bool {
+	return f.OperationCode == TFTOpDeletePacketFiltersFromExistingTFT
+}
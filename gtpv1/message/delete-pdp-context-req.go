@@ -71,12 +71,21 @@ func (d *DeletePDPContextRequest) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from DeletePDPContextRequest to b and returns the extended buffer.
+func (d *DeletePDPContextRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, d)
+}
+
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (d *DeletePDPContextRequest) MarshalTo(b []byte) error {
 	if len(b) < d.MarshalLen() {
 		return ErrTooShortToMarshal
 	}
-	d.Header.Payload = make([]byte, d.MarshalLen()-d.Header.MarshalLen())
+	var err error
+	d.Header.Payload, err = d.Header.payloadIn(b, d.MarshalLen())
+	if err != nil {
+		return err
+	}
 
 	offset := 0
 	if ie := d.Cause; ie != nil {
@@ -51,12 +51,21 @@ func (e *ErrorIndication) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from ErrorIndication to b and returns the extended buffer.
+func (e *ErrorIndication) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, e)
+}
+
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (e *ErrorIndication) MarshalTo(b []byte) error {
 	if len(b) < e.MarshalLen() {
 		return ErrTooShortToMarshal
 	}
-	e.Header.Payload = make([]byte, e.MarshalLen()-e.Header.MarshalLen())
+	var err error
+	e.Header.Payload, err = e.Header.payloadIn(b, e.MarshalLen())
+	if err != nil {
+		return err
+	}
 
 	offset := 0
 	if ie := e.TEIDDataI; ie != nil {
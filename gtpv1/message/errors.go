@@ -12,4 +12,8 @@ var (
 	ErrTooShortToMarshal  = errors.New("too short to serialize")
 	ErrTooShortToParse    = errors.New("too short to decode as GTPv1")
 	ErrInvalidMessageType = errors.New("got invalid message type")
+
+	ErrExtensionHeaderNotFound    = errors.New("extension header not found")
+	ErrInvalidExtensionHeaderType = errors.New("got invalid extension header type")
+	ErrInvalidPDUType             = errors.New("got invalid PDU type")
 )
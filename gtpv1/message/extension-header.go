@@ -0,0 +1,102 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"fmt"
+)
+
+// Extension Header Type definitions.
+const (
+	ExtHeaderTypeNoMoreExtensionHeaders uint8 = 0x00
+	ExtHeaderTypeServiceClassIndicator  uint8 = 0x20
+	ExtHeaderTypeUDPPort                uint8 = 0x40
+	ExtHeaderTypeRANContainer           uint8 = 0x81
+	ExtHeaderTypeLongPDCPPDUNumber      uint8 = 0x82
+	ExtHeaderTypeXwRANContainer         uint8 = 0x83
+	ExtHeaderTypeNRRANContainer         uint8 = 0x84
+	ExtHeaderTypePDUSessionContainer    uint8 = 0x85
+	ExtHeaderTypePDCPPDUNumber          uint8 = 0xc0
+)
+
+// ExtensionHeader is a GTPv1 extension header.
+//
+// Type is the type of this extension header, which is put in the Next Extension
+// Header Type field of the preceding header on the wire. The Length field and the
+// Next Extension Header Type field of this header are handled by Header, so the
+// Content should contain only the contents of the extension header. Content is
+// padded with zeros on Marshal so that the extension header is a multiple of 4
+// octets long.
+type ExtensionHeader struct {
+	Type    uint8
+	Content []byte
+}
+
+// NewExtensionHeader creates a new ExtensionHeader.
+func NewExtensionHeader(typ uint8, content []byte) *ExtensionHeader {
+	return &ExtensionHeader{
+		Type:    typ,
+		Content: content,
+	}
+}
+
+// MarshalLen returns the serial length of ExtensionHeader, including the Length
+// field and the Next Extension Header Type field.
+func (e *ExtensionHeader) MarshalLen() int {
+	return ((len(e.Content) + 2 + 3) / 4) * 4
+}
+
+// marshalTo puts the ExtensionHeader in b with the type of the next one.
+func (e *ExtensionHeader) marshalTo(b []byte, next uint8) error {
+	l := e.MarshalLen()
+	if len(b) < l {
+		return ErrTooShortToMarshal
+	}
+	if l/4 > 0xff {
+		return ErrInvalidLength
+	}
+
+	b[0] = uint8(l / 4)
+	n := copy(b[1:l-1], e.Content)
+	for i := 1 + n; i < l-1; i++ {
+		b[i] = 0
+	}
+	b[l-1] = next
+	return nil
+}
+
+// parseExtensionHeader decodes b as an ExtensionHeader of type typ, and returns
+// it with the type of the next one.
+func parseExtensionHeader(b []byte, typ uint8) (*ExtensionHeader, uint8, error) {
+	if len(b) < 1 {
+		return nil, 0, ErrTooShortToParse
+	}
+
+	l := int(b[0]) * 4
+	if l == 0 {
+		return nil, 0, ErrInvalidLength
+	}
+	if len(b) < l {
+		return nil, 0, ErrTooShortToParse
+	}
+
+	e := &ExtensionHeader{
+		Type:    typ,
+		Content: make([]byte, l-2),
+	}
+	copy(e.Content, b[1:l-1])
+	return e, b[l-1], nil
+}
+
+// ComprehensionRequired reports whether the receiver is required to comprehend
+// the ExtensionHeader, which is indicated by the most significant bit of the type.
+func (e *ExtensionHeader) ComprehensionRequired() bool {
+	return e.Type&0x80 != 0
+}
+
+// String returns the ExtensionHeader values in human readable format.
+func (e *ExtensionHeader) String() string {
+	return fmt.Sprintf("{Type: %#x, Content: %#v}", e.Type, e.Content)
+}
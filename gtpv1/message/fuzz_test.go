@@ -0,0 +1,89 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package message_test
+
+import (
+	"testing"
+
+	v1 "github.com/wmnsk/go-gtp/gtpv1"
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
+	"github.com/wmnsk/go-gtp/gtpv1/testutils"
+)
+
+// fuzzIEs is the set of IEs put in every message used as a seed so that
+// the per-message IE dispatching gets exercised for all the known types.
+var fuzzIEs = []*ie.IE{
+	ie.NewCause(v1.ResCauseRequestAccepted),
+	ie.NewIMSI("123450123456789"),
+	ie.NewRouteingAreaIdentity("123", "45", 0x1111, 0x22),
+	ie.NewRecovery(254),
+	ie.NewSelectionMode(v1.SelectionModeMSorNetworkProvidedAPNSubscribedVerified),
+	ie.NewTEIDDataI(0xdeadbeef),
+	ie.NewTEIDCPlane(0xdeadbeef),
+	ie.NewNSAPI(5),
+	ie.NewChargingID(0xdeadbeef),
+	ie.NewEndUserAddressIPv4("1.1.1.1"),
+	ie.NewAccessPointName("some.apn.example"),
+	ie.NewProtocolConfigurationOptions(
+		0, ie.NewConfigurationProtocolOption(1, []byte{0xde, 0xad, 0xbe, 0xef}),
+	),
+	ie.NewGSNAddress("1.1.1.1"),
+	ie.NewGSNAddress("2.2.2.2"),
+	ie.NewMSISDN("123412345678"),
+	ie.NewQoSProfile([]byte{0xde, 0xad, 0xbe, 0xef}),
+	ie.NewCommonFlags(0, 0, 1, 0, 0, 0, 0, 0),
+	ie.NewRATType(v1.RatTypeUTRAN),
+	ie.NewUserLocationInformationWithSAI("123", "45", 0x1111, 0x2222),
+	ie.NewMSTimeZone(0x00, 0x00),
+}
+
+func FuzzParse(f *testing.F) {
+	for typ := 1; typ <= 0xff; typ++ {
+		b, err := message.Marshal(message.NewGeneric(
+			uint8(typ), testutils.TestBearerInfo.TEID, testutils.TestBearerInfo.Seq, fuzzIEs...,
+		))
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		// Seed only the types that have their own implementation.
+		m, err := message.Parse(b)
+		if err != nil {
+			continue
+		}
+		if _, ok := m.(*message.Generic); ok {
+			continue
+		}
+		f.Add(b)
+	}
+
+	tpdu, err := message.Marshal(message.NewTPDUWithOptions(
+		testutils.TestBearerInfo.TEID, []byte{0xde, 0xad, 0xbe, 0xef},
+		message.WithSequenceNumber(testutils.TestBearerInfo.Seq),
+		message.WithExtensionHeaders(
+			message.NewPDCPPDUNumberExtensionHeader(0x1234),
+			message.NewUDPPortExtensionHeader(2152),
+		),
+	))
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(tpdu)
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		m, err := message.Parse(b)
+		if err != nil {
+			return
+		}
+
+		if _, err := message.Marshal(m); err != nil {
+			t.Fatalf("failed to marshal parsed message: %v", err)
+		}
+	})
+}
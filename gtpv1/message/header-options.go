@@ -0,0 +1,77 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+// HeaderOption configures the optional fields of Header.
+//
+// The Sequence Number, N-PDU Number and Next Extension Header Type fields are
+// present if any of the S, PN and E flags is set, and each flag tells whether the
+// value in the corresponding field is meaningful. With HeaderOptions the flags can
+// be controlled independently, which is useful to interoperate with the peers that
+// expect a specific form of the header.
+type HeaderOption func(h *Header)
+
+// WithSequenceNumber sets the S flag and the Sequence Number.
+func WithSequenceNumber(seq uint16) HeaderOption {
+	return func(h *Header) {
+		h.Flags |= 0x02
+		h.SequenceNumber = seq
+	}
+}
+
+// WithNPDUNumber sets the PN flag and the N-PDU Number.
+func WithNPDUNumber(num uint8) HeaderOption {
+	return func(h *Header) {
+		h.SetNPDUNumber(num)
+	}
+}
+
+// WithExtensionHeaderFlag sets the E flag even if no extension headers follow, in
+// which case the Next Extension Header Type is set to 0.
+func WithExtensionHeaderFlag() HeaderOption {
+	return func(h *Header) {
+		h.Flags |= 0x04
+	}
+}
+
+// WithExtensionHeaders appends the extension headers to the chain, and sets the
+// E flag.
+func WithExtensionHeaders(exts ...*ExtensionHeader) HeaderOption {
+	return func(h *Header) {
+		h.AddExtensionHeaders(exts...)
+	}
+}
+
+// WithoutOptionalFields clears the S, PN and E flags and removes the extension
+// headers, which makes the header the short one of 8 octets.
+//
+// This is to be given before the other options to start from the short header,
+// or to remove the optional fields from the Header created by the constructors
+// like NewTPDUWithSequence.
+func WithoutOptionalFields() HeaderOption {
+	return func(h *Header) {
+		h.Flags &^= 0x07
+		h.SequenceNumber = 0
+		h.NPDUNumber = 0
+		h.ExtensionHeaders = nil
+	}
+}
+
+// NewHeaderWithOptions creates a new Header with the version 1 and the protocol
+// type GTP. The optional fields are not present unless specified with opts.
+func NewHeaderWithOptions(mtype uint8, teid uint32, payload []byte, opts ...HeaderOption) *Header {
+	h := NewHeader(0x30, mtype, teid, 0, payload)
+	return h.WithOptions(opts...)
+}
+
+// WithOptions applies the HeaderOptions to the Header in order and returns it,
+// with the length updated.
+func (h *Header) WithOptions(opts ...HeaderOption) *Header {
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.SetLength()
+	return h
+}
@@ -17,7 +17,14 @@ type Header struct {
 	TEID           uint32
 	SequenceNumber uint16
 	Reserved       uint16
-	Payload        []byte
+	NPDUNumber     uint8
+
+	// ExtensionHeaders is the chain of extension headers in the order they appear.
+	// The E flag and the Next Extension Header Type fields are set by the library
+	// according to this on Marshal.
+	ExtensionHeaders []*ExtensionHeader
+
+	Payload []byte
 }
 
 // NewHeader creates a new Header.
@@ -58,21 +65,49 @@ func (h *Header) MarshalTo(b []byte) error {
 	}
 
 	b[0] = h.Flags
+	if len(h.ExtensionHeaders) > 0 {
+		b[0] |= 0x04
+	}
 	b[1] = h.Type
 	binary.BigEndian.PutUint16(b[2:4], h.Length)
 	binary.BigEndian.PutUint32(b[4:8], h.TEID)
 	offset := 8
-	if h.HasSequence() {
+	if h.hasOptionalFields() {
 		binary.BigEndian.PutUint16(b[offset:offset+2], h.SequenceNumber)
-		// two bytes of padding before payload.
+		b[offset+2] = h.NPDUNumber
+		b[offset+3] = ExtHeaderTypeNoMoreExtensionHeaders
+		if len(h.ExtensionHeaders) > 0 {
+			b[offset+3] = h.ExtensionHeaders[0].Type
+		}
 		offset += 4
 	}
 
-	// two bytes of padding before payload.
+	for i, e := range h.ExtensionHeaders {
+		next := ExtHeaderTypeNoMoreExtensionHeaders
+		if i+1 < len(h.ExtensionHeaders) {
+			next = h.ExtensionHeaders[i+1].Type
+		}
+		if err := e.marshalTo(b[offset:], next); err != nil {
+			return err
+		}
+		offset += e.MarshalLen()
+	}
+
 	copy(b[offset:], h.Payload)
 	return nil
 }
 
+// payloadIn returns the part of b that the payload of a message of length l
+// occupies, so that the IEs are marshaled directly into b without allocating
+// an intermediate buffer. The Payload of the Header refers to b after that.
+func (h *Header) payloadIn(b []byte, l int) ([]byte, error) {
+	offset := h.MarshalLen() - len(h.Payload)
+	if len(b) < l || l < offset {
+		return nil, ErrTooShortToMarshal
+	}
+	return b[offset:l], nil
+}
+
 // ParseHeader decodes given byte sequence as a GTPv1 header.
 func ParseHeader(b []byte) (*Header, error) {
 	h := &Header{}
@@ -85,7 +120,7 @@ func ParseHeader(b []byte) (*Header, error) {
 // UnmarshalBinary sets the values retrieved from byte sequence in GTPv1 header.
 func (h *Header) UnmarshalBinary(b []byte) error {
 	l := len(b)
-	if l < 11 {
+	if l < 8 {
 		return ErrTooShortToParse
 	}
 	var offset = 4
@@ -95,10 +130,25 @@ func (h *Header) UnmarshalBinary(b []byte) error {
 
 	h.TEID = binary.BigEndian.Uint32(b[4:8])
 	offset += 4
-	if h.HasSequence() {
+	h.ExtensionHeaders = nil
+	if h.hasOptionalFields() {
+		if l < offset+4 {
+			return ErrTooShortToParse
+		}
 		h.SequenceNumber = binary.BigEndian.Uint16(b[offset : offset+2])
-		// two bytes of padding before payload.
+		h.NPDUNumber = b[offset+2]
+		next := b[offset+3]
 		offset += 4
+
+		for h.HasExtensionHeader() && next != ExtHeaderTypeNoMoreExtensionHeaders {
+			e, n, err := parseExtensionHeader(b[offset:], next)
+			if err != nil {
+				return err
+			}
+			h.ExtensionHeaders = append(h.ExtensionHeaders, e)
+			offset += e.MarshalLen()
+			next = n
+		}
 	}
 
 	if int(h.Length)+8 != l {
@@ -120,6 +170,60 @@ func (h *Header) HasSequence() bool {
 	return ((int(h.Flags) >> 1) & 0x1) == 1
 }
 
+// HasExtensionHeader determines whether a GTP Header has extension headers by checking the flag.
+func (h *Header) HasExtensionHeader() bool {
+	return ((int(h.Flags) >> 2) & 0x1) == 1
+}
+
+// HasNPDUNumber determines whether a GTP Header has N-PDU Number by checking the flag.
+func (h *Header) HasNPDUNumber() bool {
+	return (int(h.Flags) & 0x1) == 1
+}
+
+// hasOptionalFields reports whether the Sequence Number, N-PDU Number and Next
+// Extension Header Type fields are present, which is the case when any of the
+// E, S and PN flags is set.
+func (h *Header) hasOptionalFields() bool {
+	return h.Flags&0x07 != 0 || len(h.ExtensionHeaders) > 0
+}
+
+// SetNPDUNumber sets the PN flag to 1 and puts the N-PDU Number given into NPDUNumber field.
+func (h *Header) SetNPDUNumber(num uint8) {
+	h.Flags |= 0x01
+	h.NPDUNumber = num
+}
+
+// AddExtensionHeaders appends the extension headers to the end of the chain and
+// sets the E flag. The length of the Header should be updated with SetLength after
+// calling this.
+func (h *Header) AddExtensionHeaders(exts ...*ExtensionHeader) {
+	if len(exts) == 0 {
+		return
+	}
+	h.Flags |= 0x04
+	h.ExtensionHeaders = append(h.ExtensionHeaders, exts...)
+}
+
+// WithExtensionHeaders sets the extension headers to Header and returns it,
+// replacing the existing ones if any.
+func (h *Header) WithExtensionHeaders(exts ...*ExtensionHeader) *Header {
+	h.ExtensionHeaders = nil
+	h.Flags &^= 0x04
+	h.AddExtensionHeaders(exts...)
+	h.SetLength()
+	return h
+}
+
+// ExtensionHeaderByType returns the first extension header of the type given.
+func (h *Header) ExtensionHeaderByType(typ uint8) (*ExtensionHeader, error) {
+	for _, e := range h.ExtensionHeaders {
+		if e.Type == typ {
+			return e, nil
+		}
+	}
+	return nil, ErrExtensionHeaderNotFound
+}
+
 // Sequence returns SequenceNumber in uint16.
 func (h *Header) Sequence() uint16 {
 	return h.SequenceNumber
@@ -133,9 +237,12 @@ func (h *Header) SetSequenceNumber(seq uint16) {
 // MarshalLen returns the serial length of Header.
 func (h *Header) MarshalLen() int {
 	l := len(h.Payload) + 8
-	if h.HasSequence() {
+	if h.hasOptionalFields() {
 		l += 4
 	}
+	for _, e := range h.ExtensionHeaders {
+		l += e.MarshalLen()
+	}
 
 	return l
 }
@@ -157,12 +264,14 @@ func (h *Header) MessageType() uint8 {
 
 // String returns the GTPv1 header values in human readable format.
 func (h *Header) String() string {
-	return fmt.Sprintf("{Flags: %#x, Type: %#x, Length: %d, TEID: %#08x, SequenceNumber: %#04x, Payload: %#v}",
+	return fmt.Sprintf("{Flags: %#x, Type: %#x, Length: %d, TEID: %#08x, SequenceNumber: %#04x, NPDUNumber: %#x, ExtensionHeaders: %v, Payload: %#v}",
 		h.Flags,
 		h.Type,
 		h.Length,
 		h.TEID,
 		h.SequenceNumber,
+		h.NPDUNumber,
+		h.ExtensionHeaders,
 		h.Payload,
 	)
 }
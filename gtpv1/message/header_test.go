@@ -5,6 +5,7 @@
 package message_test
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/wmnsk/go-gtp/gtpv1/message"
@@ -34,6 +35,87 @@ func TestHeader(t *testing.T) {
 				0x32, 0x10, 0x00, 0x08, 0xde, 0xad, 0xbe, 0xef,
 				0xca, 0xfe, 0x00, 0x00, 0xde, 0xad, 0xbe, 0xef,
 			},
+		}, {
+			Description: "WithExtensionHeaders",
+			Structured: message.NewHeader(
+				message.NewHeaderFlags(
+					1, // version
+					1, // Protocol Type
+					1, // Next Extension Header?
+					1, // Sequence Number?
+					0, // N-PDU Number?
+				), //Flags
+				0xff,       // Message type
+				0xdeadbeef, // TEID
+				0xcafe,     // Sequence Number
+				[]byte{ // Payload
+					0xde, 0xad, 0xbe, 0xef,
+				},
+			).WithExtensionHeaders(
+				message.NewExtensionHeader(
+					message.ExtHeaderTypeUDPPort, []byte{0x08, 0x68},
+				),
+				message.NewExtensionHeader(
+					message.ExtHeaderTypePDUSessionContainer, []byte{0x10, 0x09, 0x00, 0x00, 0x00, 0x00},
+				),
+			),
+			Serialized: []byte{
+				0x36, 0xff, 0x00, 0x14, 0xde, 0xad, 0xbe, 0xef,
+				0xca, 0xfe, 0x00, 0x40,
+				0x01, 0x08, 0x68, 0x85,
+				0x02, 0x10, 0x09, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0xde, 0xad, 0xbe, 0xef,
+			},
+		}, {
+			Description: "WithOptions/NoOptionalFields",
+			Structured:  message.NewHeaderWithOptions(0xff, 0xdeadbeef, []byte{0xde, 0xad, 0xbe, 0xef}),
+			Serialized: []byte{
+				0x30, 0xff, 0x00, 0x04, 0xde, 0xad, 0xbe, 0xef,
+				0xde, 0xad, 0xbe, 0xef,
+			},
+		}, {
+			Description: "WithOptions/NPDUNumberOnly",
+			Structured: message.NewHeaderWithOptions(
+				0xff, 0xdeadbeef, []byte{0xde, 0xad, 0xbe, 0xef},
+				message.WithNPDUNumber(0x12),
+			),
+			Serialized: []byte{
+				0x31, 0xff, 0x00, 0x08, 0xde, 0xad, 0xbe, 0xef,
+				0x00, 0x00, 0x12, 0x00, 0xde, 0xad, 0xbe, 0xef,
+			},
+		}, {
+			Description: "WithOptions/AllFlags",
+			Structured: message.NewHeaderWithOptions(
+				0xff, 0xdeadbeef, []byte{0xde, 0xad, 0xbe, 0xef},
+				message.WithSequenceNumber(0xcafe),
+				message.WithNPDUNumber(0x12),
+				message.WithExtensionHeaderFlag(),
+			),
+			Serialized: []byte{
+				0x37, 0xff, 0x00, 0x08, 0xde, 0xad, 0xbe, 0xef,
+				0xca, 0xfe, 0x12, 0x00, 0xde, 0xad, 0xbe, 0xef,
+			},
+		}, {
+			Description: "WithOptions/ExtensionHeaders",
+			Structured: message.NewHeaderWithOptions(
+				0xff, 0xdeadbeef, []byte{0xde, 0xad, 0xbe, 0xef},
+				message.WithExtensionHeaders(message.NewUDPPortExtensionHeader(2152)),
+			),
+			Serialized: []byte{
+				0x34, 0xff, 0x00, 0x0c, 0xde, 0xad, 0xbe, 0xef,
+				0x00, 0x00, 0x00, 0x40,
+				0x01, 0x08, 0x68, 0x00,
+				0xde, 0xad, 0xbe, 0xef,
+			},
+		}, {
+			Description: "WithOptions/WithoutOptionalFields",
+			Structured: message.NewHeader(0x36, 0xff, 0xdeadbeef, 0xcafe, []byte{0xde, 0xad, 0xbe, 0xef}).WithOptions(
+				message.WithoutOptionalFields(),
+			),
+			Serialized: []byte{
+				0x30, 0xff, 0x00, 0x04, 0xde, 0xad, 0xbe, 0xef,
+				0xde, 0xad, 0xbe, 0xef,
+			},
 		},
 	}
 
@@ -45,3 +127,104 @@ func TestHeader(t *testing.T) {
 		return v, nil
 	})
 }
+
+func TestHeaderExtensionHeaders(t *testing.T) {
+	h := message.NewHeader(0x30, message.MsgTypeTPDU, 0xdeadbeef, 0, []byte{0xde, 0xad, 0xbe, 0xef})
+	h.AddExtensionHeaders(message.NewExtensionHeader(message.ExtHeaderTypePDCPPDUNumber, []byte{0x12}))
+	h.SetLength()
+
+	b, err := h.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := message.ParseHeader(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.HasExtensionHeader() {
+		t.Fatal("E flag should be set")
+	}
+
+	e, err := parsed.ExtensionHeaderByType(message.ExtHeaderTypePDCPPDUNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := e.Content, []byte{0x12, 0x00}; !bytes.Equal(got, want) {
+		t.Errorf("wrong content. want: %x, got: %x", want, got)
+	}
+	if !e.ComprehensionRequired() {
+		t.Error("PDCP PDU Number should require comprehension")
+	}
+	if got, want := parsed.Payload, []byte{0xde, 0xad, 0xbe, 0xef}; !bytes.Equal(got, want) {
+		t.Errorf("wrong payload. want: %x, got: %x", want, got)
+	}
+
+	if _, err := parsed.ExtensionHeaderByType(message.ExtHeaderTypeUDPPort); err != message.ErrExtensionHeaderNotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestTypedExtensionHeaders(t *testing.T) {
+	cases := []struct {
+		description string
+		ext         *message.ExtensionHeader
+		serialized  []byte
+		get         func(e *message.ExtensionHeader) (uint16, error)
+		want        uint16
+	}{
+		{
+			"UDPPort",
+			message.NewUDPPortExtensionHeader(2152),
+			[]byte{0x01, 0x08, 0x68, 0x00},
+			(*message.ExtensionHeader).UDPPort,
+			2152,
+		}, {
+			"PDCPPDUNumber",
+			message.NewPDCPPDUNumberExtensionHeader(0x1234),
+			[]byte{0x01, 0x12, 0x34, 0x00},
+			(*message.ExtensionHeader).PDCPPDUNumber,
+			0x1234,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			h := message.NewHeader(0x30, message.MsgTypeTPDU, 0xdeadbeef, 0, []byte{0xde, 0xad, 0xbe, 0xef})
+			h.AddExtensionHeaders(c.ext)
+			h.SetLength()
+
+			b, err := h.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := b[12:16]; !bytes.Equal(got, c.serialized) {
+				t.Errorf("wrong extension header. want: %x, got: %x", c.serialized, got)
+			}
+
+			parsed, err := message.ParseHeader(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e, err := parsed.ExtensionHeaderByType(c.ext.Type)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := c.get(e)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("wrong value. want: %d, got: %d", c.want, got)
+			}
+		})
+	}
+
+	// getters fail on the other types.
+	if _, err := message.NewUDPPortExtensionHeader(2152).PDCPPDUNumber(); err != message.ErrInvalidExtensionHeaderType {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := message.NewPDCPPDUNumberExtensionHeader(1).UDPPort(); err != message.ErrInvalidExtensionHeaderType {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
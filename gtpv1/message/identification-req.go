@@ -0,0 +1,227 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+)
+
+// IdentificationRequest is a IdentificationRequest Header and its IEs above.
+type IdentificationRequest struct {
+	*Header
+	RouteingAreaIdentity *ie.IE
+	PacketTMSI           *ie.IE
+	PTMSISignature       *ie.IE
+	SGSNAddressForCPlane *ie.IE
+	HopCounter           *ie.IE
+	PrivateExtension     *ie.IE
+	AdditionalIEs        []*ie.IE
+}
+
+// NewIdentificationRequest creates a new GTPv1 IdentificationRequest.
+func NewIdentificationRequest(teid uint32, seq uint16, IEs ...*ie.IE) *IdentificationRequest {
+	r := &IdentificationRequest{
+		Header: NewHeader(0x32, MsgTypeIdentificationRequest, teid, seq, nil),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.RouteingAreaIdentity:
+			r.RouteingAreaIdentity = i
+		case ie.PacketTMSI:
+			r.PacketTMSI = i
+		case ie.PTMSISignature:
+			r.PTMSISignature = i
+		case ie.GSNAddress:
+			r.SGSNAddressForCPlane = i
+		case ie.HopCounter:
+			r.HopCounter = i
+		case ie.PrivateExtension:
+			r.PrivateExtension = i
+		default:
+			r.AdditionalIEs = append(r.AdditionalIEs, i)
+		}
+	}
+
+	r.SetLength()
+	return r
+}
+
+// Marshal returns the byte sequence generated from a IdentificationRequest.
+func (r *IdentificationRequest) Marshal() ([]byte, error) {
+	b := make([]byte, r.MarshalLen())
+	if err := r.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// AppendTo appends the byte sequence generated from IdentificationRequest to b and returns the extended buffer.
+func (r *IdentificationRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, r)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (r *IdentificationRequest) MarshalTo(b []byte) error {
+	if len(b) < r.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+	var err error
+	r.Header.Payload, err = r.Header.payloadIn(b, r.MarshalLen())
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if ie := r.RouteingAreaIdentity; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := r.PacketTMSI; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := r.PTMSISignature; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := r.SGSNAddressForCPlane; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := r.HopCounter; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := r.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range r.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	r.Header.SetLength()
+	return r.Header.MarshalTo(b)
+}
+
+// ParseIdentificationRequest decodes a given byte sequence as a IdentificationRequest.
+func ParseIdentificationRequest(b []byte) (*IdentificationRequest, error) {
+	r := &IdentificationRequest{}
+	if err := r.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// UnmarshalBinary decodes a given byte sequence as a IdentificationRequest.
+func (r *IdentificationRequest) UnmarshalBinary(b []byte) error {
+	var err error
+	r.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(r.Header.Payload) < 2 {
+		return nil
+	}
+
+	IEs, err := ie.ParseMultiIEs(r.Header.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.RouteingAreaIdentity:
+			r.RouteingAreaIdentity = i
+		case ie.PacketTMSI:
+			r.PacketTMSI = i
+		case ie.PTMSISignature:
+			r.PTMSISignature = i
+		case ie.GSNAddress:
+			r.SGSNAddressForCPlane = i
+		case ie.HopCounter:
+			r.HopCounter = i
+		case ie.PrivateExtension:
+			r.PrivateExtension = i
+		default:
+			r.AdditionalIEs = append(r.AdditionalIEs, i)
+		}
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (r *IdentificationRequest) MarshalLen() int {
+	l := r.Header.MarshalLen() - len(r.Header.Payload)
+
+	if ie := r.RouteingAreaIdentity; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := r.PacketTMSI; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := r.PTMSISignature; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := r.SGSNAddressForCPlane; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := r.HopCounter; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := r.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range r.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (r *IdentificationRequest) SetLength() {
+	r.Length = uint16(r.MarshalLen() - 8)
+}
+
+// MessageTypeName returns the name of protocol.
+func (r *IdentificationRequest) MessageTypeName() string {
+	return "Identification Request"
+}
+
+// TEID returns the TEID in human-readable string.
+func (r *IdentificationRequest) TEID() uint32 {
+	return r.Header.TEID
+}
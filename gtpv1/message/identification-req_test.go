@@ -0,0 +1,50 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
+	"github.com/wmnsk/go-gtp/gtpv1/testutils"
+)
+
+func TestIdentificationRequest(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "Normal",
+			Structured: message.NewIdentificationRequest(
+				testutils.TestBearerInfo.TEID, testutils.TestBearerInfo.Seq,
+				ie.NewRouteingAreaIdentity("123", "45", 0x1111, 0x22),
+				ie.NewPacketTMSI(0xdeadbeef),
+				ie.NewPTMSISignature(0xbeebee),
+				ie.NewGSNAddress("1.1.1.1"),
+			),
+			Serialized: []byte{
+				// Header
+				0x32, 0x30, 0x00, 0x1b, 0x11, 0x22, 0x33, 0x44,
+				0x00, 0x01, 0x00, 0x00,
+				// RAI
+				0x03, 0x21, 0xf3, 0x54, 0x11, 0x11, 0x22,
+				// P-TMSI
+				0x05, 0xde, 0xad, 0xbe, 0xef,
+				// P-TMSI Signature
+				0x0c, 0xbe, 0xeb, 0xee,
+				// GSN Address
+				0x85, 0x00, 0x04, 0x01, 0x01, 0x01, 0x01,
+			},
+		},
+	}
+
+	testutils.Run(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseIdentificationRequest(b)
+		if err != nil {
+			return nil, err
+		}
+		v.Payload = nil
+		return v, nil
+	})
+}
@@ -0,0 +1,253 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+)
+
+// IdentificationResponse is a IdentificationResponse Header and its IEs above.
+type IdentificationResponse struct {
+	*Header
+	Cause                     *ie.IE
+	IMSI                      *ie.IE
+	AuthenticationTriplets    []*ie.IE
+	AuthenticationQuintuplets []*ie.IE
+	UEUsageType               *ie.IE
+	IOVUpdatesCounter         *ie.IE
+	PrivateExtension          *ie.IE
+	AdditionalIEs             []*ie.IE
+}
+
+// NewIdentificationResponse creates a new GTPv1 IdentificationResponse.
+func NewIdentificationResponse(teid uint32, seq uint16, IEs ...*ie.IE) *IdentificationResponse {
+	r := &IdentificationResponse{
+		Header: NewHeader(0x32, MsgTypeIdentificationResponse, teid, seq, nil),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			r.Cause = i
+		case ie.IMSI:
+			r.IMSI = i
+		case ie.AuthenticationTriplet:
+			r.AuthenticationTriplets = append(r.AuthenticationTriplets, i)
+		case ie.AuthenticationQuintuplet:
+			r.AuthenticationQuintuplets = append(r.AuthenticationQuintuplets, i)
+		case ie.UEUsageType:
+			r.UEUsageType = i
+		case ie.IOVUpdatesCounter:
+			r.IOVUpdatesCounter = i
+		case ie.PrivateExtension:
+			r.PrivateExtension = i
+		default:
+			r.AdditionalIEs = append(r.AdditionalIEs, i)
+		}
+	}
+
+	r.SetLength()
+	return r
+}
+
+// Marshal returns the byte sequence generated from a IdentificationResponse.
+func (r *IdentificationResponse) Marshal() ([]byte, error) {
+	b := make([]byte, r.MarshalLen())
+	if err := r.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// AppendTo appends the byte sequence generated from IdentificationResponse to b and returns the extended buffer.
+func (r *IdentificationResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, r)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (r *IdentificationResponse) MarshalTo(b []byte) error {
+	if len(b) < r.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+	var err error
+	r.Header.Payload, err = r.Header.payloadIn(b, r.MarshalLen())
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if ie := r.Cause; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := r.IMSI; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	for _, ie := range r.AuthenticationTriplets {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	for _, ie := range r.AuthenticationQuintuplets {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := r.UEUsageType; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := r.IOVUpdatesCounter; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := r.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range r.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	r.Header.SetLength()
+	return r.Header.MarshalTo(b)
+}
+
+// ParseIdentificationResponse decodes a given byte sequence as a IdentificationResponse.
+func ParseIdentificationResponse(b []byte) (*IdentificationResponse, error) {
+	r := &IdentificationResponse{}
+	if err := r.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// UnmarshalBinary decodes a given byte sequence as a IdentificationResponse.
+func (r *IdentificationResponse) UnmarshalBinary(b []byte) error {
+	var err error
+	r.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(r.Header.Payload) < 2 {
+		return nil
+	}
+
+	IEs, err := ie.ParseMultiIEs(r.Header.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			r.Cause = i
+		case ie.IMSI:
+			r.IMSI = i
+		case ie.AuthenticationTriplet:
+			r.AuthenticationTriplets = append(r.AuthenticationTriplets, i)
+		case ie.AuthenticationQuintuplet:
+			r.AuthenticationQuintuplets = append(r.AuthenticationQuintuplets, i)
+		case ie.UEUsageType:
+			r.UEUsageType = i
+		case ie.IOVUpdatesCounter:
+			r.IOVUpdatesCounter = i
+		case ie.PrivateExtension:
+			r.PrivateExtension = i
+		default:
+			r.AdditionalIEs = append(r.AdditionalIEs, i)
+		}
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (r *IdentificationResponse) MarshalLen() int {
+	l := r.Header.MarshalLen() - len(r.Header.Payload)
+
+	if ie := r.Cause; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := r.IMSI; ie != nil {
+		l += ie.MarshalLen()
+	}
+	for _, ie := range r.AuthenticationTriplets {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	for _, ie := range r.AuthenticationQuintuplets {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	if ie := r.UEUsageType; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := r.IOVUpdatesCounter; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := r.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range r.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (r *IdentificationResponse) SetLength() {
+	r.Length = uint16(r.MarshalLen() - 8)
+}
+
+// MessageTypeName returns the name of protocol.
+func (r *IdentificationResponse) MessageTypeName() string {
+	return "Identification Response"
+}
+
+// TEID returns the TEID in human-readable string.
+func (r *IdentificationResponse) TEID() uint32 {
+	return r.Header.TEID
+}
@@ -0,0 +1,68 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	v1 "github.com/wmnsk/go-gtp/gtpv1"
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
+	"github.com/wmnsk/go-gtp/gtpv1/testutils"
+)
+
+func TestIdentificationResponse(t *testing.T) {
+	var (
+		rand = []byte{
+			0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+			0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+		}
+		sres = []byte{0x02, 0x02, 0x02, 0x02}
+		kc   = []byte{0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03}
+	)
+
+	cases := []testutils.TestCase{
+		{
+			Description: "Normal",
+			Structured: message.NewIdentificationResponse(
+				testutils.TestBearerInfo.TEID, testutils.TestBearerInfo.Seq,
+				ie.NewCause(v1.ResCauseRequestAccepted),
+				ie.NewIMSI("123451234567890"),
+				ie.NewAuthenticationTriplet(rand, sres, kc),
+				ie.NewAuthenticationTriplet(rand, sres, kc),
+			),
+			Serialized: []byte{
+				// Header
+				0x32, 0x31, 0x00, 0x49, 0x11, 0x22, 0x33, 0x44,
+				0x00, 0x01, 0x00, 0x00,
+				// Cause
+				0x01, 0x80,
+				// IMSI
+				0x02, 0x21, 0x43, 0x15, 0x32, 0x54, 0x76, 0x98, 0xf0,
+				// Authentication Triplet
+				0x09,
+				0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+				0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+				0x02, 0x02, 0x02, 0x02,
+				0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03,
+				// Authentication Triplet
+				0x09,
+				0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+				0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+				0x02, 0x02, 0x02, 0x02,
+				0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03,
+			},
+		},
+	}
+
+	testutils.Run(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseIdentificationResponse(b)
+		if err != nil {
+			return nil, err
+		}
+		v.Payload = nil
+		return v, nil
+	})
+}
@@ -0,0 +1,255 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+)
+
+// MBMSNotificationRequest is a MBMSNotificationRequest Header and its IEs above.
+type MBMSNotificationRequest struct {
+	*Header
+	IMSI                 *ie.IE
+	TEIDCPlane           *ie.IE
+	NSAPI                *ie.IE
+	EndUserAddress       *ie.IE
+	APN                  *ie.IE
+	GGSNAddressForCPlane *ie.IE
+	MBMSPCO              *ie.IE
+	PrivateExtension     *ie.IE
+	AdditionalIEs        []*ie.IE
+}
+
+// NewMBMSNotificationRequest creates a new GTPv1 MBMSNotificationRequest.
+func NewMBMSNotificationRequest(teid uint32, seq uint16, IEs ...*ie.IE) *MBMSNotificationRequest {
+	m := &MBMSNotificationRequest{
+		Header: NewHeader(0x32, MsgTypeMBMSNotificationRequest, teid, seq, nil),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.IMSI:
+			m.IMSI = i
+		case ie.TEIDCPlane:
+			m.TEIDCPlane = i
+		case ie.NSAPI:
+			m.NSAPI = i
+		case ie.EndUserAddress:
+			m.EndUserAddress = i
+		case ie.AccessPointName:
+			m.APN = i
+		case ie.GSNAddress:
+			m.GGSNAddressForCPlane = i
+		case ie.MBMSProtocolConfigurationOptions:
+			m.MBMSPCO = i
+		case ie.PrivateExtension:
+			m.PrivateExtension = i
+		default:
+			m.AdditionalIEs = append(m.AdditionalIEs, i)
+		}
+	}
+
+	m.SetLength()
+	return m
+}
+
+// Marshal returns the byte sequence generated from a MBMSNotificationRequest.
+func (m *MBMSNotificationRequest) Marshal() ([]byte, error) {
+	b := make([]byte, m.MarshalLen())
+	if err := m.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// AppendTo appends the byte sequence generated from MBMSNotificationRequest to b and returns the extended buffer.
+func (m *MBMSNotificationRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (m *MBMSNotificationRequest) MarshalTo(b []byte) error {
+	if len(b) < m.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if ie := m.IMSI; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.TEIDCPlane; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.NSAPI; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.EndUserAddress; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.APN; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.GGSNAddressForCPlane; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.MBMSPCO; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range m.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	m.Header.SetLength()
+	return m.Header.MarshalTo(b)
+}
+
+// ParseMBMSNotificationRequest decodes a given byte sequence as a MBMSNotificationRequest.
+func ParseMBMSNotificationRequest(b []byte) (*MBMSNotificationRequest, error) {
+	m := &MBMSNotificationRequest{}
+	if err := m.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// UnmarshalBinary decodes a given byte sequence as a MBMSNotificationRequest.
+func (m *MBMSNotificationRequest) UnmarshalBinary(b []byte) error {
+	var err error
+	m.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(m.Header.Payload) < 2 {
+		return nil
+	}
+
+	IEs, err := ie.ParseMultiIEs(m.Header.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.IMSI:
+			m.IMSI = i
+		case ie.TEIDCPlane:
+			m.TEIDCPlane = i
+		case ie.NSAPI:
+			m.NSAPI = i
+		case ie.EndUserAddress:
+			m.EndUserAddress = i
+		case ie.AccessPointName:
+			m.APN = i
+		case ie.GSNAddress:
+			m.GGSNAddressForCPlane = i
+		case ie.MBMSProtocolConfigurationOptions:
+			m.MBMSPCO = i
+		case ie.PrivateExtension:
+			m.PrivateExtension = i
+		default:
+			m.AdditionalIEs = append(m.AdditionalIEs, i)
+		}
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (m *MBMSNotificationRequest) MarshalLen() int {
+	l := m.Header.MarshalLen() - len(m.Header.Payload)
+
+	if ie := m.IMSI; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.TEIDCPlane; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.NSAPI; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.EndUserAddress; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.APN; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.GGSNAddressForCPlane; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.MBMSPCO; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range m.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (m *MBMSNotificationRequest) SetLength() {
+	m.Length = uint16(m.MarshalLen() - 8)
+}
+
+// MessageTypeName returns the name of protocol.
+func (m *MBMSNotificationRequest) MessageTypeName() string {
+	return "MBMS Notification Request"
+}
+
+// TEID returns the TEID in human-readable string.
+func (m *MBMSNotificationRequest) TEID() uint32 {
+	return m.Header.TEID
+}
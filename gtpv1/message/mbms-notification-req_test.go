@@ -0,0 +1,57 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
+	"github.com/wmnsk/go-gtp/gtpv1/testutils"
+)
+
+func TestMBMSNotificationRequest(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "Normal",
+			Structured: message.NewMBMSNotificationRequest(
+				testutils.TestBearerInfo.TEID, testutils.TestBearerInfo.Seq,
+				ie.NewIMSI("123451234567890"),
+				ie.NewTEIDCPlane(0xdeadbeef),
+				ie.NewNSAPI(5),
+				ie.NewEndUserAddress("1.1.1.1"),
+				ie.NewAccessPointName("some.apn.example"),
+				ie.NewGSNAddress("1.1.1.1"),
+			),
+			Serialized: []byte{
+				// Header
+				0x32, 0x60, 0x00, 0x38, 0x11, 0x22, 0x33, 0x44,
+				0x00, 0x01, 0x00, 0x00,
+				// IMSI
+				0x02, 0x21, 0x43, 0x15, 0x32, 0x54, 0x76, 0x98, 0xf0,
+				// TEID-C
+				0x11, 0xde, 0xad, 0xbe, 0xef,
+				// NSAPI
+				0x14, 0x05,
+				// End User Address
+				0x80, 0x00, 0x06, 0xf1, 0x21, 0x01, 0x01, 0x01, 0x01,
+				// APN
+				0x83, 0x00, 0x11, 0x04, 0x73, 0x6f, 0x6d, 0x65, 0x03, 0x61, 0x70, 0x6e, 0x07, 0x65, 0x78, 0x61,
+				0x6d, 0x70, 0x6c, 0x65,
+				// GSN Address
+				0x85, 0x00, 0x04, 0x01, 0x01, 0x01, 0x01,
+			},
+		},
+	}
+
+	testutils.Run(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseMBMSNotificationRequest(b)
+		if err != nil {
+			return nil, err
+		}
+		v.Payload = nil
+		return v, nil
+	})
+}
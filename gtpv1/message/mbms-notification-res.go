@@ -0,0 +1,171 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+)
+
+// MBMSNotificationResponse is a MBMSNotificationResponse Header and its IEs above.
+type MBMSNotificationResponse struct {
+	*Header
+	Cause            *ie.IE
+	PrivateExtension *ie.IE
+	AdditionalIEs    []*ie.IE
+}
+
+// NewMBMSNotificationResponse creates a new GTPv1 MBMSNotificationResponse.
+func NewMBMSNotificationResponse(teid uint32, seq uint16, IEs ...*ie.IE) *MBMSNotificationResponse {
+	m := &MBMSNotificationResponse{
+		Header: NewHeader(0x32, MsgTypeMBMSNotificationResponse, teid, seq, nil),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			m.Cause = i
+		case ie.PrivateExtension:
+			m.PrivateExtension = i
+		default:
+			m.AdditionalIEs = append(m.AdditionalIEs, i)
+		}
+	}
+
+	m.SetLength()
+	return m
+}
+
+// Marshal returns the byte sequence generated from a MBMSNotificationResponse.
+func (m *MBMSNotificationResponse) Marshal() ([]byte, error) {
+	b := make([]byte, m.MarshalLen())
+	if err := m.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// AppendTo appends the byte sequence generated from MBMSNotificationResponse to b and returns the extended buffer.
+func (m *MBMSNotificationResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (m *MBMSNotificationResponse) MarshalTo(b []byte) error {
+	if len(b) < m.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if ie := m.Cause; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range m.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	m.Header.SetLength()
+	return m.Header.MarshalTo(b)
+}
+
+// ParseMBMSNotificationResponse decodes a given byte sequence as a MBMSNotificationResponse.
+func ParseMBMSNotificationResponse(b []byte) (*MBMSNotificationResponse, error) {
+	m := &MBMSNotificationResponse{}
+	if err := m.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// UnmarshalBinary decodes a given byte sequence as a MBMSNotificationResponse.
+func (m *MBMSNotificationResponse) UnmarshalBinary(b []byte) error {
+	var err error
+	m.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(m.Header.Payload) < 2 {
+		return nil
+	}
+
+	IEs, err := ie.ParseMultiIEs(m.Header.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			m.Cause = i
+		case ie.PrivateExtension:
+			m.PrivateExtension = i
+		default:
+			m.AdditionalIEs = append(m.AdditionalIEs, i)
+		}
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (m *MBMSNotificationResponse) MarshalLen() int {
+	l := m.Header.MarshalLen() - len(m.Header.Payload)
+
+	if ie := m.Cause; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range m.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (m *MBMSNotificationResponse) SetLength() {
+	m.Length = uint16(m.MarshalLen() - 8)
+}
+
+// MessageTypeName returns the name of protocol.
+func (m *MBMSNotificationResponse) MessageTypeName() string {
+	return "MBMS Notification Response"
+}
+
+// TEID returns the TEID in human-readable string.
+func (m *MBMSNotificationResponse) TEID() uint32 {
+	return m.Header.TEID
+}
@@ -0,0 +1,73 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
+	"github.com/wmnsk/go-gtp/gtpv1/testutils"
+)
+
+func TestMBMSSessionStartRequest(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "Normal",
+			Structured: message.NewMBMSSessionStartRequest(
+				testutils.TestBearerInfo.TEID, testutils.TestBearerInfo.Seq,
+				ie.NewTEIDCPlane(0xdeadbeef),
+				ie.NewEndUserAddress("1.1.1.1"),
+				ie.NewAccessPointName("some.apn.example"),
+				ie.NewGSNAddress("1.1.1.1"),
+				ie.NewQoSProfile([]byte{0x01, 0x02, 0x03, 0x04}),
+				ie.NewCommonFlags(0, 0, 0, 0, 0, 0, 0, 0),
+				ie.NewTemporaryMobileGroupIdentity(0x123456, "123", "45"),
+				ie.NewMBMSServiceArea([]byte{0x01, 0x00, 0x01}),
+				ie.NewMBMS2G3GIndicator(ie.MBMS2G3GIndicatorBoth),
+				ie.NewMBMSSessionDuration(time.Hour),
+				ie.NewMBMSTimeToDataTransfer(10),
+			),
+			Serialized: []byte{
+				// Header
+				0x32, 0x74, 0x00, 0x55, 0x11, 0x22, 0x33, 0x44,
+				0x00, 0x01, 0x00, 0x00,
+				// TEID-C
+				0x11, 0xde, 0xad, 0xbe, 0xef,
+				// End User Address
+				0x80, 0x00, 0x06, 0xf1, 0x21, 0x01, 0x01, 0x01, 0x01,
+				// APN
+				0x83, 0x00, 0x11, 0x04, 0x73, 0x6f, 0x6d, 0x65, 0x03, 0x61, 0x70, 0x6e, 0x07, 0x65, 0x78, 0x61,
+				0x6d, 0x70, 0x6c, 0x65,
+				// GSN Address
+				0x85, 0x00, 0x04, 0x01, 0x01, 0x01, 0x01,
+				// QoS Profile
+				0x87, 0x00, 0x04, 0x01, 0x02, 0x03, 0x04,
+				// Common Flags
+				0x94, 0x00, 0x01, 0x00,
+				// TMGI
+				0x9d, 0x00, 0x06, 0x12, 0x34, 0x56, 0x21, 0xf3, 0x54,
+				// MBMS Service Area
+				0xa0, 0x00, 0x03, 0x01, 0x00, 0x01,
+				// MBMS 2G/3G Indicator
+				0xa6, 0x00, 0x01, 0x02,
+				// MBMS Session Duration
+				0xa8, 0x00, 0x03, 0x07, 0x08, 0x00,
+				// MBMS Time To Data Transfer
+				0xab, 0x00, 0x01, 0x0a,
+			},
+		},
+	}
+
+	testutils.Run(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseMBMSSessionStartRequest(b)
+		if err != nil {
+			return nil, err
+		}
+		v.Payload = nil
+		return v, nil
+	})
+}
@@ -0,0 +1,199 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+)
+
+// MBMSSessionStopRequest is a MBMSSessionStopRequest Header and its IEs above.
+type MBMSSessionStopRequest struct {
+	*Header
+	EndUserAddress     *ie.IE
+	APN                *ie.IE
+	MBMSFlowIdentifier *ie.IE
+	PrivateExtension   *ie.IE
+	AdditionalIEs      []*ie.IE
+}
+
+// NewMBMSSessionStopRequest creates a new GTPv1 MBMSSessionStopRequest.
+func NewMBMSSessionStopRequest(teid uint32, seq uint16, IEs ...*ie.IE) *MBMSSessionStopRequest {
+	m := &MBMSSessionStopRequest{
+		Header: NewHeader(0x32, MsgTypeMBMSSessionStopRequest, teid, seq, nil),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.EndUserAddress:
+			m.EndUserAddress = i
+		case ie.AccessPointName:
+			m.APN = i
+		case ie.MBMSFlowIdentifier:
+			m.MBMSFlowIdentifier = i
+		case ie.PrivateExtension:
+			m.PrivateExtension = i
+		default:
+			m.AdditionalIEs = append(m.AdditionalIEs, i)
+		}
+	}
+
+	m.SetLength()
+	return m
+}
+
+// Marshal returns the byte sequence generated from a MBMSSessionStopRequest.
+func (m *MBMSSessionStopRequest) Marshal() ([]byte, error) {
+	b := make([]byte, m.MarshalLen())
+	if err := m.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// AppendTo appends the byte sequence generated from MBMSSessionStopRequest to b and returns the extended buffer.
+func (m *MBMSSessionStopRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (m *MBMSSessionStopRequest) MarshalTo(b []byte) error {
+	if len(b) < m.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if ie := m.EndUserAddress; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.APN; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.MBMSFlowIdentifier; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range m.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	m.Header.SetLength()
+	return m.Header.MarshalTo(b)
+}
+
+// ParseMBMSSessionStopRequest decodes a given byte sequence as a MBMSSessionStopRequest.
+func ParseMBMSSessionStopRequest(b []byte) (*MBMSSessionStopRequest, error) {
+	m := &MBMSSessionStopRequest{}
+	if err := m.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// UnmarshalBinary decodes a given byte sequence as a MBMSSessionStopRequest.
+func (m *MBMSSessionStopRequest) UnmarshalBinary(b []byte) error {
+	var err error
+	m.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(m.Header.Payload) < 2 {
+		return nil
+	}
+
+	IEs, err := ie.ParseMultiIEs(m.Header.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.EndUserAddress:
+			m.EndUserAddress = i
+		case ie.AccessPointName:
+			m.APN = i
+		case ie.MBMSFlowIdentifier:
+			m.MBMSFlowIdentifier = i
+		case ie.PrivateExtension:
+			m.PrivateExtension = i
+		default:
+			m.AdditionalIEs = append(m.AdditionalIEs, i)
+		}
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (m *MBMSSessionStopRequest) MarshalLen() int {
+	l := m.Header.MarshalLen() - len(m.Header.Payload)
+
+	if ie := m.EndUserAddress; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.APN; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.MBMSFlowIdentifier; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range m.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (m *MBMSSessionStopRequest) SetLength() {
+	m.Length = uint16(m.MarshalLen() - 8)
+}
+
+// MessageTypeName returns the name of protocol.
+func (m *MBMSSessionStopRequest) MessageTypeName() string {
+	return "MBMS Session Stop Request"
+}
+
+// TEID returns the TEID in human-readable string.
+func (m *MBMSSessionStopRequest) TEID() uint32 {
+	return m.Header.TEID
+}
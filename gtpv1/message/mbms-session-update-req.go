@@ -0,0 +1,297 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+)
+
+// MBMSSessionUpdateRequest is a MBMSSessionUpdateRequest Header and its IEs above.
+type MBMSSessionUpdateRequest struct {
+	*Header
+	TEIDCPlane                  *ie.IE
+	EndUserAddress              *ie.IE
+	APN                         *ie.IE
+	GGSNAddressForCPlane        *ie.IE
+	TMGI                        *ie.IE
+	MBMSSessionDuration         *ie.IE
+	MBMSServiceArea             *ie.IE
+	MBMSSessionIdentifier       *ie.IE
+	MBMSSessionRepetitionNumber *ie.IE
+	MBMSFlowIdentifier          *ie.IE
+	PrivateExtension            *ie.IE
+	AdditionalIEs               []*ie.IE
+}
+
+// NewMBMSSessionUpdateRequest creates a new GTPv1 MBMSSessionUpdateRequest.
+func NewMBMSSessionUpdateRequest(teid uint32, seq uint16, IEs ...*ie.IE) *MBMSSessionUpdateRequest {
+	m := &MBMSSessionUpdateRequest{
+		Header: NewHeader(0x32, MsgTypeMBMSSessionUpdateRequest, teid, seq, nil),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.TEIDCPlane:
+			m.TEIDCPlane = i
+		case ie.EndUserAddress:
+			m.EndUserAddress = i
+		case ie.AccessPointName:
+			m.APN = i
+		case ie.GSNAddress:
+			m.GGSNAddressForCPlane = i
+		case ie.TemporaryMobileGroupIdentity:
+			m.TMGI = i
+		case ie.MBMSSessionDuration:
+			m.MBMSSessionDuration = i
+		case ie.MBMSServiceArea:
+			m.MBMSServiceArea = i
+		case ie.MBMSSessionIdentifier:
+			m.MBMSSessionIdentifier = i
+		case ie.MBMSSessionRepetitionNumber:
+			m.MBMSSessionRepetitionNumber = i
+		case ie.MBMSFlowIdentifier:
+			m.MBMSFlowIdentifier = i
+		case ie.PrivateExtension:
+			m.PrivateExtension = i
+		default:
+			m.AdditionalIEs = append(m.AdditionalIEs, i)
+		}
+	}
+
+	m.SetLength()
+	return m
+}
+
+// Marshal returns the byte sequence generated from a MBMSSessionUpdateRequest.
+func (m *MBMSSessionUpdateRequest) Marshal() ([]byte, error) {
+	b := make([]byte, m.MarshalLen())
+	if err := m.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// AppendTo appends the byte sequence generated from MBMSSessionUpdateRequest to b and returns the extended buffer.
+func (m *MBMSSessionUpdateRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (m *MBMSSessionUpdateRequest) MarshalTo(b []byte) error {
+	if len(b) < m.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if ie := m.TEIDCPlane; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.EndUserAddress; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.APN; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.GGSNAddressForCPlane; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.TMGI; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.MBMSSessionDuration; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.MBMSServiceArea; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.MBMSSessionIdentifier; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.MBMSSessionRepetitionNumber; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.MBMSFlowIdentifier; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range m.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	m.Header.SetLength()
+	return m.Header.MarshalTo(b)
+}
+
+// ParseMBMSSessionUpdateRequest decodes a given byte sequence as a MBMSSessionUpdateRequest.
+func ParseMBMSSessionUpdateRequest(b []byte) (*MBMSSessionUpdateRequest, error) {
+	m := &MBMSSessionUpdateRequest{}
+	if err := m.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// UnmarshalBinary decodes a given byte sequence as a MBMSSessionUpdateRequest.
+func (m *MBMSSessionUpdateRequest) UnmarshalBinary(b []byte) error {
+	var err error
+	m.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(m.Header.Payload) < 2 {
+		return nil
+	}
+
+	IEs, err := ie.ParseMultiIEs(m.Header.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.TEIDCPlane:
+			m.TEIDCPlane = i
+		case ie.EndUserAddress:
+			m.EndUserAddress = i
+		case ie.AccessPointName:
+			m.APN = i
+		case ie.GSNAddress:
+			m.GGSNAddressForCPlane = i
+		case ie.TemporaryMobileGroupIdentity:
+			m.TMGI = i
+		case ie.MBMSSessionDuration:
+			m.MBMSSessionDuration = i
+		case ie.MBMSServiceArea:
+			m.MBMSServiceArea = i
+		case ie.MBMSSessionIdentifier:
+			m.MBMSSessionIdentifier = i
+		case ie.MBMSSessionRepetitionNumber:
+			m.MBMSSessionRepetitionNumber = i
+		case ie.MBMSFlowIdentifier:
+			m.MBMSFlowIdentifier = i
+		case ie.PrivateExtension:
+			m.PrivateExtension = i
+		default:
+			m.AdditionalIEs = append(m.AdditionalIEs, i)
+		}
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (m *MBMSSessionUpdateRequest) MarshalLen() int {
+	l := m.Header.MarshalLen() - len(m.Header.Payload)
+
+	if ie := m.TEIDCPlane; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.EndUserAddress; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.APN; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.GGSNAddressForCPlane; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.TMGI; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.MBMSSessionDuration; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.MBMSServiceArea; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.MBMSSessionIdentifier; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.MBMSSessionRepetitionNumber; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.MBMSFlowIdentifier; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range m.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (m *MBMSSessionUpdateRequest) SetLength() {
+	m.Length = uint16(m.MarshalLen() - 8)
+}
+
+// MessageTypeName returns the name of protocol.
+func (m *MBMSSessionUpdateRequest) MessageTypeName() string {
+	return "MBMS Session Update Request"
+}
+
+// TEID returns the TEID in human-readable string.
+func (m *MBMSSessionUpdateRequest) TEID() uint32 {
+	return m.Header.TEID
+}
@@ -0,0 +1,231 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+)
+
+// MBMSSessionUpdateResponse is a MBMSSessionUpdateResponse Header and its IEs above.
+type MBMSSessionUpdateResponse struct {
+	*Header
+	Cause                *ie.IE
+	TEIDDataI            *ie.IE
+	TEIDCPlane           *ie.IE
+	SGSNAddressForDataI  *ie.IE
+	SGSNAddressForCPlane *ie.IE
+	PrivateExtension     *ie.IE
+	AdditionalIEs        []*ie.IE
+}
+
+// NewMBMSSessionUpdateResponse creates a new GTPv1 MBMSSessionUpdateResponse.
+func NewMBMSSessionUpdateResponse(teid uint32, seq uint16, IEs ...*ie.IE) *MBMSSessionUpdateResponse {
+	m := &MBMSSessionUpdateResponse{
+		Header: NewHeader(0x32, MsgTypeMBMSSessionUpdateResponse, teid, seq, nil),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			m.Cause = i
+		case ie.TEIDDataI:
+			m.TEIDDataI = i
+		case ie.TEIDCPlane:
+			m.TEIDCPlane = i
+		case ie.GSNAddress:
+			if m.SGSNAddressForDataI == nil {
+				m.SGSNAddressForDataI = i
+			} else if m.SGSNAddressForCPlane == nil {
+				m.SGSNAddressForCPlane = i
+			}
+		case ie.PrivateExtension:
+			m.PrivateExtension = i
+		default:
+			m.AdditionalIEs = append(m.AdditionalIEs, i)
+		}
+	}
+
+	m.SetLength()
+	return m
+}
+
+// Marshal returns the byte sequence generated from a MBMSSessionUpdateResponse.
+func (m *MBMSSessionUpdateResponse) Marshal() ([]byte, error) {
+	b := make([]byte, m.MarshalLen())
+	if err := m.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// AppendTo appends the byte sequence generated from MBMSSessionUpdateResponse to b and returns the extended buffer.
+func (m *MBMSSessionUpdateResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (m *MBMSSessionUpdateResponse) MarshalTo(b []byte) error {
+	if len(b) < m.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if ie := m.Cause; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.TEIDDataI; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.TEIDCPlane; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.SGSNAddressForDataI; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.SGSNAddressForCPlane; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range m.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	m.Header.SetLength()
+	return m.Header.MarshalTo(b)
+}
+
+// ParseMBMSSessionUpdateResponse decodes a given byte sequence as a MBMSSessionUpdateResponse.
+func ParseMBMSSessionUpdateResponse(b []byte) (*MBMSSessionUpdateResponse, error) {
+	m := &MBMSSessionUpdateResponse{}
+	if err := m.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// UnmarshalBinary decodes a given byte sequence as a MBMSSessionUpdateResponse.
+func (m *MBMSSessionUpdateResponse) UnmarshalBinary(b []byte) error {
+	var err error
+	m.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(m.Header.Payload) < 2 {
+		return nil
+	}
+
+	IEs, err := ie.ParseMultiIEs(m.Header.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			m.Cause = i
+		case ie.TEIDDataI:
+			m.TEIDDataI = i
+		case ie.TEIDCPlane:
+			m.TEIDCPlane = i
+		case ie.GSNAddress:
+			if m.SGSNAddressForDataI == nil {
+				m.SGSNAddressForDataI = i
+			} else if m.SGSNAddressForCPlane == nil {
+				m.SGSNAddressForCPlane = i
+			}
+		case ie.PrivateExtension:
+			m.PrivateExtension = i
+		default:
+			m.AdditionalIEs = append(m.AdditionalIEs, i)
+		}
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (m *MBMSSessionUpdateResponse) MarshalLen() int {
+	l := m.Header.MarshalLen() - len(m.Header.Payload)
+
+	if ie := m.Cause; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.TEIDDataI; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.TEIDCPlane; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.SGSNAddressForDataI; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.SGSNAddressForCPlane; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range m.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (m *MBMSSessionUpdateResponse) SetLength() {
+	m.Length = uint16(m.MarshalLen() - 8)
+}
+
+// MessageTypeName returns the name of protocol.
+func (m *MBMSSessionUpdateResponse) MessageTypeName() string {
+	return "MBMS Session Update Response"
+}
+
+// TEID returns the TEID in human-readable string.
+func (m *MBMSSessionUpdateResponse) TEID() uint32 {
+	return m.Header.TEID
+}
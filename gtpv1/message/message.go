@@ -7,6 +7,8 @@ Package message provides encoding/decoding feature of GTPv1 protocol.
 */
 package message
 
+import "github.com/wmnsk/go-gtp/utils"
+
 // Message Type definitions.
 const (
 	_ uint8 = iota
@@ -40,7 +42,7 @@ const (
 	MsgTypePDUNotificationResponse
 	MsgTypePDUNotificationRejectRequest
 	MsgTypePDUNotificationRejectResponse
-	_
+	MsgTypeSupportedExtensionHeadersNotification
 	MsgTypeSendRoutingInfoRequest
 	MsgTypeSendRoutingInfoResponse
 	MsgTypeFailureReportRequest
@@ -57,6 +59,21 @@ const (
 	_
 	_
 	_
+	MsgTypeIdentificationRequest // 48
+	MsgTypeIdentificationResponse
+	MsgTypeSGSNContextRequest
+	MsgTypeSGSNContextResponse
+	MsgTypeSGSNContextAcknowledge
+	MsgTypeForwardRelocationRequest
+	MsgTypeForwardRelocationResponse
+	MsgTypeForwardRelocationComplete
+	MsgTypeRelocationCancelRequest
+	MsgTypeRelocationCancelResponse
+	MsgTypeForwardSRNSContext
+	MsgTypeForwardRelocationCompleteAcknowledge
+	MsgTypeForwardSRNSContextAcknowledge
+	MsgTypeUERegistrationQueryRequest
+	MsgTypeUERegistrationQueryResponse
 	_
 	_
 	_
@@ -64,14 +81,61 @@ const (
 	_
 	_
 	_
+	MsgTypeRANInformationRelay
 	_
-	MsgTypeIdentificationRequest // 48
-	MsgTypeIdentificationResponse
-	MsgTypeSGSNContextRequest
-	MsgTypeSGSNContextResponse
-	MsgTypeSGSNContextAcknowledge
+	_
+	_
+	_
+	_
+	_
+	_
+	_
+	_
+	_
+	_
+	_
+	_
+	_
+	_
+	_
+	_
+	_
+	_
+	_
+	_
+	_
+	_
+	_
+	_
+	MsgTypeMBMSNotificationRequest // 96
+	MsgTypeMBMSNotificationResponse
+	MsgTypeMBMSNotificationRejectRequest
+	MsgTypeMBMSNotificationRejectResponse
+	MsgTypeCreateMBMSContextRequest
+	MsgTypeCreateMBMSContextResponse
+	MsgTypeUpdateMBMSContextRequest
+	MsgTypeUpdateMBMSContextResponse
+	MsgTypeDeleteMBMSContextRequest
+	MsgTypeDeleteMBMSContextResponse
+	_
+	_
+	_
+	_
+	_
+	_
+	MsgTypeMBMSRegistrationRequest // 112
+	MsgTypeMBMSRegistrationResponse
+	MsgTypeMBMSDeRegistrationRequest
+	MsgTypeMBMSDeRegistrationResponse
+	MsgTypeMBMSSessionStartRequest
+	MsgTypeMBMSSessionStartResponse
+	MsgTypeMBMSSessionStopRequest
+	MsgTypeMBMSSessionStopResponse
+	MsgTypeMBMSSessionUpdateRequest
+	MsgTypeMBMSSessionUpdateResponse
 	MsgTypeDataRecordTransferRequest  uint8 = 240
 	MsgTypeDataRecordTransferResponse uint8 = 241
+	MsgTypeEndMarker                  uint8 = 254
 	MsgTypeTPDU                       uint8 = 255
 )
 
@@ -104,8 +168,25 @@ func Marshal(g Message) ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from a Message instance to b
+// and returns the extended buffer. b is reallocated only when its capacity is
+// not enough, so the buffers can be reused without allocating.
+func AppendTo(b []byte, m Message) ([]byte, error) {
+	l := len(b)
+	b = utils.Grow(b, m.MarshalLen())
+	if err := m.MarshalTo(b[l:]); err != nil {
+		return b[:l], err
+	}
+
+	return b, nil
+}
+
 // Parse decodes the given bytes as Message.
 func Parse(b []byte) (Message, error) {
+	if len(b) < 2 {
+		return nil, ErrTooShortToParse
+	}
+
 	var m Message
 
 	switch b[1] {
@@ -147,15 +228,17 @@ func Parse(b []byte) (Message, error) {
 	*/
 	case MsgTypeErrorIndication:
 		m = &ErrorIndication{}
+	case MsgTypePDUNotificationRequest:
+		m = &PDUNotificationRequest{}
+	case MsgTypePDUNotificationResponse:
+		m = &PDUNotificationResponse{}
+	case MsgTypePDUNotificationRejectRequest:
+		m = &PDUNotificationRejectRequest{}
+	case MsgTypePDUNotificationRejectResponse:
+		m = &PDUNotificationRejectResponse{}
+	case MsgTypeSupportedExtensionHeadersNotification:
+		m = &SupportedExtensionHeadersNotification{}
 	/* XXX - Implement!
-	case MsgTypePduNotificationRequest:
-		m = &PduNotificationReq{}
-	case MsgTypePduNotificationResponse:
-		m = &PduNotificationRes{}
-	case MsgTypePduNotificationRejectRequest:
-		m = &PduNotificationRejectReq{}
-	case MsgTypePduNotificationRejectResponse:
-		m = &PduNotificationRejectRes{}
 	case MsgTypeSendRoutingInfoRequest:
 		m = &SendRoutingInfoReq{}
 	case MsgTypeSendRoutingInfoResponse:
@@ -168,21 +251,43 @@ func Parse(b []byte) (Message, error) {
 		m = &NoteMsPresentReq{}
 	case MsgTypeNoteMsPresentResponse:
 		m = &NoteMsPresentRes{}
+	*/
 	case MsgTypeIdentificationRequest:
-		m = &IdentificationReq{}
+		m = &IdentificationRequest{}
 	case MsgTypeIdentificationResponse:
-		m = &IdentificationRes{}
-	case MsgTypeSgsnContextRequest:
-		m = &SgsnContextReq{}
-	case MsgTypeSgsnContextResponse:
-		m = &SgsnContextRes{}
-	case MsgTypeSgsnContextAcknowledge:
-		m = &SgsnContextAck{}
+		m = &IdentificationResponse{}
+	case MsgTypeSGSNContextRequest:
+		m = &SGSNContextRequest{}
+	case MsgTypeSGSNContextResponse:
+		m = &SGSNContextResponse{}
+	case MsgTypeSGSNContextAcknowledge:
+		m = &SGSNContextAcknowledge{}
+	case MsgTypeRANInformationRelay:
+		m = &RANInformationRelay{}
+	case MsgTypeMBMSNotificationRequest:
+		m = &MBMSNotificationRequest{}
+	case MsgTypeMBMSNotificationResponse:
+		m = &MBMSNotificationResponse{}
+	case MsgTypeMBMSSessionStartRequest:
+		m = &MBMSSessionStartRequest{}
+	case MsgTypeMBMSSessionStartResponse:
+		m = &MBMSSessionStartResponse{}
+	case MsgTypeMBMSSessionStopRequest:
+		m = &MBMSSessionStopRequest{}
+	case MsgTypeMBMSSessionStopResponse:
+		m = &MBMSSessionStopResponse{}
+	case MsgTypeMBMSSessionUpdateRequest:
+		m = &MBMSSessionUpdateRequest{}
+	case MsgTypeMBMSSessionUpdateResponse:
+		m = &MBMSSessionUpdateResponse{}
+	/* XXX - Implement!
 	case MsgTypeDataRecordTransferRequest:
 		m = &DataRecordTransferReq{}
 	case MsgTypeDataRecordTransferResponse:
 		m = &DataRecordTransferRes{}
 	*/
+	case MsgTypeEndMarker:
+		m = &EndMarker{}
 	case MsgTypeTPDU:
 		m = &TPDU{}
 	default:
@@ -0,0 +1,545 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// PDU Type definitions for NR RAN Container, defined in TS 38.425.
+const (
+	PDUTypeDLUserData           uint8 = 0
+	PDUTypeDLDataDeliveryStatus uint8 = 1
+)
+
+// DLDiscardBlock is a block of NR PDCP PDUs to be discarded, given in DL USER DATA.
+type DLDiscardBlock struct {
+	Start uint32
+	Size  uint8
+}
+
+// SNRange is a range of sequence numbers, given in DL DATA DELIVERY STATUS.
+type SNRange struct {
+	Start uint32
+	End   uint32
+}
+
+// DLUserData is the DL USER DATA frame carried in the NR RAN Container extension
+// header, defined in TS 38.425 5.5.2.1.
+//
+// The optional fields are encoded only when the corresponding flag(DLFlush,
+// DLDiscardBlocks, ReportDelivered) is set. The number of blocks is taken from
+// DiscardBlocks.
+type DLUserData struct {
+	DLDiscardBlocks bool
+	DLFlush         bool
+	ReportPolling   bool
+
+	RequestOutOfSeqReport bool
+	ReportDelivered       bool
+	UserDataExistence     bool
+	AssistInfoPolling     bool
+	Retransmission        bool
+
+	NRUSequenceNumber    uint32
+	DLDiscardNRPDCPPDUSN uint32
+	DiscardBlocks        []*DLDiscardBlock
+	DLReportNRPDCPPDUSN  uint32
+}
+
+// NewDLUserData creates a new DLUserData with NR-U Sequence Number.
+func NewDLUserData(nruSN uint32) *DLUserData {
+	return &DLUserData{
+		NRUSequenceNumber: nruSN,
+	}
+}
+
+// Marshal returns the byte sequence generated from a DLUserData.
+func (d *DLUserData) Marshal() ([]byte, error) {
+	b := make([]byte, d.MarshalLen())
+	if err := d.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (d *DLUserData) MarshalTo(b []byte) error {
+	if len(b) < d.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+	if d.DLDiscardBlocks && len(d.DiscardBlocks) > 0xff {
+		return ErrInvalidLength
+	}
+
+	b[0] = PDUTypeDLUserData << 4
+	if d.DLDiscardBlocks {
+		b[0] |= 0x04
+	}
+	if d.DLFlush {
+		b[0] |= 0x02
+	}
+	if d.ReportPolling {
+		b[0] |= 0x01
+	}
+	b[1] = 0
+	if d.RequestOutOfSeqReport {
+		b[1] |= 0x10
+	}
+	if d.ReportDelivered {
+		b[1] |= 0x08
+	}
+	if d.UserDataExistence {
+		b[1] |= 0x04
+	}
+	if d.AssistInfoPolling {
+		b[1] |= 0x02
+	}
+	if d.Retransmission {
+		b[1] |= 0x01
+	}
+	putUint24(b[2:5], d.NRUSequenceNumber)
+
+	offset := 5
+	if d.DLFlush {
+		putUint24(b[offset:offset+3], d.DLDiscardNRPDCPPDUSN)
+		offset += 3
+	}
+	if d.DLDiscardBlocks {
+		b[offset] = uint8(len(d.DiscardBlocks))
+		offset++
+		for _, blk := range d.DiscardBlocks {
+			putUint24(b[offset:offset+3], blk.Start)
+			b[offset+3] = blk.Size
+			offset += 4
+		}
+	}
+	if d.ReportDelivered {
+		putUint24(b[offset:offset+3], d.DLReportNRPDCPPDUSN)
+	}
+	return nil
+}
+
+// ParseDLUserData decodes a given byte sequence as a DLUserData.
+func ParseDLUserData(b []byte) (*DLUserData, error) {
+	d := &DLUserData{}
+	if err := d.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// UnmarshalBinary decodes a given byte sequence as a DLUserData.
+//
+// The padding after the fields, which is put to make the extension header a
+// multiple of 4 octets long, is ignored.
+func (d *DLUserData) UnmarshalBinary(b []byte) error {
+	if len(b) < 5 {
+		return ErrTooShortToParse
+	}
+	if b[0]>>4 != PDUTypeDLUserData {
+		return ErrInvalidPDUType
+	}
+
+	d.DLDiscardBlocks = b[0]&0x04 != 0
+	d.DLFlush = b[0]&0x02 != 0
+	d.ReportPolling = b[0]&0x01 != 0
+	d.RequestOutOfSeqReport = b[1]&0x10 != 0
+	d.ReportDelivered = b[1]&0x08 != 0
+	d.UserDataExistence = b[1]&0x04 != 0
+	d.AssistInfoPolling = b[1]&0x02 != 0
+	d.Retransmission = b[1]&0x01 != 0
+	d.NRUSequenceNumber = uint24(b[2:5])
+
+	offset := 5
+	if d.DLFlush {
+		if len(b) < offset+3 {
+			return ErrTooShortToParse
+		}
+		d.DLDiscardNRPDCPPDUSN = uint24(b[offset : offset+3])
+		offset += 3
+	}
+	d.DiscardBlocks = nil
+	if d.DLDiscardBlocks {
+		if len(b) < offset+1 {
+			return ErrTooShortToParse
+		}
+		n := int(b[offset])
+		offset++
+		if len(b) < offset+n*4 {
+			return ErrTooShortToParse
+		}
+		d.DiscardBlocks = make([]*DLDiscardBlock, n)
+		for i := range d.DiscardBlocks {
+			d.DiscardBlocks[i] = &DLDiscardBlock{
+				Start: uint24(b[offset : offset+3]),
+				Size:  b[offset+3],
+			}
+			offset += 4
+		}
+	}
+	if d.ReportDelivered {
+		if len(b) < offset+3 {
+			return ErrTooShortToParse
+		}
+		d.DLReportNRPDCPPDUSN = uint24(b[offset : offset+3])
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of DLUserData.
+func (d *DLUserData) MarshalLen() int {
+	l := 5
+	if d.DLFlush {
+		l += 3
+	}
+	if d.DLDiscardBlocks {
+		l += 1 + len(d.DiscardBlocks)*4
+	}
+	if d.ReportDelivered {
+		l += 3
+	}
+	return l
+}
+
+// ToExtensionHeader returns the DLUserData as a NR RAN Container extension header.
+func (d *DLUserData) ToExtensionHeader() (*ExtensionHeader, error) {
+	b, err := d.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return NewExtensionHeader(ExtHeaderTypeNRRANContainer, b), nil
+}
+
+// String returns the DLUserData values in human readable format.
+func (d *DLUserData) String() string {
+	return fmt.Sprintf("{DLDiscardBlocks: %v, DLFlush: %v, ReportPolling: %v, ReportDelivered: %v, Retransmission: %v, NRUSequenceNumber: %d}",
+		d.DLDiscardBlocks, d.DLFlush, d.ReportPolling, d.ReportDelivered, d.Retransmission, d.NRUSequenceNumber,
+	)
+}
+
+// DLDataDeliveryStatus is the DL DATA DELIVERY STATUS frame carried in the NR RAN
+// Container extension header, defined in TS 38.425 5.5.2.2.
+//
+// The optional fields are encoded only when the corresponding flag is set. The
+// numbers of ranges are taken from LostNRURanges and DeliveredNRPDCPRanges.
+type DLDataDeliveryStatus struct {
+	HighestTransmittedInd bool
+	HighestDeliveredInd   bool
+	FinalFrameInd         bool
+	LostPacketReport      bool
+
+	DeliveredRangeInd                bool
+	DataRateInd                      bool
+	HighestRetransmittedInd          bool
+	HighestDeliveredRetransmittedInd bool
+	CauseReport                      bool
+
+	DesiredBufferSize                     uint32
+	DesiredDataRate                       uint32
+	LostNRURanges                         []*SNRange
+	HighestDeliveredNRPDCPSN              uint32
+	HighestTransmittedNRPDCPSN            uint32
+	CauseValue                            uint8
+	HighestDeliveredRetransmittedNRPDCPSN uint32
+	HighestRetransmittedNRPDCPSN          uint32
+	DeliveredNRPDCPRanges                 []*SNRange
+}
+
+// NewDLDataDeliveryStatus creates a new DLDataDeliveryStatus with the desired
+// buffer size for the data radio bearer in bytes.
+func NewDLDataDeliveryStatus(bufferSize uint32) *DLDataDeliveryStatus {
+	return &DLDataDeliveryStatus{
+		DesiredBufferSize: bufferSize,
+	}
+}
+
+// Marshal returns the byte sequence generated from a DLDataDeliveryStatus.
+func (d *DLDataDeliveryStatus) Marshal() ([]byte, error) {
+	b := make([]byte, d.MarshalLen())
+	if err := d.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (d *DLDataDeliveryStatus) MarshalTo(b []byte) error {
+	if len(b) < d.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+	if (d.LostPacketReport && len(d.LostNRURanges) > 0xff) ||
+		(d.DeliveredRangeInd && len(d.DeliveredNRPDCPRanges) > 0xff) {
+		return ErrInvalidLength
+	}
+
+	b[0] = PDUTypeDLDataDeliveryStatus << 4
+	if d.HighestTransmittedInd {
+		b[0] |= 0x08
+	}
+	if d.HighestDeliveredInd {
+		b[0] |= 0x04
+	}
+	if d.FinalFrameInd {
+		b[0] |= 0x02
+	}
+	if d.LostPacketReport {
+		b[0] |= 0x01
+	}
+	b[1] = 0
+	if d.DeliveredRangeInd {
+		b[1] |= 0x10
+	}
+	if d.DataRateInd {
+		b[1] |= 0x08
+	}
+	if d.HighestRetransmittedInd {
+		b[1] |= 0x04
+	}
+	if d.HighestDeliveredRetransmittedInd {
+		b[1] |= 0x02
+	}
+	if d.CauseReport {
+		b[1] |= 0x01
+	}
+	binary.BigEndian.PutUint32(b[2:6], d.DesiredBufferSize)
+
+	offset := 6
+	if d.DataRateInd {
+		binary.BigEndian.PutUint32(b[offset:offset+4], d.DesiredDataRate)
+		offset += 4
+	}
+	if d.LostPacketReport {
+		offset = putSNRanges(b, offset, d.LostNRURanges)
+	}
+	if d.HighestDeliveredInd {
+		putUint24(b[offset:offset+3], d.HighestDeliveredNRPDCPSN)
+		offset += 3
+	}
+	if d.HighestTransmittedInd {
+		putUint24(b[offset:offset+3], d.HighestTransmittedNRPDCPSN)
+		offset += 3
+	}
+	if d.CauseReport {
+		b[offset] = d.CauseValue
+		offset++
+	}
+	if d.HighestDeliveredRetransmittedInd {
+		putUint24(b[offset:offset+3], d.HighestDeliveredRetransmittedNRPDCPSN)
+		offset += 3
+	}
+	if d.HighestRetransmittedInd {
+		putUint24(b[offset:offset+3], d.HighestRetransmittedNRPDCPSN)
+		offset += 3
+	}
+	if d.DeliveredRangeInd {
+		putSNRanges(b, offset, d.DeliveredNRPDCPRanges)
+	}
+	return nil
+}
+
+// ParseDLDataDeliveryStatus decodes a given byte sequence as a DLDataDeliveryStatus.
+func ParseDLDataDeliveryStatus(b []byte) (*DLDataDeliveryStatus, error) {
+	d := &DLDataDeliveryStatus{}
+	if err := d.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// UnmarshalBinary decodes a given byte sequence as a DLDataDeliveryStatus.
+//
+// The padding after the fields, which is put to make the extension header a
+// multiple of 4 octets long, is ignored.
+func (d *DLDataDeliveryStatus) UnmarshalBinary(b []byte) error {
+	if len(b) < 6 {
+		return ErrTooShortToParse
+	}
+	if b[0]>>4 != PDUTypeDLDataDeliveryStatus {
+		return ErrInvalidPDUType
+	}
+
+	d.HighestTransmittedInd = b[0]&0x08 != 0
+	d.HighestDeliveredInd = b[0]&0x04 != 0
+	d.FinalFrameInd = b[0]&0x02 != 0
+	d.LostPacketReport = b[0]&0x01 != 0
+	d.DeliveredRangeInd = b[1]&0x10 != 0
+	d.DataRateInd = b[1]&0x08 != 0
+	d.HighestRetransmittedInd = b[1]&0x04 != 0
+	d.HighestDeliveredRetransmittedInd = b[1]&0x02 != 0
+	d.CauseReport = b[1]&0x01 != 0
+	d.DesiredBufferSize = binary.BigEndian.Uint32(b[2:6])
+
+	var err error
+	offset := 6
+	if d.DataRateInd {
+		if len(b) < offset+4 {
+			return ErrTooShortToParse
+		}
+		d.DesiredDataRate = binary.BigEndian.Uint32(b[offset : offset+4])
+		offset += 4
+	}
+	d.LostNRURanges = nil
+	if d.LostPacketReport {
+		if d.LostNRURanges, offset, err = parseSNRanges(b, offset); err != nil {
+			return err
+		}
+	}
+	if d.HighestDeliveredInd {
+		if len(b) < offset+3 {
+			return ErrTooShortToParse
+		}
+		d.HighestDeliveredNRPDCPSN = uint24(b[offset : offset+3])
+		offset += 3
+	}
+	if d.HighestTransmittedInd {
+		if len(b) < offset+3 {
+			return ErrTooShortToParse
+		}
+		d.HighestTransmittedNRPDCPSN = uint24(b[offset : offset+3])
+		offset += 3
+	}
+	if d.CauseReport {
+		if len(b) < offset+1 {
+			return ErrTooShortToParse
+		}
+		d.CauseValue = b[offset]
+		offset++
+	}
+	if d.HighestDeliveredRetransmittedInd {
+		if len(b) < offset+3 {
+			return ErrTooShortToParse
+		}
+		d.HighestDeliveredRetransmittedNRPDCPSN = uint24(b[offset : offset+3])
+		offset += 3
+	}
+	if d.HighestRetransmittedInd {
+		if len(b) < offset+3 {
+			return ErrTooShortToParse
+		}
+		d.HighestRetransmittedNRPDCPSN = uint24(b[offset : offset+3])
+		offset += 3
+	}
+	d.DeliveredNRPDCPRanges = nil
+	if d.DeliveredRangeInd {
+		if d.DeliveredNRPDCPRanges, _, err = parseSNRanges(b, offset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of DLDataDeliveryStatus.
+func (d *DLDataDeliveryStatus) MarshalLen() int {
+	l := 6
+	if d.DataRateInd {
+		l += 4
+	}
+	if d.LostPacketReport {
+		l += 1 + len(d.LostNRURanges)*6
+	}
+	if d.HighestDeliveredInd {
+		l += 3
+	}
+	if d.HighestTransmittedInd {
+		l += 3
+	}
+	if d.CauseReport {
+		l++
+	}
+	if d.HighestDeliveredRetransmittedInd {
+		l += 3
+	}
+	if d.HighestRetransmittedInd {
+		l += 3
+	}
+	if d.DeliveredRangeInd {
+		l += 1 + len(d.DeliveredNRPDCPRanges)*6
+	}
+	return l
+}
+
+// ToExtensionHeader returns the DLDataDeliveryStatus as a NR RAN Container extension header.
+func (d *DLDataDeliveryStatus) ToExtensionHeader() (*ExtensionHeader, error) {
+	b, err := d.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return NewExtensionHeader(ExtHeaderTypeNRRANContainer, b), nil
+}
+
+// String returns the DLDataDeliveryStatus values in human readable format.
+func (d *DLDataDeliveryStatus) String() string {
+	return fmt.Sprintf("{FinalFrameInd: %v, DesiredBufferSize: %d, DesiredDataRate: %d, LostNRURanges: %d, HighestDeliveredNRPDCPSN: %d, HighestTransmittedNRPDCPSN: %d}",
+		d.FinalFrameInd, d.DesiredBufferSize, d.DesiredDataRate, len(d.LostNRURanges), d.HighestDeliveredNRPDCPSN, d.HighestTransmittedNRPDCPSN,
+	)
+}
+
+// NRRANContainerType returns the PDU Type of the NR RAN Container extension header
+// given, e.g., PDUTypeDLUserData or PDUTypeDLDataDeliveryStatus.
+func NRRANContainerType(e *ExtensionHeader) (uint8, error) {
+	if e.Type != ExtHeaderTypeNRRANContainer {
+		return 0, ErrInvalidExtensionHeaderType
+	}
+	if len(e.Content) < 1 {
+		return 0, ErrTooShortToParse
+	}
+	return e.Content[0] >> 4, nil
+}
+
+// DLUserDataFromExtensionHeader decodes the NR RAN Container extension header
+// given as a DLUserData.
+func DLUserDataFromExtensionHeader(e *ExtensionHeader) (*DLUserData, error) {
+	if e.Type != ExtHeaderTypeNRRANContainer {
+		return nil, ErrInvalidExtensionHeaderType
+	}
+	return ParseDLUserData(e.Content)
+}
+
+// DLDataDeliveryStatusFromExtensionHeader decodes the NR RAN Container extension
+// header given as a DLDataDeliveryStatus.
+func DLDataDeliveryStatusFromExtensionHeader(e *ExtensionHeader) (*DLDataDeliveryStatus, error) {
+	if e.Type != ExtHeaderTypeNRRANContainer {
+		return nil, ErrInvalidExtensionHeaderType
+	}
+	return ParseDLDataDeliveryStatus(e.Content)
+}
+
+// putSNRanges puts the number of ranges and the ranges at offset in b, and returns
+// the offset after them.
+func putSNRanges(b []byte, offset int, ranges []*SNRange) int {
+	b[offset] = uint8(len(ranges))
+	offset++
+	for _, r := range ranges {
+		putUint24(b[offset:offset+3], r.Start)
+		putUint24(b[offset+3:offset+6], r.End)
+		offset += 6
+	}
+	return offset
+}
+
+// parseSNRanges decodes the number of ranges and the ranges at offset in b, and
+// returns them with the offset after them.
+func parseSNRanges(b []byte, offset int) ([]*SNRange, int, error) {
+	if len(b) < offset+1 {
+		return nil, offset, ErrTooShortToParse
+	}
+	n := int(b[offset])
+	offset++
+	if len(b) < offset+n*6 {
+		return nil, offset, ErrTooShortToParse
+	}
+
+	ranges := make([]*SNRange, n)
+	for i := range ranges {
+		ranges[i] = &SNRange{
+			Start: uint24(b[offset : offset+3]),
+			End:   uint24(b[offset+3 : offset+6]),
+		}
+		offset += 6
+	}
+	return ranges, offset, nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
+	"github.com/wmnsk/go-gtp/gtpv1/testutils"
+)
+
+func TestDLUserData(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "NR-U SN",
+			Structured:  message.NewDLUserData(0x010203),
+			Serialized:  []byte{0x00, 0x00, 0x01, 0x02, 0x03},
+		}, {
+			Description: "WithOptionalFields",
+			Structured: &message.DLUserData{
+				DLDiscardBlocks:      true,
+				DLFlush:              true,
+				ReportPolling:        true,
+				ReportDelivered:      true,
+				Retransmission:       true,
+				NRUSequenceNumber:    0x000010,
+				DLDiscardNRPDCPPDUSN: 0x000020,
+				DiscardBlocks: []*message.DLDiscardBlock{
+					{Start: 0x000030, Size: 4},
+					{Start: 0x000040, Size: 8},
+				},
+				DLReportNRPDCPPDUSN: 0x000050,
+			},
+			Serialized: []byte{
+				0x07, 0x09, 0x00, 0x00, 0x10,
+				0x00, 0x00, 0x20,
+				0x02, 0x00, 0x00, 0x30, 0x04, 0x00, 0x00, 0x40, 0x08,
+				0x00, 0x00, 0x50,
+			},
+		},
+	}
+
+	runPDUSessionInformation(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseDLUserData(b)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	})
+}
+
+func TestDLDataDeliveryStatus(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "DesiredBufferSize",
+			Structured:  message.NewDLDataDeliveryStatus(0x00100000),
+			Serialized:  []byte{0x10, 0x00, 0x00, 0x10, 0x00, 0x00},
+		}, {
+			Description: "WithOptionalFields",
+			Structured: &message.DLDataDeliveryStatus{
+				HighestTransmittedInd:      true,
+				HighestDeliveredInd:        true,
+				FinalFrameInd:              true,
+				LostPacketReport:           true,
+				DataRateInd:                true,
+				CauseReport:                true,
+				DesiredBufferSize:          0x00002000,
+				DesiredDataRate:            0x00004000,
+				LostNRURanges:              []*message.SNRange{{Start: 0x000005, End: 0x000007}},
+				HighestDeliveredNRPDCPSN:   0x000100,
+				HighestTransmittedNRPDCPSN: 0x000110,
+				CauseValue:                 1,
+			},
+			Serialized: []byte{
+				0x1f, 0x09, 0x00, 0x00, 0x20, 0x00,
+				0x00, 0x00, 0x40, 0x00,
+				0x01, 0x00, 0x00, 0x05, 0x00, 0x00, 0x07,
+				0x00, 0x01, 0x00,
+				0x00, 0x01, 0x10,
+				0x01,
+			},
+		}, {
+			Description: "Retransmitted/DeliveredRanges",
+			Structured: &message.DLDataDeliveryStatus{
+				DeliveredRangeInd:                     true,
+				HighestRetransmittedInd:               true,
+				HighestDeliveredRetransmittedInd:      true,
+				DesiredBufferSize:                     1,
+				HighestDeliveredRetransmittedNRPDCPSN: 0x000200,
+				HighestRetransmittedNRPDCPSN:          0x000210,
+				DeliveredNRPDCPRanges: []*message.SNRange{
+					{Start: 0x000300, End: 0x000301}, {Start: 0x000310, End: 0x000311},
+				},
+			},
+			Serialized: []byte{
+				0x10, 0x16, 0x00, 0x00, 0x00, 0x01,
+				0x00, 0x02, 0x00,
+				0x00, 0x02, 0x10,
+				0x02, 0x00, 0x03, 0x00, 0x00, 0x03, 0x01, 0x00, 0x03, 0x10, 0x00, 0x03, 0x11,
+			},
+		},
+	}
+
+	runPDUSessionInformation(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseDLDataDeliveryStatus(b)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	})
+}
+
+func TestNRRANContainer(t *testing.T) {
+	dds := &message.DLDataDeliveryStatus{
+		HighestDeliveredInd:      true,
+		DesiredBufferSize:        0x00010000,
+		HighestDeliveredNRPDCPSN: 0x000123,
+	}
+	e, err := dds.ToExtensionHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpdu := message.NewTPDU(0x11223344, []byte{0xde, 0xad, 0xbe, 0xef})
+	tpdu.Header.WithExtensionHeaders(e)
+
+	b, err := tpdu.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := message.ParseTPDU(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := parsed.ExtensionHeaderByType(message.ExtHeaderTypeNRRANContainer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ, err := message.NRRANContainerType(c); err != nil || typ != message.PDUTypeDLDataDeliveryStatus {
+		t.Fatalf("wrong PDU type: %d, %v", typ, err)
+	}
+	// the content is padded, which should be ignored.
+	got, err := message.DLDataDeliveryStatusFromExtensionHeader(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(dds, got); diff != "" {
+		t.Error(diff)
+	}
+
+	if _, err := message.DLUserDataFromExtensionHeader(c); err != message.ErrInvalidPDUType {
+		t.Errorf("want ErrInvalidPDUType, got %v", err)
+	}
+}
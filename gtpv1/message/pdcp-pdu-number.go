@@ -0,0 +1,34 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import "encoding/binary"
+
+// NewPDCPPDUNumberExtensionHeader creates a new PDCP PDU Number extension header,
+// which is used to forward the T-PDUs without loss on handover, defined in TS
+// 29.281 5.2.2.2.
+func NewPDCPPDUNumberExtensionHeader(num uint16) *ExtensionHeader {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, num)
+	return NewExtensionHeader(ExtHeaderTypePDCPPDUNumber, b)
+}
+
+// PDCPPDUNumber returns the PDCP PDU number in the PDCP PDU Number extension header.
+func (e *ExtensionHeader) PDCPPDUNumber() (uint16, error) {
+	if e.Type != ExtHeaderTypePDCPPDUNumber {
+		return 0, ErrInvalidExtensionHeaderType
+	}
+	if len(e.Content) < 2 {
+		return 0, ErrTooShortToParse
+	}
+	return binary.BigEndian.Uint16(e.Content[0:2]), nil
+}
+
+// MustPDCPPDUNumber returns the PDCP PDU number in the PDCP PDU Number extension header.
+// This should only be used if it is assured to have the value.
+func (e *ExtensionHeader) MustPDCPPDUNumber() uint16 {
+	v, _ := e.PDCPPDUNumber()
+	return v
+}
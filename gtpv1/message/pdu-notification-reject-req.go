@@ -0,0 +1,227 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+)
+
+// PDUNotificationRejectRequest is a PDUNotificationRejectRequest Header and its IEs above.
+type PDUNotificationRejectRequest struct {
+	*Header
+	Cause            *ie.IE
+	TEIDCPlane       *ie.IE
+	EndUserAddress   *ie.IE
+	APN              *ie.IE
+	PCO              *ie.IE
+	PrivateExtension *ie.IE
+	AdditionalIEs    []*ie.IE
+}
+
+// NewPDUNotificationRejectRequest creates a new GTPv1 PDUNotificationRejectRequest.
+func NewPDUNotificationRejectRequest(teid uint32, seq uint16, IEs ...*ie.IE) *PDUNotificationRejectRequest {
+	p := &PDUNotificationRejectRequest{
+		Header: NewHeader(0x32, MsgTypePDUNotificationRejectRequest, teid, seq, nil),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			p.Cause = i
+		case ie.TEIDCPlane:
+			p.TEIDCPlane = i
+		case ie.EndUserAddress:
+			p.EndUserAddress = i
+		case ie.AccessPointName:
+			p.APN = i
+		case ie.ProtocolConfigurationOptions:
+			p.PCO = i
+		case ie.PrivateExtension:
+			p.PrivateExtension = i
+		default:
+			p.AdditionalIEs = append(p.AdditionalIEs, i)
+		}
+	}
+
+	p.SetLength()
+	return p
+}
+
+// Marshal returns the byte sequence generated from a PDUNotificationRejectRequest.
+func (p *PDUNotificationRejectRequest) Marshal() ([]byte, error) {
+	b := make([]byte, p.MarshalLen())
+	if err := p.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// AppendTo appends the byte sequence generated from PDUNotificationRejectRequest to b and returns the extended buffer.
+func (p *PDUNotificationRejectRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, p)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (p *PDUNotificationRejectRequest) MarshalTo(b []byte) error {
+	if len(b) < p.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+	var err error
+	p.Header.Payload, err = p.Header.payloadIn(b, p.MarshalLen())
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if ie := p.Cause; ie != nil {
+		if err := ie.MarshalTo(p.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := p.TEIDCPlane; ie != nil {
+		if err := ie.MarshalTo(p.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := p.EndUserAddress; ie != nil {
+		if err := ie.MarshalTo(p.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := p.APN; ie != nil {
+		if err := ie.MarshalTo(p.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := p.PCO; ie != nil {
+		if err := ie.MarshalTo(p.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := p.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(p.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range p.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(p.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	p.Header.SetLength()
+	return p.Header.MarshalTo(b)
+}
+
+// ParsePDUNotificationRejectRequest decodes a given byte sequence as a PDUNotificationRejectRequest.
+func ParsePDUNotificationRejectRequest(b []byte) (*PDUNotificationRejectRequest, error) {
+	p := &PDUNotificationRejectRequest{}
+	if err := p.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// UnmarshalBinary decodes a given byte sequence as a PDUNotificationRejectRequest.
+func (p *PDUNotificationRejectRequest) UnmarshalBinary(b []byte) error {
+	var err error
+	p.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(p.Header.Payload) < 2 {
+		return nil
+	}
+
+	IEs, err := ie.ParseMultiIEs(p.Header.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			p.Cause = i
+		case ie.TEIDCPlane:
+			p.TEIDCPlane = i
+		case ie.EndUserAddress:
+			p.EndUserAddress = i
+		case ie.AccessPointName:
+			p.APN = i
+		case ie.ProtocolConfigurationOptions:
+			p.PCO = i
+		case ie.PrivateExtension:
+			p.PrivateExtension = i
+		default:
+			p.AdditionalIEs = append(p.AdditionalIEs, i)
+		}
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (p *PDUNotificationRejectRequest) MarshalLen() int {
+	l := p.Header.MarshalLen() - len(p.Header.Payload)
+
+	if ie := p.Cause; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := p.TEIDCPlane; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := p.EndUserAddress; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := p.APN; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := p.PCO; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := p.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range p.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (p *PDUNotificationRejectRequest) SetLength() {
+	p.Length = uint16(p.MarshalLen() - 8)
+}
+
+// MessageTypeName returns the name of protocol.
+func (p *PDUNotificationRejectRequest) MessageTypeName() string {
+	return "PDU Notification Reject Request"
+}
+
+// TEID returns the TEID in human-readable string.
+func (p *PDUNotificationRejectRequest) TEID() uint32 {
+	return p.Header.TEID
+}
@@ -0,0 +1,171 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+)
+
+// PDUNotificationRejectResponse is a PDUNotificationRejectResponse Header and its IEs above.
+type PDUNotificationRejectResponse struct {
+	*Header
+	Cause            *ie.IE
+	PrivateExtension *ie.IE
+	AdditionalIEs    []*ie.IE
+}
+
+// NewPDUNotificationRejectResponse creates a new GTPv1 PDUNotificationRejectResponse.
+func NewPDUNotificationRejectResponse(teid uint32, seq uint16, IEs ...*ie.IE) *PDUNotificationRejectResponse {
+	p := &PDUNotificationRejectResponse{
+		Header: NewHeader(0x32, MsgTypePDUNotificationRejectResponse, teid, seq, nil),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			p.Cause = i
+		case ie.PrivateExtension:
+			p.PrivateExtension = i
+		default:
+			p.AdditionalIEs = append(p.AdditionalIEs, i)
+		}
+	}
+
+	p.SetLength()
+	return p
+}
+
+// Marshal returns the byte sequence generated from a PDUNotificationRejectResponse.
+func (p *PDUNotificationRejectResponse) Marshal() ([]byte, error) {
+	b := make([]byte, p.MarshalLen())
+	if err := p.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// AppendTo appends the byte sequence generated from PDUNotificationRejectResponse to b and returns the extended buffer.
+func (p *PDUNotificationRejectResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, p)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (p *PDUNotificationRejectResponse) MarshalTo(b []byte) error {
+	if len(b) < p.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+	var err error
+	p.Header.Payload, err = p.Header.payloadIn(b, p.MarshalLen())
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if ie := p.Cause; ie != nil {
+		if err := ie.MarshalTo(p.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := p.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(p.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range p.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(p.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	p.Header.SetLength()
+	return p.Header.MarshalTo(b)
+}
+
+// ParsePDUNotificationRejectResponse decodes a given byte sequence as a PDUNotificationRejectResponse.
+func ParsePDUNotificationRejectResponse(b []byte) (*PDUNotificationRejectResponse, error) {
+	p := &PDUNotificationRejectResponse{}
+	if err := p.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// UnmarshalBinary decodes a given byte sequence as a PDUNotificationRejectResponse.
+func (p *PDUNotificationRejectResponse) UnmarshalBinary(b []byte) error {
+	var err error
+	p.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(p.Header.Payload) < 2 {
+		return nil
+	}
+
+	IEs, err := ie.ParseMultiIEs(p.Header.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			p.Cause = i
+		case ie.PrivateExtension:
+			p.PrivateExtension = i
+		default:
+			p.AdditionalIEs = append(p.AdditionalIEs, i)
+		}
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (p *PDUNotificationRejectResponse) MarshalLen() int {
+	l := p.Header.MarshalLen() - len(p.Header.Payload)
+
+	if ie := p.Cause; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := p.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range p.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (p *PDUNotificationRejectResponse) SetLength() {
+	p.Length = uint16(p.MarshalLen() - 8)
+}
+
+// MessageTypeName returns the name of protocol.
+func (p *PDUNotificationRejectResponse) MessageTypeName() string {
+	return "PDU Notification Reject Response"
+}
+
+// TEID returns the TEID in human-readable string.
+func (p *PDUNotificationRejectResponse) TEID() uint32 {
+	return p.Header.TEID
+}
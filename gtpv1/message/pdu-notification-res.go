@@ -0,0 +1,171 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+)
+
+// PDUNotificationResponse is a PDUNotificationResponse Header and its IEs above.
+type PDUNotificationResponse struct {
+	*Header
+	Cause            *ie.IE
+	PrivateExtension *ie.IE
+	AdditionalIEs    []*ie.IE
+}
+
+// NewPDUNotificationResponse creates a new GTPv1 PDUNotificationResponse.
+func NewPDUNotificationResponse(teid uint32, seq uint16, IEs ...*ie.IE) *PDUNotificationResponse {
+	p := &PDUNotificationResponse{
+		Header: NewHeader(0x32, MsgTypePDUNotificationResponse, teid, seq, nil),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			p.Cause = i
+		case ie.PrivateExtension:
+			p.PrivateExtension = i
+		default:
+			p.AdditionalIEs = append(p.AdditionalIEs, i)
+		}
+	}
+
+	p.SetLength()
+	return p
+}
+
+// Marshal returns the byte sequence generated from a PDUNotificationResponse.
+func (p *PDUNotificationResponse) Marshal() ([]byte, error) {
+	b := make([]byte, p.MarshalLen())
+	if err := p.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// AppendTo appends the byte sequence generated from PDUNotificationResponse to b and returns the extended buffer.
+func (p *PDUNotificationResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, p)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (p *PDUNotificationResponse) MarshalTo(b []byte) error {
+	if len(b) < p.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+	var err error
+	p.Header.Payload, err = p.Header.payloadIn(b, p.MarshalLen())
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if ie := p.Cause; ie != nil {
+		if err := ie.MarshalTo(p.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := p.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(p.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range p.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(p.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	p.Header.SetLength()
+	return p.Header.MarshalTo(b)
+}
+
+// ParsePDUNotificationResponse decodes a given byte sequence as a PDUNotificationResponse.
+func ParsePDUNotificationResponse(b []byte) (*PDUNotificationResponse, error) {
+	p := &PDUNotificationResponse{}
+	if err := p.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// UnmarshalBinary decodes a given byte sequence as a PDUNotificationResponse.
+func (p *PDUNotificationResponse) UnmarshalBinary(b []byte) error {
+	var err error
+	p.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(p.Header.Payload) < 2 {
+		return nil
+	}
+
+	IEs, err := ie.ParseMultiIEs(p.Header.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			p.Cause = i
+		case ie.PrivateExtension:
+			p.PrivateExtension = i
+		default:
+			p.AdditionalIEs = append(p.AdditionalIEs, i)
+		}
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (p *PDUNotificationResponse) MarshalLen() int {
+	l := p.Header.MarshalLen() - len(p.Header.Payload)
+
+	if ie := p.Cause; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := p.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range p.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (p *PDUNotificationResponse) SetLength() {
+	p.Length = uint16(p.MarshalLen() - 8)
+}
+
+// MessageTypeName returns the name of protocol.
+func (p *PDUNotificationResponse) MessageTypeName() string {
+	return "PDU Notification Response"
+}
+
+// TEID returns the TEID in human-readable string.
+func (p *PDUNotificationResponse) TEID() uint32 {
+	return p.Header.TEID
+}
@@ -0,0 +1,377 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// PDU Type definitions for PDU Session Container, defined in TS 38.415.
+const (
+	PDUTypeDLPDUSessionInformation uint8 = 0
+	PDUTypeULPDUSessionInformation uint8 = 1
+)
+
+// DLPDUSessionInformation is the DL PDU SESSION INFORMATION frame carried in the
+// PDU Session Container extension header, defined in TS 38.415 5.5.2.1.
+//
+// The optional fields are encoded only when the corresponding flag(PPP, QMP, SNP)
+// is set.
+type DLPDUSessionInformation struct {
+	QMP bool
+	SNP bool
+	PPP bool
+	RQI bool
+	QFI uint8
+
+	PPI                 uint8
+	DLSendingTimeStamp  uint64
+	DLQFISequenceNumber uint32
+}
+
+// NewDLPDUSessionInformation creates a new DLPDUSessionInformation with QFI and RQI.
+func NewDLPDUSessionInformation(qfi uint8, rqi bool) *DLPDUSessionInformation {
+	return &DLPDUSessionInformation{
+		QFI: qfi,
+		RQI: rqi,
+	}
+}
+
+// Marshal returns the byte sequence generated from a DLPDUSessionInformation.
+func (d *DLPDUSessionInformation) Marshal() ([]byte, error) {
+	b := make([]byte, d.MarshalLen())
+	if err := d.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (d *DLPDUSessionInformation) MarshalTo(b []byte) error {
+	if len(b) < d.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+
+	b[0] = PDUTypeDLPDUSessionInformation << 4
+	if d.QMP {
+		b[0] |= 0x08
+	}
+	if d.SNP {
+		b[0] |= 0x04
+	}
+	b[1] = d.QFI & 0x3f
+	if d.PPP {
+		b[1] |= 0x80
+	}
+	if d.RQI {
+		b[1] |= 0x40
+	}
+
+	offset := 2
+	if d.PPP {
+		b[offset] = (d.PPI & 0x07) << 5
+		offset++
+	}
+	if d.QMP {
+		binary.BigEndian.PutUint64(b[offset:offset+8], d.DLSendingTimeStamp)
+		offset += 8
+	}
+	if d.SNP {
+		putUint24(b[offset:offset+3], d.DLQFISequenceNumber)
+	}
+	return nil
+}
+
+// ParseDLPDUSessionInformation decodes a given byte sequence as a DLPDUSessionInformation.
+func ParseDLPDUSessionInformation(b []byte) (*DLPDUSessionInformation, error) {
+	d := &DLPDUSessionInformation{}
+	if err := d.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// UnmarshalBinary decodes a given byte sequence as a DLPDUSessionInformation.
+func (d *DLPDUSessionInformation) UnmarshalBinary(b []byte) error {
+	if len(b) < 2 {
+		return ErrTooShortToParse
+	}
+	if b[0]>>4 != PDUTypeDLPDUSessionInformation {
+		return ErrInvalidPDUType
+	}
+
+	d.QMP = b[0]&0x08 != 0
+	d.SNP = b[0]&0x04 != 0
+	d.PPP = b[1]&0x80 != 0
+	d.RQI = b[1]&0x40 != 0
+	d.QFI = b[1] & 0x3f
+	if len(b) < d.MarshalLen() {
+		return ErrTooShortToParse
+	}
+
+	offset := 2
+	if d.PPP {
+		d.PPI = b[offset] >> 5
+		offset++
+	}
+	if d.QMP {
+		d.DLSendingTimeStamp = binary.BigEndian.Uint64(b[offset : offset+8])
+		offset += 8
+	}
+	if d.SNP {
+		d.DLQFISequenceNumber = uint24(b[offset : offset+3])
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of DLPDUSessionInformation.
+func (d *DLPDUSessionInformation) MarshalLen() int {
+	l := 2
+	if d.PPP {
+		l++
+	}
+	if d.QMP {
+		l += 8
+	}
+	if d.SNP {
+		l += 3
+	}
+	return l
+}
+
+// ToExtensionHeader returns the DLPDUSessionInformation as a PDU Session Container extension header.
+func (d *DLPDUSessionInformation) ToExtensionHeader() (*ExtensionHeader, error) {
+	b, err := d.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return NewExtensionHeader(ExtHeaderTypePDUSessionContainer, b), nil
+}
+
+// String returns the DLPDUSessionInformation values in human readable format.
+func (d *DLPDUSessionInformation) String() string {
+	return fmt.Sprintf("{QMP: %v, SNP: %v, PPP: %v, RQI: %v, QFI: %d, PPI: %d, DLSendingTimeStamp: %d, DLQFISequenceNumber: %d}",
+		d.QMP, d.SNP, d.PPP, d.RQI, d.QFI, d.PPI, d.DLSendingTimeStamp, d.DLQFISequenceNumber,
+	)
+}
+
+// ULPDUSessionInformation is the UL PDU SESSION INFORMATION frame carried in the
+// PDU Session Container extension header, defined in TS 38.415 5.5.2.2.
+//
+// The optional fields are encoded only when the corresponding flag(QMP,
+// DLDelayInd, ULDelayInd, SNP, N3N9DelayInd) is set.
+type ULPDUSessionInformation struct {
+	QMP          bool
+	DLDelayInd   bool
+	ULDelayInd   bool
+	SNP          bool
+	N3N9DelayInd bool
+	QFI          uint8
+
+	DLSendingTimeStampRepeated uint64
+	DLReceivedTimeStamp        uint64
+	ULSendingTimeStamp         uint64
+	DLDelayResult              uint32
+	ULDelayResult              uint32
+	ULQFISequenceNumber        uint32
+	N3N9DelayResult            uint32
+}
+
+// NewULPDUSessionInformation creates a new ULPDUSessionInformation with QFI.
+func NewULPDUSessionInformation(qfi uint8) *ULPDUSessionInformation {
+	return &ULPDUSessionInformation{
+		QFI: qfi,
+	}
+}
+
+// Marshal returns the byte sequence generated from a ULPDUSessionInformation.
+func (u *ULPDUSessionInformation) Marshal() ([]byte, error) {
+	b := make([]byte, u.MarshalLen())
+	if err := u.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (u *ULPDUSessionInformation) MarshalTo(b []byte) error {
+	if len(b) < u.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+
+	b[0] = PDUTypeULPDUSessionInformation << 4
+	if u.QMP {
+		b[0] |= 0x08
+	}
+	if u.DLDelayInd {
+		b[0] |= 0x04
+	}
+	if u.ULDelayInd {
+		b[0] |= 0x02
+	}
+	if u.SNP {
+		b[0] |= 0x01
+	}
+	b[1] = u.QFI & 0x3f
+	if u.N3N9DelayInd {
+		b[1] |= 0x80
+	}
+
+	offset := 2
+	if u.QMP {
+		binary.BigEndian.PutUint64(b[offset:offset+8], u.DLSendingTimeStampRepeated)
+		binary.BigEndian.PutUint64(b[offset+8:offset+16], u.DLReceivedTimeStamp)
+		binary.BigEndian.PutUint64(b[offset+16:offset+24], u.ULSendingTimeStamp)
+		offset += 24
+	}
+	if u.DLDelayInd {
+		binary.BigEndian.PutUint32(b[offset:offset+4], u.DLDelayResult)
+		offset += 4
+	}
+	if u.ULDelayInd {
+		binary.BigEndian.PutUint32(b[offset:offset+4], u.ULDelayResult)
+		offset += 4
+	}
+	if u.SNP {
+		putUint24(b[offset:offset+3], u.ULQFISequenceNumber)
+		offset += 3
+	}
+	if u.N3N9DelayInd {
+		binary.BigEndian.PutUint32(b[offset:offset+4], u.N3N9DelayResult)
+	}
+	return nil
+}
+
+// ParseULPDUSessionInformation decodes a given byte sequence as a ULPDUSessionInformation.
+func ParseULPDUSessionInformation(b []byte) (*ULPDUSessionInformation, error) {
+	u := &ULPDUSessionInformation{}
+	if err := u.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// UnmarshalBinary decodes a given byte sequence as a ULPDUSessionInformation.
+func (u *ULPDUSessionInformation) UnmarshalBinary(b []byte) error {
+	if len(b) < 2 {
+		return ErrTooShortToParse
+	}
+	if b[0]>>4 != PDUTypeULPDUSessionInformation {
+		return ErrInvalidPDUType
+	}
+
+	u.QMP = b[0]&0x08 != 0
+	u.DLDelayInd = b[0]&0x04 != 0
+	u.ULDelayInd = b[0]&0x02 != 0
+	u.SNP = b[0]&0x01 != 0
+	u.N3N9DelayInd = b[1]&0x80 != 0
+	u.QFI = b[1] & 0x3f
+	if len(b) < u.MarshalLen() {
+		return ErrTooShortToParse
+	}
+
+	offset := 2
+	if u.QMP {
+		u.DLSendingTimeStampRepeated = binary.BigEndian.Uint64(b[offset : offset+8])
+		u.DLReceivedTimeStamp = binary.BigEndian.Uint64(b[offset+8 : offset+16])
+		u.ULSendingTimeStamp = binary.BigEndian.Uint64(b[offset+16 : offset+24])
+		offset += 24
+	}
+	if u.DLDelayInd {
+		u.DLDelayResult = binary.BigEndian.Uint32(b[offset : offset+4])
+		offset += 4
+	}
+	if u.ULDelayInd {
+		u.ULDelayResult = binary.BigEndian.Uint32(b[offset : offset+4])
+		offset += 4
+	}
+	if u.SNP {
+		u.ULQFISequenceNumber = uint24(b[offset : offset+3])
+		offset += 3
+	}
+	if u.N3N9DelayInd {
+		u.N3N9DelayResult = binary.BigEndian.Uint32(b[offset : offset+4])
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of ULPDUSessionInformation.
+func (u *ULPDUSessionInformation) MarshalLen() int {
+	l := 2
+	if u.QMP {
+		l += 24
+	}
+	if u.DLDelayInd {
+		l += 4
+	}
+	if u.ULDelayInd {
+		l += 4
+	}
+	if u.SNP {
+		l += 3
+	}
+	if u.N3N9DelayInd {
+		l += 4
+	}
+	return l
+}
+
+// ToExtensionHeader returns the ULPDUSessionInformation as a PDU Session Container extension header.
+func (u *ULPDUSessionInformation) ToExtensionHeader() (*ExtensionHeader, error) {
+	b, err := u.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return NewExtensionHeader(ExtHeaderTypePDUSessionContainer, b), nil
+}
+
+// String returns the ULPDUSessionInformation values in human readable format.
+func (u *ULPDUSessionInformation) String() string {
+	return fmt.Sprintf("{QMP: %v, DLDelayInd: %v, ULDelayInd: %v, SNP: %v, N3N9DelayInd: %v, QFI: %d}",
+		u.QMP, u.DLDelayInd, u.ULDelayInd, u.SNP, u.N3N9DelayInd, u.QFI,
+	)
+}
+
+// PDUSessionContainerType returns the PDU Type of the PDU Session Container
+// extension header given, which is either PDUTypeDLPDUSessionInformation or
+// PDUTypeULPDUSessionInformation.
+func PDUSessionContainerType(e *ExtensionHeader) (uint8, error) {
+	if e.Type != ExtHeaderTypePDUSessionContainer {
+		return 0, ErrInvalidExtensionHeaderType
+	}
+	if len(e.Content) < 1 {
+		return 0, ErrTooShortToParse
+	}
+	return e.Content[0] >> 4, nil
+}
+
+// DLPDUSessionInformationFromExtensionHeader decodes the PDU Session Container
+// extension header given as a DLPDUSessionInformation.
+func DLPDUSessionInformationFromExtensionHeader(e *ExtensionHeader) (*DLPDUSessionInformation, error) {
+	if e.Type != ExtHeaderTypePDUSessionContainer {
+		return nil, ErrInvalidExtensionHeaderType
+	}
+	return ParseDLPDUSessionInformation(e.Content)
+}
+
+// ULPDUSessionInformationFromExtensionHeader decodes the PDU Session Container
+// extension header given as a ULPDUSessionInformation.
+func ULPDUSessionInformationFromExtensionHeader(e *ExtensionHeader) (*ULPDUSessionInformation, error) {
+	if e.Type != ExtHeaderTypePDUSessionContainer {
+		return nil, ErrInvalidExtensionHeaderType
+	}
+	return ParseULPDUSessionInformation(e.Content)
+}
+
+func putUint24(b []byte, v uint32) {
+	b[0] = uint8(v >> 16)
+	b[1] = uint8(v >> 8)
+	b[2] = uint8(v)
+}
+
+func uint24(b []byte) uint32 {
+	return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+}
@@ -0,0 +1,136 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
+	"github.com/wmnsk/go-gtp/gtpv1/testutils"
+)
+
+func runPDUSessionInformation(t *testing.T, cases []testutils.TestCase, decode testutils.ParseFunc) {
+	t.Helper()
+
+	for _, c := range cases {
+		t.Run(c.Description, func(t *testing.T) {
+			v, err := decode(c.Serialized)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(c.Structured, v); diff != "" {
+				t.Error(diff)
+			}
+
+			b, err := c.Structured.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(c.Serialized, b); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestDLPDUSessionInformation(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "QFI/RQI",
+			Structured:  message.NewDLPDUSessionInformation(9, true),
+			Serialized:  []byte{0x00, 0x49},
+		}, {
+			Description: "WithOptionalFields",
+			Structured: &message.DLPDUSessionInformation{
+				QMP:                 true,
+				SNP:                 true,
+				PPP:                 true,
+				QFI:                 5,
+				PPI:                 3,
+				DLSendingTimeStamp:  0x0102030405060708,
+				DLQFISequenceNumber: 0x112233,
+			},
+			Serialized: []byte{
+				0x0c, 0x85, 0x60,
+				0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+				0x11, 0x22, 0x33,
+			},
+		},
+	}
+
+	runPDUSessionInformation(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseDLPDUSessionInformation(b)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	})
+}
+
+func TestULPDUSessionInformation(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "QFI",
+			Structured:  message.NewULPDUSessionInformation(9),
+			Serialized:  []byte{0x10, 0x09},
+		}, {
+			Description: "WithOptionalFields",
+			Structured: &message.ULPDUSessionInformation{
+				ULDelayInd:          true,
+				SNP:                 true,
+				QFI:                 1,
+				ULDelayResult:       0xdeadbeef,
+				ULQFISequenceNumber: 0x000102,
+			},
+			Serialized: []byte{
+				0x13, 0x01,
+				0xde, 0xad, 0xbe, 0xef,
+				0x00, 0x01, 0x02,
+			},
+		},
+	}
+
+	runPDUSessionInformation(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseULPDUSessionInformation(b)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	})
+}
+
+func TestPDUSessionContainer(t *testing.T) {
+	e, err := message.NewDLPDUSessionInformation(9, false).ToExtensionHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpdu := message.NewTPDU(0x11223344, []byte{0xde, 0xad, 0xbe, 0xef})
+	tpdu.Header.WithExtensionHeaders(e)
+
+	b, err := tpdu.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := message.ParseTPDU(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := parsed.ExtensionHeaderByType(message.ExtHeaderTypePDUSessionContainer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ, err := message.PDUSessionContainerType(c); err != nil || typ != message.PDUTypeDLPDUSessionInformation {
+		t.Fatalf("wrong PDU type: %d, %v", typ, err)
+	}
+	dl, err := message.DLPDUSessionInformationFromExtensionHeader(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dl.QFI, uint8(9); got != want {
+		t.Errorf("wrong QFI. want: %d, got: %d", want, got)
+	}
+}
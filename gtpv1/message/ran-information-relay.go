@@ -0,0 +1,199 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+)
+
+// RANInformationRelay is a RANInformationRelay Header and its IEs above.
+type RANInformationRelay struct {
+	*Header
+	RANTransparentContainer        *ie.IE
+	RIMRoutingAddress              *ie.IE
+	RIMRoutingAddressDiscriminator *ie.IE
+	PrivateExtension               *ie.IE
+	AdditionalIEs                  []*ie.IE
+}
+
+// NewRANInformationRelay creates a new GTPv1 RANInformationRelay.
+func NewRANInformationRelay(teid uint32, seq uint16, IEs ...*ie.IE) *RANInformationRelay {
+	r := &RANInformationRelay{
+		Header: NewHeader(0x32, MsgTypeRANInformationRelay, teid, seq, nil),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.RANTransparentContainer:
+			r.RANTransparentContainer = i
+		case ie.RIMRoutingAddress:
+			r.RIMRoutingAddress = i
+		case ie.RIMRoutingAddressDiscriminator:
+			r.RIMRoutingAddressDiscriminator = i
+		case ie.PrivateExtension:
+			r.PrivateExtension = i
+		default:
+			r.AdditionalIEs = append(r.AdditionalIEs, i)
+		}
+	}
+
+	r.SetLength()
+	return r
+}
+
+// Marshal returns the byte sequence generated from a RANInformationRelay.
+func (r *RANInformationRelay) Marshal() ([]byte, error) {
+	b := make([]byte, r.MarshalLen())
+	if err := r.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// AppendTo appends the byte sequence generated from RANInformationRelay to b and returns the extended buffer.
+func (r *RANInformationRelay) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, r)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (r *RANInformationRelay) MarshalTo(b []byte) error {
+	if len(b) < r.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+	var err error
+	r.Header.Payload, err = r.Header.payloadIn(b, r.MarshalLen())
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if ie := r.RANTransparentContainer; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := r.RIMRoutingAddress; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := r.RIMRoutingAddressDiscriminator; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := r.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range r.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(r.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	r.Header.SetLength()
+	return r.Header.MarshalTo(b)
+}
+
+// ParseRANInformationRelay decodes a given byte sequence as a RANInformationRelay.
+func ParseRANInformationRelay(b []byte) (*RANInformationRelay, error) {
+	r := &RANInformationRelay{}
+	if err := r.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// UnmarshalBinary decodes a given byte sequence as a RANInformationRelay.
+func (r *RANInformationRelay) UnmarshalBinary(b []byte) error {
+	var err error
+	r.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(r.Header.Payload) < 2 {
+		return nil
+	}
+
+	IEs, err := ie.ParseMultiIEs(r.Header.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.RANTransparentContainer:
+			r.RANTransparentContainer = i
+		case ie.RIMRoutingAddress:
+			r.RIMRoutingAddress = i
+		case ie.RIMRoutingAddressDiscriminator:
+			r.RIMRoutingAddressDiscriminator = i
+		case ie.PrivateExtension:
+			r.PrivateExtension = i
+		default:
+			r.AdditionalIEs = append(r.AdditionalIEs, i)
+		}
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (r *RANInformationRelay) MarshalLen() int {
+	l := r.Header.MarshalLen() - len(r.Header.Payload)
+
+	if ie := r.RANTransparentContainer; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := r.RIMRoutingAddress; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := r.RIMRoutingAddressDiscriminator; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := r.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range r.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (r *RANInformationRelay) SetLength() {
+	r.Length = uint16(r.MarshalLen() - 8)
+}
+
+// MessageTypeName returns the name of protocol.
+func (r *RANInformationRelay) MessageTypeName() string {
+	return "RAN Information Relay"
+}
+
+// TEID returns the TEID in human-readable string.
+func (r *RANInformationRelay) TEID() uint32 {
+	return r.Header.TEID
+}
@@ -0,0 +1,47 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
+	"github.com/wmnsk/go-gtp/gtpv1/testutils"
+)
+
+func TestRANInformationRelay(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "Normal",
+			Structured: message.NewRANInformationRelay(
+				testutils.TestBearerInfo.TEID, testutils.TestBearerInfo.Seq,
+				ie.NewRANTransparentContainer([]byte{0x71, 0x00, 0x01, 0x02}),
+				ie.NewRIMRoutingAddress([]byte{0x21, 0xf3, 0x54, 0x11, 0x11, 0x22, 0x00, 0x01}),
+				ie.NewRIMRoutingAddressDiscriminator(ie.RIMRoutingAddressDiscriminatorGERANCellID),
+			),
+			Serialized: []byte{
+				// Header
+				0x32, 0x46, 0x00, 0x1a, 0x11, 0x22, 0x33, 0x44,
+				0x00, 0x01, 0x00, 0x00,
+				// RAN Transparent Container
+				0x90, 0x00, 0x04, 0x71, 0x00, 0x01, 0x02,
+				// RIM Routing Address
+				0x9e, 0x00, 0x08, 0x21, 0xf3, 0x54, 0x11, 0x11, 0x22, 0x00, 0x01,
+				// RIM Routing Address Discriminator
+				0xb2, 0x00, 0x01, 0x00,
+			},
+		},
+	}
+
+	testutils.Run(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseRANInformationRelay(b)
+		if err != nil {
+			return nil, err
+		}
+		v.Payload = nil
+		return v, nil
+	})
+}
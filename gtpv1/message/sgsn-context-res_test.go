@@ -0,0 +1,78 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	v1 "github.com/wmnsk/go-gtp/gtpv1"
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
+	"github.com/wmnsk/go-gtp/gtpv1/testutils"
+)
+
+func TestSGSNContextResponse(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "Normal",
+			Structured: message.NewSGSNContextResponse(
+				testutils.TestBearerInfo.TEID, testutils.TestBearerInfo.Seq,
+				ie.NewCause(v1.ResCauseRequestAccepted),
+				ie.NewIMSI("123451234567890"),
+				ie.NewTEIDCPlane(0xdeadbeef),
+				ie.NewMMContextGSMKeyAndTriplets(
+					1, 1, []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08},
+					[]byte{0x09, 0x10}, []byte{0xe5, 0xe0},
+				),
+				ie.NewPDPContext(&ie.PDPContextFields{
+					NSAPI:                5,
+					SAPI:                 3,
+					QoSSubscribed:        []byte{0x01, 0x02, 0x03},
+					QoSRequested:         []byte{0x01, 0x02, 0x03},
+					QoSNegotiated:        []byte{0x01, 0x02, 0x03},
+					UplinkTEIDCPlane:     0x11111111,
+					UplinkTEIDDataI:      0x22222222,
+					PDPContextIdentifier: 1,
+					PDPTypeOrganization:  1,
+					PDPTypeNumber:        0x21,
+				}),
+				ie.NewGSNAddress("1.1.1.1"),
+			),
+			Serialized: []byte{
+				// Header
+				0x32, 0x33, 0x00, 0x57, 0x11, 0x22, 0x33, 0x44,
+				0x00, 0x01, 0x00, 0x00,
+				// Cause
+				0x01, 0x80,
+				// IMSI
+				0x02, 0x21, 0x43, 0x15, 0x32, 0x54, 0x76, 0x98, 0xf0,
+				// TEID-C
+				0x11, 0xde, 0xad, 0xbe, 0xef,
+				// MM Context
+				0x81, 0x00, 0x11,
+				0xf9, 0x41, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+				0x09, 0x10, 0x02, 0xe5, 0xe0, 0x00, 0x00,
+				// PDP Context
+				0x82, 0x00, 0x25,
+				0x05, 0x03,
+				0x03, 0x01, 0x02, 0x03, 0x03, 0x01, 0x02, 0x03, 0x03, 0x01, 0x02, 0x03,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x11, 0x11, 0x11, 0x11, 0x22, 0x22, 0x22, 0x22,
+				0x01, 0xf1, 0x21, 0x00, 0x00, 0x00, 0x00, 0xf0, 0x00,
+				// GSN Address
+				0x85, 0x00, 0x04, 0x01, 0x01, 0x01, 0x01,
+			},
+		},
+	}
+
+	testutils.Run(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseSGSNContextResponse(b)
+		if err != nil {
+			return nil, err
+		}
+		v.Payload = nil
+		return v, nil
+	})
+}
@@ -0,0 +1,158 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+)
+
+// SupportedExtensionHeadersNotification is a SupportedExtensionHeadersNotification Header and its IEs above.
+type SupportedExtensionHeadersNotification struct {
+	*Header
+	ExtensionHeaderTypeList *ie.IE
+	AdditionalIEs           []*ie.IE
+}
+
+// NewSupportedExtensionHeadersNotification creates a new GTPv1 SupportedExtensionHeadersNotification.
+func NewSupportedExtensionHeadersNotification(teid uint32, seq uint16, IEs ...*ie.IE) *SupportedExtensionHeadersNotification {
+	s := &SupportedExtensionHeadersNotification{
+		Header: NewHeader(0x32, MsgTypeSupportedExtensionHeadersNotification, teid, seq, nil),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.ExtensionHeaderTypeList:
+			s.ExtensionHeaderTypeList = i
+		default:
+			s.AdditionalIEs = append(s.AdditionalIEs, i)
+		}
+	}
+
+	s.SetLength()
+	return s
+}
+
+// Marshal returns the byte sequence generated from a SupportedExtensionHeadersNotification.
+func (s *SupportedExtensionHeadersNotification) Marshal() ([]byte, error) {
+	b := make([]byte, s.MarshalLen())
+	if err := s.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// AppendTo appends the byte sequence generated from SupportedExtensionHeadersNotification to b and returns the extended buffer.
+func (s *SupportedExtensionHeadersNotification) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, s)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (s *SupportedExtensionHeadersNotification) MarshalTo(b []byte) error {
+	if len(b) < s.MarshalLen() {
+		return ErrTooShortToMarshal
+	}
+	var err error
+	s.Header.Payload, err = s.Header.payloadIn(b, s.MarshalLen())
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if ie := s.ExtensionHeaderTypeList; ie != nil {
+		if err := ie.MarshalTo(s.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range s.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(s.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	s.Header.SetLength()
+	return s.Header.MarshalTo(b)
+}
+
+// ParseSupportedExtensionHeadersNotification decodes a given byte sequence as a SupportedExtensionHeadersNotification.
+func ParseSupportedExtensionHeadersNotification(b []byte) (*SupportedExtensionHeadersNotification, error) {
+	s := &SupportedExtensionHeadersNotification{}
+	if err := s.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// UnmarshalBinary decodes a given byte sequence as a SupportedExtensionHeadersNotification.
+func (s *SupportedExtensionHeadersNotification) UnmarshalBinary(b []byte) error {
+	var err error
+	s.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(s.Header.Payload) < 2 {
+		return nil
+	}
+
+	decodedIEs, err := ie.ParseMultiIEs(s.Header.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range decodedIEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.ExtensionHeaderTypeList:
+			s.ExtensionHeaderTypeList = i
+		default:
+			s.AdditionalIEs = append(s.AdditionalIEs, i)
+		}
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (s *SupportedExtensionHeadersNotification) MarshalLen() int {
+	l := s.Header.MarshalLen() - len(s.Header.Payload)
+
+	if ie := s.ExtensionHeaderTypeList; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range s.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (s *SupportedExtensionHeadersNotification) SetLength() {
+	s.Header.Length = uint16(s.MarshalLen() - 8)
+}
+
+// MessageTypeName returns the name of protocol.
+func (s *SupportedExtensionHeadersNotification) MessageTypeName() string {
+	return "Supported Extension Headers Notification"
+}
+
+// TEID returns the TEID in human-readable string.
+func (s *SupportedExtensionHeadersNotification) TEID() uint32 {
+	return s.Header.TEID
+}
@@ -0,0 +1,42 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
+	"github.com/wmnsk/go-gtp/gtpv1/testutils"
+)
+
+func TestSupportedExtensionHeadersNotification(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "Normal",
+			Structured: message.NewSupportedExtensionHeadersNotification(
+				0, 0, ie.NewExtensionHeaderTypeList(
+					message.ExtHeaderTypeUDPPort,
+					message.ExtHeaderTypePDUSessionContainer,
+					message.ExtHeaderTypePDCPPDUNumber,
+				),
+			),
+			Serialized: []byte{
+				0x32, 0x1f, 0x00, 0x09, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x8d, 0x03, 0x40, 0x85,
+				0xc0,
+			},
+		},
+	}
+
+	testutils.Run(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseSupportedExtensionHeadersNotification(b)
+		if err != nil {
+			return nil, err
+		}
+		v.Payload = nil
+		return v, nil
+	})
+}
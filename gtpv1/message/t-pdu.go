@@ -25,6 +25,12 @@ func NewTPDUWithSequence(teid uint32, seq uint16, payload []byte) *TPDU {
 	return t
 }
 
+// NewTPDUWithOptions creates a new G-PDU message with the optional fields given
+// as HeaderOptions. Without any options, the header is the short one of 8 octets.
+func NewTPDUWithOptions(teid uint32, payload []byte, opts ...HeaderOption) *TPDU {
+	return &TPDU{Header: NewHeaderWithOptions(MsgTypeTPDU, teid, payload, opts...)}
+}
+
 // Marshal returns the byte sequence generated from a TPDU.
 func (t *TPDU) Marshal() ([]byte, error) {
 	b := make([]byte, t.MarshalLen())
@@ -35,6 +41,11 @@ func (t *TPDU) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from TPDU to b and returns the extended buffer.
+func (t *TPDU) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, t)
+}
+
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (t *TPDU) MarshalTo(b []byte) error {
 	if len(b) < t.MarshalLen() {
@@ -5,6 +5,7 @@
 package message_test
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/wmnsk/go-gtp/gtpv1/message"
@@ -27,6 +28,16 @@ func TestTPDU(t *testing.T) {
 				0x32, 0xff, 0x00, 0x08, 0xde, 0xad, 0xbe, 0xef,
 				0x00, 0x01, 0x00, 0x00, 0xde, 0xad, 0xbe, 0xef,
 			},
+		}, {
+			Description: "With-Options",
+			Structured: message.NewTPDUWithOptions(
+				0xdeadbeef, []byte{0xde, 0xad, 0xbe, 0xef},
+				message.WithSequenceNumber(0x0001), message.WithNPDUNumber(0x02),
+			),
+			Serialized: []byte{
+				0x33, 0xff, 0x00, 0x08, 0xde, 0xad, 0xbe, 0xef,
+				0x00, 0x01, 0x02, 0x00, 0xde, 0xad, 0xbe, 0xef,
+			},
 		},
 	}
 
@@ -38,3 +49,29 @@ func TestTPDU(t *testing.T) {
 		return v, nil
 	})
 }
+
+func TestTPDUAppendTo(t *testing.T) {
+	m := message.NewTPDU(0xdeadbeef, []byte{0xde, 0xad, 0xbe, 0xef})
+	want := []byte{
+		0x30, 0xff, 0x00, 0x04, 0xde, 0xad, 0xbe, 0xef,
+		0xde, 0xad, 0xbe, 0xef,
+	}
+
+	buf := make([]byte, 0, 64)
+	b, err := m.AppendTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, want) {
+		t.Errorf("got %x, want %x", b, want)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := m.AppendTo(buf[:0]); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("AppendTo allocates %v times with the buffer large enough", allocs)
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import "encoding/binary"
+
+// NewUDPPortExtensionHeader creates a new UDP Port extension header, which is
+// used in Error Indication to carry the UDP source port of the G-PDU that
+// triggered it, defined in TS 29.281 5.2.2.1.
+func NewUDPPortExtensionHeader(port uint16) *ExtensionHeader {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, port)
+	return NewExtensionHeader(ExtHeaderTypeUDPPort, b)
+}
+
+// UDPPort returns the port number in the UDP Port extension header.
+func (e *ExtensionHeader) UDPPort() (uint16, error) {
+	if e.Type != ExtHeaderTypeUDPPort {
+		return 0, ErrInvalidExtensionHeaderType
+	}
+	if len(e.Content) < 2 {
+		return 0, ErrTooShortToParse
+	}
+	return binary.BigEndian.Uint16(e.Content[0:2]), nil
+}
+
+// MustUDPPort returns the port number in the UDP Port extension header.
+// This should only be used if it is assured to have the value.
+func (e *ExtensionHeader) MustUDPPort() uint16 {
+	v, _ := e.UDPPort()
+	return v
+}
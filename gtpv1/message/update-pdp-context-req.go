@@ -139,12 +139,21 @@ func (u *UpdatePDPContextRequest) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from UpdatePDPContextRequest to b and returns the extended buffer.
+func (u *UpdatePDPContextRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, u)
+}
+
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (u *UpdatePDPContextRequest) MarshalTo(b []byte) error {
 	if len(b) < u.MarshalLen() {
 		return ErrTooShortToMarshal
 	}
-	u.Header.Payload = make([]byte, u.MarshalLen()-u.Header.MarshalLen())
+	var err error
+	u.Header.Payload, err = u.Header.payloadIn(b, u.MarshalLen())
+	if err != nil {
+		return err
+	}
 
 	offset := 0
 	if ie := u.IMSI; ie != nil {
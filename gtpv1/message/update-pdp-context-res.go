@@ -111,12 +111,21 @@ func (u *UpdatePDPContextResponse) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from UpdatePDPContextResponse to b and returns the extended buffer.
+func (u *UpdatePDPContextResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, u)
+}
+
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (u *UpdatePDPContextResponse) MarshalTo(b []byte) error {
 	if len(b) < u.MarshalLen() {
 		return ErrTooShortToMarshal
 	}
-	u.Header.Payload = make([]byte, u.MarshalLen()-u.Header.MarshalLen())
+	var err error
+	u.Header.Payload, err = u.Header.payloadIn(b, u.MarshalLen())
+	if err != nil {
+		return err
+	}
 
 	offset := 0
 	if ie := u.Cause; ie != nil {
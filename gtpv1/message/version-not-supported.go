@@ -41,12 +41,21 @@ func (v *VersionNotSupported) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from VersionNotSupported to b and returns the extended buffer.
+func (v *VersionNotSupported) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, v)
+}
+
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (v *VersionNotSupported) MarshalTo(b []byte) error {
 	if len(b) < v.MarshalLen() {
 		return ErrTooShortToMarshal
 	}
-	v.Header.Payload = make([]byte, v.MarshalLen()-v.Header.MarshalLen())
+	var err error
+	v.Header.Payload, err = v.Header.payloadIn(b, v.MarshalLen())
+	if err != nil {
+		return err
+	}
 
 	offset := 0
 	for _, ie := range v.AdditionalIEs {
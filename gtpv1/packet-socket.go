@@ -0,0 +1,42 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv1
+
+import "time"
+
+// PacketSocketConfig is the configuration of the ring buffer used by PacketSocketConn
+// to receive the packets.
+type PacketSocketConfig struct {
+	// BlockSize is the size of each block in the ring, which should be a multiple of
+	// the page size. The default is 1MB.
+	BlockSize int
+	// NumBlocks is the number of blocks in the ring. The default is 64.
+	NumBlocks int
+	// BlockTimeout is the time after which the kernel passes the block to the reader
+	// even if it is not full. The default is 1ms.
+	BlockTimeout time.Duration
+}
+
+func (c *PacketSocketConfig) withDefaults() *PacketSocketConfig {
+	cfg := &PacketSocketConfig{
+		BlockSize:    1 << 20,
+		NumBlocks:    64,
+		BlockTimeout: time.Millisecond,
+	}
+	if c == nil {
+		return cfg
+	}
+
+	if c.BlockSize > 0 {
+		cfg.BlockSize = c.BlockSize
+	}
+	if c.NumBlocks > 0 {
+		cfg.NumBlocks = c.NumBlocks
+	}
+	if c.BlockTimeout > 0 {
+		cfg.BlockTimeout = c.BlockTimeout
+	}
+	return cfg
+}
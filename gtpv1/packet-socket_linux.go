@@ -0,0 +1,608 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package gtpv1
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/net/bpf"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	ipv4HeaderLen = 20
+	udpHeaderLen  = 8
+
+	// pollInterval is the longest time to wait for the ring without checking
+	// whether the conn is closed.
+	pollInterval = 100 * time.Millisecond
+
+	// neighborTTL is how long the resolved hardware address is cached.
+	neighborTTL = 30 * time.Second
+)
+
+// PacketSocketConn is a net.PacketConn that sends and receives UDP/IPv4 packets
+// directly on a network interface with AF_PACKET socket, bypassing the UDP and IP
+// layers of the kernel.
+//
+// The packets are received through the memory-mapped ring buffer(TPACKET_V3)
+// which is filled by the kernel without any system call per packet, and are sent
+// at once with sendmmsg(2) when written with WriteBatchToGTP. A classic BPF filter
+// is attached to the socket to receive only the non-fragmented UDP packets destined
+// for the local address.
+//
+// The hardware address of the next hop is looked up in the neighbor table of the
+// kernel, which means that the peer(or the gateway to it) should have been resolved
+// by the kernel, or be registered statically, before writing to it.
+type PacketSocketConn struct {
+	fd       int
+	ifindex  int
+	ifname   string
+	loopback bool
+	laddr    *net.UDPAddr
+
+	// udpConn holds the local address to prevent the other sockets from using it,
+	// and the kernel from responding to the packets with ICMP Port Unreachable.
+	udpConn *net.UDPConn
+
+	// rmu protects the reader state and the ring from being unmapped while read.
+	rmu       sync.Mutex
+	ring      []byte
+	blockSize int
+	numBlocks int
+	block     int
+	inBlock   bool
+	numPkts   int
+	pkt       int
+	offset    int
+
+	readDeadline  int64
+	writeDeadline int64
+	closed        int32
+	ipID          uint32
+
+	nmu       sync.Mutex
+	neighbors map[string]*neighbor
+}
+
+type neighbor struct {
+	hwAddr  net.HardwareAddr
+	expires time.Time
+}
+
+// ListenPacketSocket opens the AF_PACKET socket on the network interface named
+// ifname and returns PacketSocketConn that receives the UDP packets destined for
+// laddr. laddr should be one of the IPv4 addresses on the interface, with the port
+// number specified. If cfg is nil, the default values are used.
+//
+// The returned PacketSocketConn can be used as the backend of UPlaneConn with
+// NewUPlaneConnWithPacketConn. Opening AF_PACKET socket requires CAP_NET_RAW.
+func ListenPacketSocket(ifname string, laddr *net.UDPAddr, cfg *PacketSocketConfig) (*PacketSocketConn, error) {
+	if laddr == nil || laddr.IP.To4() == nil || laddr.IP.IsUnspecified() || laddr.Port == 0 {
+		return nil, errors.Errorf("invalid local address for PacketSocketConn: %v", laddr)
+	}
+	cfg = cfg.withDefaults()
+
+	ifi, err := net.InterfaceByName(ifname)
+	if err != nil {
+		return nil, err
+	}
+
+	udpConn, err := net.ListenUDP("udp4", laddr)
+	if err != nil {
+		return nil, err
+	}
+	// the packets are received through the ring, so nothing needs to be queued here.
+	_ = udpConn.SetReadBuffer(1)
+
+	c := &PacketSocketConn{
+		ifindex:   ifi.Index,
+		ifname:    ifname,
+		loopback:  ifi.Flags&net.FlagLoopback != 0,
+		laddr:     &net.UDPAddr{IP: laddr.IP.To4(), Port: laddr.Port},
+		udpConn:   udpConn,
+		blockSize: cfg.BlockSize,
+		numBlocks: cfg.NumBlocks,
+		neighbors: map[string]*neighbor{},
+	}
+	if err := c.open(cfg.BlockTimeout); err != nil {
+		_ = udpConn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *PacketSocketConn) open(timeout time.Duration) error {
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, int(htons(unix.ETH_P_IP)))
+	if err != nil {
+		return errors.Wrap(err, "failed to open AF_PACKET socket")
+	}
+
+	if err := c.setup(fd, timeout); err != nil {
+		_ = unix.Close(fd)
+		return err
+	}
+	c.fd = fd
+	return nil
+}
+
+func (c *PacketSocketConn) setup(fd int, timeout time.Duration) error {
+	filter, err := bpf.Assemble(c.filter())
+	if err != nil {
+		return err
+	}
+	prog := make([]unix.SockFilter, len(filter))
+	for i, ins := range filter {
+		prog[i] = unix.SockFilter{Code: ins.Op, Jt: ins.Jt, Jf: ins.Jf, K: ins.K}
+	}
+	if err := unix.SetsockoptSockFprog(fd, unix.SOL_SOCKET, unix.SO_ATTACH_FILTER, &unix.SockFprog{
+		Len:    uint16(len(prog)),
+		Filter: &prog[0],
+	}); err != nil {
+		return errors.Wrap(err, "failed to attach filter")
+	}
+
+	if err := unix.SetsockoptInt(fd, unix.SOL_PACKET, unix.PACKET_VERSION, unix.TPACKET_V3); err != nil {
+		return errors.Wrap(err, "failed to set TPACKET_V3")
+	}
+	if err := unix.SetsockoptTpacketReq3(fd, unix.SOL_PACKET, unix.PACKET_RX_RING, &unix.TpacketReq3{
+		Block_size:     uint32(c.blockSize),
+		Block_nr:       uint32(c.numBlocks),
+		Frame_size:     uint32(unix.Getpagesize()),
+		Frame_nr:       uint32(c.blockSize / unix.Getpagesize() * c.numBlocks),
+		Retire_blk_tov: uint32(timeout / time.Millisecond),
+	}); err != nil {
+		return errors.Wrap(err, "failed to set up ring buffer")
+	}
+
+	ring, err := unix.Mmap(fd, 0, c.blockSize*c.numBlocks, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+	if err != nil {
+		return errors.Wrap(err, "failed to map ring buffer")
+	}
+
+	if err := unix.Bind(fd, &unix.SockaddrLinklayer{
+		Protocol: htons(unix.ETH_P_IP),
+		Ifindex:  c.ifindex,
+	}); err != nil {
+		_ = unix.Munmap(ring)
+		return errors.Wrapf(err, "failed to bind to %s", c.ifname)
+	}
+
+	c.ring = ring
+	return nil
+}
+
+// filter returns the BPF program that accepts only the incoming, non-fragmented
+// UDP packets destined for the local address.
+func (c *PacketSocketConn) filter() []bpf.Instruction {
+	return []bpf.Instruction{
+		bpf.LoadExtension{Num: bpf.ExtType},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: unix.PACKET_OUTGOING, SkipTrue: 10},
+		// protocol
+		bpf.LoadAbsolute{Off: 9, Size: 1},
+		bpf.JumpIf{Cond: bpf.JumpNotEqual, Val: unix.IPPROTO_UDP, SkipTrue: 8},
+		// destination address
+		bpf.LoadAbsolute{Off: 16, Size: 4},
+		bpf.JumpIf{Cond: bpf.JumpNotEqual, Val: binary.BigEndian.Uint32(c.laddr.IP), SkipTrue: 6},
+		// MF flag and fragment offset
+		bpf.LoadAbsolute{Off: 6, Size: 2},
+		bpf.JumpIf{Cond: bpf.JumpBitsSet, Val: 0x3fff, SkipTrue: 4},
+		// destination port, after the IP header with variable length
+		bpf.LoadMemShift{Off: 0},
+		bpf.LoadIndirect{Off: 2, Size: 2},
+		bpf.JumpIf{Cond: bpf.JumpNotEqual, Val: uint32(c.laddr.Port), SkipTrue: 1},
+		bpf.RetConstant{Val: 0x40000},
+		bpf.RetConstant{Val: 0},
+	}
+}
+
+// ReadFrom reads a UDP packet from the ring and copies the payload into p.
+func (c *PacketSocketConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+
+	for {
+		pkt, err := c.next(true)
+		if err != nil {
+			return 0, nil, err
+		}
+		if payload, raddr, ok := parseUDPv4(pkt); ok {
+			return copy(p, payload), raddr, nil
+		}
+	}
+}
+
+// ReadBatch reads the UDP packets into ms. It blocks until at least one packet
+// is available and returns the ones that are already in the ring, up to len(ms).
+//
+// Only the first buffer in ms[i].Buffers is used. flags is ignored.
+func (c *PacketSocketConn) ReadBatch(ms []ipv4.Message, flags int) (int, error) {
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+
+	n := 0
+	for n < len(ms) {
+		pkt, err := c.next(n == 0)
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		if pkt == nil {
+			break
+		}
+
+		payload, raddr, ok := parseUDPv4(pkt)
+		if !ok {
+			continue
+		}
+		ms[n].N = copy(ms[n].Buffers[0], payload)
+		ms[n].Addr = raddr
+		n++
+	}
+	return n, nil
+}
+
+// next returns the next packet in the ring, which is valid until the next call.
+// If wait is false, it returns nil instead of waiting for the packet to arrive.
+func (c *PacketSocketConn) next(wait bool) ([]byte, error) {
+	for {
+		if atomic.LoadInt32(&c.closed) != 0 {
+			return nil, io.EOF
+		}
+
+		if !c.inBlock {
+			desc := c.blockDesc(c.block)
+			if atomic.LoadUint32(&desc.Block_status)&unix.TP_STATUS_USER == 0 {
+				if !wait {
+					return nil, nil
+				}
+				if err := c.poll(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			c.inBlock = true
+			c.numPkts = int(desc.Num_pkts)
+			c.offset = int(desc.Offset_to_first_pkt)
+			c.pkt = 0
+		}
+
+		if c.pkt == c.numPkts {
+			// give the block back to the kernel.
+			atomic.StoreUint32(&c.blockDesc(c.block).Block_status, unix.TP_STATUS_KERNEL)
+			c.block = (c.block + 1) % c.numBlocks
+			c.inBlock = false
+			continue
+		}
+
+		base := c.block*c.blockSize + c.offset
+		hdr := (*unix.Tpacket3Hdr)(unsafe.Pointer(&c.ring[base]))
+		start := base + int(hdr.Net)
+		c.offset += int(hdr.Next_offset)
+		c.pkt++
+
+		return c.ring[start : start+int(hdr.Snaplen)], nil
+	}
+}
+
+// blockDesc returns the header of i-th block, which follows the version and
+// offset_to_priv fields of struct tpacket_block_desc.
+func (c *PacketSocketConn) blockDesc(i int) *unix.TpacketHdrV1 {
+	return (*unix.TpacketHdrV1)(unsafe.Pointer(&c.ring[i*c.blockSize+8]))
+}
+
+// poll waits for the kernel to fill the block, or the read deadline to exceed.
+func (c *PacketSocketConn) poll() error {
+	timeout := pollInterval
+	if d := atomic.LoadInt64(&c.readDeadline); d != 0 {
+		left := time.Until(time.Unix(0, d))
+		if left <= 0 {
+			return &timeoutError{}
+		}
+		if left < timeout {
+			timeout = left
+		}
+	}
+
+	ms := int((timeout + time.Millisecond - 1) / time.Millisecond)
+	fds := []unix.PollFd{{Fd: int32(c.fd), Events: unix.POLLIN | unix.POLLERR}}
+	if _, err := unix.Poll(fds, ms); err != nil && err != unix.EINTR {
+		return err
+	}
+	return nil
+}
+
+// WriteTo writes a UDP packet with payload p to addr.
+func (c *PacketSocketConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	if err := c.checkWritable(); err != nil {
+		return 0, err
+	}
+
+	raddr, ok := addr.(*net.UDPAddr)
+	if !ok || raddr.IP.To4() == nil {
+		return 0, errors.Errorf("invalid address for PacketSocketConn: %v", addr)
+	}
+	sa, err := c.sockaddr(raddr.IP)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := unix.Sendto(c.fd, c.encapsulate(p, raddr), 0, sa); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteBatch writes the UDP packets in ms at once with sendmmsg(2).
+//
+// Only the first buffer in ms[i].Buffers is used. ms[i].OOB and flags are ignored.
+func (c *PacketSocketConn) WriteBatch(ms []ipv4.Message, flags int) (int, error) {
+	if err := c.checkWritable(); err != nil {
+		return 0, err
+	}
+
+	hdrs := make([]mmsghdr, len(ms))
+	iovs := make([]unix.Iovec, len(ms))
+	names := make([]unix.RawSockaddrLinklayer, len(ms))
+	for i, m := range ms {
+		raddr, ok := m.Addr.(*net.UDPAddr)
+		if !ok || raddr.IP.To4() == nil {
+			return 0, errors.Errorf("invalid address for PacketSocketConn: %v", m.Addr)
+		}
+		hw, err := c.resolve(raddr.IP)
+		if err != nil {
+			return 0, err
+		}
+
+		pkt := c.encapsulate(m.Buffers[0], raddr)
+		iovs[i].Base = &pkt[0]
+		iovs[i].SetLen(len(pkt))
+
+		names[i].Family = unix.AF_PACKET
+		names[i].Protocol = htons(unix.ETH_P_IP)
+		names[i].Ifindex = int32(c.ifindex)
+		names[i].Halen = uint8(copy(names[i].Addr[:], hw))
+
+		hdrs[i].hdr.Name = (*byte)(unsafe.Pointer(&names[i]))
+		hdrs[i].hdr.Namelen = unix.SizeofSockaddrLinklayer
+		hdrs[i].hdr.Iov = &iovs[i]
+		hdrs[i].hdr.SetIovlen(1)
+	}
+
+	n := 0
+	for n < len(hdrs) {
+		r, _, errno := unix.Syscall6(
+			unix.SYS_SENDMMSG, uintptr(c.fd),
+			uintptr(unsafe.Pointer(&hdrs[n])), uintptr(len(hdrs)-n),
+			0, 0, 0,
+		)
+		if errno != 0 {
+			if errno == unix.EINTR {
+				continue
+			}
+			return n, errno
+		}
+		for i := n; i < n+int(r); i++ {
+			ms[i].N = len(ms[i].Buffers[0])
+		}
+		n += int(r)
+	}
+	runtime.KeepAlive(names)
+	runtime.KeepAlive(iovs)
+	return n, nil
+}
+
+// mmsghdr is struct mmsghdr used by sendmmsg(2), which is not defined in x/sys/unix.
+type mmsghdr struct {
+	hdr unix.Msghdr
+	len uint32
+}
+
+func (c *PacketSocketConn) checkWritable() error {
+	if atomic.LoadInt32(&c.closed) != 0 {
+		return errors.New("use of closed PacketSocketConn")
+	}
+	if d := atomic.LoadInt64(&c.writeDeadline); d != 0 && time.Now().UnixNano() >= d {
+		return &timeoutError{}
+	}
+	return nil
+}
+
+// encapsulate returns the IPv4 packet that contains UDP packet with payload p.
+// The UDP checksum is left zero, which is allowed in IPv4.
+func (c *PacketSocketConn) encapsulate(p []byte, raddr *net.UDPAddr) []byte {
+	l := ipv4HeaderLen + udpHeaderLen + len(p)
+	b := make([]byte, l)
+
+	b[0] = 0x45
+	binary.BigEndian.PutUint16(b[2:4], uint16(l))
+	binary.BigEndian.PutUint16(b[4:6], uint16(atomic.AddUint32(&c.ipID, 1)))
+	b[6] = 0x40 // Don't Fragment
+	b[8] = 64
+	b[9] = unix.IPPROTO_UDP
+	copy(b[12:16], c.laddr.IP)
+	copy(b[16:20], raddr.IP.To4())
+	binary.BigEndian.PutUint16(b[10:12], ipv4Checksum(b[:ipv4HeaderLen]))
+
+	binary.BigEndian.PutUint16(b[20:22], uint16(c.laddr.Port))
+	binary.BigEndian.PutUint16(b[22:24], uint16(raddr.Port))
+	binary.BigEndian.PutUint16(b[24:26], uint16(udpHeaderLen+len(p)))
+	copy(b[28:], p)
+
+	return b
+}
+
+func (c *PacketSocketConn) sockaddr(ip net.IP) (*unix.SockaddrLinklayer, error) {
+	hw, err := c.resolve(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	sa := &unix.SockaddrLinklayer{
+		Protocol: htons(unix.ETH_P_IP),
+		Ifindex:  c.ifindex,
+		Halen:    uint8(len(hw)),
+	}
+	copy(sa.Addr[:], hw)
+	return sa, nil
+}
+
+// resolve returns the hardware address of the next hop to ip.
+func (c *PacketSocketConn) resolve(ip net.IP) (net.HardwareAddr, error) {
+	if c.loopback {
+		return make(net.HardwareAddr, 6), nil
+	}
+
+	key := string(ip.To4())
+	c.nmu.Lock()
+	defer c.nmu.Unlock()
+	if n, ok := c.neighbors[key]; ok && time.Now().Before(n.expires) {
+		return n.hwAddr, nil
+	}
+
+	nexthop := ip
+	routes, err := netlink.RouteGet(ip)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range routes {
+		if r.LinkIndex != c.ifindex {
+			continue
+		}
+		if r.Gw != nil {
+			nexthop = r.Gw
+		}
+		break
+	}
+
+	neighs, err := netlink.NeighList(c.ifindex, netlink.FAMILY_V4)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range neighs {
+		if !n.IP.Equal(nexthop) || len(n.HardwareAddr) == 0 {
+			continue
+		}
+		if n.State&(netlink.NUD_INCOMPLETE|netlink.NUD_FAILED) != 0 {
+			continue
+		}
+		c.neighbors[key] = &neighbor{hwAddr: n.HardwareAddr, expires: time.Now().Add(neighborTTL)}
+		return n.HardwareAddr, nil
+	}
+	return nil, errors.Errorf("no neighbor entry for %s on %s", nexthop, c.ifname)
+}
+
+// Close closes the socket and unmaps the ring.
+// The ReadFrom or ReadBatch blocked on the conn returns io.EOF.
+func (c *PacketSocketConn) Close() error {
+	if !atomic.CompareAndSwapInt32(&c.closed, 0, 1) {
+		return nil
+	}
+
+	// wait for the reader to leave the ring.
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+
+	_ = unix.Munmap(c.ring)
+	c.ring = nil
+	if err := unix.Close(c.fd); err != nil {
+		_ = c.udpConn.Close()
+		return err
+	}
+	return c.udpConn.Close()
+}
+
+// LocalAddr returns the local address that the conn receives the packets on.
+func (c *PacketSocketConn) LocalAddr() net.Addr {
+	return c.laddr
+}
+
+// SetDeadline sets the read and write deadlines.
+func (c *PacketSocketConn) SetDeadline(t time.Time) error {
+	if err := c.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.SetWriteDeadline(t)
+}
+
+// SetReadDeadline sets the deadline for ReadFrom and ReadBatch.
+func (c *PacketSocketConn) SetReadDeadline(t time.Time) error {
+	atomic.StoreInt64(&c.readDeadline, unixNano(t))
+	return nil
+}
+
+// SetWriteDeadline sets the deadline for WriteTo and WriteBatch.
+// As writes do not block on the conn, it is only checked before writing.
+func (c *PacketSocketConn) SetWriteDeadline(t time.Time) error {
+	atomic.StoreInt64(&c.writeDeadline, unixNano(t))
+	return nil
+}
+
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// parseUDPv4 returns the payload and the source address of the UDP/IPv4 packet b.
+func parseUDPv4(b []byte) ([]byte, *net.UDPAddr, bool) {
+	if len(b) < ipv4HeaderLen || b[0]>>4 != 4 {
+		return nil, nil, false
+	}
+	ihl := int(b[0]&0x0f) * 4
+	if len(b) < ihl+udpHeaderLen {
+		return nil, nil, false
+	}
+
+	udp := b[ihl:]
+	l := int(binary.BigEndian.Uint16(udp[4:6]))
+	if l < udpHeaderLen || l > len(udp) {
+		return nil, nil, false
+	}
+
+	raddr := &net.UDPAddr{
+		IP:   net.IPv4(b[12], b[13], b[14], b[15]),
+		Port: int(binary.BigEndian.Uint16(udp[0:2])),
+	}
+	return udp[udpHeaderLen:l], raddr, true
+}
+
+func ipv4Checksum(h []byte) uint16 {
+	var sum uint32
+	for i := 0; i < len(h); i += 2 {
+		sum += uint32(h[i])<<8 | uint32(h[i+1])
+	}
+	for sum > 0xffff {
+		sum = (sum >> 16) + (sum & 0xffff)
+	}
+	return ^uint16(sum)
+}
+
+func htons(v uint16) uint16 {
+	return v<<8 | v>>8
+}
+
+// timeoutError is returned when the deadline of PacketSocketConn is exceeded.
+type timeoutError struct{}
+
+func (e *timeoutError) Error() string   { return "i/o timeout" }
+func (e *timeoutError) Timeout() bool   { return true }
+func (e *timeoutError) Temporary() bool { return true }
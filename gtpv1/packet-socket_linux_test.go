@@ -0,0 +1,143 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package gtpv1_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	v1 "github.com/wmnsk/go-gtp/gtpv1"
+)
+
+func TestPacketSocketConn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srvPC, err := v1.ListenPacketSocket("lo", &net.UDPAddr{IP: net.ParseIP("127.0.0.45"), Port: 2152}, nil)
+	if err != nil {
+		t.Skipf("AF_PACKET socket is not available: %v", err)
+	}
+	srvConn := v1.NewUPlaneConnWithPacketConn(srvPC)
+	srvConn.DisableErrorIndication()
+	srvConn.EnableBatchIO(8)
+	defer srvConn.Close()
+	go func() {
+		if err := srvConn.ListenAndServe(ctx); err != nil {
+			return
+		}
+	}()
+
+	cliPC, err := net.ListenPacket("udp", "127.0.0.46:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cliConn := v1.NewUPlaneConnWithPacketConn(cliPC)
+	cliConn.DisableErrorIndication()
+	defer cliConn.Close()
+	go func() {
+		if err := cliConn.ListenAndServe(ctx); err != nil {
+			return
+		}
+	}()
+
+	// the packets written on AF_PACKET socket with loopback address are dropped by
+	// the kernel as martian, so the ones from srvConn are received on another one.
+	peerPC, err := v1.ListenPacketSocket("lo", &net.UDPAddr{IP: net.ParseIP("127.0.0.47"), Port: 2152}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	peerConn := v1.NewUPlaneConnWithPacketConn(peerPC)
+	peerConn.DisableErrorIndication()
+	defer peerConn.Close()
+	go func() {
+		if err := peerConn.ListenAndServe(ctx); err != nil {
+			return
+		}
+	}()
+
+	payloads := make([][]byte, 20)
+	for i := range payloads {
+		payloads[i] = []byte{0xde, 0xad, 0xbe, 0xef, uint8(i)}
+	}
+
+	// readAll reads the T-PDUs on u and reports which of payloads are received.
+	readAll := func(t *testing.T, u *v1.UPlaneConn, from net.Addr, teid uint32) {
+		t.Helper()
+
+		errCh := make(chan error, 1)
+		got := make([]bool, len(payloads))
+		go func() {
+			buf := make([]byte, 1500)
+			for range payloads {
+				n, addr, gotTEID, err := u.ReadFromGTP(buf)
+				if err != nil {
+					errCh <- err
+					return
+				}
+
+				if addr.String() != from.String() {
+					t.Errorf("wrong source address. want: %s, got: %s", from, addr)
+				}
+				if gotTEID != teid {
+					t.Errorf("wrong TEID. want: %#x, got: %#x", teid, gotTEID)
+				}
+				if n != 5 || int(buf[4]) >= len(got) {
+					t.Errorf("unexpected payload: %x", buf[:n])
+					continue
+				}
+				got[buf[4]] = true
+			}
+			errCh <- nil
+		}()
+
+		select {
+		case err := <-errCh:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatal("timed out while reading T-PDUs")
+		}
+		for i, ok := range got {
+			if !ok {
+				t.Errorf("payload %d is not received", i)
+			}
+		}
+	}
+
+	t.Run("Receive", func(t *testing.T) {
+		for _, p := range payloads {
+			if _, err := cliConn.WriteToGTP(0x11111111, p, srvConn.LocalAddr()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		readAll(t, srvConn, cliConn.LocalAddr(), 0x11111111)
+	})
+
+	t.Run("WriteTo", func(t *testing.T) {
+		for _, p := range payloads {
+			if _, err := srvConn.WriteToGTP(0x22222222, p, peerConn.LocalAddr()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		readAll(t, peerConn, srvConn.LocalAddr(), 0x22222222)
+	})
+
+	t.Run("WriteBatch", func(t *testing.T) {
+		n, err := srvConn.WriteBatchToGTP(0x33333333, payloads, peerConn.LocalAddr())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != len(payloads) {
+			t.Fatalf("wrong number of packets written. want: %d, got: %d", len(payloads), n)
+		}
+		readAll(t, peerConn, srvConn.LocalAddr(), 0x33333333)
+	})
+}
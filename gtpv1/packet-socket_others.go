@@ -0,0 +1,25 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+//go:build !linux
+// +build !linux
+
+package gtpv1
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// PacketSocketConn is a net.PacketConn on AF_PACKET socket, which is available
+// only on Linux.
+type PacketSocketConn struct {
+	net.PacketConn
+}
+
+// ListenPacketSocket always fails, as AF_PACKET socket is available only on Linux.
+func ListenPacketSocket(ifname string, laddr *net.UDPAddr, cfg *PacketSocketConfig) (*PacketSocketConn, error) {
+	return nil, errors.New("PacketSocketConn is not supported on this platform")
+}
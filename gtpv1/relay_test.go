@@ -8,8 +8,11 @@ import (
 	"context"
 	"net"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	v1 "github.com/wmnsk/go-gtp/gtpv1"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
 )
 
 func TestRelay(t *testing.T) {
@@ -47,6 +50,99 @@ func TestRelay(t *testing.T) {
 	if err := rightConn.RelayTo(leftConn, 0x11111111, 0x22222222, leftAddr); err != nil {
 		t.Fatal(err)
 	}
+}
+
+func TestRelayTraffic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// eNB <-> (s1uConn) S-GW (s5uConn) <-> P-GW
+	var conns []*v1.UPlaneConn
+	for _, addr := range []string{"127.0.0.19:2152", "127.0.0.20:2152"} {
+		pc, err := net.ListenPacket("udp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u := v1.NewUPlaneConnWithPacketConn(pc)
+		go func() {
+			if err := u.ListenAndServe(ctx); err != nil {
+				return
+			}
+		}()
+		conns = append(conns, u)
+	}
+	s1uConn, s5uConn := conns[0], conns[1]
+
+	var peers []net.PacketConn
+	for _, addr := range []string{"127.0.0.21:2152", "127.0.0.22:2152"} {
+		pc, err := net.ListenPacket("udp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pc.Close()
+		peers = append(peers, pc)
+	}
+	enb, pgw := peers[0], peers[1]
+
+	// uplink: 0x11111111 on S1-U -> 0x22222222 on S5-U.
+	if err := s1uConn.RelayTo(s5uConn, 0x11111111, 0x22222222, pgw.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+
+	payload := []byte{0xde, 0xad, 0xbe, 0xef}
+	b, err := message.NewTPDU(0x11111111, payload).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := enb.WriteTo(b, s1uConn.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 1500)
+	if err := pgw.SetReadDeadline(time.Now().Add(3 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	n, raddr, err := pgw.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := raddr.String(), s5uConn.LocalAddr().String(); got != want {
+		t.Errorf("T-PDU should be sent from S5-U. want: %s, got: %s", want, got)
+	}
+
+	msg, err := message.Parse(buf[:n])
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpdu, ok := msg.(*message.TPDU)
+	if !ok {
+		t.Fatalf("got unexpected type of message: %T", msg)
+	}
+	if got, want := tpdu.TEID(), uint32(0x22222222); got != want {
+		t.Errorf("wrong TEID. want: %#x, got: %#x", want, got)
+	}
+	if diff := cmp.Diff(tpdu.Decapsulate(), payload); diff != "" {
+		t.Error(diff)
+	}
+
+	stats, ok := s1uConn.RelayStats(0x11111111)
+	if !ok {
+		t.Fatal("relay should be found")
+	}
+	want := &v1.RelayStats{
+		TEIDOut:  0x22222222,
+		PeerAddr: pgw.LocalAddr(),
+		Packets:  1,
+		Bytes:    uint64(len(b)),
+	}
+	if diff := cmp.Diff(stats, want); diff != "" {
+		t.Error(diff)
+	}
 
-	// TODO: add tests to check if the traffic goes through conns.
+	if err := s1uConn.CloseRelay(0x11111111); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s1uConn.RelayStats(0x11111111); ok {
+		t.Error("relay should be removed")
+	}
 }
@@ -0,0 +1,299 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv1
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wmnsk/go-gtp/gtpv1/message"
+)
+
+// EnableSequenceNumber makes the T-PDUs sent with the outgoing TEID given by
+// WriteToGTP and WriteToGTPWithExtensionHeaders have the sequence numbers, which
+// start from 0 and are incremented for each T-PDU.
+//
+// Note that the T-PDUs forwarded by RelayTo are sent as they are received.
+func (u *UPlaneConn) EnableSequenceNumber(otei uint32) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.egressSeqMap == nil {
+		u.egressSeqMap = map[uint32]uint16{}
+	}
+	if _, ok := u.egressSeqMap[otei]; !ok {
+		u.egressSeqMap[otei] = 0
+	}
+}
+
+// DisableSequenceNumber stops adding the sequence numbers to the T-PDUs sent with
+// the outgoing TEID given.
+func (u *UPlaneConn) DisableSequenceNumber(otei uint32) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	delete(u.egressSeqMap, otei)
+}
+
+// nextSequence returns the sequence number to be used for the T-PDU with the TEID
+// given, if enabled by EnableSequenceNumber.
+func (u *UPlaneConn) nextSequence(otei uint32) (uint16, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	seq, ok := u.egressSeqMap[otei]
+	if !ok {
+		return 0, false
+	}
+	u.egressSeqMap[otei] = seq + 1
+	return seq, true
+}
+
+// encapsulate returns the T-PDU with the sequence number if it is enabled for the TEID.
+func (u *UPlaneConn) encapsulate(teid uint32, p []byte) *message.TPDU {
+	if seq, ok := u.nextSequence(teid); ok {
+		return message.NewTPDUWithSequence(teid, seq, p)
+	}
+	return Encapsulate(teid, p)
+}
+
+// EnableReordering makes the T-PDUs with the incoming TEID given passed to
+// ReadFromGTP in the order of their sequence numbers.
+//
+// The first T-PDUs are buffered in the same way until the buffer is full or timeout
+// expires, and the one with the lowest sequence number among them is taken as the
+// first one. Up to window T-PDUs that come later than the missing one are buffered.
+// When the buffer is full, or the missing one does not come within timeout, the
+// buffered T-PDUs are passed skipping the missing ones. The T-PDUs that come
+// too late, after the later ones have been passed, are discarded. The T-PDUs
+// without the sequence number are passed as they are received.
+//
+// This works with the default handler for T-PDU with DisableErrorIndication.
+func (u *UPlaneConn) EnableReordering(itei uint32, window int, timeout time.Duration) {
+	u.mu.Lock()
+	if u.reorderMap == nil {
+		u.reorderMap = map[uint32]*reorderBuffer{}
+	}
+	old, ok := u.reorderMap[itei]
+	u.reorderMap[itei] = newReorderBuffer(u, window, timeout)
+	u.mu.Unlock()
+
+	if ok {
+		old.stop()
+	}
+}
+
+// DisableReordering stops reordering the T-PDUs with the incoming TEID given.
+// The T-PDUs buffered at the moment are passed to ReadFromGTP.
+func (u *UPlaneConn) DisableReordering(itei uint32) {
+	u.mu.Lock()
+	r, ok := u.reorderMap[itei]
+	delete(u.reorderMap, itei)
+	u.mu.Unlock()
+
+	if ok {
+		r.stop()
+	}
+}
+
+// passTPDU passes the T-PDU to ReadFromGTP, reordering it if enabled for the TEID.
+func (u *UPlaneConn) passTPDU(tpdu *tpduSet, hasSeq bool) {
+	u.mu.Lock()
+	r, ok := u.reorderMap[tpdu.teid]
+	u.mu.Unlock()
+
+	if ok && hasSeq {
+		r.push(tpdu)
+		return
+	}
+
+	// wait for the T-PDU passed to u.tpduCh to be read by ReadFromGTP.
+	// if it got stuck for 3 seconds, it discards the T-PDU received.
+	go u.sendTPDU(tpdu)
+}
+
+func (u *UPlaneConn) sendTPDU(tpdu *tpduSet) {
+	select {
+	case u.tpduCh <- tpdu:
+	case <-u.closed():
+	case <-time.After(3 * time.Second):
+	}
+}
+
+// reorderBuffer buffers the T-PDUs that come earlier than the expected one.
+type reorderBuffer struct {
+	mu      sync.Mutex
+	u       *UPlaneConn
+	window  int
+	timeout time.Duration
+
+	started bool
+	next    uint16
+	pending map[uint16]*tpduSet
+	timer   *time.Timer
+
+	// out is the T-PDUs in order to be passed to ReadFromGTP by flush, which is
+	// running while flushing is true.
+	out      []*tpduSet
+	flushing bool
+}
+
+func newReorderBuffer(u *UPlaneConn, window int, timeout time.Duration) *reorderBuffer {
+	if window < 1 {
+		window = 1
+	}
+	return &reorderBuffer{
+		u:       u,
+		window:  window,
+		timeout: timeout,
+		pending: map[uint16]*tpduSet{},
+	}
+}
+
+// seqBefore reports whether a is before b, taking the wraparound into account.
+func seqBefore(a, b uint16) bool {
+	return int16(a-b) < 0
+}
+
+func (r *reorderBuffer) push(tpdu *tpduSet) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prev := r.next
+
+	switch {
+	case !r.started:
+		// wait for the ones with the lower sequence number that come later.
+		r.pending[tpdu.seq] = tpdu
+		if len(r.pending) > r.window {
+			r.skip()
+		}
+	case tpdu.seq == r.next:
+		r.pass(tpdu)
+		r.next++
+		r.drain()
+	case seqBefore(tpdu.seq, r.next):
+		logf("discarding late T-PDU: TEID: %#x, seq: %d, expected: %d", tpdu.teid, tpdu.seq, r.next)
+	default:
+		r.pending[tpdu.seq] = tpdu
+		if len(r.pending) > r.window {
+			r.skip()
+		}
+	}
+
+	// restart the timer for the next missing one if the expected one has come.
+	if r.next != prev {
+		r.stopTimer()
+	}
+	r.startTimer()
+}
+
+// drain passes the buffered T-PDUs as long as they are in sequence.
+func (r *reorderBuffer) drain() {
+	for {
+		tpdu, ok := r.pending[r.next]
+		if !ok {
+			return
+		}
+		delete(r.pending, r.next)
+		r.pass(tpdu)
+		r.next++
+	}
+}
+
+// skip gives up waiting for the missing T-PDUs, and passes the earliest buffered
+// one and the ones in sequence with it.
+func (r *reorderBuffer) skip() {
+	if len(r.pending) == 0 {
+		return
+	}
+
+	seqs := make([]uint16, 0, len(r.pending))
+	for seq := range r.pending {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqBefore(seqs[i], seqs[j]) })
+
+	r.started = true
+	r.next = seqs[0]
+	r.drain()
+}
+
+// startTimer starts the timer to skip the missing T-PDU, if any T-PDU is buffered
+// and the timer is not running.
+func (r *reorderBuffer) startTimer() {
+	if len(r.pending) == 0 {
+		r.stopTimer()
+		return
+	}
+	if r.timer != nil || r.timeout <= 0 {
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(r.timeout, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		// the timer has been stopped or replaced.
+		if r.timer != timer {
+			return
+		}
+		r.timer = nil
+		r.skip()
+		r.startTimer()
+	})
+	r.timer = timer
+}
+
+func (r *reorderBuffer) stopTimer() {
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+}
+
+// pass queues the T-PDU to be passed to ReadFromGTP by flush. r.mu must be held.
+//
+// The T-PDUs are passed from a single goroutine so that they are not reordered again,
+// and without r.mu held so that the ones coming meanwhile are not blocked.
+func (r *reorderBuffer) pass(tpdu *tpduSet) {
+	r.out = append(r.out, tpdu)
+	if !r.flushing {
+		r.flushing = true
+		go r.flush()
+	}
+}
+
+// flush passes the T-PDUs queued by pass to ReadFromGTP in order, until none is left.
+func (r *reorderBuffer) flush() {
+	for {
+		r.mu.Lock()
+		out := r.out
+		r.out = nil
+		if len(out) == 0 {
+			r.flushing = false
+			r.mu.Unlock()
+			return
+		}
+		r.mu.Unlock()
+
+		for _, tpdu := range out {
+			r.u.sendTPDU(tpdu)
+		}
+	}
+}
+
+// stop passes the buffered T-PDUs and stops the buffer.
+func (r *reorderBuffer) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for len(r.pending) > 0 {
+		r.skip()
+	}
+	r.stopTimer()
+}
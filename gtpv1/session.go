@@ -0,0 +1,299 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv1
+
+import (
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
+)
+
+// PDPContext is a PDP context of a subscriber.
+//
+// A secondary PDP context shares the PDP address, APN and the TEID for C-Plane with
+// the primary one it is linked to, and differs in QoS and TFT.
+type PDPContext struct {
+	// NSAPI is the NSAPI that identifies the PDP context, which is 5-15.
+	NSAPI uint8
+	// LinkedNSAPI is the NSAPI of the primary PDP context if this is a secondary
+	// one, and 0 otherwise.
+	LinkedNSAPI uint8
+
+	// IncomingTEID and OutgoingTEID are the TEIDs for U-Plane.
+	IncomingTEID uint32
+	OutgoingTEID uint32
+	// PeerAddress is the address of the peer GSN for U-Plane.
+	PeerAddress net.IP
+
+	QoSProfile []byte
+	TFT        *ie.TrafficFlowTemplateFields
+}
+
+// IsSecondary reports whether the PDP context is a secondary one.
+func (p *PDPContext) IsSecondary() bool {
+	return p.LinkedNSAPI != 0
+}
+
+// NewPDPContextFromCreatePDPContextRequest creates a new PDPContext from the values
+// in CreatePDPContextRequest. The one with Linked NSAPI IE is a secondary PDP context.
+//
+// IncomingTEID is not set, as it is allocated by the receiver.
+func NewPDPContextFromCreatePDPContextRequest(req *message.CreatePDPContextRequest) (*PDPContext, error) {
+	if req.NSAPI == nil {
+		return nil, ErrRequiredIEMissing
+	}
+
+	p := &PDPContext{}
+	var err error
+	if p.NSAPI, err = req.NSAPI.NSAPI(); err != nil {
+		return nil, err
+	}
+	if i := req.LinkedNSAPI; i != nil {
+		if p.LinkedNSAPI, err = i.NSAPI(); err != nil {
+			return nil, err
+		}
+	}
+	if i := req.TEIDDataI; i != nil {
+		if p.OutgoingTEID, err = i.TEID(); err != nil {
+			return nil, err
+		}
+	}
+	if i := req.SGSNAddressForUserTraffic; i != nil {
+		if p.PeerAddress, err = i.IP(); err != nil {
+			return nil, err
+		}
+	}
+	if i := req.QoSProfile; i != nil {
+		if p.QoSProfile, err = i.QoSProfile(); err != nil {
+			return nil, err
+		}
+	}
+	if i := req.TFT; i != nil {
+		if p.TFT, err = i.TrafficFlowTemplate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// Session is a set of PDP contexts of a subscriber that share the same PDP address
+// and APN, which consists of a primary PDP context and the secondary ones linked to it.
+//
+// The fields should not be modified after the Session is added to SessionTable.
+type Session struct {
+	IMSI       string
+	APN        string
+	PDPAddress net.IP
+
+	// IncomingTEIDCPlane and OutgoingTEIDCPlane are the TEIDs for C-Plane, which
+	// are shared among all the PDP contexts in the Session.
+	IncomingTEIDCPlane uint32
+	OutgoingTEIDCPlane uint32
+
+	mu          sync.Mutex
+	pdpContexts map[uint8]*PDPContext
+}
+
+// NewSession creates a new Session with no PDP contexts.
+func NewSession(imsi, apn string) *Session {
+	return &Session{
+		IMSI:        imsi,
+		APN:         apn,
+		pdpContexts: map[uint8]*PDPContext{},
+	}
+}
+
+// AddPDPContext adds a PDP context to the Session.
+//
+// A primary PDP context can be added only if the Session does not have one yet,
+// and a secondary one only if the primary one with its LinkedNSAPI exists.
+func (s *Session) AddPDPContext(p *PDPContext) error {
+	if p.NSAPI < 5 || p.NSAPI > 15 {
+		return ErrInvalidNSAPI
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.pdpContexts[p.NSAPI]; ok {
+		return ErrPDPContextAlreadyExists
+	}
+
+	if p.IsSecondary() {
+		linked, ok := s.pdpContexts[p.LinkedNSAPI]
+		if !ok || linked.IsSecondary() {
+			return ErrLinkedPDPContextNotFound
+		}
+	} else if s.primary() != nil {
+		return ErrPDPContextAlreadyExists
+	}
+
+	s.pdpContexts[p.NSAPI] = p
+	return nil
+}
+
+// RemovePDPContext removes the PDP context with the NSAPI from the Session, and
+// returns the ones removed. Removing the primary PDP context removes all the
+// secondary ones linked to it as well.
+func (s *Session) RemovePDPContext(nsapi uint8) []*PDPContext {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.pdpContexts[nsapi]
+	if !ok {
+		return nil
+	}
+	delete(s.pdpContexts, nsapi)
+	removed := []*PDPContext{p}
+
+	if !p.IsSecondary() {
+		for n, sp := range s.pdpContexts {
+			if sp.LinkedNSAPI == nsapi {
+				delete(s.pdpContexts, n)
+				removed = append(removed, sp)
+			}
+		}
+		sortPDPContexts(removed[1:])
+	}
+	return removed
+}
+
+// PDPContext returns the PDP context with the NSAPI given.
+func (s *Session) PDPContext(nsapi uint8) (*PDPContext, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.pdpContexts[nsapi]
+	return p, ok
+}
+
+// PDPContexts returns all the PDP contexts in the Session in the order of NSAPI.
+func (s *Session) PDPContexts() []*PDPContext {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ps := make([]*PDPContext, 0, len(s.pdpContexts))
+	for _, p := range s.pdpContexts {
+		ps = append(ps, p)
+	}
+	sortPDPContexts(ps)
+	return ps
+}
+
+// PrimaryPDPContext returns the primary PDP context in the Session.
+func (s *Session) PrimaryPDPContext() (*PDPContext, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.primary()
+	return p, p != nil
+}
+
+// SecondaryPDPContexts returns the secondary PDP contexts in the Session in the
+// order of NSAPI.
+func (s *Session) SecondaryPDPContexts() []*PDPContext {
+	var ps []*PDPContext
+	for _, p := range s.PDPContexts() {
+		if p.IsSecondary() {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+func (s *Session) primary() *PDPContext {
+	for _, p := range s.pdpContexts {
+		if !p.IsSecondary() {
+			return p
+		}
+	}
+	return nil
+}
+
+func sortPDPContexts(ps []*PDPContext) {
+	sort.Slice(ps, func(i, j int) bool {
+		return ps[i].NSAPI < ps[j].NSAPI
+	})
+}
+
+// SessionTable is a set of Sessions, which can be looked up by IMSI and APN, or by
+// the incoming TEID for C-Plane.
+//
+// The latter is useful to find the Session for the Create PDP Context Request for
+// a secondary PDP context, which has the TEID in the header instead of APN.
+type SessionTable struct {
+	mu     sync.Mutex
+	byKey  map[string]*Session
+	byTEID map[uint32]*Session
+}
+
+// NewSessionTable creates a new SessionTable.
+func NewSessionTable() *SessionTable {
+	return &SessionTable{
+		byKey:  map[string]*Session{},
+		byTEID: map[uint32]*Session{},
+	}
+}
+
+// Add adds a Session to the table, replacing the one with the same IMSI and APN.
+func (t *SessionTable) Add(s *Session) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if old, ok := t.byKey[sessionKey(s.IMSI, s.APN)]; ok {
+		delete(t.byTEID, old.IncomingTEIDCPlane)
+	}
+	t.byKey[sessionKey(s.IMSI, s.APN)] = s
+	if s.IncomingTEIDCPlane != 0 {
+		t.byTEID[s.IncomingTEIDCPlane] = s
+	}
+}
+
+// Remove removes a Session from the table.
+func (t *SessionTable) Remove(s *Session) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.byKey[sessionKey(s.IMSI, s.APN)] == s {
+		delete(t.byKey, sessionKey(s.IMSI, s.APN))
+	}
+	if t.byTEID[s.IncomingTEIDCPlane] == s {
+		delete(t.byTEID, s.IncomingTEIDCPlane)
+	}
+}
+
+// Get returns the Session with the IMSI and APN given.
+func (t *SessionTable) Get(imsi, apn string) (*Session, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.byKey[sessionKey(imsi, apn)]
+	return s, ok
+}
+
+// GetByIncomingTEIDCPlane returns the Session with the incoming TEID for C-Plane given.
+func (t *SessionTable) GetByIncomingTEIDCPlane(teid uint32) (*Session, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.byTEID[teid]
+	return s, ok
+}
+
+// Len returns the number of Sessions in the table.
+func (t *SessionTable) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.byKey)
+}
+
+func sessionKey(imsi, apn string) string {
+	return imsi + "/" + apn
+}
@@ -0,0 +1,141 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv1_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	v1 "github.com/wmnsk/go-gtp/gtpv1"
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
+)
+
+func TestSecondaryPDPContext(t *testing.T) {
+	tft := &ie.TrafficFlowTemplateFields{
+		OperationCode: ie.TFTOpCreateNewTFT,
+		PacketFilters: []*ie.TFTPacketFilter{
+			{Identifier: 1, Direction: ie.TFTPFBidirectional, Precedence: 0x10, Contents: []byte{0x30, 0x11}},
+		},
+	}
+
+	// the requests are serialized and parsed again to see NSAPI and Linked NSAPI
+	// are distinguished by their order.
+	parse := func(req *message.CreatePDPContextRequest) *v1.PDPContext {
+		t.Helper()
+		b, err := req.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := message.ParseCreatePDPContextRequest(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := v1.NewPDPContextFromCreatePDPContextRequest(parsed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	primary := parse(message.NewCreatePDPContextRequest(
+		0, 1,
+		ie.NewIMSI("123451234567890"),
+		ie.NewTEIDDataI(0x11111111),
+		ie.NewTEIDCPlane(0x22222222),
+		ie.NewNSAPI(5),
+		ie.NewAccessPointName("some.apn.example"),
+		ie.NewGSNAddress("1.1.1.1"),
+		ie.NewGSNAddress("2.2.2.2"),
+		ie.NewQoSProfile([]byte{0x01, 0x02}),
+	))
+	secondary := parse(message.NewCreatePDPContextRequest(
+		0x33333333, 2,
+		ie.NewTEIDDataI(0x44444444),
+		ie.NewNSAPI(6),
+		ie.NewNSAPI(5),
+		ie.NewGSNAddress("1.1.1.1"),
+		ie.NewGSNAddress("2.2.2.2"),
+		ie.NewQoSProfile([]byte{0x03, 0x04}),
+		ie.NewTrafficFlowTemplateFromFields(tft),
+	))
+
+	if diff := cmp.Diff(primary, &v1.PDPContext{
+		NSAPI:        5,
+		OutgoingTEID: 0x11111111,
+		PeerAddress:  net.ParseIP("2.2.2.2").To4(),
+		QoSProfile:   []byte{0x01, 0x02},
+	}); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff(secondary, &v1.PDPContext{
+		NSAPI:        6,
+		LinkedNSAPI:  5,
+		OutgoingTEID: 0x44444444,
+		PeerAddress:  net.ParseIP("2.2.2.2").To4(),
+		QoSProfile:   []byte{0x03, 0x04},
+		TFT:          tft,
+	}); diff != "" {
+		t.Error(diff)
+	}
+
+	sess := v1.NewSession("123451234567890", "some.apn.example")
+	sess.IncomingTEIDCPlane = 0x33333333
+
+	table := v1.NewSessionTable()
+	table.Add(sess)
+
+	// the secondary one cannot be added before the primary one.
+	if err := sess.AddPDPContext(secondary); err != v1.ErrLinkedPDPContextNotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := sess.AddPDPContext(primary); err != nil {
+		t.Fatal(err)
+	}
+
+	// the Session for the secondary one is found with the TEID in the header.
+	found, ok := table.GetByIncomingTEIDCPlane(0x33333333)
+	if !ok || found != sess {
+		t.Fatal("Session not found by TEID")
+	}
+	if err := found.AddPDPContext(secondary); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sess.AddPDPContext(&v1.PDPContext{NSAPI: 6, LinkedNSAPI: 5}); err != v1.ErrPDPContextAlreadyExists {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := sess.AddPDPContext(&v1.PDPContext{NSAPI: 7}); err != v1.ErrPDPContextAlreadyExists {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := sess.AddPDPContext(&v1.PDPContext{NSAPI: 4}); err != v1.ErrInvalidNSAPI {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if got, ok := sess.PrimaryPDPContext(); !ok || got != primary {
+		t.Error("wrong primary PDP context")
+	}
+	if diff := cmp.Diff(sess.SecondaryPDPContexts(), []*v1.PDPContext{secondary}); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff(sess.PDPContexts(), []*v1.PDPContext{primary, secondary}); diff != "" {
+		t.Error(diff)
+	}
+
+	// removing the primary one removes the secondary one as well.
+	if diff := cmp.Diff(sess.RemovePDPContext(5), []*v1.PDPContext{primary, secondary}); diff != "" {
+		t.Error(diff)
+	}
+	if got := len(sess.PDPContexts()); got != 0 {
+		t.Errorf("PDP contexts left: %d", got)
+	}
+
+	table.Remove(sess)
+	if _, ok := table.Get("123451234567890", "some.apn.example"); ok {
+		t.Error("Session not removed")
+	}
+}
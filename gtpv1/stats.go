@@ -0,0 +1,156 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv1
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+type tunnelCounters struct {
+	// accessed atomically. keep them at the top to be 64-bit aligned.
+	packetsIn, bytesIn, dropped, packetsOut, bytesOut uint64
+	lastActivity                                      int64
+}
+
+// TunnelStats is the statistics of the T-PDUs with a TEID on UPlaneConn.
+type TunnelStats struct {
+	// PacketsIn and BytesIn are the number of T-PDUs received with the TEID and
+	// the total bytes of them(including GTP-U header). Dropped is the number of
+	// the ones discarded among them, e.g., responded with Error Indication as the
+	// TEID is unknown.
+	PacketsIn, BytesIn, Dropped uint64
+
+	// PacketsOut and BytesOut are the number of T-PDUs sent with the TEID and the
+	// total bytes of them(including GTP-U header).
+	PacketsOut, BytesOut uint64
+
+	// LastActivity is the time when the last T-PDU is received or sent with the TEID.
+	LastActivity time.Time
+}
+
+func (c *tunnelCounters) stats() *TunnelStats {
+	return &TunnelStats{
+		PacketsIn:    atomic.LoadUint64(&c.packetsIn),
+		BytesIn:      atomic.LoadUint64(&c.bytesIn),
+		Dropped:      atomic.LoadUint64(&c.dropped),
+		PacketsOut:   atomic.LoadUint64(&c.packetsOut),
+		BytesOut:     atomic.LoadUint64(&c.bytesOut),
+		LastActivity: time.Unix(0, atomic.LoadInt64(&c.lastActivity)),
+	}
+}
+
+// EnableTunnelStats starts counting the T-PDUs received and sent on UPlaneConn
+// for each TEID, which can be retrieved with TunnelStats or AllTunnelStats.
+//
+// The T-PDUs received are counted with the TEID in the header, and the ones sent
+// are counted with the outgoing TEID, including the ones forwarded with RelayTo.
+// Note that the T-PDUs handled by Kernel GTP-U are not counted.
+//
+// As the statistics are kept for any TEID seen, including the unknown ones, they
+// should be deleted with DeleteTunnelStats when no longer needed. They are deleted
+// automatically when the tunnel is deleted with CloseRelay, DelTunnelByITEI or
+// DelTunnelByMSAddress, or the handler is removed with RemoveTPDUHandler.
+func (u *UPlaneConn) EnableTunnelStats() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.statsMap == nil {
+		u.statsMap = map[uint32]*tunnelCounters{}
+	}
+	atomic.StoreInt32(&u.statsEnabled, 1)
+}
+
+// DisableTunnelStats stops counting the T-PDUs for each TEID, and deletes all the
+// statistics.
+func (u *UPlaneConn) DisableTunnelStats() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	atomic.StoreInt32(&u.statsEnabled, 0)
+	u.statsMap = nil
+}
+
+// TunnelStats returns the statistics of the T-PDUs with the TEID given.
+func (u *UPlaneConn) TunnelStats(teid uint32) (*TunnelStats, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	c, ok := u.statsMap[teid]
+	if !ok {
+		return nil, false
+	}
+	return c.stats(), true
+}
+
+// AllTunnelStats returns the statistics of all the TEIDs on UPlaneConn, with the
+// TEIDs as keys.
+func (u *UPlaneConn) AllTunnelStats() map[uint32]*TunnelStats {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	stats := make(map[uint32]*TunnelStats, len(u.statsMap))
+	for teid, c := range u.statsMap {
+		stats[teid] = c.stats()
+	}
+	return stats
+}
+
+// DeleteTunnelStats deletes the statistics of the TEID given.
+func (u *UPlaneConn) DeleteTunnelStats(teid uint32) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	delete(u.statsMap, teid)
+}
+
+// MalformedPackets returns the number of packets received on UPlaneConn that
+// could not be parsed as GTPv1-U message.
+func (u *UPlaneConn) MalformedPackets() uint64 {
+	return atomic.LoadUint64(&u.malformed)
+}
+
+// tunnelCounters returns the counters for the TEID, or nil if the statistics are
+// not enabled.
+func (u *UPlaneConn) tunnelCounters(teid uint32) *tunnelCounters {
+	if atomic.LoadInt32(&u.statsEnabled) == 0 {
+		return nil
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.statsMap == nil {
+		return nil
+	}
+	c, ok := u.statsMap[teid]
+	if !ok {
+		c = &tunnelCounters{}
+		u.statsMap[teid] = c
+	}
+	return c
+}
+
+func (u *UPlaneConn) countIn(teid uint32, n int) {
+	if c := u.tunnelCounters(teid); c != nil {
+		atomic.AddUint64(&c.packetsIn, 1)
+		atomic.AddUint64(&c.bytesIn, uint64(n))
+		atomic.StoreInt64(&c.lastActivity, time.Now().UnixNano())
+	}
+}
+
+func (u *UPlaneConn) countOut(teid uint32, n int) {
+	if c := u.tunnelCounters(teid); c != nil {
+		atomic.AddUint64(&c.packetsOut, 1)
+		atomic.AddUint64(&c.bytesOut, uint64(n))
+		atomic.StoreInt64(&c.lastActivity, time.Now().UnixNano())
+	}
+}
+
+func (u *UPlaneConn) countDropped(teid uint32) {
+	if c := u.tunnelCounters(teid); c != nil {
+		atomic.AddUint64(&c.dropped, 1)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv1_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	v1 "github.com/wmnsk/go-gtp/gtpv1"
+)
+
+func TestTunnelStats(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srvPC, err := net.ListenPacket("udp", "127.0.0.43:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srvConn := v1.NewUPlaneConnWithPacketConn(srvPC)
+	defer srvConn.Close()
+	srvConn.EnableTunnelStats()
+	if err := srvConn.AddTPDUHandler(0x11111111, func(u *v1.UPlaneConn, senderAddr net.Addr, teid uint32, payload *v1.Buffer) {
+		payload.Release()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := srvConn.ListenAndServe(ctx); err != nil {
+			return
+		}
+	}()
+
+	cliPC, err := net.ListenPacket("udp", "127.0.0.44:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cliConn := v1.NewUPlaneConnWithPacketConn(cliPC)
+	defer cliConn.Close()
+	cliConn.DisableErrorIndication()
+	go func() {
+		if err := cliConn.ListenAndServe(ctx); err != nil {
+			return
+		}
+	}()
+
+	payload := []byte{0xde, 0xad, 0xbe, 0xef}
+	for i := 0; i < 3; i++ {
+		if _, err := cliConn.WriteToGTP(0x11111111, payload, srvConn.LocalAddr()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// unknown TEID, which is responded with Error Indication.
+	if _, err := cliConn.WriteToGTP(0x22222222, payload, srvConn.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cliConn.WriteTo([]byte{0x30, 0xff, 0x00}, srvConn.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+	before := time.Now()
+	if _, err := srvConn.WriteToGTP(0x33333333, payload, cliConn.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[uint32]*v1.TunnelStats{
+		0x11111111: {PacketsIn: 3, BytesIn: 36},
+		0x22222222: {PacketsIn: 1, BytesIn: 12, Dropped: 1},
+		0x33333333: {PacketsOut: 1, BytesOut: 12},
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		got := srvConn.AllTunnelStats()
+		ok := len(got) == len(want) && srvConn.MalformedPackets() == 1
+		for teid, w := range want {
+			g, found := got[teid]
+			if !found || g.PacketsIn != w.PacketsIn || g.BytesIn != w.BytesIn || g.Dropped != w.Dropped ||
+				g.PacketsOut != w.PacketsOut || g.BytesOut != w.BytesOut {
+				ok = false
+			}
+		}
+		if ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			for teid, g := range got {
+				t.Logf("%#08x: %+v", teid, g)
+			}
+			t.Fatalf("unexpected stats. malformed: %d", srvConn.MalformedPackets())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if s, ok := srvConn.TunnelStats(0x33333333); !ok || s.LastActivity.Before(before) {
+		t.Errorf("wrong last activity: %v", s)
+	}
+
+	// the statistics are deleted with the handler.
+	srvConn.RemoveTPDUHandler(0x11111111)
+	if _, ok := srvConn.TunnelStats(0x11111111); ok {
+		t.Error("stats not deleted")
+	}
+}
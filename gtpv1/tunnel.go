@@ -5,16 +5,55 @@
 package gtpv1
 
 import (
+	"encoding/binary"
 	"errors"
 	"net"
+	"sync/atomic"
 )
 
 type peer struct {
+	// accessed atomically. keep them at the top to be 64-bit aligned.
+	packets, bytes, errors uint64
+
 	teid    uint32
 	addr    net.Addr
 	srcConn *UPlaneConn
 }
 
+// forward sends the T-PDU to the peer, rewriting the TEID in the header.
+func (p *peer) forward(b []byte) error {
+	binary.BigEndian.PutUint32(b[4:8], p.teid)
+	if _, err := p.srcConn.writeTPDU(p.teid, b, p.addr); err != nil {
+		atomic.AddUint64(&p.errors, 1)
+		return err
+	}
+
+	atomic.AddUint64(&p.packets, 1)
+	atomic.AddUint64(&p.bytes, uint64(len(b)))
+	return nil
+}
+
+// RelayStats is the statistics of the T-PDUs relayed with RelayTo.
+type RelayStats struct {
+	TEIDOut  uint32
+	PeerAddr net.Addr
+
+	// Packets and Bytes are the number of T-PDUs and the total bytes of them
+	// (including GTP-U header) sent to the peer, and Errors is the number of
+	// T-PDUs failed to be sent.
+	Packets, Bytes, Errors uint64
+}
+
+func (p *peer) stats() *RelayStats {
+	return &RelayStats{
+		TEIDOut:  p.teid,
+		PeerAddr: p.addr,
+		Packets:  atomic.LoadUint64(&p.packets),
+		Bytes:    atomic.LoadUint64(&p.bytes),
+		Errors:   atomic.LoadUint64(&p.errors),
+	}
+}
+
 // RelayTo relays T-PDU type of packet to peer node(specified by raddr) from the UPlaneConn given.
 //
 // By using this, owner of UPlaneConn won't be able to Read and Write the packets that has teidIn.
@@ -24,11 +63,13 @@ func (u *UPlaneConn) RelayTo(c *UPlaneConn, teidIn, teidOut uint32, raddr net.Ad
 	}
 
 	u.mu.Lock()
-	defer u.mu.Unlock()
 	if u.relayMap == nil {
 		u.relayMap = map[uint32]*peer{}
 	}
 	u.relayMap[teidIn] = &peer{teid: teidOut, addr: raddr, srcConn: c}
+	u.mu.Unlock()
+
+	c.addRelaySource(teidOut, raddr, u, teidIn)
 	return nil
 }
 
@@ -39,9 +80,102 @@ func (u *UPlaneConn) CloseRelay(teidIn uint32) error {
 	}
 
 	u.mu.Lock()
+	p, ok := u.relayMap[teidIn]
 	delete(u.relayMap, teidIn)
 	u.mu.Unlock()
 
-	u.iteiMap.delete(teidIn)
+	if ok {
+		p.srcConn.delRelaySource(p.teid, p.addr)
+	}
+
+	u.releaseTEID(teidIn)
 	return nil
 }
+
+// RelayStats returns the statistics of the relay with the incoming TEID given.
+// The counters are reset when the relay is replaced by another RelayTo.
+func (u *UPlaneConn) RelayStats(teidIn uint32) (*RelayStats, bool) {
+	p, ok := u.relayPeer(teidIn)
+	if !ok {
+		return nil, false
+	}
+	return p.stats(), true
+}
+
+// AllRelayStats returns the statistics of all the relays on UPlaneConn, with the
+// incoming TEIDs as keys.
+func (u *UPlaneConn) AllRelayStats() map[uint32]*RelayStats {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	stats := make(map[uint32]*RelayStats, len(u.relayMap))
+	for teidIn, p := range u.relayMap {
+		stats[teidIn] = p.stats()
+	}
+	return stats
+}
+
+func (u *UPlaneConn) relayPeer(teidIn uint32) (*peer, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	p, ok := u.relayMap[teidIn]
+	return p, ok
+}
+
+// relaySource is the UPlaneConn and its incoming TEID that the T-PDUs sent with
+// RelayTo come from. This is used to find the tunnel affected by Error Indication,
+// which is received on the UPlaneConn that sends the T-PDUs.
+type relaySource struct {
+	conn   *UPlaneConn
+	teidIn uint32
+}
+
+type relayKey struct {
+	teid uint32
+	ip   string
+}
+
+func newRelayKey(teid uint32, addr net.Addr) relayKey {
+	ip, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		ip = addr.String()
+	}
+	return relayKey{teid: teid, ip: ip}
+}
+
+func (u *UPlaneConn) addRelaySource(teidOut uint32, raddr net.Addr, src *UPlaneConn, teidIn uint32) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.relaySources == nil {
+		u.relaySources = map[relayKey]*relaySource{}
+	}
+	u.relaySources[newRelayKey(teidOut, raddr)] = &relaySource{conn: src, teidIn: teidIn}
+}
+
+func (u *UPlaneConn) delRelaySource(teidOut uint32, raddr net.Addr) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	delete(u.relaySources, newRelayKey(teidOut, raddr))
+}
+
+// tunnelByOTEI finds the tunnel that sends T-PDUs with the outgoing TEID to the
+// peer given, from the ones relayed with RelayTo or, if enabled, Kernel GTP-U.
+// It returns the incoming TEID of the tunnel and the function to delete it.
+func (u *UPlaneConn) tunnelByOTEI(otei uint32, peerIP string) (uint32, func() error, bool) {
+	u.mu.Lock()
+	src, ok := u.relaySources[relayKey{teid: otei, ip: peerIP}]
+	kernGTPEnabled := u.kernGTPEnabled
+	u.mu.Unlock()
+
+	if ok {
+		return src.teidIn, func() error { return src.conn.CloseRelay(src.teidIn) }, true
+	}
+
+	if kernGTPEnabled {
+		return u.kernelTunnelByOTEI(otei, net.ParseIP(peerIP))
+	}
+	return 0, nil, false
+}
@@ -45,7 +45,11 @@ func (u *UPlaneConn) EnableKernelGTP(devname string, role Role) error {
 		}
 	}
 
-	f, err := u.pktConn.(*net.UDPConn).File()
+	udpConn, ok := u.pktConn.(*net.UDPConn)
+	if !ok {
+		return errors.Wrapf(ErrInvalidConnection, "Kernel GTP-U requires *net.UDPConn, got %T", u.pktConn)
+	}
+	f, err := udpConn.File()
 	if err != nil {
 		return errors.Wrapf(err, "failed to retrieve file from conn")
 	}
@@ -79,6 +83,39 @@ func (u *UPlaneConn) EnableKernelGTP(devname string, role Role) error {
 	return nil
 }
 
+// DisableKernelGTP deletes the GTP device created by EnableKernelGTP, with all the
+// tunnels added to it.
+//
+// The userland tunnels removed by EnableKernelGTP are not restored. After disabled,
+// the T-PDUs are handled in userland again.
+func (u *UPlaneConn) DisableKernelGTP() error {
+	if !u.kernGTPEnabled {
+		return nil
+	}
+
+	if err := netlink.LinkDel(u.GTPLink); err != nil {
+		return errors.Wrapf(err, "failed to delete device: %s", u.GTPLink.Name)
+	}
+	u.kernGTPEnabled = false
+	return nil
+}
+
+// KernelTunnels returns the tunnels in Linux Kernel GTP-U.
+//
+// Note that the tunnels on all the GTP devices in the network namespace are
+// returned, as the kernel does not tell which device each tunnel belongs to.
+func (u *UPlaneConn) KernelTunnels() ([]*netlink.PDP, error) {
+	if !u.kernGTPEnabled {
+		return nil, errors.New("cannot call KernelTunnels when not using Kernel GTP-U")
+	}
+
+	pdps, err := netlink.GTPPDPList()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list tunnels")
+	}
+	return pdps, nil
+}
+
 // AddTunnel adds a GTP-U tunnel with Linux Kernel GTP-U via netlink.
 func (u *UPlaneConn) AddTunnel(peerIP, msIP net.IP, otei, itei uint32) error {
 	if !u.kernGTPEnabled {
@@ -133,7 +170,7 @@ func (u *UPlaneConn) DelTunnelByITEI(itei uint32) error {
 		return errors.Wrapf(err, "failed to delete tunnel for %s", pdp)
 	}
 
-	u.iteiMap.delete(itei)
+	u.releaseTEID(itei)
 	return nil
 }
 
@@ -153,6 +190,23 @@ func (u *UPlaneConn) DelTunnelByMSAddress(msIP net.IP) error {
 		return errors.Wrapf(err, "failed to delete tunnel for %s", pdp)
 	}
 
-	u.iteiMap.delete(itei)
+	u.releaseTEID(itei)
 	return nil
 }
+
+// kernelTunnelByOTEI returns the incoming TEID of the Linux Kernel GTP-U tunnel
+// that has the outgoing TEID and the peer's IP given, and the function to delete it.
+func (u *UPlaneConn) kernelTunnelByOTEI(otei uint32, peerIP net.IP) (uint32, func() error, bool) {
+	pdps, err := netlink.GTPPDPList()
+	if err != nil {
+		return 0, nil, false
+	}
+
+	for _, pdp := range pdps {
+		if pdp.OTEI == otei && pdp.PeerAddress.Equal(peerIP) {
+			itei := pdp.ITEI
+			return itei, func() error { return u.DelTunnelByITEI(itei) }, true
+		}
+	}
+	return 0, nil, false
+}
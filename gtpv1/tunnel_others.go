@@ -0,0 +1,15 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+//go:build !linux
+// +build !linux
+
+package gtpv1
+
+import "net"
+
+// kernelTunnelByOTEI always fails, as Kernel GTP-U is available only on Linux.
+func (u *UPlaneConn) kernelTunnelByOTEI(otei uint32, peerIP net.IP) (uint32, func() error, bool) {
+	return 0, nil, false
+}
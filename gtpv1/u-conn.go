@@ -11,10 +11,12 @@ import (
 	"io"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/vishvananda/netlink"
+	"github.com/wmnsk/go-gtp/gtpcap"
 	"github.com/wmnsk/go-gtp/gtpv1/ie"
 	"github.com/wmnsk/go-gtp/gtpv1/message"
 	v2ies "github.com/wmnsk/go-gtp/gtpv2/ie"
@@ -29,6 +31,9 @@ type tpduSet struct {
 
 // UPlaneConn represents a U-Plane Connection of GTPv1.
 type UPlaneConn struct {
+	// accessed atomically. keep it at the top to be 64-bit aligned.
+	malformed uint64
+
 	mu      sync.Mutex
 	laddr   net.Addr
 	pktConn net.PacketConn
@@ -38,11 +43,38 @@ type UPlaneConn struct {
 	tpduCh  chan *tpduSet
 	closeCh chan struct{}
 
-	relayMap map[uint32]*peer
+	relayMap     map[uint32]*peer
+	relaySources map[relayKey]*relaySource
+
+	egressSeqMap map[uint32]uint16
+	statsMap     map[uint32]*tunnelCounters
+	statsEnabled int32
+	dscpMap      map[uint32]*dscpMarking
+	reorderMap   map[uint32]*reorderBuffer
+
+	endMarkerFn    EndMarkerFunc
+	errIndFn       ErrorIndicationFunc
+	errIndTeardown bool
+	tpduFn         TPDUHandlerFunc
+	tpduFnMap      map[uint32]TPDUHandlerFunc
+
+	// captureHook is called with the packets sent and received.
+	captureHook gtpcap.Hook
+
+	// extension header types listed in Supported Extension Headers Notification,
+	// which is sent automatically if extHdrNotify is true.
+	extHdrNotify bool
+	extHdrTypes  []uint8
+
+	// for batched I/O with recvmmsg/sendmmsg
+	batchSize int
+	batchConn batchConn
+	batchIsV4 bool
 
 	// for Linux kernel GTP with netlink
 	kernGTPEnabled bool
 	errIndEnabled  bool
+	errIndUDPPort  bool
 	GTPLink        *netlink.GTP
 }
 
@@ -50,7 +82,7 @@ type UPlaneConn struct {
 func NewUPlaneConn(laddr net.Addr) *UPlaneConn {
 	return &UPlaneConn{
 		mu:            sync.Mutex{},
-		msgHandlerMap: defaultHandlerMap,
+		msgHandlerMap: newDefaultMsgHandlerMap(),
 		iteiMap:       newiteiMap(),
 		laddr:         laddr,
 
@@ -61,11 +93,25 @@ func NewUPlaneConn(laddr net.Addr) *UPlaneConn {
 	}
 }
 
+// NewUPlaneConnWithPacketConn creates a new UPlaneConn over the net.PacketConn given,
+// instead of opening a UDP socket by itself. The local address is taken from pc.
+//
+// This is useful to use the transport other than the UDP socket provided by the
+// standard library(e.g., userspace network stacks), or the socket prepared with
+// some custom options.
+//
+// Note that EnableKernelGTP works only if pc is *net.UDPConn.
+func NewUPlaneConnWithPacketConn(pc net.PacketConn) *UPlaneConn {
+	u := NewUPlaneConn(pc.LocalAddr())
+	u.pktConn = pc
+	return u
+}
+
 // DialUPlane sends Echo Request to raddr to check if the endpoint is alive and returns UPlaneConn.
 func DialUPlane(ctx context.Context, laddr, raddr net.Addr) (*UPlaneConn, error) {
 	u := &UPlaneConn{
 		mu:            sync.Mutex{},
-		msgHandlerMap: defaultHandlerMap,
+		msgHandlerMap: newDefaultMsgHandlerMap(),
 		iteiMap:       newiteiMap(),
 		laddr:         laddr,
 
@@ -154,7 +200,12 @@ func (u *UPlaneConn) listenAndServe(ctx context.Context) error {
 }
 
 func (u *UPlaneConn) serve(ctx context.Context) error {
-	buf := make([]byte, 1500)
+	if bc, _, size := u.batchIO(); bc != nil {
+		return u.serveBatch(ctx, bc, size)
+	}
+
+	b := GetBuffer()
+	defer func() { b.Release() }()
 	for {
 		select {
 		case <-ctx.Done():
@@ -165,7 +216,7 @@ func (u *UPlaneConn) serve(ctx context.Context) error {
 			// do nothing and go forward.
 		}
 
-		n, raddr, err := u.pktConn.ReadFrom(buf)
+		n, raddr, err := u.pktConn.ReadFrom(b.readSpace())
 		if err != nil {
 			if err == io.EOF {
 				return nil
@@ -173,49 +224,66 @@ func (u *UPlaneConn) serve(ctx context.Context) error {
 			return errors.Errorf("error reading from UPlaneConn %s: %s", u.LocalAddr(), err)
 		}
 
-		// just forward T-PDU instead of passing it to reader if relayer is
-		// configured and the message type is T-PDU.
-		if len(u.relayMap) != 0 && buf[1] == message.MsgTypeTPDU {
-			// ignore if the packet size is smaller than minimum header size
-			if n < 11 {
-				continue
-			}
+		if u.handlePacket(b, n, raddr) {
+			b = GetBuffer()
+		}
+	}
+}
 
-			u.mu.Lock()
-			peer, ok := u.relayMap[binary.BigEndian.Uint32(buf[4:8])]
-			u.mu.Unlock()
-			if !ok { // pass message to handler if TEID is unknown
-				msg, err := message.Parse(buf[:n])
-				if err != nil {
-					continue
-				}
-
-				if err := u.handleMessage(raddr, msg); err != nil {
-					// should not stop serving with this error
-					logf("error handling message on UPlaneConn %s: %s", u.LocalAddr(), err)
-				}
-				continue
-			}
+// handlePacket relays or handles a packet of length n read into b. It returns true
+// if b is taken by the handler set by SetTPDUHandler, otherwise b can be reused.
+func (u *UPlaneConn) handlePacket(b *Buffer, n int, raddr net.Addr) bool {
+	buf := b.readSpace()[:n]
+	u.capture(buf, raddr, u.LocalAddr())
 
+	isTPDU := len(buf) >= 8 && buf[1] == message.MsgTypeTPDU
+	if isTPDU {
+		u.countIn(binary.BigEndian.Uint32(buf[4:8]), n)
+	}
+
+	if u.discardUnsupportedExtensionHeaders(buf, raddr) {
+		if isTPDU {
+			u.countDropped(binary.BigEndian.Uint32(buf[4:8]))
+		}
+		return false
+	}
+
+	// just forward T-PDU instead of passing it to reader if relayer is
+	// configured for the TEID. T-PDUs with unknown TEID are passed to handler.
+	if isTPDU {
+		if peer, ok := u.relayPeer(binary.BigEndian.Uint32(buf[4:8])); ok {
 			// just use original packet not to get it slow.
-			binary.BigEndian.PutUint32(buf[4:8], peer.teid)
-			if _, err := peer.srcConn.WriteTo(buf[:n], peer.addr); err != nil {
+			if err := peer.forward(buf); err != nil {
 				// should not stop serving with this error
 				logf("error sending on UPlaneConn %s: %s", u.LocalAddr(), err)
 			}
-			continue
+			return false
 		}
 
-		msg, err := message.Parse(buf[:n])
-		if err != nil {
-			continue
+		if u.borrowTPDU(b, n, raddr) {
+			return true
 		}
+	}
 
-		if err := u.handleMessage(raddr, msg); err != nil {
-			// should not stop serving with this error
-			logf("error handling message on UPlaneConn %s: %s", u.LocalAddr(), err)
-		}
+	msg, err := message.Parse(copyPacket(buf))
+	if err != nil {
+		atomic.AddUint64(&u.malformed, 1)
+		return false
 	}
+
+	if err := u.handleMessage(raddr, msg); err != nil {
+		// should not stop serving with this error
+		logf("error handling message on UPlaneConn %s: %s", u.LocalAddr(), err)
+	}
+	return false
+}
+
+// copyPacket copies the packet read, as the message parsed from it is handled in
+// another goroutine while the buffer is reused for the next one.
+func copyPacket(b []byte) []byte {
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
 }
 
 // ReadFrom reads a packet from the connection,
@@ -261,17 +329,64 @@ func (u *UPlaneConn) ReadFromGTP(p []byte) (n int, addr net.Addr, teid uint32, e
 // see SetDeadline and SetWriteDeadline.
 // On packet-oriented connections, write timeouts are rare.
 func (u *UPlaneConn) WriteTo(p []byte, addr net.Addr) (n int, err error) {
-	return u.pktConn.WriteTo(p, addr)
+	n, err = u.pktConn.WriteTo(p, addr)
+	if err == nil {
+		u.capture(p, u.LocalAddr(), addr)
+	}
+	return n, err
 }
 
 // WriteToGTP writes a packet with TEID and payload to addr.
 func (u *UPlaneConn) WriteToGTP(teid uint32, p []byte, addr net.Addr) (n int, err error) {
-	b, err := Encapsulate(teid, p).Marshal()
+	b, err := u.encapsulate(teid, p).Marshal()
+	if err != nil {
+		return
+	}
+
+	if _, err = u.writeTPDU(teid, b, addr); err != nil {
+		return
+	}
+	return len(b), nil
+}
+
+// WriteToGTPWithExtensionHeaders writes a packet with TEID, extension headers and
+// payload to addr.
+//
+// This is useful to send the T-PDU with the PDU Session Container on N3/N9, e.g.,
+//
+//	e, _ := message.NewDLPDUSessionInformation(qfi, false).ToExtensionHeader()
+//	u.WriteToGTPWithExtensionHeaders(teid, payload, addr, e)
+func (u *UPlaneConn) WriteToGTPWithExtensionHeaders(teid uint32, p []byte, addr net.Addr, exts ...*message.ExtensionHeader) (n int, err error) {
+	pdu := u.encapsulate(teid, p)
+	pdu.Header.WithExtensionHeaders(exts...)
+
+	b, err := pdu.Marshal()
+	if err != nil {
+		return
+	}
+
+	if _, err = u.writeTPDU(teid, b, addr); err != nil {
+		return
+	}
+	return len(b), nil
+}
+
+// WriteToGTPWithOptions writes a packet with TEID and payload to addr, with the
+// optional fields in the header controlled by the HeaderOptions given.
+//
+// The options are applied after the sequence number enabled by EnableSequenceNumber
+// is set, so that, e.g., message.WithoutOptionalFields can make the header the short
+// one of 8 octets regardless of it.
+func (u *UPlaneConn) WriteToGTPWithOptions(teid uint32, p []byte, addr net.Addr, opts ...message.HeaderOption) (n int, err error) {
+	pdu := u.encapsulate(teid, p)
+	pdu.Header.WithOptions(opts...)
+
+	b, err := pdu.Marshal()
 	if err != nil {
 		return
 	}
 
-	if _, err = u.pktConn.WriteTo(b, addr); err != nil {
+	if _, err = u.writeTPDU(teid, b, addr); err != nil {
 		return
 	}
 	return len(b), nil
@@ -285,11 +400,12 @@ func (u *UPlaneConn) closed() <-chan struct{} {
 
 // Close closes the connection.
 // Any blocked Read or Write operations will be unblocked and return errors.
+//
+// The handlers added with AddHandler are kept as they are.
 func (u *UPlaneConn) Close() error {
 	u.mu.Lock()
 	defer u.mu.Unlock()
 
-	u.msgHandlerMap = defaultHandlerMap
 	u.relayMap = nil
 	close(u.closeCh)
 
@@ -402,7 +518,7 @@ func (u *UPlaneConn) EchoRequest(raddr net.Addr) error {
 		return err
 	}
 
-	if _, err := u.pktConn.WriteTo(b, raddr); err != nil {
+	if _, err := u.WriteTo(b, raddr); err != nil {
 		return err
 	}
 	return nil
@@ -415,7 +531,7 @@ func (u *UPlaneConn) EchoResponse(raddr net.Addr) error {
 		return err
 	}
 
-	if _, err := u.pktConn.WriteTo(b, raddr); err != nil {
+	if _, err := u.WriteTo(b, raddr); err != nil {
 		return err
 	}
 	return nil
@@ -423,16 +539,38 @@ func (u *UPlaneConn) EchoResponse(raddr net.Addr) error {
 
 // ErrorIndication just sends ErrorIndication message.
 func (u *UPlaneConn) ErrorIndication(raddr net.Addr, received message.Message) error {
+	return u.sendErrorIndication(raddr, received.TEID(), received.Sequence())
+}
+
+// SendErrorIndication sends Error Indication to raddr, to notify that the T-PDU
+// with the TEID given has been received but no tunnel is found for it.
+//
+// This is done automatically by the default handler for T-PDU unless it is disabled
+// by DisableErrorIndication.
+func (u *UPlaneConn) SendErrorIndication(teid uint32, raddr net.Addr) error {
+	return u.sendErrorIndication(raddr, teid, 0)
+}
+
+func (u *UPlaneConn) sendErrorIndication(raddr net.Addr, teid uint32, seq uint16) error {
 	ip, _, err := net.SplitHostPort(u.LocalAddr().String())
 	if err != nil {
 		return err
 	}
 
-	errInd, err := message.NewErrorIndication(
-		0, received.Sequence(),
-		ie.NewTEIDDataI(received.TEID()),
+	ind := message.NewErrorIndication(
+		0, seq,
+		ie.NewTEIDDataI(teid),
 		ie.NewGSNAddress(ip),
-	).Marshal()
+	)
+	// the UDP source port of the G-PDU that triggered the Error Indication.
+	u.mu.Lock()
+	withUDPPort := u.errIndUDPPort
+	u.mu.Unlock()
+	if ua, ok := raddr.(*net.UDPAddr); ok && withUDPPort {
+		ind.Header.WithExtensionHeaders(message.NewUDPPortExtensionHeader(uint16(ua.Port)))
+	}
+
+	errInd, err := ind.Marshal()
 	if err != nil {
 		return err
 	}
@@ -443,6 +581,163 @@ func (u *UPlaneConn) ErrorIndication(raddr net.Addr, received message.Message) e
 	return nil
 }
 
+// SendEndMarker sends an End Marker with the TEID given to raddr, to indicate
+// the end of the T-PDUs on the path to the peer, e.g., on path switching at handover.
+func (u *UPlaneConn) SendEndMarker(teid uint32, raddr net.Addr) error {
+	b, err := message.NewEndMarker(teid).Marshal()
+	if err != nil {
+		return err
+	}
+
+	if _, err := u.WriteTo(b, raddr); err != nil {
+		return err
+	}
+	return nil
+}
+
+// EndMarkerFunc is called when an End Marker is received on UPlaneConn.
+// teid is the TEID in the GTP header of the End Marker.
+type EndMarkerFunc func(u *UPlaneConn, senderAddr net.Addr, teid uint32)
+
+// SetEndMarkerHandler sets the function called when an End Marker is received.
+// Giving nil removes it.
+//
+// If the TEID is relayed with RelayTo, the End Marker is forwarded to the peer
+// with the outgoing TEID before fn is called, so that the nodes further on the
+// path can switch as well.
+//
+// This works with the default handler for End Marker. If it is overridden with
+// AddHandler, fn is not called.
+func (u *UPlaneConn) SetEndMarkerHandler(fn EndMarkerFunc) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.endMarkerFn = fn
+}
+
+// ErrorIndicationFunc is called when an Error Indication is received on UPlaneConn.
+//
+// ind has the TEID and the address in the Error Indication, which are the outgoing
+// TEID of the tunnel and the address of the peer that does not know it. itei is
+// the incoming TEID of the tunnel affected, which is valid only if found is true.
+// The tunnels are looked up from the ones relayed with RelayTo or added with
+// AddTunnel to Kernel GTP-U.
+type ErrorIndicationFunc func(u *UPlaneConn, senderAddr net.Addr, ind *ErrorIndicatedError, itei uint32, found bool)
+
+// SetErrorIndicationHandler sets the function called when an Error Indication is
+// received. Giving nil removes it.
+//
+// This works with the default handler for Error Indication. If it is overridden
+// with AddHandler, fn is not called.
+func (u *UPlaneConn) SetErrorIndicationHandler(fn ErrorIndicationFunc) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.errIndFn = fn
+}
+
+// EnableTeardownOnErrorIndication makes the default handler for Error Indication
+// remove the tunnel affected, after calling the function set by SetErrorIndicationHandler.
+//
+// See also: DisableTeardownOnErrorIndication.
+func (u *UPlaneConn) EnableTeardownOnErrorIndication() {
+	u.mu.Lock()
+	u.errIndTeardown = true
+	u.mu.Unlock()
+}
+
+// DisableTeardownOnErrorIndication stops the removal of the tunnels on Error Indication,
+// which is disabled by default.
+func (u *UPlaneConn) DisableTeardownOnErrorIndication() {
+	u.mu.Lock()
+	u.errIndTeardown = false
+	u.mu.Unlock()
+}
+
+// EnableSupportedExtensionHeadersNotification makes UPlaneConn respond with
+// Supported Extension Headers Notification to the messages that have any extension
+// header which is required to be comprehended but not in the types given, and
+// discard them without passing to the handlers or relaying.
+//
+// The types given are listed in the Notification as the ones supported by the
+// application, e.g., message.ExtHeaderTypePDUSessionContainer on N3.
+//
+// See also: DisableSupportedExtensionHeadersNotification.
+func (u *UPlaneConn) EnableSupportedExtensionHeadersNotification(types ...uint8) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.extHdrNotify = true
+	u.extHdrTypes = types
+}
+
+// DisableSupportedExtensionHeadersNotification stops the check of the extension
+// headers in the messages received, which is disabled by default.
+func (u *UPlaneConn) DisableSupportedExtensionHeadersNotification() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.extHdrNotify = false
+	u.extHdrTypes = nil
+}
+
+// discardUnsupportedExtensionHeaders returns true if the packet in b has an
+// extension header that is required to be comprehended but not supported, after
+// sending Supported Extension Headers Notification to the sender.
+func (u *UPlaneConn) discardUnsupportedExtensionHeaders(b []byte, raddr net.Addr) bool {
+	// no need to look into the packet without E flag.
+	if len(b) < 8 || b[0]&0x04 == 0 {
+		return false
+	}
+
+	u.mu.Lock()
+	enabled, types := u.extHdrNotify, u.extHdrTypes
+	u.mu.Unlock()
+	if !enabled {
+		return false
+	}
+
+	h, err := message.ParseHeader(b)
+	if err != nil {
+		return false
+	}
+
+	for _, e := range h.ExtensionHeaders {
+		if !e.ComprehensionRequired() || hasExtensionHeaderType(types, e.Type) {
+			continue
+		}
+
+		if err := u.sendSupportedExtensionHeadersNotification(raddr, types); err != nil {
+			logf("failed to send Supported Extension Headers Notification to %s: %v", raddr, err)
+		}
+		return true
+	}
+	return false
+}
+
+func (u *UPlaneConn) sendSupportedExtensionHeadersNotification(raddr net.Addr, types []uint8) error {
+	b, err := message.NewSupportedExtensionHeadersNotification(
+		0, 0, ie.NewExtensionHeaderTypeList(types...),
+	).Marshal()
+	if err != nil {
+		return err
+	}
+
+	if _, err := u.WriteTo(b, raddr); err != nil {
+		return err
+	}
+	return nil
+}
+
+func hasExtensionHeaderType(types []uint8, typ uint8) bool {
+	for _, t := range types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
 // RespondTo sends a message(specified with "toBeSent" param) in response to
 // a message(specified with "received" param).
 //
@@ -551,3 +846,22 @@ func (u *UPlaneConn) DisableErrorIndication() {
 	u.errIndEnabled = false
 	u.mu.Unlock()
 }
+
+// EnableErrorIndicationUDPPort makes UPlaneConn put the UDP Port extension header
+// in the Error Indication it sends, with the UDP source port of the G-PDU that
+// triggered it. It is not included by default.
+//
+// See also: DisableErrorIndicationUDPPort.
+func (u *UPlaneConn) EnableErrorIndicationUDPPort() {
+	u.mu.Lock()
+	u.errIndUDPPort = true
+	u.mu.Unlock()
+}
+
+// DisableErrorIndicationUDPPort stops putting the UDP Port extension header in
+// the Error Indication enabled by EnableErrorIndicationUDPPort.
+func (u *UPlaneConn) DisableErrorIndicationUDPPort() {
+	u.mu.Lock()
+	u.errIndUDPPort = false
+	u.mu.Unlock()
+}
@@ -5,6 +5,7 @@
 package gtpv1_test
 
 import (
+	"bytes"
 	"context"
 	"net"
 	"testing"
@@ -12,7 +13,10 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 
+	"github.com/wmnsk/go-gtp/gtpcap"
 	v1 "github.com/wmnsk/go-gtp/gtpv1"
+	"github.com/wmnsk/go-gtp/gtpv1/ie"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
 )
 
 type testVal struct {
@@ -103,3 +107,664 @@ func TestClientWrite(t *testing.T) {
 		t.Fatal("timed out while waiting for response to come")
 	}
 }
+
+func TestNewUPlaneConnWithPacketConn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.3:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srvConn := v1.NewUPlaneConnWithPacketConn(pc)
+	srvConn.DisableErrorIndication()
+	go func() {
+		if err := srvConn.ListenAndServe(ctx); err != nil {
+			return
+		}
+	}()
+
+	if got, want := srvConn.LocalAddr().String(), "127.0.0.3:2152"; got != want {
+		t.Errorf("wrong local address. want: %s, got: %s", want, got)
+	}
+
+	cliAddr, err := net.ResolveUDPAddr("udp", "127.0.0.4:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// DialUPlane fails unless the server responds to Echo Request over pc.
+	if _, err := v1.DialUPlane(ctx, cliAddr, pc.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriteToGTPWithExtensionHeaders(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.5:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	cliPC, err := net.ListenPacket("udp", "127.0.0.6:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cliConn := v1.NewUPlaneConnWithPacketConn(cliPC)
+	defer cliConn.Close()
+
+	e, err := message.NewDLPDUSessionInformation(9, false).ToExtensionHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cliConn.WriteToGTPWithExtensionHeaders(0x11111111, []byte{0xde, 0xad, 0xbe, 0xef}, pc.LocalAddr(), e); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pc.SetReadDeadline(time.Now().Add(3 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1500)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pdu, err := message.ParseTPDU(buf[:n])
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := pdu.ExtensionHeaderByType(message.ExtHeaderTypePDUSessionContainer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dl, err := message.DLPDUSessionInformationFromExtensionHeader(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dl.QFI, uint8(9); got != want {
+		t.Errorf("wrong QFI. want: %d, got: %d", want, got)
+	}
+	if diff := cmp.Diff(pdu.Payload, []byte{0xde, 0xad, 0xbe, 0xef}); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestWriteToGTPWithOptions(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.41:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	cliPC, err := net.ListenPacket("udp", "127.0.0.42:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cliConn := v1.NewUPlaneConnWithPacketConn(cliPC)
+	defer cliConn.Close()
+	cliConn.EnableSequenceNumber(0x22222222)
+
+	payload := []byte{0xde, 0xad, 0xbe, 0xef}
+	cases := []struct {
+		description string
+		teid        uint32
+		opts        []message.HeaderOption
+		header      []byte
+	}{
+		{
+			"NPDUNumber", 0x11111111,
+			[]message.HeaderOption{message.WithNPDUNumber(0x12)},
+			[]byte{0x31, 0xff, 0x00, 0x08, 0x11, 0x11, 0x11, 0x11, 0x00, 0x00, 0x12, 0x00},
+		}, {
+			"WithoutOptionalFields", 0x22222222,
+			[]message.HeaderOption{message.WithoutOptionalFields()},
+			[]byte{0x30, 0xff, 0x00, 0x04, 0x22, 0x22, 0x22, 0x22},
+		},
+	}
+
+	buf := make([]byte, 1500)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if _, err := cliConn.WriteToGTPWithOptions(c.teid, payload, pc.LocalAddr(), c.opts...); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := pc.SetReadDeadline(time.Now().Add(3 * time.Second)); err != nil {
+				t.Fatal(err)
+			}
+			n, _, err := pc.ReadFrom(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(buf[:n], append(c.header, payload...)); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestEndMarker(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srvPC, err := net.ListenPacket("udp", "127.0.0.7:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srvConn := v1.NewUPlaneConnWithPacketConn(srvPC)
+
+	type received struct {
+		addr net.Addr
+		teid uint32
+	}
+	rcvCh := make(chan received, 1)
+	srvConn.SetEndMarkerHandler(func(u *v1.UPlaneConn, senderAddr net.Addr, teid uint32) {
+		rcvCh <- received{senderAddr, teid}
+	})
+	go func() {
+		if err := srvConn.ListenAndServe(ctx); err != nil {
+			return
+		}
+	}()
+
+	cliPC, err := net.ListenPacket("udp", "127.0.0.8:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cliConn := v1.NewUPlaneConnWithPacketConn(cliPC)
+	defer cliConn.Close()
+
+	if err := cliConn.SendEndMarker(0x11111111, srvConn.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case r := <-rcvCh:
+		if got, want := r.teid, uint32(0x11111111); got != want {
+			t.Errorf("wrong TEID. want: %#x, got: %#x", want, got)
+		}
+		if got, want := r.addr.String(), "127.0.0.8:2152"; got != want {
+			t.Errorf("wrong sender. want: %s, got: %s", want, got)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for End Marker")
+	}
+}
+
+func TestErrorIndicationTeardown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var conns []*v1.UPlaneConn
+	for _, addr := range []string{"127.0.0.9:2152", "127.0.0.10:2152"} {
+		pc, err := net.ListenPacket("udp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u := v1.NewUPlaneConnWithPacketConn(pc)
+		go func() {
+			if err := u.ListenAndServe(ctx); err != nil {
+				return
+			}
+		}()
+		conns = append(conns, u)
+	}
+	inConn, outConn := conns[0], conns[1]
+
+	peerPC, err := net.ListenPacket("udp", "127.0.0.15:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peerPC.Close()
+
+	if err := inConn.RelayTo(outConn, 0x11111111, 0x22222222, peerPC.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+
+	type indicated struct {
+		itei  uint32
+		found bool
+	}
+	indCh := make(chan indicated, 1)
+	outConn.SetErrorIndicationHandler(func(u *v1.UPlaneConn, senderAddr net.Addr, ind *v1.ErrorIndicatedError, itei uint32, found bool) {
+		indCh <- indicated{itei, found}
+	})
+	outConn.EnableTeardownOnErrorIndication()
+	inConn.EnableErrorIndicationUDPPort()
+
+	b, err := message.NewErrorIndication(
+		0, 0, ie.NewTEIDDataI(0x22222222), ie.NewGSNAddress("127.0.0.15"),
+	).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := peerPC.WriteTo(b, outConn.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case ind := <-indCh:
+		if !ind.found {
+			t.Fatal("tunnel should be found")
+		}
+		if got, want := ind.itei, uint32(0x11111111); got != want {
+			t.Errorf("wrong incoming TEID. want: %#x, got: %#x", want, got)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for Error Indication")
+	}
+
+	// T-PDU for the removed tunnel should be responded with Error Indication
+	// instead of being relayed.
+	tpdu, err := message.NewTPDU(0x11111111, []byte{0xde, 0xad, 0xbe, 0xef}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1500)
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if time.Now().After(deadline) {
+			t.Fatal("tunnel has not been removed")
+		}
+		if _, err := peerPC.WriteTo(tpdu, inConn.LocalAddr()); err != nil {
+			t.Fatal(err)
+		}
+		if err := peerPC.SetReadDeadline(time.Now().Add(100 * time.Millisecond)); err != nil {
+			t.Fatal(err)
+		}
+		n, _, err := peerPC.ReadFrom(buf)
+		if err != nil {
+			continue
+		}
+		if n > 1 && buf[1] == message.MsgTypeErrorIndication {
+			ind, err := message.ParseErrorIndication(buf[:n])
+			if err != nil {
+				t.Fatal(err)
+			}
+			e, err := ind.ExtensionHeaderByType(message.ExtHeaderTypeUDPPort)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := e.MustUDPPort(), uint16(2152); got != want {
+				t.Errorf("wrong UDP Port. want: %d, got: %d", want, got)
+			}
+			break
+		}
+	}
+}
+
+func TestSequenceNumber(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srvPC, err := net.ListenPacket("udp", "127.0.0.16:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srvConn := v1.NewUPlaneConnWithPacketConn(srvPC)
+	srvConn.DisableErrorIndication()
+	srvConn.EnableReordering(0x11111111, 8, 200*time.Millisecond)
+	go func() {
+		if err := srvConn.ListenAndServe(ctx); err != nil {
+			return
+		}
+	}()
+
+	cliPC, err := net.ListenPacket("udp", "127.0.0.17:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cliConn := v1.NewUPlaneConnWithPacketConn(cliPC)
+	defer cliConn.Close()
+
+	t.Run("Egress", func(t *testing.T) {
+		pc, err := net.ListenPacket("udp", "127.0.0.18:2152")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pc.Close()
+
+		cliConn.EnableSequenceNumber(0x22222222)
+		defer cliConn.DisableSequenceNumber(0x22222222)
+
+		buf := make([]byte, 1500)
+		for want := uint16(0); want < 3; want++ {
+			if _, err := cliConn.WriteToGTP(0x22222222, []byte{0xde, 0xad, 0xbe, 0xef}, pc.LocalAddr()); err != nil {
+				t.Fatal(err)
+			}
+			if err := pc.SetReadDeadline(time.Now().Add(3 * time.Second)); err != nil {
+				t.Fatal(err)
+			}
+			n, _, err := pc.ReadFrom(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pdu, err := message.ParseTPDU(buf[:n])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !pdu.HasSequence() {
+				t.Fatal("T-PDU should have sequence number")
+			}
+			if got := pdu.Sequence(); got != want {
+				t.Errorf("wrong sequence number. want: %d, got: %d", want, got)
+			}
+		}
+	})
+
+	t.Run("Reordering", func(t *testing.T) {
+		// 10 comes after 11 but should be passed first. 14 is missing, and 15
+		// should be passed after the timeout.
+		for _, seq := range []uint16{11, 10, 13, 12, 15} {
+			b, err := message.NewTPDUWithSequence(0x11111111, seq, []byte{uint8(seq)}).Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := cliConn.WriteTo(b, srvConn.LocalAddr()); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		buf := make([]byte, 1500)
+		for _, want := range []uint8{10, 11, 12, 13, 15} {
+			readCh := make(chan uint8)
+			go func() {
+				n, _, _, err := srvConn.ReadFromGTP(buf)
+				if err != nil || n != 1 {
+					return
+				}
+				readCh <- buf[0]
+			}()
+
+			select {
+			case got := <-readCh:
+				if got != want {
+					t.Fatalf("wrong order. want: %d, got: %d", want, got)
+				}
+			case <-time.After(3 * time.Second):
+				t.Fatalf("timed out waiting for T-PDU %d", want)
+			}
+		}
+	})
+}
+
+func TestSupportedExtensionHeadersNotification(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srvPC, err := net.ListenPacket("udp", "127.0.0.39:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srvConn := v1.NewUPlaneConnWithPacketConn(srvPC)
+	defer srvConn.Close()
+	srvConn.DisableErrorIndication()
+	srvConn.EnableSupportedExtensionHeadersNotification(message.ExtHeaderTypePDUSessionContainer)
+	go func() {
+		if err := srvConn.ListenAndServe(ctx); err != nil {
+			return
+		}
+	}()
+
+	cliPC, err := net.ListenPacket("udp", "127.0.0.40:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cliConn := v1.NewUPlaneConnWithPacketConn(cliPC)
+	defer cliConn.Close()
+
+	notifCh := make(chan []uint8, 1)
+	cliConn.AddHandler(message.MsgTypeSupportedExtensionHeadersNotification, func(c v1.Conn, senderAddr net.Addr, msg message.Message) error {
+		n, ok := msg.(*message.SupportedExtensionHeadersNotification)
+		if !ok {
+			return v1.ErrUnexpectedType
+		}
+		types, err := n.ExtensionHeaderTypeList.ExtensionHeaderTypeList()
+		if err != nil {
+			return err
+		}
+		notifCh <- types
+		return nil
+	})
+	go func() {
+		if err := cliConn.ListenAndServe(ctx); err != nil {
+			return
+		}
+	}()
+
+	payload := []byte{0xde, 0xad, 0xbe, 0xef}
+	dl, err := message.NewDLPDUSessionInformation(9, false).ToExtensionHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the one required to be comprehended but not supported is discarded.
+	if _, err := cliConn.WriteToGTPWithExtensionHeaders(
+		0x11111111, payload, srvConn.LocalAddr(),
+		message.NewExtensionHeader(message.ExtHeaderTypeXwRANContainer, []byte{0x00, 0x00}),
+	); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case types := <-notifCh:
+		if diff := cmp.Diff(types, []uint8{message.ExtHeaderTypePDUSessionContainer}); diff != "" {
+			t.Error(diff)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out while waiting for Supported Extension Headers Notification")
+	}
+
+	// the supported one and the one not required to be comprehended are passed.
+	if _, err := cliConn.WriteToGTPWithExtensionHeaders(0x22222222, payload, srvConn.LocalAddr(), dl); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cliConn.WriteToGTPWithExtensionHeaders(
+		0x33333333, payload, srvConn.LocalAddr(),
+		message.NewExtensionHeader(message.ExtHeaderTypeServiceClassIndicator, []byte{0x01, 0x00}),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := srvConn.SetReadDeadline(time.Now().Add(3 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	got := map[uint32]bool{}
+	buf := make([]byte, 1500)
+	for i := 0; i < 2; i++ {
+		_, _, teid, err := srvConn.ReadFromGTP(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[teid] = true
+	}
+	if diff := cmp.Diff(got, map[uint32]bool{0x22222222: true, 0x33333333: true}); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestCaptureHook(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.56:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	srvPC, err := net.ListenPacket("udp", "127.0.0.57:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srvConn := v1.NewUPlaneConnWithPacketConn(srvPC)
+	defer srvConn.Close()
+	srvConn.DisableErrorIndication()
+
+	capBuf := &bytes.Buffer{}
+	w, err := gtpcap.NewWriter(capBuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srvConn.SetCaptureHook(w.Hook())
+	go func() {
+		if err := srvConn.ListenAndServe(ctx); err != nil {
+			return
+		}
+	}()
+
+	b, err := message.NewTPDU(0x11111111, []byte{0xde, 0xad, 0xbe, 0xef}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pc.WriteTo(b, srvPC.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 1500)
+	if _, _, _, err := srvConn.ReadFromGTP(buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srvConn.WriteToGTP(0x22222222, []byte{0xde, 0xad, 0xbe, 0xef}, pc.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := gtpcap.NewReader(capBuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkts, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkts) != 2 {
+		t.Fatalf("wrong number of packets captured, want: 2, got: %d", len(pkts))
+	}
+
+	for i, want := range []struct {
+		teid     uint32
+		src, dst string
+	}{
+		{0x11111111, "127.0.0.56:2152", "127.0.0.57:2152"},
+		{0x22222222, "127.0.0.57:2152", "127.0.0.56:2152"},
+	} {
+		p := pkts[i]
+		pdu, ok := p.Message.(*message.TPDU)
+		if !ok {
+			t.Fatalf("wrong message captured: %v", p.Message)
+		}
+		if got := pdu.TEID(); got != want.teid {
+			t.Errorf("wrong TEID. want: %#x, got: %#x", want.teid, got)
+		}
+		if p.Src.String() != want.src || p.Dst.String() != want.dst {
+			t.Errorf("wrong addresses: %s -> %s", p.Src, p.Dst)
+		}
+	}
+}
+
+func TestErrorIndicationDefault(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.61:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uConn := v1.NewUPlaneConnWithPacketConn(pc)
+	defer uConn.Close()
+
+	peerPC, err := net.ListenPacket("udp", "127.0.0.62:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peerPC.Close()
+
+	// no extension header should be included by default.
+	if err := uConn.SendErrorIndication(0x11111111, peerPC.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+	want, err := message.NewErrorIndication(
+		0, 0, ie.NewTEIDDataI(0x11111111), ie.NewGSNAddress("127.0.0.61"),
+	).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 1500)
+	if err := peerPC.SetReadDeadline(time.Now().Add(3 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	n, _, err := peerPC.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, buf[:n]); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestHandlersPerConn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var conns []*v1.UPlaneConn
+	for _, addr := range []string{"127.0.0.58:2152", "127.0.0.59:2152"} {
+		pc, err := net.ListenPacket("udp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u := v1.NewUPlaneConnWithPacketConn(pc)
+		go func() {
+			if err := u.ListenAndServe(ctx); err != nil {
+				return
+			}
+		}()
+		conns = append(conns, u)
+	}
+
+	peerPC, err := net.ListenPacket("udp", "127.0.0.60:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peerPC.Close()
+
+	// the handler added to one UPlaneConn should not affect the other.
+	handledCh := make(chan struct{}, 1)
+	conns[0].AddHandler(message.MsgTypeEchoRequest, func(c v1.Conn, senderAddr net.Addr, msg message.Message) error {
+		handledCh <- struct{}{}
+		return nil
+	})
+
+	b, err := message.NewEchoRequest(0, ie.NewRecovery(0)).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := peerPC.WriteTo(b, conns[1].LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1500)
+	if err := peerPC.SetReadDeadline(time.Now().Add(3 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	n, _, err := peerPC.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf[1], message.MsgTypeEchoResponse; n < 2 || got != want {
+		t.Errorf("unexpected response from the UPlaneConn with the default handler: %x", buf[:n])
+	}
+	select {
+	case <-handledCh:
+		t.Fatal("handler added to another UPlaneConn is called")
+	default:
+	}
+
+	if _, err := peerPC.WriteTo(b, conns[0].LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-handledCh:
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for the handler to be called")
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"net"
+
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// PeerPolicy is the policy to decide whether to accept the messages from a peer.
+//
+// A peer is accepted if its IP address is in any of AllowedNetworks, or AllowFunc
+// returns true for it.
+type PeerPolicy struct {
+	// AllowedNetworks is the list of the networks the peers are allowed to be in.
+	AllowedNetworks []*net.IPNet
+
+	// AllowFunc is called for the peers not in AllowedNetworks, if not nil.
+	AllowFunc func(raddr net.Addr) bool
+
+	// Reject makes Conn respond to the requests from the peers not allowed with
+	// Cause "Request rejected (reason not specified)", instead of dropping them
+	// silently. The messages other than requests are always dropped.
+	Reject bool
+}
+
+// NewPeerPolicy creates a new PeerPolicy that allows the networks given in CIDR
+// notation, e.g., "192.0.2.0/24".
+func NewPeerPolicy(cidrs ...string) (*PeerPolicy, error) {
+	p := &PeerPolicy{}
+	for _, cidr := range cidrs {
+		_, ipnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		p.AllowedNetworks = append(p.AllowedNetworks, ipnet)
+	}
+	return p, nil
+}
+
+// Allows reports whether the peer is allowed by the PeerPolicy.
+func (p *PeerPolicy) Allows(raddr net.Addr) bool {
+	if ip := addrIP(raddr); ip != nil {
+		for _, ipnet := range p.AllowedNetworks {
+			if ipnet.Contains(ip) {
+				return true
+			}
+		}
+	}
+
+	if p.AllowFunc != nil {
+		return p.AllowFunc(raddr)
+	}
+	return false
+}
+
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP
+	case *net.IPAddr:
+		return a.IP
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
+
+// SetPeerPolicy sets the PeerPolicy to the Conn. The packets from the peers not
+// allowed are dropped(or rejected) before being parsed and passed to HandlerFunc.
+//
+// Giving nil accepts all the peers, which is the default.
+func (c *Conn) SetPeerPolicy(policy *PeerPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.peerPolicy = policy
+}
+
+// RejectedPackets returns the number of packets dropped or rejected by PeerPolicy.
+func (c *Conn) RejectedPackets() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.rejectedPackets
+}
+
+// acceptsPeer reports whether the packet from raddr should be handled, and rejects
+// it if necessary.
+func (c *Conn) acceptsPeer(raddr net.Addr, raw []byte) bool {
+	c.mu.Lock()
+	policy := c.peerPolicy
+	c.mu.Unlock()
+
+	// AllowFunc is evaluated without the lock, as it may call the methods of Conn.
+	if policy == nil || policy.Allows(raddr) {
+		return true
+	}
+
+	c.mu.Lock()
+	c.rejectedPackets++
+	c.mu.Unlock()
+
+	if policy.Reject {
+		if err := c.rejectRequest(raddr, raw, CauseRequestRejectedReasonNotSpecified); err != nil {
+			logf("failed to reject the request from %s: %s", raddr, err)
+		}
+	}
+	return false
+}
+
+// rejectRequest responds to the request with the Cause given, by looking only at
+// the header of it.
+func (c *Conn) rejectRequest(raddr net.Addr, raw []byte, cause uint8) error {
+	h, err := message.ParseHeader(raw)
+	if err != nil {
+		return err
+	}
+	return c.respondWithError(raddr, h.Type, 0, h.Sequence(), cause, 0)
+}
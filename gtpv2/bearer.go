@@ -6,6 +6,9 @@ package gtpv2
 
 import (
 	"net"
+	"sync"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
 )
 
 // QoSProfile represents a QoS-related information that belongs to a Bearer.
@@ -18,8 +21,45 @@ type QoSProfile struct {
 	GBRUL, GBRDL uint64
 }
 
+// newBearerQoSIE creates a new BearerQoS IE from the values in QoSProfile.
+func newBearerQoSIE(qos *QoSProfile) *ie.IE {
+	var pci, pvi uint8
+	if qos.PCI {
+		pci = 1
+	}
+	if qos.PVI {
+		pvi = 1
+	}
+
+	return ie.NewBearerQoS(pci, qos.PL, pvi, qos.QCI, qos.MBRUL, qos.MBRDL, qos.GBRUL, qos.GBRDL)
+}
+
+// parseBearerQoSIE retrieves the values in BearerQoS IE as QoSProfile.
+func parseBearerQoSIE(qosIE *ie.IE) (*QoSProfile, error) {
+	f, err := qosIE.BearerQoS()
+	if err != nil {
+		return nil, err
+	}
+
+	return &QoSProfile{
+		PCI:   f.ARP&0x40 != 0,
+		PL:    (f.ARP & 0x3c) >> 2,
+		PVI:   f.ARP&0x01 != 0,
+		QCI:   f.QCI,
+		MBRUL: f.MaximumBitRateForUplink,
+		MBRDL: f.MaximumBitRateForDownlink,
+		GBRUL: f.GuaranteedBitRateForUplink,
+		GBRDL: f.GuaranteedBitRateForDownlink,
+	}, nil
+}
+
 // Bearer represents a GTPv2 bearer.
+//
+// The remote address and the TEIDs are guarded by the lock in Bearer, so they
+// should be accessed with the methods. The exported fields are expected to be
+// set before the Bearer is added to a Session.
 type Bearer struct {
+	mu              sync.Mutex
 	raddr           net.Addr
 	teidIn, teidOut uint32
 
@@ -38,30 +78,71 @@ func NewBearer(ebi uint8, apn string, qos *QoSProfile) *Bearer {
 
 // RemoteAddress returns the remote address associated with Bearer.
 func (b *Bearer) RemoteAddress() net.Addr {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
 	return b.raddr
 }
 
 // SetRemoteAddress sets the remote address associated with Bearer.
 func (b *Bearer) SetRemoteAddress(raddr net.Addr) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
 	b.raddr = raddr
 }
 
 // IncomingTEID returns the incoming TEID associated with Bearer.
 func (b *Bearer) IncomingTEID() uint32 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
 	return b.teidIn
 }
 
 // SetIncomingTEID sets the incoming TEID associated with Bearer.
 func (b *Bearer) SetIncomingTEID(teid uint32) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
 	b.teidIn = teid
 }
 
 // OutgoingTEID returns the outgoing TEID associated with Bearer.
 func (b *Bearer) OutgoingTEID() uint32 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
 	return b.teidOut
 }
 
 // SetOutgoingTEID sets the outgoing TEID associated with Bearer.
 func (b *Bearer) SetOutgoingTEID(teid uint32) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
 	b.teidOut = teid
 }
+
+// peerUPlaneIFTypes returns the interface types of the user plane F-TEIDs that the
+// Bearers on Conn send the packets to, i.e., the ones of the peer on the user plane
+// corresponding to the interface of Conn. It returns nil if the interface is unknown.
+func (c *Conn) peerUPlaneIFTypes() []uint8 {
+	switch c.localIfType {
+	case IFTypeS11MMEGTPC, IFTypeS11S4SGWGTPC, IFTypeS4SGSNGTPC:
+		return []uint8{IFTypeS1UeNodeBGTPU, IFTypeS12RNCGTPU, IFTypeS4SGSNGTPU, IFTypeS11MMEGTPU}
+	case IFTypeS5S8SGWGTPC:
+		return []uint8{IFTypeS5S8PGWGTPU}
+	case IFTypeS5S8PGWGTPC:
+		return []uint8{IFTypeS5S8SGWGTPU}
+	case IFTypeS2bePDGGTPC:
+		return []uint8{IFTypeS2bUPGWGTPU}
+	case IFTypeS2bPGWGTPC:
+		return []uint8{IFTypeS2bUePDGGTPU}
+	case IFTypeS2aTWANGTPC:
+		return []uint8{IFTypeS2aPGWGTPU}
+	case IFTypeS2aPGWGTPC:
+		return []uint8{IFTypeS2aTWANGTPU}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"net"
+
+	"github.com/wmnsk/go-gtp/gtpcap"
+)
+
+// SetCaptureHook sets the function called with every packet sent and received on
+// the Conn, with the timestamp and the addresses. Giving nil disables capturing.
+//
+// The Hook returned by (*gtpcap.Writer).Hook writes the packets to a pcapng file,
+// which lets the traces be collected without capturing on the host, e.g.,
+//
+//	w, _ := gtpcap.CreateFile("s11.pcapng")
+//	defer w.Close()
+//	c.SetCaptureHook(w.Hook())
+//
+// The packets received are passed to the hook before any validation, and the ones
+// sent are passed after they are written, or queued if EnableSendQueue is called.
+func (c *Conn) SetCaptureHook(fn gtpcap.Hook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.captureHook = fn
+}
+
+func (c *Conn) capture(b []byte, src, dst net.Addr) {
+	c.mu.Lock()
+	fn := c.captureHook
+	c.mu.Unlock()
+
+	if fn == nil {
+		return
+	}
+	fn(gtpcap.NewPacket(b, src, dst))
+}
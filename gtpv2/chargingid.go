@@ -0,0 +1,180 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"sync"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+// ChargingIDAllocator allocates the Charging IDs unique within the node.
+//
+// The implementations must be safe for concurrent use, as Conn calls them from the
+// goroutines handling the messages.
+type ChargingIDAllocator interface {
+	// Allocate returns a new Charging ID, which is never 0.
+	Allocate() (uint32, error)
+
+	// Release returns the Charging ID to the allocator to be reused.
+	Release(id uint32)
+}
+
+// SetChargingIDAllocator sets the ChargingIDAllocator used by Conn. Giving nil disables it.
+//
+// When it is set, ParseCreateSession allocates a Charging ID to the default bearer, and
+// CreateBearerWithWait allocates one to the dedicated bearer to be created and puts it in
+// the Bearer Context in the request. The Charging IDs are released when the Bearer is
+// removed from the Session or the Session is removed from Conn.
+//
+// This is expected to be used by P-GW, which assigns the Charging IDs.
+func (c *Conn) SetChargingIDAllocator(a ChargingIDAllocator) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.chargingIDAllocator = a
+}
+
+func (c *Conn) loadChargingIDAllocator() ChargingIDAllocator {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.chargingIDAllocator
+}
+
+// allocateChargingID sets a new Charging ID to br if ChargingIDAllocator is set and
+// br does not have one yet.
+func (c *Conn) allocateChargingID(br *Bearer) error {
+	a := c.loadChargingIDAllocator()
+	if a == nil || br.ChargingID != 0 {
+		return nil
+	}
+
+	id, err := a.Allocate()
+	if err != nil {
+		return err
+	}
+	br.ChargingID = id
+	return nil
+}
+
+func (c *Conn) releaseChargingID(br *Bearer) {
+	a := c.loadChargingIDAllocator()
+	if a == nil || br == nil || br.ChargingID == 0 {
+		return
+	}
+	a.Release(br.ChargingID)
+}
+
+// ChargingIDIE returns the Charging ID IE with the Charging ID of Bearer, or nil if
+// it is not assigned, which is ignored by the message constructors.
+func (b *Bearer) ChargingIDIE() *ie.IE {
+	if b.ChargingID == 0 {
+		return nil
+	}
+	return ie.NewChargingID(b.ChargingID)
+}
+
+// MonotonicChargingIDAllocator allocates the Charging IDs by incrementing the last
+// one, skipping 0 and the ones still in use after wrapping around.
+type MonotonicChargingIDAllocator struct {
+	mu      sync.Mutex
+	last    uint32
+	inUse   map[uint32]struct{}
+	persist func(last uint32) error
+}
+
+// NewMonotonicChargingIDAllocator creates a new MonotonicChargingIDAllocator that
+// allocates the Charging IDs after last.
+//
+// persist is called with every Charging ID allocated before it is returned, and can
+// be nil. Storing the value in it and giving it as last at the next start prevents
+// the Charging IDs from being reused across the restart. If persist returns error,
+// the allocation fails with it.
+func NewMonotonicChargingIDAllocator(last uint32, persist func(last uint32) error) *MonotonicChargingIDAllocator {
+	return &MonotonicChargingIDAllocator{
+		last:    last,
+		inUse:   map[uint32]struct{}{},
+		persist: persist,
+	}
+}
+
+// Allocate returns a new Charging ID.
+func (a *MonotonicChargingIDAllocator) Allocate() (uint32, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	id := a.last
+	for {
+		id++
+		if id == a.last {
+			return 0, ErrNoChargingIDAvailable
+		}
+		if id == 0 {
+			continue
+		}
+		if _, ok := a.inUse[id]; !ok {
+			break
+		}
+	}
+
+	if a.persist != nil {
+		if err := a.persist(id); err != nil {
+			return 0, err
+		}
+	}
+	a.last = id
+	a.inUse[id] = struct{}{}
+	return id, nil
+}
+
+// Release releases the Charging ID.
+func (a *MonotonicChargingIDAllocator) Release(id uint32) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.inUse, id)
+}
+
+// RandomChargingIDAllocator allocates the Charging IDs randomly, avoiding the ones
+// in use.
+type RandomChargingIDAllocator struct {
+	mu    sync.Mutex
+	inUse map[uint32]struct{}
+}
+
+// NewRandomChargingIDAllocator creates a new RandomChargingIDAllocator.
+func NewRandomChargingIDAllocator() *RandomChargingIDAllocator {
+	return &RandomChargingIDAllocator{inUse: map[uint32]struct{}{}}
+}
+
+// Allocate returns a new Charging ID.
+//
+// It returns ErrNoChargingIDAvailable if no unused value is found after some tries.
+func (a *RandomChargingIDAllocator) Allocate() (uint32, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for try := 0; try < 0xffff; try++ {
+		id := generateRandomUint32()
+		if id == 0 {
+			continue
+		}
+		if _, ok := a.inUse[id]; ok {
+			continue
+		}
+		a.inUse[id] = struct{}{}
+		return id, nil
+	}
+	return 0, ErrNoChargingIDAvailable
+}
+
+// Release releases the Charging ID.
+func (a *RandomChargingIDAllocator) Release(id uint32) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.inUse, id)
+}
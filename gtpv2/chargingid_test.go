@@ -0,0 +1,113 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2_test
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+func TestMonotonicChargingIDAllocator(t *testing.T) {
+	var persisted uint32
+	a := v2.NewMonotonicChargingIDAllocator(0xfffffffe, func(last uint32) error {
+		persisted = last
+		return nil
+	})
+
+	// 0 is skipped on wrapping around.
+	for _, want := range []uint32{0xffffffff, 1, 2} {
+		got, err := a.Allocate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("wrong Charging ID. want: %#x, got: %#x", want, got)
+		}
+		if persisted != want {
+			t.Errorf("wrong Charging ID persisted. want: %#x, got: %#x", want, persisted)
+		}
+	}
+
+	errPersist := errors.New("failed to persist")
+	a = v2.NewMonotonicChargingIDAllocator(0, func(last uint32) error { return errPersist })
+	if _, err := a.Allocate(); err != errPersist {
+		t.Errorf("want errPersist, got %v", err)
+	}
+}
+
+func TestRandomChargingIDAllocator(t *testing.T) {
+	a := v2.NewRandomChargingIDAllocator()
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		seen = map[uint32]struct{}{}
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 128; j++ {
+				id, err := a.Allocate()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				if _, ok := seen[id]; ok || id == 0 {
+					t.Errorf("invalid Charging ID allocated: %#x", id)
+				}
+				seen[id] = struct{}{}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+type countingAllocator struct {
+	*v2.MonotonicChargingIDAllocator
+	released []uint32
+}
+
+func (a *countingAllocator) Release(id uint32) {
+	a.released = append(a.released, id)
+	a.MonotonicChargingIDAllocator.Release(id)
+}
+
+func TestChargingIDAllocation(t *testing.T) {
+	pgwAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 62), Port: 2123}
+	sgwAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 63), Port: 2123}
+
+	a := &countingAllocator{MonotonicChargingIDAllocator: v2.NewMonotonicChargingIDAllocator(100, nil)}
+	conn := v2.NewConn(pgwAddr, v2.IFTypeS5S8PGWGTPC, 0)
+	conn.SetChargingIDAllocator(a)
+
+	sess, err := conn.ParseCreateSession(
+		sgwAddr,
+		ie.NewIMSI("123451234567890"),
+		ie.NewFullyQualifiedTEID(v2.IFTypeS5S8PGWGTPC, 0x11111111, "127.0.0.62", ""),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	br := sess.GetDefaultBearer()
+	if br.ChargingID != 101 {
+		t.Errorf("wrong Charging ID. want: %d, got: %d", 101, br.ChargingID)
+	}
+	if got := br.ChargingIDIE().MustChargingID(); got != 101 {
+		t.Errorf("wrong Charging ID IE: %d", got)
+	}
+
+	conn.RemoveSession(sess)
+	if len(a.released) != 1 || a.released[0] != 101 {
+		t.Errorf("Charging ID not released: %v", a.released)
+	}
+}
@@ -0,0 +1,417 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// ErrNoCheckpoint indicates that there is no checkpoint to be restored.
+var ErrNoCheckpoint = errors.New("no checkpoint found")
+
+// checkpointVersion is the version of the format of Checkpoint.
+const checkpointVersion = 1
+
+// Checkpoint is the state of a Conn and a PathManager saved to be restored after
+// the planned restart of the node, so that the sessions survive it.
+//
+// It is encoded in JSON by the CheckpointStore given in this package.
+type Checkpoint struct {
+	Version int       `json:"version"`
+	TakenAt time.Time `json:"taken_at"`
+
+	// RestartCounter and Sequence are the ones of the Conn. The RestartCounter
+	// is restored as it is, so that the peers do not see the planned restart
+	// as the restart of the node.
+	RestartCounter uint8  `json:"restart_counter"`
+	Sequence       uint32 `json:"sequence"`
+
+	// PeerSequences are the Sequence Numbers per peer, which are restored not to
+	// reuse the ones the peers may still remember.
+	PeerSequences []*PeerSequence `json:"peer_sequences,omitempty"`
+
+	Sessions []*SessionCheckpoint `json:"sessions"`
+	Peers    []*PeerCheckpoint    `json:"peers,omitempty"`
+}
+
+// SessionCheckpoint is the state of a Session in Checkpoint.
+type SessionCheckpoint struct {
+	IMSI     string    `json:"imsi"`
+	MSISDN   string    `json:"msisdn,omitempty"`
+	IMEI     string    `json:"imei,omitempty"`
+	Location *Location `json:"location,omitempty"`
+	PeerAddr string    `json:"peer_addr"`
+	Active   bool      `json:"active"`
+
+	// TEIDs are the TEIDs keyed by InterfaceType. The one of the local
+	// interface type of the Conn is used to register the Session on restore.
+	TEIDs   map[uint8]uint32             `json:"teids"`
+	FQCSIDs map[uint8]FQCSID             `json:"fq_csids,omitempty"`
+	Bearers map[string]*BearerCheckpoint `json:"bearers"`
+}
+
+// BearerCheckpoint is the state of a Bearer, including the tunnel endpoints, in
+// Checkpoint.
+type BearerCheckpoint struct {
+	EBI           uint8      `json:"ebi"`
+	APN           string     `json:"apn,omitempty"`
+	SubscriberIP  string     `json:"subscriber_ip,omitempty"`
+	ChargingID    uint32     `json:"charging_id,omitempty"`
+	IncomingTEID  uint32     `json:"incoming_teid,omitempty"`
+	OutgoingTEID  uint32     `json:"outgoing_teid,omitempty"`
+	RemoteAddress string     `json:"remote_address,omitempty"`
+	QoSProfile    QoSProfile `json:"qos"`
+}
+
+// PeerCheckpoint is the state of a PathPeer in Checkpoint.
+type PeerCheckpoint struct {
+	ID             string `json:"id"`
+	CPlaneAddr     string `json:"c_plane_addr,omitempty"`
+	UPlaneAddr     string `json:"u_plane_addr,omitempty"`
+	RestartCounter uint8  `json:"restart_counter"`
+	RestartKnown   bool   `json:"restart_known"`
+}
+
+// TakeCheckpoint returns the Checkpoint of the Sessions on c and the peers
+// supervised by m. m can be nil if PathManager is not used.
+func TakeCheckpoint(c *Conn, m *PathManager) *Checkpoint {
+	cp := &Checkpoint{
+		Version:        checkpointVersion,
+		TakenAt:        time.Now(),
+		RestartCounter: c.RestartCounter,
+		Sequence:       c.SequenceNumber(),
+		PeerSequences:  c.PeerSequences(),
+	}
+
+	for _, sess := range c.Sessions() {
+		cp.Sessions = append(cp.Sessions, newSessionCheckpoint(sess))
+	}
+	sort.Slice(cp.Sessions, func(i, j int) bool { return cp.Sessions[i].IMSI < cp.Sessions[j].IMSI })
+
+	if m != nil {
+		cp.Peers = m.checkpointPeers()
+	}
+	return cp
+}
+
+func newSessionCheckpoint(sess *Session) *SessionCheckpoint {
+	info := sess.Info()
+	sc := &SessionCheckpoint{
+		IMSI:    info.IMSI,
+		MSISDN:  info.MSISDN,
+		IMEI:    info.IMEI,
+		Active:  info.Active,
+		TEIDs:   info.TEIDs,
+		FQCSIDs: info.FQCSIDs,
+		Bearers: map[string]*BearerCheckpoint{},
+	}
+	if info.PeerAddr != nil {
+		sc.PeerAddr = info.PeerAddr.String()
+	}
+	if sess.Subscriber != nil && sess.Location != nil {
+		loc := *sess.Location
+		sc.Location = &loc
+	}
+	for name, br := range info.Bearers {
+		bc := &BearerCheckpoint{
+			EBI:          br.EBI,
+			APN:          br.APN,
+			SubscriberIP: br.SubscriberIP,
+			ChargingID:   br.ChargingID,
+			IncomingTEID: br.IncomingTEID,
+			OutgoingTEID: br.OutgoingTEID,
+			QoSProfile:   br.QoSProfile,
+		}
+		if br.RemoteAddress != nil {
+			bc.RemoteAddress = br.RemoteAddress.String()
+		}
+		sc.Bearers[name] = bc
+	}
+	return sc
+}
+
+// Restore restores the Sessions in Checkpoint to c and the peers to m, which
+// should be done before they start serving. m can be nil if PathManager is not
+// used.
+//
+// RestartCounter of c is set to the one in Checkpoint. The peers are added to m
+// with the restart counters known before the restart, so that the restart of
+// the peers during the downtime is detected on the first Echo Response once m
+// starts running again.
+//
+// The Sessions are registered to c with the TEID of the local interface type,
+// which fires the LifecycleHooks set to c as they are created; the hooks can be
+// used to set up the user plane tunnels of the Bearers again.
+func (cp *Checkpoint) Restore(c *Conn, m *PathManager) error {
+	if cp.Version != checkpointVersion {
+		return fmt.Errorf("unsupported checkpoint version: %d", cp.Version)
+	}
+
+	sessions := make([]*Session, 0, len(cp.Sessions))
+	iteis := make([]uint32, 0, len(cp.Sessions))
+	for _, sc := range cp.Sessions {
+		sess, err := sc.session()
+		if err != nil {
+			return fmt.Errorf("failed to restore session of %s: %w", sc.IMSI, err)
+		}
+		itei, ok := sc.TEIDs[c.localIfType]
+		if !ok {
+			return fmt.Errorf("failed to restore session of %s: %w", sc.IMSI, ErrTEIDNotFound)
+		}
+		sessions = append(sessions, sess)
+		iteis = append(iteis, itei)
+	}
+
+	var peers []*PathPeer
+	if m != nil {
+		for _, pc := range cp.Peers {
+			p, err := pc.peer()
+			if err != nil {
+				return fmt.Errorf("failed to restore peer %s: %w", pc.ID, err)
+			}
+			peers = append(peers, p)
+		}
+	}
+
+	c.mu.Lock()
+	c.RestartCounter = cp.RestartCounter
+	c.sequence = cp.Sequence
+	c.mu.Unlock()
+	for _, ps := range cp.PeerSequences {
+		c.SetPeerSequence(ps)
+	}
+
+	for i, sess := range sessions {
+		for _, fqCSID := range sess.fqCSIDs {
+			c.csidSessionMap.storeFQCSID(fqCSID, sess)
+		}
+		c.RegisterSession(iteis[i], sess)
+	}
+	for _, p := range peers {
+		m.addPeer(p)
+	}
+	return nil
+}
+
+func (sc *SessionCheckpoint) session() (*Session, error) {
+	peer, err := net.ResolveUDPAddr("udp", sc.PeerAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &Subscriber{IMSI: sc.IMSI, MSISDN: sc.MSISDN, IMEI: sc.IMEI, Location: &Location{}}
+	if sc.Location != nil {
+		loc := *sc.Location
+		sub.Location = &loc
+	}
+	sess := NewSession(peer, sub)
+	sess.isActive = sc.Active
+
+	for ifType, teid := range sc.TEIDs {
+		sess.AddTEID(ifType, teid)
+	}
+	for nodeType, fqCSID := range sc.FQCSIDs {
+		csids := make([]uint16, len(fqCSID.CSIDs))
+		copy(csids, fqCSID.CSIDs)
+		sess.setFQCSID(nodeType, &FQCSID{NodeID: fqCSID.NodeID, CSIDs: csids})
+	}
+	for name, bc := range sc.Bearers {
+		qos := bc.QoSProfile
+		br := NewBearer(bc.EBI, bc.APN, &qos)
+		br.SubscriberIP = bc.SubscriberIP
+		br.ChargingID = bc.ChargingID
+		br.SetIncomingTEID(bc.IncomingTEID)
+		br.SetOutgoingTEID(bc.OutgoingTEID)
+		if bc.RemoteAddress != "" {
+			raddr, err := net.ResolveUDPAddr("udp", bc.RemoteAddress)
+			if err != nil {
+				return nil, err
+			}
+			br.SetRemoteAddress(raddr)
+		}
+		sess.AddBearer(name, br)
+	}
+	return sess, nil
+}
+
+func (pc *PeerCheckpoint) peer() (*PathPeer, error) {
+	p := &PathPeer{
+		ID:             pc.ID,
+		restartCounter: pc.RestartCounter,
+		restartKnown:   pc.RestartKnown,
+	}
+	if pc.CPlaneAddr != "" {
+		addr, err := net.ResolveUDPAddr("udp", pc.CPlaneAddr)
+		if err != nil {
+			return nil, err
+		}
+		p.CPlaneAddr = addr
+		p.cPath = &path{}
+	}
+	if pc.UPlaneAddr != "" {
+		addr, err := net.ResolveUDPAddr("udp", pc.UPlaneAddr)
+		if err != nil {
+			return nil, err
+		}
+		p.UPlaneAddr = addr
+		p.uPath = &path{}
+	}
+	return p, nil
+}
+
+// CheckpointStore saves and loads Checkpoint.
+type CheckpointStore interface {
+	// Save saves the Checkpoint, replacing the one saved before.
+	Save(cp *Checkpoint) error
+	// Load returns the Checkpoint saved last, or ErrNoCheckpoint if none.
+	Load() (*Checkpoint, error)
+}
+
+// FileCheckpointStore is a CheckpointStore that saves Checkpoint to a file in
+// JSON. The file is replaced atomically, so that the one saved before is kept
+// if the node crashes while saving.
+type FileCheckpointStore struct {
+	Path string
+}
+
+// NewFileCheckpointStore creates a new FileCheckpointStore that saves Checkpoint
+// to the file at path.
+func NewFileCheckpointStore(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{Path: path}
+}
+
+// Save saves the Checkpoint to the file.
+func (s *FileCheckpointStore) Save(cp *Checkpoint) error {
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(s.Path), filepath.Base(s.Path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), s.Path)
+}
+
+// Load loads the Checkpoint from the file. It returns ErrNoCheckpoint if the
+// file does not exist.
+func (s *FileCheckpointStore) Load() (*Checkpoint, error) {
+	b, err := ioutil.ReadFile(s.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoCheckpoint
+		}
+		return nil, err
+	}
+
+	cp := &Checkpoint{}
+	if err := json.Unmarshal(b, cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// Checkpointer saves the Checkpoint of a Conn and a PathManager to the
+// CheckpointStore periodically and on the signals, and restores it on startup.
+type Checkpointer struct {
+	// Conn and PathManager are the ones to be checkpointed. PathManager can
+	// be nil.
+	Conn        *Conn
+	PathManager *PathManager
+	Store       CheckpointStore
+
+	// Interval is the interval of the periodic checkpoints. No periodic
+	// checkpoint is taken if zero.
+	Interval time.Duration
+
+	// Signals are the signals on which the checkpoint is taken before Run
+	// returns. SIGTERM is used if empty.
+	Signals []os.Signal
+}
+
+// NewCheckpointer creates a new Checkpointer.
+func NewCheckpointer(c *Conn, m *PathManager, store CheckpointStore) *Checkpointer {
+	return &Checkpointer{Conn: c, PathManager: m, Store: store}
+}
+
+// Save takes the Checkpoint and saves it to the store.
+func (ck *Checkpointer) Save() error {
+	return ck.Store.Save(TakeCheckpoint(ck.Conn, ck.PathManager))
+}
+
+// Restore loads the Checkpoint from the store and restores it. This should be
+// called before the Conn and the PathManager start serving.
+//
+// It returns ErrNoCheckpoint if there is no Checkpoint saved, which is the case
+// of the first start of the node and can be ignored.
+func (ck *Checkpointer) Restore() error {
+	cp, err := ck.Store.Load()
+	if err != nil {
+		return err
+	}
+	return cp.Restore(ck.Conn, ck.PathManager)
+}
+
+// Run takes the checkpoints every Interval until ctx is canceled, and takes the
+// last one and returns when one of the Signals is received. The node is expected
+// to exit after Run returns on the signal.
+//
+// The failures of the periodic checkpoints are just logged, while the one of the
+// last checkpoint is returned.
+func (ck *Checkpointer) Run(ctx context.Context) error {
+	signals := ck.Signals
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGTERM}
+	}
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, signals...)
+	defer signal.Stop(sigCh)
+
+	var tick <-chan time.Time
+	if ck.Interval > 0 {
+		ticker := time.NewTicker(ck.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick:
+			if err := ck.Save(); err != nil {
+				logf("failed to save checkpoint: %v", err)
+			}
+		case <-sigCh:
+			return ck.Save()
+		}
+	}
+}
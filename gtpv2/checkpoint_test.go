@@ -0,0 +1,201 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/wmnsk/go-gtp/gtptest"
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+func newCheckpointStore(t *testing.T) *v2.FileCheckpointStore {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return v2.NewFileCheckpointStore(filepath.Join(dir, "checkpoint.json"))
+}
+
+func TestCheckpoint(t *testing.T) {
+	mmeAddr := &net.UDPAddr{IP: net.IP{127, 0, 0, 41}, Port: 2123}
+	sgwAddr := &net.UDPAddr{IP: net.IP{127, 0, 0, 42}, Port: 2123}
+	pgwAddr := &net.UDPAddr{IP: net.IP{127, 0, 0, 43}, Port: 2123}
+
+	conn := v2.NewConn(sgwAddr, v2.IFTypeS11S4SGWGTPC, 3)
+	conn.IncSequence()
+	conn.IncSequence()
+	conn.SetPeerSequence(&v2.PeerSequence{Peer: mmeAddr.String(), Request: 10, Command: 0x800003})
+
+	sess := v2.NewSession(mmeAddr, &v2.Subscriber{
+		IMSI: "001010000000001", MSISDN: "819012345678", IMEI: "123450123456789",
+		Location: &v2.Location{MCC: "001", MNC: "01", RATType: v2.RATTypeEUTRAN, TAI: 1},
+	})
+	sess.AddTEID(v2.IFTypeS11MMEGTPC, 0x11111111)
+	sess.AddTEID(v2.IFTypeS1UeNodeBGTPU, 0x22222222)
+	br := sess.GetDefaultBearer()
+	br.EBI = 5
+	br.APN = "internet"
+	br.SubscriberIP = "10.45.0.1"
+	br.ChargingID = 1
+	br.QCI = 9
+	br.SetIncomingTEID(0x33333333)
+	br.SetOutgoingTEID(0x22222222)
+	br.SetRemoteAddress(&net.UDPAddr{IP: net.IP{127, 0, 1, 1}, Port: 2152})
+	conn.RegisterSession(0x44444444, sess)
+	if err := conn.SetSessionFQCSID(sess, v2.CSIDNodeMME, ie.NewFullyQualifiedCSID("127.0.0.41", 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sess.Activate(); err != nil {
+		t.Fatal(err)
+	}
+
+	pm := v2.NewPathManager(time.Second, 3)
+	pm.AddPeer("pgw", pgwAddr, nil)
+
+	store := newCheckpointStore(t)
+	if err := v2.NewCheckpointer(conn, pm, store).Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	restoredConn := v2.NewConn(sgwAddr, v2.IFTypeS11S4SGWGTPC, 0)
+	restoredPM := v2.NewPathManager(time.Second, 3)
+	if err := v2.NewCheckpointer(restoredConn, restoredPM, store).Restore(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := restoredConn.RestartCounter, uint8(3); got != want {
+		t.Errorf("wrong RestartCounter: got %d, want %d", got, want)
+	}
+	if got, want := restoredConn.SequenceNumber(), uint32(2); got != want {
+		t.Errorf("wrong SequenceNumber: got %d, want %d", got, want)
+	}
+	if diff := cmp.Diff(conn.PeerSequences(), restoredConn.PeerSequences()); diff != "" {
+		t.Errorf("wrong PeerSequences:\n%s", diff)
+	}
+
+	restored, err := restoredConn.GetSessionByTEID(0x44444444, mmeAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(restored.Info(), sess.Info()); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff(restored.Location, sess.Location); diff != "" {
+		t.Error(diff)
+	}
+	if got := restoredConn.GetSessionsByCSID("127.0.0.41", 1); len(got) != 1 || got[0] != restored {
+		t.Errorf("session not restored with FQ-CSID: %v", got)
+	}
+	if _, ok := restoredPM.Peer("pgw"); !ok {
+		t.Error("peer not restored")
+	}
+}
+
+func TestCheckpointPeerRestart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	network := gtptest.NewNetwork()
+	listen := func(addr string, counter uint8) *v2.Conn {
+		pc, err := network.ListenPacket("udp", addr+v2.GTPCPort)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := v2.NewConnWithPacketConn(pc, v2.IFTypeS5S8SGWGTPC, counter)
+		t.Cleanup(func() { c.Close() })
+		return c
+	}
+	localConn := listen("127.0.0.44", 0)
+	peerConn := listen("127.0.0.45", 2)
+
+	// the peer had the restart counter 1 when the checkpoint was taken, and
+	// restarted while the local node was down.
+	cp := &v2.Checkpoint{
+		Version: 1,
+		Peers: []*v2.PeerCheckpoint{{
+			ID: "pgw", CPlaneAddr: "127.0.0.45" + v2.GTPCPort, RestartCounter: 1, RestartKnown: true,
+		}},
+	}
+	store := newCheckpointStore(t)
+	if err := store.Save(cp); err != nil {
+		t.Fatal(err)
+	}
+
+	pm := v2.NewPathManager(20*time.Millisecond, 3)
+	pm.RegisterConn(localConn)
+	restartCh := make(chan uint8, 1)
+	pm.SetPeerRestartHandler(func(peer *v2.PathPeer, counter uint8) {
+		restartCh <- counter
+	})
+	if err := v2.NewCheckpointer(localConn, pm, store).Restore(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []*v2.Conn{localConn, peerConn} {
+		go func(c *v2.Conn) {
+			if err := c.ListenAndServe(ctx); err != nil {
+				t.Log(err)
+			}
+		}(c)
+	}
+	go func() {
+		if err := pm.Run(ctx); err != nil {
+			t.Log(err)
+		}
+	}()
+
+	select {
+	case got := <-restartCh:
+		if got != 2 {
+			t.Errorf("wrong restart counter: got %d, want 2", got)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("restart of peer during the downtime not detected")
+	}
+}
+
+func TestCheckpointer(t *testing.T) {
+	store := newCheckpointStore(t)
+	conn := v2.NewConn(&net.UDPAddr{IP: net.IP{127, 0, 0, 46}, Port: 2123}, v2.IFTypeS11S4SGWGTPC, 0)
+	ck := v2.NewCheckpointer(conn, nil, store)
+
+	if err := ck.Restore(); !errors.Is(err, v2.ErrNoCheckpoint) {
+		t.Fatalf("want ErrNoCheckpoint, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ck.Interval = 10 * time.Millisecond
+	done := make(chan error)
+	go func() { done <- ck.Run(ctx) }()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if _, err := store.Load(); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no periodic checkpoint taken")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
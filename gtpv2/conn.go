@@ -15,6 +15,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/wmnsk/go-gtp/gtpcap"
 	"github.com/wmnsk/go-gtp/gtpv2/ie"
 	"github.com/wmnsk/go-gtp/gtpv2/message"
 )
@@ -33,6 +34,7 @@ type Conn struct {
 	pktConn net.PacketConn
 	*imsiSessionMap
 	*iteiSessionMap
+	*csidSessionMap
 	localIfType uint8
 
 	validationEnabled bool
@@ -40,6 +42,67 @@ type Conn struct {
 	closeCh chan struct{}
 	*msgHandlerMap
 
+	// partialFailureHandler is called when the Sessions are affected by
+	// Delete/Update PDN Connection Set Request.
+	partialFailureHandler PartialFailureHandlerFunc
+
+	// hooks is called on the lifecycle events of Sessions and Bearers.
+	// pendingTriggers keeps Create Session Requests being handled, keyed by IMSI,
+	// to be passed to the hooks when the Session is registered.
+	hooks           *LifecycleHooks
+	pendingTriggers sync.Map
+
+	// expiryStopCh is closed to stop the expiry of idle Sessions.
+	expiryStopCh chan struct{}
+
+	// sendQueue is the queue of outbound packets, enabled with EnableSendQueue.
+	sendQueue chan *outboundPacket
+
+	// sockOpts is applied to the socket opened by ListenAndServe.
+	sockOpts *SocketOptions
+
+	// captureHook is called with the packets sent and received.
+	captureHook gtpcap.Hook
+
+	// tracer traces the messages sent and received. pendingTxs keeps the requests
+	// waiting for the response, and handlerCtxs keeps the contexts for the messages
+	// being handled.
+	tracer        Tracer
+	tracerTimeout time.Duration
+	pendingTxs    sync.Map
+	handlerCtxs   sync.Map
+
+	// versionFallback is called when a peer responds with Version Not Supported
+	// Indication, and v2UnsupportedPeers keeps such peers.
+	versionFallback    VersionFallbackFunc
+	v2UnsupportedPeers sync.Map
+
+	// errorResponseEnabled enables the automatic error responses to the invalid requests,
+	// and handlingRequests keeps whether the requests being handled are responded.
+	errorResponseEnabled bool
+	handlingRequests     sync.Map
+
+	// respCache keeps the responses to suppress the retransmitted requests, enabled
+	// with EnableResponseCache.
+	respCache *responseCache
+
+	// paths keeps the PathInfo measured with Echo, and pathInfoFn is called when
+	// it is updated.
+	paths      pathStats
+	pathInfoFn PathInfoFunc
+
+	// overload keeps the overload information received from the peers, enabled with
+	// EnableOverloadControl.
+	overload *overloadControl
+
+	// chargingIDAllocator allocates the Charging IDs to the bearers if set.
+	chargingIDAllocator ChargingIDAllocator
+
+	// peerPolicy decides whether to accept the packets from a peer, and
+	// rejectedPackets counts the packets not accepted.
+	peerPolicy      *PeerPolicy
+	rejectedPackets uint64
+
 	// sequence is the last SequenceNumber used in the request.
 	//
 	// TS29.274 7.6  Reliable Delivery of Signalling Messages;
@@ -47,6 +110,11 @@ type Conn struct {
 	// from the same IP/UDP endpoint(=Conn).
 	sequence uint32
 
+	// seqSpaces is the spaces of SequenceNumber per peer, from which the Sequence
+	// Numbers in the requests are allocated. seqTick orders them by the last use.
+	seqSpaces map[string]*sequenceSpace
+	seqTick   uint64
+
 	// RestartCounter is the RestartCounter value in Recovery IE, which represents how many
 	// times the GTPv2-C endpoint is restarted.
 	RestartCounter uint8
@@ -59,15 +127,28 @@ func NewConn(laddr net.Addr, localIfType, counter uint8) *Conn {
 		laddr:             laddr,
 		imsiSessionMap:    newimsiSessionMap(),
 		iteiSessionMap:    newiteiSessionMap(),
+		csidSessionMap:    newcsidSessionMap(),
 		localIfType:       localIfType,
 		validationEnabled: true,
 		closeCh:           make(chan struct{}),
-		msgHandlerMap:     defaultHandlerMap,
+		msgHandlerMap:     newDefaultMsgHandlerMap(),
 		sequence:          0,
 		RestartCounter:    counter,
 	}
 }
 
+// NewConnWithPacketConn creates a new Conn over the net.PacketConn given, instead of
+// opening a UDP socket by itself. The local address is taken from pc.
+//
+// This is useful to use the transport other than the UDP socket provided by the
+// standard library(e.g., userspace network stacks), or the socket prepared with
+// some custom options. ListenAndServe should be called to start serving as usual.
+func NewConnWithPacketConn(pc net.PacketConn, localIfType, counter uint8) *Conn {
+	c := NewConn(pc.LocalAddr(), localIfType, counter)
+	c.pktConn = pc
+	return c
+}
+
 // Dial sends Echo Request to raddr to check if the endpoint is alive and returns Conn.
 //
 // It does not bind the raddr to the underlying connection, which enables a Conn to
@@ -75,14 +156,21 @@ func NewConn(laddr net.Addr, localIfType, counter uint8) *Conn {
 //
 // If Echo exchange is unnecessary, use NewConn and ListenAndServe instead.
 func Dial(ctx context.Context, laddr, raddr net.Addr, localIfType, counter uint8) (*Conn, error) {
+	return DialWithSocketOptions(ctx, laddr, raddr, localIfType, counter, nil)
+}
+
+// DialWithSocketOptions is the same as Dial, but applies the SocketOptions given to
+// the underlying socket.
+func DialWithSocketOptions(ctx context.Context, laddr, raddr net.Addr, localIfType, counter uint8, opts *SocketOptions) (*Conn, error) {
 	c := &Conn{
 		mu:                sync.Mutex{},
 		imsiSessionMap:    newimsiSessionMap(),
 		iteiSessionMap:    newiteiSessionMap(),
+		csidSessionMap:    newcsidSessionMap(),
 		localIfType:       localIfType,
 		validationEnabled: true,
 		closeCh:           make(chan struct{}),
-		msgHandlerMap:     defaultHandlerMap,
+		msgHandlerMap:     newDefaultMsgHandlerMap(),
 		sequence:          0,
 		RestartCounter:    counter,
 	}
@@ -91,7 +179,7 @@ func Dial(ctx context.Context, laddr, raddr net.Addr, localIfType, counter uint8
 	// not using net.Dial, as it binds src/dst IP:Port, which makes it harder to
 	// handle multiple connections with a Conn.
 	var err error
-	c.pktConn, err = net.ListenPacket(raddr.Network(), laddr.String())
+	c.pktConn, err = ListenPacket(raddr.Network(), laddr.String(), opts)
 	if err != nil {
 		return nil, err
 	}
@@ -120,7 +208,7 @@ func Dial(ctx context.Context, laddr, raddr net.Addr, localIfType, counter uint8
 	if err != nil {
 		return nil, err
 	}
-	if err := c.handleMessage(raddr, msg); err != nil {
+	if err := c.handleMessage(raddr, buf[:n], msg); err != nil {
 		return nil, err
 	}
 
@@ -133,16 +221,31 @@ func Dial(ctx context.Context, laddr, raddr net.Addr, localIfType, counter uint8
 }
 
 // ListenAndServe creates a new GTPv2-C Conn and start serving background.
+//
+// If the Conn is created with NewConnWithPacketConn, it just starts serving over the
+// net.PacketConn given.
 func (c *Conn) ListenAndServe(ctx context.Context) error {
-	var err error
-	c.pktConn, err = net.ListenPacket(c.laddr.Network(), c.laddr.String())
-	if err != nil {
-		return err
+	if c.pktConn == nil {
+		var err error
+		c.pktConn, err = ListenPacket(c.laddr.Network(), c.laddr.String(), c.sockOpts)
+		if err != nil {
+			return err
+		}
 	}
 
 	return c.listenAndServe(ctx)
 }
 
+// SetSocketOptions sets the SocketOptions applied to the socket opened by
+// ListenAndServe. This should be called before ListenAndServe, and has no effect
+// on the Conn created with NewConnWithPacketConn.
+func (c *Conn) SetSocketOptions(opts *SocketOptions) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sockOpts = opts
+}
+
 func (c *Conn) listenAndServe(ctx context.Context) error {
 	// TODO: this func is left for future enhancement.
 	return c.serve(ctx)
@@ -174,14 +277,27 @@ func (c *Conn) serve(ctx context.Context) error {
 
 		raw := make([]byte, n)
 		copy(raw, buf)
+		c.capture(raw, raddr, c.LocalAddr())
+		if !c.acceptsPeer(raddr, raw) {
+			continue
+		}
+		if !c.checkVersion(raddr, raw) {
+			continue
+		}
+
 		go func() {
 			msg, err := message.Parse(raw)
 			if err != nil {
 				logf("error parsing the message: %v, %x", err, raw)
+				if c.errorResponseIsEnabled() {
+					if err := c.respondToMalformed(raddr, raw, err); err != nil {
+						logf("failed to respond to the malformed message: %s", err)
+					}
+				}
 				return
 			}
 
-			if err := c.handleMessage(raddr, msg); err != nil {
+			if err := c.handleMessage(raddr, raw, msg); err != nil {
 				logf("error handling message on Conn %s: %s", c.LocalAddr(), err)
 			}
 		}()
@@ -207,17 +323,28 @@ func (c *Conn) ReadFrom(p []byte) (n int, addr net.Addr, err error) {
 // an Error with Timeout() == true after a fixed time limit;
 // see SetDeadline and SetWriteDeadline.
 // On packet-oriented connections, write timeouts are rare.
+//
+// If the queue is enabled with EnableSendQueue, the packet is just queued.
 func (c *Conn) WriteTo(p []byte, addr net.Addr) (n int, err error) {
-	return c.pktConn.WriteTo(p, addr)
+	if q := c.loadSendQueue(); q != nil {
+		n, err = c.enqueue(q, p, addr)
+	} else {
+		n, err = c.pktConn.WriteTo(p, addr)
+	}
+	if err == nil {
+		c.capture(p, c.LocalAddr(), addr)
+	}
+	return n, err
 }
 
 // Close closes the connection.
 // Any blocked Read or Write operations will be unblocked and return errors.
+//
+// The handlers added with AddHandler are kept as they are.
 func (c *Conn) Close() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.msgHandlerMap = defaultHandlerMap
 	c.RestartCounter = 0
 	close(c.closeCh)
 
@@ -298,20 +425,55 @@ func (c *Conn) AddHandlers(funcs map[uint8]HandlerFunc) {
 	}
 }
 
-func (c *Conn) handleMessage(senderAddr net.Addr, msg message.Message) error {
+func (c *Conn) handleMessage(senderAddr net.Addr, raw []byte, msg message.Message) error {
+	if c.suppressDuplicate(senderAddr, msg) {
+		return nil
+	}
+
+	errorResponseEnabled := c.errorResponseIsEnabled()
 	if c.validationEnabled {
 		if err := c.validate(senderAddr, msg); err != nil {
 			logf("failed to validate a message: %s", err)
+			if errorResponseEnabled && c.respondToInvalid(senderAddr, msg, err) {
+				return nil
+			}
 		}
 	}
 
+	c.updateOverload(senderAddr, raw, msg)
+	c.recordResponseFQCSIDs(senderAddr, msg)
+	if res, ok := msg.(*message.EchoResponse); ok {
+		c.pathEchoReceived(senderAddr, res)
+	}
+
+	if teid := msg.TEID(); teid != 0 {
+		if sess, ok := c.iteiSessionMap.load(teid); ok && sess != nil {
+			sess.Touch()
+		}
+	}
+
+	done := c.setTrigger(msg)
+	defer done()
+
+	end := c.startHandlerSpan(senderAddr, msg)
+
 	handle, ok := c.msgHandlerMap.load(msg.MessageType())
 	if !ok {
-		logf("%v", &HandlerNotFoundError{MsgType: msg.MessageTypeName()})
+		err := &HandlerNotFoundError{MsgType: msg.MessageTypeName()}
+		logf("%v", err)
+		end(err)
+		return nil
 	}
-	if err := handle(c, senderAddr, msg); err != nil {
+	stopTracking := c.trackResponse(senderAddr, msg)
+	err := handle(c, senderAddr, msg)
+	responded := stopTracking()
+	if err != nil {
 		logf("failed to handle message %s: %s", msg, err)
+		if errorResponseEnabled && !responded {
+			c.respondToInvalid(senderAddr, msg, err)
+		}
 	}
+	end(err)
 
 	return nil
 }
@@ -350,7 +512,7 @@ func (c *Conn) validate(senderAddr net.Addr, msg message.Message) error {
 		if err := c.VersionNotSupportedIndication(senderAddr, msg); err != nil {
 			return errors.Errorf("failed to respond with VersionNotSupportedIndication: %s", err)
 		}
-		return errors.Errorf("received an invalid version(%d) of message: %v", msg.Version(), msg)
+		return &InvalidVersionError{Version: msg.Version()}
 	}
 
 	// check if TEID is known or not
@@ -363,25 +525,51 @@ func (c *Conn) validate(senderAddr net.Addr, msg message.Message) error {
 }
 
 // SendMessageTo sends a message to addr.
-// Unlike WriteTo, it sets the Sequence Number properly and returns the one used in the message.
+// Unlike WriteTo, it sets the Sequence Number properly and returns the one used in the message,
+// or 0 if it fails to send.
 func (c *Conn) SendMessageTo(msg message.Message, addr net.Addr) (uint32, error) {
-	seq := c.IncSequence()
+	return c.sendMessageTo(context.Background(), msg, addr, nil)
+}
+
+// SendMessageToContext is the same as SendMessageTo, but the Span for the request
+// is started as a child of the one in ctx, if tracing is enabled with SetTracer.
+func (c *Conn) SendMessageToContext(ctx context.Context, msg message.Message, addr net.Addr) (uint32, error) {
+	return c.sendMessageTo(ctx, msg, addr, nil)
+}
+
+// sendMessageTo sends a message to addr. sess is the Session the message is for,
+// which is used only for tracing and can be nil.
+func (c *Conn) sendMessageTo(ctx context.Context, msg message.Message, addr net.Addr, sess *Session) (uint32, error) {
+	if c.throttleOverload(addr, msg) {
+		return 0, ErrThrottledByOverload
+	}
+
+	seq := c.nextSequence(addr, msg.MessageType())
 	msg.SetSequenceNumber(seq)
 
 	payload, err := message.Marshal(msg)
 	if err != nil {
-		seq = c.DecSequence()
-		return seq, errors.Wrapf(err, "failed to send %T", msg)
+		c.releaseSequence(addr, msg.MessageType(), seq)
+		return 0, errors.Wrapf(err, "failed to send %T", msg)
 	}
 
+	// start tracing before sending, not to miss the response coming quickly.
+	abort := c.startClientSpan(ctx, msg, addr, sess)
 	if _, err := c.WriteTo(payload, addr); err != nil {
-		seq = c.DecSequence()
-		return seq, errors.Wrapf(err, "failed to send %T", msg)
+		abort(err)
+		c.releaseSequence(addr, msg.MessageType(), seq)
+		return 0, errors.Wrapf(err, "failed to send %T", msg)
+	}
+	if msg.MessageType() == message.MsgTypeEchoRequest {
+		c.pathEchoSent(addr, seq)
 	}
 	return seq, nil
 }
 
 // IncSequence increments the SequenceNumber associated with Conn.
+//
+// Note that the Sequence Numbers in the requests sent from Conn are allocated from
+// the spaces per peer, not from this value. See PeerSequence.
 func (c *Conn) IncSequence() uint32 {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -404,7 +592,8 @@ func (c *Conn) DecSequence() uint32 {
 	return c.sequence
 }
 
-// SequenceNumber returns the current(=last used) SequenceNumber associated with Conn.
+// SequenceNumber returns the current(=last used) SequenceNumber associated with Conn,
+// which is the one used in the last request sent toward any peer.
 func (c *Conn) SequenceNumber() uint32 {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -502,6 +691,23 @@ func (c *Conn) ParseCreateSession(raddr net.Addr, IEs ...*ie.IE) (*Session, erro
 			if it == c.localIfType {
 				c.RegisterSession(teid, sess)
 			}
+		case ie.FullyQualifiedCSID:
+			var nodeType uint8
+			switch i.Instance() {
+			case 0:
+				nodeType = CSIDNodeMME
+			case 1:
+				nodeType = CSIDNodeSGW
+			case 2:
+				nodeType = CSIDNodeEPDG
+			case 3:
+				nodeType = CSIDNodeTWAN
+			default:
+				continue
+			}
+			if err := c.SetSessionFQCSID(sess, nodeType, i); err != nil {
+				return nil, err
+			}
 		case ie.BearerContext:
 			switch i.Instance() {
 			case 0:
@@ -559,6 +765,10 @@ func (c *Conn) ParseCreateSession(raddr net.Addr, IEs ...*ie.IE) (*Session, erro
 			}
 		}
 	}
+
+	if err := c.allocateChargingID(br); err != nil {
+		return nil, err
+	}
 	return sess, nil
 }
 
@@ -581,14 +791,18 @@ func (c *Conn) ParseCreateSession(raddr net.Addr, IEs ...*ie.IE) (*Session, erro
 // this method.
 func (c *Conn) CreateSession(raddr net.Addr, ie ...*ie.IE) (*Session, uint32, error) {
 
+	// set IEs into CreateSessionRequest.
+	msg := message.NewCreateSessionRequest(0, 0, ie...)
+
+	// let the LifecycleHooks get the request when the Session is registered.
+	done := c.setTrigger(msg)
+	defer done()
+
 	sess, err := c.ParseCreateSession(raddr, ie...)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	// set IEs into CreateSessionRequest.
-	msg := message.NewCreateSessionRequest(0, 0, ie...)
-
 	seq, err := c.SendMessageTo(msg, raddr)
 	if err != nil {
 		return nil, 0, err
@@ -600,7 +814,7 @@ func (c *Conn) CreateSession(raddr net.Addr, ie ...*ie.IE) (*Session, uint32, er
 func (c *Conn) DeleteSession(teid uint32, sess *Session, ie ...*ie.IE) (uint32, error) {
 	msg := message.NewDeleteSessionRequest(teid, 0, ie...)
 
-	seq, err := c.SendMessageTo(msg, sess.peerAddr)
+	seq, err := c.sendMessageTo(context.Background(), msg, sess.peerAddr, sess)
 	if err != nil {
 		return 0, err
 	}
@@ -611,18 +825,303 @@ func (c *Conn) DeleteSession(teid uint32, sess *Session, ie ...*ie.IE) (uint32,
 func (c *Conn) ModifyBearer(teid uint32, sess *Session, ie ...*ie.IE) (uint32, error) {
 	msg := message.NewModifyBearerRequest(teid, 0, ie...)
 
-	seq, err := c.SendMessageTo(msg, sess.peerAddr)
+	seq, err := c.sendMessageTo(context.Background(), msg, sess.peerAddr, sess)
 	if err != nil {
 		return 0, err
 	}
 	return seq, nil
 }
 
+// ModifyBearerWithWait sends a ModifyBearerRequest with TEID and IEs given, and waits
+// for the ModifyBearerResponse to be passed to the Session for certain period of time
+// specified by timeout.
+//
+// On success, the F-TEID of the user plane peer contained in the Bearer Contexts to be
+// modified(e.g., S1-U eNodeB F-TEID on S11) is set as the outgoing TEID and remote address
+// of the Bearer looked up by EBI, and all the F-TEIDs in both request and response are
+// stored in the Session with their interface types. If the Cause in the response is not acceptance, it
+// returns *CauseNotOKError with the response received.
+//
+// The ModifyBearerResponse is passed to the Session by the default handler. If the
+// handler for ModifyBearerResponse is overridden by AddHandler, it should pass the
+// message to the Session with PassMessageTo, otherwise this always times out.
+func (c *Conn) ModifyBearerWithWait(teid uint32, sess *Session, timeout time.Duration, ies ...*ie.IE) (*message.ModifyBearerResponse, error) {
+	seq, err := c.ModifyBearer(teid, sess, ies...)
+	if err != nil {
+		return nil, err
+	}
+
+	incomingMsg, err := sess.WaitMessage(seq, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	res, ok := incomingMsg.(*message.ModifyBearerResponse)
+	if !ok {
+		return nil, &UnexpectedTypeError{Msg: incomingMsg}
+	}
+
+	if err := checkCause(res, res.Cause); err != nil {
+		return res, err
+	}
+
+	var modified []*Bearer
+	for _, i := range ies {
+		if i == nil || i.Type != ie.BearerContext || i.Instance() != 0 {
+			continue
+		}
+		br, err := sess.lookupBearerByContext(i)
+		if err != nil {
+			return res, err
+		}
+		if err := sess.updateBearerFTEIDs(i, br, c.peerUPlaneIFTypes()); err != nil {
+			return res, err
+		}
+		modified = append(modified, br)
+	}
+	if brCtxIE := res.BearerContextsModified; brCtxIE != nil {
+		if err := sess.updateBearerFTEIDs(brCtxIE, nil, nil); err != nil {
+			return res, err
+		}
+	}
+
+	done := sess.setTrigger(res)
+	defer done()
+	for _, br := range modified {
+		sess.fireBearerEvent(func(h *LifecycleHooks) BearerHookFunc { return h.OnBearerModified }, br)
+	}
+
+	return res, nil
+}
+
 // DeleteBearer sends a DeleteBearerRequest TEID and with IEs given.
 func (c *Conn) DeleteBearer(teid uint32, sess *Session, ie ...*ie.IE) (uint32, error) {
 	msg := message.NewDeleteBearerRequest(teid, 0, ie...)
 
-	seq, err := c.SendMessageTo(msg, sess.peerAddr)
+	seq, err := c.sendMessageTo(context.Background(), msg, sess.peerAddr, sess)
+	if err != nil {
+		return 0, err
+	}
+	return seq, nil
+}
+
+// CreateBearer sends a CreateBearerRequest with TEID and IEs given.
+func (c *Conn) CreateBearer(teid uint32, sess *Session, ie ...*ie.IE) (uint32, error) {
+	msg := message.NewCreateBearerRequest(teid, 0, ie...)
+
+	seq, err := c.sendMessageTo(context.Background(), msg, sess.peerAddr, sess)
+	if err != nil {
+		return 0, err
+	}
+	return seq, nil
+}
+
+// CreateBearerWithWait establishes a dedicated bearer by sending a CreateBearerRequest
+// and waiting for the CreateBearerResponse to be passed to the Session for certain
+// period of time specified by timeout.
+//
+// The Bearer Context in the request is built from the QoSProfile of br, tft and the
+// fTEIDs given. EBI is set to 0 and the default bearer of sess is used as Linked EBI.
+// On success, the EBI granted by the peer is set to br, and br is added to sess with
+// the name given. The first F-TEID given is set as the incoming TEID of br, and the
+// F-TEID of the user plane peer in the response(e.g., S5/S8-U SGW F-TEID when Conn is
+// S5/S8 PGW) is set as the outgoing TEID and remote address of br.
+// If ChargingIDAllocator is set with SetChargingIDAllocator and br does not have
+// Charging ID, a new one is allocated and put in the Bearer Context.
+//
+// If the Cause in the response is not acceptance, it returns *CauseNotOKError and br
+// is not added to sess.
+//
+// The CreateBearerResponse is passed to the Session by the default handler. If the
+// handler for CreateBearerResponse is overridden by AddHandler, it should pass the
+// message to the Session with PassMessageTo, otherwise this always times out.
+func (c *Conn) CreateBearerWithWait(teid uint32, sess *Session, name string, br *Bearer, tft *ie.IE, timeout time.Duration, fTEIDs ...*ie.IE) (res *message.CreateBearerResponse, err error) {
+	if br.QoSProfile == nil {
+		return nil, &RequiredParameterMissingError{"QoSProfile", "Bearer must have QoSProfile set"}
+	}
+	for _, fteid := range fTEIDs {
+		if fteid == nil {
+			continue
+		}
+		teidIn, err := fteid.TEID()
+		if err != nil {
+			return nil, err
+		}
+		br.SetIncomingTEID(teidIn)
+		break
+	}
+
+	if br.ChargingID == 0 {
+		if err := c.allocateChargingID(br); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err != nil {
+				c.releaseChargingID(br)
+				br.ChargingID = 0
+			}
+		}()
+	}
+
+	brCtxIE := ie.NewBearerContext(
+		append([]*ie.IE{ie.NewEPSBearerID(0), tft, newBearerQoSIE(br.QoSProfile), br.ChargingIDIE()}, fTEIDs...)...,
+	)
+
+	seq, err := c.CreateBearer(
+		teid, sess, ie.NewEPSBearerID(sess.GetDefaultBearer().EBI), brCtxIE,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	incomingMsg, err := sess.WaitMessage(seq, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	res, ok := incomingMsg.(*message.CreateBearerResponse)
+	if !ok {
+		return nil, &UnexpectedTypeError{Msg: incomingMsg}
+	}
+
+	if err := checkCause(res, res.Cause); err != nil {
+		return res, err
+	}
+	if res.BearerContexts == nil {
+		return res, &RequiredIEMissingError{Type: ie.BearerContext}
+	}
+	if causeIE, err := res.BearerContexts.FindByType(ie.Cause, 0); err == nil {
+		if err := checkCause(res, causeIE); err != nil {
+			return res, err
+		}
+	}
+
+	ebiIE, err := res.BearerContexts.FindByType(ie.EPSBearerID, 0)
+	if err != nil {
+		return res, &RequiredIEMissingError{Type: ie.EPSBearerID}
+	}
+	br.EBI, err = ebiIE.EPSBearerID()
+	if err != nil {
+		return res, err
+	}
+
+	if err := sess.updateBearerFTEIDs(res.BearerContexts, br, c.peerUPlaneIFTypes()); err != nil {
+		return res, err
+	}
+
+	done := sess.setTrigger(res)
+	defer done()
+	sess.AddBearer(name, br)
+
+	return res, nil
+}
+
+// ParseCreateBearerRequest retrieves the values in the Bearer Context of the
+// CreateBearerRequest given and returns them as a new Bearer.
+//
+// The QoSProfile is set from the Bearer QoS, and the F-TEID of the user plane peer
+// (e.g., S5/S8-U PGW F-TEID when Conn is S5/S8 SGW) is set as the outgoing TEID and
+// remote address of the Bearer. The F-TEIDs are also stored in sess
+// with their interface types. The EBI is left as it is in the request(typically 0),
+// and the Bearer is not added to sess until responded with CreateBearerResponse.
+func (c *Conn) ParseCreateBearerRequest(sess *Session, req *message.CreateBearerRequest) (*Bearer, error) {
+	if req.BearerContexts == nil {
+		return nil, &RequiredIEMissingError{Type: ie.BearerContext}
+	}
+
+	br := &Bearer{QoSProfile: &QoSProfile{}}
+	for _, child := range req.BearerContexts.ChildIEs {
+		switch child.Type {
+		case ie.EPSBearerID:
+			ebi, err := child.EPSBearerID()
+			if err != nil {
+				return nil, err
+			}
+			br.EBI = ebi
+		case ie.BearerQoS:
+			qos, err := parseBearerQoSIE(child)
+			if err != nil {
+				return nil, err
+			}
+			br.QoSProfile = qos
+		}
+	}
+	if err := sess.updateBearerFTEIDs(req.BearerContexts, br, c.peerUPlaneIFTypes()); err != nil {
+		return nil, err
+	}
+
+	return br, nil
+}
+
+// CreateBearerResponse sends a CreateBearerResponse with TEID given in response to the
+// CreateBearerRequest given.
+//
+// The Bearer Context in the response is built from the EBI assigned to br, cause and
+// fTEIDs given. If the cause is acceptance, br is added to sess with the name given, and
+// the first F-TEID given is set as the incoming TEID of br. If the EBI of br is 0 at that
+// time, a new one is allocated with (*Session).AllocateEBI.
+func (c *Conn) CreateBearerResponse(teid uint32, sess *Session, req *message.CreateBearerRequest, name string, br *Bearer, cause uint8, fTEIDs ...*ie.IE) error {
+	var allocated bool
+	if cause == CauseRequestAccepted && br.EBI == 0 {
+		ebi, err := sess.AllocateEBI()
+		if err != nil {
+			return err
+		}
+		br.EBI = ebi
+		allocated = true
+	}
+
+	brCtxIE := ie.NewBearerContext(
+		append([]*ie.IE{ie.NewEPSBearerID(br.EBI), ie.NewCause(cause, 0, 0, 0, nil)}, fTEIDs...)...,
+	)
+	res := message.NewCreateBearerResponse(
+		teid, 0, ie.NewCause(cause, 0, 0, 0, nil), brCtxIE,
+	)
+
+	if err := c.RespondTo(sess.peerAddr, req, res); err != nil {
+		if allocated {
+			sess.ReleaseEBI(br.EBI)
+			br.EBI = 0
+		}
+		return err
+	}
+
+	if cause != CauseRequestAccepted {
+		return nil
+	}
+	for _, fteid := range fTEIDs {
+		if fteid == nil {
+			continue
+		}
+		teidIn, err := fteid.TEID()
+		if err != nil {
+			return err
+		}
+		br.SetIncomingTEID(teidIn)
+		break
+	}
+	sess.AddBearer(name, br)
+	return nil
+}
+
+// CreateIndirectDataForwardingTunnel sends a CreateIndirectDataForwardingTunnelRequest
+// with TEID and IEs given.
+func (c *Conn) CreateIndirectDataForwardingTunnel(teid uint32, sess *Session, ie ...*ie.IE) (uint32, error) {
+	msg := message.NewCreateIndirectDataForwardingTunnelRequest(teid, 0, ie...)
+
+	seq, err := c.sendMessageTo(context.Background(), msg, sess.peerAddr, sess)
+	if err != nil {
+		return 0, err
+	}
+	return seq, nil
+}
+
+// DeleteIndirectDataForwardingTunnel sends a DeleteIndirectDataForwardingTunnelRequest
+// with TEID and IEs given.
+func (c *Conn) DeleteIndirectDataForwardingTunnel(teid uint32, sess *Session, ie ...*ie.IE) (uint32, error) {
+	msg := message.NewDeleteIndirectDataForwardingTunnelRequest(teid, 0, ie...)
+
+	seq, err := c.sendMessageTo(context.Background(), msg, sess.peerAddr, sess)
 	if err != nil {
 		return 0, err
 	}
@@ -640,6 +1139,8 @@ func (c *Conn) RespondTo(raddr net.Addr, received, toBeSent message.Message) err
 	if err := toBeSent.MarshalTo(b); err != nil {
 		return err
 	}
+	c.cacheResponse(raddr, received.MessageType(), received.Sequence(), b)
+	c.markResponded(raddr, received.MessageType(), received.Sequence())
 
 	if _, err := c.WriteTo(b, raddr); err != nil {
 		return err
@@ -667,6 +1168,35 @@ func (c *Conn) GetSessionByIMSI(imsi string) (*Session, error) {
 	return nil, &UnknownIMSIError{IMSI: imsi}
 }
 
+// GetSessionByMSISDN returns Session looked up by MSISDN.
+//
+// This iterates over all the Sessions in Conn, as they are not indexed by MSISDN.
+func (c *Conn) GetSessionByMSISDN(msisdn string) (*Session, error) {
+	var found *Session
+	c.RangeSessions(func(sess *Session) bool {
+		if sess.MSISDN == msisdn {
+			found = sess
+			return false
+		}
+		return true
+	})
+
+	if found == nil {
+		return nil, &UnknownMSISDNError{MSISDN: msisdn}
+	}
+	return found, nil
+}
+
+// GetSessionByIncomingTEID returns Session looked up only by the incoming TEID,
+// without checking the sender. Use GetSessionByTEID to handle incoming messages.
+func (c *Conn) GetSessionByIncomingTEID(teid uint32) (*Session, error) {
+	session, ok := c.iteiSessionMap.load(teid)
+	if !ok || session == nil {
+		return nil, &InvalidTEIDError{TEID: teid}
+	}
+	return session, nil
+}
+
 // GetIMSIByTEID returns IMSI associated with TEID and the peer node.
 func (c *Conn) GetIMSIByTEID(teid uint32, peer net.Addr) (string, error) {
 	sess, err := c.GetSessionByTEID(teid, peer)
@@ -688,11 +1218,45 @@ func (c *Conn) RegisterSession(itei uint32, session *Session) {
 	c.imsiSessionMap.store(session.IMSI, session)
 
 	session.AddTEID(c.localIfType, itei)
+	session.Touch()
+
+	session.mu.Lock()
+	if session.conn != nil {
+		session.mu.Unlock()
+		return
+	}
+	session.conn = c
+	if session.trigger == nil {
+		if msg, ok := c.pendingTriggers.Load(session.IMSI); ok {
+			session.trigger = msg.(message.Message)
+		}
+	}
+	active := session.isActive
+	session.mu.Unlock()
+
+	session.fireSessionEvent(func(h *LifecycleHooks) SessionHookFunc { return h.OnSessionCreated })
+	if active {
+		session.fireSessionEvent(func(h *LifecycleHooks) SessionHookFunc { return h.OnSessionActivated })
+	}
 }
 
 // RemoveSession removes a session registered in a Conn.
 func (c *Conn) RemoveSession(session *Session) {
+	if registered, _ := session.registeredConn(); registered == c {
+		session.fireSessionEvent(func(h *LifecycleHooks) SessionHookFunc { return h.OnSessionDeleted })
+		for _, br := range session.Bearers() {
+			c.releaseChargingID(br)
+		}
+
+		session.mu.Lock()
+		session.conn = nil
+		session.mu.Unlock()
+	}
+
 	c.imsiSessionMap.delete(session.IMSI)
+	for _, fqCSID := range session.FQCSIDs() {
+		c.csidSessionMap.deleteFQCSID(fqCSID, session)
+	}
 
 	itei, err := session.GetTEID(c.localIfType)
 	if err != nil { // if incoming TEID could not be found for some reason
@@ -769,7 +1333,7 @@ func generateRandomUint32() uint32 {
 	return binary.BigEndian.Uint32(b)
 }
 
-// Bearers returns all the bearers registered in Session.
+// Sessions returns all the sessions registered in Conn.
 func (c *Conn) Sessions() []*Session {
 	var ss []*Session
 	c.imsiSessionMap.rangeWithFunc(func(k, v interface{}) bool {
@@ -780,6 +1344,31 @@ func (c *Conn) Sessions() []*Session {
 	return ss
 }
 
+// RangeSessions calls fn sequentially for each Session registered in Conn.
+// If fn returns false, it stops the iteration.
+//
+// Sessions can be added or removed during the iteration, in the same way as
+// sync.Map.Range. Use (*Session).Info to get a snapshot of the values.
+func (c *Conn) RangeSessions(fn func(sess *Session) bool) {
+	c.imsiSessionMap.rangeWithFunc(func(k, v interface{}) bool {
+		return fn(v.(*Session))
+	})
+}
+
+// SessionCountByPeer returns the number of active sessions registered in Conn,
+// keyed by the address of the peer in string.
+func (c *Conn) SessionCountByPeer() map[string]int {
+	counts := map[string]int{}
+	c.RangeSessions(func(sess *Session) bool {
+		if sess.IsActive() {
+			counts[sess.peerAddrString]++
+		}
+		return true
+	})
+
+	return counts
+}
+
 // SessionCount returns the number of sessions registered in Conn.
 //
 // This may have some impact on performance in case of large number of Session exists.
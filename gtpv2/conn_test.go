@@ -6,15 +6,23 @@ package gtpv2_test
 
 import (
 	"context"
+	"encoding/binary"
 	"log"
 	"net"
+	"runtime"
+	"sort"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
+	"github.com/wmnsk/go-gtp/gtpcap"
+	"github.com/wmnsk/go-gtp/gtptest"
+	v1msg "github.com/wmnsk/go-gtp/gtpv1/message"
 	v2 "github.com/wmnsk/go-gtp/gtpv2"
 	"github.com/wmnsk/go-gtp/gtpv2/ie"
 	"github.com/wmnsk/go-gtp/gtpv2/message"
+	"golang.org/x/net/ipv4"
 )
 
 func setup(ctx context.Context, doneCh chan struct{}) (cliConn, srvConn *v2.Conn, err error) {
@@ -208,3 +216,1898 @@ func TestCreateSession(t *testing.T) {
 		t.Fatal("timed out while waiting for validating Create Session Response")
 	}
 }
+
+func TestModifyBearerWithWait(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cliAddr, err := net.ResolveUDPAddr("udp", "127.0.0.3"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srvAddr, err := net.ResolveUDPAddr("udp", "127.0.0.4"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srvConn := serveConn(ctx, t, srvAddr, v2.IFTypeS11S4SGWGTPC)
+	srvConn.AddHandler(
+		message.MsgTypeModifyBearerRequest,
+		func(c *v2.Conn, cliAddr net.Addr, msg message.Message) error {
+			cause := v2.CauseRequestAccepted
+			if mbReq := msg.(*message.ModifyBearerRequest); mbReq.BearerContextsToBeModified == nil {
+				cause = v2.CauseMandatoryIEMissing
+			}
+
+			mbRsp := message.NewModifyBearerResponse(
+				0x11111111, 0,
+				ie.NewCause(cause, 0, 0, 0, nil),
+				ie.NewBearerContext(
+					ie.NewCause(cause, 0, 0, 0, nil),
+					ie.NewEPSBearerID(5),
+					ie.NewFullyQualifiedTEID(v2.IFTypeS1USGWGTPU, 0x22222222, "127.0.0.4", ""),
+				),
+			)
+			return c.RespondTo(cliAddr, msg, mbRsp)
+		},
+	)
+	cliConn := serveConn(ctx, t, cliAddr, v2.IFTypeS11MMEGTPC)
+
+	sess := v2.NewSession(srvAddr, &v2.Subscriber{IMSI: "123451234567891", Location: &v2.Location{}})
+	sess.GetDefaultBearer().EBI = 5
+	cliConn.RegisterSession(0x11111111, sess)
+
+	t.Run("Accepted", func(t *testing.T) {
+		if _, err := cliConn.ModifyBearerWithWait(
+			0x33333333, sess, 3*time.Second,
+			ie.NewBearerContext(
+				ie.NewEPSBearerID(5),
+				ie.NewFullyQualifiedTEID(v2.IFTypeS1UeNodeBGTPU, 0x44444444, "127.0.0.5", ""),
+			),
+		); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := sess.GetDefaultBearer().OutgoingTEID(), uint32(0x44444444); got != want {
+			t.Errorf("wrong outgoing TEID. want: %#x, got: %#x", want, got)
+		}
+		if got, want := sess.GetDefaultBearer().RemoteAddress().String(), "127.0.0.5"+v2.GTPUPort; got != want {
+			t.Errorf("wrong remote address. want: %s, got: %s", want, got)
+		}
+		teid, err := sess.GetTEID(v2.IFTypeS1USGWGTPU)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := uint32(0x22222222); teid != want {
+			t.Errorf("wrong S1-U S-GW TEID. want: %#x, got: %#x", want, teid)
+		}
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		_, err := cliConn.ModifyBearerWithWait(0x33333333, sess, 3*time.Second)
+		causeErr, ok := err.(*v2.CauseNotOKError)
+		if !ok {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if causeErr.Cause != v2.CauseMandatoryIEMissing {
+			t.Errorf("wrong Cause. want: %d, got: %d", v2.CauseMandatoryIEMissing, causeErr.Cause)
+		}
+	})
+}
+
+func TestCreateBearerWithWait(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pgwAddr, err := net.ResolveUDPAddr("udp", "127.0.0.5"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sgwAddr, err := net.ResolveUDPAddr("udp", "127.0.0.6"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	brCh := make(chan *v2.Bearer, 1)
+	sgwConn := serveConn(ctx, t, sgwAddr, v2.IFTypeS5S8SGWGTPC)
+	sgwConn.AddHandler(
+		message.MsgTypeCreateBearerRequest,
+		func(c *v2.Conn, pgwAddr net.Addr, msg message.Message) error {
+			sess := v2.NewSession(pgwAddr, &v2.Subscriber{IMSI: "123451234567892"})
+			br, err := c.ParseCreateBearerRequest(sess, msg.(*message.CreateBearerRequest))
+			if err != nil {
+				return err
+			}
+
+			br.EBI = 6
+			if err := c.CreateBearerResponse(
+				0x11111111, sess, msg.(*message.CreateBearerRequest), "dedicated", br,
+				v2.CauseRequestAccepted,
+				ie.NewFullyQualifiedTEID(v2.IFTypeS5S8SGWGTPU, 0x22222222, "127.0.0.6", "").WithInstance(2),
+				ie.NewFullyQualifiedTEID(v2.IFTypeS5S8PGWGTPU, br.OutgoingTEID(), "127.0.0.5", "").WithInstance(3),
+			); err != nil {
+				return err
+			}
+
+			brCh <- br
+			return nil
+		},
+	)
+	pgwConn := serveConn(ctx, t, pgwAddr, v2.IFTypeS5S8PGWGTPC)
+
+	sess := v2.NewSession(sgwAddr, &v2.Subscriber{IMSI: "123451234567892", Location: &v2.Location{}})
+	sess.GetDefaultBearer().EBI = 5
+	pgwConn.RegisterSession(0x11111111, sess)
+
+	qos := &v2.QoSProfile{PCI: true, PL: 2, QCI: 1, MBRUL: 128000, MBRDL: 128000, GBRUL: 64000, GBRDL: 64000}
+	br := v2.NewBearer(0, "", qos)
+	if _, err := pgwConn.CreateBearerWithWait(
+		0x33333333, sess, "dedicated", br, nil, 3*time.Second,
+		ie.NewFullyQualifiedTEID(v2.IFTypeS5S8PGWGTPU, 0x44444444, "127.0.0.5", "").WithInstance(1),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if br.EBI != 6 {
+		t.Errorf("wrong EBI. want: %d, got: %d", 6, br.EBI)
+	}
+	if got, want := br.OutgoingTEID(), uint32(0x22222222); got != want {
+		t.Errorf("wrong outgoing TEID. want: %#x, got: %#x", want, got)
+	}
+	if got, want := br.IncomingTEID(), uint32(0x44444444); got != want {
+		t.Errorf("wrong incoming TEID. want: %#x, got: %#x", want, got)
+	}
+	if got, err := sess.LookupBearerByName("dedicated"); err != nil || got != br {
+		t.Errorf("bearer not added to session: %v", err)
+	}
+
+	select {
+	case got := <-brCh:
+		if diff := cmp.Diff(got.QoSProfile, qos); diff != "" {
+			t.Error(diff)
+		}
+		if got, want := got.OutgoingTEID(), uint32(0x44444444); got != want {
+			t.Errorf("wrong outgoing TEID on S-GW. want: %#x, got: %#x", want, got)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out while waiting for Create Bearer Request to be handled")
+	}
+}
+
+func TestS1Handover(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mmeAddr, err := net.ResolveUDPAddr("udp", "127.0.0.7"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sgwAddr, err := net.ResolveUDPAddr("udp", "127.0.0.8"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accepted := ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil)
+	sgwConn := serveConn(ctx, t, sgwAddr, v2.IFTypeS11S4SGWGTPC)
+	sgwConn.AddHandlers(map[uint8]v2.HandlerFunc{
+		message.MsgTypeCreateIndirectDataForwardingTunnelRequest: func(c *v2.Conn, mmeAddr net.Addr, msg message.Message) error {
+			return c.RespondTo(mmeAddr, msg, message.NewCreateIndirectDataForwardingTunnelResponse(
+				0x11111111, 0, accepted,
+				ie.NewBearerContext(
+					ie.NewEPSBearerID(5), accepted,
+					ie.NewFullyQualifiedTEID(v2.IFTypeS1UeNodeBGTPU, 0x66666666, "127.0.0.9", ""),
+					ie.NewFullyQualifiedTEID(v2.IFTypeSGWUPFGTPUForDL, 0x22222222, "127.0.0.8", "").WithInstance(1),
+				),
+				ie.NewBearerContext(
+					ie.NewEPSBearerID(6), accepted,
+					ie.NewFullyQualifiedTEID(v2.IFTypeSGWUPFGTPUForDL, 0x77777777, "127.0.0.8", "").WithInstance(1),
+				),
+			))
+		},
+		message.MsgTypeModifyBearerRequest: func(c *v2.Conn, mmeAddr net.Addr, msg message.Message) error {
+			return c.RespondTo(mmeAddr, msg, message.NewModifyBearerResponse(0x11111111, 0, accepted))
+		},
+		message.MsgTypeDeleteIndirectDataForwardingTunnelRequest: func(c *v2.Conn, mmeAddr net.Addr, msg message.Message) error {
+			return c.RespondTo(mmeAddr, msg, message.NewDeleteIndirectDataForwardingTunnelResponse(0x11111111, 0, accepted))
+		},
+	})
+	mmeConn := serveConn(ctx, t, mmeAddr, v2.IFTypeS11MMEGTPC)
+
+	sess := v2.NewSession(sgwAddr, &v2.Subscriber{IMSI: "123451234567893", Location: &v2.Location{}})
+	sess.GetDefaultBearer().EBI = 5
+	sess.AddTEID(v2.IFTypeS1UeNodeBGTPU, 0x88888888)
+	mmeConn.RegisterSession(0x11111111, sess)
+
+	// Modify Bearer Request cannot be built without OnHandoverExecution.
+	var perr *v2.RequiredParameterMissingError
+	if err := v2.NewS1Handover(mmeConn, sess, 0x33333333, nil).Run(ctx); !errors.As(err, &perr) {
+		t.Errorf("want RequiredParameterMissingError without OnHandoverExecution, got %v", err)
+	}
+
+	var steps []string
+	var ho *v2.S1Handover
+	ho = v2.NewS1Handover(mmeConn, sess, 0x33333333, &v2.S1HandoverHooks{
+		OnForwardingTunnelCreated: func(sess *v2.Session, res *message.CreateIndirectDataForwardingTunnelResponse) error {
+			steps = append(steps, "created")
+
+			if got := len(ho.ForwardingFTEIDs(5)); got != 2 {
+				t.Errorf("wrong number of forwarding F-TEIDs for EBI 5: %d", got)
+			}
+			fteids := ho.ForwardingFTEIDs(6)
+			if len(fteids) != 1 || fteids[0].MustTEID() != 0x77777777 {
+				t.Errorf("wrong forwarding F-TEIDs for EBI 6: %v", fteids)
+			}
+			if teid, err := sess.GetTEID(v2.IFTypeS1UeNodeBGTPU); err != nil || teid != 0x88888888 {
+				t.Errorf("S1-U eNodeB TEID overwritten by forwarding one: %#x, %v", teid, err)
+			}
+			return nil
+		},
+		OnHandoverExecution: func(ctx context.Context, sess *v2.Session) ([]*ie.IE, error) {
+			steps = append(steps, "executed")
+			return []*ie.IE{
+				ie.NewBearerContext(
+					ie.NewEPSBearerID(5),
+					ie.NewFullyQualifiedTEID(v2.IFTypeS1UeNodeBGTPU, 0x44444444, "127.0.0.9", ""),
+				),
+			}, nil
+		},
+		OnBearerModified: func(sess *v2.Session, res *message.ModifyBearerResponse) error {
+			steps = append(steps, "modified")
+			return nil
+		},
+		OnForwardingTunnelDeleted: func(sess *v2.Session, res *message.DeleteIndirectDataForwardingTunnelResponse) error {
+			steps = append(steps, "deleted")
+			return nil
+		},
+	})
+	ho.ForwardingDuration = 100 * time.Millisecond
+
+	if err := ho.Run(ctx, ie.NewBearerContext(
+		ie.NewEPSBearerID(5),
+		ie.NewFullyQualifiedTEID(v2.IFTypeeNodeBGTPUForDL, 0x55555555, "127.0.0.9", ""),
+	)); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(steps, []string{"created", "executed", "modified", "deleted"}); diff != "" {
+		t.Error(diff)
+	}
+	if fteids := ho.ForwardingFTEIDs(5); fteids != nil {
+		t.Errorf("forwarding F-TEIDs left after deletion: %v", fteids)
+	}
+	if got, want := sess.GetDefaultBearer().OutgoingTEID(), uint32(0x44444444); got != want {
+		t.Errorf("wrong outgoing TEID. want: %#x, got: %#x", want, got)
+	}
+}
+
+func TestDeletePDNConnectionSet(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pgwAddr, err := net.ResolveUDPAddr("udp", "127.0.0.10"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sgwAddr, err := net.ResolveUDPAddr("udp", "127.0.0.11"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removedCh := make(chan []*v2.Session)
+	pgwConn := serveConn(ctx, t, pgwAddr, v2.IFTypeS5S8PGWGTPC)
+	pgwConn.SetPartialFailureHandler(func(c *v2.Conn, senderAddr net.Addr, msg message.Message, sessions []*v2.Session) error {
+		removedCh <- sessions
+		return nil
+	})
+
+	causeCh := make(chan uint8)
+	sgwConn := serveConn(ctx, t, sgwAddr, v2.IFTypeS5S8SGWGTPC)
+	sgwConn.AddHandler(
+		message.MsgTypeDeletePDNConnectionSetResponse,
+		func(c *v2.Conn, senderAddr net.Addr, msg message.Message) error {
+			res := msg.(*message.DeletePDNConnectionSetResponse)
+			cause, err := res.Cause.Cause()
+			if err != nil {
+				return err
+			}
+			causeCh <- cause
+			return nil
+		},
+	)
+
+	for i, s := range []struct {
+		imsi string
+		csid uint16
+	}{
+		{"123451234567890", 1},
+		{"123451234567891", 1},
+		{"123451234567892", 2},
+	} {
+		sess := v2.NewSession(sgwAddr, &v2.Subscriber{IMSI: s.imsi, Location: &v2.Location{}})
+		pgwConn.RegisterSession(uint32(0x11111111+i), sess)
+		if err := pgwConn.SetSessionFQCSID(sess, v2.CSIDNodeSGW, ie.NewFullyQualifiedCSID("127.0.0.11", s.csid)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := len(pgwConn.GetSessionsByCSID("127.0.0.11", 1)); got != 2 {
+		t.Fatalf("wrong number of sessions with CSID 1. want: 2, got: %d", got)
+	}
+
+	if _, err := sgwConn.SendMessageTo(
+		message.NewDeletePDNConnectionSetRequest(
+			0, 0, ie.NewFullyQualifiedCSID("127.0.0.11", 1).WithInstance(1),
+		), pgwAddr,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case sessions := <-removedCh:
+		var imsis []string
+		for _, sess := range sessions {
+			imsis = append(imsis, sess.IMSI)
+		}
+		sort.Strings(imsis)
+		if diff := cmp.Diff([]string{"123451234567890", "123451234567891"}, imsis); diff != "" {
+			t.Error(diff)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for partial failure handler")
+	}
+
+	select {
+	case cause := <-causeCh:
+		if cause != v2.CauseRequestAccepted {
+			t.Errorf("wrong Cause. want: %d, got: %d", v2.CauseRequestAccepted, cause)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for Delete PDN Connection Set Response")
+	}
+
+	if got := len(pgwConn.GetSessionsByCSID("127.0.0.11", 1)); got != 0 {
+		t.Errorf("sessions with CSID 1 still exist: %d", got)
+	}
+	if _, err := pgwConn.GetSessionByIMSI("123451234567892"); err != nil {
+		t.Errorf("session with CSID 2 should not be removed: %v", err)
+	}
+}
+
+func TestDeletePDNConnectionSetByResponseFQCSID(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pc, sgwPC, err := gtptest.Pipe("127.0.0.74"+v2.GTPCPort, "127.0.0.75"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sgwAddr := sgwPC.LocalAddr()
+
+	removedCh := make(chan []*v2.Session, 1)
+	mmeConn := servePacketConn(ctx, t, pc, v2.IFTypeS11MMEGTPC)
+	mmeConn.SetPartialFailureHandler(func(c *v2.Conn, senderAddr net.Addr, msg message.Message, sessions []*v2.Session) error {
+		removedCh <- sessions
+		return nil
+	})
+
+	read := func() message.Message {
+		t.Helper()
+
+		if err := sgwPC.SetReadDeadline(time.Now().Add(3 * time.Second)); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 1500)
+		n, _, err := sgwPC.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := message.Parse(buf[:n])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+
+	sess, _, err := mmeConn.CreateSession(
+		sgwAddr, ie.NewIMSI("123451234567894"), mmeConn.NewSenderFTEID("127.0.0.74", ""),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csReq, ok := read().(*message.CreateSessionRequest)
+	if !ok {
+		t.Fatal("Create Session Request not received")
+	}
+
+	// S-GW responds with its own FQ-CSID and the one from P-GW.
+	b, err := message.Marshal(message.NewCreateSessionResponse(
+		csReq.SenderFTEIDC.MustTEID(), csReq.Sequence(),
+		ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+		ie.NewFullyQualifiedCSID("127.0.0.76", 4),
+		ie.NewFullyQualifiedCSID("127.0.0.75", 3).WithInstance(1),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sgwPC.WriteTo(b, pc.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50 && len(mmeConn.GetSessionsByCSID("127.0.0.75", 3)) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := mmeConn.GetSessionsByCSID("127.0.0.76", 4); len(got) != 1 || got[0] != sess {
+		t.Errorf("P-GW FQ-CSID in response is not associated: %v", got)
+	}
+
+	b, err = message.Marshal(message.NewDeletePDNConnectionSetRequest(
+		0, 1, ie.NewFullyQualifiedCSID("127.0.0.75", 3).WithInstance(1),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sgwPC.WriteTo(b, pc.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case sessions := <-removedCh:
+		if len(sessions) != 1 || sessions[0] != sess {
+			t.Errorf("wrong sessions removed: %v", sessions)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for partial failure handler")
+	}
+	res, ok := read().(*message.DeletePDNConnectionSetResponse)
+	if !ok {
+		t.Fatal("Delete PDN Connection Set Response not received")
+	}
+	if cause := res.Cause.MustCause(); cause != v2.CauseRequestAccepted {
+		t.Errorf("wrong Cause. want: %d, got: %d", v2.CauseRequestAccepted, cause)
+	}
+}
+
+func TestNewConnWithPacketConn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.12"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srvConn := v2.NewConnWithPacketConn(pc, v2.IFTypeS11S4SGWGTPC, 0)
+	go func() {
+		if err := srvConn.ListenAndServe(ctx); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	if got, want := srvConn.LocalAddr().String(), "127.0.0.12"+v2.GTPCPort; got != want {
+		t.Errorf("wrong local address. want: %s, got: %s", want, got)
+	}
+
+	cliAddr, err := net.ResolveUDPAddr("udp", "127.0.0.13"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Dial fails unless the server responds to Echo Request over pc.
+	if _, err := v2.Dial(ctx, cliAddr, pc.LocalAddr(), v2.IFTypeS11MMEGTPC, 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSessionInspection(t *testing.T) {
+	laddr, err := net.ResolveUDPAddr("udp", "127.0.0.14"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer1, err := net.ResolveUDPAddr("udp", "127.0.0.15"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer2, err := net.ResolveUDPAddr("udp", "127.0.0.16"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn := v2.NewConn(laddr, v2.IFTypeS11S4SGWGTPC, 0)
+	for i, s := range []struct {
+		imsi, msisdn string
+		peer         net.Addr
+	}{
+		{"123451234567890", "8130900000000", peer1},
+		{"123451234567891", "8130900000001", peer1},
+		{"123451234567892", "8130900000002", peer2},
+	} {
+		sess := v2.NewSession(s.peer, &v2.Subscriber{IMSI: s.imsi, MSISDN: s.msisdn, Location: &v2.Location{}})
+		sess.AddTEID(v2.IFTypeS11MMEGTPC, uint32(0x22222222+i))
+		conn.RegisterSession(uint32(0x11111111+i), sess)
+		if err := sess.Activate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if diff := cmp.Diff(map[string]int{peer1.String(): 2, peer2.String(): 1}, conn.SessionCountByPeer()); diff != "" {
+		t.Error(diff)
+	}
+
+	sess, err := conn.GetSessionByMSISDN("8130900000001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sess.IMSI, "123451234567891"; got != want {
+		t.Errorf("wrong IMSI. want: %s, got: %s", want, got)
+	}
+
+	sess, err = conn.GetSessionByIncomingTEID(0x11111113)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := sess.Info()
+	if got, want := info.IMSI, "123451234567892"; got != want {
+		t.Errorf("wrong IMSI. want: %s, got: %s", want, got)
+	}
+	want := map[uint8]uint32{v2.IFTypeS11MMEGTPC: 0x22222224, v2.IFTypeS11S4SGWGTPC: 0x11111113}
+	if diff := cmp.Diff(want, info.TEIDs); diff != "" {
+		t.Error(diff)
+	}
+
+	// the snapshot should not be affected by the changes afterwards.
+	sess.AddTEID(v2.IFTypeS11MMEGTPC, 0)
+	if got := info.TEIDs[v2.IFTypeS11MMEGTPC]; got != 0x22222224 {
+		t.Errorf("snapshot is changed: %#x", got)
+	}
+
+	if _, err := conn.GetSessionByMSISDN("8130900000009"); err == nil {
+		t.Error("expected error for unknown MSISDN")
+	}
+
+	// the snapshot should be taken safely while the Bearer is being updated.
+	br := sess.GetDefaultBearer()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := uint32(0); i < 100; i++ {
+			br.SetIncomingTEID(i)
+			br.SetOutgoingTEID(i)
+			br.SetRemoteAddress(peer2)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		_ = sess.Info()
+	}
+	<-done
+}
+
+func TestLifecycleHooks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mmePC, sgwPC, err := gtptest.Pipe("127.0.0.17"+v2.GTPCPort, "127.0.0.18"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eventCh := make(chan string, 10)
+	sgwConn := v2.NewConnWithPacketConn(sgwPC, v2.IFTypeS11S4SGWGTPC, 0)
+	sgwConn.SetLifecycleHooks(&v2.LifecycleHooks{
+		OnSessionCreated: func(c *v2.Conn, sess *v2.Session, msg message.Message) {
+			eventCh <- "created by " + msg.MessageTypeName()
+		},
+		OnSessionActivated: func(c *v2.Conn, sess *v2.Session, msg message.Message) {
+			eventCh <- "activated by " + msg.MessageTypeName()
+		},
+		OnSessionDeleted: func(c *v2.Conn, sess *v2.Session, msg message.Message) {
+			eventCh <- "deleted by " + msg.MessageTypeName()
+		},
+		OnBearerAdded: func(c *v2.Conn, sess *v2.Session, br *v2.Bearer, msg message.Message) {
+			eventCh <- "bearer added by " + msg.MessageTypeName()
+		},
+	})
+	sgwConn.AddHandlers(map[uint8]v2.HandlerFunc{
+		message.MsgTypeCreateSessionRequest: func(c *v2.Conn, mmeAddr net.Addr, msg message.Message) error {
+			csReq := msg.(*message.CreateSessionRequest)
+			sess := v2.NewSession(mmeAddr, &v2.Subscriber{IMSI: csReq.IMSI.MustIMSI(), Location: &v2.Location{}})
+			c.RegisterSession(0x11111111, sess)
+			sess.AddBearer("dedicated", v2.NewBearer(6, "", &v2.QoSProfile{}))
+			if err := sess.Activate(); err != nil {
+				return err
+			}
+			// no event for the Session already active.
+			if err := sess.Activate(); err != nil {
+				return err
+			}
+			return c.RespondTo(mmeAddr, msg, message.NewCreateSessionResponse(
+				csReq.SenderFTEIDC.MustTEID(), 0,
+				ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+			))
+		},
+		message.MsgTypeDeleteSessionRequest: func(c *v2.Conn, mmeAddr net.Addr, msg message.Message) error {
+			sess, err := c.GetSessionByTEID(msg.TEID(), mmeAddr)
+			if err != nil {
+				return err
+			}
+			c.RemoveSession(sess)
+			return nil
+		},
+	})
+	go func() {
+		if err := sgwConn.ListenAndServe(ctx); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	csRspCh := make(chan struct{})
+	mmeConn := v2.NewConnWithPacketConn(mmePC, v2.IFTypeS11MMEGTPC, 0)
+	mmeConn.AddHandler(
+		message.MsgTypeCreateSessionResponse,
+		func(c *v2.Conn, sgwAddr net.Addr, msg message.Message) error {
+			csRspCh <- struct{}{}
+			return nil
+		},
+	)
+	go func() {
+		if err := mmeConn.ListenAndServe(ctx); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	sess, _, err := mmeConn.CreateSession(
+		sgwPC.LocalAddr(),
+		ie.NewIMSI("123451234567893"),
+		mmeConn.NewSenderFTEID("127.0.0.17", ""),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-csRspCh:
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for Create Session Response")
+	}
+	if _, err := mmeConn.DeleteSession(0x11111111, sess); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for len(got) < 4 {
+		select {
+		case e := <-eventCh:
+			got = append(got, e)
+		case <-time.After(3 * time.Second):
+			t.Fatalf("timed out waiting for events, got: %v", got)
+		}
+	}
+
+	want := []string{
+		"created by Create Session Request",
+		"bearer added by Create Session Request",
+		"activated by Create Session Request",
+		"deleted by Delete Session Request",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	laddr, err := net.ResolveUDPAddr("udp", "127.0.0.19"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer, err := net.ResolveUDPAddr("udp", "127.0.0.20"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn := v2.NewConn(laddr, v2.IFTypeS11S4SGWGTPC, 0)
+	expiredCh := make(chan string, 2)
+	conn.SetLifecycleHooks(&v2.LifecycleHooks{
+		OnSessionExpired: func(c *v2.Conn, sess *v2.Session, msg message.Message) {
+			expiredCh <- sess.IMSI
+		},
+	})
+
+	idle := v2.NewSession(peer, &v2.Subscriber{IMSI: "123451234567890", Location: &v2.Location{}})
+	conn.RegisterSession(0x11111111, idle)
+	alive := v2.NewSession(peer, &v2.Subscriber{IMSI: "123451234567891", Location: &v2.Location{}})
+	conn.RegisterSession(0x11111112, alive)
+
+	conn.EnableSessionExpiry(100*time.Millisecond, 10*time.Millisecond)
+	defer conn.DisableSessionExpiry()
+
+	timeout := time.After(3 * time.Second)
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case imsi := <-expiredCh:
+			if got, want := imsi, idle.IMSI; got != want {
+				t.Fatalf("wrong Session expired. want: %s, got: %s", want, got)
+			}
+			if _, err := conn.GetSessionByIMSI(idle.IMSI); err == nil {
+				t.Error("expired Session is not removed")
+			}
+			if _, err := conn.GetSessionByIMSI(alive.IMSI); err != nil {
+				t.Error(err)
+			}
+			return
+		case <-ticker.C:
+			alive.Touch()
+		case <-timeout:
+			t.Fatal("timed out waiting for the Session to expire")
+		}
+	}
+}
+
+// blockingPacketConn blocks WriteTo until release is closed.
+type blockingPacketConn struct {
+	net.PacketConn
+	release chan struct{}
+}
+
+func (b *blockingPacketConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	<-b.release
+	return b.PacketConn.WriteTo(p, addr)
+}
+
+func TestSendQueue(t *testing.T) {
+	pc, peerPC, err := gtptest.Pipe("127.0.0.21"+v2.GTPCPort, "127.0.0.22"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peerPC.Close()
+
+	bpc := &blockingPacketConn{PacketConn: pc, release: make(chan struct{})}
+	conn := v2.NewConnWithPacketConn(bpc, v2.IFTypeS11MMEGTPC, 0)
+	defer conn.Close()
+	conn.EnableSendQueue(2)
+
+	// the first packet may be taken by the sender and blocked there, so the
+	// queue should be full by depth+2 packets at most.
+	var sent int
+	for ; sent < 4; sent++ {
+		if _, err := conn.EchoRequest(peerPC.LocalAddr()); err != nil {
+			if !errors.Is(err, v2.ErrSendQueueFull) {
+				t.Fatal(err)
+			}
+			break
+		}
+	}
+	if sent == 4 {
+		t.Fatal("queue never got full")
+	}
+	if got := conn.SequenceNumber(); got != uint32(sent) {
+		t.Errorf("wrong Sequence Number after failure. want: %d, got: %d", sent, got)
+	}
+
+	close(bpc.release)
+
+	buf := make([]byte, 1500)
+	for i := 0; i < sent; i++ {
+		if err := peerPC.SetReadDeadline(time.Now().Add(3 * time.Second)); err != nil {
+			t.Fatal(err)
+		}
+		n, _, err := peerPC.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := message.Parse(buf[:n])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := msg.Sequence(), uint32(i+1); got != want {
+			t.Errorf("wrong Sequence Number. want: %d, got: %d", want, got)
+		}
+	}
+}
+
+func TestSocketOptions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opts := &v2.SocketOptions{
+		ReadBuffer:  1 << 20,
+		WriteBuffer: 1 << 20,
+		DSCP:        v2.DSCPCS5,
+		PacketInfo:  true,
+	}
+	if runtime.GOOS == "linux" {
+		opts.DontFragment = true
+	}
+
+	pc, err := v2.ListenPacket("udp4", "127.0.0.24"+v2.GTPCPort, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srvConn := v2.NewConnWithPacketConn(pc, v2.IFTypeS11S4SGWGTPC, 0)
+	defer srvConn.Close()
+	go func() {
+		if err := srvConn.ListenAndServe(ctx); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	laddr, err := net.ResolveUDPAddr("udp4", "127.0.0.23"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cliConn, err := v2.DialWithSocketOptions(ctx, laddr, srvConn.LocalAddr(), v2.IFTypeS11MMEGTPC, 0, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cliConn.Close()
+
+	// DSCP is set without PacketInfo, to check it with the *net.UDPConn.
+	pc, err = v2.ListenPacket("udp4", "127.0.0.25"+v2.GTPCPort, &v2.SocketOptions{DSCP: v2.DSCPCS5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	tos, err := ipv4.NewConn(pc.(*net.UDPConn)).TOS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tos, int(v2.DSCPCS5<<2); got != want {
+		t.Errorf("wrong TOS. want: %#x, got: %#x", want, got)
+	}
+
+	if _, err := v2.ListenPacket("udp4", "127.0.0.25:0", &v2.SocketOptions{DSCP: 64}); err == nil {
+		t.Error("DSCP larger than 63 should be rejected")
+	}
+}
+
+type testSpanKey struct{}
+
+type testSpan struct {
+	info  *v2.SpanInfo
+	endCh chan *testSpan
+	res   message.Message
+	err   error
+}
+
+func (s *testSpan) End(res message.Message, err error) {
+	s.res, s.err = res, err
+	s.endCh <- s
+}
+
+type testTracer struct {
+	endCh chan *testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, info *v2.SpanInfo) (context.Context, v2.Span) {
+	span := &testSpan{info: info, endCh: t.endCh}
+	return context.WithValue(ctx, testSpanKey{}, span), span
+}
+
+func TestTracer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mmePC, sgwPC, err := gtptest.Pipe("127.0.0.26"+v2.GTPCPort, "127.0.0.27"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mmeTracer := &testTracer{endCh: make(chan *testSpan, 1)}
+	mmeConn := v2.NewConnWithPacketConn(mmePC, v2.IFTypeS11MMEGTPC, 0)
+	mmeConn.SetTracer(mmeTracer, 3*time.Second)
+	sgwTracer := &testTracer{endCh: make(chan *testSpan, 1)}
+	sgwConn := v2.NewConnWithPacketConn(sgwPC, v2.IFTypeS11S4SGWGTPC, 0)
+	sgwConn.SetTracer(sgwTracer, 3*time.Second)
+
+	sgwCtxCh := make(chan context.Context, 1)
+	sgwConn.AddHandler(
+		message.MsgTypeDetachNotification,
+		func(c *v2.Conn, senderAddr net.Addr, msg message.Message) error {
+			sgwCtxCh <- c.HandlerContext(msg)
+			return c.RespondTo(senderAddr, msg, message.NewDetachAcknowledge(0, 0, ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil)))
+		},
+	)
+	mmeCtxCh := make(chan context.Context, 1)
+	mmeConn.AddHandler(
+		message.MsgTypeDetachAcknowledge,
+		func(c *v2.Conn, senderAddr net.Addr, msg message.Message) error {
+			mmeCtxCh <- c.HandlerContext(msg)
+			return nil
+		},
+	)
+
+	for _, conn := range []*v2.Conn{mmeConn, sgwConn} {
+		go func(conn *v2.Conn) {
+			if err := conn.ListenAndServe(ctx); err != nil {
+				log.Println(err)
+			}
+		}(conn)
+	}
+
+	type parentKey struct{}
+	parent := context.WithValue(context.Background(), parentKey{}, "parent")
+	seq, err := mmeConn.SendMessageToContext(parent, message.NewDetachNotification(0, 0), sgwPC.LocalAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case span := <-sgwTracer.endCh:
+		want := &v2.SpanInfo{
+			Kind:            v2.SpanKindServer,
+			MessageType:     message.MsgTypeDetachNotification,
+			MessageTypeName: "Detach Notification",
+			Sequence:        seq,
+			Peer:            mmePC.LocalAddr(),
+		}
+		if diff := cmp.Diff(want, span.info); diff != "" {
+			t.Error(diff)
+		}
+		if got := (<-sgwCtxCh).Value(testSpanKey{}); got != span {
+			t.Errorf("Span is not propagated to HandlerFunc on S-GW")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for the server Span to end")
+	}
+
+	select {
+	case span := <-mmeTracer.endCh:
+		if got, want := span.info.Kind, v2.SpanKindClient; got != want {
+			t.Errorf("wrong SpanKind. want: %d, got: %d", want, got)
+		}
+		if span.err != nil {
+			t.Error(span.err)
+		}
+		if _, ok := span.res.(*message.DetachAcknowledge); !ok {
+			t.Errorf("wrong response: %v", span.res)
+		}
+
+		handlerCtx := <-mmeCtxCh
+		if got := handlerCtx.Value(testSpanKey{}); got != span {
+			t.Errorf("Span is not propagated to HandlerFunc on MME")
+		}
+		if got := handlerCtx.Value(parentKey{}); got != "parent" {
+			t.Errorf("parent context is not propagated to HandlerFunc on MME")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for the client Span to end")
+	}
+}
+
+// exchange sends a message to raddr and returns the one received.
+func exchange(pc net.PacketConn, raddr net.Addr, req message.Message) (message.Message, error) {
+	b, err := message.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	return exchangeRaw(pc, raddr, b)
+}
+
+func exchangeRaw(pc net.PacketConn, raddr net.Addr, b []byte) (message.Message, error) {
+	if _, err := pc.WriteTo(b, raddr); err != nil {
+		return nil, err
+	}
+
+	if err := pc.SetReadDeadline(time.Now().Add(3 * time.Second)); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 1500)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		return nil, err
+	}
+	return message.Parse(buf[:n])
+}
+
+func TestPeerPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	network := gtptest.NewNetwork()
+	pc, err := network.ListenPacket("udp", "127.0.0.28"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowedPC, err := network.ListenPacket("udp", "127.0.0.29"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deniedPC, err := network.ListenPacket("udp", "127.0.0.30"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := v2.NewPeerPolicy("127.0.0.29/32")
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy.Reject = true
+
+	conn := v2.NewConnWithPacketConn(pc, v2.IFTypeS11S4SGWGTPC, 0)
+	// AllowFunc should be able to call the methods of Conn without deadlock.
+	policy.AllowFunc = func(raddr net.Addr) bool {
+		return conn.RejectedPackets() > 100
+	}
+	conn.SetPeerPolicy(policy)
+	go func() {
+		if err := conn.ListenAndServe(ctx); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	res, err := exchange(allowedPC, pc.LocalAddr(), message.NewEchoRequest(1, ie.NewRecovery(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := res.(*message.EchoResponse); !ok {
+		t.Errorf("unexpected response to allowed peer: %v", res)
+	}
+
+	res, err = exchange(deniedPC, pc.LocalAddr(), message.NewCreateSessionRequest(0, 2, ie.NewIMSI("123451234567890")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	csRsp, ok := res.(*message.CreateSessionResponse)
+	if !ok {
+		t.Fatalf("unexpected response to denied peer: %v", res)
+	}
+	if got, want := csRsp.Sequence(), uint32(2); got != want {
+		t.Errorf("wrong Sequence Number. want: %d, got: %d", want, got)
+	}
+	if cause := csRsp.Cause.MustCause(); cause != v2.CauseRequestRejectedReasonNotSpecified {
+		t.Errorf("wrong Cause. want: %d, got: %d", v2.CauseRequestRejectedReasonNotSpecified, cause)
+	}
+
+	if got, want := conn.RejectedPackets(), uint64(1); got != want {
+		t.Errorf("wrong number of rejected packets. want: %d, got: %d", want, got)
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pc, peerPC, err := gtptest.Pipe("127.0.0.31"+v2.GTPCPort, "127.0.0.32"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn := v2.NewConnWithPacketConn(pc, v2.IFTypeS11S4SGWGTPC, 0)
+	conn.EnableErrorResponse()
+	conn.AddHandler(
+		message.MsgTypeReleaseAccessBearersRequest,
+		func(c *v2.Conn, senderAddr net.Addr, msg message.Message) error {
+			return &v2.RequiredIEMissingError{Type: ie.NodeType}
+		},
+	)
+	conn.AddHandler(
+		message.MsgTypeDetachNotification,
+		func(c *v2.Conn, senderAddr net.Addr, msg message.Message) error {
+			ack := message.NewDetachAcknowledge(0, 0, ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil))
+			if err := c.RespondTo(senderAddr, msg, ack); err != nil {
+				return err
+			}
+			return &v2.InvalidIEError{Type: ie.DetachType}
+		},
+	)
+	go func() {
+		if err := conn.ListenAndServe(ctx); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	truncated, err := message.Marshal(message.NewCreateSessionRequest(
+		0, 1, ie.NewIMSI("123451234567890"), ie.NewMSISDN("8130900000000"),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	truncated = truncated[:len(truncated)-2]
+	binary.BigEndian.PutUint16(truncated[2:4], uint16(len(truncated)-4))
+
+	cases := []struct {
+		description string
+		send        func() (message.Message, error)
+		cause       uint8
+		offendingIE uint8
+	}{
+		{
+			"Invalid Length",
+			func() (message.Message, error) {
+				return exchangeRaw(peerPC, pc.LocalAddr(), truncated)
+			},
+			v2.CauseInvalidLength, 0,
+		}, {
+			"Context Not Found",
+			func() (message.Message, error) {
+				return exchange(peerPC, pc.LocalAddr(), message.NewDeleteSessionRequest(0xdeadbeef, 2))
+			},
+			v2.CauseContextNotFound, 0,
+		}, {
+			"Mandatory IE missing",
+			func() (message.Message, error) {
+				return exchange(peerPC, pc.LocalAddr(), message.NewReleaseAccessBearersRequest(0, 3))
+			},
+			v2.CauseMandatoryIEMissing, ie.NodeType,
+		}, {
+			"Already responded",
+			func() (message.Message, error) {
+				res, err := exchange(peerPC, pc.LocalAddr(), message.NewDetachNotification(0, 4))
+				if err != nil {
+					return nil, err
+				}
+				return res, expectNoPacket(peerPC)
+			},
+			v2.CauseRequestAccepted, 0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			res, err := c.send()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := message.Marshal(res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			generic, err := message.ParseGeneric(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var causeIE *ie.IE
+			for _, i := range generic.IEs {
+				if i.Type == ie.Cause {
+					causeIE = i
+				}
+			}
+			if causeIE == nil {
+				t.Fatalf("no Cause in %s", res.MessageTypeName())
+			}
+
+			if got := causeIE.MustCause(); got != c.cause {
+				t.Errorf("wrong Cause. want: %d, got: %d", c.cause, got)
+			}
+			if c.offendingIE != 0 {
+				if got := causeIE.Payload[2]; got != c.offendingIE {
+					t.Errorf("wrong offending IE. want: %d, got: %d", c.offendingIE, got)
+				}
+			}
+		})
+	}
+}
+
+func TestVersionFallback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pc, peerPC, err := gtptest.Pipe("127.0.0.33"+v2.GTPCPort, "127.0.0.34"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type fallback struct {
+		peer net.Addr
+		seq  uint32
+	}
+	fallbackCh := make(chan fallback, 1)
+	conn := v2.NewConnWithPacketConn(pc, v2.IFTypeS11MMEGTPC, 0)
+	conn.SetVersionFallback(func(c *v2.Conn, peer net.Addr, seq uint32) {
+		fallbackCh <- fallback{peer, seq}
+	})
+	go func() {
+		if err := conn.ListenAndServe(ctx); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	// GTPv1-C message is responded with Version Not Supported Indication.
+	v1Req, err := v1msg.NewEchoRequest(5).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := exchangeRaw(peerPC, pc.LocalAddr(), v1Req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := res.(*message.VersionNotSupportedIndication); !ok {
+		t.Fatalf("unexpected response to GTPv1-C message: %v", res)
+	}
+	if got, want := res.Sequence(), uint32(5); got != want {
+		t.Errorf("wrong Sequence Number. want: %d, got: %d", want, got)
+	}
+
+	// GTPv1-C response is discarded without being responded.
+	v1Res, err := v1msg.NewEchoResponse(6).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := peerPC.WriteTo(v1Res, pc.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+	if err := expectNoPacket(peerPC); err != nil {
+		t.Errorf("after GTPv1-C response: %s", err)
+	}
+
+	// the peer speaking only GTPv1-C responds to GTPv2-C request with GTPv1-C
+	// Version Not Supported.
+	if !conn.PeerSupportsV2(peerPC.LocalAddr()) {
+		t.Error("peer is marked as unsupported before Version Not Supported")
+	}
+	seq, err := conn.EchoRequest(peerPC.LocalAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := peerPC.SetReadDeadline(time.Now().Add(3 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1500)
+	if _, _, err := peerPC.ReadFrom(buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf[0] >> 5; got != 2 {
+		t.Fatalf("unexpected version of request: %d", got)
+	}
+	vns, err := v1msg.NewVersionNotSupported(0, 0).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := peerPC.WriteTo(vns, pc.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case fb := <-fallbackCh:
+		if got, want := fb.peer.String(), peerPC.LocalAddr().String(); got != want {
+			t.Errorf("wrong peer. want: %s, got: %s", want, got)
+		}
+		if fb.seq != seq {
+			t.Errorf("wrong Sequence Number. want: %d, got: %d", seq, fb.seq)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for the fallback")
+	}
+	if conn.PeerSupportsV2(peerPC.LocalAddr()) {
+		t.Error("peer is not marked as unsupported after Version Not Supported")
+	}
+
+	// Version Not Supported is never responded, not to bounce between the nodes.
+	if err := expectNoPacket(peerPC); err != nil {
+		t.Errorf("after GTPv1-C Version Not Supported: %s", err)
+	}
+}
+
+// expectNoPacket returns error if any packet is received on pc for a while.
+func expectNoPacket(pc net.PacketConn) error {
+	if err := pc.SetReadDeadline(time.Now().Add(300 * time.Millisecond)); err != nil {
+		return err
+	}
+	buf := make([]byte, 1500)
+	n, _, err := pc.ReadFrom(buf)
+	if err == nil {
+		return errors.Errorf("unexpected packet received: %x", buf[:n])
+	}
+	if nerr, ok := err.(net.Error); ok && nerr.Timeout() {
+		return nil
+	}
+	return err
+}
+
+func TestCaptureHook(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pc, peerPC, err := gtptest.Pipe("127.0.0.56"+v2.GTPCPort, "127.0.0.57"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer peerPC.Close()
+
+	conn := v2.NewConnWithPacketConn(pc, v2.IFTypeS11MMEGTPC, 0)
+	defer conn.Close()
+
+	pktCh := make(chan *gtpcap.Packet, 2)
+	conn.SetCaptureHook(func(p *gtpcap.Packet) {
+		pktCh <- p
+	})
+	go func() {
+		if err := conn.ListenAndServe(ctx); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	seq, err := conn.EchoRequest(peerPC.LocalAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 1500)
+	if err := peerPC.SetReadDeadline(time.Now().Add(3 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := peerPC.ReadFrom(buf); err != nil {
+		t.Fatal(err)
+	}
+	res, err := message.NewEchoResponse(seq, ie.NewRecovery(0)).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := peerPC.WriteTo(res, pc.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []struct {
+		msgType  uint8
+		src, dst string
+	}{
+		{message.MsgTypeEchoRequest, "127.0.0.56" + v2.GTPCPort, "127.0.0.57" + v2.GTPCPort},
+		{message.MsgTypeEchoResponse, "127.0.0.57" + v2.GTPCPort, "127.0.0.56" + v2.GTPCPort},
+	} {
+		select {
+		case p := <-pktCh:
+			if p.Message == nil || p.Message.MessageType() != want.msgType {
+				t.Errorf("wrong message captured: %v", p.Message)
+			}
+			if p.Src.String() != want.src || p.Dst.String() != want.dst {
+				t.Errorf("wrong addresses: %s -> %s", p.Src, p.Dst)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatal("packet not captured")
+		}
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pc, peerPC, err := gtptest.Pipe("127.0.0.58"+v2.GTPCPort, "127.0.0.59"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn := v2.NewConnWithPacketConn(pc, v2.IFTypeS11S4SGWGTPC, 0)
+	conn.EnableResponseCache(200*time.Millisecond, 16)
+
+	var handled uint32
+	conn.AddHandler(
+		message.MsgTypeReleaseAccessBearersRequest,
+		func(c *v2.Conn, senderAddr net.Addr, msg message.Message) error {
+			handled++
+			return c.RespondTo(senderAddr, msg, message.NewReleaseAccessBearersResponse(
+				0, 0, ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil), ie.NewRecovery(uint8(handled)),
+			))
+		},
+	)
+	go func() {
+		if err := conn.ListenAndServe(ctx); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	send := func(seq uint32) uint8 {
+		t.Helper()
+
+		res, err := exchange(peerPC, pc.LocalAddr(), message.NewReleaseAccessBearersRequest(0, seq))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rabRsp, ok := res.(*message.ReleaseAccessBearersResponse)
+		if !ok {
+			t.Fatalf("unexpected response: %v", res)
+		}
+		if got := rabRsp.Sequence(); got != seq {
+			t.Errorf("wrong Sequence Number. want: %d, got: %d", seq, got)
+		}
+		return rabRsp.Recovery.MustRecovery()
+	}
+
+	for i := 0; i < 3; i++ {
+		if got := send(1); got != 1 {
+			t.Errorf("response not from the first handling: %d", got)
+		}
+	}
+	if got := send(2); got != 2 {
+		t.Errorf("new request not handled: %d", got)
+	}
+	if got, want := conn.SuppressedRequests(), uint64(2); got != want {
+		t.Errorf("wrong number of suppressed requests. want: %d, got: %d", want, got)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if got := send(1); got != 3 {
+		t.Errorf("request not handled after the window: %d", got)
+	}
+
+	// the size less than 1 disables the cache.
+	conn.EnableResponseCache(200*time.Millisecond, -1)
+	for want := uint8(4); want <= 5; want++ {
+		if got := send(3); got != want {
+			t.Errorf("request not handled with the cache disabled. want: %d, got: %d", want, got)
+		}
+	}
+	if got := conn.SuppressedRequests(); got != 0 {
+		t.Errorf("requests suppressed with the cache disabled: %d", got)
+	}
+}
+
+func TestN26(t *testing.T) {
+	ind := v2.NewN26Indication(ie.NewIndicationFromOctets(0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x80))
+	if !v2.IsN26Interworking(ind) || !ind.HasDAF() {
+		t.Errorf("wrong N26 Indication: %x", ind.Payload)
+	}
+	if v2.IsN26Interworking(ie.NewIndicationFromOctets(0x00)) {
+		t.Error("Indication without 5GSIWK is regarded as N26")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pc, peerPC, err := gtptest.Pipe("127.0.0.60"+v2.GTPCPort, "127.0.0.61"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn := v2.NewConnWithPacketConn(pc, v2.IFTypeS10MMEGTPC, 0)
+	conn.EnableN26(&v2.N26Hooks{
+		MapToEPS: func(sender net.Addr, req *message.ContextRequest) ([]*ie.IE, error) {
+			if !v2.IsN26Interworking(req.Indication) {
+				return nil, &v2.CauseNotOKError{Cause: v2.CauseSystemFailure}
+			}
+			return []*ie.IE{
+				ie.NewIMSI("123451234567890"),
+				ie.NewIndicationFromOctets(0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x80),
+			}, nil
+		},
+	})
+	go func() {
+		if err := conn.ListenAndServe(ctx); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	send := func(seq uint32, ind *ie.IE) *message.ContextResponse {
+		t.Helper()
+
+		req := message.NewContextRequest(
+			0, seq,
+			ie.NewGUTI("123", "45", 0x0001, 0x01, 0x00000001),
+			ie.NewFullyQualifiedTEID(v2.IFTypeS10MMEGTPC, 0x11223344, "127.0.0.61", ""),
+			ind,
+		)
+		res, err := exchange(peerPC, pc.LocalAddr(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctxRes, ok := res.(*message.ContextResponse)
+		if !ok {
+			t.Fatalf("unexpected response: %v", res)
+		}
+		if got := ctxRes.TEID(); got != 0x11223344 {
+			t.Errorf("wrong TEID. want: %#x, got: %#x", 0x11223344, got)
+		}
+		if !v2.IsN26Interworking(ctxRes.IndicationFlags) {
+			t.Errorf("Indication in response is not for N26: %v", ctxRes.IndicationFlags)
+		}
+		return ctxRes
+	}
+
+	res := send(1, v2.NewN26Indication(nil))
+	if got := res.Cause.MustCause(); got != v2.CauseRequestAccepted {
+		t.Errorf("wrong Cause: %d", got)
+	}
+	if got := res.IMSI.MustIMSI(); got != "123451234567890" {
+		t.Errorf("wrong IMSI: %s", got)
+	}
+
+	res = send(2, nil)
+	if got := res.Cause.MustCause(); got != v2.CauseSystemFailure {
+		t.Errorf("wrong Cause: %d", got)
+	}
+}
+
+func TestOverloadControl(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// receive sends the Delete Session Response with the Overload Control Information
+	// from peerPC, and waits for it to be stored.
+	receive := func(t *testing.T, conn *v2.Conn, peerPC net.PacketConn, ocis ...*ie.IE) {
+		t.Helper()
+
+		b, err := message.Marshal(message.NewDeleteSessionResponse(
+			0, 1, append([]*ie.IE{ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil)}, ocis...)...,
+		))
+		if err != nil {
+			t.Fatal(err)
+		}
+		before := len(conn.PeerOverloadInfo(peerPC.LocalAddr()))
+		if _, err := peerPC.WriteTo(b, conn.LocalAddr()); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 50; i++ {
+			if len(conn.PeerOverloadInfo(peerPC.LocalAddr())) != before {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	listen := func(t *testing.T, laddr, raddr string, ifType uint8, decide v2.OverloadDecisionFunc) (*v2.Conn, net.PacketConn) {
+		t.Helper()
+
+		pc, peerPC, err := gtptest.Pipe(laddr+v2.GTPCPort, raddr+v2.GTPCPort)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn := v2.NewConnWithPacketConn(pc, ifType, 0)
+		conn.DisableValidation()
+		conn.EnableOverloadControl(decide)
+		go func() {
+			if err := conn.ListenAndServe(ctx); err != nil {
+				log.Println(err)
+			}
+		}()
+		return conn, peerPC
+	}
+
+	// S-GW on S5/S8 toward P-GW in overload.
+	var decided []*v2.OverloadInfo
+	conn, peerPC := listen(t, "127.0.0.64", "127.0.0.65", v2.IFTypeS5S8SGWGTPC, func(peer net.Addr, msg message.Message, info *v2.OverloadInfo) bool {
+		decided = append(decided, info)
+		return info.Metric < 50
+	})
+	peerAddr := peerPC.LocalAddr()
+
+	receive(t, conn, peerPC, ie.NewOverloadControlInformation(10, 30, time.Minute))
+	receive(t, conn, peerPC, ie.NewOverloadControlInformation(20, 80, time.Minute, "apn.example"))
+	infos := conn.PeerOverloadInfo(peerAddr)
+	if len(infos) != 2 {
+		t.Fatalf("wrong number of overload information: %d", len(infos))
+	}
+	if infos[0].APN != "" || infos[0].Metric != 30 || infos[1].APN != "apn.example" || infos[1].Metric != 80 {
+		t.Errorf("wrong overload information: %+v, %+v", infos[0], infos[1])
+	}
+
+	t.Run("Throttle", func(t *testing.T) {
+		if _, err := conn.SendMessageTo(message.NewEchoRequest(0, ie.NewRecovery(0)), peerAddr); err != nil {
+			t.Errorf("Echo Request should not be throttled: %v", err)
+		}
+		// the requests releasing the resources are not the candidates.
+		if _, err := conn.SendMessageTo(message.NewDeleteSessionRequest(0, 0), peerAddr); err != nil {
+			t.Errorf("Delete Session Request should not be throttled: %v", err)
+		}
+
+		// node-level information applies.
+		if _, err := conn.SendMessageTo(message.NewCreateSessionRequest(0, 0, ie.NewAccessPointName("other.example")), peerAddr); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		// APN-level information with the larger Metric applies.
+		_, err := conn.SendMessageTo(message.NewCreateSessionRequest(0, 0, ie.NewAccessPointName("apn.example")), peerAddr)
+		if !errors.Is(err, v2.ErrThrottledByOverload) {
+			t.Errorf("want ErrThrottledByOverload, got %v", err)
+		}
+
+		if len(decided) != 2 || decided[0].Metric != 30 || decided[1].Metric != 80 {
+			t.Errorf("wrong information passed to decision func: %v", decided)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		// Metric 0 stops the overload control, and older sequence number is ignored.
+		receive(t, conn, peerPC, ie.NewOverloadControlInformation(21, 0, time.Minute, "apn.example"))
+		receive(t, conn, peerPC, ie.NewOverloadControlInformation(9, 90, time.Minute))
+		infos := conn.PeerOverloadInfo(peerAddr)
+		if len(infos) != 1 || infos[0].Metric != 30 {
+			t.Errorf("wrong overload information: %v", infos)
+		}
+	})
+
+	t.Run("Relayed", func(t *testing.T) {
+		// MME receives the P-GW's information(instance 0) relayed by S-GW together with
+		// the S-GW's one(instance 1), and only the latter is about S-GW.
+		mmeConn, sgwPC := listen(t, "127.0.0.72", "127.0.0.73", v2.IFTypeS11MMEGTPC, nil)
+		receive(t, mmeConn, sgwPC,
+			ie.NewOverloadControlInformation(10, 100, time.Minute),
+			ie.NewOverloadControlInformation(10, 20, time.Minute).WithInstance(1),
+		)
+		infos := mmeConn.PeerOverloadInfo(sgwPC.LocalAddr())
+		if len(infos) != 1 || infos[0].Instance != 1 || infos[0].Metric != 20 {
+			t.Errorf("wrong overload information: %v", infos)
+		}
+	})
+}
+
+func TestPeerSequence(t *testing.T) {
+	pc, peerPC, err := gtptest.Pipe("127.0.0.66"+v2.GTPCPort, "127.0.0.67"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	peerA := peerPC.LocalAddr()
+	peerB := &net.UDPAddr{IP: net.IP{127, 0, 0, 68}, Port: 2123}
+
+	conn := v2.NewConnWithPacketConn(pc, v2.IFTypeS11MMEGTPC, 0)
+
+	received := func() uint32 {
+		t.Helper()
+
+		buf := make([]byte, 1500)
+		if err := peerPC.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+			t.Fatal(err)
+		}
+		n, _, err := peerPC.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := message.Parse(buf[:n])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return msg.Sequence()
+	}
+
+	send := func(msg message.Message, peer net.Addr) uint32 {
+		t.Helper()
+
+		seq, err := conn.SendMessageTo(msg, peer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return seq
+	}
+
+	cases := []struct {
+		msg  message.Message
+		peer net.Addr
+		want uint32
+	}{
+		{message.NewEchoRequest(0, ie.NewRecovery(0)), peerA, 1},
+		{message.NewEchoRequest(0, ie.NewRecovery(0)), peerB, 1},
+		{message.NewDeleteBearerCommand(0, 0), peerA, 0x800001},
+		{message.NewEchoRequest(0, ie.NewRecovery(0)), peerA, 2},
+	}
+	for _, c := range cases {
+		if got := send(c.msg, c.peer); got != c.want {
+			t.Errorf("wrong Sequence Number toward %s. want: %#x, got: %#x", c.peer, c.want, got)
+		}
+		if c.peer == peerA {
+			if got := received(); got != c.want {
+				t.Errorf("wrong Sequence Number received. want: %#x, got: %#x", c.want, got)
+			}
+		}
+	}
+
+	want := []*v2.PeerSequence{
+		{Peer: peerA.String(), Request: 2, Command: 0x800001},
+		{Peer: peerB.String(), Request: 1},
+	}
+	if diff := cmp.Diff(want, conn.PeerSequences()); diff != "" {
+		t.Error(diff)
+	}
+
+	t.Run("Retransmit", func(t *testing.T) {
+		if err := conn.RetransmitTo(cases[3].msg, peerA); err != nil {
+			t.Fatal(err)
+		}
+		if got := received(); got != 2 {
+			t.Errorf("wrong Sequence Number in retransmission: %d", got)
+		}
+		if got := conn.PeerSequence(peerA).Request; got != 2 {
+			t.Errorf("Sequence Number allocated on retransmission: %d", got)
+		}
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		conn.ResetPeerSequence(peerA)
+		if got := send(message.NewEchoRequest(0, ie.NewRecovery(0)), peerA); got != 1 {
+			t.Errorf("wrong Sequence Number after reset: %d", got)
+		}
+		received()
+
+		// wraps around at 2^23.
+		conn.SetPeerSequence(&v2.PeerSequence{Peer: peerA.String(), Request: 0x7fffff})
+		if got := send(message.NewEchoRequest(0, ie.NewRecovery(0)), peerA); got != 0 {
+			t.Errorf("wrong Sequence Number after wrapping around: %d", got)
+		}
+		received()
+	})
+
+	t.Run("Eviction", func(t *testing.T) {
+		// peerA is used least recently, as peerB is used after the last send to it.
+		conn.SetPeerSequence(&v2.PeerSequence{Peer: peerB.String(), Request: 10})
+		for i := 0; i < 4095; i++ {
+			peer := &net.UDPAddr{IP: net.IP{127, 1, byte(i / 256), byte(i)}, Port: 2123}
+			conn.SetPeerSequence(&v2.PeerSequence{Peer: peer.String(), Request: 1})
+		}
+
+		pss := conn.PeerSequences()
+		if got, want := len(pss), 4096; got != want {
+			t.Fatalf("wrong number of peers. want: %d, got: %d", want, got)
+		}
+		if got := conn.PeerSequence(peerA).Request; got != 0 {
+			t.Errorf("Sequence Number toward %s not discarded: %d", peerA, got)
+		}
+		if got := conn.PeerSequence(peerB).Request; got != 10 {
+			t.Errorf("wrong Sequence Number toward %s: %d", peerB, got)
+		}
+	})
+
+	t.Run("WriteError", func(t *testing.T) {
+		if err := pc.Close(); err != nil {
+			t.Fatal(err)
+		}
+		seq, err := conn.SendMessageTo(message.NewEchoRequest(0, ie.NewRecovery(0)), peerB)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if seq != 0 {
+			t.Errorf("wrong Sequence Number returned on error: %d", seq)
+		}
+		if got := conn.PeerSequence(peerB).Request; got != 10 {
+			t.Errorf("Sequence Number not released on error: %d", got)
+		}
+	})
+}
+
+func TestPathInfo(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pc, peerPC, err := gtptest.Pipe("127.0.0.69"+v2.GTPCPort, "127.0.0.70"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer := peerPC.LocalAddr()
+
+	infoCh := make(chan *v2.PathInfo, 4)
+	conn := v2.NewConnWithPacketConn(pc, v2.IFTypeS5S8SGWGTPC, 0)
+	conn.SetPathInfoHandler(func(c *v2.Conn, info *v2.PathInfo) {
+		infoCh <- info
+	})
+	go func() {
+		if err := conn.ListenAndServe(ctx); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	// sends Echo Request, and responds to it with the restart counter given if
+	// counter is not negative.
+	echo := func(counter int) {
+		t.Helper()
+
+		if _, err := conn.EchoRequest(peer); err != nil {
+			t.Fatal(err)
+		}
+
+		buf := make([]byte, 1500)
+		if err := peerPC.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+			t.Fatal(err)
+		}
+		n, _, err := peerPC.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := message.Parse(buf[:n])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if counter < 0 {
+			return
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		b, err := message.Marshal(message.NewEchoResponse(req.Sequence(), ie.NewRecovery(uint8(counter))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := peerPC.WriteTo(b, pc.LocalAddr()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	receive := func() *v2.PathInfo {
+		t.Helper()
+
+		select {
+		case info := <-infoCh:
+			return info
+		case <-time.After(time.Second):
+			t.Fatal("PathInfo not notified")
+			return nil
+		}
+	}
+
+	echo(3)
+	info := receive()
+	if info.Peer != "127.0.0.70" || info.Sent != 1 || info.Received != 1 || info.Lost != 0 {
+		t.Errorf("wrong PathInfo: %+v", info)
+	}
+	if info.LastRTT < 5*time.Millisecond || info.SmoothedRTT != info.LastRTT {
+		t.Errorf("wrong RTT: last %s, smoothed %s", info.LastRTT, info.SmoothedRTT)
+	}
+
+	// the one not responded is counted as lost when the next is sent.
+	echo(-1)
+	echo(4)
+	if info := receive(); info.Lost != 1 {
+		t.Errorf("wrong number of lost Echo: %d", info.Lost)
+	}
+	info = receive()
+	if info.Sent != 3 || info.Received != 2 {
+		t.Errorf("wrong PathInfo: %+v", info)
+	}
+	if len(info.RestartCounters) != 2 || info.RestartCounters[0].Counter != 3 || info.RestartCounters[1].Counter != 4 {
+		t.Errorf("wrong restart counter history: %v", info.RestartCounters)
+	}
+
+	got, ok := conn.PathInfo(peer)
+	if !ok {
+		t.Fatal("PathInfo not found")
+	}
+	if diff := cmp.Diff(info, got); diff != "" {
+		t.Error(diff)
+	}
+	if _, ok := conn.PathInfo(&net.UDPAddr{IP: net.IP{127, 0, 0, 71}, Port: 2123}); ok {
+		t.Error("PathInfo found for unknown peer")
+	}
+}
+
+func TestHandlersPerConn(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	network := gtptest.NewNetwork()
+	var conns []*v2.Conn
+	for _, addr := range []string{"127.0.0.77", "127.0.0.78"} {
+		pc, err := network.ListenPacket("udp", addr+v2.GTPCPort)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn := v2.NewConnWithPacketConn(pc, v2.IFTypeS11S4SGWGTPC, 0)
+		go func() {
+			if err := conn.ListenAndServe(ctx); err != nil {
+				log.Println(err)
+			}
+		}()
+		conns = append(conns, conn)
+	}
+	peerPC, err := network.ListenPacket("udp", "127.0.0.79"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the handler added to one Conn should not affect the other.
+	handledCh := make(chan net.Addr, 1)
+	conns[0].AddHandler(message.MsgTypeEchoRequest, func(c *v2.Conn, senderAddr net.Addr, msg message.Message) error {
+		handledCh <- c.LocalAddr()
+		return nil
+	})
+
+	res, err := exchange(peerPC, conns[1].LocalAddr(), message.NewEchoRequest(1, ie.NewRecovery(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := res.(*message.EchoResponse); !ok {
+		t.Errorf("unexpected response from the Conn with the default handler: %v", res)
+	}
+	select {
+	case addr := <-handledCh:
+		t.Fatalf("handler added to another Conn is called on %s", addr)
+	default:
+	}
+
+	b, err := message.NewEchoRequest(2, ie.NewRecovery(0)).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := peerPC.WriteTo(b, conns[0].LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case addr := <-handledCh:
+		if got, want := addr.String(), conns[0].LocalAddr().String(); got != want {
+			t.Errorf("handler is called on wrong Conn. want: %s, got: %s", want, got)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for the handler to be called")
+	}
+}
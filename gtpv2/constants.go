@@ -300,3 +300,13 @@ const (
 	DaylightSavingPlusOneHour
 	DaylightSavingPlusTwoHours
 )
+
+// Node type definitions, used to distinguish the owner of FQ-CSID stored in Session.
+const (
+	_ uint8 = iota
+	CSIDNodeMME
+	CSIDNodeSGW
+	CSIDNodePGW
+	CSIDNodeEPDG
+	CSIDNodeTWAN
+)
@@ -0,0 +1,215 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"encoding/hex"
+	"net"
+	"sync"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// FQCSID is a set of PDN Connection Set Identifiers(CSIDs) allocated by a node.
+//
+// NodeID is the IP address of the node in string, or the hex-encoded string if the
+// Node-ID is not an IP address. It can be passed to ie.NewFullyQualifiedCSID as it is.
+type FQCSID struct {
+	NodeID string
+	CSIDs  []uint16
+}
+
+// ParseFQCSID decodes FQ-CSID IE into FQCSID.
+func ParseFQCSID(fqCSIDIE *ie.IE) (*FQCSID, error) {
+	f, err := fqCSIDIE.FullyQualifiedCSID()
+	if err != nil {
+		return nil, err
+	}
+
+	fqCSID := &FQCSID{CSIDs: f.CSIDs}
+	switch f.NodeIDType {
+	case 0, 1: // IPv4, IPv6
+		fqCSID.NodeID = net.IP(f.NodeID).String()
+	default:
+		fqCSID.NodeID = hex.EncodeToString(f.NodeID)
+	}
+	return fqCSID, nil
+}
+
+// PartialFailureHandlerFunc is a handler called when the Sessions are affected by
+// Delete PDN Connection Set Request or Update PDN Connection Set Request.
+//
+// In the case of Delete PDN Connection Set Request, sessions are the ones already
+// removed from Conn, and the handler is expected to release the resources associated
+// with them(e.g., U-Plane tunnels). In the case of Update PDN Connection Set Request,
+// sessions are the ones whose FQ-CSIDs have been updated.
+type PartialFailureHandlerFunc func(c *Conn, senderAddr net.Addr, msg message.Message, sessions []*Session) error
+
+// SetPartialFailureHandler sets the handler called when the Sessions are affected by
+// the partial failure handling procedures. See PartialFailureHandlerFunc for details.
+//
+// The Sessions are removed or updated by the default handlers for Delete/Update PDN
+// Connection Set Request regardless of the existence of the handler. Note that the
+// handler is not called if the default handlers are overridden with AddHandler.
+func (c *Conn) SetPartialFailureHandler(fn PartialFailureHandlerFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.partialFailureHandler = fn
+}
+
+// SetSessionFQCSID associates the FQ-CSID IE given with the Session as the one
+// allocated by the node type given(e.g., CSIDNodeMME), so that the Session can be
+// looked up by the CSIDs later.
+//
+// If the Session already has FQ-CSID of the same node type, it is replaced.
+func (c *Conn) SetSessionFQCSID(sess *Session, nodeType uint8, fqCSIDIE *ie.IE) error {
+	fqCSID, err := ParseFQCSID(fqCSIDIE)
+	if err != nil {
+		return err
+	}
+
+	if old := sess.setFQCSID(nodeType, fqCSID); old != nil {
+		c.csidSessionMap.deleteFQCSID(old, sess)
+	}
+	c.csidSessionMap.storeFQCSID(fqCSID, sess)
+	return nil
+}
+
+// GetSessionsByCSID returns the Sessions associated with the CSID allocated by the
+// node specified by nodeID.
+func (c *Conn) GetSessionsByCSID(nodeID string, csid uint16) []*Session {
+	return c.csidSessionMap.load(csidKey{nodeID, csid})
+}
+
+// GetSessionsByFQCSID returns the Sessions associated with any of the CSIDs in the
+// FQ-CSID IE given.
+func (c *Conn) GetSessionsByFQCSID(fqCSIDIE *ie.IE) ([]*Session, error) {
+	fqCSID, err := ParseFQCSID(fqCSIDIE)
+	if err != nil {
+		return nil, err
+	}
+
+	var ss []*Session
+	seen := map[*Session]struct{}{}
+	for _, csid := range fqCSID.CSIDs {
+		for _, sess := range c.GetSessionsByCSID(fqCSID.NodeID, csid) {
+			if _, ok := seen[sess]; ok {
+				continue
+			}
+			seen[sess] = struct{}{}
+			ss = append(ss, sess)
+		}
+	}
+	return ss, nil
+}
+
+// RemoveSessionsByFQCSID removes all the Sessions associated with any of the CSIDs in
+// the FQ-CSID IEs given, and returns the removed ones. nil IEs are just ignored.
+//
+// This is the core of the handling of Delete PDN Connection Set Request.
+func (c *Conn) RemoveSessionsByFQCSID(fqCSIDIEs ...*ie.IE) ([]*Session, error) {
+	var removed []*Session
+	seen := map[*Session]struct{}{}
+	for _, i := range fqCSIDIEs {
+		if i == nil {
+			continue
+		}
+
+		ss, err := c.GetSessionsByFQCSID(i)
+		if err != nil {
+			return removed, err
+		}
+		for _, sess := range ss {
+			if _, ok := seen[sess]; ok {
+				continue
+			}
+			seen[sess] = struct{}{}
+
+			c.RemoveSession(sess)
+			removed = append(removed, sess)
+		}
+	}
+	return removed, nil
+}
+
+// recordResponseFQCSIDs associates the FQ-CSIDs in Create Session Response accepted
+// by the peer with the Session looked up by TEID, so that the Session can be removed
+// by Delete PDN Connection Set Request from the S-GW or P-GW that allocated them.
+func (c *Conn) recordResponseFQCSIDs(senderAddr net.Addr, msg message.Message) {
+	res, ok := msg.(*message.CreateSessionResponse)
+	if !ok || checkCause(res, res.Cause) != nil {
+		return
+	}
+	sess, err := c.GetSessionByTEID(res.TEID(), senderAddr)
+	if err != nil {
+		return
+	}
+
+	for nodeType, fqCSIDIE := range map[uint8]*ie.IE{
+		CSIDNodePGW: res.PGWFQCSID,
+		CSIDNodeSGW: res.SGWFQCSID,
+	} {
+		if fqCSIDIE == nil {
+			continue
+		}
+		if err := c.SetSessionFQCSID(sess, nodeType, fqCSIDIE); err != nil {
+			logf("failed to set FQ-CSID in %s from %s: %s", res.MessageTypeName(), senderAddr, err)
+		}
+	}
+}
+
+type csidKey struct {
+	nodeID string
+	csid   uint16
+}
+
+type csidSessionMap struct {
+	syncMap sync.Map
+}
+
+func newcsidSessionMap() *csidSessionMap {
+	return &csidSessionMap{}
+}
+
+func (m *csidSessionMap) store(key csidKey, session *Session) {
+	ss, _ := m.syncMap.LoadOrStore(key, &sync.Map{})
+	ss.(*sync.Map).Store(session, struct{}{})
+}
+
+func (m *csidSessionMap) load(key csidKey) []*Session {
+	ss, ok := m.syncMap.Load(key)
+	if !ok {
+		return nil
+	}
+
+	var sessions []*Session
+	ss.(*sync.Map).Range(func(k, v interface{}) bool {
+		sessions = append(sessions, k.(*Session))
+		return true
+	})
+	return sessions
+}
+
+func (m *csidSessionMap) delete(key csidKey, session *Session) {
+	ss, ok := m.syncMap.Load(key)
+	if !ok {
+		return
+	}
+	ss.(*sync.Map).Delete(session)
+}
+
+func (m *csidSessionMap) storeFQCSID(fqCSID *FQCSID, session *Session) {
+	for _, csid := range fqCSID.CSIDs {
+		m.store(csidKey{fqCSID.NodeID, csid}, session)
+	}
+}
+
+func (m *csidSessionMap) deleteFQCSID(fqCSID *FQCSID, session *Session) {
+	for _, csid := range fqCSID.CSIDs {
+		m.delete(csidKey{fqCSID.NodeID, csid}, session)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import "sort"
+
+// The range of EPS Bearer ID that can be assigned to the bearers.
+// 0-4 are reserved in TS 24.007.
+const (
+	MinEBI uint8 = 5
+	MaxEBI uint8 = 15
+)
+
+// AllocateEBI returns the lowest EBI that is neither used by any Bearer in Session
+// nor allocated before, and reserves it until a Bearer with the EBI is added to
+// Session or ReleaseEBI is called.
+//
+// As the EBIs in use are looked up from the Bearers, an EBI is freed automatically
+// when the Bearer is removed from Session.
+func (s *Session) AllocateEBI() (uint8, error) {
+	used := s.usedEBIs()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ebi := MinEBI; ebi <= MaxEBI; ebi++ {
+		if _, ok := used[ebi]; ok {
+			continue
+		}
+		if _, ok := s.ebiReserved[ebi]; ok {
+			continue
+		}
+		s.ebiReserved[ebi] = struct{}{}
+		return ebi, nil
+	}
+	return 0, ErrNoEBIAvailable
+}
+
+// ReleaseEBI releases the EBI allocated with AllocateEBI, which is not used by any
+// Bearer, e.g., when the peer rejected the bearer creation.
+func (s *Session) ReleaseEBI(ebi uint8) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.ebiReserved, ebi)
+}
+
+// AddBearerWithEBI adds a Bearer to Session with the name given, in the same way as
+// AddBearer, but ensures that the EBI of br is valid and unique in Session.
+//
+// If the EBI of br is 0, a new one is allocated with AllocateEBI and set to br.
+// It returns ErrInvalidEBI if the EBI is out of range, and ErrEBIInUse if it is used
+// by another Bearer in Session.
+func (s *Session) AddBearerWithEBI(name string, br *Bearer) error {
+	if br.EBI == 0 {
+		ebi, err := s.AllocateEBI()
+		if err != nil {
+			return err
+		}
+		br.EBI = ebi
+	}
+	if br.EBI < MinEBI || br.EBI > MaxEBI {
+		return ErrInvalidEBI
+	}
+	if existing, err := s.LookupBearerNameByEBI(br.EBI); err == nil && existing != name {
+		return ErrEBIInUse
+	}
+
+	s.AddBearer(name, br)
+	return nil
+}
+
+// DefaultEBI returns the EBI of the default bearer, or 0 if it is not assigned yet.
+func (s *Session) DefaultEBI() uint8 {
+	if br := s.GetDefaultBearer(); br != nil {
+		return br.EBI
+	}
+	return 0
+}
+
+// DedicatedEBIs returns the EBIs of the bearers other than the default bearer in
+// ascending order.
+func (s *Session) DedicatedEBIs() []uint8 {
+	var ebis []uint8
+	s.bearerMap.rangeWithFunc(func(name, bearer interface{}) bool {
+		if br := bearer.(*Bearer); name.(string) != "default" && br.EBI != 0 {
+			ebis = append(ebis, br.EBI)
+		}
+		return true
+	})
+
+	sort.Slice(ebis, func(i, j int) bool { return ebis[i] < ebis[j] })
+	return ebis
+}
+
+// usedEBIs returns the set of EBIs used by the Bearers in Session.
+func (s *Session) usedEBIs() map[uint8]struct{} {
+	used := map[uint8]struct{}{}
+	s.bearerMap.rangeWithFunc(func(name, bearer interface{}) bool {
+		if br := bearer.(*Bearer); br.EBI != 0 {
+			used[br.EBI] = struct{}{}
+		}
+		return true
+	})
+	return used
+}
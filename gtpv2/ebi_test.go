@@ -0,0 +1,126 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/wmnsk/go-gtp/gtptest"
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+func TestEBIAllocation(t *testing.T) {
+	sess := v2.NewSession(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, &v2.Subscriber{IMSI: "123451234567890"})
+
+	ebi, err := sess.AllocateEBI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ebi != v2.MinEBI {
+		t.Errorf("wrong EBI. want: %d, got: %d", v2.MinEBI, ebi)
+	}
+	sess.GetDefaultBearer().EBI = ebi
+	if got := sess.DefaultEBI(); got != 5 {
+		t.Errorf("wrong default EBI: %d", got)
+	}
+
+	// reserved, but not used by any bearer yet.
+	reserved, err := sess.AllocateEBI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reserved != 6 {
+		t.Errorf("wrong EBI. want: %d, got: %d", 6, reserved)
+	}
+
+	if err := sess.AddBearerWithEBI("dedicated1", v2.NewBearer(0, "", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sess.AddBearerWithEBI("dedicated2", v2.NewBearer(reserved, "", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]uint8{6, 7}, sess.DedicatedEBIs()); diff != "" {
+		t.Error(diff)
+	}
+
+	if err := sess.AddBearerWithEBI("dup", v2.NewBearer(7, "", nil)); err != v2.ErrEBIInUse {
+		t.Errorf("want ErrEBIInUse, got %v", err)
+	}
+	for _, ebi := range []uint8{4, 16} {
+		if err := sess.AddBearerWithEBI("invalid", v2.NewBearer(ebi, "", nil)); err != v2.ErrInvalidEBI {
+			t.Errorf("want ErrInvalidEBI for %d, got %v", ebi, err)
+		}
+	}
+
+	for i := 8; i <= 15; i++ {
+		if _, err := sess.AllocateEBI(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := sess.AllocateEBI(); err != v2.ErrNoEBIAvailable {
+		t.Errorf("want ErrNoEBIAvailable, got %v", err)
+	}
+
+	// EBIs are freed when released or the bearer is removed.
+	sess.ReleaseEBI(10)
+	sess.RemoveBearerByEBI(6)
+	for _, want := range []uint8{6, 10} {
+		got, err := sess.AllocateEBI()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("wrong EBI. want: %d, got: %d", want, got)
+		}
+	}
+}
+
+func TestCreateBearerResponseEBIOnFailure(t *testing.T) {
+	pc, peerPC, err := gtptest.Pipe("127.0.0.70"+v2.GTPCPort, "127.0.0.71"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := v2.NewConnWithPacketConn(pc, v2.IFTypeS5S8SGWGTPC, 0)
+	// make the responses fail to be sent.
+	if err := pc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	sess := v2.NewSession(peerPC.LocalAddr(), &v2.Subscriber{IMSI: "123451234567890"})
+	req := message.NewCreateBearerRequest(0, 1)
+
+	// the EBI given by the caller is kept reserved.
+	given, err := sess.AllocateEBI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	br := v2.NewBearer(given, "", nil)
+	if err := conn.CreateBearerResponse(0, sess, req, "given", br, v2.CauseRequestAccepted); err == nil {
+		t.Fatal("CreateBearerResponse succeeded on closed Conn")
+	}
+	if br.EBI != given {
+		t.Errorf("EBI of bearer changed. want: %d, got: %d", given, br.EBI)
+	}
+
+	// the EBI allocated in CreateBearerResponse is released.
+	br = v2.NewBearer(0, "", nil)
+	if err := conn.CreateBearerResponse(0, sess, req, "allocated", br, v2.CauseRequestAccepted); err == nil {
+		t.Fatal("CreateBearerResponse succeeded on closed Conn")
+	}
+	if br.EBI != 0 {
+		t.Errorf("EBI allocated is left in bearer: %d", br.EBI)
+	}
+
+	ebi, err := sess.AllocateEBI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := given + 1; ebi != want {
+		t.Errorf("wrong EBI. want: %d, got: %d", want, ebi)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"io"
+	"net"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// EnableErrorResponse turns on the automatic error responses to the invalid requests
+// based on TS 29.274 7.7 Error Handling. It is disabled by default.
+//
+// When enabled, Conn responds to a request with the Cause below instead of leaving
+// the peer to time out;
+//
+//	Cause "Invalid Length": the request is truncated or has inconsistent length
+//	Cause "Invalid Message Format": the request cannot be parsed for other reasons
+//	Cause "Context Not Found": the TEID is unknown to Conn(only if validation is enabled)
+//	Cause "Mandatory IE missing": HandlerFunc returned *RequiredIEMissingError
+//	Cause "Mandatory IE incorrect": HandlerFunc returned *InvalidIEError
+//
+// The messages too short to have the header, the messages that are not requests, and
+// the ones of unknown type are discarded silently, as the spec requires. With the
+// TEID unknown, the request is not passed to HandlerFunc.
+//
+// The error responses are sent with TEID=0, except for Create Session Request, which
+// is responded with the TEID in Sender F-TEID for Control Plane if available. The
+// request that HandlerFunc has already responded to(with RespondTo) is not responded
+// again even if it returns the error above.
+func (c *Conn) EnableErrorResponse() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errorResponseEnabled = true
+}
+
+// DisableErrorResponse turns off the automatic error responses.
+//
+// See EnableErrorResponse for what are responded.
+func (c *Conn) DisableErrorResponse() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errorResponseEnabled = false
+}
+
+func (c *Conn) errorResponseIsEnabled() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.errorResponseEnabled
+}
+
+// respondToMalformed responds to the request that failed to be parsed.
+func (c *Conn) respondToMalformed(raddr net.Addr, raw []byte, parseErr error) error {
+	h, err := message.ParseHeader(raw)
+	if err != nil {
+		// discard silently, as the header is not available.
+		return nil
+	}
+
+	cause := CauseInvalidMessageFormat
+	for _, e := range []error{
+		message.ErrInvalidLength, message.ErrTooShortToParse,
+		ie.ErrInvalidLength, ie.ErrTooShortToParse, io.ErrUnexpectedEOF,
+	} {
+		if errors.Is(parseErr, e) {
+			cause = CauseInvalidLength
+			break
+		}
+	}
+
+	return c.respondWithError(raddr, h.Type, 0, h.Sequence(), cause, 0)
+}
+
+// respondToInvalid responds to the request that is parsed but failed to be handled,
+// if the error is the one to be responded. It reports whether it responded.
+func (c *Conn) respondToInvalid(raddr net.Addr, msg message.Message, handleErr error) bool {
+	if _, ok := errorResponseTypes[msg.MessageType()]; !ok {
+		return false
+	}
+
+	var (
+		cause       uint8
+		offendingIE uint8
+	)
+	switch err := errors.Cause(handleErr).(type) {
+	case *InvalidTEIDError:
+		cause = CauseContextNotFound
+	case *RequiredIEMissingError:
+		cause, offendingIE = CauseMandatoryIEMissing, err.Type
+	case *InvalidIEError:
+		cause, offendingIE = CauseMandatoryIEIncorrect, err.Type
+	default:
+		return false
+	}
+
+	var teid uint32
+	if csReq, ok := msg.(*message.CreateSessionRequest); ok && csReq.SenderFTEIDC != nil {
+		teid, _ = csReq.SenderFTEIDC.TEID()
+	}
+
+	if err := c.respondWithError(raddr, msg.MessageType(), teid, msg.Sequence(), cause, offendingIE); err != nil {
+		logf("failed to respond to %s with Cause %d: %s", msg.MessageTypeName(), cause, err)
+		return false
+	}
+	return true
+}
+
+// respondWithError responds to the request with the Cause given. Nothing is sent if
+// reqType is not a request. offendingIE is omitted if 0.
+func (c *Conn) respondWithError(raddr net.Addr, reqType uint8, teid, seq uint32, cause, offendingIE uint8) error {
+	rspType, ok := errorResponseTypes[reqType]
+	if !ok {
+		return nil
+	}
+
+	var offending *ie.IE
+	if offendingIE != 0 {
+		offending = &ie.IE{Type: offendingIE}
+	}
+
+	b, err := message.Marshal(message.NewGeneric(
+		rspType, teid, seq, ie.NewCause(cause, 0, 0, 0, offending),
+	))
+	if err != nil {
+		return err
+	}
+	c.cacheResponse(raddr, reqType, seq, b)
+	c.markResponded(raddr, reqType, seq)
+
+	_, err = c.WriteTo(b, raddr)
+	return err
+}
+
+// trackResponse starts tracking whether the request msg from raddr is responded while
+// it is handled. The function returned stops it and reports whether it is responded.
+func (c *Conn) trackResponse(raddr net.Addr, msg message.Message) func() bool {
+	if _, ok := errorResponseTypes[msg.MessageType()]; !ok {
+		return func() bool { return false }
+	}
+
+	key := newResponseCacheKey(raddr, msg.MessageType(), msg.Sequence())
+	responded := new(uint32)
+	c.handlingRequests.Store(key, responded)
+	return func() bool {
+		c.handlingRequests.Delete(key)
+		return atomic.LoadUint32(responded) != 0
+	}
+}
+
+// markResponded records that the request of reqType with seq from raddr is responded,
+// if it is being handled.
+func (c *Conn) markResponded(raddr net.Addr, reqType uint8, seq uint32) {
+	if v, ok := c.handlingRequests.Load(newResponseCacheKey(raddr, reqType, seq)); ok {
+		atomic.StoreUint32(v.(*uint32), 1)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
 	"github.com/wmnsk/go-gtp/gtpv2/message"
 )
 
@@ -15,9 +16,33 @@ var (
 	// ErrTEIDNotFound indicates that TEID is not registered for the interface specified.
 	ErrTEIDNotFound = errors.New("no TEID found")
 
+	// ErrFQCSIDNotFound indicates that FQ-CSID is not registered for the node type specified.
+	ErrFQCSIDNotFound = errors.New("no FQ-CSID found")
+
 	// ErrTimeout indicates that a handler failed to complete its work due to the
 	// absence of message expected to come from another endpoint.
 	ErrTimeout = errors.New("timed out")
+
+	// ErrSendQueueFull indicates that the packet is not sent as the queue enabled
+	// with EnableSendQueue is full.
+	ErrSendQueueFull = errors.New("send queue is full")
+
+	// ErrInvalidEBI indicates that the EBI is out of the range for EPS bearers(5-15).
+	ErrInvalidEBI = errors.New("invalid EBI")
+
+	// ErrEBIInUse indicates that the EBI is already used by another Bearer in the Session.
+	ErrEBIInUse = errors.New("EBI already in use")
+
+	// ErrNoEBIAvailable indicates that all the EBIs are used in the Session.
+	ErrNoEBIAvailable = errors.New("no EBI available")
+
+	// ErrNoChargingIDAvailable indicates that ChargingIDAllocator failed to find a
+	// Charging ID that is not in use.
+	ErrNoChargingIDAvailable = errors.New("no Charging ID available")
+
+	// ErrThrottledByOverload indicates that the request is not sent as the peer is in
+	// overload. See EnableOverloadControl.
+	ErrThrottledByOverload = errors.New("request throttled due to the overload of peer")
 )
 
 // CauseNotOKError indicates that the value in Cause IE is not OK.
@@ -32,6 +57,30 @@ func (e *CauseNotOKError) Error() string {
 	return fmt.Sprintf("got non-OK Cause: %d in %s; %s", e.Cause, e.MsgType, e.Msg)
 }
 
+// checkCause returns *CauseNotOKError if the Cause IE given does not indicate that
+// the request is accepted, and *RequiredIEMissingError if it is missing.
+func checkCause(msg message.Message, causeIE *ie.IE) error {
+	if causeIE == nil {
+		return &RequiredIEMissingError{Type: ie.Cause}
+	}
+
+	cause, err := causeIE.Cause()
+	if err != nil {
+		return err
+	}
+
+	switch cause {
+	case CauseRequestAccepted, CauseRequestAcceptedPartially,
+		CauseNewPDNTypeDueToNetworkPreference, CauseNewPDNTypeDueToSingleAddressBearerOnly:
+		return nil
+	}
+	return &CauseNotOKError{
+		MsgType: msg.MessageTypeName(),
+		Cause:   cause,
+		Msg:     "request rejected by peer",
+	}
+}
+
 // RequiredIEMissingError indicates that the IE required is missing.
 type RequiredIEMissingError struct {
 	Type uint8
@@ -42,6 +91,22 @@ func (e *RequiredIEMissingError) Error() string {
 	return fmt.Sprintf("required IE missing: %d", e.Type)
 }
 
+// InvalidIEError indicates that the IE required is present but incorrect.
+type InvalidIEError struct {
+	Type uint8
+	Err  error
+}
+
+//x Error returns error with incorrect IE type.
+func (e *InvalidIEError) Error() string {
+	return fmt.Sprintf("invalid IE: %d, %v", e.Type, e.Err)
+}
+
+// Unwrap returns the error that made the IE invalid.
+func (e *InvalidIEError) Unwrap() error {
+	return e.Err
+}
+
 // RequiredParameterMissingError indicates that no Bearer found by lookup methods.
 type RequiredParameterMissingError struct {
 	Name, Msg string
@@ -114,6 +179,16 @@ func (e *UnknownIMSIError) Error() string {
 	return fmt.Sprintf("got unknown IMSI: %s", e.IMSI)
 }
 
+// UnknownMSISDNError indicates that the MSISDN is different from expected one.
+type UnknownMSISDNError struct {
+	MSISDN string
+}
+
+//x Error returns violating MSISDN.
+func (e *UnknownMSISDNError) Error() string {
+	return fmt.Sprintf("got unknown MSISDN: %s", e.MSISDN)
+}
+
 // UnknownAPNError indicates that the APN is different from expected one.
 type UnknownAPNError struct {
 	APN string
@@ -0,0 +1,87 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"time"
+)
+
+// EnableSessionExpiry starts removing the Sessions that have been idle for longer
+// than idleTimeout, checking every checkInterval.
+//
+// A Session is considered idle if no message has been received for it, and Touch
+// has not been called, e.g., by the keepalive callback of U-Plane. Before removing
+// an idle Session, OnSessionExpired in LifecycleHooks is called, and then
+// OnSessionDeleted is called as usual in RemoveSession.
+//
+// Calling this again replaces the timers. The expiry stops when the Conn is closed
+// or DisableSessionExpiry is called.
+func (c *Conn) EnableSessionExpiry(idleTimeout, checkInterval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.expiryStopCh != nil {
+		close(c.expiryStopCh)
+	}
+	c.expiryStopCh = make(chan struct{})
+
+	go c.expireSessions(idleTimeout, checkInterval, c.expiryStopCh)
+}
+
+// DisableSessionExpiry stops the expiry of idle Sessions started by EnableSessionExpiry.
+func (c *Conn) DisableSessionExpiry() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.expiryStopCh != nil {
+		close(c.expiryStopCh)
+		c.expiryStopCh = nil
+	}
+}
+
+func (c *Conn) expireSessions(idleTimeout, checkInterval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-c.closed():
+			return
+		case now := <-ticker.C:
+			var expired []*Session
+			c.RangeSessions(func(sess *Session) bool {
+				if now.Sub(sess.LastSeen()) > idleTimeout {
+					expired = append(expired, sess)
+				}
+				return true
+			})
+
+			for _, sess := range expired {
+				sess.fireSessionEvent(func(h *LifecycleHooks) SessionHookFunc { return h.OnSessionExpired })
+				c.RemoveSession(sess)
+			}
+		}
+	}
+}
+
+// Touch marks a Session as active at the moment, to prevent it from being expired
+// by EnableSessionExpiry. This is done automatically when a message for the
+// Session is received.
+func (s *Session) Touch() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastSeen = time.Now()
+}
+
+// LastSeen returns the last time a message for the Session is received or Touch is called.
+func (s *Session) LastSeen() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastSeen
+}
@@ -7,6 +7,7 @@ package gtpv2
 import (
 	"net"
 	"sync"
+	"time"
 
 	"github.com/wmnsk/go-gtp/gtpv2/ie"
 	"github.com/wmnsk/go-gtp/gtpv2/message"
@@ -41,13 +42,77 @@ func newMsgHandlerMap(m map[uint8]HandlerFunc) *msgHandlerMap {
 	return mhm
 }
 
-var defaultHandlerMap = newMsgHandlerMap(
-	map[uint8]HandlerFunc{
-		message.MsgTypeEchoRequest:                   handleEchoRequest,
-		message.MsgTypeEchoResponse:                  handleEchoResponse,
-		message.MsgTypeVersionNotSupportedIndication: handleVersionNotSupportedIndication,
-	},
-)
+// newDefaultMsgHandlerMap returns the msgHandlerMap with the default handlers,
+// which is created for each connection so that the handlers added to one do not
+// affect the others.
+func newDefaultMsgHandlerMap() *msgHandlerMap {
+	return newMsgHandlerMap(
+		map[uint8]HandlerFunc{
+			message.MsgTypeEchoRequest:                   handleEchoRequest,
+			message.MsgTypeEchoResponse:                  handleEchoResponse,
+			message.MsgTypeVersionNotSupportedIndication: handleVersionNotSupportedIndication,
+			message.MsgTypeModifyBearerResponse:          handleModifyBearerResponse,
+			message.MsgTypeCreateBearerResponse:          handleCreateBearerResponse,
+
+			message.MsgTypeDeletePDNConnectionSetRequest: handleDeletePDNConnectionSetRequest,
+			message.MsgTypeUpdatePDNConnectionSetRequest: handleUpdatePDNConnectionSetRequest,
+
+			message.MsgTypeCreateIndirectDataForwardingTunnelResponse: handleCreateIndirectDataForwardingTunnelResponse,
+			message.MsgTypeDeleteIndirectDataForwardingTunnelResponse: handleDeleteIndirectDataForwardingTunnelResponse,
+		},
+	)
+}
+
+// errorResponseTypes is the message types used to respond to the requests with
+// non-OK Cause, keyed by the type of the request.
+//
+// Echo Request is not included, as Echo Response cannot have Cause IE.
+var errorResponseTypes = map[uint8]uint8{
+	message.MsgTypeDirectTransferRequest:                     message.MsgTypeDirectTransferResponse,
+	message.MsgTypeNotificationRequest:                       message.MsgTypeNotificationResponse,
+	message.MsgTypeSRVCCPsToCsRequest:                        message.MsgTypeSRVCCPsToCsResponse,
+	message.MsgTypeSRVCCPsToCsCompleteNotification:           message.MsgTypeSRVCCPsToCsCompleteAcknowledge,
+	message.MsgTypeSRVCCPsToCsCancelNotification:             message.MsgTypeSRVCCPsToCsCancelAcknowledge,
+	message.MsgTypeSRVCCCsToPsRequest:                        message.MsgTypeSRVCCCsToPsResponse,
+	message.MsgTypeSRVCCCsToPsCompleteNotification:           message.MsgTypeSRVCCCsToPsCompleteAcknowledge,
+	message.MsgTypeSRVCCCsToPsCancelNotification:             message.MsgTypeSRVCCCsToPsCancelAcknowledge,
+	message.MsgTypeCreateSessionRequest:                      message.MsgTypeCreateSessionResponse,
+	message.MsgTypeModifyBearerRequest:                       message.MsgTypeModifyBearerResponse,
+	message.MsgTypeDeleteSessionRequest:                      message.MsgTypeDeleteSessionResponse,
+	message.MsgTypeChangeNotificationRequest:                 message.MsgTypeChangeNotificationResponse,
+	message.MsgTypeRemoteUEReportNotification:                message.MsgTypeRemoteUEReportAcknowledge,
+	message.MsgTypeModifyBearerCommand:                       message.MsgTypeModifyBearerFailureIndication,
+	message.MsgTypeDeleteBearerCommand:                       message.MsgTypeDeleteBearerFailureIndication,
+	message.MsgTypeBearerResourceCommand:                     message.MsgTypeBearerResourceFailureIndication,
+	message.MsgTypeCreateBearerRequest:                       message.MsgTypeCreateBearerResponse,
+	message.MsgTypeUpdateBearerRequest:                       message.MsgTypeUpdateBearerResponse,
+	message.MsgTypeDeleteBearerRequest:                       message.MsgTypeDeleteBearerResponse,
+	message.MsgTypeDeletePDNConnectionSetRequest:             message.MsgTypeDeletePDNConnectionSetResponse,
+	message.MsgTypePGWDownlinkTriggeringNotification:         message.MsgTypePGWDownlinkTriggeringAcknowledge,
+	message.MsgTypeIdentificationRequest:                     message.MsgTypeIdentificationResponse,
+	message.MsgTypeContextRequest:                            message.MsgTypeContextResponse,
+	message.MsgTypeForwardRelocationRequest:                  message.MsgTypeForwardRelocationResponse,
+	message.MsgTypeForwardRelocationCompleteNotification:     message.MsgTypeForwardRelocationCompleteAcknowledge,
+	message.MsgTypeForwardAccessContextNotification:          message.MsgTypeForwardAccessContextAcknowledge,
+	message.MsgTypeRelocationCancelRequest:                   message.MsgTypeRelocationCancelResponse,
+	message.MsgTypeDetachNotification:                        message.MsgTypeDetachAcknowledge,
+	message.MsgTypeAlertMMENotification:                      message.MsgTypeAlertMMEAcknowledge,
+	message.MsgTypeUEActivityNotification:                    message.MsgTypeUEActivityAcknowledge,
+	message.MsgTypeUERegistrationQueryRequest:                message.MsgTypeUERegistrationQueryResponse,
+	message.MsgTypeCreateForwardingTunnelRequest:             message.MsgTypeCreateForwardingTunnelResponse,
+	message.MsgTypeSuspendNotification:                       message.MsgTypeSuspendAcknowledge,
+	message.MsgTypeResumeNotification:                        message.MsgTypeResumeAcknowledge,
+	message.MsgTypeCreateIndirectDataForwardingTunnelRequest: message.MsgTypeCreateIndirectDataForwardingTunnelResponse,
+	message.MsgTypeDeleteIndirectDataForwardingTunnelRequest: message.MsgTypeDeleteIndirectDataForwardingTunnelResponse,
+	message.MsgTypeReleaseAccessBearersRequest:               message.MsgTypeReleaseAccessBearersResponse,
+	message.MsgTypeDownlinkDataNotification:                  message.MsgTypeDownlinkDataNotificationAcknowledge,
+	message.MsgTypePGWRestartNotification:                    message.MsgTypePGWRestartNotificationAcknowledge,
+	message.MsgTypeUpdatePDNConnectionSetRequest:             message.MsgTypeUpdatePDNConnectionSetResponse,
+	message.MsgTypeModifyAccessBearersRequest:                message.MsgTypeModifyAccessBearersResponse,
+	message.MsgTypeMBMSSessionStartRequest:                   message.MsgTypeMBMSSessionStartResponse,
+	message.MsgTypeMBMSSessionUpdateRequest:                  message.MsgTypeMBMSSessionUpdateResponse,
+	message.MsgTypeMBMSSessionStopRequest:                    message.MsgTypeMBMSSessionStopResponse,
+}
 
 func handleEchoRequest(c *Conn, senderAddr net.Addr, msg message.Message) error {
 	// this should never happen, as the type should have been assured by
@@ -80,6 +145,175 @@ func handleVersionNotSupportedIndication(c *Conn, senderAddr net.Addr, msg messa
 		return &UnexpectedTypeError{Msg: msg}
 	}
 
+	if c.fallbackVersion(senderAddr, msg.Sequence()) {
+		return nil
+	}
+
 	// let's just return err anyway.
 	return &InvalidVersionError{Version: msg.Version()}
 }
+
+// handleModifyBearerResponse passes the message to the Session looked up by TEID,
+// which is expected to be waiting for it in ModifyBearerWithWait.
+func handleModifyBearerResponse(c *Conn, senderAddr net.Addr, msg message.Message) error {
+	// this should never happen, as the type should have been assured by
+	// msgHandlerMap before this function is called.
+	if _, ok := msg.(*message.ModifyBearerResponse); !ok {
+		return &UnexpectedTypeError{Msg: msg}
+	}
+
+	return passMessageToSession(c, senderAddr, msg)
+}
+
+// handleCreateBearerResponse passes the message to the Session looked up by TEID,
+// which is expected to be waiting for it in CreateBearerWithWait.
+func handleCreateBearerResponse(c *Conn, senderAddr net.Addr, msg message.Message) error {
+	// this should never happen, as the type should have been assured by
+	// msgHandlerMap before this function is called.
+	if _, ok := msg.(*message.CreateBearerResponse); !ok {
+		return &UnexpectedTypeError{Msg: msg}
+	}
+
+	return passMessageToSession(c, senderAddr, msg)
+}
+
+// handleCreateIndirectDataForwardingTunnelResponse passes the message to the Session
+// looked up by TEID, which is expected to be waiting for it in S1Handover.
+func handleCreateIndirectDataForwardingTunnelResponse(c *Conn, senderAddr net.Addr, msg message.Message) error {
+	// this should never happen, as the type should have been assured by
+	// msgHandlerMap before this function is called.
+	if _, ok := msg.(*message.CreateIndirectDataForwardingTunnelResponse); !ok {
+		return &UnexpectedTypeError{Msg: msg}
+	}
+
+	return passMessageToSession(c, senderAddr, msg)
+}
+
+// handleDeleteIndirectDataForwardingTunnelResponse passes the message to the Session
+// looked up by TEID, which is expected to be waiting for it in S1Handover.
+func handleDeleteIndirectDataForwardingTunnelResponse(c *Conn, senderAddr net.Addr, msg message.Message) error {
+	// this should never happen, as the type should have been assured by
+	// msgHandlerMap before this function is called.
+	if _, ok := msg.(*message.DeleteIndirectDataForwardingTunnelResponse); !ok {
+		return &UnexpectedTypeError{Msg: msg}
+	}
+
+	return passMessageToSession(c, senderAddr, msg)
+}
+
+// handleDeletePDNConnectionSetRequest removes all the Sessions associated with the
+// FQ-CSIDs in the message and calls the partial failure handler if set.
+func handleDeletePDNConnectionSetRequest(c *Conn, senderAddr net.Addr, msg message.Message) error {
+	// this should never happen, as the type should have been assured by
+	// msgHandlerMap before this function is called.
+	req, ok := msg.(*message.DeletePDNConnectionSetRequest)
+	if !ok {
+		return &UnexpectedTypeError{Msg: msg}
+	}
+
+	// the Sessions removed before the error are still reported to the handler, as they
+	// are no longer in Conn.
+	cause := CauseRequestAccepted
+	sessions, err := c.RemoveSessionsByFQCSID(
+		req.MMEFQCSID, req.SGWFQCSID, req.PGWFQCSID, req.EPDGFQCSID, req.TWANFQCSID,
+	)
+	if err != nil {
+		logf("failed to remove Sessions by FQ-CSID from %s: %s", senderAddr, err)
+		cause = CauseMandatoryIEIncorrect
+	}
+
+	if err := c.RespondTo(
+		senderAddr, msg,
+		message.NewDeletePDNConnectionSetResponse(
+			0, 0, ie.NewCause(cause, 0, 0, 0, nil),
+		),
+	); err != nil {
+		logf("failed to respond to %s from %s: %s", msg.MessageTypeName(), senderAddr, err)
+	}
+
+	if cause != CauseRequestAccepted && len(sessions) == 0 {
+		return nil
+	}
+	return c.callPartialFailureHandler(senderAddr, msg, sessions)
+}
+
+// handleUpdatePDNConnectionSetRequest updates the FQ-CSIDs of the Session looked up
+// by TEID with the ones in the message and calls the partial failure handler if set.
+func handleUpdatePDNConnectionSetRequest(c *Conn, senderAddr net.Addr, msg message.Message) error {
+	// this should never happen, as the type should have been assured by
+	// msgHandlerMap before this function is called.
+	req, ok := msg.(*message.UpdatePDNConnectionSetRequest)
+	if !ok {
+		return &UnexpectedTypeError{Msg: msg}
+	}
+
+	sess, err := c.GetSessionByTEID(msg.TEID(), senderAddr)
+	if err != nil {
+		logf("failed to handle %s from %s: %s", msg.MessageTypeName(), senderAddr, err)
+		return c.RespondTo(
+			senderAddr, msg,
+			message.NewUpdatePDNConnectionSetResponse(
+				0, 0, ie.NewCause(CauseContextNotFound, 0, 0, 0, nil),
+			),
+		)
+	}
+
+	// the response is sent with TEID=0 if the peer's TEID is unknown for some reason.
+	teid, _ := sess.GetTEID(IFTypeS5S8SGWGTPC)
+
+	for nodeType, fqCSIDIE := range map[uint8]*ie.IE{
+		CSIDNodeMME: req.MMEFQCSID,
+		CSIDNodeSGW: req.SGWFQCSID,
+	} {
+		if fqCSIDIE == nil {
+			continue
+		}
+		if err := c.SetSessionFQCSID(sess, nodeType, fqCSIDIE); err != nil {
+			logf("failed to handle %s from %s: %s", msg.MessageTypeName(), senderAddr, err)
+			return c.RespondTo(
+				senderAddr, msg,
+				message.NewUpdatePDNConnectionSetResponse(
+					teid, 0, ie.NewCause(CauseMandatoryIEIncorrect, 0, 0, 0, nil),
+				),
+			)
+		}
+	}
+
+	var pgwFQCSIDIE *ie.IE
+	if fqCSID, err := sess.GetFQCSID(CSIDNodePGW); err == nil {
+		pgwFQCSIDIE = ie.NewFullyQualifiedCSID(fqCSID.NodeID, fqCSID.CSIDs...)
+	}
+	if err := c.RespondTo(
+		senderAddr, msg,
+		message.NewUpdatePDNConnectionSetResponse(
+			teid, 0, ie.NewCause(CauseRequestAccepted, 0, 0, 0, nil), pgwFQCSIDIE,
+		),
+	); err != nil {
+		return err
+	}
+
+	return c.callPartialFailureHandler(senderAddr, msg, []*Session{sess})
+}
+
+// callPartialFailureHandler calls the partial failure handler if set.
+func (c *Conn) callPartialFailureHandler(senderAddr net.Addr, msg message.Message, sessions []*Session) error {
+	c.mu.Lock()
+	fn := c.partialFailureHandler
+	c.mu.Unlock()
+
+	if fn == nil {
+		return nil
+	}
+	return fn(c, senderAddr, msg, sessions)
+}
+
+// passMessageToSession passes the message to the Session looked up by TEID and
+// the sender of the message.
+func passMessageToSession(c *Conn, senderAddr net.Addr, msg message.Message) error {
+	sess, err := c.GetSessionByTEID(msg.TEID(), senderAddr)
+	if err != nil {
+		return err
+	}
+
+	return PassMessageTo(sess, msg, 5*time.Second)
+}
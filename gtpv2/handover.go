@@ -0,0 +1,266 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"context"
+	"time"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// S1HandoverHooks is a set of functions called at each step of S1Handover.
+//
+// Any of them except OnHandoverExecution can be nil. If a hook returns error, the procedure is aborted and the
+// forwarding tunnel is deleted if it has already been created.
+type S1HandoverHooks struct {
+	// OnForwardingTunnelCreated is called after Create Indirect Data Forwarding Tunnel
+	// Response is received with acceptance.
+	OnForwardingTunnelCreated func(sess *Session, res *message.CreateIndirectDataForwardingTunnelResponse) error
+
+	// OnHandoverExecution is called after the forwarding tunnel is created, and is
+	// expected to block until the handover is executed on the radio side(e.g., until
+	// the target eNB sends Handover Notify). It returns the IEs to be used in
+	// Modify Bearer Request, typically the Bearer Context with the F-TEID of target eNB.
+	//
+	// This is required by Run, as Modify Bearer Request cannot be built without it.
+	OnHandoverExecution func(ctx context.Context, sess *Session) ([]*ie.IE, error)
+
+	// OnBearerModified is called after Modify Bearer Response is received with acceptance.
+	OnBearerModified func(sess *Session, res *message.ModifyBearerResponse) error
+
+	// OnForwardingTunnelDeleted is called after Delete Indirect Data Forwarding Tunnel
+	// Response is received.
+	OnForwardingTunnelDeleted func(sess *Session, res *message.DeleteIndirectDataForwardingTunnelResponse) error
+}
+
+// S1Handover orchestrates the GTPv2-C part of S1-based handover with indirect data
+// forwarding on S11, in the following order;
+//
+//  Create Indirect Data Forwarding Tunnel Request/Response
+//  (handover execution on the radio side)
+//  Modify Bearer Request/Response
+//  (indirect forwarding timer expires)
+//  Delete Indirect Data Forwarding Tunnel Request/Response
+//
+// Each step can be performed one by one, or all at once with Run.
+type S1Handover struct {
+	conn *Conn
+	sess *Session
+	teid uint32
+
+	fwdCreated bool
+
+	// fwdFTEIDs is the F-TEIDs for the forwarding tunnel by EBI, which are kept apart
+	// from the ones in Session, as they share the interface types with the F-TEIDs
+	// used for the bearers(e.g., S1-U eNodeB).
+	fwdFTEIDs map[uint8][]*ie.IE
+
+	// Timeout is the time to wait for each response. Default is 5 seconds.
+	Timeout time.Duration
+
+	// ForwardingDuration is the time to keep the forwarding tunnel after the bearer is
+	// modified(=indirect forwarding timer). Default is 3 seconds.
+	ForwardingDuration time.Duration
+
+	*S1HandoverHooks
+}
+
+// NewS1Handover creates a new S1Handover for the Session given.
+//
+// teid is the TEID of the peer(=S-GW) to be set in the requests.
+func NewS1Handover(c *Conn, sess *Session, teid uint32, hooks *S1HandoverHooks) *S1Handover {
+	if hooks == nil {
+		hooks = &S1HandoverHooks{}
+	}
+
+	return &S1Handover{
+		conn:               c,
+		sess:               sess,
+		teid:               teid,
+		Timeout:            5 * time.Second,
+		ForwardingDuration: 3 * time.Second,
+		S1HandoverHooks:    hooks,
+	}
+}
+
+// Run performs all the steps of S1Handover in order.
+//
+// The IEs given are used in Create Indirect Data Forwarding Tunnel Request, and the ones
+// returned by OnHandoverExecution hook are used in Modify Bearer Request.
+//
+// If ctx is canceled while waiting for the handover execution or the expiry of the
+// indirect forwarding timer, the forwarding tunnel is deleted immediately.
+//
+// It returns *RequiredParameterMissingError without sending anything if
+// OnHandoverExecution is not set.
+func (h *S1Handover) Run(ctx context.Context, ies ...*ie.IE) error {
+	fn := h.OnHandoverExecution
+	if fn == nil {
+		return &RequiredParameterMissingError{"OnHandoverExecution", "S1Handover needs it to build Modify Bearer Request"}
+	}
+
+	if _, err := h.CreateForwardingTunnel(ies...); err != nil {
+		return h.abort(err)
+	}
+
+	mbIEs, err := fn(ctx, h.sess)
+	if err != nil {
+		return h.abort(err)
+	}
+	if len(mbIEs) == 0 {
+		return h.abort(&RequiredParameterMissingError{"IEs", "OnHandoverExecution must return the IEs for Modify Bearer Request"})
+	}
+
+	select {
+	case <-ctx.Done():
+		return h.abort(ctx.Err())
+	default:
+	}
+
+	if _, err := h.ModifyBearer(mbIEs...); err != nil {
+		return h.abort(err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(h.ForwardingDuration):
+	}
+
+	_, err = h.DeleteForwardingTunnel()
+	return err
+}
+
+// CreateForwardingTunnel sends Create Indirect Data Forwarding Tunnel Request with the
+// IEs given and waits for the response.
+//
+// On success, the F-TEIDs in the Bearer Contexts in the response are kept in S1Handover
+// and can be retrieved with ForwardingFTEIDs. They are not stored in the Session, not
+// to overwrite the F-TEIDs of the bearers with the same interface types.
+func (h *S1Handover) CreateForwardingTunnel(ies ...*ie.IE) (*message.CreateIndirectDataForwardingTunnelResponse, error) {
+	seq, err := h.conn.CreateIndirectDataForwardingTunnel(h.teid, h.sess, ies...)
+	if err != nil {
+		return nil, err
+	}
+
+	incomingMsg, err := h.sess.WaitMessage(seq, h.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	res, ok := incomingMsg.(*message.CreateIndirectDataForwardingTunnelResponse)
+	if !ok {
+		return nil, &UnexpectedTypeError{Msg: incomingMsg}
+	}
+	if err := checkCause(res, res.Cause); err != nil {
+		return res, err
+	}
+	h.fwdCreated = true
+
+	fteids, err := forwardingFTEIDs(res.BearerContexts)
+	if err != nil {
+		return res, err
+	}
+	h.fwdFTEIDs = fteids
+
+	if fn := h.OnForwardingTunnelCreated; fn != nil {
+		if err := fn(h.sess, res); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// ModifyBearer sends Modify Bearer Request with the IEs given and waits for the
+// response. See ModifyBearerWithWait for how the Session is updated.
+func (h *S1Handover) ModifyBearer(ies ...*ie.IE) (*message.ModifyBearerResponse, error) {
+	res, err := h.conn.ModifyBearerWithWait(h.teid, h.sess, h.Timeout, ies...)
+	if err != nil {
+		return res, err
+	}
+
+	if fn := h.OnBearerModified; fn != nil {
+		if err := fn(h.sess, res); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// DeleteForwardingTunnel sends Delete Indirect Data Forwarding Tunnel Request and waits
+// for the response.
+func (h *S1Handover) DeleteForwardingTunnel(ies ...*ie.IE) (*message.DeleteIndirectDataForwardingTunnelResponse, error) {
+	seq, err := h.conn.DeleteIndirectDataForwardingTunnel(h.teid, h.sess, ies...)
+	if err != nil {
+		return nil, err
+	}
+
+	incomingMsg, err := h.sess.WaitMessage(seq, h.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	res, ok := incomingMsg.(*message.DeleteIndirectDataForwardingTunnelResponse)
+	if !ok {
+		return nil, &UnexpectedTypeError{Msg: incomingMsg}
+	}
+	h.fwdCreated = false
+	h.fwdFTEIDs = nil
+
+	if err := checkCause(res, res.Cause); err != nil {
+		return res, err
+	}
+
+	if fn := h.OnForwardingTunnelDeleted; fn != nil {
+		if err := fn(h.sess, res); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// ForwardingFTEIDs returns the F-TEIDs for the forwarding tunnel of the bearer with
+// ebi, which are received in Create Indirect Data Forwarding Tunnel Response.
+// It returns nil after the forwarding tunnel is deleted.
+func (h *S1Handover) ForwardingFTEIDs(ebi uint8) []*ie.IE {
+	return h.fwdFTEIDs[ebi]
+}
+
+// forwardingFTEIDs returns the F-TEIDs in the Bearer Contexts given by EBI.
+func forwardingFTEIDs(brCtxIEs []*ie.IE) (map[uint8][]*ie.IE, error) {
+	fteids := map[uint8][]*ie.IE{}
+	for _, brCtxIE := range brCtxIEs {
+		if brCtxIE == nil {
+			continue
+		}
+		ebiIE, err := brCtxIE.FindByType(ie.EPSBearerID, 0)
+		if err != nil {
+			return nil, &RequiredIEMissingError{Type: ie.EPSBearerID}
+		}
+		ebi, err := ebiIE.EPSBearerID()
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range brCtxIE.ChildIEs {
+			if child.Type == ie.FullyQualifiedTEID {
+				fteids[ebi] = append(fteids[ebi], child)
+			}
+		}
+	}
+	return fteids, nil
+}
+
+// abort deletes the forwarding tunnel if created, and returns the error given as it is.
+func (h *S1Handover) abort(err error) error {
+	if !h.fwdCreated {
+		return err
+	}
+
+	if _, e := h.DeleteForwardingTunnel(); e != nil {
+		logf("failed to delete forwarding tunnel for %s: %s", h.sess.IMSI, e)
+	}
+	return err
+}
@@ -1,7 +1,9 @@
 package gtpv2_test
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"math"
 	"net"
 	"strconv"
@@ -30,6 +32,34 @@ func init() {
 	}
 }
 
+// serveConn creates a Conn over the socket bound to laddr and starts serving it
+// in background. The socket is bound before returning, so that the Conn is ready
+// to send and receive without waiting for the serving goroutine.
+func serveConn(ctx context.Context, t *testing.T, laddr net.Addr, localIfType uint8) *v2.Conn {
+	t.Helper()
+
+	pc, err := net.ListenPacket(laddr.Network(), laddr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return servePacketConn(ctx, t, pc, localIfType)
+}
+
+// servePacketConn creates a Conn over pc and starts serving it in background.
+func servePacketConn(ctx context.Context, t *testing.T, pc net.PacketConn, localIfType uint8) *v2.Conn {
+	t.Helper()
+
+	conn := v2.NewConnWithPacketConn(pc, localIfType, 0)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		if err := conn.ListenAndServe(ctx); err != nil {
+			log.Println(err)
+		}
+	}()
+	return conn
+}
+
 func TestSessionCount(t *testing.T) {
 	if want, got := testConn.SessionCount(), len(sessions); want != got {
 		t.Errorf("SessionCount is invalid. want: %d, got: %d", want, got)
@@ -0,0 +1,159 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// SessionHookFunc is called on the lifecycle events of Session.
+//
+// msg is the message that triggered the event. It is the message being handled by
+// the HandlerFunc in which the event happens, the response that completed the
+// procedure in the methods like ModifyBearerWithWait, or nil if unknown(e.g., the
+// event happens outside of HandlerFunc).
+type SessionHookFunc func(c *Conn, sess *Session, msg message.Message)
+
+// BearerHookFunc is called on the lifecycle events of Bearer.
+// See SessionHookFunc for what msg is.
+type BearerHookFunc func(c *Conn, sess *Session, br *Bearer, msg message.Message)
+
+// LifecycleHooks is a set of functions called on the lifecycle events of Sessions
+// and Bearers on a Conn. Any of them can be nil.
+//
+// The events are fired only for the Sessions registered to the Conn with
+// RegisterSession(or CreateSession, which calls it internally).
+type LifecycleHooks struct {
+	// OnSessionCreated is called when a Session is registered to Conn for the first time.
+	OnSessionCreated SessionHookFunc
+
+	// OnSessionActivated is called when an inactive Session is activated, and not
+	// when Activate is called on the active one again. If a Session is activated
+	// before registered, it is called just after OnSessionCreated.
+	OnSessionActivated SessionHookFunc
+
+	// OnSessionDeleted is called when a Session is removed from Conn.
+	OnSessionDeleted SessionHookFunc
+
+	// OnSessionExpired is called when a Session is found idle by EnableSessionExpiry,
+	// just before it is removed from Conn.
+	OnSessionExpired SessionHookFunc
+
+	// OnBearerAdded is called when a Bearer is added to a Session with AddBearer.
+	OnBearerAdded BearerHookFunc
+
+	// OnBearerModified is called when a Bearer is modified with ModifyBearerWithWait.
+	OnBearerModified BearerHookFunc
+
+	// OnBearerRemoved is called when a Bearer is removed from a Session with
+	// RemoveBearer or RemoveBearerByEBI.
+	OnBearerRemoved BearerHookFunc
+}
+
+// SetLifecycleHooks sets the hooks called on the lifecycle events of Sessions and
+// Bearers on the Conn. Giving nil removes all the hooks.
+//
+// The hooks are called synchronously in the goroutine where the event happens, so
+// they should not block for long.
+func (c *Conn) SetLifecycleHooks(hooks *LifecycleHooks) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.hooks = hooks
+}
+
+func (c *Conn) lifecycleHooks() *LifecycleHooks {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.hooks == nil {
+		return &LifecycleHooks{}
+	}
+	return c.hooks
+}
+
+// fireSessionEvent calls the hook for the Session event selected by fn, if the
+// Session is registered to a Conn.
+func (s *Session) fireSessionEvent(fn func(*LifecycleHooks) SessionHookFunc) {
+	c, msg := s.registeredConn()
+	if c == nil {
+		return
+	}
+	if hook := fn(c.lifecycleHooks()); hook != nil {
+		hook(c, s, msg)
+	}
+}
+
+// fireBearerEvent calls the hook for the Bearer event selected by fn, if the
+// Session is registered to a Conn.
+func (s *Session) fireBearerEvent(fn func(*LifecycleHooks) BearerHookFunc, br *Bearer) {
+	c, msg := s.registeredConn()
+	if c == nil || br == nil {
+		return
+	}
+	if hook := fn(c.lifecycleHooks()); hook != nil {
+		hook(c, s, br, msg)
+	}
+}
+
+func (s *Session) registeredConn() (*Conn, message.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.conn, s.trigger
+}
+
+// setTrigger sets the message that triggers the events on Session, and returns
+// a function to clear it.
+func (s *Session) setTrigger(msg message.Message) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.trigger = msg
+	return func() { s.clearTrigger(msg) }
+}
+
+// clearTrigger clears the message that triggers the events on Session, if it is
+// not overwritten by another one.
+func (s *Session) clearTrigger(msg message.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.trigger == msg {
+		s.trigger = nil
+	}
+}
+
+// setTrigger associates the message being handled with the Session it is for, so
+// that the hooks called in the HandlerFunc can get it. It returns a function to
+// clear it, which should be called after the HandlerFunc returns.
+//
+// The Session is looked up by TEID. For Create Session Request, which has no TEID,
+// the message is kept by IMSI until the Session is registered with RegisterSession.
+func (c *Conn) setTrigger(msg message.Message) func() {
+	if teid := msg.TEID(); teid != 0 {
+		if sess, ok := c.iteiSessionMap.load(teid); ok && sess != nil {
+			return sess.setTrigger(msg)
+		}
+		return func() {}
+	}
+
+	csReq, ok := msg.(*message.CreateSessionRequest)
+	if !ok || csReq.IMSI == nil {
+		return func() {}
+	}
+	imsi, err := csReq.IMSI.IMSI()
+	if err != nil {
+		return func() {}
+	}
+
+	c.pendingTriggers.Store(imsi, msg)
+	return func() {
+		c.pendingTriggers.Delete(imsi)
+		if sess, ok := c.imsiSessionMap.load(imsi); ok {
+			sess.clearTrigger(msg)
+		}
+	}
+}
@@ -5,6 +5,7 @@
 package ie
 
 import (
+	"fmt"
 	"strings"
 )
 
@@ -22,6 +23,23 @@ func NewAccessPointName(apn string) *IE {
 	return i
 }
 
+// NewAccessPointNameWithOI creates a new AccessPointName IE that consists of
+// the Network Identifier given as ni and the Operator Identifier built from
+// MCC and MNC, e.g., "internet.mnc045.mcc123.gprs".
+func NewAccessPointNameWithOI(ni, mcc, mnc string) *IE {
+	return NewAccessPointName(ni + "." + APNOperatorIdentifier(mcc, mnc))
+}
+
+// APNOperatorIdentifier returns the APN Operator Identifier defined in
+// TS 23.003 9.1.2, e.g., "mnc045.mcc123.gprs". mnc is zero-padded to three
+// digits.
+func APNOperatorIdentifier(mcc, mnc string) string {
+	if len(mnc) == 2 {
+		mnc = "0" + mnc
+	}
+	return fmt.Sprintf("mnc%s.mcc%s.gprs", mnc, mcc)
+}
+
 // AccessPointName returns AccessPointName in string if the type of IE matches.
 func (i *IE) AccessPointName() (string, error) {
 	if i.Type != AccessPointName {
@@ -54,3 +72,70 @@ func (i *IE) MustAccessPointName() string {
 	v, _ := i.AccessPointName()
 	return v
 }
+
+// APNNetworkIdentifier returns the Network Identifier part of AccessPointName,
+// which is the whole APN if it does not contain the Operator Identifier.
+func (i *IE) APNNetworkIdentifier() (string, error) {
+	apn, err := i.AccessPointName()
+	if err != nil {
+		return "", err
+	}
+
+	ni, _ := splitAPN(apn)
+	return ni, nil
+}
+
+// MustAPNNetworkIdentifier returns APNNetworkIdentifier in string, ignoring errors.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustAPNNetworkIdentifier() string {
+	v, _ := i.APNNetworkIdentifier()
+	return v
+}
+
+// APNOperatorIdentifier returns the MCC and MNC in the Operator Identifier
+// part of AccessPointName.
+//
+// The MNC is returned in three digits as it is in the Operator Identifier,
+// since the one zero-padded from two digits cannot be distinguished. It
+// returns *IENotFoundError if AccessPointName does not contain the Operator
+// Identifier.
+func (i *IE) APNOperatorIdentifier() (mcc, mnc string, err error) {
+	apn, err := i.AccessPointName()
+	if err != nil {
+		return "", "", err
+	}
+
+	_, oi := splitAPN(apn)
+	if oi == "" {
+		return "", "", &IENotFoundError{Type: AccessPointName}
+	}
+	labels := strings.Split(oi, ".")
+	return labels[1][3:], labels[0][3:], nil
+}
+
+// splitAPN splits the APN into the Network Identifier and the Operator
+// Identifier. oi is empty if the APN does not end with the Operator Identifier.
+func splitAPN(apn string) (ni, oi string) {
+	labels := strings.Split(apn, ".")
+	n := len(labels)
+	if n < 3 ||
+		!strings.EqualFold(labels[n-1], "gprs") ||
+		!isOILabel(labels[n-2], "mcc") ||
+		!isOILabel(labels[n-3], "mnc") {
+		return apn, ""
+	}
+	return strings.Join(labels[:n-3], "."), strings.ToLower(strings.Join(labels[n-3:], "."))
+}
+
+// isOILabel reports whether the label is the prefix followed by three digits.
+func isOILabel(label, prefix string) bool {
+	if len(label) != 6 || !strings.EqualFold(label[:3], prefix) {
+		return false
+	}
+	for _, c := range label[3:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -13,8 +13,12 @@ func NewAllocationRetensionPriority(pci, pl, pvi uint8) *IE {
 	return i
 }
 
+// AllocationRetensionPriority returns the octet of ARP in uint8 if the type of IE
+// matches. Bearer QoS IE is also accepted, as it has ARP in its first octet.
 func (i *IE) AllocationRetensionPriority() (uint8, error) {
-	if i.Type != AllocationRetensionPriority {
+	switch i.Type {
+	case AllocationRetensionPriority, BearerQoS:
+	default:
 		return 0, &InvalidTypeError{Type: i.Type}
 	}
 	if len(i.Payload) < 1 {
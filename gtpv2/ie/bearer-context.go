@@ -8,7 +8,7 @@ import "io"
 
 // NewBearerContext creates a new BearerContext IE.
 func NewBearerContext(ies ...*IE) *IE {
-	var omitted []*IE
+	omitted := make([]*IE, 0, len(ies))
 	for _, ie := range ies {
 		if ie != nil {
 			omitted = append(omitted, ie)
@@ -36,7 +36,7 @@ func (i *IE) BearerFlags() (uint8, error) {
 				return child.BearerFlags()
 			}
 		}
-		return 0, ErrIENotFound
+		return 0, &IENotFoundError{Type: BearerFlags}
 	default:
 		return 0, &InvalidTypeError{Type: i.Type}
 	}
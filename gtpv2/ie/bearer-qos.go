@@ -38,7 +38,7 @@ func (i *IE) BearerQoS() (*BearerQoSFields, error) {
 				return child.BearerQoS()
 			}
 		}
-		return nil, ErrIENotFound
+		return nil, &IENotFoundError{Type: BearerQoS}
 	default:
 		return nil, &InvalidTypeError{Type: i.Type}
 	}
@@ -0,0 +1,433 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+)
+
+// TFT operation code definitions.
+const (
+	TFTOpSpare uint8 = iota
+	TFTOpCreateNewTFT
+	TFTOpDeleteExistingTFT
+	TFTOpAddPacketFiltersToExistingTFT
+	TFTOpReplacePacketFiltersInExistingTFT
+	TFTOpDeletePacketFiltersFromExistingTFT
+	TFTOpNoTFTOperation
+)
+
+// TFT packet filter direction definitions.
+const (
+	TFTPFPreRel7TFTFilter uint8 = iota
+	TFTPFDownlinkOnly
+	TFTPFUplinkOnly
+	TFTPFBidirectional
+)
+
+// TFT packet filter component type identifiers defined in TS 24.008 10.5.6.12.
+const (
+	PFCompIPv4RemoteAddress             uint8 = 0x10
+	PFCompIPv4LocalAddress              uint8 = 0x11
+	PFCompIPv6RemoteAddress             uint8 = 0x20
+	PFCompIPv6RemoteAddressPrefixLength uint8 = 0x21
+	PFCompIPv6LocalAddressPrefixLength  uint8 = 0x23
+	PFCompProtocolIdentifierNextHeader  uint8 = 0x30
+	PFCompSingleLocalPort               uint8 = 0x40
+	PFCompLocalPortRange                uint8 = 0x41
+	PFCompSingleRemotePort              uint8 = 0x50
+	PFCompRemotePortRange               uint8 = 0x51
+	PFCompSecurityParameterIndex        uint8 = 0x60
+	PFCompTypeOfServiceTrafficClass     uint8 = 0x70
+	PFCompFlowLabel                     uint8 = 0x80
+)
+
+// NewBearerTFT creates a new BearerTFT IE.
+//
+// For TFTOpDeletePacketFiltersFromExistingTFT, only the Identifier of each filter
+// is used. params can be nil if no parameters are needed.
+func NewBearerTFT(op uint8, filters []*TFTPacketFilter, params []*TFTParameter) *IE {
+	return newTFTIE(BearerTFT, op, filters, params)
+}
+
+// NewTrafficAggregateDescription creates a new TrafficAggregateDescription IE,
+// which is encoded in the same way as BearerTFT.
+func NewTrafficAggregateDescription(op uint8, filters []*TFTPacketFilter, params []*TFTParameter) *IE {
+	return newTFTIE(TrafficAggregateDescription, op, filters, params)
+}
+
+func newTFTIE(itype, op uint8, filters []*TFTPacketFilter, params []*TFTParameter) *IE {
+	f := &TrafficFlowTemplateFields{
+		OperationCode: op,
+		PacketFilters: filters,
+		Parameters:    params,
+	}
+	b, err := f.Marshal()
+	if err != nil {
+		return nil
+	}
+	return New(itype, 0x00, b)
+}
+
+// TrafficFlowTemplate returns the TFT in BearerTFT or TrafficAggregateDescription
+// in TrafficFlowTemplateFields type if the type of IE matches.
+func (i *IE) TrafficFlowTemplate() (*TrafficFlowTemplateFields, error) {
+	switch i.Type {
+	case BearerTFT, TrafficAggregateDescription:
+		return ParseTrafficFlowTemplateFields(i.Payload)
+	default:
+		return nil, &InvalidTypeError{Type: i.Type}
+	}
+}
+
+// MustTrafficFlowTemplate returns TrafficFlowTemplate in TrafficFlowTemplateFields
+// type, ignoring errors.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustTrafficFlowTemplate() *TrafficFlowTemplateFields {
+	v, _ := i.TrafficFlowTemplate()
+	return v
+}
+
+// TFTPacketFilter is a packet filter in BearerTFT IE.
+//
+// Contents are the packet filter components encoded as defined in 3GPP TS 24.008.
+// Use Components to decode them.
+type TFTPacketFilter struct {
+	Identifier uint8
+	Direction  uint8
+	Precedence uint8
+	Contents   []byte
+}
+
+// NewTFTPacketFilter creates a new TFTPacketFilter with the components given.
+func NewTFTPacketFilter(id, dir, prec uint8, c *PacketFilterComponents) *TFTPacketFilter {
+	return &TFTPacketFilter{
+		Identifier: id,
+		Direction:  dir,
+		Precedence: prec,
+		Contents:   c.marshal(),
+	}
+}
+
+// Components decodes the Contents of TFTPacketFilter.
+func (pf *TFTPacketFilter) Components() (*PacketFilterComponents, error) {
+	c := &PacketFilterComponents{}
+	if err := c.unmarshal(pf.Contents); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// TFTParameter is a parameter in BearerTFT IE.
+type TFTParameter struct {
+	Identifier uint8
+	Contents   []byte
+}
+
+// TrafficFlowTemplateFields is a set of fields in BearerTFT IE.
+type TrafficFlowTemplateFields struct {
+	OperationCode uint8
+	PacketFilters []*TFTPacketFilter
+	Parameters    []*TFTParameter
+}
+
+// Marshal serializes TrafficFlowTemplateFields.
+func (f *TrafficFlowTemplateFields) Marshal() ([]byte, error) {
+	b := make([]byte, f.MarshalLen())
+	if err := f.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalTo serializes TrafficFlowTemplateFields.
+func (f *TrafficFlowTemplateFields) MarshalTo(b []byte) error {
+	if len(b) < f.MarshalLen() {
+		return io.ErrUnexpectedEOF
+	}
+
+	b[0] = f.OperationCode<<5 | uint8(len(f.PacketFilters))&0x0f
+	if len(f.Parameters) > 0 {
+		b[0] |= 0x10
+	}
+	offset := 1
+
+	for _, pf := range f.PacketFilters {
+		if f.hasIdentifiersOnly() {
+			b[offset] = pf.Identifier & 0x0f
+			offset++
+			continue
+		}
+		b[offset] = (pf.Direction&0x03)<<4 | pf.Identifier&0x0f
+		b[offset+1] = pf.Precedence
+		b[offset+2] = uint8(len(pf.Contents))
+		copy(b[offset+3:], pf.Contents)
+		offset += 3 + len(pf.Contents)
+	}
+
+	for _, p := range f.Parameters {
+		b[offset] = p.Identifier
+		b[offset+1] = uint8(len(p.Contents))
+		copy(b[offset+2:], p.Contents)
+		offset += 2 + len(p.Contents)
+	}
+	return nil
+}
+
+// ParseTrafficFlowTemplateFields decodes TrafficFlowTemplateFields.
+func ParseTrafficFlowTemplateFields(b []byte) (*TrafficFlowTemplateFields, error) {
+	f := &TrafficFlowTemplateFields{}
+	if err := f.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// UnmarshalBinary decodes given bytes into TrafficFlowTemplateFields.
+func (f *TrafficFlowTemplateFields) UnmarshalBinary(b []byte) error {
+	l := len(b)
+	if l < 1 {
+		return io.ErrUnexpectedEOF
+	}
+
+	f.OperationCode = b[0] >> 5
+	hasParams := b[0]&0x10 != 0
+	n := int(b[0] & 0x0f)
+	offset := 1
+
+	f.PacketFilters = nil
+	for i := 0; i < n; i++ {
+		if f.hasIdentifiersOnly() {
+			if l < offset+1 {
+				return io.ErrUnexpectedEOF
+			}
+			f.PacketFilters = append(f.PacketFilters, &TFTPacketFilter{Identifier: b[offset] & 0x0f})
+			offset++
+			continue
+		}
+
+		if l < offset+3 {
+			return io.ErrUnexpectedEOF
+		}
+		pf := &TFTPacketFilter{
+			Identifier: b[offset] & 0x0f,
+			Direction:  (b[offset] >> 4) & 0x03,
+			Precedence: b[offset+1],
+		}
+		cl := int(b[offset+2])
+		offset += 3
+		if l < offset+cl {
+			return io.ErrUnexpectedEOF
+		}
+		pf.Contents = b[offset : offset+cl]
+		offset += cl
+		f.PacketFilters = append(f.PacketFilters, pf)
+	}
+
+	f.Parameters = nil
+	if !hasParams {
+		return nil
+	}
+	for offset < l {
+		if l < offset+2 {
+			return io.ErrUnexpectedEOF
+		}
+		p := &TFTParameter{Identifier: b[offset]}
+		cl := int(b[offset+1])
+		offset += 2
+		if l < offset+cl {
+			return io.ErrUnexpectedEOF
+		}
+		p.Contents = b[offset : offset+cl]
+		offset += cl
+		f.Parameters = append(f.Parameters, p)
+	}
+	return nil
+}
+
+// MarshalLen returns the serial length of TrafficFlowTemplateFields.
+func (f *TrafficFlowTemplateFields) MarshalLen() int {
+	l := 1
+	for _, pf := range f.PacketFilters {
+		if f.hasIdentifiersOnly() {
+			l++
+			continue
+		}
+		l += 3 + len(pf.Contents)
+	}
+	for _, p := range f.Parameters {
+		l += 2 + len(p.Contents)
+	}
+	return l
+}
+
+// hasIdentifiersOnly reports whether the packet filters have only the identifiers.
+func (f *TrafficFlowTemplateFields) hasIdentifiersOnly() bool {
+	return f.OperationCode == TFTOpDeletePacketFiltersFromExistingTFT
+}
+
+// PacketFilterComponents is a set of the packet filter components in
+// TFTPacketFilter. The "remote" is the peer of the UE and the "local" is the
+// UE, regardless of the direction of the filter.
+//
+// The components that are not present are left zero, and the Has* fields tell
+// the presence of the ones that can be zero. The ports are matched as the
+// range from Low to High, which are the same for the single port.
+type PacketFilterComponents struct {
+	RemoteAddress *net.IPNet
+	LocalAddress  *net.IPNet
+
+	HasProtocol bool
+	Protocol    uint8
+
+	LocalPortLow   uint16
+	LocalPortHigh  uint16
+	RemotePortLow  uint16
+	RemotePortHigh uint16
+
+	HasSecurityParameterIndex bool
+	SecurityParameterIndex    uint32
+
+	HasTypeOfService  bool
+	TypeOfService     uint8
+	TypeOfServiceMask uint8
+
+	HasFlowLabel bool
+	FlowLabel    uint32 // 20 bits
+}
+
+func (c *PacketFilterComponents) marshal() []byte {
+	var b []byte
+	if a := c.RemoteAddress; a != nil {
+		b = appendAddressComponent(b, a, PFCompIPv4RemoteAddress, PFCompIPv6RemoteAddressPrefixLength)
+	}
+	if a := c.LocalAddress; a != nil {
+		b = appendAddressComponent(b, a, PFCompIPv4LocalAddress, PFCompIPv6LocalAddressPrefixLength)
+	}
+	if c.HasProtocol {
+		b = append(b, PFCompProtocolIdentifierNextHeader, c.Protocol)
+	}
+	b = appendPortComponent(b, c.LocalPortLow, c.LocalPortHigh, PFCompSingleLocalPort, PFCompLocalPortRange)
+	b = appendPortComponent(b, c.RemotePortLow, c.RemotePortHigh, PFCompSingleRemotePort, PFCompRemotePortRange)
+	if c.HasSecurityParameterIndex {
+		b = append(b, PFCompSecurityParameterIndex, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(b[len(b)-4:], c.SecurityParameterIndex)
+	}
+	if c.HasTypeOfService {
+		b = append(b, PFCompTypeOfServiceTrafficClass, c.TypeOfService, c.TypeOfServiceMask)
+	}
+	if c.HasFlowLabel {
+		b = append(b, PFCompFlowLabel, uint8(c.FlowLabel>>16)&0x0f, uint8(c.FlowLabel>>8), uint8(c.FlowLabel))
+	}
+	return b
+}
+
+func appendAddressComponent(b []byte, a *net.IPNet, v4Type, v6Type uint8) []byte {
+	if v4 := a.IP.To4(); v4 != nil {
+		mask := a.Mask
+		if len(mask) == net.IPv6len {
+			mask = mask[12:]
+		}
+		b = append(b, v4Type)
+		b = append(b, v4...)
+		return append(b, mask...)
+	}
+
+	ones, _ := a.Mask.Size()
+	b = append(b, v6Type)
+	b = append(b, a.IP.To16()...)
+	return append(b, uint8(ones))
+}
+
+func appendPortComponent(b []byte, low, high uint16, singleType, rangeType uint8) []byte {
+	switch {
+	case low == 0 && high == 0:
+		return b
+	case high == 0 || low == high:
+		return append(b, singleType, uint8(low>>8), uint8(low))
+	default:
+		return append(b, rangeType, uint8(low>>8), uint8(low), uint8(high>>8), uint8(high))
+	}
+}
+
+func (c *PacketFilterComponents) unmarshal(b []byte) error {
+	for len(b) > 0 {
+		t, v := b[0], b[1:]
+		n := pfComponentLen(t)
+		if n < 0 {
+			return ErrMalformed
+		}
+		if len(v) < n {
+			return io.ErrUnexpectedEOF
+		}
+
+		switch t {
+		case PFCompIPv4RemoteAddress:
+			c.RemoteAddress = &net.IPNet{IP: net.IP(v[0:4]), Mask: net.IPMask(v[4:8])}
+		case PFCompIPv4LocalAddress:
+			c.LocalAddress = &net.IPNet{IP: net.IP(v[0:4]), Mask: net.IPMask(v[4:8])}
+		case PFCompIPv6RemoteAddress:
+			c.RemoteAddress = &net.IPNet{IP: net.IP(v[0:16]), Mask: net.IPMask(v[16:32])}
+		case PFCompIPv6RemoteAddressPrefixLength:
+			c.RemoteAddress = &net.IPNet{IP: net.IP(v[0:16]), Mask: net.CIDRMask(int(v[16]), 128)}
+		case PFCompIPv6LocalAddressPrefixLength:
+			c.LocalAddress = &net.IPNet{IP: net.IP(v[0:16]), Mask: net.CIDRMask(int(v[16]), 128)}
+		case PFCompProtocolIdentifierNextHeader:
+			c.HasProtocol = true
+			c.Protocol = v[0]
+		case PFCompSingleLocalPort:
+			c.LocalPortLow = binary.BigEndian.Uint16(v[0:2])
+			c.LocalPortHigh = c.LocalPortLow
+		case PFCompLocalPortRange:
+			c.LocalPortLow = binary.BigEndian.Uint16(v[0:2])
+			c.LocalPortHigh = binary.BigEndian.Uint16(v[2:4])
+		case PFCompSingleRemotePort:
+			c.RemotePortLow = binary.BigEndian.Uint16(v[0:2])
+			c.RemotePortHigh = c.RemotePortLow
+		case PFCompRemotePortRange:
+			c.RemotePortLow = binary.BigEndian.Uint16(v[0:2])
+			c.RemotePortHigh = binary.BigEndian.Uint16(v[2:4])
+		case PFCompSecurityParameterIndex:
+			c.HasSecurityParameterIndex = true
+			c.SecurityParameterIndex = binary.BigEndian.Uint32(v[0:4])
+		case PFCompTypeOfServiceTrafficClass:
+			c.HasTypeOfService = true
+			c.TypeOfService = v[0]
+			c.TypeOfServiceMask = v[1]
+		case PFCompFlowLabel:
+			c.HasFlowLabel = true
+			c.FlowLabel = uint32(v[0]&0x0f)<<16 | uint32(v[1])<<8 | uint32(v[2])
+		}
+		b = v[n:]
+	}
+	return nil
+}
+
+// pfComponentLen returns the length of the value of the packet filter
+// component, or -1 if the type is unknown.
+func pfComponentLen(t uint8) int {
+	switch t {
+	case PFCompIPv4RemoteAddress, PFCompIPv4LocalAddress:
+		return 8
+	case PFCompIPv6RemoteAddress:
+		return 32
+	case PFCompIPv6RemoteAddressPrefixLength, PFCompIPv6LocalAddressPrefixLength:
+		return 17
+	case PFCompProtocolIdentifierNextHeader:
+		return 1
+	case PFCompSingleLocalPort, PFCompSingleRemotePort:
+		return 2
+	case PFCompLocalPortRange, PFCompRemotePortRange, PFCompSecurityParameterIndex:
+		return 4
+	case PFCompTypeOfServiceTrafficClass:
+		return 2
+	case PFCompFlowLabel:
+		return 3
+	default:
+		return -1
+	}
+}
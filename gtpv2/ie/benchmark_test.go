@@ -0,0 +1,87 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie_test
+
+import (
+	"testing"
+
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+func newBenchBearerContext() *ie.IE {
+	return ie.NewBearerContext(
+		ie.NewEPSBearerID(0x05),
+		ie.NewFullyQualifiedTEID(v2.IFTypeS1USGWGTPU, 0x11111111, "1.1.1.3", ""),
+		ie.NewBearerQoS(1, 2, 1, 0xff, 0x1111111111, 0x2222222222, 0x1111111111, 0x2222222222),
+	)
+}
+
+func BenchmarkIE(b *testing.B) {
+	benchIEs := []struct {
+		description string
+		ie          *ie.IE
+	}{
+		{"IMSI", ie.NewIMSI("123451234567890")},
+		{"F-TEID", ie.NewFullyQualifiedTEID(v2.IFTypeS11MMEGTPC, 0xffffffff, "1.1.1.1", "")},
+		{"BearerContext", newBenchBearerContext()},
+	}
+
+	for _, c := range benchIEs {
+		serialized, err := c.ie.Marshal()
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(c.description+"/Marshal", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := c.ie.Marshal(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(c.description+"/Parse", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := ie.Parse(serialized); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+
+	b.Run("BearerContext/New", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = newBenchBearerContext()
+		}
+	})
+}
+
+func BenchmarkParseMultiIEs(b *testing.B) {
+	var serialized []byte
+	for _, i := range []*ie.IE{
+		ie.NewIMSI("123451234567890"),
+		ie.NewMSISDN("123450123456789"),
+		ie.NewFullyQualifiedTEID(v2.IFTypeS11MMEGTPC, 0xffffffff, "1.1.1.1", ""),
+		ie.NewAccessPointName("some.apn.example"),
+		ie.NewRATType(v2.RATTypeEUTRAN),
+		newBenchBearerContext(),
+	} {
+		s, err := i.Marshal()
+		if err != nil {
+			b.Fatal(err)
+		}
+		serialized = append(serialized, s...)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ie.ParseMultiIEs(serialized); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -44,7 +44,7 @@ func (i *IE) Cause() (uint8, error) {
 				return child.Cause()
 			}
 		}
-		return 0, ErrIENotFound
+		return 0, &IENotFoundError{Type: Cause}
 	default:
 		return 0, &InvalidTypeError{Type: i.Type}
 	}
@@ -61,7 +61,7 @@ func (i *IE) MustCause() uint8 {
 func (i *IE) CauseFlags() (uint8, error) {
 	switch i.Type {
 	case Cause:
-		if len(i.Payload) < 1 {
+		if len(i.Payload) < 2 {
 			return 0, io.ErrUnexpectedEOF
 		}
 
@@ -74,10 +74,10 @@ func (i *IE) CauseFlags() (uint8, error) {
 
 		for _, child := range ies {
 			if child.Type == Cause {
-				return child.Cause()
+				return child.CauseFlags()
 			}
 		}
-		return 0, ErrIENotFound
+		return 0, &IENotFoundError{Type: Cause}
 	default:
 		return 0, &InvalidTypeError{Type: i.Type}
 	}
@@ -35,7 +35,7 @@ func (i *IE) ChargingID() (uint32, error) {
 				return child.ChargingID()
 			}
 		}
-		return 0, ErrIENotFound
+		return 0, &IENotFoundError{Type: ChargingID}
 	default:
 		return 0, &InvalidTypeError{Type: i.Type}
 	}
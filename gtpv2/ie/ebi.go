@@ -34,7 +34,7 @@ func (i *IE) EPSBearerID() (uint8, error) {
 				return child.EPSBearerID()
 			}
 		}
-		return 0, ErrIENotFound
+		return 0, &IENotFoundError{Type: EPSBearerID}
 	default:
 		return 0, &InvalidTypeError{Type: i.Type}
 	}
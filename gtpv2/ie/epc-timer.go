@@ -0,0 +1,64 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+import (
+	"math"
+	"time"
+)
+
+// EPCTimerInfinite is the duration represented by the EPC Timer with the unit
+// "infinite".
+const EPCTimerInfinite time.Duration = math.MaxInt64
+
+// units of EPC Timer, in the order of the unit value in the higher 3 bits.
+var epcTimerUnits = []time.Duration{
+	2 * time.Second, time.Minute, 10 * time.Minute, time.Hour, 10 * time.Hour,
+}
+
+// NewEPCTimerFromDuration creates a new EPCTimer IE from time.Duration.
+//
+// The smallest unit that can represent the duration is chosen, and the value is
+// rounded up to the multiple of the unit. If the duration is too long to be
+// represented(longer than 310 hours), the timer is set to infinite.
+func NewEPCTimerFromDuration(d time.Duration) *IE {
+	if d <= 0 {
+		return NewEPCTimer(0)
+	}
+	for u, unit := range epcTimerUnits {
+		v := (d + unit - 1) / unit
+		if v <= 0x1f {
+			return NewEPCTimer(uint8(u<<5) | uint8(v))
+		}
+	}
+	return NewEPCTimer(0xe0)
+}
+
+// EPCTimerDuration returns EPCTimer in time.Duration if the type of IE matches.
+//
+// It returns EPCTimerInfinite if the unit is "infinite". The unit values that are not
+// defined are interpreted as 1 minute as specified in TS 29.274.
+func (i *IE) EPCTimerDuration() (time.Duration, error) {
+	v, err := i.EPCTimer()
+	if err != nil {
+		return 0, err
+	}
+
+	unit := v >> 5
+	switch {
+	case unit == 7:
+		return EPCTimerInfinite, nil
+	case int(unit) >= len(epcTimerUnits):
+		unit = 1
+	}
+	return time.Duration(v&0x1f) * epcTimerUnits[unit], nil
+}
+
+// MustEPCTimerDuration returns EPCTimer in time.Duration, ignoring errors.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustEPCTimerDuration() time.Duration {
+	v, _ := i.EPCTimerDuration()
+	return v
+}
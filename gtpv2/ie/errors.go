@@ -18,7 +18,8 @@ var (
 	ErrIENotFound      = errors.New("could not find the specified IE in a grouped IE")
 	ErrIEValueNotFound = errors.New("could not find the specified value in an IE")
 
-	ErrMalformed = errors.New("malformed IE")
+	ErrMalformed       = errors.New("malformed IE")
+	ErrTooDeeplyNested = errors.New("grouped IEs are nested too deeply")
 )
 
 // InvalidTypeError indicates the type of IE is invalid.
@@ -30,3 +31,45 @@ type InvalidTypeError struct {
 func (e *InvalidTypeError) Error() string {
 	return fmt.Sprintf("got invalid type: %v", e.Type)
 }
+
+// Is reports whether target is ErrInvalidType, so that errors.Is can be used
+// to check the errors regardless of the type.
+func (e *InvalidTypeError) Is(target error) bool {
+	return target == ErrInvalidType
+}
+
+// IENotFoundError indicates that the IE looked up by type and instance is not
+// found. It matches ErrIENotFound with errors.Is.
+type IENotFoundError struct {
+	Type     uint8
+	Instance uint8
+}
+
+// Error returns message with the type and instance of IE looked up.
+func (e *IENotFoundError) Error() string {
+	return fmt.Sprintf("could not find IE: %s (instance %d)", TypeName(e.Type), e.Instance)
+}
+
+// Is reports whether target is ErrIENotFound.
+func (e *IENotFoundError) Is(target error) bool {
+	return target == ErrIENotFound
+}
+
+// InvalidLengthError indicates that the length of IE is inconsistent with
+// the bytes available, e.g., the Length field exceeds the rest of a message.
+// It matches ErrInvalidLength with errors.Is.
+type InvalidLengthError struct {
+	Type uint8
+	// Got is the number of bytes available, and Want is the one required.
+	Got, Want int
+}
+
+// Error returns message with the type of IE and the lengths.
+func (e *InvalidLengthError) Error() string {
+	return fmt.Sprintf("invalid length of IE %s: got %d bytes, want %d", TypeName(e.Type), e.Got, e.Want)
+}
+
+// Is reports whether target is ErrInvalidLength.
+func (e *InvalidLengthError) Is(target error) bool {
+	return target == ErrInvalidLength
+}
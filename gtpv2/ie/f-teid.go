@@ -12,7 +12,7 @@ import (
 
 // NewFullyQualifiedTEID creates a new FullyQualifiedTEID IE.
 func NewFullyQualifiedTEID(ifType uint8, teid uint32, v4, v6 string) *IE {
-	v := NewFullyQualifiedTEIDFields(ifType, teid, net.ParseIP(v4), net.ParseIP(v6))
+	v := newFullyQualifiedTEIDFields(ifType, teid, parseIP(v4), parseIP(v6))
 	b, err := v.Marshal()
 	if err != nil {
 		return nil
@@ -23,7 +23,7 @@ func NewFullyQualifiedTEID(ifType uint8, teid uint32, v4, v6 string) *IE {
 
 // NewFullyQualifiedTEIDNetIP creates a new FullyQualifiedTEID IE from net.IP instead of string.
 func NewFullyQualifiedTEIDNetIP(ifType uint8, teid uint32, v4, v6 net.IP) *IE {
-	v := NewFullyQualifiedTEIDFields(ifType, teid, v4, v6)
+	v := newFullyQualifiedTEIDFields(ifType, teid, v4, v6)
 	b, err := v.Marshal()
 	if err != nil {
 		return nil
@@ -53,7 +53,14 @@ type FullyQualifiedTEIDFields struct {
 
 // NewFullyQualifiedTEIDFields creates a new FullyQualifiedTEIDFields.
 func NewFullyQualifiedTEIDFields(ifType uint8, teid uint32, v4, v6 net.IP) *FullyQualifiedTEIDFields {
-	f := &FullyQualifiedTEIDFields{
+	f := newFullyQualifiedTEIDFields(ifType, teid, v4, v6)
+	return &f
+}
+
+// newFullyQualifiedTEIDFields returns FullyQualifiedTEIDFields as a value, so
+// that the constructors of IE can keep it on the stack.
+func newFullyQualifiedTEIDFields(ifType uint8, teid uint32, v4, v6 net.IP) FullyQualifiedTEIDFields {
+	f := FullyQualifiedTEIDFields{
 		InterfaceType: ifType,
 		TEIDGREKey:    teid,
 	}
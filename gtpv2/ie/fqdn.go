@@ -10,7 +10,13 @@ import (
 
 // NewFullyQualifiedDomainName creates a new FullyQualifiedDomainName IE.
 func NewFullyQualifiedDomainName(fqdn string) *IE {
-	i := New(FullyQualifiedDomainName, 0x00, make([]byte, len(fqdn)+1))
+	return newFQDN(FullyQualifiedDomainName, fqdn)
+}
+
+// newFQDN creates a new IE of the type given, which has the FQDN encoded as
+// defined in TS 29.303 as its payload.
+func newFQDN(itype uint8, fqdn string) *IE {
+	i := New(itype, 0x00, make([]byte, len(fqdn)+1))
 	var offset = 0
 	for _, label := range strings.Split(fqdn, ".") {
 		l := len(label)
@@ -23,8 +29,12 @@ func NewFullyQualifiedDomainName(fqdn string) *IE {
 }
 
 // FullyQualifiedDomainName returns FullyQualifiedDomainName in string if the type of IE matches.
+//
+// This can also be used to retrieve the value of PGWFQDN IE, which has the same format.
 func (i *IE) FullyQualifiedDomainName() (string, error) {
-	if i.Type != FullyQualifiedDomainName {
+	switch i.Type {
+	case FullyQualifiedDomainName, PGWFQDN:
+	default:
 		return "", &InvalidTypeError{Type: i.Type}
 	}
 
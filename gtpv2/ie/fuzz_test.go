@@ -0,0 +1,71 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package ie_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+func FuzzParse(f *testing.F) {
+	for _, c := range testIEs {
+		f.Add(c.serialized)
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		i, err := ie.Parse(b)
+		if err != nil {
+			return
+		}
+
+		got, err := i.Marshal()
+		if err != nil {
+			t.Fatalf("failed to marshal parsed IE: %v", err)
+		}
+		if !bytes.Equal(got, b[:len(got)]) {
+			t.Errorf("round trip mismatch: got %x, want %x", got, b[:len(got)])
+		}
+		_ = i.String()
+	})
+}
+
+func FuzzParseGrouped(f *testing.F) {
+	for _, c := range testIEs {
+		if c.structured.IsGrouped() {
+			f.Add(c.serialized[4:])
+		}
+	}
+	nested := ie.NewBearerContext(
+		ie.NewEPSBearerID(5),
+		ie.NewBearerContext(ie.NewEPSBearerID(6), ie.NewBearerContext(ie.NewEPSBearerID(7))),
+	)
+	f.Add(nested.Payload)
+
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		if len(payload) > 0xffff {
+			return
+		}
+
+		b := append([]byte{ie.BearerContext, uint8(len(payload) >> 8), uint8(len(payload)), 0x00}, payload...)
+
+		i, err := ie.Parse(b)
+		if err != nil {
+			return
+		}
+
+		got, err := i.Marshal()
+		if err != nil {
+			t.Fatalf("failed to marshal parsed IE: %v", err)
+		}
+		if !bytes.Equal(got, b) {
+			t.Errorf("round trip mismatch: got %x, want %x", got, b)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+import (
+	"fmt"
+	"io"
+)
+
+// The IEs that consist only of the fixed-length fields are described in ies.json,
+// and their constructors and getters are generated from it.
+// To add a new one, append it to ies.json and run go generate.
+//go:generate go run ./internal/iegen -i ies.json -o generated.go
+
+// Validate checks if the payload of the IE is long enough to contain all the
+// fields of its type.
+//
+// Only the types described in ies.json are checked for now, and nil is
+// always returned for the others.
+func (i *IE) Validate() error {
+	l, ok := payloadMinLengths[i.Type]
+	if !ok {
+		return nil
+	}
+
+	if len(i.Payload) < l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// ValueString returns the fields of the IE in human readable format.
+//
+// Only the types described in ies.json are decoded for now, and the payload
+// in hex is returned for the others or when the IE is malformed.
+func (i *IE) ValueString() string {
+	f, ok := valueFormatters[i.Type]
+	if !ok || i.Validate() != nil {
+		return fmt.Sprintf("%x", i.Payload)
+	}
+	return f(i)
+}
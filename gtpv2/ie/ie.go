@@ -10,6 +10,8 @@ package ie
 import (
 	"encoding/binary"
 	"fmt"
+
+	"github.com/wmnsk/go-gtp/utils"
 )
 
 // IE definitions.
@@ -227,6 +229,9 @@ const (
 	ServicesAuthorized
 	BitRate
 	PC5QoSFlow
+	SGiPtPTunnelAddress
+	PGWChangeInfo
+	PGWFQDN
 	_
 	_
 	_
@@ -264,10 +269,7 @@ const (
 	_
 	_
 	_
-	_
-	_
-	_
-	_ // 206-253: Spare for future use
+	_ // 216-253: Spare for future use
 	SpecialIETypeForIETypeExtension
 	PrivateExtension
 )
@@ -309,6 +311,30 @@ func (i *IE) Instance() uint8 {
 	return i.instance & 0x0f
 }
 
+// Clone returns a deep copy of the IE, including its Payload and ChildIEs,
+// which can be modified without affecting the original one.
+func (i *IE) Clone() *IE {
+	if i == nil {
+		return nil
+	}
+
+	c := &IE{
+		Type:     i.Type,
+		Length:   i.Length,
+		instance: i.instance,
+	}
+	if i.Payload != nil {
+		c.Payload = append(make([]byte, 0, len(i.Payload)), i.Payload...)
+	}
+	if i.ChildIEs != nil {
+		c.ChildIEs = make([]*IE, len(i.ChildIEs))
+		for n, child := range i.ChildIEs {
+			c.ChildIEs[n] = child.Clone()
+		}
+	}
+	return c
+}
+
 // Marshal returns the byte sequence generated from an IE instance.
 func (i *IE) Marshal() ([]byte, error) {
 	b := make([]byte, i.MarshalLen())
@@ -318,6 +344,18 @@ func (i *IE) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from an IE instance to b and
+// returns the extended buffer. b is reallocated only when its capacity is not
+// enough, so the buffers can be reused without allocating.
+func (i *IE) AppendTo(b []byte) ([]byte, error) {
+	l := len(b)
+	b = utils.Grow(b, i.MarshalLen())
+	if err := i.MarshalTo(b[l:]); err != nil {
+		return b[:l], err
+	}
+	return b, nil
+}
+
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (i *IE) MarshalTo(b []byte) error {
 	b[0] = i.Type
@@ -346,8 +384,17 @@ func Parse(b []byte) (*IE, error) {
 	return ie, nil
 }
 
+// maxNestingDepth is the maximum depth of grouped IEs accepted by Parse.
+// No grouped IE defined in TS 29.274 goes deeper than a few levels, so
+// anything beyond this is treated as malformed rather than recursed into.
+const maxNestingDepth = 8
+
 // UnmarshalBinary sets the values retrieved from byte sequence in GTPv2 IE.
 func (i *IE) UnmarshalBinary(b []byte) error {
+	return i.unmarshalBinary(b, 0)
+}
+
+func (i *IE) unmarshalBinary(b []byte, depth int) error {
 	l := len(b)
 	if l < 5 {
 		return ErrTooShortToParse
@@ -356,15 +403,19 @@ func (i *IE) UnmarshalBinary(b []byte) error {
 	i.Type = b[0]
 	i.Length = binary.BigEndian.Uint16(b[1:3])
 	if int(i.Length) > l-4 {
-		return ErrInvalidLength
+		return &InvalidLengthError{Type: i.Type, Got: l - 4, Want: int(i.Length)}
 	}
 
 	i.instance = b[3]
 	i.Payload = b[4 : 4+int(i.Length)]
 
 	if i.IsGrouped() {
+		if depth >= maxNestingDepth {
+			return ErrTooDeeplyNested
+		}
+
 		var err error
-		i.ChildIEs, err = ParseMultiIEs(i.Payload)
+		i.ChildIEs, err = parseMultiIEs(i.Payload, depth+1)
 		if err != nil {
 			return err
 		}
@@ -409,6 +460,8 @@ func (i *IE) String() string {
 
 var grouped = []uint8{
 	BearerContext,
+	PGWChangeInfo,
+	OverloadControlInformation,
 	// TODO: add all grouped type of IEs here.
 }
 
@@ -429,15 +482,8 @@ func (i *IE) Add(ies ...*IE) {
 		return
 	}
 
-	i.Payload = nil
 	i.ChildIEs = append(i.ChildIEs, ies...)
-	for _, ie := range i.ChildIEs {
-		serialized, err := ie.Marshal()
-		if err != nil {
-			continue
-		}
-		i.Payload = append(i.Payload, serialized...)
-	}
+	i.Payload, _ = marshalChildIEs(i.ChildIEs)
 	i.SetLength()
 }
 
@@ -447,21 +493,15 @@ func (i *IE) Remove(typ, instance uint8) {
 		return
 	}
 
-	i.Payload = nil
 	var newChildren []*IE
 	for _, ie := range i.ChildIEs {
 		if ie.Type == typ && ie.Instance() == instance {
 			continue
 		}
 		newChildren = append(newChildren, ie)
-
-		serialized, err := ie.Marshal()
-		if err != nil {
-			continue
-		}
-		i.Payload = append(i.Payload, serialized...)
 	}
 	i.ChildIEs = newChildren
+	i.Payload, _ = marshalChildIEs(i.ChildIEs)
 	i.SetLength()
 }
 
@@ -471,7 +511,7 @@ func (i *IE) Remove(typ, instance uint8) {
 // because this ranges over a ChildIEs each time it is called.
 func (i *IE) FindByType(typ, instance uint8) (*IE, error) {
 	if !i.IsGrouped() {
-		return nil, ErrInvalidType
+		return nil, &InvalidTypeError{Type: i.Type}
 	}
 
 	for _, ie := range i.ChildIEs {
@@ -479,7 +519,46 @@ func (i *IE) FindByType(typ, instance uint8) (*IE, error) {
 			return ie, nil
 		}
 	}
-	return nil, ErrIENotFound
+	return nil, &IENotFoundError{Type: typ, Instance: instance}
+}
+
+// FindAllByType returns all the IEs looked up by type and instance, in the
+// order they appear in a grouped IE.
+//
+// It returns *IENotFoundError if no IE matches.
+func (i *IE) FindAllByType(typ, instance uint8) ([]*IE, error) {
+	if !i.IsGrouped() {
+		return nil, &InvalidTypeError{Type: i.Type}
+	}
+
+	var found []*IE
+	for _, ie := range i.ChildIEs {
+		if ie.Type == typ && ie.Instance() == instance {
+			found = append(found, ie)
+		}
+	}
+	if len(found) == 0 {
+		return nil, &IENotFoundError{Type: typ, Instance: instance}
+	}
+	return found, nil
+}
+
+// FindByEBI returns the grouped IE that contains the EPS Bearer ID given, e.g.,
+// the Bearer Context for the bearer from the ones repeated in a message.
+//
+// It returns *IENotFoundError if no IE matches.
+func FindByEBI(ies []*IE, ebi uint8) (*IE, error) {
+	for _, i := range ies {
+		if i == nil || !i.IsGrouped() {
+			continue
+		}
+		for _, child := range i.ChildIEs {
+			if child.Type == EPSBearerID && child.MustEPSBearerID() == ebi {
+				return i, nil
+			}
+		}
+	}
+	return nil, &IENotFoundError{Type: EPSBearerID}
 }
 
 // ParseMultiIEs decodes multiple IEs at a time.
@@ -487,14 +566,28 @@ func (i *IE) FindByType(typ, instance uint8) (*IE, error) {
 // When you don't know the number of IEs, this is the only way to decode them.
 // See benchmarks in diameter_test.go for the detail.
 func ParseMultiIEs(b []byte) ([]*IE, error) {
-	var ies []*IE
-	for {
-		if len(b) == 0 {
-			break
+	return parseMultiIEs(b, 0)
+}
+
+func parseMultiIEs(b []byte, depth int) ([]*IE, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	// allocate all the IEs at once instead of one by one, as the number of
+	// allocations is what matters most in parsing the messages.
+	n := countIEs(b)
+	ies := make([]*IE, 0, n)
+	slab := make([]IE, n)
+	for k := 0; len(b) > 0; k++ {
+		var i *IE
+		if k < n {
+			i = &slab[k]
+		} else {
+			i = &IE{}
 		}
 
-		i, err := Parse(b)
-		if err != nil {
+		if err := i.unmarshalBinary(b, depth); err != nil {
 			return nil, err
 		}
 		ies = append(ies, i)
@@ -503,6 +596,24 @@ func ParseMultiIEs(b []byte) ([]*IE, error) {
 	return ies, nil
 }
 
+// countIEs returns the number of IEs in b, assuming that b is well-formed.
+// The malformed ones are detected later in unmarshalBinary.
+func countIEs(b []byte) int {
+	n := 0
+	for len(b) >= 4 {
+		n++
+		l := 4 + int(binary.BigEndian.Uint16(b[1:3]))
+		if l > len(b) {
+			return n
+		}
+		b = b[l:]
+	}
+	if len(b) > 0 {
+		n++
+	}
+	return n
+}
+
 func newUint8ValIE(t, v uint8) *IE {
 	return New(t, 0x00, []byte{v})
 }
@@ -531,16 +642,34 @@ func newStringIE(t uint8, v string) *IE {
 }
 
 func newGroupedIE(itype uint8, ies ...*IE) *IE {
-	i := New(itype, 0x00, make([]byte, 0))
+	i := New(itype, 0x00, nil)
 	i.ChildIEs = ies
-	for _, ie := range i.ChildIEs {
-		serialized, err := ie.Marshal()
-		if err != nil {
-			return nil
-		}
-		i.Payload = append(i.Payload, serialized...)
+
+	var err error
+	i.Payload, err = marshalChildIEs(ies)
+	if err != nil {
+		return nil
 	}
 	i.SetLength()
 
 	return i
 }
+
+// marshalChildIEs serializes the IEs into a single buffer, without allocating
+// the intermediate buffer for each IE.
+func marshalChildIEs(ies []*IE) ([]byte, error) {
+	l := 0
+	for _, ie := range ies {
+		l += ie.MarshalLen()
+	}
+
+	b := make([]byte, l)
+	offset := 0
+	for _, ie := range ies {
+		if err := ie.MarshalTo(b[offset:]); err != nil {
+			return nil, err
+		}
+		offset += ie.MarshalLen()
+	}
+	return b, nil
+}
@@ -5,6 +5,8 @@
 package ie_test
 
 import (
+	"errors"
+	"net"
 	"testing"
 	"time"
 
@@ -13,421 +15,497 @@ import (
 	"github.com/wmnsk/go-gtp/gtpv2/ie"
 )
 
-func TestIEs(t *testing.T) {
-	cases := []struct {
-		description string
-		structured  *ie.IE
-		serialized  []byte
-	}{
-		{
-			"IMSI",
-			ie.NewIMSI("123451234567890"),
-			[]byte{0x01, 0x00, 0x08, 0x00, 0x21, 0x43, 0x15, 0x32, 0x54, 0x76, 0x98, 0xf0},
-		}, {
-			"Cause",
-			ie.NewCause(gtpv2.CauseRequestAccepted, 0, 0, 0, nil),
-			[]byte{0x02, 0x00, 0x02, 0x00, 0x10, 0x00},
-		}, {
-			"CauseIMSIIMEINotKnown",
-			ie.NewCause(gtpv2.CauseIMSIIMEINotKnown, 1, 0, 0, ie.NewIMSI("")),
-			[]byte{0x02, 0x00, 0x06, 0x00, 0x60, 0x04, 0x01, 0x00, 0x00, 0x00},
-		}, {
-			"Recovery",
-			ie.NewRecovery(0xff),
-			[]byte{0x03, 0x00, 0x01, 0x00, 0xff},
-		}, {
-			"AccessPointName",
-			ie.NewAccessPointName("some.apn.example"),
-			[]byte{0x47, 0x00, 0x11, 0x00, 0x04, 0x73, 0x6f, 0x6d, 0x65, 0x03, 0x61, 0x70, 0x6e, 0x07, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65},
-		}, {
-			"AggregateMaximumBitRate",
-			ie.NewAggregateMaximumBitRate(0x11111111, 0x22222222),
-			[]byte{0x48, 0x00, 0x08, 0x00, 0x11, 0x11, 0x11, 0x11, 0x22, 0x22, 0x22, 0x22},
-		}, {
-			"EPSBearerID",
-			ie.NewEPSBearerID(0x05),
-			[]byte{0x49, 0x00, 0x01, 0x00, 0x05},
-		}, {
-			"IPAddress/v4",
-			ie.NewIPAddress("1.1.1.1"),
-			[]byte{0x4a, 0x00, 0x04, 0x00, 0x01, 0x01, 0x01, 0x01},
-		}, {
-			"IPAddress/v6",
-			ie.NewIPAddress("2001::1"),
-			[]byte{0x4a, 0x00, 0x10, 0x00, 0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
-		}, {
-			"MobileEquipmentIdentity",
-			ie.NewMobileEquipmentIdentity("123450123456789"),
-			[]byte{0x4b, 0x00, 0x08, 0x00, 0x21, 0x43, 0x05, 0x21, 0x43, 0x65, 0x87, 0xf9},
-		}, {
-			"MSISDN",
-			ie.NewMSISDN("123450123456789"),
-			[]byte{0x4c, 0x00, 0x08, 0x00, 0x21, 0x43, 0x05, 0x21, 0x43, 0x65, 0x87, 0xf9},
-		}, {
-			"Indication",
-			ie.NewIndication(
-				1, 0, 1, 0, 0, 0, 0, 1,
-				0, 0, 0, 0, 1, 0, 0, 0,
-				0, 0, 0, 1, 0, 1, 0, 1,
-				0, 0, 0, 1, 0, 0, 0, 0,
-				1, 0, 0, 0, 1, 0, 0, 0,
-				1, 0, 0, 0, 0, 0, 0, 1,
-				0, 1, 0, 0, 0, 0, 0, 0,
-				1, 0, 1, 0, 0, 0, 0, 0,
-				0, 0, 0, 0, 0, 0, 0, 1,
-			),
-			[]byte{0x4d, 0x00, 0x09, 0x00, 0xa1, 0x08, 0x15, 0x10, 0x88, 0x81, 0x40, 0xa0, 0x01},
-		}, {
-			"IndicationFromBitSequence",
-			ie.NewIndicationFromBitSequence("101000010000100000010101000100001000100010000001010000001010000000000001"),
-			[]byte{0x4d, 0x00, 0x09, 0x00, 0xa1, 0x08, 0x15, 0x10, 0x88, 0x81, 0x40, 0xa0, 0x01},
-		}, {
-			"IndicationFromOctets/Full",
-			ie.NewIndicationFromOctets(0xa1, 0x08, 0x15, 0x10, 0x88, 0x81, 0x40, 0xa0, 0x01),
-			[]byte{0x4d, 0x00, 0x09, 0x00, 0xa1, 0x08, 0x15, 0x10, 0x88, 0x81, 0x40, 0xa0, 0x01},
-		}, {
-			"IndicationFromOctets/Short",
-			ie.NewIndicationFromOctets(0xa1, 0x08),
-			[]byte{0x4d, 0x00, 0x02, 0x00, 0xa1, 0x08},
-		}, {
-			"ProtocolConfigurationOptions",
-			ie.NewProtocolConfigurationOptions(
-				gtpv2.ConfigProtocolPPPWithIP,
-				// see pco-ppp_test.go for how to create these payload.
-				ie.NewPCOContainer(gtpv2.ProtoIDIPCP, []byte{0x01, 0x00, 0x00, 0x10, 0x03, 0x06, 0x01, 0x01, 0x01, 0x01, 0x81, 0x06, 0x02, 0x02, 0x02, 0x02}),
-				ie.NewPCOContainer(gtpv2.ProtoIDPAP, []byte{0x01, 0x00, 0x00, 0x0c, 0x03, 0x66, 0x6f, 0x6f, 0x03, 0x62, 0x61, 0x72}),
-				ie.NewPCOContainer(gtpv2.ProtoIDCHAP, []byte{0x01, 0x00, 0x00, 0x0c, 0x04, 0xde, 0xad, 0xbe, 0xef, 0x66, 0x6f, 0x6f}),
-				ie.NewPCOContainer(gtpv2.ContIDMSSupportofNetworkRequestedBearerControlIndicator, nil),
-				ie.NewPCOContainer(gtpv2.ContIDIPaddressAllocationViaNASSignalling, nil),
-				ie.NewPCOContainer(gtpv2.ContIDDNSServerIPv4AddressRequest, nil),
-				ie.NewPCOContainer(gtpv2.ContIDIPv4LinkMTURequest, nil),
-			),
-			[]byte{
-				0x4e, 0x00, 0x3e, 0x00,
-				// Extension / ConfigurationProtocol
-				0x80,
-				// IPCP
-				0x80, 0x21, 0x10, 0x01, 0x00, 0x00, 0x10, 0x03, 0x06, 0x01, 0x01, 0x01, 0x01, 0x81, 0x06, 0x02, 0x02, 0x02, 0x02,
-				// PAP
-				0xc0, 0x23, 0x0c, 0x01, 0x00, 0x00, 0x0c, 0x03, 0x66, 0x6f, 0x6f, 0x03, 0x62, 0x61, 0x72,
-				// CHAP
-				0xc2, 0x23, 0x0c, 0x01, 0x00, 0x00, 0x0c, 0x04, 0xde, 0xad, 0xbe, 0xef, 0x66, 0x6f, 0x6f,
-				// Bearer control indicator
-				0x00, 0x05, 0x00,
-				// IP alloc via NAS
-				0x00, 0x0a, 0x00,
-				// DNS server request
-				0x00, 0x0d, 0x00,
-				// IPv4 link MTU request
-				0x00, 0x10, 0x00,
-			},
-		}, {
-			"PDNAddressAllocation/v4",
-			ie.NewPDNAddressAllocation("1.1.1.1"),
-			[]byte{0x4f, 0x00, 0x05, 0x00, 0x01, 0x01, 0x01, 0x01, 0x01},
+var testIEs = []struct {
+	description string
+	structured  *ie.IE
+	serialized  []byte
+}{
+	{
+		"IMSI",
+		ie.NewIMSI("123451234567890"),
+		[]byte{0x01, 0x00, 0x08, 0x00, 0x21, 0x43, 0x15, 0x32, 0x54, 0x76, 0x98, 0xf0},
+	}, {
+		"Cause",
+		ie.NewCause(gtpv2.CauseRequestAccepted, 0, 0, 0, nil),
+		[]byte{0x02, 0x00, 0x02, 0x00, 0x10, 0x00},
+	}, {
+		"CauseIMSIIMEINotKnown",
+		ie.NewCause(gtpv2.CauseIMSIIMEINotKnown, 1, 0, 0, ie.NewIMSI("")),
+		[]byte{0x02, 0x00, 0x06, 0x00, 0x60, 0x04, 0x01, 0x00, 0x00, 0x00},
+	}, {
+		"Recovery",
+		ie.NewRecovery(0xff),
+		[]byte{0x03, 0x00, 0x01, 0x00, 0xff},
+	}, {
+		"AccessPointName",
+		ie.NewAccessPointName("some.apn.example"),
+		[]byte{0x47, 0x00, 0x11, 0x00, 0x04, 0x73, 0x6f, 0x6d, 0x65, 0x03, 0x61, 0x70, 0x6e, 0x07, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65},
+	}, {
+		"AccessPointName/WithOI",
+		ie.NewAccessPointNameWithOI("internet", "123", "45"),
+		[]byte{
+			0x47, 0x00, 0x1c, 0x00,
+			0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x65, 0x74,
+			0x06, 0x6d, 0x6e, 0x63, 0x30, 0x34, 0x35,
+			0x06, 0x6d, 0x63, 0x63, 0x31, 0x32, 0x33,
+			0x04, 0x67, 0x70, 0x72, 0x73,
 		},
-		/* XXX - needs fix in NewPDNAddressAllocation!
-		{
-			"PDNAddressAllocation/v6",
-			ie.NewPDNAddressAllocation("2001::1"),
-			[]byte{0x4f, 0x00, 0x12, 0x00, 0x02, 0x00, 0x20, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
-		}, */
-		{
-			"BearerQoS",
-			ie.NewBearerQoS(1, 2, 1, 0xff, 0x1111111111, 0x2222222222, 0x1111111111, 0x2222222222),
-			[]byte{0x50, 0x00, 0x16, 0x00, 0x49, 0xff, 0x11, 0x11, 0x11, 0x11, 0x11, 0x22, 0x22, 0x22, 0x22, 0x22, 0x11, 0x11, 0x11, 0x11, 0x11, 0x22, 0x22, 0x22, 0x22, 0x22},
-		}, {
-			"FlowQoS",
-			ie.NewFlowQoS(0xff, 0x1111111111, 0x2222222222, 0x1111111111, 0x2222222222),
-			[]byte{0x51, 0x00, 0x15, 0x00, 0xff, 0x11, 0x11, 0x11, 0x11, 0x11, 0x22, 0x22, 0x22, 0x22, 0x22, 0x11, 0x11, 0x11, 0x11, 0x11, 0x22, 0x22, 0x22, 0x22, 0x22},
-		}, {
-			"RATType",
-			ie.NewRATType(gtpv2.RATTypeEUTRAN),
-			[]byte{0x52, 0x00, 0x01, 0x00, 0x06},
-		}, {
-			"ServingNetwork/2-digit",
-			ie.NewServingNetwork("123", "45"),
-			[]byte{0x53, 0x00, 0x03, 0x00, 0x21, 0xf3, 0x54},
-		}, {
-			"ServingNetwork/3-digit",
-			ie.NewServingNetwork("123", "456"),
-			[]byte{0x53, 0x00, 0x03, 0x00, 0x21, 0x63, 0x54},
+	}, {
+		"AggregateMaximumBitRate",
+		ie.NewAggregateMaximumBitRate(0x11111111, 0x22222222),
+		[]byte{0x48, 0x00, 0x08, 0x00, 0x11, 0x11, 0x11, 0x11, 0x22, 0x22, 0x22, 0x22},
+	}, {
+		"EPSBearerID",
+		ie.NewEPSBearerID(0x05),
+		[]byte{0x49, 0x00, 0x01, 0x00, 0x05},
+	}, {
+		"IPAddress/v4",
+		ie.NewIPAddress("1.1.1.1"),
+		[]byte{0x4a, 0x00, 0x04, 0x00, 0x01, 0x01, 0x01, 0x01},
+	}, {
+		"IPAddress/v6",
+		ie.NewIPAddress("2001::1"),
+		[]byte{0x4a, 0x00, 0x10, 0x00, 0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+	}, {
+		"MobileEquipmentIdentity",
+		ie.NewMobileEquipmentIdentity("123450123456789"),
+		[]byte{0x4b, 0x00, 0x08, 0x00, 0x21, 0x43, 0x05, 0x21, 0x43, 0x65, 0x87, 0xf9},
+	}, {
+		"MSISDN",
+		ie.NewMSISDN("123450123456789"),
+		[]byte{0x4c, 0x00, 0x08, 0x00, 0x21, 0x43, 0x05, 0x21, 0x43, 0x65, 0x87, 0xf9},
+	}, {
+		"Indication",
+		ie.NewIndication(
+			1, 0, 1, 0, 0, 0, 0, 1,
+			0, 0, 0, 0, 1, 0, 0, 0,
+			0, 0, 0, 1, 0, 1, 0, 1,
+			0, 0, 0, 1, 0, 0, 0, 0,
+			1, 0, 0, 0, 1, 0, 0, 0,
+			1, 0, 0, 0, 0, 0, 0, 1,
+			0, 1, 0, 0, 0, 0, 0, 0,
+			1, 0, 1, 0, 0, 0, 0, 0,
+			0, 0, 0, 0, 0, 0, 0, 1,
+		),
+		[]byte{0x4d, 0x00, 0x09, 0x00, 0xa1, 0x08, 0x15, 0x10, 0x88, 0x81, 0x40, 0xa0, 0x01},
+	}, {
+		"IndicationFromBitSequence",
+		ie.NewIndicationFromBitSequence("101000010000100000010101000100001000100010000001010000001010000000000001"),
+		[]byte{0x4d, 0x00, 0x09, 0x00, 0xa1, 0x08, 0x15, 0x10, 0x88, 0x81, 0x40, 0xa0, 0x01},
+	}, {
+		"IndicationFromOctets/Full",
+		ie.NewIndicationFromOctets(0xa1, 0x08, 0x15, 0x10, 0x88, 0x81, 0x40, 0xa0, 0x01),
+		[]byte{0x4d, 0x00, 0x09, 0x00, 0xa1, 0x08, 0x15, 0x10, 0x88, 0x81, 0x40, 0xa0, 0x01},
+	}, {
+		"IndicationFromOctets/Short",
+		ie.NewIndicationFromOctets(0xa1, 0x08),
+		[]byte{0x4d, 0x00, 0x02, 0x00, 0xa1, 0x08},
+	}, {
+		"ProtocolConfigurationOptions",
+		ie.NewProtocolConfigurationOptions(
+			gtpv2.ConfigProtocolPPPWithIP,
+			// see pco-ppp_test.go for how to create these payload.
+			ie.NewPCOContainer(gtpv2.ProtoIDIPCP, []byte{0x01, 0x00, 0x00, 0x10, 0x03, 0x06, 0x01, 0x01, 0x01, 0x01, 0x81, 0x06, 0x02, 0x02, 0x02, 0x02}),
+			ie.NewPCOContainer(gtpv2.ProtoIDPAP, []byte{0x01, 0x00, 0x00, 0x0c, 0x03, 0x66, 0x6f, 0x6f, 0x03, 0x62, 0x61, 0x72}),
+			ie.NewPCOContainer(gtpv2.ProtoIDCHAP, []byte{0x01, 0x00, 0x00, 0x0c, 0x04, 0xde, 0xad, 0xbe, 0xef, 0x66, 0x6f, 0x6f}),
+			ie.NewPCOContainer(gtpv2.ContIDMSSupportofNetworkRequestedBearerControlIndicator, nil),
+			ie.NewPCOContainer(gtpv2.ContIDIPaddressAllocationViaNASSignalling, nil),
+			ie.NewPCOContainer(gtpv2.ContIDDNSServerIPv4AddressRequest, nil),
+			ie.NewPCOContainer(gtpv2.ContIDIPv4LinkMTURequest, nil),
+		),
+		[]byte{
+			0x4e, 0x00, 0x3e, 0x00,
+			// Extension / ConfigurationProtocol
+			0x80,
+			// IPCP
+			0x80, 0x21, 0x10, 0x01, 0x00, 0x00, 0x10, 0x03, 0x06, 0x01, 0x01, 0x01, 0x01, 0x81, 0x06, 0x02, 0x02, 0x02, 0x02,
+			// PAP
+			0xc0, 0x23, 0x0c, 0x01, 0x00, 0x00, 0x0c, 0x03, 0x66, 0x6f, 0x6f, 0x03, 0x62, 0x61, 0x72,
+			// CHAP
+			0xc2, 0x23, 0x0c, 0x01, 0x00, 0x00, 0x0c, 0x04, 0xde, 0xad, 0xbe, 0xef, 0x66, 0x6f, 0x6f,
+			// Bearer control indicator
+			0x00, 0x05, 0x00,
+			// IP alloc via NAS
+			0x00, 0x0a, 0x00,
+			// DNS server request
+			0x00, 0x0d, 0x00,
+			// IPv4 link MTU request
+			0x00, 0x10, 0x00,
 		},
-		/* { XXX - implement!
-			"EPSBearerLevelTrafficFlowTemplate",
-			ie.NewEPSBearerLevelTrafficFlowTemplate(),
-			[]byte{},
-		},*/
-		/* { XXX - implement! (same as Bearer TFT)
-			"TrafficAggregateDescription",
-			ie.NewTrafficAggregateDescription(),
-			[]byte{},
-		},*/
-		{
-			"UserLocationInformation/Lazy-1",
-			ie.NewUserLocationInformationLazy(
-				"123", "45",
-				0x1111, 0x2222, 0x3333, -1, 0x5555, 0x666666, -1, 0x22222222,
-			),
-			[]byte{
-				0x56, 0x00, 0x26, 0x00,
-				// Flags
-				0xbb,
-				// CGI
-				0x21, 0xf3, 0x54, 0x11, 0x11, 0x22, 0x22,
-				// SAI
-				0x21, 0xf3, 0x54, 0x11, 0x11, 0x33, 0x33,
-				// TAI
-				0x21, 0xf3, 0x54, 0x55, 0x55,
-				// ECGI
-				0x21, 0xf3, 0x54, 0x00, 0x06, 0x66, 0x66,
-				// RAI
-				0x21, 0xf3, 0x54, 0x11, 0x11,
-				// Extended Macro eNB ID
-				0x21, 0xf3, 0x54, 0x22, 0x22, 0x22,
-			},
-		}, {
-			"UserLocationInformation/Lazy-2",
-			ie.NewUserLocationInformationLazy(
-				"123", "45",
-				0x1111, 0x2222, 0x3333, 0x4444, 0x5555, 0x666666, 0x11111111, 0x22222222,
-			),
-			[]byte{
-				0x56, 0x00, 0x33, 0x00,
-				// Flags
-				0xff,
-				// CGI
-				0x21, 0xf3, 0x54, 0x11, 0x11, 0x22, 0x22,
-				// SAI
-				0x21, 0xf3, 0x54, 0x11, 0x11, 0x33, 0x33,
-				// RAI
-				0x21, 0xf3, 0x54, 0x11, 0x11, 0x44, 0x44,
-				// TAI
-				0x21, 0xf3, 0x54, 0x55, 0x55,
-				// ECGI
-				0x21, 0xf3, 0x54, 0x00, 0x06, 0x66, 0x66,
-				// RAI
-				0x21, 0xf3, 0x54, 0x11, 0x11,
-				// Macro eNB ID
-				0x21, 0xf3, 0x54, 0x11, 0x11, 0x11,
-				// Extended Macro eNB ID
-				0x21, 0xf3, 0x54, 0x22, 0x22, 0x22,
-			},
-		}, {
-			"UserLocationInformation/Full",
-			ie.NewUserLocationInformation(
-				1, 1, 1, 1, 1, 1, 1, 1, "123", "45",
-				0x1111, 0x2222, 0x3333, 0x4444, 0x5555, 0x666666, 0x11111111, 0x22222222,
-			),
-			[]byte{
-				0x56, 0x00, 0x33, 0x00,
-				// Flags
-				0xff,
-				// CGI
-				0x21, 0xf3, 0x54, 0x11, 0x11, 0x22, 0x22,
-				// SAI
-				0x21, 0xf3, 0x54, 0x11, 0x11, 0x33, 0x33,
-				// RAI
-				0x21, 0xf3, 0x54, 0x11, 0x11, 0x44, 0x44,
-				// TAI
-				0x21, 0xf3, 0x54, 0x55, 0x55,
-				// ECGI
-				0x21, 0xf3, 0x54, 0x00, 0x06, 0x66, 0x66,
-				// RAI
-				0x21, 0xf3, 0x54, 0x11, 0x11,
-				// Macro eNB ID
-				0x21, 0xf3, 0x54, 0x11, 0x11, 0x11,
-				// Extended Macro eNB ID
-				0x21, 0xf3, 0x54, 0x22, 0x22, 0x22,
-			},
-		}, {
-			"FullyQualifiedTEID/v4",
-			ie.NewFullyQualifiedTEID(gtpv2.IFTypeS11MMEGTPC, 0xffffffff, "1.1.1.1", ""),
-			[]byte{0x57, 0x00, 0x09, 0x00, 0x8a, 0xff, 0xff, 0xff, 0xff, 0x01, 0x01, 0x01, 0x01},
-		}, {
-			"FullyQualifiedTEID/v6",
-			ie.NewFullyQualifiedTEID(gtpv2.IFTypeS11MMEGTPC, 0xffffffff, "", "2001::1"),
-			[]byte{0x57, 0x00, 0x15, 0x00, 0x4a, 0xff, 0xff, 0xff, 0xff, 0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
-		}, {
-			"FullyQualifiedTEID/v4v6",
-			ie.NewFullyQualifiedTEID(gtpv2.IFTypeS11MMEGTPC, 0xffffffff, "1.1.1.1", "2001::1"),
-			[]byte{0x57, 0x00, 0x19, 0x00, 0xca, 0xff, 0xff, 0xff, 0xff, 0x01, 0x01, 0x01, 0x01, 0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
-		}, {
-			"TMSI",
-			ie.NewTMSI(0xffffffff),
-			[]byte{0x58, 0x00, 0x04, 0x00, 0xff, 0xff, 0xff, 0xff},
-		}, {
-			"GlobalCNID",
-			ie.NewGlobalCNID("123", "45", 0xfff),
-			[]byte{0x59, 0x00, 0x05, 0x00, 0x21, 0xf3, 0x54, 0x0f, 0xff},
-		}, {
-			"S103PDNDataForwardingInfo/v4",
-			ie.NewS103PDNDataForwardingInfo("1.1.1.1", 0xdeadbeef, 5, 6, 7),
-			[]byte{0x5a, 0x00, 0x0d, 0x00, 0x04, 0x01, 0x01, 0x01, 0x01, 0xde, 0xad, 0xbe, 0xef, 0x03, 0x05, 0x06, 0x07},
-		}, {
-			"S103PDNDataForwardingInfo/v6",
-			ie.NewS103PDNDataForwardingInfo("2001::1", 0xdeadbeef, 5, 6, 7),
-			[]byte{0x5a, 0x00, 0x19, 0x00, 0x10, 0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0xde, 0xad, 0xbe, 0xef, 0x03, 0x05, 0x06, 0x07},
-		}, {
-			"S1UDataForwarding/v4",
-			ie.NewS1UDataForwarding(5, "1.1.1.1", 0xdeadbeef),
-			[]byte{0x5b, 0x00, 0x0a, 0x00, 0x05, 0x04, 0x01, 0x01, 0x01, 0x01, 0xde, 0xad, 0xbe, 0xef},
-		}, {
-			"S1UDataForwarding/v6",
-			ie.NewS1UDataForwarding(5, "2001::1", 0xdeadbeef),
-			[]byte{0x5b, 0x00, 0x16, 0x00, 0x05, 0x10, 0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0xde, 0xad, 0xbe, 0xef},
-		}, {
-			"DelayValue",
-			ie.NewDelayValue(500 * time.Millisecond),
-			[]byte{0x5c, 0x00, 0x01, 0x00, 0x0a},
-		}, {
-			"BearerContext",
-			ie.NewBearerContext(ie.NewDelayValue(500*time.Millisecond), ie.NewDelayValue(100*time.Millisecond)),
-			[]byte{0x5d, 0x00, 0x0a, 0x00, 0x5c, 0x00, 0x01, 0x00, 0x0a, 0x5c, 0x00, 0x01, 0x00, 0x02},
-		}, {
-			"ChargingID",
-			ie.NewChargingID(0xffffffff),
-			[]byte{0x5e, 0x00, 0x04, 0x00, 0xff, 0xff, 0xff, 0xff},
-		}, {
-			"ChargingCharacteristics",
-			ie.NewChargingCharacteristics(0xffff),
-			[]byte{0x5f, 0x00, 0x02, 0x00, 0xff, 0xff},
-		}, {
-			"BearerFlags",
-			ie.NewBearerFlags(1, 1, 1, 1),
-			[]byte{0x61, 0x00, 0x01, 0x00, 0x0f},
-		}, {
-			"PDNType",
-			ie.NewPDNType(gtpv2.PDNTypeIPv4),
-			[]byte{0x63, 0x00, 0x01, 0x00, 0x01},
-		}, {
-			"ProcedureTransactionID",
-			ie.NewProcedureTransactionID(1),
-			[]byte{0x64, 0x00, 0x01, 0x00, 0x01},
-		}, {
-			"PacketTMSI",
-			ie.NewPacketTMSI(0xdeadbeef),
-			[]byte{0x6f, 0x00, 0x04, 0x00, 0xde, 0xad, 0xbe, 0xef},
-		}, {
-			"PTMSISignature",
-			ie.NewPTMSISignature(0xbeebee),
-			[]byte{0x70, 0x00, 0x03, 0x00, 0xbe, 0xeb, 0xee},
-		}, {
-			"HopCounter",
-			ie.NewHopCounter(1),
-			[]byte{0x71, 0x00, 0x01, 0x00, 0x01},
-		}, {
-			"UETimeZone",
-			ie.NewUETimeZone(9*time.Hour, 0),
-			[]byte{0x72, 0x00, 0x02, 0x00, 0x63, 0x00},
-		}, {
-			"TraceReference",
-			ie.NewTraceReference("123", "45", 1),
-			[]byte{0x73, 0x00, 0x06, 0x00, 0x21, 0xf3, 0x54, 0x00, 0x00, 0x01},
-		}, {
-			"GUTI",
-			ie.NewGUTI("123", "45", 0x1111, 0x22, 0x33333333),
-			[]byte{0x75, 0x00, 0x0a, 0x00, 0x21, 0xf3, 0x54, 0x11, 0x11, 0x22, 0x33, 0x33, 0x33, 0x33},
-		}, {
-			"PLMNID/2digits",
-			ie.NewPLMNID("123", "45"),
-			[]byte{0x78, 0x00, 0x03, 0x00, 0x21, 0xf3, 0x54},
-		}, {
-			"PLMNID/3digits",
-			ie.NewPLMNID("123", "456"),
-			[]byte{0x78, 0x00, 0x03, 0x00, 0x21, 0x63, 0x54},
-		}, {
-			"PortNumber",
-			ie.NewPortNumber(2123),
-			[]byte{0x7e, 0x00, 0x02, 0x00, 0x08, 0x4b},
-		}, {
-			"APNRestriction",
-			ie.NewAPNRestriction(gtpv2.APNRestrictionPublic1),
-			[]byte{0x7f, 0x00, 0x01, 0x00, 0x01},
-		}, {
-			"SelectionMode",
-			ie.NewSelectionMode(gtpv2.SelectionModeMSProvidedAPNSubscriptionNotVerified),
-			[]byte{0x80, 0x00, 0x01, 0x00, 0x01},
-		}, {
-			"FullyQualifiedCSID/v4",
-			ie.NewFullyQualifiedCSID("1.1.1.1", 1),
-			[]byte{0x84, 0x00, 0x07, 0x00, 0x01, 0x01, 0x01, 0x01, 0x01, 0x00, 0x01},
-		}, {
-			"FullyQualifiedCSID/v4/multiCSIDs",
-			ie.NewFullyQualifiedCSID("1.1.1.1", 1, 2),
-			[]byte{0x84, 0x00, 0x09, 0x00, 0x02, 0x01, 0x01, 0x01, 0x01, 0x00, 0x01, 0x00, 0x02},
-		}, {
-			"FullyQualifiedCSID/v6",
-			ie.NewFullyQualifiedCSID("2001::1", 1),
-			[]byte{0x84, 0x00, 0x13, 0x00, 0x11, 0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x01},
-		}, {
-			"FullyQualifiedCSID/other",
-			ie.NewFullyQualifiedCSID("12304501", 1),
-			[]byte{0x84, 0x00, 0x07, 0x00, 0x21, 0x12, 0x30, 0x45, 0x01, 0x00, 0x01},
-		}, {
-			"NodeType",
-			ie.NewNodeType(gtpv2.NodeTypeMME),
-			[]byte{0x87, 0x00, 0x01, 0x00, 0x01},
-		}, {
-			"FullyQualifiedDomainName",
-			ie.NewFullyQualifiedDomainName("some-fqdn.example"),
-			[]byte{0x88, 0x00, 0x12, 0x00, 0x09, 0x73, 0x6f, 0x6d, 0x65, 0x2d, 0x66, 0x71, 0x64, 0x6e, 0x07, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65},
-		}, {
-			"RFSPIndex",
-			ie.NewRFSPIndex(1),
-			[]byte{0x90, 0x00, 0x01, 0x00, 0x01},
-		}, {
-			"UserCSGInformation",
-			ie.NewUserCSGInformation("123", "45", 0x00ffffff, gtpv2.AccessModeHybrid, 0, gtpv2.CMICSG),
-			[]byte{0x91, 0x00, 0x08, 0x00, 0x21, 0xf3, 0x54, 0x00, 0xff, 0xff, 0xff, 0x41},
-		}, {
-			"CSGID",
-			ie.NewCSGID(0x00ffffff),
-			[]byte{0x93, 0x00, 0x04, 0x00, 0x00, 0xff, 0xff, 0xff},
-		}, {
-			"CSGMembershipIndication",
-			ie.NewCSGMembershipIndication(gtpv2.CMICSG),
-			[]byte{0x94, 0x00, 0x01, 0x00, 0x01},
-		}, {
-			"ServiceIndicator",
-			ie.NewServiceIndicator(gtpv2.ServiceIndCSCall),
-			[]byte{0x95, 0x00, 0x01, 0x00, 0x01},
-		}, {
-			"DetachType",
-			ie.NewDetachType(gtpv2.DetachTypePS),
-			[]byte{0x96, 0x00, 0x01, 0x00, 0x01},
-		}, {
-			"LocalDistinguishedName",
-			ie.NewLocalDistinguishedName("some-name"),
-			[]byte{0x97, 0x00, 0x09, 0x00, 0x73, 0x6f, 0x6d, 0x65, 0x2d, 0x6e, 0x61, 0x6d, 0x65},
-		}, {
-			"NodeFeatures",
-			ie.NewNodeFeatures(0x01),
-			[]byte{0x98, 0x00, 0x01, 0x00, 0x01},
-		}, {
-			"AllocationRetensionPriority",
-			ie.NewAllocationRetensionPriority(1, 2, 1),
-			[]byte{0x9b, 0x00, 0x01, 0x00, 0x49},
-		}, {
-			"ULITimestamp",
-			ie.NewULITimestamp(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)),
-			[]byte{0xaa, 0x00, 0x04, 0x00, 0xdf, 0xd5, 0x2c, 0x00},
-		}, {
-			"MBMSFlags",
-			ie.NewMBMSFlags(1, 1),
-			[]byte{0xab, 0x00, 0x01, 0x00, 0x03},
-		}, {
-			"RANNASCause",
-			ie.NewRANNASCause(gtpv2.ProtoTypeS1APCause, gtpv2.CauseTypeNAS, []byte{0x01}),
-			[]byte{0xac, 0x00, 0x02, 0x00, 0x12, 0x01},
-		}, {
-			"PrivateExtension",
-			ie.NewPrivateExtension(10415, []byte{0xde, 0xad, 0xbe, 0xef}),
-			[]byte{0xff, 0x00, 0x06, 0x00, 0x28, 0xaf, 0xde, 0xad, 0xbe, 0xef},
+	}, {
+		"PDNAddressAllocation/v4",
+		ie.NewPDNAddressAllocation("1.1.1.1"),
+		[]byte{0x4f, 0x00, 0x05, 0x00, 0x01, 0x01, 0x01, 0x01, 0x01},
+	},
+	/* XXX - needs fix in NewPDNAddressAllocation!
+	{
+		"PDNAddressAllocation/v6",
+		ie.NewPDNAddressAllocation("2001::1"),
+		[]byte{0x4f, 0x00, 0x12, 0x00, 0x02, 0x00, 0x20, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+	}, */
+	{
+		"BearerQoS",
+		ie.NewBearerQoS(1, 2, 1, 0xff, 0x1111111111, 0x2222222222, 0x1111111111, 0x2222222222),
+		[]byte{0x50, 0x00, 0x16, 0x00, 0x49, 0xff, 0x11, 0x11, 0x11, 0x11, 0x11, 0x22, 0x22, 0x22, 0x22, 0x22, 0x11, 0x11, 0x11, 0x11, 0x11, 0x22, 0x22, 0x22, 0x22, 0x22},
+	}, {
+		"BearerTFT",
+		ie.NewBearerTFT(ie.TFTOpCreateNewTFT, []*ie.TFTPacketFilter{
+			ie.NewTFTPacketFilter(1, ie.TFTPFBidirectional, 0x10, &ie.PacketFilterComponents{
+				RemoteAddress: &net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)},
+				HasProtocol:   true,
+				Protocol:      17,
+				RemotePortLow: 53,
+			}),
+		}, nil),
+		[]byte{
+			0x54, 0x00, 0x12, 0x00,
+			0x21, 0x31, 0x10, 0x0e,
+			0x10, 0x0a, 0x00, 0x00, 0x00, 0xff, 0x00, 0x00, 0x00,
+			0x30, 0x11,
+			0x50, 0x00, 0x35,
 		},
-	}
+	}, {
+		"FlowQoS",
+		ie.NewFlowQoS(0xff, 0x1111111111, 0x2222222222, 0x1111111111, 0x2222222222),
+		[]byte{0x51, 0x00, 0x15, 0x00, 0xff, 0x11, 0x11, 0x11, 0x11, 0x11, 0x22, 0x22, 0x22, 0x22, 0x22, 0x11, 0x11, 0x11, 0x11, 0x11, 0x22, 0x22, 0x22, 0x22, 0x22},
+	}, {
+		"RATType",
+		ie.NewRATType(gtpv2.RATTypeEUTRAN),
+		[]byte{0x52, 0x00, 0x01, 0x00, 0x06},
+	}, {
+		"ServingNetwork/2-digit",
+		ie.NewServingNetwork("123", "45"),
+		[]byte{0x53, 0x00, 0x03, 0x00, 0x21, 0xf3, 0x54},
+	}, {
+		"ServingNetwork/3-digit",
+		ie.NewServingNetwork("123", "456"),
+		[]byte{0x53, 0x00, 0x03, 0x00, 0x21, 0x63, 0x54},
+	},
+	/* { XXX - implement!
+		"EPSBearerLevelTrafficFlowTemplate",
+		ie.NewEPSBearerLevelTrafficFlowTemplate(),
+		[]byte{},
+	},*/
+	/* { XXX - implement! (same as Bearer TFT)
+		"TrafficAggregateDescription",
+		ie.NewTrafficAggregateDescription(),
+		[]byte{},
+	},*/
+	{
+		"UserLocationInformation/Lazy-1",
+		ie.NewUserLocationInformationLazy(
+			"123", "45",
+			0x1111, 0x2222, 0x3333, -1, 0x5555, 0x666666, -1, 0x22222222,
+		),
+		[]byte{
+			0x56, 0x00, 0x26, 0x00,
+			// Flags
+			0xbb,
+			// CGI
+			0x21, 0xf3, 0x54, 0x11, 0x11, 0x22, 0x22,
+			// SAI
+			0x21, 0xf3, 0x54, 0x11, 0x11, 0x33, 0x33,
+			// TAI
+			0x21, 0xf3, 0x54, 0x55, 0x55,
+			// ECGI
+			0x21, 0xf3, 0x54, 0x00, 0x06, 0x66, 0x66,
+			// RAI
+			0x21, 0xf3, 0x54, 0x11, 0x11,
+			// Extended Macro eNB ID
+			0x21, 0xf3, 0x54, 0x22, 0x22, 0x22,
+		},
+	}, {
+		"UserLocationInformation/Lazy-2",
+		ie.NewUserLocationInformationLazy(
+			"123", "45",
+			0x1111, 0x2222, 0x3333, 0x4444, 0x5555, 0x666666, 0x11111111, 0x22222222,
+		),
+		[]byte{
+			0x56, 0x00, 0x33, 0x00,
+			// Flags
+			0xff,
+			// CGI
+			0x21, 0xf3, 0x54, 0x11, 0x11, 0x22, 0x22,
+			// SAI
+			0x21, 0xf3, 0x54, 0x11, 0x11, 0x33, 0x33,
+			// RAI
+			0x21, 0xf3, 0x54, 0x11, 0x11, 0x44, 0x44,
+			// TAI
+			0x21, 0xf3, 0x54, 0x55, 0x55,
+			// ECGI
+			0x21, 0xf3, 0x54, 0x00, 0x06, 0x66, 0x66,
+			// RAI
+			0x21, 0xf3, 0x54, 0x11, 0x11,
+			// Macro eNB ID
+			0x21, 0xf3, 0x54, 0x11, 0x11, 0x11,
+			// Extended Macro eNB ID
+			0x21, 0xf3, 0x54, 0x22, 0x22, 0x22,
+		},
+	}, {
+		"UserLocationInformation/Full",
+		ie.NewUserLocationInformation(
+			1, 1, 1, 1, 1, 1, 1, 1, "123", "45",
+			0x1111, 0x2222, 0x3333, 0x4444, 0x5555, 0x666666, 0x11111111, 0x22222222,
+		),
+		[]byte{
+			0x56, 0x00, 0x33, 0x00,
+			// Flags
+			0xff,
+			// CGI
+			0x21, 0xf3, 0x54, 0x11, 0x11, 0x22, 0x22,
+			// SAI
+			0x21, 0xf3, 0x54, 0x11, 0x11, 0x33, 0x33,
+			// RAI
+			0x21, 0xf3, 0x54, 0x11, 0x11, 0x44, 0x44,
+			// TAI
+			0x21, 0xf3, 0x54, 0x55, 0x55,
+			// ECGI
+			0x21, 0xf3, 0x54, 0x00, 0x06, 0x66, 0x66,
+			// RAI
+			0x21, 0xf3, 0x54, 0x11, 0x11,
+			// Macro eNB ID
+			0x21, 0xf3, 0x54, 0x11, 0x11, 0x11,
+			// Extended Macro eNB ID
+			0x21, 0xf3, 0x54, 0x22, 0x22, 0x22,
+		},
+	}, {
+		"FullyQualifiedTEID/v4",
+		ie.NewFullyQualifiedTEID(gtpv2.IFTypeS11MMEGTPC, 0xffffffff, "1.1.1.1", ""),
+		[]byte{0x57, 0x00, 0x09, 0x00, 0x8a, 0xff, 0xff, 0xff, 0xff, 0x01, 0x01, 0x01, 0x01},
+	}, {
+		"FullyQualifiedTEID/v6",
+		ie.NewFullyQualifiedTEID(gtpv2.IFTypeS11MMEGTPC, 0xffffffff, "", "2001::1"),
+		[]byte{0x57, 0x00, 0x15, 0x00, 0x4a, 0xff, 0xff, 0xff, 0xff, 0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+	}, {
+		"FullyQualifiedTEID/v4v6",
+		ie.NewFullyQualifiedTEID(gtpv2.IFTypeS11MMEGTPC, 0xffffffff, "1.1.1.1", "2001::1"),
+		[]byte{0x57, 0x00, 0x19, 0x00, 0xca, 0xff, 0xff, 0xff, 0xff, 0x01, 0x01, 0x01, 0x01, 0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+	}, {
+		"TMSI",
+		ie.NewTMSI(0xffffffff),
+		[]byte{0x58, 0x00, 0x04, 0x00, 0xff, 0xff, 0xff, 0xff},
+	}, {
+		"GlobalCNID",
+		ie.NewGlobalCNID("123", "45", 0xfff),
+		[]byte{0x59, 0x00, 0x05, 0x00, 0x21, 0xf3, 0x54, 0x0f, 0xff},
+	}, {
+		"S103PDNDataForwardingInfo/v4",
+		ie.NewS103PDNDataForwardingInfo("1.1.1.1", 0xdeadbeef, 5, 6, 7),
+		[]byte{0x5a, 0x00, 0x0d, 0x00, 0x04, 0x01, 0x01, 0x01, 0x01, 0xde, 0xad, 0xbe, 0xef, 0x03, 0x05, 0x06, 0x07},
+	}, {
+		"S103PDNDataForwardingInfo/v6",
+		ie.NewS103PDNDataForwardingInfo("2001::1", 0xdeadbeef, 5, 6, 7),
+		[]byte{0x5a, 0x00, 0x19, 0x00, 0x10, 0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0xde, 0xad, 0xbe, 0xef, 0x03, 0x05, 0x06, 0x07},
+	}, {
+		"S1UDataForwarding/v4",
+		ie.NewS1UDataForwarding(5, "1.1.1.1", 0xdeadbeef),
+		[]byte{0x5b, 0x00, 0x0a, 0x00, 0x05, 0x04, 0x01, 0x01, 0x01, 0x01, 0xde, 0xad, 0xbe, 0xef},
+	}, {
+		"S1UDataForwarding/v6",
+		ie.NewS1UDataForwarding(5, "2001::1", 0xdeadbeef),
+		[]byte{0x5b, 0x00, 0x16, 0x00, 0x05, 0x10, 0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0xde, 0xad, 0xbe, 0xef},
+	}, {
+		"DelayValue",
+		ie.NewDelayValue(500 * time.Millisecond),
+		[]byte{0x5c, 0x00, 0x01, 0x00, 0x0a},
+	}, {
+		"BearerContext",
+		ie.NewBearerContext(ie.NewDelayValue(500*time.Millisecond), ie.NewDelayValue(100*time.Millisecond)),
+		[]byte{0x5d, 0x00, 0x0a, 0x00, 0x5c, 0x00, 0x01, 0x00, 0x0a, 0x5c, 0x00, 0x01, 0x00, 0x02},
+	}, {
+		"ChargingID",
+		ie.NewChargingID(0xffffffff),
+		[]byte{0x5e, 0x00, 0x04, 0x00, 0xff, 0xff, 0xff, 0xff},
+	}, {
+		"ChargingCharacteristics",
+		ie.NewChargingCharacteristics(0xffff),
+		[]byte{0x5f, 0x00, 0x02, 0x00, 0xff, 0xff},
+	}, {
+		"BearerFlags",
+		ie.NewBearerFlags(1, 1, 1, 1),
+		[]byte{0x61, 0x00, 0x01, 0x00, 0x0f},
+	}, {
+		"PDNType",
+		ie.NewPDNType(gtpv2.PDNTypeIPv4),
+		[]byte{0x63, 0x00, 0x01, 0x00, 0x01},
+	}, {
+		"ProcedureTransactionID",
+		ie.NewProcedureTransactionID(1),
+		[]byte{0x64, 0x00, 0x01, 0x00, 0x01},
+	}, {
+		"PacketTMSI",
+		ie.NewPacketTMSI(0xdeadbeef),
+		[]byte{0x6f, 0x00, 0x04, 0x00, 0xde, 0xad, 0xbe, 0xef},
+	}, {
+		"PTMSISignature",
+		ie.NewPTMSISignature(0xbeebee),
+		[]byte{0x70, 0x00, 0x03, 0x00, 0xbe, 0xeb, 0xee},
+	}, {
+		"HopCounter",
+		ie.NewHopCounter(1),
+		[]byte{0x71, 0x00, 0x01, 0x00, 0x01},
+	}, {
+		"UETimeZone",
+		ie.NewUETimeZone(9*time.Hour, 0),
+		[]byte{0x72, 0x00, 0x02, 0x00, 0x63, 0x00},
+	}, {
+		"TraceReference",
+		ie.NewTraceReference("123", "45", 1),
+		[]byte{0x73, 0x00, 0x06, 0x00, 0x21, 0xf3, 0x54, 0x00, 0x00, 0x01},
+	}, {
+		"GUTI",
+		ie.NewGUTI("123", "45", 0x1111, 0x22, 0x33333333),
+		[]byte{0x75, 0x00, 0x0a, 0x00, 0x21, 0xf3, 0x54, 0x11, 0x11, 0x22, 0x33, 0x33, 0x33, 0x33},
+	}, {
+		"PLMNID/2digits",
+		ie.NewPLMNID("123", "45"),
+		[]byte{0x78, 0x00, 0x03, 0x00, 0x21, 0xf3, 0x54},
+	}, {
+		"PLMNID/3digits",
+		ie.NewPLMNID("123", "456"),
+		[]byte{0x78, 0x00, 0x03, 0x00, 0x21, 0x63, 0x54},
+	}, {
+		"PortNumber",
+		ie.NewPortNumber(2123),
+		[]byte{0x7e, 0x00, 0x02, 0x00, 0x08, 0x4b},
+	}, {
+		"APNRestriction",
+		ie.NewAPNRestriction(gtpv2.APNRestrictionPublic1),
+		[]byte{0x7f, 0x00, 0x01, 0x00, 0x01},
+	}, {
+		"SelectionMode",
+		ie.NewSelectionMode(gtpv2.SelectionModeMSProvidedAPNSubscriptionNotVerified),
+		[]byte{0x80, 0x00, 0x01, 0x00, 0x01},
+	}, {
+		"FullyQualifiedCSID/v4",
+		ie.NewFullyQualifiedCSID("1.1.1.1", 1),
+		[]byte{0x84, 0x00, 0x07, 0x00, 0x01, 0x01, 0x01, 0x01, 0x01, 0x00, 0x01},
+	}, {
+		"FullyQualifiedCSID/v4/multiCSIDs",
+		ie.NewFullyQualifiedCSID("1.1.1.1", 1, 2),
+		[]byte{0x84, 0x00, 0x09, 0x00, 0x02, 0x01, 0x01, 0x01, 0x01, 0x00, 0x01, 0x00, 0x02},
+	}, {
+		"FullyQualifiedCSID/v6",
+		ie.NewFullyQualifiedCSID("2001::1", 1),
+		[]byte{0x84, 0x00, 0x13, 0x00, 0x11, 0x20, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x01},
+	}, {
+		"FullyQualifiedCSID/other",
+		ie.NewFullyQualifiedCSID("12304501", 1),
+		[]byte{0x84, 0x00, 0x07, 0x00, 0x21, 0x12, 0x30, 0x45, 0x01, 0x00, 0x01},
+	}, {
+		"NodeType",
+		ie.NewNodeType(gtpv2.NodeTypeMME),
+		[]byte{0x87, 0x00, 0x01, 0x00, 0x01},
+	}, {
+		"FullyQualifiedDomainName",
+		ie.NewFullyQualifiedDomainName("some-fqdn.example"),
+		[]byte{0x88, 0x00, 0x12, 0x00, 0x09, 0x73, 0x6f, 0x6d, 0x65, 0x2d, 0x66, 0x71, 0x64, 0x6e, 0x07, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65},
+	}, {
+		"RFSPIndex",
+		ie.NewRFSPIndex(1),
+		[]byte{0x90, 0x00, 0x01, 0x00, 0x01},
+	}, {
+		"UserCSGInformation",
+		ie.NewUserCSGInformation("123", "45", 0x00ffffff, gtpv2.AccessModeHybrid, 0, gtpv2.CMICSG),
+		[]byte{0x91, 0x00, 0x08, 0x00, 0x21, 0xf3, 0x54, 0x00, 0xff, 0xff, 0xff, 0x41},
+	}, {
+		"CSGID",
+		ie.NewCSGID(0x00ffffff),
+		[]byte{0x93, 0x00, 0x04, 0x00, 0x00, 0xff, 0xff, 0xff},
+	}, {
+		"CSGMembershipIndication",
+		ie.NewCSGMembershipIndication(gtpv2.CMICSG),
+		[]byte{0x94, 0x00, 0x01, 0x00, 0x01},
+	}, {
+		"ServiceIndicator",
+		ie.NewServiceIndicator(gtpv2.ServiceIndCSCall),
+		[]byte{0x95, 0x00, 0x01, 0x00, 0x01},
+	}, {
+		"DetachType",
+		ie.NewDetachType(gtpv2.DetachTypePS),
+		[]byte{0x96, 0x00, 0x01, 0x00, 0x01},
+	}, {
+		"LocalDistinguishedName",
+		ie.NewLocalDistinguishedName("some-name"),
+		[]byte{0x97, 0x00, 0x09, 0x00, 0x73, 0x6f, 0x6d, 0x65, 0x2d, 0x6e, 0x61, 0x6d, 0x65},
+	}, {
+		"NodeFeatures",
+		ie.NewNodeFeatures(0x01),
+		[]byte{0x98, 0x00, 0x01, 0x00, 0x01},
+	}, {
+		"AllocationRetensionPriority",
+		ie.NewAllocationRetensionPriority(1, 2, 1),
+		[]byte{0x9b, 0x00, 0x01, 0x00, 0x49},
+	}, {
+		"ULITimestamp",
+		ie.NewULITimestamp(time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)),
+		[]byte{0xaa, 0x00, 0x04, 0x00, 0xdf, 0xd5, 0x2c, 0x00},
+	}, {
+		"MBMSFlags",
+		ie.NewMBMSFlags(1, 1),
+		[]byte{0xab, 0x00, 0x01, 0x00, 0x03},
+	}, {
+		"RANNASCause",
+		ie.NewRANNASCause(gtpv2.ProtoTypeS1APCause, gtpv2.CauseTypeNAS, []byte{0x01}),
+		[]byte{0xac, 0x00, 0x02, 0x00, 0x12, 0x01},
+	}, {
+		"OverloadControlInformation",
+		ie.NewOverloadControlInformation(10, 50, 30*time.Second, "apn"),
+		[]byte{
+			0xb4, 0x00, 0x1a, 0x00,
+			0xb7, 0x00, 0x04, 0x00, 0x00, 0x00, 0x00, 0x0a,
+			0xb6, 0x00, 0x01, 0x00, 0x32,
+			0x9c, 0x00, 0x01, 0x00, 0x0f,
+			0x47, 0x00, 0x04, 0x00, 0x03, 0x61, 0x70, 0x6e,
+		},
+	}, {
+		"SignallingPriorityIndication",
+		ie.NewSignallingPriorityIndication(0x01),
+		[]byte{0x9d, 0x00, 0x01, 0x00, 0x01},
+	}, {
+		"ExtendedProtocolConfigurationOptions",
+		ie.NewExtendedProtocolConfigurationOptions([]byte{0x80, 0x00, 0x0d, 0x00}),
+		[]byte{0xc5, 0x00, 0x04, 0x00, 0x80, 0x00, 0x0d, 0x00},
+	}, {
+		"ServingPLMNRateControl",
+		ie.NewServingPLMNRateControl(10, 20),
+		[]byte{0xc6, 0x00, 0x04, 0x00, 0x00, 0x0a, 0x00, 0x14},
+	}, {
+		"Counter",
+		ie.NewCounter(0xdeadbeef, 1),
+		[]byte{0xc7, 0x00, 0x05, 0x00, 0xde, 0xad, 0xbe, 0xef, 0x01},
+	}, {
+		"MappedUEUsageType",
+		ie.NewMappedUEUsageType(0x1234),
+		[]byte{0xc8, 0x00, 0x02, 0x00, 0x12, 0x34},
+	}, {
+		"SGiPtPTunnelAddress",
+		ie.NewSGiPtPTunnelAddress("10.0.0.1", "", 8080),
+		[]byte{0xd5, 0x00, 0x07, 0x00, 0x05, 0x0a, 0x00, 0x00, 0x01, 0x1f, 0x90},
+	}, {
+		"PGWChangeInfo",
+		ie.NewPGWChangeInfo(
+			ie.NewPGWFQDN("set1"),
+			ie.NewIPAddress("10.0.0.2"),
+		),
+		[]byte{
+			0xd6, 0x00, 0x11, 0x00,
+			0xd7, 0x00, 0x05, 0x00, 0x04, 0x73, 0x65, 0x74, 0x31,
+			0x4a, 0x00, 0x04, 0x00, 0x0a, 0x00, 0x00, 0x02,
+		},
+	}, {
+		"PGWFQDN",
+		ie.NewPGWFQDN("pgw.example"),
+		[]byte{0xd7, 0x00, 0x0c, 0x00, 0x03, 0x70, 0x67, 0x77, 0x07, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65},
+	}, {
+		"PrivateExtension",
+		ie.NewPrivateExtension(10415, []byte{0xde, 0xad, 0xbe, 0xef}),
+		[]byte{0xff, 0x00, 0x06, 0x00, 0x28, 0xaf, 0xde, 0xad, 0xbe, 0xef},
+	},
+}
 
-	for _, c := range cases {
+func TestIEs(t *testing.T) {
+	for _, c := range testIEs {
 		t.Run("serialize/"+c.description, func(t *testing.T) {
 			got, err := c.structured.Marshal()
 			if err != nil {
@@ -439,6 +517,18 @@ func TestIEs(t *testing.T) {
 			}
 		})
 
+		t.Run("append/"+c.description, func(t *testing.T) {
+			prefix := []byte{0xde, 0xad}
+			got, err := c.structured.AppendTo(prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(got, append(prefix, c.serialized...)); diff != "" {
+				t.Error(diff)
+			}
+		})
+
 		t.Run("decode/"+c.description, func(t *testing.T) {
 			got, err := ie.Parse(c.serialized)
 			if err != nil {
@@ -450,5 +540,344 @@ func TestIEs(t *testing.T) {
 				t.Error(diff)
 			}
 		})
+
+		t.Run("clone/"+c.description, func(t *testing.T) {
+			parsed, err := ie.Parse(c.serialized)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := parsed.Clone()
+			opt := cmp.AllowUnexported(*got, *c.structured)
+			if diff := cmp.Diff(got, c.structured, opt); diff != "" {
+				t.Error(diff)
+			}
+
+			// modifying the copy should not affect the original.
+			for n := range got.Payload {
+				got.Payload[n] = ^got.Payload[n]
+			}
+			if diff := cmp.Diff(parsed, c.structured, opt); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestParseNested(t *testing.T) {
+	nest := func(n int) []byte {
+		i := ie.NewEPSBearerID(5)
+		for j := 0; j < n; j++ {
+			i = ie.NewBearerContext(i)
+		}
+		b, err := i.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	if _, err := ie.Parse(nest(8)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := ie.Parse(nest(9)); !errors.Is(err, ie.ErrTooDeeplyNested) {
+		t.Errorf("got %v, want %v", err, ie.ErrTooDeeplyNested)
+	}
+}
+
+func TestGenerated(t *testing.T) {
+	i := ie.NewCSGInformationReportingAction(0x05)
+	if err := i.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if !i.HasUCICSG() || i.HasUCISHC() || !i.HasUCIUHC() {
+		t.Errorf("unexpected flags in %x", i.Payload)
+	}
+
+	if got, want := ie.NewServingPLMNRateControl(10, 20).ValueString(), "{Uplink Rate Limit: 10, Downlink Rate Limit: 20}"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	malformed := ie.New(ie.Counter, 0, []byte{0xde, 0xad})
+	if err := malformed.Validate(); err == nil {
+		t.Error("expected error for malformed Counter")
+	}
+	if got, want := malformed.ValueString(), "dead"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if _, err := malformed.CounterValue(); err == nil {
+		t.Error("expected error for malformed Counter")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	t.Run("InvalidLength", func(t *testing.T) {
+		_, err := ie.Parse([]byte{0x03, 0x00, 0x05, 0x00, 0x80})
+
+		var lerr *ie.InvalidLengthError
+		if !errors.As(err, &lerr) {
+			t.Fatalf("got %v, want *InvalidLengthError", err)
+		}
+		if got, want := *lerr, (ie.InvalidLengthError{Type: ie.Recovery, Got: 1, Want: 5}); got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+		if !errors.Is(err, ie.ErrInvalidLength) {
+			t.Errorf("%v does not match ErrInvalidLength", err)
+		}
+	})
+
+	t.Run("IENotFound", func(t *testing.T) {
+		_, err := ie.NewBearerContext(ie.NewEPSBearerID(5)).FindByType(ie.Cause, 0)
+
+		var nerr *ie.IENotFoundError
+		if !errors.As(err, &nerr) {
+			t.Fatalf("got %v, want *IENotFoundError", err)
+		}
+		if nerr.Type != ie.Cause {
+			t.Errorf("wrong Type: got %d, want %d", nerr.Type, ie.Cause)
+		}
+		if !errors.Is(err, ie.ErrIENotFound) {
+			t.Errorf("%v does not match ErrIENotFound", err)
+		}
+	})
+
+	t.Run("InvalidType", func(t *testing.T) {
+		_, err := ie.NewRecovery(1).FindByType(ie.Cause, 0)
+		if !errors.Is(err, ie.ErrInvalidType) {
+			t.Errorf("%v does not match ErrInvalidType", err)
+		}
+	})
+}
+
+func TestFindAllByType(t *testing.T) {
+	brCtx := ie.NewBearerContext(
+		ie.NewEPSBearerID(5),
+		ie.NewFullyQualifiedTEID(gtpv2.IFTypeS1UeNodeBGTPU, 0x11111111, "1.1.1.1", ""),
+		ie.NewFullyQualifiedTEID(gtpv2.IFTypeS5S8SGWGTPU, 0x22222222, "1.1.1.2", "").WithInstance(1),
+		ie.NewFullyQualifiedTEID(gtpv2.IFTypeS1UeNodeBGTPU, 0x33333333, "1.1.1.3", ""),
+	)
+
+	found, err := brCtx.FindAllByType(ie.FullyQualifiedTEID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var teids []uint32
+	for _, i := range found {
+		teids = append(teids, i.MustTEID())
+	}
+	if diff := cmp.Diff(teids, []uint32{0x11111111, 0x33333333}); diff != "" {
+		t.Error(diff)
+	}
+
+	if _, err := brCtx.FindAllByType(ie.FullyQualifiedTEID, 2); !errors.Is(err, ie.ErrIENotFound) {
+		t.Errorf("got %v, want ErrIENotFound", err)
+	}
+}
+
+func TestFindByEBI(t *testing.T) {
+	ies := []*ie.IE{
+		ie.NewBearerContext(ie.NewEPSBearerID(5), ie.NewCause(gtpv2.CauseRequestAccepted, 0, 0, 0, nil)),
+		nil,
+		ie.NewBearerContext(ie.NewEPSBearerID(6), ie.NewCause(gtpv2.CauseNoResourcesAvailable, 0, 0, 0, nil)),
+	}
+
+	brCtx, err := ie.FindByEBI(ies, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if brCtx != ies[2] {
+		t.Errorf("got %v, want %v", brCtx, ies[2])
+	}
+
+	if _, err := ie.FindByEBI(ies, 7); !errors.Is(err, ie.ErrIENotFound) {
+		t.Errorf("got %v, want ErrIENotFound", err)
+	}
+}
+
+func TestAPNIdentifiers(t *testing.T) {
+	cases := []struct {
+		description string
+		apn         *ie.IE
+		ni          string
+		mcc, mnc    string
+	}{
+		{"NIOnly", ie.NewAccessPointName("some.apn.example"), "some.apn.example", "", ""},
+		{"WithOI", ie.NewAccessPointNameWithOI("some.apn", "123", "45"), "some.apn", "123", "045"},
+		{"WithOI/3-digit", ie.NewAccessPointNameWithOI("internet", "123", "456"), "internet", "123", "456"},
+		{"WithOI/UpperCase", ie.NewAccessPointName("internet.MNC045.MCC123.GPRS"), "internet", "123", "045"},
+		{"NotOI", ie.NewAccessPointName("internet.mnc45.mcc123.gprs"), "internet.mnc45.mcc123.gprs", "", ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if got := c.apn.MustAPNNetworkIdentifier(); got != c.ni {
+				t.Errorf("wrong NI: got %q, want %q", got, c.ni)
+			}
+
+			mcc, mnc, err := c.apn.APNOperatorIdentifier()
+			if c.mcc == "" {
+				if !errors.Is(err, ie.ErrIENotFound) {
+					t.Errorf("got %v, want ErrIENotFound", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mcc != c.mcc || mnc != c.mnc {
+				t.Errorf("wrong OI: got %s-%s, want %s-%s", mcc, mnc, c.mcc, c.mnc)
+			}
+		})
+	}
+}
+
+func TestPacketFilterComponents(t *testing.T) {
+	cases := []struct {
+		description string
+		components  *ie.PacketFilterComponents
+	}{
+		{
+			"IPv4",
+			&ie.PacketFilterComponents{
+				RemoteAddress:  &net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)},
+				LocalAddress:   &net.IPNet{IP: net.IP{192, 168, 0, 1}, Mask: net.CIDRMask(32, 32)},
+				HasProtocol:    true,
+				Protocol:       6,
+				LocalPortLow:   1000,
+				LocalPortHigh:  2000,
+				RemotePortLow:  443,
+				RemotePortHigh: 443,
+			},
+		}, {
+			"IPv6",
+			&ie.PacketFilterComponents{
+				RemoteAddress:             &net.IPNet{IP: net.ParseIP("2001:db8::"), Mask: net.CIDRMask(32, 128)},
+				HasSecurityParameterIndex: true,
+				SecurityParameterIndex:    0xdeadbeef,
+				HasTypeOfService:          true,
+				TypeOfService:             0xb8,
+				TypeOfServiceMask:         0xfc,
+				HasFlowLabel:              true,
+				FlowLabel:                 0x12345,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			tft, err := ie.NewBearerTFT(ie.TFTOpCreateNewTFT, []*ie.TFTPacketFilter{
+				ie.NewTFTPacketFilter(1, ie.TFTPFUplinkOnly, 0xff, c.components),
+			}, nil).TrafficFlowTemplate()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := tft.PacketFilters[0].Components()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(got, c.components); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestAllocationRetensionPriority(t *testing.T) {
+	for _, i := range []*ie.IE{
+		ie.NewAllocationRetensionPriority(1, 2, 1),
+		ie.NewBearerQoS(1, 2, 1, 9, 0, 0, 0, 0),
+	} {
+		pl, err := i.PriorityLevel()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pl != 2 || !i.HasPCI() || !i.HasPVI() {
+			t.Errorf("wrong ARP in type %d: PL=%d, PCI=%v, PVI=%v", i.Type, pl, i.HasPCI(), i.HasPVI())
+		}
+	}
+}
+
+func TestPGWChangeInfo(t *testing.T) {
+	i := ie.NewPGWChangeInfo(
+		ie.NewPGWFQDN("topon.s5s8.pgw-set1.epc.mnc001.mcc001.3gppnetwork.org"),
+		ie.NewPGWFQDN("topon.s5s8.pgw2.epc.mnc001.mcc001.3gppnetwork.org").WithInstance(1),
+	)
+	b, err := i.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ie.Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := parsed.MustPGWSetFQDN(), "topon.s5s8.pgw-set1.epc.mnc001.mcc001.3gppnetwork.org"; got != want {
+		t.Errorf("wrong PGW Set FQDN: got %s, want %s", got, want)
+	}
+	if _, err := ie.NewPGWChangeInfo().PGWSetFQDN(); !errors.Is(err, ie.ErrIENotFound) {
+		t.Errorf("want ErrIENotFound, got %v", err)
+	}
+}
+
+func TestSGiPtPTunnelAddress(t *testing.T) {
+	f, err := ie.NewSGiPtPTunnelAddress("10.0.0.1", "2001:db8::1", 0).SGiPtPTunnelAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.HasIPv4() || !f.HasIPv6() || f.HasPort() {
+		t.Errorf("wrong flags: %#x", f.Flags)
+	}
+	if got := f.IPv6Address.String(); got != "2001:db8::1" {
+		t.Errorf("wrong IPv6 address: %s", got)
+	}
+}
+
+func TestOverloadControlInformation(t *testing.T) {
+	b, err := ie.NewOverloadControlInformation(1, 20, 5*time.Minute, "apn1.example", "apn2.example").Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ie.Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := parsed.OverloadControlInformation()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &ie.OverloadControlInformationFields{
+		SequenceNumber:   1,
+		Metric:           20,
+		PeriodOfValidity: 5 * time.Minute,
+		APNs:             []string{"apn1.example", "apn2.example"},
+	}
+	if diff := cmp.Diff(want, f); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestEPCTimerDuration(t *testing.T) {
+	cases := []struct {
+		given time.Duration
+		want  time.Duration
+		value uint8
+	}{
+		{0, 0, 0x00},
+		{61 * time.Second, 62 * time.Second, 0x1f},
+		{90 * time.Second, 2 * time.Minute, 0x22},
+		{3 * time.Hour, 3 * time.Hour, 0x52},
+		{400 * time.Hour, ie.EPCTimerInfinite, 0xe0},
+	}
+
+	for _, c := range cases {
+		i := ie.NewEPCTimerFromDuration(c.given)
+		if got := i.MustEPCTimer(); got != c.value {
+			t.Errorf("wrong value for %s: got %#x, want %#x", c.given, got, c.value)
+		}
+		if got := i.MustEPCTimerDuration(); got != c.want {
+			t.Errorf("wrong duration for %s: got %s, want %s", c.given, got, c.want)
+		}
 	}
 }
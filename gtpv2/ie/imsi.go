@@ -29,7 +29,7 @@ func (i *IE) IMSI() (string, error) {
 		return "", io.ErrUnexpectedEOF
 	}
 
-	return utils.SwappedBytesToStr(i.Payload, true), nil
+	return utils.DecodeTBCD(i.Payload)
 }
 
 // MustIMSI returns IMSI in string, ignoring errors.
@@ -0,0 +1,340 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Command iegen generates the constructors, getters and helpers of GTPv2 IEs
+// from the description of IEs in JSON.
+//
+// Each IE in the description consists of the name of the IE type constant
+// and the fields in the order they appear in the payload. The types of the
+// fields supported are uint8, uint16, uint32, uint64, string and bytes,
+// and string or bytes can only be the last field as they consume the rest
+// of the payload. The bits in uint8 fields can be named with flags, which
+// generates HasXxx methods.
+//
+// Usage:
+//
+//	go run ./internal/iegen -i ies.json -o generated.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"strings"
+	"text/template"
+)
+
+// IE is the description of an IE.
+type IE struct {
+	Name   string   `json:"name"`
+	Fields []*Field `json:"fields"`
+}
+
+// Field is the description of a field in an IE.
+type Field struct {
+	Name   string  `json:"name"`
+	Type   string  `json:"type"`
+	Getter string  `json:"getter"`
+	Label  string  `json:"label"`
+	Flags  []*Flag `json:"flags"`
+
+	// set by prepare.
+	Offset int
+	Size   int
+}
+
+// Flag is the description of a bit in a uint8 field.
+type Flag struct {
+	Name string `json:"name"`
+	Bit  int    `json:"bit"`
+}
+
+var sizes = map[string]int{
+	"uint8":  1,
+	"uint16": 2,
+	"uint32": 4,
+	"uint64": 8,
+	"string": 0,
+	"bytes":  0,
+}
+
+func main() {
+	in := flag.String("i", "ies.json", "path to the description of IEs")
+	out := flag.String("o", "generated.go", "path to the file to be generated")
+	flag.Parse()
+
+	b, err := ioutil.ReadFile(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var ies []*IE
+	if err := json.Unmarshal(b, &ies); err != nil {
+		log.Fatalf("failed to decode %s: %v", *in, err)
+	}
+
+	src, err := generate(*in, ies)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func generate(in string, ies []*IE) ([]byte, error) {
+	for _, i := range ies {
+		if err := prepare(i); err != nil {
+			return nil, fmt.Errorf("%s: %w", i.Name, err)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, struct {
+		Source string
+		IEs    []*IE
+	}{in, ies}); err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
+
+func prepare(i *IE) error {
+	if i.Name == "" {
+		return errors.New("name is empty")
+	}
+	if len(i.Fields) == 0 {
+		return errors.New("no fields")
+	}
+
+	offset := 0
+	for n, f := range i.Fields {
+		size, ok := sizes[f.Type]
+		if !ok {
+			return fmt.Errorf("unsupported type %q in field %s", f.Type, f.Name)
+		}
+		if size == 0 && n != len(i.Fields)-1 {
+			return fmt.Errorf("field %s of type %s must be the last one", f.Name, f.Type)
+		}
+		if len(f.Flags) > 0 && f.Type != "uint8" {
+			return fmt.Errorf("field %s has flags but is not uint8", f.Name)
+		}
+		for _, fl := range f.Flags {
+			if fl.Bit < 1 || fl.Bit > 8 {
+				return fmt.Errorf("bit %d of flag %s is out of range", fl.Bit, fl.Name)
+			}
+		}
+
+		if f.Getter == "" {
+			if len(i.Fields) != 1 {
+				return fmt.Errorf("getter of field %s must be given if IE has multiple fields", f.Name)
+			}
+			f.Getter = i.Name
+		}
+		if f.Label == "" {
+			f.Label = "Value"
+		}
+
+		f.Offset = offset
+		f.Size = size
+		offset += size
+	}
+	return nil
+}
+
+var funcs = template.FuncMap{
+	"params": func(i *IE) string {
+		var p []string
+		for _, f := range i.Fields {
+			p = append(p, f.Name+" "+goType(f.Type))
+		}
+		return strings.Join(p, ", ")
+	},
+	"goType": goType,
+	"zero": func(typ string) string {
+		switch typ {
+		case "string":
+			return `""`
+		case "bytes":
+			return "nil"
+		default:
+			return "0"
+		}
+	},
+	"fixedLen": func(i *IE) int {
+		l := 0
+		for _, f := range i.Fields {
+			l += f.Size
+		}
+		return l
+	},
+	"last": func(i *IE) *Field {
+		return i.Fields[len(i.Fields)-1]
+	},
+	"isVariable": func(f *Field) bool {
+		return f.Size == 0
+	},
+	"offsetEnd": func(f *Field) int {
+		return f.Offset + f.Size
+	},
+	"put": func(f *Field) string {
+		switch f.Type {
+		case "uint8":
+			return fmt.Sprintf("i.Payload[%d] = %s", f.Offset, f.Name)
+		case "uint16", "uint32", "uint64":
+			return fmt.Sprintf("binary.BigEndian.Put%s(i.Payload[%d:%d], %s)",
+				strings.Title(f.Type), f.Offset, f.Offset+f.Size, f.Name)
+		default:
+			return fmt.Sprintf("copy(i.Payload[%d:], %s)", f.Offset, f.Name)
+		}
+	},
+	"get": func(f *Field) string {
+		switch f.Type {
+		case "uint8":
+			return fmt.Sprintf("i.Payload[%d]", f.Offset)
+		case "uint16", "uint32", "uint64":
+			return fmt.Sprintf("binary.BigEndian.%s(i.Payload[%d:%d])",
+				strings.Title(f.Type), f.Offset, f.Offset+f.Size)
+		case "string":
+			return fmt.Sprintf("string(%s)", rest(f.Offset))
+		default:
+			return rest(f.Offset)
+		}
+	},
+	"bitHelper": func(bit int) string {
+		return fmt.Sprintf("has%d%sBit", bit, map[int]string{1: "st", 2: "nd", 3: "rd"}[bit]+
+			map[bool]string{true: "th"}[bit > 3])
+	},
+	"verb": func(f *Field) string {
+		switch f.Type {
+		case "bytes":
+			return "%x"
+		case "string":
+			return "%s"
+		}
+		if len(f.Flags) > 0 {
+			return "0x%02x"
+		}
+		return "%d"
+	},
+	"needsBinary": func(ies []*IE) bool {
+		for _, i := range ies {
+			for _, f := range i.Fields {
+				if f.Size > 1 {
+					return true
+				}
+			}
+		}
+		return false
+	},
+}
+
+func rest(offset int) string {
+	if offset == 0 {
+		return "i.Payload"
+	}
+	return fmt.Sprintf("i.Payload[%d:]", offset)
+}
+
+func goType(typ string) string {
+	if typ == "bytes" {
+		return "[]byte"
+	}
+	return typ
+}
+
+var tmpl = template.Must(template.New("generated").Funcs(funcs).Parse(`// Code generated by iegen from {{.Source}}; DO NOT EDIT.
+
+package ie
+
+import (
+{{- if needsBinary .IEs}}
+	"encoding/binary"
+{{- end}}
+	"fmt"
+	"io"
+)
+
+// payloadMinLengths is the minimum length of payload of the IEs generated,
+// which is checked in Validate.
+var payloadMinLengths = map[uint8]int{
+{{- range .IEs}}
+	{{.Name}}: {{fixedLen .}},
+{{- end}}
+}
+
+// valueFormatters is used by ValueString to format the fields of the IEs generated.
+var valueFormatters = map[uint8]func(i *IE) string{
+{{- range .IEs}}
+	{{.Name}}: func(i *IE) string {
+		return fmt.Sprintf("{
+		{{- range $n, $f := .Fields}}{{if $n}}, {{end}}{{$f.Label}}: {{verb $f}}{{range $f.Flags}}, {{.Name}}: %v{{end}}{{end}}}",
+		{{- range .Fields}}
+			i.Must{{.Getter}}(),{{range .Flags}} i.Has{{.Name}}(),{{end}}
+		{{- end}}
+		)
+	},
+{{- end}}
+}
+{{range $ie := .IEs}}
+// New{{.Name}} creates a new {{.Name}} IE.
+func New{{.Name}}({{params .}}) *IE {
+	{{- $last := last .}}
+	{{- if eq (len .Fields) 1 | and (isVariable $last)}}
+	return New({{.Name}}, 0x00, {{if eq $last.Type "string"}}[]byte({{$last.Name}}){{else}}{{$last.Name}}{{end}})
+	{{- else}}
+	i := New({{.Name}}, 0x00, make([]byte, {{fixedLen .}}{{if isVariable $last}}+len({{$last.Name}}){{end}}))
+	{{- range .Fields}}
+	{{put .}}
+	{{- end}}
+	return i
+	{{- end}}
+}
+{{range .Fields}}
+// {{.Getter}} returns {{.Getter}} in {{goType .Type}} if the type of IE matches.
+func (i *IE) {{.Getter}}() ({{goType .Type}}, error) {
+	if i.Type != {{$ie.Name}} {
+		return {{zero .Type}}, &InvalidTypeError{Type: i.Type}
+	}
+	{{- if offsetEnd .}}
+	if len(i.Payload) < {{offsetEnd .}} {
+		return {{zero .Type}}, io.ErrUnexpectedEOF
+	}
+	{{- end}}
+
+	return {{get .}}, nil
+}
+
+// Must{{.Getter}} returns {{.Getter}} in {{goType .Type}}, ignoring errors.
+// This should only be used if it is assured to have the value.
+func (i *IE) Must{{.Getter}}() {{goType .Type}} {
+	v, _ := i.{{.Getter}}()
+	return v
+}
+{{- $g := .Getter}}
+{{- range .Flags}}
+
+// Has{{.Name}} reports whether an IE has {{.Name}} bit.
+func (i *IE) Has{{.Name}}() bool {
+	v, err := i.{{$g}}()
+	if err != nil {
+		return false
+	}
+
+	return {{bitHelper .Bit}}(v)
+}
+{{- end}}
+{{end}}
+{{- end}}`))
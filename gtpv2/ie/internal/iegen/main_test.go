@@ -0,0 +1,77 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+)
+
+// TestUpToDate checks if generated.go is regenerated after ies.json is modified.
+func TestUpToDate(t *testing.T) {
+	b, err := ioutil.ReadFile("../../ies.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ies []*IE
+	if err := json.Unmarshal(b, &ies); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := generate("ies.json", ies)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := ioutil.ReadFile("../../generated.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Error("generated.go is outdated. Run go generate in gtpv2/ie")
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	cases := []struct {
+		description string
+		ie          *IE
+	}{
+		{
+			"NoFields",
+			&IE{Name: "Foo"},
+		}, {
+			"UnknownType",
+			&IE{Name: "Foo", Fields: []*Field{{Name: "foo", Type: "uint128"}}},
+		}, {
+			"VariableNotLast",
+			&IE{Name: "Foo", Fields: []*Field{
+				{Name: "foo", Type: "bytes", Getter: "Foo"},
+				{Name: "bar", Type: "uint8", Getter: "Bar"},
+			}},
+		}, {
+			"FlagsInUint16",
+			&IE{Name: "Foo", Fields: []*Field{{Name: "foo", Type: "uint16", Flags: []*Flag{{Name: "F", Bit: 1}}}}},
+		}, {
+			"NoGetter",
+			&IE{Name: "Foo", Fields: []*Field{
+				{Name: "foo", Type: "uint8"},
+				{Name: "bar", Type: "uint8"},
+			}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if err := prepare(c.ie); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -6,7 +6,6 @@ package ie
 
 import (
 	"io"
-	"strings"
 
 	"github.com/wmnsk/go-gtp/utils"
 )
@@ -30,8 +29,7 @@ func (i *IE) MobileEquipmentIdentity() (string, error) {
 		return "", io.ErrUnexpectedEOF
 	}
 
-	str := utils.SwappedBytesToStr(i.Payload, false)
-	return strings.TrimSuffix(str, "f"), nil
+	return utils.DecodeTBCD(i.Payload)
 }
 
 // MustMobileEquipmentIdentity returns MobileEquipmentIdentity in string, ignoring errors.
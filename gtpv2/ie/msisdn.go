@@ -6,7 +6,6 @@ package ie
 
 import (
 	"io"
-	"strings"
 
 	"github.com/wmnsk/go-gtp/utils"
 )
@@ -29,8 +28,7 @@ func (i *IE) MSISDN() (string, error) {
 		return "", io.ErrUnexpectedEOF
 	}
 
-	str := utils.SwappedBytesToStr(i.Payload, false)
-	return strings.TrimSuffix(str, "f"), nil
+	return utils.DecodeTBCD(i.Payload)
 }
 
 // MustMSISDN returns MSISDN in string, ignoring errors.
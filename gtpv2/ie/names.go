@@ -0,0 +1,175 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+import "fmt"
+
+var typeNames = map[uint8]string{
+	IMSI:                                     "IMSI",
+	Cause:                                    "Cause",
+	Recovery:                                 "Recovery",
+	STNSR:                                    "STN-SR",
+	AccessPointName:                          "Access Point Name",
+	AggregateMaximumBitRate:                  "Aggregate Maximum Bit Rate",
+	EPSBearerID:                              "EPS Bearer ID",
+	IPAddress:                                "IP Address",
+	MobileEquipmentIdentity:                  "Mobile Equipment Identity",
+	MSISDN:                                   "MSISDN",
+	Indication:                               "Indication",
+	ProtocolConfigurationOptions:             "Protocol Configuration Options",
+	PDNAddressAllocation:                     "PDN Address Allocation",
+	BearerQoS:                                "Bearer QoS",
+	FlowQoS:                                  "Flow QoS",
+	RATType:                                  "RAT Type",
+	ServingNetwork:                           "Serving Network",
+	BearerTFT:                                "Bearer TFT",
+	TrafficAggregateDescription:              "Traffic Aggregate Description",
+	UserLocationInformation:                  "User Location Information",
+	FullyQualifiedTEID:                       "F-TEID",
+	TMSI:                                     "TMSI",
+	GlobalCNID:                               "Global CN ID",
+	S103PDNDataForwardingInfo:                "S103 PDN Data Forwarding Info",
+	S1UDataForwarding:                        "S1-U Data Forwarding",
+	DelayValue:                               "Delay Value",
+	BearerContext:                            "Bearer Context",
+	ChargingID:                               "Charging ID",
+	ChargingCharacteristics:                  "Charging Characteristics",
+	TraceInformation:                         "Trace Information",
+	BearerFlags:                              "Bearer Flags",
+	PDNType:                                  "PDN Type",
+	ProcedureTransactionID:                   "Procedure Transaction ID",
+	MMContextGSMKeyAndTriplets:               "MM Context GSM Key and Triplets",
+	MMContextUMTSKeyUsedCipherAndQuintuplets: "MM Context UMTS Key, Used Cipher and Quintuplets",
+	MMContextGSMKeyUsedCipherAndQuintuplets:  "MM Context GSM Key, Used Cipher and Quintuplets",
+	MMContextUMTSKeyAndQuintuplets:           "MM Context UMTS Key and Quintuplets",
+	MMContextEPSSecurityContextQuadrupletsAndQuintuplets: "MM Context EPS Security Context Quadruplets and Quintuplets",
+	MMContextUMTSKeyQuadrupletsAndQuintuplets:            "MM Context UMTS Key, Quadruplets and Quintuplets",
+	PDNConnection:                          "PDN Connection",
+	PDUNumbers:                             "PDU Numbers",
+	PacketTMSI:                             "P-TMSI",
+	PTMSISignature:                         "P-TMSI Signature",
+	HopCounter:                             "Hop Counter",
+	UETimeZone:                             "UE Time Zone",
+	TraceReference:                         "Trace Reference",
+	CompleteRequestMessage:                 "Complete Request Message",
+	GUTI:                                   "GUTI",
+	FContainer:                             "F-Container",
+	FCause:                                 "F-Cause",
+	PLMNID:                                 "PLMN ID",
+	TargetIdentification:                   "Target Identification",
+	PacketFlowID:                           "Packet Flow ID",
+	RABContext:                             "RAB Context",
+	SourceRNCPDCPContextInfo:               "Source RNC PDCP Context Info",
+	PortNumber:                             "Port Number",
+	APNRestriction:                         "APN Restriction",
+	SelectionMode:                          "Selection Mode",
+	SourceIdentification:                   "Source Identification",
+	Reserved:                               "Reserved",
+	ChangeReportingAction:                  "Change Reporting Action",
+	FullyQualifiedCSID:                     "FQ-CSID",
+	ChannelNeeded:                          "Channel Needed",
+	EMLPPPriority:                          "eMLPP Priority",
+	NodeType:                               "Node Type",
+	FullyQualifiedDomainName:               "FQDN",
+	TI:                                     "TI",
+	MBMSSessionDuration:                    "MBMS Session Duration",
+	MBMSServiceArea:                        "MBMS Service Area",
+	MBMSSessionIdentifier:                  "MBMS Session Identifier",
+	MBMSFlowIdentifier:                     "MBMS Flow Identifier",
+	MBMSIPMulticastDistribution:            "MBMS IP Multicast Distribution",
+	MBMSDistributionAcknowledge:            "MBMS Distribution Acknowledge",
+	RFSPIndex:                              "RFSP Index",
+	UserCSGInformation:                     "User CSG Information",
+	CSGInformationReportingAction:          "CSG Information Reporting Action",
+	CSGID:                                  "CSG ID",
+	CSGMembershipIndication:                "CSG Membership Indication",
+	ServiceIndicator:                       "Service Indicator",
+	DetachType:                             "Detach Type",
+	LocalDistinguishedName:                 "Local Distinguished Name",
+	NodeFeatures:                           "Node Features",
+	MBMSTimeToDataTransfer:                 "MBMS Time to Data Transfer",
+	Throttling:                             "Throttling",
+	AllocationRetensionPriority:            "Allocation/Retention Priority",
+	EPCTimer:                               "EPC Timer",
+	SignallingPriorityIndication:           "Signalling Priority Indication",
+	TMGI:                                   "TMGI",
+	AdditionalMMContextForSRVCC:            "Additional MM Context for SRVCC",
+	AdditionalFlagsForSRVCC:                "Additional Flags for SRVCC",
+	MDTConfiguration:                       "MDT Configuration",
+	AdditionalProtocolConfigurationOptions: "Additional Protocol Configuration Options",
+	AbsoluteTimeofMBMSDataTransfer:         "Absolute Time of MBMS Data Transfer",
+	HeNBInformationReporting:               "HeNB Information Reporting",
+	IPv4ConfigurationParameters:            "IPv4 Configuration Parameters",
+	ChangeToReportFlags:                    "Change to Report Flags",
+	ActionIndication:                       "Action Indication",
+	TWANIdentifier:                         "TWAN Identifier",
+	ULITimestamp:                           "ULI Timestamp",
+	MBMSFlags:                              "MBMS Flags",
+	RANNASCause:                            "RAN/NAS Cause",
+	CNOperatorSelectionEntity:              "CN Operator Selection Entity",
+	TrustedWLANModeIndication:              "Trusted WLAN Mode Indication",
+	NodeNumber:                             "Node Number",
+	NodeIdentifier:                         "Node Identifier",
+	PresenceReportingAreaAction:            "Presence Reporting Area Action",
+	PresenceReportingAreaInformation:       "Presence Reporting Area Information",
+	TWANIdentifierTimestamp:                "TWAN Identifier Timestamp",
+	OverloadControlInformation:             "Overload Control Information",
+	LoadControlInformation:                 "Load Control Information",
+	Metric:                                 "Metric",
+	SequenceNumber:                         "Sequence Number",
+	APNAndRelativeCapacity:                 "APN and Relative Capacity",
+	WLANOffloadabilityIndication:           "WLAN Offloadability Indication",
+	PagingAndServiceInformation:            "Paging and Service Information",
+	IntegerNumber:                          "Integer Number",
+	MillisecondTimeStamp:                   "Millisecond Time Stamp",
+	MonitoringEventInformation:             "Monitoring Event Information",
+	ECGIList:                               "ECGI List",
+	RemoteUEContext:                        "Remote UE Context",
+	RemoteUserID:                           "Remote User ID",
+	RemoteUEIPinformation:                  "Remote UE IP Information",
+	CIoTOptimizationsSupportIndication:     "CIoT Optimizations Support Indication",
+	SCEFPDNConnection:                      "SCEF PDN Connection",
+	HeaderCompressionConfiguration:         "Header Compression Configuration",
+	ExtendedProtocolConfigurationOptions:   "Extended Protocol Configuration Options",
+	ServingPLMNRateControl:                 "Serving PLMN Rate Control",
+	Counter:                                "Counter",
+	MappedUEUsageType:                      "Mapped UE Usage Type",
+	SecondaryRATUsageDataReport:            "Secondary RAT Usage Data Report",
+	UPFunctionSelectionIndicationFlags:     "UP Function Selection Indication Flags",
+	MaximumPacketLossRate:                  "Maximum Packet Loss Rate",
+	APNRateControlStatus:                   "APN Rate Control Status",
+	ExtendedTraceInformation:               "Extended Trace Information",
+	MonitoringEventExtensionInformation:    "Monitoring Event Extension Information",
+	AdditionalRRMPolicyIndex:               "Additional RRM Policy Index",
+	V2XContext:                             "V2X Context",
+	PC5QoSParameters:                       "PC5 QoS Parameters",
+	ServicesAuthorized:                     "Services Authorized",
+	BitRate:                                "Bit Rate",
+	PC5QoSFlow:                             "PC5 QoS Flow",
+	SGiPtPTunnelAddress:                    "SGi PtP Tunnel Address",
+	PGWChangeInfo:                          "PGW Change Info",
+	PGWFQDN:                                "PGW FQDN",
+	SpecialIETypeForIETypeExtension:        "Special IE Type for IE Type Extension",
+	PrivateExtension:                       "Private Extension",
+}
+
+// TypeName returns the name of the IE type given.
+func TypeName(t uint8) string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("Unknown(%d)", t)
+}
+
+// IsKnownType reports whether the IE type given is defined in TS 29.274.
+func IsKnownType(t uint8) bool {
+	_, ok := typeNames[t]
+	return ok
+}
+
+// Name returns the name of the IE type.
+func (i *IE) Name() string {
+	return TypeName(i.Type)
+}
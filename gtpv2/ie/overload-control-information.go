@@ -0,0 +1,80 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+import (
+	"time"
+)
+
+// NewOverloadControlInformation creates a new OverloadControlInformation IE.
+//
+// validity is the Period of Validity, which is encoded as EPC Timer. The List of
+// Access Point Name is included only if apns are given, which means that the
+// overload information is for those APNs, not for the node.
+func NewOverloadControlInformation(seq uint32, metric uint8, validity time.Duration, apns ...string) *IE {
+	ies := []*IE{
+		NewSequenceNumber(seq),
+		NewMetric(metric),
+		NewEPCTimerFromDuration(validity),
+	}
+	for _, apn := range apns {
+		ies = append(ies, NewAccessPointName(apn))
+	}
+
+	return newGroupedIE(OverloadControlInformation, ies...)
+}
+
+// OverloadControlInformationFields is a set of fields in OverloadControlInformation IE.
+type OverloadControlInformationFields struct {
+	SequenceNumber   uint32
+	Metric           uint8 // Overload Reduction Metric, in percent
+	PeriodOfValidity time.Duration
+	APNs             []string
+}
+
+// OverloadControlInformation returns the values in OverloadControlInformation in
+// OverloadControlInformationFields if the type of IE matches.
+func (i *IE) OverloadControlInformation() (*OverloadControlInformationFields, error) {
+	if i.Type != OverloadControlInformation {
+		return nil, &InvalidTypeError{Type: i.Type}
+	}
+
+	f := &OverloadControlInformationFields{}
+
+	seq, err := i.FindByType(SequenceNumber, 0)
+	if err != nil {
+		return nil, err
+	}
+	if f.SequenceNumber, err = seq.SequenceNumber(); err != nil {
+		return nil, err
+	}
+
+	metric, err := i.FindByType(Metric, 0)
+	if err != nil {
+		return nil, err
+	}
+	if f.Metric, err = metric.Metric(); err != nil {
+		return nil, err
+	}
+
+	timer, err := i.FindByType(EPCTimer, 0)
+	if err != nil {
+		return nil, err
+	}
+	if f.PeriodOfValidity, err = timer.EPCTimerDuration(); err != nil {
+		return nil, err
+	}
+
+	apns, _ := i.FindAllByType(AccessPointName, 0)
+	for _, a := range apns {
+		apn, err := a.AccessPointName()
+		if err != nil {
+			return nil, err
+		}
+		f.APNs = append(f.APNs, apn)
+	}
+
+	return f, nil
+}
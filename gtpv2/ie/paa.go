@@ -28,7 +28,7 @@ func NewPDNAddressAllocation(addr string) *IE {
 //
 // If they cannot be converted as IPv4/IPv6, PDN Type will be Non-IP.
 func NewPDNAddressAllocationDual(v4addr, v6addr string) *IE {
-	return NewPDNAddressAllocationDualNetIP(net.ParseIP(v4addr), net.ParseIP(v6addr))
+	return NewPDNAddressAllocationDualNetIP(parseIP(v4addr), parseIP(v6addr))
 }
 
 // NewPDNAddressAllocationNetIP creates a new PDNAddressAllocation IE from net.IP.
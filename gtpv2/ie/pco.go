@@ -47,7 +47,7 @@ func (i *IE) ProtocolConfigurationOptions() (*ProtocolConfigurationOptionsFields
 				return child.ProtocolConfigurationOptions()
 			}
 		}
-		return nil, ErrIENotFound
+		return nil, &IENotFoundError{Type: ProtocolConfigurationOptions}
 	default:
 		return nil, &InvalidTypeError{Type: i.Type}
 	}
@@ -0,0 +1,48 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+// NewPGWFQDN creates a new PGWFQDN IE.
+//
+// The value can be retrieved with FullyQualifiedDomainName.
+func NewPGWFQDN(fqdn string) *IE {
+	return newFQDN(PGWFQDN, fqdn)
+}
+
+// NewPGWChangeInfo creates a new PGWChangeInfo IE.
+//
+// In the interworking with 5GS, PGW-C+SMF includes it with the PGW Set FQDN
+// (PGWFQDN with instance 0), the Alternative PGW-C/SMF FQDN (PGWFQDN with
+// instance 1) and the Alternative PGW-C/SMF IP Address (IPAddress), so that
+// MME/SGW can select another PGW-C+SMF in the same set.
+func NewPGWChangeInfo(ies ...*IE) *IE {
+	omitted := make([]*IE, 0, len(ies))
+	for _, ie := range ies {
+		if ie != nil {
+			omitted = append(omitted, ie)
+		}
+	}
+	return newGroupedIE(PGWChangeInfo, omitted...)
+}
+
+// PGWSetFQDN returns the PGW Set FQDN in PGWChangeInfo if the type of IE matches.
+func (i *IE) PGWSetFQDN() (string, error) {
+	if i.Type != PGWChangeInfo {
+		return "", &InvalidTypeError{Type: i.Type}
+	}
+
+	fqdn, err := i.FindByType(PGWFQDN, 0)
+	if err != nil {
+		return "", err
+	}
+	return fqdn.FullyQualifiedDomainName()
+}
+
+// MustPGWSetFQDN returns PGWSetFQDN in string, ignoring errors.
+// This should only be used if it is assured to have the value.
+func (i *IE) MustPGWSetFQDN() string {
+	v, _ := i.PGWSetFQDN()
+	return v
+}
@@ -36,7 +36,7 @@ func (i *IE) RANNASCause() (*RANNASCauseFields, error) {
 				return child.RANNASCause()
 			}
 		}
-		return nil, ErrIENotFound
+		return nil, &IENotFoundError{Type: RANNASCause}
 	default:
 		return nil, &InvalidTypeError{Type: i.Type}
 	}
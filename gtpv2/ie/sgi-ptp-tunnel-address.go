@@ -0,0 +1,163 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+)
+
+// NewSGiPtPTunnelAddress creates a new SGiPtPTunnelAddress IE.
+//
+// The Port Number is omitted if port is 0.
+func NewSGiPtPTunnelAddress(v4, v6 string, port uint16) *IE {
+	v := NewSGiPtPTunnelAddressFields(parseIP(v4), parseIP(v6), port)
+	b, err := v.Marshal()
+	if err != nil {
+		return nil
+	}
+
+	return New(SGiPtPTunnelAddress, 0x00, b)
+}
+
+// SGiPtPTunnelAddress returns SGiPtPTunnelAddress in SGiPtPTunnelAddressFields type if the type of IE matches.
+func (i *IE) SGiPtPTunnelAddress() (*SGiPtPTunnelAddressFields, error) {
+	if i.Type != SGiPtPTunnelAddress {
+		return nil, &InvalidTypeError{Type: i.Type}
+	}
+
+	return ParseSGiPtPTunnelAddressFields(i.Payload)
+}
+
+// SGiPtPTunnelAddressFields is a set of fields in SGiPtPTunnelAddress IE, which is
+// the address of the SGi PtP tunnel for Non-IP data delivery.
+type SGiPtPTunnelAddressFields struct {
+	Flags       uint8 // P, V6, V4 in the lowest 3 bits
+	IPv4Address net.IP
+	IPv6Address net.IP
+	Port        uint16
+}
+
+// NewSGiPtPTunnelAddressFields creates a new SGiPtPTunnelAddressFields.
+func NewSGiPtPTunnelAddressFields(v4, v6 net.IP, port uint16) *SGiPtPTunnelAddressFields {
+	f := &SGiPtPTunnelAddressFields{}
+
+	if v := v4.To4(); v != nil {
+		f.Flags |= 0x01
+		f.IPv4Address = v
+	}
+	if v := v6.To16(); v != nil {
+		f.Flags |= 0x02
+		f.IPv6Address = v
+	}
+	if port != 0 {
+		f.Flags |= 0x04
+		f.Port = port
+	}
+
+	return f
+}
+
+// HasIPv4 reports whether the IPv4 address is present.
+func (f *SGiPtPTunnelAddressFields) HasIPv4() bool {
+	return has1stBit(f.Flags)
+}
+
+// HasIPv6 reports whether the IPv6 address is present.
+func (f *SGiPtPTunnelAddressFields) HasIPv6() bool {
+	return has2ndBit(f.Flags)
+}
+
+// HasPort reports whether the Port Number is present.
+func (f *SGiPtPTunnelAddressFields) HasPort() bool {
+	return has3rdBit(f.Flags)
+}
+
+// Marshal serializes SGiPtPTunnelAddressFields.
+func (f *SGiPtPTunnelAddressFields) Marshal() ([]byte, error) {
+	b := make([]byte, f.MarshalLen())
+	if err := f.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// MarshalTo serializes SGiPtPTunnelAddressFields.
+func (f *SGiPtPTunnelAddressFields) MarshalTo(b []byte) error {
+	if len(b) < f.MarshalLen() {
+		return io.ErrUnexpectedEOF
+	}
+
+	b[0] = f.Flags & 0x07
+	offset := 1
+
+	if f.HasIPv4() {
+		copy(b[offset:offset+4], f.IPv4Address.To4())
+		offset += 4
+	}
+	if f.HasIPv6() {
+		copy(b[offset:offset+16], f.IPv6Address.To16())
+		offset += 16
+	}
+	if f.HasPort() {
+		binary.BigEndian.PutUint16(b[offset:offset+2], f.Port)
+	}
+
+	return nil
+}
+
+// ParseSGiPtPTunnelAddressFields decodes SGiPtPTunnelAddressFields.
+func ParseSGiPtPTunnelAddressFields(b []byte) (*SGiPtPTunnelAddressFields, error) {
+	f := &SGiPtPTunnelAddressFields{}
+	if err := f.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// UnmarshalBinary decodes given bytes into SGiPtPTunnelAddressFields.
+func (f *SGiPtPTunnelAddressFields) UnmarshalBinary(b []byte) error {
+	if len(b) < 1 {
+		return io.ErrUnexpectedEOF
+	}
+
+	f.Flags = b[0] & 0x07
+	if len(b) < f.MarshalLen() {
+		return io.ErrUnexpectedEOF
+	}
+	offset := 1
+
+	if f.HasIPv4() {
+		f.IPv4Address = net.IP(b[offset : offset+4])
+		offset += 4
+	}
+	if f.HasIPv6() {
+		f.IPv6Address = net.IP(b[offset : offset+16])
+		offset += 16
+	}
+	if f.HasPort() {
+		f.Port = binary.BigEndian.Uint16(b[offset : offset+2])
+	}
+
+	return nil
+}
+
+// MarshalLen returns the serial length of SGiPtPTunnelAddressFields in int.
+func (f *SGiPtPTunnelAddressFields) MarshalLen() int {
+	l := 1
+	if f.HasIPv4() {
+		l += 4
+	}
+	if f.HasIPv6() {
+		l += 16
+	}
+	if f.HasPort() {
+		l += 2
+	}
+	return l
+}
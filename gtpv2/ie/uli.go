@@ -6,6 +6,7 @@ package ie
 
 import (
 	"encoding/binary"
+	"fmt"
 	"io"
 
 	"github.com/wmnsk/go-gtp/utils"
@@ -230,7 +231,6 @@ func uliPayloadLen(flags uint8) int {
 // UserLocationInfo is a getter function to parse UserLocationInformationFields
 func (i *IE) UserLocationInfo() (*UserLocationInformationFields, error) {
 	var uli UserLocationInformationFields
-	var plmn PLMN
 	l := len(i.Payload)
 	if l == 0 {
 		return &uli, io.ErrUnexpectedEOF
@@ -242,7 +242,7 @@ func (i *IE) UserLocationInfo() (*UserLocationInformationFields, error) {
 		}
 		var cgi CGI
 		uli.CGI = &cgi
-		uli.CGI.PLMN = &plmn
+		uli.CGI.PLMN = &PLMN{}
 		uli.CGI.PLMN.MCC, uli.CGI.PLMN.MNC, _ = utils.DecodePLMN(i.Payload[offset : offset+3])
 		uli.CGI.LAC = binary.BigEndian.Uint16(i.Payload[offset+3 : offset+5])
 		uli.CGI.CI = binary.BigEndian.Uint16(i.Payload[offset+5 : offset+7])
@@ -254,7 +254,7 @@ func (i *IE) UserLocationInfo() (*UserLocationInformationFields, error) {
 		}
 		var sai SAI
 		uli.SAI = &sai
-		uli.SAI.PLMN = &plmn
+		uli.SAI.PLMN = &PLMN{}
 		uli.SAI.PLMN.MCC, uli.SAI.PLMN.MNC, _ = utils.DecodePLMN(i.Payload[offset : offset+3])
 		uli.SAI.LAC = binary.BigEndian.Uint16(i.Payload[offset+3 : offset+5])
 		uli.SAI.SAC = binary.BigEndian.Uint16(i.Payload[offset+5 : offset+7])
@@ -266,7 +266,7 @@ func (i *IE) UserLocationInfo() (*UserLocationInformationFields, error) {
 		}
 		var rai RAI
 		uli.RAI = &rai
-		uli.RAI.PLMN = &plmn
+		uli.RAI.PLMN = &PLMN{}
 		uli.RAI.PLMN.MCC, uli.RAI.PLMN.MNC, _ = utils.DecodePLMN(i.Payload[offset : offset+3])
 		uli.RAI.LAC = binary.BigEndian.Uint16(i.Payload[offset+3 : offset+5])
 		uli.RAI.RAC = binary.BigEndian.Uint16(i.Payload[offset+5 : offset+7])
@@ -278,7 +278,7 @@ func (i *IE) UserLocationInfo() (*UserLocationInformationFields, error) {
 		}
 		var tai TAI
 		uli.TAI = &tai
-		uli.TAI.PLMN = &plmn
+		uli.TAI.PLMN = &PLMN{}
 		uli.TAI.PLMN.MCC, uli.TAI.PLMN.MNC, _ = utils.DecodePLMN(i.Payload[offset : offset+3])
 		uli.TAI.TAC = binary.BigEndian.Uint16(i.Payload[offset+3 : offset+5])
 		offset += tailen
@@ -290,9 +290,9 @@ func (i *IE) UserLocationInfo() (*UserLocationInformationFields, error) {
 		}
 		var ecgi ECGI
 		uli.ECGI = &ecgi
-		uli.ECGI.PLMN = &plmn
+		uli.ECGI.PLMN = &PLMN{}
 		uli.ECGI.PLMN.MCC, uli.ECGI.PLMN.MNC, _ = utils.DecodePLMN(i.Payload[offset : offset+3])
-		uli.ECGI.ECI = binary.BigEndian.Uint32(i.Payload[offset+3 : offset+7])
+		uli.ECGI.ECI = binary.BigEndian.Uint32(i.Payload[offset+3:offset+7]) & 0x0fffffff
 		offset += ecgilen
 
 	}
@@ -302,7 +302,7 @@ func (i *IE) UserLocationInfo() (*UserLocationInformationFields, error) {
 		}
 		var lai LAI
 		uli.LAI = &lai
-		uli.LAI.PLMN = &plmn
+		uli.LAI.PLMN = &PLMN{}
 		uli.LAI.PLMN.MCC, uli.LAI.PLMN.MNC, _ = utils.DecodePLMN(i.Payload[offset : offset+3])
 		uli.LAI.LAC = binary.BigEndian.Uint16(i.Payload[offset+3 : offset+5])
 		offset += lailen
@@ -313,7 +313,7 @@ func (i *IE) UserLocationInfo() (*UserLocationInformationFields, error) {
 		}
 		var menbi MENBI
 		uli.MENBI = &menbi
-		uli.MENBI.PLMN = &plmn
+		uli.MENBI.PLMN = &PLMN{}
 		uli.MENBI.PLMN.MCC, uli.MENBI.PLMN.MNC, _ = utils.DecodePLMN(i.Payload[offset : offset+3])
 		uli.MENBI.MENBI = utils.Uint24To32(i.Payload[offset+3 : offset+6])
 		offset += menbilen
@@ -324,9 +324,228 @@ func (i *IE) UserLocationInfo() (*UserLocationInformationFields, error) {
 		}
 		var emenbi EMENBI
 		uli.EMENBI = &emenbi
-		uli.EMENBI.PLMN = &plmn
+		uli.EMENBI.PLMN = &PLMN{}
 		uli.EMENBI.PLMN.MCC, uli.EMENBI.PLMN.MNC, _ = utils.DecodePLMN(i.Payload[offset : offset+3])
 		uli.EMENBI.EMENBI = utils.Uint24To32(i.Payload[offset+3 : offset+6])
 	}
 	return &uli, nil
 }
+
+// HasCGI reports whether an IE has CGI.
+func (i *IE) HasCGI() bool {
+	return i.hasULIFlag(0)
+}
+
+// HasSAI reports whether an IE has SAI.
+func (i *IE) HasSAI() bool {
+	return i.hasULIFlag(1)
+}
+
+// HasRAI reports whether an IE has RAI.
+func (i *IE) HasRAI() bool {
+	return i.hasULIFlag(2)
+}
+
+// HasTAI reports whether an IE has TAI.
+func (i *IE) HasTAI() bool {
+	return i.hasULIFlag(3)
+}
+
+// HasECGI reports whether an IE has ECGI.
+func (i *IE) HasECGI() bool {
+	return i.hasULIFlag(4)
+}
+
+// HasLAI reports whether an IE has LAI.
+func (i *IE) HasLAI() bool {
+	return i.hasULIFlag(5)
+}
+
+// HasMENBI reports whether an IE has Macro eNodeB ID.
+func (i *IE) HasMENBI() bool {
+	return i.hasULIFlag(6)
+}
+
+// HasEMENBI reports whether an IE has Extended Macro eNodeB ID.
+func (i *IE) HasEMENBI() bool {
+	return i.hasULIFlag(7)
+}
+
+func (i *IE) hasULIFlag(bit uint8) bool {
+	if i.Type != UserLocationInformation || len(i.Payload) < 1 {
+		return false
+	}
+	return i.Payload[0]>>bit&0x01 == 1
+}
+
+// CGI returns CGI in UserLocationInformation IE if the type of IE matches.
+// It returns ErrIEValueNotFound if the IE does not have CGI.
+func (i *IE) CGI() (*CGI, error) {
+	uli, err := i.uliFields()
+	if err != nil {
+		return nil, err
+	}
+	if uli.CGI == nil {
+		return nil, ErrIEValueNotFound
+	}
+	return uli.CGI, nil
+}
+
+// SAI returns SAI in UserLocationInformation IE if the type of IE matches.
+// It returns ErrIEValueNotFound if the IE does not have SAI.
+func (i *IE) SAI() (*SAI, error) {
+	uli, err := i.uliFields()
+	if err != nil {
+		return nil, err
+	}
+	if uli.SAI == nil {
+		return nil, ErrIEValueNotFound
+	}
+	return uli.SAI, nil
+}
+
+// RAI returns RAI in UserLocationInformation IE if the type of IE matches.
+// It returns ErrIEValueNotFound if the IE does not have RAI.
+func (i *IE) RAI() (*RAI, error) {
+	uli, err := i.uliFields()
+	if err != nil {
+		return nil, err
+	}
+	if uli.RAI == nil {
+		return nil, ErrIEValueNotFound
+	}
+	return uli.RAI, nil
+}
+
+// TAI returns TAI in UserLocationInformation IE if the type of IE matches.
+// It returns ErrIEValueNotFound if the IE does not have TAI.
+func (i *IE) TAI() (*TAI, error) {
+	uli, err := i.uliFields()
+	if err != nil {
+		return nil, err
+	}
+	if uli.TAI == nil {
+		return nil, ErrIEValueNotFound
+	}
+	return uli.TAI, nil
+}
+
+// ECGI returns ECGI in UserLocationInformation IE if the type of IE matches.
+// It returns ErrIEValueNotFound if the IE does not have ECGI.
+func (i *IE) ECGI() (*ECGI, error) {
+	uli, err := i.uliFields()
+	if err != nil {
+		return nil, err
+	}
+	if uli.ECGI == nil {
+		return nil, ErrIEValueNotFound
+	}
+	return uli.ECGI, nil
+}
+
+// LAI returns LAI in UserLocationInformation IE if the type of IE matches.
+// It returns ErrIEValueNotFound if the IE does not have LAI.
+func (i *IE) LAI() (*LAI, error) {
+	uli, err := i.uliFields()
+	if err != nil {
+		return nil, err
+	}
+	if uli.LAI == nil {
+		return nil, ErrIEValueNotFound
+	}
+	return uli.LAI, nil
+}
+
+// MENBI returns Macro eNodeB ID in UserLocationInformation IE if the type of IE matches.
+// It returns ErrIEValueNotFound if the IE does not have Macro eNodeB ID.
+func (i *IE) MENBI() (*MENBI, error) {
+	uli, err := i.uliFields()
+	if err != nil {
+		return nil, err
+	}
+	if uli.MENBI == nil {
+		return nil, ErrIEValueNotFound
+	}
+	return uli.MENBI, nil
+}
+
+// EMENBI returns Extended Macro eNodeB ID in UserLocationInformation IE if the type
+// of IE matches. It returns ErrIEValueNotFound if the IE does not have Extended
+// Macro eNodeB ID.
+func (i *IE) EMENBI() (*EMENBI, error) {
+	uli, err := i.uliFields()
+	if err != nil {
+		return nil, err
+	}
+	if uli.EMENBI == nil {
+		return nil, ErrIEValueNotFound
+	}
+	return uli.EMENBI, nil
+}
+
+func (i *IE) uliFields() (*UserLocationInformationFields, error) {
+	if i.Type != UserLocationInformation {
+		return nil, &InvalidTypeError{Type: i.Type}
+	}
+	return i.UserLocationInfo()
+}
+
+// String returns PLMN in "MCC-MNC" format, e.g., "123-45".
+func (p *PLMN) String() string {
+	if p == nil {
+		return ""
+	}
+	return p.MCC + "-" + p.MNC
+}
+
+// String returns CGI in "MCC-MNC-LAC-CI" format, with LAC and CI in hex.
+func (c *CGI) String() string {
+	return fmt.Sprintf("%s-%04x-%04x", c.PLMN, c.LAC, c.CI)
+}
+
+// String returns SAI in "MCC-MNC-LAC-SAC" format, with LAC and SAC in hex.
+func (s *SAI) String() string {
+	return fmt.Sprintf("%s-%04x-%04x", s.PLMN, s.LAC, s.SAC)
+}
+
+// String returns RAI in "MCC-MNC-LAC-RAC" format, with LAC and RAC in hex.
+func (r *RAI) String() string {
+	return fmt.Sprintf("%s-%04x-%04x", r.PLMN, r.LAC, r.RAC)
+}
+
+// String returns TAI in "MCC-MNC-TAC" format, with TAC in hex.
+func (t *TAI) String() string {
+	return fmt.Sprintf("%s-%04x", t.PLMN, t.TAC)
+}
+
+// String returns ECGI in "MCC-MNC-ECI" format, with ECI in 7 digits of hex.
+func (e *ECGI) String() string {
+	return fmt.Sprintf("%s-%07x", e.PLMN, e.ECI)
+}
+
+// ENBID returns the eNodeB ID part of ECI, assuming it is a macro eNodeB, whose ID
+// is the leftmost 20 bits of ECI.
+func (e *ECGI) ENBID() uint32 {
+	return e.ECI >> 8
+}
+
+// CellID returns the Cell ID part of ECI, assuming it is a macro eNodeB, whose Cell
+// ID is the rightmost 8 bits of ECI.
+func (e *ECGI) CellID() uint8 {
+	return uint8(e.ECI)
+}
+
+// String returns LAI in "MCC-MNC-LAC" format, with LAC in hex.
+func (l *LAI) String() string {
+	return fmt.Sprintf("%s-%04x", l.PLMN, l.LAC)
+}
+
+// String returns Macro eNodeB ID in "MCC-MNC-ID" format, with ID in hex.
+func (m *MENBI) String() string {
+	return fmt.Sprintf("%s-%05x", m.PLMN, m.MENBI)
+}
+
+// String returns Extended Macro eNodeB ID in "MCC-MNC-ID" format, with ID in hex.
+func (e *EMENBI) String() string {
+	return fmt.Sprintf("%s-%06x", e.PLMN, e.EMENBI)
+}
@@ -0,0 +1,65 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package ie_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+func TestULIGetters(t *testing.T) {
+	uli := ie.New(ie.UserLocationInformation, 0x00, []byte{
+		// Flags: TAI and ECGI
+		0x18,
+		// TAI
+		0x21, 0xf3, 0x54, 0x55, 0x55,
+		// ECGI, with different PLMN and spare bits set
+		0x00, 0xf1, 0x10, 0xf1, 0x23, 0x45, 0x67,
+	})
+
+	if !uli.HasTAI() || !uli.HasECGI() {
+		t.Error("TAI or ECGI is reported missing")
+	}
+	if uli.HasCGI() || uli.HasSAI() || uli.HasRAI() || uli.HasLAI() || uli.HasMENBI() || uli.HasEMENBI() {
+		t.Error("missing field is reported present")
+	}
+
+	tai, err := uli.TAI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(&ie.TAI{PLMN: &ie.PLMN{MCC: "123", MNC: "45"}, TAC: 0x5555}, tai); diff != "" {
+		t.Error(diff)
+	}
+	if got, want := tai.String(), "123-45-5555"; got != want {
+		t.Errorf("wrong TAI string. want: %s, got: %s", want, got)
+	}
+
+	ecgi, err := uli.ECGI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(&ie.ECGI{PLMN: &ie.PLMN{MCC: "001", MNC: "01"}, ECI: 0x1234567}, ecgi); diff != "" {
+		t.Error(diff)
+	}
+	if got, want := ecgi.String(), "001-01-1234567"; got != want {
+		t.Errorf("wrong ECGI string. want: %s, got: %s", want, got)
+	}
+	if got, want := ecgi.ENBID(), uint32(0x12345); got != want {
+		t.Errorf("wrong eNB ID. want: %#x, got: %#x", want, got)
+	}
+	if got, want := ecgi.CellID(), uint8(0x67); got != want {
+		t.Errorf("wrong Cell ID. want: %#x, got: %#x", want, got)
+	}
+
+	if _, err := uli.CGI(); err != ie.ErrIEValueNotFound {
+		t.Errorf("unexpected error for missing CGI: %v", err)
+	}
+	if _, err := ie.NewIMSI("123451234567890").TAI(); err == nil {
+		t.Error("expected error for the IE of wrong type")
+	}
+}
@@ -4,6 +4,8 @@
 
 package ie
 
+import "net"
+
 func has8thBit(f uint8) bool {
 	return (f&0x80)>>7 == 1
 }
@@ -35,3 +37,12 @@ func has2ndBit(f uint8) bool {
 func has1stBit(f uint8) bool {
 	return (f & 0x01) == 1
 }
+
+// parseIP is net.ParseIP that returns nil without allocating anything for the
+// empty string, which is given when the address is not present.
+func parseIP(s string) net.IP {
+	if s == "" {
+		return nil
+	}
+	return net.ParseIP(s)
+}
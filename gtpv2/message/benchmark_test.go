@@ -0,0 +1,150 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+func newBenchCreateSessionRequest() *message.CreateSessionRequest {
+	return message.NewCreateSessionRequest(
+		0x11223344, 0x000001,
+		ie.NewIMSI("123451234567890"),
+		ie.NewMSISDN("123450123456789"),
+		ie.NewMobileEquipmentIdentity("123450123456789"),
+		ie.NewUserLocationInformationLazy("123", "45", -1, -1, -1, -1, 0x0001, 0x00000101, -1, -1),
+		ie.NewServingNetwork("123", "45"),
+		ie.NewRATType(v2.RATTypeEUTRAN),
+		ie.NewIndicationFromOctets(0xa1, 0x08, 0x15, 0x10, 0x88, 0x81, 0x40),
+		ie.NewFullyQualifiedTEID(v2.IFTypeS11MMEGTPC, 0xffffffff, "1.1.1.1", ""),
+		ie.NewFullyQualifiedTEID(v2.IFTypeS5S8PGWGTPC, 0xffffffff, "1.1.1.2", "").WithInstance(1),
+		ie.NewAccessPointName("some.apn.example"),
+		ie.NewSelectionMode(v2.SelectionModeMSorNetworkProvidedAPNSubscribedVerified),
+		ie.NewPDNType(v2.PDNTypeIPv4),
+		ie.NewPDNAddressAllocation("2.2.2.2"),
+		ie.NewAPNRestriction(v2.APNRestrictionPublic1),
+		ie.NewAggregateMaximumBitRate(0x11111111, 0x22222222),
+		ie.NewBearerContext(
+			ie.NewEPSBearerID(0x05),
+			ie.NewFullyQualifiedTEID(v2.IFTypeS1USGWGTPU, 0x11111111, "1.1.1.3", "").WithInstance(0),
+			ie.NewBearerQoS(1, 2, 1, 0xff, 0x1111111111, 0x2222222222, 0x1111111111, 0x2222222222),
+		),
+		ie.NewRecovery(0x01),
+	)
+}
+
+func BenchmarkCreateSessionRequest(b *testing.B) {
+	csr := newBenchCreateSessionRequest()
+	serialized, err := csr.Marshal()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("New", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = newBenchCreateSessionRequest()
+		}
+	})
+	b.Run("Marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := csr.Marshal(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("MarshalTo", func(b *testing.B) {
+		buf := make([]byte, csr.MarshalLen())
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := csr.MarshalTo(buf); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("AppendTo", func(b *testing.B) {
+		buf := make([]byte, 0, csr.MarshalLen())
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := csr.AppendTo(buf[:0]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Parse", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := message.Parse(serialized); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("NewRoundTrip", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			serialized, err := newBenchCreateSessionRequest().Marshal()
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := message.Parse(serialized); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("ParseLazy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l, err := message.ParseLazy(serialized)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := l.IE(ie.IMSI, 0); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("RoundTrip", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			serialized, err := csr.Marshal()
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := message.Parse(serialized); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkEchoRequest(b *testing.B) {
+	echo := message.NewEchoRequest(0x000001, ie.NewRecovery(0x80))
+	serialized, err := echo.Marshal()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("Marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := echo.Marshal(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Parse", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := message.Parse(serialized); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,57 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"reflect"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+var (
+	headerType = reflect.TypeOf((*Header)(nil))
+	ieType     = reflect.TypeOf((*ie.IE)(nil))
+	iesType    = reflect.TypeOf([]*ie.IE(nil))
+)
+
+// Clone returns a deep copy of a Message instance, including the Header, the
+// IEs and the bytes they refer to, which can be modified without affecting the
+// original one. The unknown IEs are kept as they are in AdditionalIEs.
+//
+// Better to use the Clone method of each message instead if you know the type
+// of message to be copied.
+func Clone(m Message) Message {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return m
+	}
+
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+	for n := 0; n < c.Elem().NumField(); n++ {
+		f := c.Elem().Field(n)
+		if !f.CanSet() {
+			continue
+		}
+
+		switch f.Type() {
+		case headerType:
+			f.Set(reflect.ValueOf(f.Interface().(*Header).Clone()))
+		case ieType:
+			f.Set(reflect.ValueOf(f.Interface().(*ie.IE).Clone()))
+		case iesType:
+			ies := f.Interface().([]*ie.IE)
+			if ies == nil {
+				continue
+			}
+			cloned := make([]*ie.IE, len(ies))
+			for j, i := range ies {
+				cloned[j] = i.Clone()
+			}
+			f.Set(reflect.ValueOf(cloned))
+		}
+	}
+	return c.Interface().(Message)
+}
@@ -0,0 +1,65 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+func TestClone(t *testing.T) {
+	b, err := message.NewCreateSessionRequest(
+		0x11223344, 1,
+		ie.NewIMSI("123451234567890"),
+		ie.NewFullyQualifiedTEID(v2.IFTypeS11MMEGTPC, 0xffffffff, "1.1.1.1", ""),
+		ie.NewBearerContext(
+			ie.NewEPSBearerID(5),
+			ie.NewFullyQualifiedTEID(v2.IFTypeS1UeNodeBGTPU, 0x11111111, "1.1.1.1", ""),
+		),
+		ie.New(0xfe, 0x00, []byte{0xde, 0xad, 0xbe, 0xef}),
+	).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orig, err := message.ParseCreateSessionRequest(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cloned := orig.Clone()
+	opt := cmp.AllowUnexported(ie.IE{})
+	if diff := cmp.Diff(cloned, orig, opt); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// modifying the copy should not affect the original nor the bytes it is parsed from.
+	want := append([]byte{}, b...)
+	cloned.SetTEID(0)
+	cloned.IMSI.Payload[0] = 0xff
+	cloned.BearerContextsToBeCreated[0].ChildIEs[1].Payload[1] = 0xff
+	cloned.BearerContextsToBeCreated[0].Add(ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil))
+	cloned.AdditionalIEs[0].Payload[0] = 0xff
+	cloned.Header.Payload[0] = 0xff
+
+	got, err := orig.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff(b, want); diff != "" {
+		t.Error(diff)
+	}
+
+	if _, ok := message.Clone(orig).(*message.CreateSessionRequest); !ok {
+		t.Errorf("Clone returned %T, want *message.CreateSessionRequest", message.Clone(orig))
+	}
+}
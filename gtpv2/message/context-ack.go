@@ -83,12 +83,23 @@ func (c *ContextAcknowledge) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from ContextAcknowledge to b and returns the extended buffer.
+func (c *ContextAcknowledge) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, c)
+}
+
+// Clone returns a deep copy of ContextAcknowledge.
+func (c *ContextAcknowledge) Clone() *ContextAcknowledge {
+	return Clone(c).(*ContextAcknowledge)
+}
+
 // MarshalTo serializes ContextAcknowledge into bytes.
 func (c *ContextAcknowledge) MarshalTo(b []byte) error {
-	if c.Header.Payload != nil {
-		c.Header.Payload = nil
+	var err error
+	c.Header.Payload, err = c.Header.payloadIn(b, c.MarshalLen())
+	if err != nil {
+		return err
 	}
-	c.Header.Payload = make([]byte, c.MarshalLen()-c.Header.MarshalLen())
 
 	offset := 0
 	if ie := c.Cause; ie != nil {
@@ -116,12 +116,23 @@ func (c *ContextRequest) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from ContextRequest to b and returns the extended buffer.
+func (c *ContextRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, c)
+}
+
+// Clone returns a deep copy of ContextRequest.
+func (c *ContextRequest) Clone() *ContextRequest {
+	return Clone(c).(*ContextRequest)
+}
+
 // MarshalTo serializes ContextRequest into bytes.
 func (c *ContextRequest) MarshalTo(b []byte) error {
-	if c.Header.Payload != nil {
-		c.Header.Payload = nil
+	var err error
+	c.Header.Payload, err = c.Header.payloadIn(b, c.MarshalLen())
+	if err != nil {
+		return err
 	}
-	c.Header.Payload = make([]byte, c.MarshalLen()-c.Header.MarshalLen())
 
 	offset := 0
 	if ie := c.IMSI; ie != nil {
@@ -176,12 +176,23 @@ func (c *ContextResponse) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from ContextResponse to b and returns the extended buffer.
+func (c *ContextResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, c)
+}
+
+// Clone returns a deep copy of ContextResponse.
+func (c *ContextResponse) Clone() *ContextResponse {
+	return Clone(c).(*ContextResponse)
+}
+
 // MarshalTo serializes ContextResponse into bytes.
 func (c *ContextResponse) MarshalTo(b []byte) error {
-	if c.Header.Payload != nil {
-		c.Header.Payload = nil
+	var err error
+	c.Header.Payload, err = c.Header.payloadIn(b, c.MarshalLen())
+	if err != nil {
+		return err
 	}
-	c.Header.Payload = make([]byte, c.MarshalLen()-c.Header.MarshalLen())
 
 	offset := 0
 	if ie := c.Cause; ie != nil {
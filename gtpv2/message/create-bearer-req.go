@@ -115,12 +115,23 @@ func (c *CreateBearerRequest) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from CreateBearerRequest to b and returns the extended buffer.
+func (c *CreateBearerRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, c)
+}
+
+// Clone returns a deep copy of CreateBearerRequest.
+func (c *CreateBearerRequest) Clone() *CreateBearerRequest {
+	return Clone(c).(*CreateBearerRequest)
+}
+
 // MarshalTo serializes CreateBearerRequest into bytes.
 func (c *CreateBearerRequest) MarshalTo(b []byte) error {
-	if c.Header.Payload != nil {
-		c.Header.Payload = nil
+	var err error
+	c.Header.Payload, err = c.Header.payloadIn(b, c.MarshalLen())
+	if err != nil {
+		return err
 	}
-	c.Header.Payload = make([]byte, c.MarshalLen()-c.Header.MarshalLen())
 
 	offset := 0
 	if ie := c.PTI; ie != nil {
@@ -143,12 +143,23 @@ func (c *CreateBearerResponse) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from CreateBearerResponse to b and returns the extended buffer.
+func (c *CreateBearerResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, c)
+}
+
+// Clone returns a deep copy of CreateBearerResponse.
+func (c *CreateBearerResponse) Clone() *CreateBearerResponse {
+	return Clone(c).(*CreateBearerResponse)
+}
+
 // MarshalTo serializes CreateBearerResponse into bytes.
 func (c *CreateBearerResponse) MarshalTo(b []byte) error {
-	if c.Header.Payload != nil {
-		c.Header.Payload = nil
+	var err error
+	c.Header.Payload, err = c.Header.payloadIn(b, c.MarshalLen())
+	if err != nil {
+		return err
 	}
-	c.Header.Payload = make([]byte, c.MarshalLen()-c.Header.MarshalLen())
 
 	offset := 0
 	if ie := c.Cause; ie != nil {
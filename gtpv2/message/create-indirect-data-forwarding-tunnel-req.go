@@ -0,0 +1,252 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+// CreateIndirectDataForwardingTunnelRequest is a CreateIndirectDataForwardingTunnelRequest Header and its IEs above.
+type CreateIndirectDataForwardingTunnelRequest struct {
+	*Header
+	IMSI             *ie.IE
+	MEI              *ie.IE
+	IndicationFlags  *ie.IE
+	SenderFTEIDC     *ie.IE
+	BearerContexts   []*ie.IE
+	Recovery         *ie.IE
+	PrivateExtension *ie.IE
+	AdditionalIEs    []*ie.IE
+}
+
+// NewCreateIndirectDataForwardingTunnelRequest creates a new CreateIndirectDataForwardingTunnelRequest.
+func NewCreateIndirectDataForwardingTunnelRequest(teid, seq uint32, IEs ...*ie.IE) *CreateIndirectDataForwardingTunnelRequest {
+	m := &CreateIndirectDataForwardingTunnelRequest{
+		Header: NewHeader(
+			NewHeaderFlags(2, 0, 1),
+			MsgTypeCreateIndirectDataForwardingTunnelRequest, teid, seq, nil,
+		),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.IMSI:
+			m.IMSI = i
+		case ie.MobileEquipmentIdentity:
+			m.MEI = i
+		case ie.Indication:
+			m.IndicationFlags = i
+		case ie.FullyQualifiedTEID:
+			m.SenderFTEIDC = i
+		case ie.BearerContext:
+			m.BearerContexts = append(m.BearerContexts, i)
+		case ie.Recovery:
+			m.Recovery = i
+		case ie.PrivateExtension:
+			m.PrivateExtension = i
+		default:
+			m.AdditionalIEs = append(m.AdditionalIEs, i)
+		}
+	}
+
+	m.SetLength()
+	return m
+}
+
+// Marshal returns the byte sequence generated from a CreateIndirectDataForwardingTunnelRequest.
+func (m *CreateIndirectDataForwardingTunnelRequest) Marshal() ([]byte, error) {
+	b := make([]byte, m.MarshalLen())
+	if err := m.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// AppendTo appends the byte sequence generated from CreateIndirectDataForwardingTunnelRequest to b and returns the extended buffer.
+func (m *CreateIndirectDataForwardingTunnelRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// Clone returns a deep copy of CreateIndirectDataForwardingTunnelRequest.
+func (m *CreateIndirectDataForwardingTunnelRequest) Clone() *CreateIndirectDataForwardingTunnelRequest {
+	return Clone(m).(*CreateIndirectDataForwardingTunnelRequest)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (m *CreateIndirectDataForwardingTunnelRequest) MarshalTo(b []byte) error {
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if ie := m.IMSI; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.MEI; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.IndicationFlags; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.SenderFTEIDC; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	for _, ie := range m.BearerContexts {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.Recovery; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range m.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	m.Header.SetLength()
+	return m.Header.MarshalTo(b)
+}
+
+// ParseCreateIndirectDataForwardingTunnelRequest decodes a given byte sequence as a CreateIndirectDataForwardingTunnelRequest.
+func ParseCreateIndirectDataForwardingTunnelRequest(b []byte) (*CreateIndirectDataForwardingTunnelRequest, error) {
+	m := &CreateIndirectDataForwardingTunnelRequest{}
+	if err := m.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// UnmarshalBinary decodes a given byte sequence as a CreateIndirectDataForwardingTunnelRequest.
+func (m *CreateIndirectDataForwardingTunnelRequest) UnmarshalBinary(b []byte) error {
+	var err error
+	m.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(m.Header.Payload) < 2 {
+		return nil
+	}
+
+	decodedIEs, err := ie.ParseMultiIEs(m.Header.Payload)
+	if err != nil {
+		return err
+	}
+	for _, i := range decodedIEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.IMSI:
+			m.IMSI = i
+		case ie.MobileEquipmentIdentity:
+			m.MEI = i
+		case ie.Indication:
+			m.IndicationFlags = i
+		case ie.FullyQualifiedTEID:
+			m.SenderFTEIDC = i
+		case ie.BearerContext:
+			m.BearerContexts = append(m.BearerContexts, i)
+		case ie.Recovery:
+			m.Recovery = i
+		case ie.PrivateExtension:
+			m.PrivateExtension = i
+		default:
+			m.AdditionalIEs = append(m.AdditionalIEs, i)
+		}
+	}
+
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (m *CreateIndirectDataForwardingTunnelRequest) MarshalLen() int {
+	l := m.Header.MarshalLen() - len(m.Header.Payload)
+
+	if ie := m.IMSI; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.MEI; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.IndicationFlags; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.SenderFTEIDC; ie != nil {
+		l += ie.MarshalLen()
+	}
+	for _, ie := range m.BearerContexts {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	if ie := m.Recovery; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range m.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (m *CreateIndirectDataForwardingTunnelRequest) SetLength() {
+	m.Header.Length = uint16(m.MarshalLen() - 4)
+}
+
+// MessageTypeName returns the name of protocol.
+func (m *CreateIndirectDataForwardingTunnelRequest) MessageTypeName() string {
+	return "Create Indirect Data Forwarding Tunnel Request"
+}
+
+// TEID returns the TEID in uint32.
+func (m *CreateIndirectDataForwardingTunnelRequest) TEID() uint32 {
+	return m.Header.teid()
+}
@@ -0,0 +1,56 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+	"github.com/wmnsk/go-gtp/gtpv2/testutils"
+)
+
+func TestCreateIndirectDataForwardingTunnelRequest(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "Normal",
+			Structured: message.NewCreateIndirectDataForwardingTunnelRequest(
+				testutils.TestBearerInfo.TEID, testutils.TestBearerInfo.Seq,
+				ie.NewIMSI("123451234567890"),
+				ie.NewFullyQualifiedTEID(v2.IFTypeS11MMEGTPC, 0xffffffff, "1.1.1.1", ""),
+				ie.NewBearerContext(
+					ie.NewEPSBearerID(5),
+					ie.NewFullyQualifiedTEID(v2.IFTypeeNodeBGTPUForDL, 0xffffffff, "1.1.1.2", ""),
+				),
+			),
+			Serialized: []byte{
+				// Header
+				0x48, 0xa6, 0x00, 0x37, 0x11, 0x22, 0x33, 0x44, 0x00, 0x00, 0x01, 0x00,
+				// IMSI
+				0x01, 0x00, 0x08, 0x00, 0x21, 0x43, 0x15, 0x32, 0x54, 0x76, 0x98, 0xf0,
+				// FullyQualifiedTEID
+				0x57, 0x00, 0x09, 0x00, 0x8a, 0xff, 0xff, 0xff, 0xff, 0x01, 0x01, 0x01,
+				0x01,
+				// BearerContext
+				0x5d, 0x00, 0x12, 0x00,
+				//   EPSBearerID
+				0x49, 0x00, 0x01, 0x00, 0x05,
+				//   FullyQualifiedTEID
+				0x57, 0x00, 0x09, 0x00, 0x93, 0xff, 0xff, 0xff, 0xff, 0x01, 0x01, 0x01,
+				0x02,
+			},
+		},
+	}
+
+	testutils.Run(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseCreateIndirectDataForwardingTunnelRequest(b)
+		if err != nil {
+			return nil, err
+		}
+		v.Payload = nil
+		return v, nil
+	})
+}
@@ -0,0 +1,224 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+// CreateIndirectDataForwardingTunnelResponse is a CreateIndirectDataForwardingTunnelResponse Header and its IEs above.
+type CreateIndirectDataForwardingTunnelResponse struct {
+	*Header
+	Cause            *ie.IE
+	SenderFTEIDC     *ie.IE
+	BearerContexts   []*ie.IE
+	Recovery         *ie.IE
+	PrivateExtension *ie.IE
+	AdditionalIEs    []*ie.IE
+}
+
+// NewCreateIndirectDataForwardingTunnelResponse creates a new CreateIndirectDataForwardingTunnelResponse.
+func NewCreateIndirectDataForwardingTunnelResponse(teid, seq uint32, IEs ...*ie.IE) *CreateIndirectDataForwardingTunnelResponse {
+	m := &CreateIndirectDataForwardingTunnelResponse{
+		Header: NewHeader(
+			NewHeaderFlags(2, 0, 1),
+			MsgTypeCreateIndirectDataForwardingTunnelResponse, teid, seq, nil,
+		),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			m.Cause = i
+		case ie.FullyQualifiedTEID:
+			m.SenderFTEIDC = i
+		case ie.BearerContext:
+			m.BearerContexts = append(m.BearerContexts, i)
+		case ie.Recovery:
+			m.Recovery = i
+		case ie.PrivateExtension:
+			m.PrivateExtension = i
+		default:
+			m.AdditionalIEs = append(m.AdditionalIEs, i)
+		}
+	}
+
+	m.SetLength()
+	return m
+}
+
+// Marshal returns the byte sequence generated from a CreateIndirectDataForwardingTunnelResponse.
+func (m *CreateIndirectDataForwardingTunnelResponse) Marshal() ([]byte, error) {
+	b := make([]byte, m.MarshalLen())
+	if err := m.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// AppendTo appends the byte sequence generated from CreateIndirectDataForwardingTunnelResponse to b and returns the extended buffer.
+func (m *CreateIndirectDataForwardingTunnelResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// Clone returns a deep copy of CreateIndirectDataForwardingTunnelResponse.
+func (m *CreateIndirectDataForwardingTunnelResponse) Clone() *CreateIndirectDataForwardingTunnelResponse {
+	return Clone(m).(*CreateIndirectDataForwardingTunnelResponse)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (m *CreateIndirectDataForwardingTunnelResponse) MarshalTo(b []byte) error {
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if ie := m.Cause; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.SenderFTEIDC; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	for _, ie := range m.BearerContexts {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.Recovery; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := m.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range m.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	m.Header.SetLength()
+	return m.Header.MarshalTo(b)
+}
+
+// ParseCreateIndirectDataForwardingTunnelResponse decodes a given byte sequence as a CreateIndirectDataForwardingTunnelResponse.
+func ParseCreateIndirectDataForwardingTunnelResponse(b []byte) (*CreateIndirectDataForwardingTunnelResponse, error) {
+	m := &CreateIndirectDataForwardingTunnelResponse{}
+	if err := m.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// UnmarshalBinary decodes a given byte sequence as a CreateIndirectDataForwardingTunnelResponse.
+func (m *CreateIndirectDataForwardingTunnelResponse) UnmarshalBinary(b []byte) error {
+	var err error
+	m.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(m.Header.Payload) < 2 {
+		return nil
+	}
+
+	decodedIEs, err := ie.ParseMultiIEs(m.Header.Payload)
+	if err != nil {
+		return err
+	}
+	for _, i := range decodedIEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			m.Cause = i
+		case ie.FullyQualifiedTEID:
+			m.SenderFTEIDC = i
+		case ie.BearerContext:
+			m.BearerContexts = append(m.BearerContexts, i)
+		case ie.Recovery:
+			m.Recovery = i
+		case ie.PrivateExtension:
+			m.PrivateExtension = i
+		default:
+			m.AdditionalIEs = append(m.AdditionalIEs, i)
+		}
+	}
+
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (m *CreateIndirectDataForwardingTunnelResponse) MarshalLen() int {
+	l := m.Header.MarshalLen() - len(m.Header.Payload)
+
+	if ie := m.Cause; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.SenderFTEIDC; ie != nil {
+		l += ie.MarshalLen()
+	}
+	for _, ie := range m.BearerContexts {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	if ie := m.Recovery; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := m.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range m.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (m *CreateIndirectDataForwardingTunnelResponse) SetLength() {
+	m.Header.Length = uint16(m.MarshalLen() - 4)
+}
+
+// MessageTypeName returns the name of protocol.
+func (m *CreateIndirectDataForwardingTunnelResponse) MessageTypeName() string {
+	return "Create Indirect Data Forwarding Tunnel Response"
+}
+
+// TEID returns the TEID in uint32.
+func (m *CreateIndirectDataForwardingTunnelResponse) TEID() uint32 {
+	return m.Header.teid()
+}
@@ -0,0 +1,103 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+	"github.com/wmnsk/go-gtp/gtpv2/testutils"
+)
+
+func TestCreateIndirectDataForwardingTunnelResponse(t *testing.T) {
+	cases := []testutils.TestCase{
+		{
+			Description: "Normal",
+			Structured: message.NewCreateIndirectDataForwardingTunnelResponse(
+				testutils.TestBearerInfo.TEID, testutils.TestBearerInfo.Seq,
+				ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+				ie.NewFullyQualifiedTEID(v2.IFTypeS11S4SGWGTPC, 0xffffffff, "1.1.1.3", ""),
+				ie.NewBearerContext(
+					ie.NewEPSBearerID(5),
+					ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+					ie.NewFullyQualifiedTEID(v2.IFTypeSGWUPFGTPUForDL, 0xffffffff, "1.1.1.4", ""),
+				),
+			),
+			Serialized: []byte{
+				// Header
+				0x48, 0xa7, 0x00, 0x37, 0x11, 0x22, 0x33, 0x44, 0x00, 0x00, 0x01, 0x00,
+				// Cause
+				0x02, 0x00, 0x02, 0x00, 0x10, 0x00,
+				// FullyQualifiedTEID
+				0x57, 0x00, 0x09, 0x00, 0x8b, 0xff, 0xff, 0xff, 0xff, 0x01, 0x01, 0x01,
+				0x03,
+				// BearerContext
+				0x5d, 0x00, 0x18, 0x00,
+				//   EPSBearerID
+				0x49, 0x00, 0x01, 0x00, 0x05,
+				//   Cause
+				0x02, 0x00, 0x02, 0x00, 0x10, 0x00,
+				//   FullyQualifiedTEID
+				0x57, 0x00, 0x09, 0x00, 0x97, 0xff, 0xff, 0xff, 0xff, 0x01, 0x01, 0x01,
+				0x04,
+			},
+		}, {
+			Description: "MultipleBearers",
+			Structured: message.NewCreateIndirectDataForwardingTunnelResponse(
+				testutils.TestBearerInfo.TEID, testutils.TestBearerInfo.Seq,
+				ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+				ie.NewFullyQualifiedTEID(v2.IFTypeS11S4SGWGTPC, 0xffffffff, "1.1.1.3", ""),
+				ie.NewBearerContext(
+					ie.NewEPSBearerID(5),
+					ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+					ie.NewFullyQualifiedTEID(v2.IFTypeSGWUPFGTPUForDL, 0xffffffff, "1.1.1.4", ""),
+				),
+				ie.NewBearerContext(
+					ie.NewEPSBearerID(6),
+					ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+					ie.NewFullyQualifiedTEID(v2.IFTypeSGWUPFGTPUForDL, 0xffffffff, "1.1.1.4", ""),
+				),
+			),
+			Serialized: []byte{
+				// Header
+				0x48, 0xa7, 0x00, 0x53, 0x11, 0x22, 0x33, 0x44, 0x00, 0x00, 0x01, 0x00,
+				// Cause
+				0x02, 0x00, 0x02, 0x00, 0x10, 0x00,
+				// FullyQualifiedTEID
+				0x57, 0x00, 0x09, 0x00, 0x8b, 0xff, 0xff, 0xff, 0xff, 0x01, 0x01, 0x01,
+				0x03,
+				// BearerContext
+				0x5d, 0x00, 0x18, 0x00,
+				//   EPSBearerID
+				0x49, 0x00, 0x01, 0x00, 0x05,
+				//   Cause
+				0x02, 0x00, 0x02, 0x00, 0x10, 0x00,
+				//   FullyQualifiedTEID
+				0x57, 0x00, 0x09, 0x00, 0x97, 0xff, 0xff, 0xff, 0xff, 0x01, 0x01, 0x01,
+				0x04,
+				// BearerContext
+				0x5d, 0x00, 0x18, 0x00,
+				//   EPSBearerID
+				0x49, 0x00, 0x01, 0x00, 0x06,
+				//   Cause
+				0x02, 0x00, 0x02, 0x00, 0x10, 0x00,
+				//   FullyQualifiedTEID
+				0x57, 0x00, 0x09, 0x00, 0x97, 0xff, 0xff, 0xff, 0xff, 0x01, 0x01, 0x01,
+				0x04,
+			},
+		},
+	}
+
+	testutils.Run(t, cases, func(b []byte) (testutils.Serializable, error) {
+		v, err := message.ParseCreateIndirectDataForwardingTunnelResponse(b)
+		if err != nil {
+			return nil, err
+		}
+		v.Payload = nil
+		return v, nil
+	})
+}
@@ -29,8 +29,8 @@ type CreateSessionRequest struct {
 	LinkedEBI                          *ie.IE
 	TWMI                               *ie.IE
 	PCO                                *ie.IE
-	BearerContextsToBeCreated          *ie.IE
-	BearerContextsToBeRemoved          *ie.IE
+	BearerContextsToBeCreated          []*ie.IE
+	BearerContextsToBeRemoved          []*ie.IE
 	TraceInformation                   *ie.IE
 	Recovery                           *ie.IE
 	MMEFQCSID                          *ie.IE
@@ -144,9 +144,9 @@ func NewCreateSessionRequest(teid, seq uint32, IEs ...*ie.IE) *CreateSessionRequ
 		case ie.BearerContext:
 			switch i.Instance() {
 			case 0:
-				c.BearerContextsToBeCreated = i
+				c.BearerContextsToBeCreated = append(c.BearerContextsToBeCreated, i)
 			case 1:
-				c.BearerContextsToBeRemoved = i
+				c.BearerContextsToBeRemoved = append(c.BearerContextsToBeRemoved, i)
 			default:
 				c.AdditionalIEs = append(c.AdditionalIEs, i)
 			}
@@ -284,12 +284,23 @@ func (c *CreateSessionRequest) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from CreateSessionRequest to b and returns the extended buffer.
+func (c *CreateSessionRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, c)
+}
+
+// Clone returns a deep copy of CreateSessionRequest.
+func (c *CreateSessionRequest) Clone() *CreateSessionRequest {
+	return Clone(c).(*CreateSessionRequest)
+}
+
 // MarshalTo serializes CreateSessionRequest into bytes.
 func (c *CreateSessionRequest) MarshalTo(b []byte) error {
-	if c.Header.Payload != nil {
-		c.Header.Payload = nil
+	var err error
+	c.Header.Payload, err = c.Header.payloadIn(b, c.MarshalLen())
+	if err != nil {
+		return err
 	}
-	c.Header.Payload = make([]byte, c.MarshalLen()-c.Header.MarshalLen())
 
 	offset := 0
 	if ie := c.IMSI; ie != nil {
@@ -400,13 +411,19 @@ func (c *CreateSessionRequest) MarshalTo(b []byte) error {
 		}
 		offset += ie.MarshalLen()
 	}
-	if ie := c.BearerContextsToBeCreated; ie != nil {
+	for _, ie := range c.BearerContextsToBeCreated {
+		if ie == nil {
+			continue
+		}
 		if err := ie.MarshalTo(c.Payload[offset:]); err != nil {
 			return err
 		}
 		offset += ie.MarshalLen()
 	}
-	if ie := c.BearerContextsToBeRemoved; ie != nil {
+	for _, ie := range c.BearerContextsToBeRemoved {
+		if ie == nil {
+			continue
+		}
 		if err := ie.MarshalTo(c.Payload[offset:]); err != nil {
 			return err
 		}
@@ -777,9 +794,9 @@ func (c *CreateSessionRequest) UnmarshalBinary(b []byte) error {
 		case ie.BearerContext:
 			switch i.Instance() {
 			case 0:
-				c.BearerContextsToBeCreated = i
+				c.BearerContextsToBeCreated = append(c.BearerContextsToBeCreated, i)
 			case 1:
-				c.BearerContextsToBeRemoved = i
+				c.BearerContextsToBeRemoved = append(c.BearerContextsToBeRemoved, i)
 			default:
 				c.AdditionalIEs = append(c.AdditionalIEs, i)
 			}
@@ -967,10 +984,16 @@ func (c *CreateSessionRequest) MarshalLen() int {
 	if ie := c.PCO; ie != nil {
 		l += ie.MarshalLen()
 	}
-	if ie := c.BearerContextsToBeCreated; ie != nil {
+	for _, ie := range c.BearerContextsToBeCreated {
+		if ie == nil {
+			continue
+		}
 		l += ie.MarshalLen()
 	}
-	if ie := c.BearerContextsToBeRemoved; ie != nil {
+	for _, ie := range c.BearerContextsToBeRemoved {
+		if ie == nil {
+			continue
+		}
 		l += ie.MarshalLen()
 	}
 	if ie := c.TraceInformation; ie != nil {
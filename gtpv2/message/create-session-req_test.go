@@ -98,3 +98,50 @@ func TestCreateSessionRequest(t *testing.T) {
 		return v, nil
 	})
 }
+
+func TestCreateSessionRequestMultipleBearerContexts(t *testing.T) {
+	b, err := message.NewCreateSessionRequest(
+		testutils.TestBearerInfo.TEID, testutils.TestBearerInfo.Seq,
+		ie.NewIMSI("123451234567890"),
+		ie.NewBearerContext(
+			ie.NewEPSBearerID(5),
+			ie.NewFullyQualifiedTEID(v2.IFTypeS1UeNodeBGTPU, 0x11111111, "1.1.1.1", ""),
+		),
+		ie.NewBearerContext(
+			ie.NewEPSBearerID(6),
+			ie.NewFullyQualifiedTEID(v2.IFTypeS1UeNodeBGTPU, 0x22222222, "1.1.1.1", ""),
+			ie.NewFullyQualifiedTEID(v2.IFTypeS5S8SGWGTPU, 0x33333333, "1.1.1.2", "").WithInstance(1),
+		),
+		ie.NewBearerContext(ie.NewEPSBearerID(7)).WithInstance(1),
+	).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	csReq, err := message.ParseCreateSessionRequest(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(csReq.BearerContextsToBeCreated), 2; got != want {
+		t.Fatalf("wrong number of BearerContextsToBeCreated: got %d, want %d", got, want)
+	}
+	if got, want := len(csReq.BearerContextsToBeRemoved), 1; got != want {
+		t.Fatalf("wrong number of BearerContextsToBeRemoved: got %d, want %d", got, want)
+	}
+
+	brCtx, err := ie.FindByEBI(csReq.BearerContextsToBeCreated, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fteid, err := brCtx.FindByType(ie.FullyQualifiedTEID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fteid.MustTEID(), uint32(0x33333333); got != want {
+		t.Errorf("wrong TEID: got %#x, want %#x", got, want)
+	}
+
+	if _, err := ie.FindByEBI(csReq.BearerContextsToBeCreated, 7); err == nil {
+		t.Error("EBI 7 should not be found in BearerContextsToBeCreated")
+	}
+}
@@ -22,8 +22,8 @@ type CreateSessionResponse struct {
 	AMBR                          *ie.IE
 	EBI                           *ie.IE
 	PCO                           *ie.IE
-	BearerContextsCreated         *ie.IE
-	BearerContextMarkedForRemoval *ie.IE
+	BearerContextsCreated         []*ie.IE
+	BearerContextMarkedForRemoval []*ie.IE
 	Recovery                      *ie.IE
 	ChargingGatewayName           *ie.IE // = PGWNodeName
 	ChargingGatewayAddress        *ie.IE
@@ -44,6 +44,8 @@ type CreateSessionResponse struct {
 	NBIFOMContainer               *ie.IE
 	PDNConnectionChargingID       *ie.IE
 	EPCO                          *ie.IE
+	SGiPtPTunnelAddress           *ie.IE
+	PGWChangeInfo                 *ie.IE
 	PrivateExtension              *ie.IE
 	AdditionalIEs                 []*ie.IE
 }
@@ -91,9 +93,9 @@ func NewCreateSessionResponse(teid, seq uint32, IEs ...*ie.IE) *CreateSessionRes
 		case ie.BearerContext:
 			switch i.Instance() {
 			case 0:
-				c.BearerContextsCreated = i
+				c.BearerContextsCreated = append(c.BearerContextsCreated, i)
 			case 1:
-				c.BearerContextMarkedForRemoval = i
+				c.BearerContextMarkedForRemoval = append(c.BearerContextMarkedForRemoval, i)
 			default:
 				c.AdditionalIEs = append(c.AdditionalIEs, i)
 			}
@@ -157,6 +159,10 @@ func NewCreateSessionResponse(teid, seq uint32, IEs ...*ie.IE) *CreateSessionRes
 			c.PDNConnectionChargingID = i
 		case ie.ExtendedProtocolConfigurationOptions:
 			c.EPCO = i
+		case ie.SGiPtPTunnelAddress:
+			c.SGiPtPTunnelAddress = i
+		case ie.PGWChangeInfo:
+			c.PGWChangeInfo = i
 		case ie.PrivateExtension:
 			c.PrivateExtension = i
 		default:
@@ -177,12 +183,23 @@ func (c *CreateSessionResponse) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from CreateSessionResponse to b and returns the extended buffer.
+func (c *CreateSessionResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, c)
+}
+
+// Clone returns a deep copy of CreateSessionResponse.
+func (c *CreateSessionResponse) Clone() *CreateSessionResponse {
+	return Clone(c).(*CreateSessionResponse)
+}
+
 // MarshalTo serializes CreateSessionResponse into bytes.
 func (c *CreateSessionResponse) MarshalTo(b []byte) error {
-	if c.Header.Payload != nil {
-		c.Header.Payload = nil
+	var err error
+	c.Header.Payload, err = c.Header.payloadIn(b, c.MarshalLen())
+	if err != nil {
+		return err
 	}
-	c.Header.Payload = make([]byte, c.MarshalLen()-c.Header.MarshalLen())
 
 	offset := 0
 	if ie := c.Cause; ie != nil {
@@ -251,13 +268,19 @@ func (c *CreateSessionResponse) MarshalTo(b []byte) error {
 		}
 		offset += ie.MarshalLen()
 	}
-	if ie := c.BearerContextsCreated; ie != nil {
+	for _, ie := range c.BearerContextsCreated {
+		if ie == nil {
+			continue
+		}
 		if err := ie.MarshalTo(c.Payload[offset:]); err != nil {
 			return err
 		}
 		offset += ie.MarshalLen()
 	}
-	if ie := c.BearerContextMarkedForRemoval; ie != nil {
+	for _, ie := range c.BearerContextMarkedForRemoval {
+		if ie == nil {
+			continue
+		}
 		if err := ie.MarshalTo(c.Payload[offset:]); err != nil {
 			return err
 		}
@@ -383,6 +406,18 @@ func (c *CreateSessionResponse) MarshalTo(b []byte) error {
 		}
 		offset += ie.MarshalLen()
 	}
+	if ie := c.SGiPtPTunnelAddress; ie != nil {
+		if err := ie.MarshalTo(c.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+	if ie := c.PGWChangeInfo; ie != nil {
+		if err := ie.MarshalTo(c.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
 	if ie := c.PrivateExtension; ie != nil {
 		if err := ie.MarshalTo(c.Payload[offset:]); err != nil {
 			return err
@@ -464,9 +499,9 @@ func (c *CreateSessionResponse) UnmarshalBinary(b []byte) error {
 		case ie.BearerContext:
 			switch i.Instance() {
 			case 0:
-				c.BearerContextsCreated = i
+				c.BearerContextsCreated = append(c.BearerContextsCreated, i)
 			case 1:
-				c.BearerContextMarkedForRemoval = i
+				c.BearerContextMarkedForRemoval = append(c.BearerContextMarkedForRemoval, i)
 			default:
 				c.AdditionalIEs = append(c.AdditionalIEs, i)
 			}
@@ -530,6 +565,10 @@ func (c *CreateSessionResponse) UnmarshalBinary(b []byte) error {
 			c.PDNConnectionChargingID = i
 		case ie.ExtendedProtocolConfigurationOptions:
 			c.EPCO = i
+		case ie.SGiPtPTunnelAddress:
+			c.SGiPtPTunnelAddress = i
+		case ie.PGWChangeInfo:
+			c.PGWChangeInfo = i
 		case ie.PrivateExtension:
 			c.PrivateExtension = i
 		default:
@@ -577,10 +616,16 @@ func (c *CreateSessionResponse) MarshalLen() int {
 	if ie := c.PCO; ie != nil {
 		l += ie.MarshalLen()
 	}
-	if ie := c.BearerContextsCreated; ie != nil {
+	for _, ie := range c.BearerContextsCreated {
+		if ie == nil {
+			continue
+		}
 		l += ie.MarshalLen()
 	}
-	if ie := c.BearerContextMarkedForRemoval; ie != nil {
+	for _, ie := range c.BearerContextMarkedForRemoval {
+		if ie == nil {
+			continue
+		}
 		l += ie.MarshalLen()
 	}
 	if ie := c.Recovery; ie != nil {
@@ -643,6 +688,12 @@ func (c *CreateSessionResponse) MarshalLen() int {
 	if ie := c.EPCO; ie != nil {
 		l += ie.MarshalLen()
 	}
+	if ie := c.SGiPtPTunnelAddress; ie != nil {
+		l += ie.MarshalLen()
+	}
+	if ie := c.PGWChangeInfo; ie != nil {
+		l += ie.MarshalLen()
+	}
 	if ie := c.PrivateExtension; ie != nil {
 		l += ie.MarshalLen()
 	}
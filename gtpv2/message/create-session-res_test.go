@@ -63,6 +63,31 @@ func TestCreateSessionResponse(t *testing.T) {
 				// ChargingID
 				0x5e, 0x00, 0x04, 0x00, 0x00, 0x00, 0x00, 0x01,
 			},
+		}, {
+			Description: "Normal/FromPGWCSMF",
+			Structured: message.NewCreateSessionResponse(
+				testutils.TestBearerInfo.TEID, testutils.TestBearerInfo.Seq,
+				ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+				ie.NewSGiPtPTunnelAddress("10.0.0.1", "", 8080),
+				ie.NewPGWChangeInfo(
+					ie.NewPGWFQDN("set1"),
+					ie.NewIPAddress("10.0.0.2"),
+				),
+			),
+			Serialized: []byte{
+				// Header
+				0x48, 0x21, 0x00, 0x2e, 0x11, 0x22, 0x33, 0x44, 0x00, 0x00, 0x01, 0x00,
+				// Cause
+				0x02, 0x00, 0x02, 0x00, 0x10, 0x00,
+				// SGi PtP Tunnel Address
+				0xd5, 0x00, 0x07, 0x00, 0x05, 0x0a, 0x00, 0x00, 0x01, 0x1f, 0x90,
+				// PGW Change Info
+				0xd6, 0x00, 0x11, 0x00,
+				//   PGW FQDN
+				0xd7, 0x00, 0x05, 0x00, 0x04, 0x73, 0x65, 0x74, 0x31,
+				//   IP Address
+				0x4a, 0x00, 0x04, 0x00, 0x0a, 0x00, 0x00, 0x02,
+			},
 		},
 	}
 
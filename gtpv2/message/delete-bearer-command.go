@@ -74,12 +74,23 @@ func (d *DeleteBearerCommand) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from DeleteBearerCommand to b and returns the extended buffer.
+func (d *DeleteBearerCommand) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, d)
+}
+
+// Clone returns a deep copy of DeleteBearerCommand.
+func (d *DeleteBearerCommand) Clone() *DeleteBearerCommand {
+	return Clone(d).(*DeleteBearerCommand)
+}
+
 // MarshalTo serializes DeleteBearerCommand into bytes.
 func (d *DeleteBearerCommand) MarshalTo(b []byte) error {
-	if d.Header.Payload != nil {
-		d.Header.Payload = nil
+	var err error
+	d.Header.Payload, err = d.Header.payloadIn(b, d.MarshalLen())
+	if err != nil {
+		return err
 	}
-	d.Header.Payload = make([]byte, d.MarshalLen()-d.Header.MarshalLen())
 
 	offset := 0
 	if ie := d.BearerContexts; ie != nil {
@@ -68,12 +68,23 @@ func (d *DeleteBearerFailureIndication) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from DeleteBearerFailureIndication to b and returns the extended buffer.
+func (d *DeleteBearerFailureIndication) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, d)
+}
+
+// Clone returns a deep copy of DeleteBearerFailureIndication.
+func (d *DeleteBearerFailureIndication) Clone() *DeleteBearerFailureIndication {
+	return Clone(d).(*DeleteBearerFailureIndication)
+}
+
 // MarshalTo serializes DeleteBearerFailureIndication into bytes.
 func (d *DeleteBearerFailureIndication) MarshalTo(b []byte) error {
-	if d.Header.Payload != nil {
-		d.Header.Payload = nil
+	var err error
+	d.Header.Payload, err = d.Header.payloadIn(b, d.MarshalLen())
+	if err != nil {
+		return err
 	}
-	d.Header.Payload = make([]byte, d.MarshalLen()-d.Header.MarshalLen())
 
 	offset := 0
 	if ie := d.Cause; ie != nil {
@@ -120,12 +120,23 @@ func (d *DeleteBearerRequest) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from DeleteBearerRequest to b and returns the extended buffer.
+func (d *DeleteBearerRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, d)
+}
+
+// Clone returns a deep copy of DeleteBearerRequest.
+func (d *DeleteBearerRequest) Clone() *DeleteBearerRequest {
+	return Clone(d).(*DeleteBearerRequest)
+}
+
 // MarshalTo serializes DeleteBearerRequest into bytes.
 func (d *DeleteBearerRequest) MarshalTo(b []byte) error {
-	if d.Header.Payload != nil {
-		d.Header.Payload = nil
+	var err error
+	d.Header.Payload, err = d.Header.payloadIn(b, d.MarshalLen())
+	if err != nil {
+		return err
 	}
-	d.Header.Payload = make([]byte, d.MarshalLen()-d.Header.MarshalLen())
 
 	offset := 0
 
@@ -154,12 +154,23 @@ func (d *DeleteBearerResponse) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from DeleteBearerResponse to b and returns the extended buffer.
+func (d *DeleteBearerResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, d)
+}
+
+// Clone returns a deep copy of DeleteBearerResponse.
+func (d *DeleteBearerResponse) Clone() *DeleteBearerResponse {
+	return Clone(d).(*DeleteBearerResponse)
+}
+
 // MarshalTo serializes DeleteBearerResponse into bytes.
 func (d *DeleteBearerResponse) MarshalTo(b []byte) error {
-	if d.Header.Payload != nil {
-		d.Header.Payload = nil
+	var err error
+	d.Header.Payload, err = d.Header.payloadIn(b, d.MarshalLen())
+	if err != nil {
+		return err
 	}
-	d.Header.Payload = make([]byte, d.MarshalLen()-d.Header.MarshalLen())
 
 	offset := 0
 	if ie := d.Cause; ie != nil {
@@ -0,0 +1,162 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+// DeleteIndirectDataForwardingTunnelRequest is a DeleteIndirectDataForwardingTunnelRequest Header and its IEs above.
+type DeleteIndirectDataForwardingTunnelRequest struct {
+	*Header
+	PrivateExtension *ie.IE
+	AdditionalIEs    []*ie.IE
+}
+
+// NewDeleteIndirectDataForwardingTunnelRequest creates a new DeleteIndirectDataForwardingTunnelRequest.
+func NewDeleteIndirectDataForwardingTunnelRequest(teid, seq uint32, IEs ...*ie.IE) *DeleteIndirectDataForwardingTunnelRequest {
+	m := &DeleteIndirectDataForwardingTunnelRequest{
+		Header: NewHeader(
+			NewHeaderFlags(2, 0, 1),
+			MsgTypeDeleteIndirectDataForwardingTunnelRequest, teid, seq, nil,
+		),
+	}
+
+	for _, i := range IEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.PrivateExtension:
+			m.PrivateExtension = i
+		default:
+			m.AdditionalIEs = append(m.AdditionalIEs, i)
+		}
+	}
+
+	m.SetLength()
+	return m
+}
+
+// Marshal returns the byte sequence generated from a DeleteIndirectDataForwardingTunnelRequest.
+func (m *DeleteIndirectDataForwardingTunnelRequest) Marshal() ([]byte, error) {
+	b := make([]byte, m.MarshalLen())
+	if err := m.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// AppendTo appends the byte sequence generated from DeleteIndirectDataForwardingTunnelRequest to b and returns the extended buffer.
+func (m *DeleteIndirectDataForwardingTunnelRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// Clone returns a deep copy of DeleteIndirectDataForwardingTunnelRequest.
+func (m *DeleteIndirectDataForwardingTunnelRequest) Clone() *DeleteIndirectDataForwardingTunnelRequest {
+	return Clone(m).(*DeleteIndirectDataForwardingTunnelRequest)
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+func (m *DeleteIndirectDataForwardingTunnelRequest) MarshalTo(b []byte) error {
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	if ie := m.PrivateExtension; ie != nil {
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	for _, ie := range m.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		if err := ie.MarshalTo(m.Header.Payload[offset:]); err != nil {
+			return err
+		}
+		offset += ie.MarshalLen()
+	}
+
+	m.Header.SetLength()
+	return m.Header.MarshalTo(b)
+}
+
+// ParseDeleteIndirectDataForwardingTunnelRequest decodes a given byte sequence as a DeleteIndirectDataForwardingTunnelRequest.
+func ParseDeleteIndirectDataForwardingTunnelRequest(b []byte) (*DeleteIndirectDataForwardingTunnelRequest, error) {
+	m := &DeleteIndirectDataForwardingTunnelRequest{}
+	if err := m.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// UnmarshalBinary decodes a given byte sequence as a DeleteIndirectDataForwardingTunnelRequest.
+func (m *DeleteIndirectDataForwardingTunnelRequest) UnmarshalBinary(b []byte) error {
+	var err error
+	m.Header, err = ParseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(m.Header.Payload) < 2 {
+		return nil
+	}
+
+	decodedIEs, err := ie.ParseMultiIEs(m.Header.Payload)
+	if err != nil {
+		return err
+	}
+	for _, i := range decodedIEs {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.PrivateExtension:
+			m.PrivateExtension = i
+		default:
+			m.AdditionalIEs = append(m.AdditionalIEs, i)
+		}
+	}
+
+	return nil
+}
+
+// MarshalLen returns the serial length of Data.
+func (m *DeleteIndirectDataForwardingTunnelRequest) MarshalLen() int {
+	l := m.Header.MarshalLen() - len(m.Header.Payload)
+
+	if ie := m.PrivateExtension; ie != nil {
+		l += ie.MarshalLen()
+	}
+
+	for _, ie := range m.AdditionalIEs {
+		if ie == nil {
+			continue
+		}
+		l += ie.MarshalLen()
+	}
+	return l
+}
+
+// SetLength sets the length in Length field.
+func (m *DeleteIndirectDataForwardingTunnelRequest) SetLength() {
+	m.Header.Length = uint16(m.MarshalLen() - 4)
+}
+
+// MessageTypeName returns the name of protocol.
+func (m *DeleteIndirectDataForwardingTunnelRequest) MessageTypeName() string {
+	return "Delete Indirect Data Forwarding Tunnel Request"
+}
+
+// TEID returns the TEID in uint32.
+func (m *DeleteIndirectDataForwardingTunnelRequest) TEID() uint32 {
+	return m.Header.teid()
+}
@@ -69,12 +69,23 @@ func (m *DeletePDNConnectionSetRequest) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from DeletePDNConnectionSetRequest to b and returns the extended buffer.
+func (m *DeletePDNConnectionSetRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// Clone returns a deep copy of DeletePDNConnectionSetRequest.
+func (m *DeletePDNConnectionSetRequest) Clone() *DeletePDNConnectionSetRequest {
+	return Clone(m).(*DeletePDNConnectionSetRequest)
+}
+
 // MarshalTo serializes DeletePDNConnectionSetRequest into bytes.
 func (m *DeletePDNConnectionSetRequest) MarshalTo(b []byte) error {
-	if m.Header.Payload != nil {
-		m.Header.Payload = nil
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
 	}
-	m.Header.Payload = make([]byte, m.MarshalLen()-m.Header.MarshalLen())
 
 	offset := 0
 	if ie := m.MMEFQCSID; ie != nil {
@@ -135,12 +135,23 @@ func (d *DeleteSessionRequest) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from DeleteSessionRequest to b and returns the extended buffer.
+func (d *DeleteSessionRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, d)
+}
+
+// Clone returns a deep copy of DeleteSessionRequest.
+func (d *DeleteSessionRequest) Clone() *DeleteSessionRequest {
+	return Clone(d).(*DeleteSessionRequest)
+}
+
 // MarshalTo serializes DeleteSessionRequest into bytes.
 func (d *DeleteSessionRequest) MarshalTo(b []byte) error {
-	if d.Header.Payload != nil {
-		d.Header.Payload = nil
+	var err error
+	d.Header.Payload, err = d.Header.payloadIn(b, d.MarshalLen())
+	if err != nil {
+		return err
 	}
-	d.Header.Payload = make([]byte, d.MarshalLen()-d.Header.MarshalLen())
 
 	offset := 0
 	if ie := d.Cause; ie != nil {
@@ -92,12 +92,23 @@ func (d *DeleteSessionResponse) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from DeleteSessionResponse to b and returns the extended buffer.
+func (d *DeleteSessionResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, d)
+}
+
+// Clone returns a deep copy of DeleteSessionResponse.
+func (d *DeleteSessionResponse) Clone() *DeleteSessionResponse {
+	return Clone(d).(*DeleteSessionResponse)
+}
+
 // MarshalTo serializes DeleteSessionResponse into bytes.
 func (d *DeleteSessionResponse) MarshalTo(b []byte) error {
-	if d.Header.Payload != nil {
-		d.Header.Payload = nil
+	var err error
+	d.Header.Payload, err = d.Header.payloadIn(b, d.MarshalLen())
+	if err != nil {
+		return err
 	}
-	d.Header.Payload = make([]byte, d.MarshalLen()-d.Header.MarshalLen())
 
 	offset := 0
 	if ie := d.Cause; ie != nil {
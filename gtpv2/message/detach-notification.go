@@ -55,12 +55,23 @@ func (m *DetachNotification) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from DetachNotification to b and returns the extended buffer.
+func (m *DetachNotification) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// Clone returns a deep copy of DetachNotification.
+func (m *DetachNotification) Clone() *DetachNotification {
+	return Clone(m).(*DetachNotification)
+}
+
 // MarshalTo serializes DetachNotification into bytes.
 func (m *DetachNotification) MarshalTo(b []byte) error {
-	if m.Header.Payload != nil {
-		m.Header.Payload = nil
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
 	}
-	m.Header.Payload = make([]byte, m.MarshalLen()-m.Header.MarshalLen())
 
 	offset := 0
 	if ie := m.Cause; ie != nil {
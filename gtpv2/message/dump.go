@@ -0,0 +1,314 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+// Dump returns the multi-line, hierarchical text dissection of a message, which
+// consists of the header fields and each IE with its decoded values. The IEs in
+// grouped IEs are shown under their parents.
+//
+// The values of the IEs that are not known or failed to decode are shown in hex.
+// This is intended for troubleshooting logs and CLI tools, and the format is not
+// stable.
+func Dump(m Message) string {
+	d := &dumper{}
+
+	b, err := Marshal(m)
+	if err != nil {
+		d.line(0, "%s: failed to serialize: %v", m.MessageTypeName(), err)
+		return d.String()
+	}
+	h, err := ParseHeader(b)
+	if err != nil {
+		d.line(0, "%s: failed to decode header: %v", m.MessageTypeName(), err)
+		return d.String()
+	}
+
+	d.line(0, "%s", m.MessageTypeName())
+	d.line(1, "Header")
+	d.line(2, "Version: %d", h.Version())
+	d.line(2, "Piggybacking: %v", h.IsPiggybacking())
+	d.line(2, "TEID Flag: %v", h.HasTEID())
+	d.line(2, "Message Type: %d", h.Type)
+	d.line(2, "Length: %d", h.Length)
+	if h.HasTEID() {
+		d.line(2, "TEID: 0x%08x", h.TEID)
+	}
+	d.line(2, "Sequence Number: 0x%06x", h.SequenceNumber)
+
+	ies, err := ie.ParseMultiIEs(h.Payload)
+	if err != nil {
+		d.line(1, "Malformed IEs: %v", err)
+		d.line(2, "Value: %x", h.Payload)
+		return d.String()
+	}
+	for _, i := range ies {
+		d.ie(1, i)
+	}
+	return d.String()
+}
+
+type dumper struct {
+	strings.Builder
+}
+
+func (d *dumper) line(depth int, format string, a ...interface{}) {
+	d.WriteString(strings.Repeat("    ", depth))
+	fmt.Fprintf(d, format, a...)
+	d.WriteString("\n")
+}
+
+func (d *dumper) ie(depth int, i *ie.IE) {
+	d.line(depth, "%s [Type: %d, Length: %d, Instance: %d]", i.Name(), i.Type, i.Length, i.Instance())
+
+	if i.IsGrouped() {
+		for _, c := range i.ChildIEs {
+			d.ie(depth+1, c)
+		}
+		return
+	}
+
+	fields, err := ieFields(i)
+	if err != nil {
+		d.line(depth+1, "Malformed: %v", err)
+	}
+	if err != nil || fields == nil {
+		d.line(depth+1, "Value: %x", i.Payload)
+		return
+	}
+	for _, f := range fields {
+		d.line(depth+1, "%s: %v", f.name, f.value)
+	}
+}
+
+type field struct {
+	name  string
+	value interface{}
+}
+
+// ieFields returns the decoded values of the IE, or nil if the type of IE is
+// not supported to be decoded.
+func ieFields(i *ie.IE) ([]field, error) {
+	switch i.Type {
+	case ie.IMSI:
+		v, err := i.IMSI()
+		return []field{{"IMSI", v}}, err
+	case ie.MSISDN:
+		v, err := i.MSISDN()
+		return []field{{"MSISDN", v}}, err
+	case ie.MobileEquipmentIdentity:
+		v, err := i.MobileEquipmentIdentity()
+		return []field{{"MEI", v}}, err
+	case ie.AccessPointName:
+		v, err := i.AccessPointName()
+		return []field{{"APN", v}}, err
+	case ie.FullyQualifiedDomainName:
+		v, err := i.FullyQualifiedDomainName()
+		return []field{{"FQDN", v}}, err
+	case ie.LocalDistinguishedName:
+		v, err := i.LocalDistinguishedName()
+		return []field{{"LDN", v}}, err
+	case ie.Cause:
+		v, err := i.Cause()
+		if err != nil {
+			return nil, err
+		}
+		return []field{
+			{"Cause", v},
+			{"PCE", i.HasPCE()},
+			{"BCE", i.HasBCE()},
+			{"CS", i.HasCS()},
+		}, nil
+	case ie.Recovery:
+		v, err := i.Recovery()
+		return []field{{"Restart Counter", v}}, err
+	case ie.EPSBearerID:
+		v, err := i.EPSBearerID()
+		return []field{{"EBI", v}}, err
+	case ie.AggregateMaximumBitRate:
+		v, err := i.AggregateMaximumBitRate()
+		if err != nil {
+			return nil, err
+		}
+		return []field{
+			{"APN-AMBR for Uplink", v.APNAMBRForUplink},
+			{"APN-AMBR for Downlink", v.APNAMBRForDownlink},
+		}, nil
+	case ie.BearerQoS:
+		v, err := i.BearerQoS()
+		if err != nil {
+			return nil, err
+		}
+		return []field{
+			{"PCI", v.ARP >> 6 & 0x01},
+			{"PL", v.ARP >> 2 & 0x0f},
+			{"PVI", v.ARP & 0x01},
+			{"QCI", v.QCI},
+			{"MBR for Uplink", v.MaximumBitRateForUplink},
+			{"MBR for Downlink", v.MaximumBitRateForDownlink},
+			{"GBR for Uplink", v.GuaranteedBitRateForUplink},
+			{"GBR for Downlink", v.GuaranteedBitRateForDownlink},
+		}, nil
+	case ie.RATType:
+		v, err := i.RATType()
+		return []field{{"RAT Type", v}}, err
+	case ie.ServingNetwork:
+		v, err := i.ServingNetwork()
+		return []field{{"PLMN", v}}, err
+	case ie.PLMNID:
+		v, err := i.PLMNID()
+		return []field{{"PLMN", v}}, err
+	case ie.PDNType:
+		v, err := i.PDNType()
+		return []field{{"PDN Type", v}}, err
+	case ie.PDNAddressAllocation:
+		t, err := i.PDNType()
+		if err != nil {
+			return nil, err
+		}
+		ip, err := i.IP()
+		return []field{{"PDN Type", t}, {"Address", ip}}, err
+	case ie.IPAddress:
+		v, err := i.IP()
+		return []field{{"Address", v}}, err
+	case ie.FullyQualifiedTEID:
+		v, err := i.FullyQualifiedTEID()
+		if err != nil {
+			return nil, err
+		}
+		fs := []field{
+			{"Interface Type", v.InterfaceType},
+			{"TEID/GRE Key", fmt.Sprintf("0x%08x", v.TEIDGREKey)},
+		}
+		if v.IPv4Address != nil {
+			fs = append(fs, field{"IPv4 Address", v.IPv4Address})
+		}
+		if v.IPv6Address != nil {
+			fs = append(fs, field{"IPv6 Address", v.IPv6Address})
+		}
+		return fs, nil
+	case ie.FullyQualifiedCSID:
+		v, err := i.FullyQualifiedCSID()
+		if err != nil {
+			return nil, err
+		}
+		return []field{
+			{"Node-ID Type", v.NodeIDType},
+			{"Node-ID", fmt.Sprintf("%x", v.NodeID)},
+			{"CSIDs", v.CSIDs},
+		}, nil
+	case ie.UserLocationInformation:
+		v, err := i.UserLocationInfo()
+		if err != nil {
+			return nil, err
+		}
+		return uliFields(v), nil
+	case ie.ChargingID:
+		v, err := i.ChargingID()
+		return []field{{"Charging ID", fmt.Sprintf("0x%08x", v)}}, err
+	case ie.ChargingCharacteristics:
+		v, err := i.ChargingCharacteristics()
+		return []field{{"Charging Characteristics", fmt.Sprintf("0x%04x", v)}}, err
+	case ie.SelectionMode:
+		v, err := i.SelectionMode()
+		return []field{{"Selection Mode", v}}, err
+	case ie.Indication:
+		v, err := i.Indication()
+		return []field{{"Flags", fmt.Sprintf("%x", v)}}, err
+	case ie.UETimeZone:
+		tz, err := i.TimeZone()
+		if err != nil {
+			return nil, err
+		}
+		ds, err := i.DaylightSaving()
+		return []field{{"Time Zone", tz}, {"Daylight Saving Time", ds}}, err
+	case ie.APNRestriction:
+		v, err := i.APNRestriction()
+		return []field{{"Restriction Type", v}}, err
+	case ie.ProcedureTransactionID:
+		v, err := i.ProcedureTransactionID()
+		return []field{{"PTI", v}}, err
+	case ie.NodeType:
+		v, err := i.NodeType()
+		return []field{{"Node Type", v}}, err
+	case ie.DetachType:
+		v, err := i.DetachType()
+		return []field{{"Detach Type", v}}, err
+	case ie.DelayValue:
+		v, err := i.DelayValue()
+		return []field{{"Delay Value", v}}, err
+	case ie.HopCounter:
+		v, err := i.HopCounter()
+		return []field{{"Hop Counter", v}}, err
+	case ie.PortNumber:
+		v, err := i.PortNumber()
+		return []field{{"Port Number", v}}, err
+	case ie.RFSPIndex:
+		v, err := i.RFSPIndex()
+		return []field{{"RFSP Index", v}}, err
+	case ie.ServiceIndicator:
+		v, err := i.ServiceIndicator()
+		return []field{{"Service Indicator", v}}, err
+	case ie.AllocationRetensionPriority:
+		v, err := i.AllocationRetensionPriority()
+		return []field{{"ARP", fmt.Sprintf("0x%02x", v)}}, err
+	case ie.BearerFlags:
+		v, err := i.BearerFlags()
+		return []field{{"Flags", fmt.Sprintf("0x%02x", v)}}, err
+	case ie.NodeFeatures:
+		v, err := i.NodeFeatures()
+		return []field{{"Supported Features", fmt.Sprintf("0x%02x", v)}}, err
+	case ie.TMSI:
+		v, err := i.TMSI()
+		return []field{{"TMSI", fmt.Sprintf("0x%08x", v)}}, err
+	case ie.PacketTMSI:
+		v, err := i.PacketTMSI()
+		return []field{{"P-TMSI", fmt.Sprintf("0x%08x", v)}}, err
+	case ie.PrivateExtension:
+		id, err := i.EnterpriseID()
+		if err != nil {
+			return nil, err
+		}
+		v, err := i.PrivateExtension()
+		return []field{{"Enterprise ID", id}, {"Value", fmt.Sprintf("%x", v)}}, err
+	default:
+		return nil, nil
+	}
+}
+
+func uliFields(v *ie.UserLocationInformationFields) []field {
+	var fs []field
+	if v.CGI != nil {
+		fs = append(fs, field{"CGI", fmt.Sprintf("MCC: %s, MNC: %s, LAC: 0x%04x, CI: 0x%04x", v.CGI.MCC, v.CGI.MNC, v.CGI.LAC, v.CGI.CI)})
+	}
+	if v.SAI != nil {
+		fs = append(fs, field{"SAI", fmt.Sprintf("MCC: %s, MNC: %s, LAC: 0x%04x, SAC: 0x%04x", v.SAI.MCC, v.SAI.MNC, v.SAI.LAC, v.SAI.SAC)})
+	}
+	if v.RAI != nil {
+		fs = append(fs, field{"RAI", fmt.Sprintf("MCC: %s, MNC: %s, LAC: 0x%04x, RAC: 0x%04x", v.RAI.MCC, v.RAI.MNC, v.RAI.LAC, v.RAI.RAC)})
+	}
+	if v.TAI != nil {
+		fs = append(fs, field{"TAI", fmt.Sprintf("MCC: %s, MNC: %s, TAC: 0x%04x", v.TAI.MCC, v.TAI.MNC, v.TAI.TAC)})
+	}
+	if v.ECGI != nil {
+		fs = append(fs, field{"ECGI", fmt.Sprintf("MCC: %s, MNC: %s, ECI: 0x%07x", v.ECGI.MCC, v.ECGI.MNC, v.ECGI.ECI)})
+	}
+	if v.LAI != nil {
+		fs = append(fs, field{"LAI", fmt.Sprintf("MCC: %s, MNC: %s, LAC: 0x%04x", v.LAI.MCC, v.LAI.MNC, v.LAI.LAC)})
+	}
+	if v.MENBI != nil {
+		fs = append(fs, field{"Macro eNodeB ID", fmt.Sprintf("MCC: %s, MNC: %s, ID: 0x%05x", v.MENBI.MCC, v.MENBI.MNC, v.MENBI.MENBI)})
+	}
+	if v.EMENBI != nil {
+		fs = append(fs, field{"Extended Macro eNodeB ID", fmt.Sprintf("MCC: %s, MNC: %s, ID: 0x%05x", v.EMENBI.MCC, v.EMENBI.MNC, v.EMENBI.EMENBI)})
+	}
+	return fs
+}
@@ -0,0 +1,75 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+func TestDump(t *testing.T) {
+	m := message.NewCreateSessionRequest(
+		0, 0x000001,
+		ie.NewIMSI("123451234567890"),
+		ie.NewFullyQualifiedTEID(v2.IFTypeS11MMEGTPC, 0x11111111, "1.1.1.1", ""),
+		ie.NewBearerContext(
+			ie.NewEPSBearerID(0x05),
+			ie.NewBearerQoS(1, 2, 1, 9, 0x1111111111, 0x2222222222, 0, 0),
+		),
+		ie.NewUserLocationInformationLazy("123", "45", -1, -1, -1, -1, 0x0001, 0x00000101, -1, -1),
+		ie.New(ie.TraceInformation, 0x00, []byte{0xde, 0xad, 0xbe, 0xef}),
+	)
+
+	want := `Create Session Request
+    Header
+        Version: 2
+        Piggybacking: false
+        TEID Flag: true
+        Message Type: 32
+        Length: 93
+        TEID: 0x00000000
+        Sequence Number: 0x000001
+    IMSI [Type: 1, Length: 8, Instance: 0]
+        IMSI: 123451234567890
+    User Location Information [Type: 86, Length: 13, Instance: 0]
+        TAI: MCC: 123, MNC: 45, TAC: 0x0001
+        ECGI: MCC: 123, MNC: 45, ECI: 0x0000101
+    F-TEID [Type: 87, Length: 9, Instance: 0]
+        Interface Type: 10
+        TEID/GRE Key: 0x11111111
+        IPv4 Address: 1.1.1.1
+    Bearer Context [Type: 93, Length: 31, Instance: 0]
+        EPS Bearer ID [Type: 73, Length: 1, Instance: 0]
+            EBI: 5
+        Bearer QoS [Type: 80, Length: 22, Instance: 0]
+            PCI: 1
+            PL: 2
+            PVI: 1
+            QCI: 9
+            MBR for Uplink: 73300775185
+            MBR for Downlink: 146601550370
+            GBR for Uplink: 0
+            GBR for Downlink: 0
+    Trace Information [Type: 96, Length: 4, Instance: 0]
+        Value: deadbeef
+`
+	if diff := cmp.Diff(want, message.Dump(m)); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestDumpMalformed(t *testing.T) {
+	m := message.NewCreateSessionRequest(0, 0, ie.New(ie.FullyQualifiedTEID, 0x00, []byte{0x0a}))
+
+	got := message.Dump(m)
+	if !strings.Contains(got, "F-TEID [Type: 87, Length: 1, Instance: 0]\n        Malformed: ") {
+		t.Errorf("malformed IE is not shown:\n%s", got)
+	}
+}
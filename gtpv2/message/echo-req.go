@@ -56,12 +56,23 @@ func (e *EchoRequest) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from EchoRequest to b and returns the extended buffer.
+func (e *EchoRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, e)
+}
+
+// Clone returns a deep copy of EchoRequest.
+func (e *EchoRequest) Clone() *EchoRequest {
+	return Clone(e).(*EchoRequest)
+}
+
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (e *EchoRequest) MarshalTo(b []byte) error {
-	if e.Header.Payload != nil {
-		e.Header.Payload = nil
+	var err error
+	e.Header.Payload, err = e.Header.payloadIn(b, e.MarshalLen())
+	if err != nil {
+		return err
 	}
-	e.Header.Payload = make([]byte, e.MarshalLen()-e.Header.MarshalLen())
 
 	offset := 0
 	if ie := e.Recovery; ie != nil {
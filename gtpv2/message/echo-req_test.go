@@ -5,6 +5,8 @@
 package message_test
 
 import (
+	"bytes"
+	"errors"
 	"testing"
 
 	"github.com/wmnsk/go-gtp/gtpv2/ie"
@@ -33,3 +35,39 @@ func TestEchoRequest(t *testing.T) {
 		return v, nil
 	})
 }
+
+func TestEchoRequestMarshalToShortBuffer(t *testing.T) {
+	m := message.NewEchoRequest(0, ie.NewRecovery(0x80))
+
+	b := make([]byte, m.MarshalLen()-1)
+	if err := m.MarshalTo(b); !errors.Is(err, message.ErrTooShortToMarshal) {
+		t.Errorf("got %v, want %v", err, message.ErrTooShortToMarshal)
+	}
+}
+
+func TestEchoRequestAppendTo(t *testing.T) {
+	m := message.NewEchoRequest(0, ie.NewRecovery(0x80))
+	want := []byte{
+		0xde, 0xad,
+		0x40, 0x01, 0x00, 0x09, 0x00, 0x00, 0x00, 0x00,
+		0x03, 0x00, 0x01, 0x00, 0x80,
+	}
+
+	b, err := m.AppendTo([]byte{0xde, 0xad})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, want) {
+		t.Errorf("got %x, want %x", b, want)
+	}
+
+	buf := make([]byte, 0, 64)
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := m.AppendTo(buf[:0]); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("AppendTo allocates %v times with the buffer large enough", allocs)
+	}
+}
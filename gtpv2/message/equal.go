@@ -0,0 +1,215 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+// Equal reports whether the two messages have the same values decoded.
+//
+// See Diff for how the messages are compared.
+func Equal(a, b Message) bool {
+	return len(diffMessages(a, b)) == 0
+}
+
+// Diff returns the differences between the values decoded in the two
+// messages in human readable format, or an empty string if they are equal.
+//
+// Each line starts with "-" for the value in a and "+" for the one in b,
+// followed by the name of the field. The IEs in the repeated fields and the
+// grouped IEs are named after their type and instance, e.g.,
+// "BearerContextsToBeCreated.Bearer Context(0).F-TEID(1)". The Length in the header and IEs and
+// the raw Payload of the header are not compared, as they are derived from
+// the IEs. The IEs that can be repeated in a field, such as the Bearer
+// Contexts or AdditionalIEs, and the IEs inside the grouped IEs are compared
+// regardless of their order, as TS 29.274 does not define the order of them.
+func Diff(a, b Message) string {
+	return strings.Join(diffMessages(a, b), "\n")
+}
+
+func diffMessages(a, b Message) []string {
+	if a == nil || b == nil || reflect.TypeOf(a) != reflect.TypeOf(b) {
+		if a == nil && b == nil {
+			return nil
+		}
+		return []string{fmt.Sprintf("-Message: %s", messageName(a)), fmt.Sprintf("+Message: %s", messageName(b))}
+	}
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Kind() != reflect.Ptr || va.Elem().Kind() != reflect.Struct || va.IsNil() || vb.IsNil() {
+		if reflect.DeepEqual(a, b) {
+			return nil
+		}
+		return []string{fmt.Sprintf("-Message: %v", a), fmt.Sprintf("+Message: %v", b)}
+	}
+	va, vb = va.Elem(), vb.Elem()
+
+	var diffs []string
+	for n := 0; n < va.NumField(); n++ {
+		name := va.Type().Field(n).Name
+		fa, fb := va.Field(n), vb.Field(n)
+		if !fa.CanInterface() {
+			continue
+		}
+
+		switch fa.Type() {
+		case headerType:
+			diffs = append(diffs, diffHeaders(fa.Interface().(*Header), fb.Interface().(*Header))...)
+		case ieType:
+			diffs = append(diffs, diffIEs(name, fa.Interface().(*ie.IE), fb.Interface().(*ie.IE))...)
+		case iesType:
+			diffs = append(diffs, diffIESets(name, fa.Interface().([]*ie.IE), fb.Interface().([]*ie.IE))...)
+		}
+	}
+	return diffs
+}
+
+func messageName(m Message) string {
+	if m == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%T", m)
+}
+
+func diffHeaders(a, b *Header) []string {
+	if a == nil || b == nil {
+		if a == b {
+			return nil
+		}
+		return []string{fmt.Sprintf("-Header: %v", a), fmt.Sprintf("+Header: %v", b)}
+	}
+
+	var diffs []string
+	add := func(name string, x, y interface{}) {
+		if x != y {
+			diffs = append(diffs, fmt.Sprintf("-Header.%s: %v", name, x), fmt.Sprintf("+Header.%s: %v", name, y))
+		}
+	}
+	add("Flags", a.Flags, b.Flags)
+	add("Type", a.Type, b.Type)
+	if a.HasTEID() && b.HasTEID() {
+		add("TEID", a.TEID, b.TEID)
+	}
+	add("SequenceNumber", a.SequenceNumber, b.SequenceNumber)
+	add("Spare", a.Spare, b.Spare)
+	return diffs
+}
+
+// diffIEs compares the two IEs in the field named name.
+func diffIEs(name string, a, b *ie.IE) []string {
+	if equalIEs(a, b) {
+		return nil
+	}
+	if a == nil || b == nil || a.Type != b.Type || a.Instance() != b.Instance() || !a.IsGrouped() {
+		return []string{fmt.Sprintf("-%s: %s", name, ieString(a)), fmt.Sprintf("+%s: %s", name, ieString(b))}
+	}
+
+	ca, erra := childIEs(a)
+	cb, errb := childIEs(b)
+	if erra != nil || errb != nil {
+		return []string{fmt.Sprintf("-%s: %s", name, ieString(a)), fmt.Sprintf("+%s: %s", name, ieString(b))}
+	}
+	return diffIESets(name, ca, cb)
+}
+
+// diffIESets compares the two sets of IEs in the field named name regardless
+// of the order. The unmatched IEs of the same type and instance are compared
+// with each other in order to show where they differ.
+func diffIESets(name string, a, b []*ie.IE) []string {
+	onlyA, onlyB := unmatchedIEs(a, b)
+
+	var diffs []string
+	for _, x := range onlyA {
+		path := fmt.Sprintf("%s.%s(%d)", name, ie.TypeName(x.Type), x.Instance())
+		paired := false
+		for n, y := range onlyB {
+			if y != nil && y.Type == x.Type && y.Instance() == x.Instance() {
+				diffs = append(diffs, diffIEs(path, x, y)...)
+				onlyB[n] = nil
+				paired = true
+				break
+			}
+		}
+		if !paired {
+			diffs = append(diffs, fmt.Sprintf("-%s: %s", path, ieString(x)))
+		}
+	}
+	for _, y := range onlyB {
+		if y == nil {
+			continue
+		}
+		diffs = append(diffs, fmt.Sprintf("+%s.%s(%d): %s", name, ie.TypeName(y.Type), y.Instance(), ieString(y)))
+	}
+	return diffs
+}
+
+// unmatchedIEs returns the non-nil IEs that have no equal one in the other.
+func unmatchedIEs(a, b []*ie.IE) (onlyA, onlyB []*ie.IE) {
+	matched := make([]bool, len(b))
+	for _, x := range a {
+		if x == nil {
+			continue
+		}
+		found := false
+		for n, y := range b {
+			if !matched[n] && equalIEs(x, y) {
+				matched[n] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			onlyA = append(onlyA, x)
+		}
+	}
+	for n, y := range b {
+		if y != nil && !matched[n] {
+			onlyB = append(onlyB, y)
+		}
+	}
+	return onlyA, onlyB
+}
+
+func equalIEs(a, b *ie.IE) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Type != b.Type || a.Instance() != b.Instance() {
+		return false
+	}
+	if !a.IsGrouped() {
+		return bytes.Equal(a.Payload, b.Payload)
+	}
+
+	ca, erra := childIEs(a)
+	cb, errb := childIEs(b)
+	if erra != nil || errb != nil {
+		return bytes.Equal(a.Payload, b.Payload)
+	}
+	onlyA, onlyB := unmatchedIEs(ca, cb)
+	return len(onlyA) == 0 && len(onlyB) == 0
+}
+
+// childIEs returns the IEs inside a grouped IE, decoding the Payload if they
+// are not decoded yet.
+func childIEs(i *ie.IE) ([]*ie.IE, error) {
+	if i.ChildIEs != nil || len(i.Payload) == 0 {
+		return i.ChildIEs, nil
+	}
+	return ie.ParseMultiIEs(i.Payload)
+}
+
+func ieString(i *ie.IE) string {
+	if i == nil {
+		return "<nil>"
+	}
+	return i.String()
+}
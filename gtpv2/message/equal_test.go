@@ -0,0 +1,111 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"testing"
+
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+func TestEqual(t *testing.T) {
+	newBearerContext := func(ebi uint8, teid uint32) *ie.IE {
+		return ie.NewBearerContext(
+			ie.NewEPSBearerID(ebi),
+			ie.NewFullyQualifiedTEID(v2.IFTypeS1UeNodeBGTPU, teid, "1.1.1.1", ""),
+		)
+	}
+	newCSReq := func(ies ...*ie.IE) *message.CreateSessionRequest {
+		ies = append([]*ie.IE{ie.NewIMSI("123451234567890")}, ies...)
+		return message.NewCreateSessionRequest(0x11223344, 1, ies...)
+	}
+
+	cases := []struct {
+		description string
+		a, b        message.Message
+		diff        string
+	}{
+		{
+			"Same",
+			newCSReq(newBearerContext(5, 1)),
+			newCSReq(newBearerContext(5, 1)),
+			"",
+		}, {
+			"BearerContexts/Reordered",
+			newCSReq(newBearerContext(5, 1), newBearerContext(6, 2)),
+			newCSReq(newBearerContext(6, 2), newBearerContext(5, 1)),
+			"",
+		}, {
+			"ChildIEs/Reordered",
+			newCSReq(ie.NewBearerContext(ie.NewEPSBearerID(5), ie.NewChargingID(1))),
+			newCSReq(ie.NewBearerContext(ie.NewChargingID(1), ie.NewEPSBearerID(5))),
+			"",
+		}, {
+			"Parsed",
+			newCSReq(newBearerContext(5, 1)),
+			mustParse(t, newCSReq(newBearerContext(5, 1))),
+			"",
+		}, {
+			"Header/Sequence",
+			message.NewEchoRequest(1, ie.NewRecovery(1)),
+			message.NewEchoRequest(2, ie.NewRecovery(1)),
+			"-Header.SequenceNumber: 1\n+Header.SequenceNumber: 2",
+		}, {
+			"IE/Value",
+			message.NewEchoRequest(1, ie.NewRecovery(1)),
+			message.NewEchoRequest(1, ie.NewRecovery(2)),
+			"-Recovery: {Type: 3, Length: 1, Instance: 0x0, Payload: []byte{0x1}}\n" +
+				"+Recovery: {Type: 3, Length: 1, Instance: 0x0, Payload: []byte{0x2}}",
+		}, {
+			"IE/Missing",
+			message.NewEchoRequest(1, ie.NewRecovery(1)),
+			message.NewEchoRequest(1),
+			"-Recovery: {Type: 3, Length: 1, Instance: 0x0, Payload: []byte{0x1}}\n+Recovery: <nil>",
+		}, {
+			"ChildIE/Value",
+			newCSReq(newBearerContext(5, 1)),
+			newCSReq(ie.NewBearerContext(ie.NewEPSBearerID(5), ie.NewFullyQualifiedTEID(v2.IFTypeS1UeNodeBGTPU, 1, "1.1.1.2", ""))),
+			"-BearerContextsToBeCreated.Bearer Context(0).F-TEID(0): {Type: 87, Length: 9, Instance: 0x0, Payload: []byte{0x80, 0x0, 0x0, 0x0, 0x1, 0x1, 0x1, 0x1, 0x1}}\n" +
+				"+BearerContextsToBeCreated.Bearer Context(0).F-TEID(0): {Type: 87, Length: 9, Instance: 0x0, Payload: []byte{0x80, 0x0, 0x0, 0x0, 0x1, 0x1, 0x1, 0x1, 0x2}}",
+		}, {
+			"BearerContexts/Extra",
+			newCSReq(newBearerContext(5, 1)),
+			newCSReq(newBearerContext(5, 1), ie.NewBearerContext(ie.NewEPSBearerID(6))),
+			"+BearerContextsToBeCreated.Bearer Context(0): {Type: 93, Length: 5, Instance: 0x0, Payload: []byte{0x49, 0x0, 0x1, 0x0, 0x6}}",
+		}, {
+			"Type",
+			message.NewEchoRequest(1),
+			message.NewEchoResponse(1),
+			"-Message: *message.EchoRequest\n+Message: *message.EchoResponse",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if got := message.Diff(c.a, c.b); got != c.diff {
+				t.Errorf("wrong diff:\ngot:\n%s\nwant:\n%s", got, c.diff)
+			}
+			if got, want := message.Equal(c.a, c.b), c.diff == ""; got != want {
+				t.Errorf("Equal returned %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func mustParse(t *testing.T, m message.Message) message.Message {
+	t.Helper()
+
+	b, err := message.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := message.Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
@@ -4,10 +4,31 @@
 
 package message
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Error definitions.
 var (
-	ErrInvalidLength   = errors.New("length value is invalid")
-	ErrTooShortToParse = errors.New("too short to decode as GTP")
+	ErrInvalidLength     = errors.New("length value is invalid")
+	ErrTooShortToMarshal = errors.New("too short to serialize")
+	ErrTooShortToParse   = errors.New("too short to decode as GTP")
 )
+
+// ParseError indicates that a message failed to be decoded. Err is the cause,
+// e.g., *ie.InvalidLengthError with the type of IE that is malformed.
+type ParseError struct {
+	MessageType uint8
+	Err         error
+}
+
+// Error returns the type of message and the reason.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("failed to decode GTPv2 Message (type %d): %v", e.MessageType, e.Err)
+}
+
+// Unwrap returns the cause of the error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
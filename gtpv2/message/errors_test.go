@@ -0,0 +1,39 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+func TestParseError(t *testing.T) {
+	b := []byte{
+		0x40, 0x01, 0x00, 0x09, 0x00, 0x00, 0x01, 0x00,
+		// Recovery with Length longer than the rest
+		0x03, 0x00, 0x05, 0x00, 0x80,
+	}
+
+	_, err := message.Parse(b)
+
+	var perr *message.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("got %v, want *ParseError", err)
+	}
+	if perr.MessageType != message.MsgTypeEchoRequest {
+		t.Errorf("wrong MessageType: got %d, want %d", perr.MessageType, message.MsgTypeEchoRequest)
+	}
+
+	var lerr *ie.InvalidLengthError
+	if !errors.As(err, &lerr) || lerr.Type != ie.Recovery {
+		t.Errorf("got %v, want *ie.InvalidLengthError of Recovery", err)
+	}
+	if !errors.Is(err, ie.ErrInvalidLength) {
+		t.Errorf("%v does not match ie.ErrInvalidLength", err)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package message_test
+
+import (
+	"testing"
+	"time"
+
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+	"github.com/wmnsk/go-gtp/gtpv2/testutils"
+)
+
+// fuzzIEs is the set of IEs put in every message used as a seed so that
+// the per-message IE dispatching gets exercised for all the known types.
+var fuzzIEs = []*ie.IE{
+	ie.NewIMSI("123451234567890"),
+	ie.NewMSISDN("123450123456789"),
+	ie.NewMobileEquipmentIdentity("123450123456789"),
+	ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+	ie.NewRecovery(0x80),
+	ie.NewAccessPointName("some.apn.example"),
+	ie.NewFullyQualifiedTEID(v2.IFTypeS11MMEGTPC, 0xffffffff, "1.1.1.1", ""),
+	ie.NewFullyQualifiedTEID(v2.IFTypeS5S8PGWGTPC, 0xffffffff, "1.1.1.2", "").WithInstance(1),
+	ie.NewPDNType(v2.PDNTypeIPv4),
+	ie.NewPDNAddressAllocation("2.2.2.2"),
+	ie.NewAggregateMaximumBitRate(0x11111111, 0x22222222),
+	ie.NewIndicationFromOctets(0xa1, 0x08, 0x15, 0x10, 0x88, 0x81, 0x40),
+	ie.NewEPSBearerID(0x05),
+	ie.NewBearerContext(
+		ie.NewEPSBearerID(0x05),
+		ie.NewFullyQualifiedTEID(v2.IFTypeS1USGWGTPU, 0xffffffff, "1.1.1.3", ""),
+		ie.NewBearerQoS(1, 2, 1, 0xff, 0x1111111111, 0x2222222222, 0x1111111111, 0x2222222222),
+		ie.NewCause(v2.CauseRequestAccepted, 0, 0, 0, nil),
+	),
+	ie.NewServingNetwork("123", "45"),
+	ie.NewUserLocationInformationLazy("123", "45", -1, -1, -1, -1, 0x0001, 0x00000101, -1, -1),
+	ie.NewRATType(v2.RATTypeEUTRAN),
+	ie.NewSelectionMode(v2.SelectionModeMSorNetworkProvidedAPNSubscribedVerified),
+	ie.NewAPNRestriction(v2.APNRestrictionPublic1),
+	ie.NewChargingID(0xffffffff),
+	ie.NewDelayValue(500 * time.Millisecond),
+}
+
+func FuzzParse(f *testing.F) {
+	for typ := 1; typ <= 0xff; typ++ {
+		b, err := message.Marshal(message.NewGeneric(
+			uint8(typ), testutils.TestBearerInfo.TEID, testutils.TestBearerInfo.Seq, fuzzIEs...,
+		))
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		// Seed only the types that have their own implementation.
+		m, err := message.Parse(b)
+		if err != nil {
+			continue
+		}
+		if _, ok := m.(*message.Generic); ok {
+			continue
+		}
+		f.Add(b)
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		m, err := message.Parse(b)
+		if err != nil {
+			return
+		}
+
+		if _, err := message.Marshal(m); err != nil {
+			t.Fatalf("failed to marshal parsed message: %v", err)
+		}
+		_ = message.Dump(m)
+	})
+}
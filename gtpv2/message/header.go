@@ -44,6 +44,19 @@ func NewHeaderFlags(v, p, t int) uint8 {
 	)
 }
 
+// Clone returns a deep copy of the Header, including its Payload.
+func (h *Header) Clone() *Header {
+	if h == nil {
+		return nil
+	}
+
+	c := *h
+	if h.Payload != nil {
+		c.Payload = append(make([]byte, 0, len(h.Payload)), h.Payload...)
+	}
+	return &c
+}
+
 // Marshal returns the byte sequence generated from a Header instance.
 func (h *Header) Marshal() ([]byte, error) {
 	b := make([]byte, h.MarshalLen())
@@ -70,6 +83,20 @@ func (h *Header) MarshalTo(b []byte) error {
 	return nil
 }
 
+// payloadIn returns the part of b that the payload of a message of length l
+// occupies, so that the IEs are marshaled directly into b without allocating
+// an intermediate buffer. The Payload of the Header refers to b after that.
+func (h *Header) payloadIn(b []byte, l int) ([]byte, error) {
+	offset := 8
+	if h.HasTEID() {
+		offset += 4
+	}
+	if len(b) < l || l < offset {
+		return nil, ErrTooShortToMarshal
+	}
+	return b[offset:l], nil
+}
+
 // ParseHeader decodes given byte sequence as a GTPv2 header.
 func ParseHeader(b []byte) (*Header, error) {
 	h := &Header{}
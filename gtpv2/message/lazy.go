@@ -0,0 +1,115 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"encoding/binary"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+// Lazy is a GTPv2 message parsed lazily.
+//
+// ParseLazy decodes only the header, and the IEs are looked up in the given
+// bytes when they are accessed. The IEs returned refer to the sub-slices of
+// the bytes without copying, so the bytes must not be modified while Lazy
+// and the IEs are in use.
+//
+// This is useful for proxies and relays that inspect only a few IEs before
+// forwarding the message as it is. Use Decode to get the fully decoded
+// Message when needed.
+type Lazy struct {
+	*Header
+	raw []byte
+	msg Message
+}
+
+// ParseLazy decodes the header of given bytes and returns Lazy.
+//
+// The IEs are not validated until they are accessed.
+func ParseLazy(b []byte) (*Lazy, error) {
+	h, err := ParseHeader(b)
+	if err != nil {
+		return nil, err
+	}
+	return &Lazy{Header: h, raw: b}, nil
+}
+
+// Raw returns the bytes Lazy is parsed from.
+func (l *Lazy) Raw() []byte {
+	return l.raw
+}
+
+// TEID returns the TEID in uint32.
+func (l *Lazy) TEID() uint32 {
+	return l.Header.teid()
+}
+
+// IE returns the first IE that matches the type and instance given.
+//
+// It returns *ie.IENotFoundError if no IE matches, or the error in decoding the
+// IEs in front of the one looked up.
+func (l *Lazy) IE(typ, instance uint8) (*ie.IE, error) {
+	var found []byte
+	err := l.walk(func(b []byte) bool {
+		if b[0] == typ && b[3]&0x0f == instance {
+			found = b
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, &ie.IENotFoundError{Type: typ, Instance: instance}
+	}
+	return ie.Parse(found)
+}
+
+// Walk calls fn with the type, instance and payload of each IE in order
+// without decoding them, until fn returns false.
+//
+// The payload refers to the bytes Lazy is parsed from.
+func (l *Lazy) Walk(fn func(typ, instance uint8, payload []byte) bool) error {
+	return l.walk(func(b []byte) bool {
+		return fn(b[0], b[3]&0x0f, b[4:])
+	})
+}
+
+// walk calls fn with each IE including its header in bytes.
+func (l *Lazy) walk(fn func(b []byte) bool) error {
+	b := l.Header.Payload
+	for len(b) > 0 {
+		if len(b) < 4 {
+			return ie.ErrTooShortToParse
+		}
+		n := 4 + int(binary.BigEndian.Uint16(b[1:3]))
+		if n > len(b) {
+			return &ie.InvalidLengthError{Type: b[0], Got: len(b) - 4, Want: n - 4}
+		}
+		if !fn(b[:n]) {
+			return nil
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// Decode decodes the whole message and returns it as Message.
+//
+// The result is cached, so Decode can be called multiple times at no cost.
+func (l *Lazy) Decode() (Message, error) {
+	if l.msg != nil {
+		return l.msg, nil
+	}
+
+	m, err := Parse(l.raw)
+	if err != nil {
+		return nil, err
+	}
+	l.msg = m
+	return m, nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"errors"
+	"testing"
+
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+func TestLazy(t *testing.T) {
+	b, err := newBenchCreateSessionRequest().Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := message.ParseLazy(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := l.MessageType(), message.MsgTypeCreateSessionRequest; got != want {
+		t.Errorf("wrong MessageType: got %d, want %d", got, want)
+	}
+	if got, want := l.TEID(), uint32(0x11223344); got != want {
+		t.Errorf("wrong TEID: got %#x, want %#x", got, want)
+	}
+
+	t.Run("IE", func(t *testing.T) {
+		i, err := l.IE(ie.IMSI, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := i.MustIMSI(), "123451234567890"; got != want {
+			t.Errorf("wrong IMSI: got %s, want %s", got, want)
+		}
+
+		i, err = l.IE(ie.FullyQualifiedTEID, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := i.MustInterfaceType(), uint8(v2.IFTypeS5S8PGWGTPC); got != want {
+			t.Errorf("wrong InterfaceType: got %d, want %d", got, want)
+		}
+
+		if _, err := l.IE(ie.FullyQualifiedTEID, 2); !errors.Is(err, ie.ErrIENotFound) {
+			t.Errorf("got %v, want %v", err, ie.ErrIENotFound)
+		}
+	})
+
+	t.Run("Walk", func(t *testing.T) {
+		var types []uint8
+		if err := l.Walk(func(typ, instance uint8, payload []byte) bool {
+			types = append(types, typ)
+			return typ != ie.FullyQualifiedTEID
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		want := []uint8{
+			ie.IMSI, ie.MSISDN, ie.MobileEquipmentIdentity, ie.UserLocationInformation,
+			ie.ServingNetwork, ie.RATType, ie.Indication, ie.FullyQualifiedTEID,
+		}
+		if len(types) != len(want) {
+			t.Fatalf("wrong IEs walked: got %v, want %v", types, want)
+		}
+		for n := range want {
+			if types[n] != want[n] {
+				t.Errorf("wrong IEs walked: got %v, want %v", types, want)
+			}
+		}
+	})
+
+	t.Run("Decode", func(t *testing.T) {
+		m, err := l.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		csr, ok := m.(*message.CreateSessionRequest)
+		if !ok {
+			t.Fatalf("wrong type: %T", m)
+		}
+		if got, want := csr.APN.MustAccessPointName(), "some.apn.example"; got != want {
+			t.Errorf("wrong APN: got %s, want %s", got, want)
+		}
+	})
+}
+
+func TestLazyMalformed(t *testing.T) {
+	b := []byte{
+		0x40, 0x01, 0x00, 0x0c, 0x00, 0x00, 0x01, 0x00,
+		// Recovery
+		0x03, 0x00, 0x01, 0x00, 0x80,
+		// truncated IE
+		0x47, 0x00, 0x10,
+	}
+
+	l, err := message.ParseLazy(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the IEs in front of the malformed one can be accessed.
+	if _, err := l.IE(ie.Recovery, 0); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := l.IE(ie.AccessPointName, 0); !errors.Is(err, ie.ErrTooShortToParse) {
+		t.Errorf("got %v, want %v", err, ie.ErrTooShortToParse)
+	}
+}
@@ -7,9 +7,7 @@ Package message provides encoding/decoding feature of GTPv2 protocol.
 */
 package message
 
-import (
-	"github.com/pkg/errors"
-)
+import "github.com/wmnsk/go-gtp/utils"
 
 // Message Type definitions.
 const (
@@ -300,8 +298,25 @@ func Marshal(m Message) ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from a Message instance to b
+// and returns the extended buffer. b is reallocated only when its capacity is
+// not enough, so the buffers can be reused without allocating.
+func AppendTo(b []byte, m Message) ([]byte, error) {
+	l := len(b)
+	b = utils.Grow(b, m.MarshalLen())
+	if err := m.MarshalTo(b[l:]); err != nil {
+		return b[:l], err
+	}
+
+	return b, nil
+}
+
 // Parse decodes the given bytes as Message.
 func Parse(b []byte) (Message, error) {
+	if len(b) < 2 {
+		return nil, ErrTooShortToParse
+	}
+
 	var m Message
 
 	switch b[1] {
@@ -371,12 +386,20 @@ func Parse(b []byte) (Message, error) {
 		m = &DetachNotification{}
 	case MsgTypeDetachAcknowledge:
 		m = &DetachAcknowledge{}
+	case MsgTypeCreateIndirectDataForwardingTunnelRequest:
+		m = &CreateIndirectDataForwardingTunnelRequest{}
+	case MsgTypeCreateIndirectDataForwardingTunnelResponse:
+		m = &CreateIndirectDataForwardingTunnelResponse{}
+	case MsgTypeDeleteIndirectDataForwardingTunnelRequest:
+		m = &DeleteIndirectDataForwardingTunnelRequest{}
+	case MsgTypeDeleteIndirectDataForwardingTunnelResponse:
+		m = &DeleteIndirectDataForwardingTunnelResponse{}
 	default:
 		m = &Generic{}
 	}
 
 	if err := m.UnmarshalBinary(b); err != nil {
-		return nil, errors.Wrap(err, "failed to decode GTPv2 Message")
+		return nil, &ParseError{MessageType: b[1], Err: err}
 	}
 	return m, nil
 }
@@ -71,12 +71,23 @@ func (m *ModifyAccessBearersRequest) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from ModifyAccessBearersRequest to b and returns the extended buffer.
+func (m *ModifyAccessBearersRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// Clone returns a deep copy of ModifyAccessBearersRequest.
+func (m *ModifyAccessBearersRequest) Clone() *ModifyAccessBearersRequest {
+	return Clone(m).(*ModifyAccessBearersRequest)
+}
+
 // MarshalTo serializes ModifyAccessBearersRequest into bytes.
 func (m *ModifyAccessBearersRequest) MarshalTo(b []byte) error {
-	if m.Header.Payload != nil {
-		m.Header.Payload = nil
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
 	}
-	m.Header.Payload = make([]byte, m.MarshalLen()-m.Header.MarshalLen())
 
 	offset := 0
 	if ie := m.IndicationFlags; ie != nil {
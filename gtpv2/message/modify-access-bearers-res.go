@@ -71,12 +71,23 @@ func (m *ModifyAccessBearersResponse) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from ModifyAccessBearersResponse to b and returns the extended buffer.
+func (m *ModifyAccessBearersResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// Clone returns a deep copy of ModifyAccessBearersResponse.
+func (m *ModifyAccessBearersResponse) Clone() *ModifyAccessBearersResponse {
+	return Clone(m).(*ModifyAccessBearersResponse)
+}
+
 // MarshalTo serializes ModifyAccessBearersResponse into bytes.
 func (m *ModifyAccessBearersResponse) MarshalTo(b []byte) error {
-	if m.Header.Payload != nil {
-		m.Header.Payload = nil
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
 	}
-	m.Header.Payload = make([]byte, m.MarshalLen()-m.Header.MarshalLen())
 
 	offset := 0
 	if ie := m.Cause; ie != nil {
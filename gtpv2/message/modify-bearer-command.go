@@ -68,12 +68,23 @@ func (m *ModifyBearerCommand) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from ModifyBearerCommand to b and returns the extended buffer.
+func (m *ModifyBearerCommand) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// Clone returns a deep copy of ModifyBearerCommand.
+func (m *ModifyBearerCommand) Clone() *ModifyBearerCommand {
+	return Clone(m).(*ModifyBearerCommand)
+}
+
 // MarshalTo serializes ModifyBearerCommand into bytes.
 func (m *ModifyBearerCommand) MarshalTo(b []byte) error {
-	if m.Header.Payload != nil {
-		m.Header.Payload = nil
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
 	}
-	m.Header.Payload = make([]byte, m.MarshalLen()-m.Header.MarshalLen())
 
 	offset := 0
 	if ie := m.APNAMBR; ie != nil {
@@ -65,12 +65,23 @@ func (m *ModifyBearerFailureIndication) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from ModifyBearerFailureIndication to b and returns the extended buffer.
+func (m *ModifyBearerFailureIndication) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// Clone returns a deep copy of ModifyBearerFailureIndication.
+func (m *ModifyBearerFailureIndication) Clone() *ModifyBearerFailureIndication {
+	return Clone(m).(*ModifyBearerFailureIndication)
+}
+
 // MarshalTo serializes ModifyBearerFailureIndication into bytes.
 func (m *ModifyBearerFailureIndication) MarshalTo(b []byte) error {
-	if m.Header.Payload != nil {
-		m.Header.Payload = nil
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
 	}
-	m.Header.Payload = make([]byte, m.MarshalLen()-m.Header.MarshalLen())
 
 	offset := 0
 	if ie := m.Cause; ie != nil {
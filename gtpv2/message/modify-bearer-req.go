@@ -186,12 +186,23 @@ func (m *ModifyBearerRequest) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from ModifyBearerRequest to b and returns the extended buffer.
+func (m *ModifyBearerRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// Clone returns a deep copy of ModifyBearerRequest.
+func (m *ModifyBearerRequest) Clone() *ModifyBearerRequest {
+	return Clone(m).(*ModifyBearerRequest)
+}
+
 // MarshalTo serializes ModifyBearerRequest into bytes.
 func (m *ModifyBearerRequest) MarshalTo(b []byte) error {
-	if m.Header.Payload != nil {
-		m.Header.Payload = nil
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
 	}
-	m.Header.Payload = make([]byte, m.MarshalLen()-m.Header.MarshalLen())
 
 	offset := 0
 	if ie := m.MEI; ie != nil {
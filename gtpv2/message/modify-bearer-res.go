@@ -149,12 +149,23 @@ func (m *ModifyBearerResponse) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from ModifyBearerResponse to b and returns the extended buffer.
+func (m *ModifyBearerResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// Clone returns a deep copy of ModifyBearerResponse.
+func (m *ModifyBearerResponse) Clone() *ModifyBearerResponse {
+	return Clone(m).(*ModifyBearerResponse)
+}
+
 // MarshalTo serializes ModifyBearerResponse into bytes.
 func (m *ModifyBearerResponse) MarshalTo(b []byte) error {
-	if m.Header.Payload != nil {
-		m.Header.Payload = nil
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
 	}
-	m.Header.Payload = make([]byte, m.MarshalLen()-m.Header.MarshalLen())
 
 	offset := 0
 	if ie := m.Cause; ie != nil {
@@ -0,0 +1,221 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+)
+
+// AnomalyKind is the kind of Anomaly.
+type AnomalyKind uint8
+
+// AnomalyKind definitions.
+const (
+	_ AnomalyKind = iota
+	// AnomalyUnknownIE is an IE of the type not defined in TS 29.274.
+	AnomalyUnknownIE
+	// AnomalyUnexpectedIE is an IE of the known type that is not expected in
+	// the message with its instance, e.g., F-TEID with instance 5 in Create
+	// Session Request. It is kept in AdditionalIEs of the message.
+	AnomalyUnexpectedIE
+	// AnomalyTruncatedIE is the last IE that is shorter than its Length.
+	AnomalyTruncatedIE
+	// AnomalySpareBits is the spare bits set to non-zero in the header or IE.
+	AnomalySpareBits
+)
+
+var anomalyNames = map[AnomalyKind]string{
+	AnomalyUnknownIE:    "unknown IE",
+	AnomalyUnexpectedIE: "unexpected IE",
+	AnomalyTruncatedIE:  "truncated IE",
+	AnomalySpareBits:    "non-zero spare bits",
+}
+
+// String returns the name of AnomalyKind.
+func (k AnomalyKind) String() string {
+	if name, ok := anomalyNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown anomaly (%d)", k)
+}
+
+// Anomaly is a deviation from TS 29.274 found in parsing a message, which is
+// accepted by Parse but may be worth noticing.
+//
+// Anomaly implements error so that it is returned as it is in strict mode.
+type Anomaly struct {
+	Kind AnomalyKind
+
+	// Offset is the position in the message where the anomaly is found.
+	Offset int
+
+	// Type and Instance are the ones of the IE where the anomaly is found.
+	// They are zero if the anomaly is in the header.
+	Type     uint8
+	Instance uint8
+}
+
+// Error returns the anomaly in human readable format.
+func (a *Anomaly) Error() string {
+	if a.Type == 0 {
+		return fmt.Sprintf("%s in header at offset %d", a.Kind, a.Offset)
+	}
+	return fmt.Sprintf("%s at offset %d: %s (instance %d)", a.Kind, a.Offset, ie.TypeName(a.Type), a.Instance)
+}
+
+// ParseOption configures how ParseWithOptions handles the anomalies.
+type ParseOption func(*parseOptions)
+
+type parseOptions struct {
+	strict  bool
+	lenient bool
+}
+
+// WithStrictParsing makes ParseWithOptions return the first anomaly found as
+// an error, which is useful for conformance testing.
+func WithStrictParsing() ParseOption {
+	return func(o *parseOptions) {
+		o.strict = true
+	}
+}
+
+// WithLenientParsing makes ParseWithOptions skip the truncated IE at the end
+// of the message instead of failing, which is useful for interoperating with
+// the peers that do not strictly follow the specification.
+func WithLenientParsing() ParseOption {
+	return func(o *parseOptions) {
+		o.lenient = true
+	}
+}
+
+// ParseWithOptions decodes the given bytes as Message as Parse does, and
+// returns the anomalies found in the message as well.
+//
+// Without options, the anomalies that Parse accepts are just recorded, and
+// the truncated IE fails the parsing as Parse does. See WithStrictParsing and
+// WithLenientParsing for the options.
+func ParseWithOptions(b []byte, opts ...ParseOption) (Message, []*Anomaly, error) {
+	o := &parseOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	h, err := ParseHeader(b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hl := len(b) - len(h.Payload)
+	var anomalies []*Anomaly
+	if h.Flags&0x03 != 0 || h.Spare&spareMask(h) != 0 {
+		anomalies = append(anomalies, &Anomaly{Kind: AnomalySpareBits})
+	}
+
+	valid, err := inspectIEs(h.Payload, hl, &anomalies)
+	if err != nil {
+		return nil, anomalies, err
+	}
+	if valid < len(h.Payload) && !o.lenient {
+		return nil, anomalies, ie.ErrInvalidLength
+	}
+
+	m, err := Parse(b[:hl+valid])
+	if err != nil {
+		return nil, anomalies, err
+	}
+
+	if _, ok := m.(*Generic); !ok {
+		for _, i := range additionalIEs(m) {
+			if !ie.IsKnownType(i.Type) {
+				continue
+			}
+			anomalies = append(anomalies, &Anomaly{
+				Kind: AnomalyUnexpectedIE,
+				// the IEs refer to the sub-slices of b without copying.
+				Offset:   cap(b) - cap(i.Payload) - 4,
+				Type:     i.Type,
+				Instance: i.Instance(),
+			})
+		}
+		sort.SliceStable(anomalies, func(x, y int) bool {
+			return anomalies[x].Offset < anomalies[y].Offset
+		})
+	}
+
+	if o.strict && len(anomalies) > 0 {
+		return nil, anomalies, anomalies[0]
+	}
+	return m, anomalies, nil
+}
+
+// spareMask returns the mask of the spare bits in the last octet of header,
+// which is shared with Message Priority if the MP flag is set.
+func spareMask(h *Header) uint8 {
+	if h.HasMessagePriority() {
+		return 0x0f
+	}
+	return 0xff
+}
+
+// inspectIEs records the anomalies in the IEs in b, including the ones in
+// grouped IEs, and returns the length of b without the truncated IE at the
+// end. offset is the position of b in the message.
+func inspectIEs(b []byte, offset int, anomalies *[]*Anomaly) (int, error) {
+	n := 0
+	for n < len(b) {
+		if len(b)-n < 4 {
+			*anomalies = append(*anomalies, &Anomaly{Kind: AnomalyTruncatedIE, Offset: offset + n, Type: b[n]})
+			return n, nil
+		}
+
+		typ, ins := b[n], b[n+3]&0x0f
+		l := 4 + int(binary.BigEndian.Uint16(b[n+1:n+3]))
+		if n+l > len(b) {
+			*anomalies = append(*anomalies, &Anomaly{Kind: AnomalyTruncatedIE, Offset: offset + n, Type: typ, Instance: ins})
+			return n, nil
+		}
+
+		if !ie.IsKnownType(typ) {
+			*anomalies = append(*anomalies, &Anomaly{Kind: AnomalyUnknownIE, Offset: offset + n, Type: typ, Instance: ins})
+		}
+		if b[n+3]&0xf0 != 0 {
+			*anomalies = append(*anomalies, &Anomaly{Kind: AnomalySpareBits, Offset: offset + n, Type: typ, Instance: ins})
+		}
+
+		if (&ie.IE{Type: typ}).IsGrouped() {
+			valid, err := inspectIEs(b[n+4:n+l], offset+n+4, anomalies)
+			if err != nil {
+				return n, err
+			}
+			if valid < l-4 {
+				// only the truncation at the end of the message can be skipped.
+				return n, ie.ErrInvalidLength
+			}
+		}
+		n += l
+	}
+	return n, nil
+}
+
+// additionalIEs returns the IEs that are not expected in the message, which
+// are kept in the AdditionalIEs field of each message.
+func additionalIEs(m Message) []*ie.IE {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	f := v.Elem().FieldByName("AdditionalIEs")
+	if !f.IsValid() {
+		return nil
+	}
+	ies, _ := f.Interface().([]*ie.IE)
+	return ies
+}
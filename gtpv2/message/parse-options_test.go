@@ -0,0 +1,134 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package message_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+func TestParseWithOptions(t *testing.T) {
+	marshal := func(m message.Message) []byte {
+		b, err := message.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	clean := marshal(message.NewEchoRequest(1, ie.NewRecovery(0x80)))
+	quirky := marshal(message.NewCreateSessionRequest(
+		0x11223344, 1,
+		ie.NewIMSI("123451234567890"),
+		ie.New(0xf0, 0x00, []byte{0xde, 0xad}),
+		ie.NewFullyQualifiedTEID(v2.IFTypeS11MMEGTPC, 0xffffffff, "1.1.1.1", "").WithInstance(5),
+	))
+	// the last IE is shorter than its Length.
+	truncated := append(append([]byte{}, clean...), 0x03, 0x00, 0x05, 0x00, 0x01)
+	// non-zero spare bits in the header and the instance octet of Recovery.
+	spare := append([]byte{}, clean...)
+	spare[0] |= 0x01
+	spare[11] |= 0x10
+
+	cases := []struct {
+		description string
+		serialized  []byte
+		opts        []message.ParseOption
+		anomalies   []message.Anomaly
+		err         error
+	}{
+		{
+			"Clean",
+			clean,
+			nil,
+			nil,
+			nil,
+		}, {
+			"Quirky",
+			quirky,
+			nil,
+			[]message.Anomaly{
+				{Kind: message.AnomalyUnknownIE, Offset: 24, Type: 0xf0},
+				{Kind: message.AnomalyUnexpectedIE, Offset: 30, Type: ie.FullyQualifiedTEID, Instance: 5},
+			},
+			nil,
+		}, {
+			"Quirky/Strict",
+			quirky,
+			[]message.ParseOption{message.WithStrictParsing()},
+			[]message.Anomaly{
+				{Kind: message.AnomalyUnknownIE, Offset: 24, Type: 0xf0},
+				{Kind: message.AnomalyUnexpectedIE, Offset: 30, Type: ie.FullyQualifiedTEID, Instance: 5},
+			},
+			&message.Anomaly{Kind: message.AnomalyUnknownIE, Offset: 24, Type: 0xf0},
+		}, {
+			"Truncated",
+			truncated,
+			nil,
+			[]message.Anomaly{
+				{Kind: message.AnomalyTruncatedIE, Offset: 13, Type: ie.Recovery},
+			},
+			ie.ErrInvalidLength,
+		}, {
+			"Truncated/Lenient",
+			truncated,
+			[]message.ParseOption{message.WithLenientParsing()},
+			[]message.Anomaly{
+				{Kind: message.AnomalyTruncatedIE, Offset: 13, Type: ie.Recovery},
+			},
+			nil,
+		}, {
+			"SpareBits",
+			spare,
+			nil,
+			[]message.Anomaly{
+				{Kind: message.AnomalySpareBits},
+				{Kind: message.AnomalySpareBits, Offset: 8, Type: ie.Recovery},
+			},
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			m, anomalies, err := message.ParseWithOptions(c.serialized, c.opts...)
+
+			var got []message.Anomaly
+			for _, a := range anomalies {
+				got = append(got, *a)
+			}
+			if diff := cmp.Diff(got, c.anomalies); diff != "" {
+				t.Error(diff)
+			}
+
+			if c.err == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if m == nil {
+					t.Fatal("message is nil")
+				}
+				return
+			}
+
+			var want *message.Anomaly
+			if errors.As(c.err, &want) {
+				var a *message.Anomaly
+				if !errors.As(err, &a) || *a != *want {
+					t.Errorf("got %v, want %v", err, want)
+				}
+				return
+			}
+			if !errors.Is(err, c.err) {
+				t.Errorf("got %v, want %v", err, c.err)
+			}
+		})
+	}
+}
@@ -52,12 +52,23 @@ func (m *PGWRestartNotificationAcknowledge) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from PGWRestartNotificationAcknowledge to b and returns the extended buffer.
+func (m *PGWRestartNotificationAcknowledge) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// Clone returns a deep copy of PGWRestartNotificationAcknowledge.
+func (m *PGWRestartNotificationAcknowledge) Clone() *PGWRestartNotificationAcknowledge {
+	return Clone(m).(*PGWRestartNotificationAcknowledge)
+}
+
 // MarshalTo serializes PGWRestartNotificationAcknowledge into bytes.
 func (m *PGWRestartNotificationAcknowledge) MarshalTo(b []byte) error {
-	if m.Header.Payload != nil {
-		m.Header.Payload = nil
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
 	}
-	m.Header.Payload = make([]byte, m.MarshalLen()-m.Header.MarshalLen())
 
 	offset := 0
 	if ie := m.Cause; ie != nil {
@@ -63,12 +63,23 @@ func (m *PGWRestartNotification) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from PGWRestartNotification to b and returns the extended buffer.
+func (m *PGWRestartNotification) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// Clone returns a deep copy of PGWRestartNotification.
+func (m *PGWRestartNotification) Clone() *PGWRestartNotification {
+	return Clone(m).(*PGWRestartNotification)
+}
+
 // MarshalTo serializes PGWRestartNotification into bytes.
 func (m *PGWRestartNotification) MarshalTo(b []byte) error {
-	if m.Header.Payload != nil {
-		m.Header.Payload = nil
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
 	}
-	m.Header.Payload = make([]byte, m.MarshalLen()-m.Header.MarshalLen())
 
 	offset := 0
 	if ie := m.PGWS5S8IPAddressForControlPlaneOrPMIP; ie != nil {
@@ -59,12 +59,23 @@ func (r *ReleaseAccessBearersRequest) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from ReleaseAccessBearersRequest to b and returns the extended buffer.
+func (r *ReleaseAccessBearersRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, r)
+}
+
+// Clone returns a deep copy of ReleaseAccessBearersRequest.
+func (r *ReleaseAccessBearersRequest) Clone() *ReleaseAccessBearersRequest {
+	return Clone(r).(*ReleaseAccessBearersRequest)
+}
+
 // MarshalTo serializes ReleaseAccessBearersRequest into bytes.
 func (r *ReleaseAccessBearersRequest) MarshalTo(b []byte) error {
-	if r.Header.Payload != nil {
-		r.Header.Payload = nil
+	var err error
+	r.Header.Payload, err = r.Header.payloadIn(b, r.MarshalLen())
+	if err != nil {
+		return err
 	}
-	r.Header.Payload = make([]byte, r.MarshalLen()-r.Header.MarshalLen())
 
 	offset := 0
 	if ie := r.ListOfRABs; ie != nil {
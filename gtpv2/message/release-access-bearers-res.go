@@ -62,12 +62,23 @@ func (r *ReleaseAccessBearersResponse) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from ReleaseAccessBearersResponse to b and returns the extended buffer.
+func (r *ReleaseAccessBearersResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, r)
+}
+
+// Clone returns a deep copy of ReleaseAccessBearersResponse.
+func (r *ReleaseAccessBearersResponse) Clone() *ReleaseAccessBearersResponse {
+	return Clone(r).(*ReleaseAccessBearersResponse)
+}
+
 // MarshalTo serializes ReleaseAccessBearersResponse into bytes.
 func (r *ReleaseAccessBearersResponse) MarshalTo(b []byte) error {
-	if r.Header.Payload != nil {
-		r.Header.Payload = nil
+	var err error
+	r.Header.Payload, err = r.Header.payloadIn(b, r.MarshalLen())
+	if err != nil {
+		return err
 	}
-	r.Header.Payload = make([]byte, r.MarshalLen()-r.Header.MarshalLen())
 
 	offset := 0
 	if ie := r.Cause; ie != nil {
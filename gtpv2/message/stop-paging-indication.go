@@ -50,12 +50,23 @@ func (s *StopPagingIndication) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from StopPagingIndication to b and returns the extended buffer.
+func (s *StopPagingIndication) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, s)
+}
+
+// Clone returns a deep copy of StopPagingIndication.
+func (s *StopPagingIndication) Clone() *StopPagingIndication {
+	return Clone(s).(*StopPagingIndication)
+}
+
 // MarshalTo serializes StopPagingIndication into bytes.
 func (s *StopPagingIndication) MarshalTo(b []byte) error {
-	if s.Header.Payload != nil {
-		s.Header.Payload = nil
+	var err error
+	s.Header.Payload, err = s.Header.payloadIn(b, s.MarshalLen())
+	if err != nil {
+		return err
 	}
-	s.Header.Payload = make([]byte, s.MarshalLen()-s.Header.MarshalLen())
 
 	offset := 0
 	if ie := s.IMSI; ie != nil {
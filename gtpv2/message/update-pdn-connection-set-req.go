@@ -60,12 +60,23 @@ func (m *UpdatePDNConnectionSetRequest) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from UpdatePDNConnectionSetRequest to b and returns the extended buffer.
+func (m *UpdatePDNConnectionSetRequest) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// Clone returns a deep copy of UpdatePDNConnectionSetRequest.
+func (m *UpdatePDNConnectionSetRequest) Clone() *UpdatePDNConnectionSetRequest {
+	return Clone(m).(*UpdatePDNConnectionSetRequest)
+}
+
 // MarshalTo serializes UpdatePDNConnectionSetRequest into bytes.
 func (m *UpdatePDNConnectionSetRequest) MarshalTo(b []byte) error {
-	if m.Header.Payload != nil {
-		m.Header.Payload = nil
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
 	}
-	m.Header.Payload = make([]byte, m.MarshalLen()-m.Header.MarshalLen())
 
 	offset := 0
 	if ie := m.MMEFQCSID; ie != nil {
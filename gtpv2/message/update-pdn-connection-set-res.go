@@ -59,12 +59,23 @@ func (m *UpdatePDNConnectionSetResponse) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from UpdatePDNConnectionSetResponse to b and returns the extended buffer.
+func (m *UpdatePDNConnectionSetResponse) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, m)
+}
+
+// Clone returns a deep copy of UpdatePDNConnectionSetResponse.
+func (m *UpdatePDNConnectionSetResponse) Clone() *UpdatePDNConnectionSetResponse {
+	return Clone(m).(*UpdatePDNConnectionSetResponse)
+}
+
 // MarshalTo puts the byte sequence in the byte array given as b.
 func (m *UpdatePDNConnectionSetResponse) MarshalTo(b []byte) error {
-	if m.Header.Payload != nil {
-		m.Header.Payload = nil
+	var err error
+	m.Header.Payload, err = m.Header.payloadIn(b, m.MarshalLen())
+	if err != nil {
+		return err
 	}
-	m.Header.Payload = make([]byte, m.MarshalLen()-m.Header.MarshalLen())
 
 	offset := 0
 	if ie := m.Cause; ie != nil {
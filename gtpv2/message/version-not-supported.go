@@ -43,12 +43,23 @@ func (v *VersionNotSupportedIndication) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// AppendTo appends the byte sequence generated from VersionNotSupportedIndication to b and returns the extended buffer.
+func (v *VersionNotSupportedIndication) AppendTo(b []byte) ([]byte, error) {
+	return AppendTo(b, v)
+}
+
+// Clone returns a deep copy of VersionNotSupportedIndication.
+func (v *VersionNotSupportedIndication) Clone() *VersionNotSupportedIndication {
+	return Clone(v).(*VersionNotSupportedIndication)
+}
+
 // MarshalTo serializes VersionNotSupportedIndication into bytes.
 func (v *VersionNotSupportedIndication) MarshalTo(b []byte) error {
-	if v.Header.Payload != nil {
-		v.Header.Payload = nil
+	var err error
+	v.Header.Payload, err = v.Header.payloadIn(b, v.MarshalLen())
+	if err != nil {
+		return err
 	}
-	v.Header.Payload = make([]byte, v.MarshalLen()-v.Header.MarshalLen())
 
 	offset := 0
 	for _, ie := range v.AdditionalIEs {
@@ -0,0 +1,189 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// NewN26Indication returns the Indication IE with the flags required for the
+// interworking between EPS and 5GS with N26, i.e., 5GSIWK set and 5GSNN26 cleared.
+//
+// The flags in ind are kept in the returned IE. ind itself is not modified and can be nil.
+func NewN26Indication(ind *ie.IE) *ie.IE {
+	var octs []uint8
+	if ind != nil {
+		if v, err := ind.Indication(); err == nil {
+			octs = append(octs, v...)
+		}
+	}
+	for len(octs) < 7 {
+		octs = append(octs, 0)
+	}
+	octs[6] |= 0x20
+	octs[6] &^= 0x80
+
+	return ie.NewIndicationFromOctets(octs...)
+}
+
+// IsN26Interworking reports whether the Indication IE given indicates the interworking
+// with N26, i.e., 5GSIWK is set and 5GSNN26 is not.
+func IsN26Interworking(ind *ie.IE) bool {
+	if ind == nil {
+		return false
+	}
+	return ind.Has5GSIWK() && !ind.Has5GSNN26()
+}
+
+// N26Hooks is a set of functions to map the UE context between EPS and 5GS, which is
+// called when the Context messages are exchanged between MME and AMF over N26.
+//
+// Any of them can be nil, and the handler for the corresponding message is not
+// registered in that case.
+type N26Hooks struct {
+	// MapToEPS is called when Context Request is received from MME(=5GS to EPS idle
+	// mode mobility). It is expected to return the IEs of the UE context mapped from
+	// 5GS to EPS, such as IMSI, MM Context, PDN Connections and Sender F-TEID, which
+	// are used in Context Response.
+	//
+	// If it returns *CauseNotOKError, the Cause in it is used in the response.
+	// Otherwise, an error is responded with Context Not Found.
+	MapToEPS func(sender net.Addr, req *message.ContextRequest) ([]*ie.IE, error)
+
+	// MapFromEPS is called when Context Response is received from MME(=EPS to 5GS idle
+	// mode mobility) with acceptance. It is expected to map the EPS UE context in the
+	// response to 5GS one, and return the IEs used in Context Acknowledge.
+	//
+	// If it returns error, Context Acknowledge is sent with the Cause in the same way
+	// as MapToEPS.
+	MapFromEPS func(sender net.Addr, res *message.ContextResponse) ([]*ie.IE, error)
+
+	// OnContextAcknowledge is called when Context Acknowledge is received from MME.
+	OnContextAcknowledge func(sender net.Addr, ack *message.ContextAcknowledge) error
+}
+
+// EnableN26 registers the handlers for Context Request, Context Response and
+// Context Acknowledge which call the hooks given, so that Conn works as AMF on N26.
+//
+// The Indication in Context Response and Context Acknowledge sent from the handlers
+// always has the flags set by NewN26Indication.
+func (c *Conn) EnableN26(hooks *N26Hooks) {
+	if hooks == nil {
+		return
+	}
+
+	if fn := hooks.MapToEPS; fn != nil {
+		c.AddHandler(message.MsgTypeContextRequest, func(c *Conn, senderAddr net.Addr, msg message.Message) error {
+			req, ok := msg.(*message.ContextRequest)
+			if !ok {
+				return &UnexpectedTypeError{Msg: msg}
+			}
+
+			var teid uint32
+			if fteid := req.AddressAndTEIDForCPlane; fteid != nil {
+				teid, _ = fteid.TEID()
+			}
+
+			ies, err := fn(senderAddr, req)
+			res := message.NewContextResponse(teid, 0, n26IEs(ies, err)...)
+			if rerr := c.RespondTo(senderAddr, req, res); rerr != nil {
+				return rerr
+			}
+			return err
+		})
+	}
+
+	if fn := hooks.MapFromEPS; fn != nil {
+		c.AddHandler(message.MsgTypeContextResponse, func(c *Conn, senderAddr net.Addr, msg message.Message) error {
+			res, ok := msg.(*message.ContextResponse)
+			if !ok {
+				return &UnexpectedTypeError{Msg: msg}
+			}
+			if err := checkCause(res, res.Cause); err != nil {
+				return err
+			}
+
+			var teid uint32
+			if fteid := res.SenderFTEID; fteid != nil {
+				teid, _ = fteid.TEID()
+			}
+
+			ies, err := fn(senderAddr, res)
+			ack := message.NewContextAcknowledge(teid, 0, n26IEs(ies, err)...)
+			if rerr := c.RespondTo(senderAddr, res, ack); rerr != nil {
+				return rerr
+			}
+			return err
+		})
+	}
+
+	if fn := hooks.OnContextAcknowledge; fn != nil {
+		c.AddHandler(message.MsgTypeContextAcknowledge, func(c *Conn, senderAddr net.Addr, msg message.Message) error {
+			ack, ok := msg.(*message.ContextAcknowledge)
+			if !ok {
+				return &UnexpectedTypeError{Msg: msg}
+			}
+			return fn(senderAddr, ack)
+		})
+	}
+}
+
+// n26IEs returns the IEs to be used in Context Response or Context Acknowledge,
+// built from the IEs and error returned from a hook in N26Hooks.
+func n26IEs(ies []*ie.IE, err error) []*ie.IE {
+	if err != nil {
+		cause := CauseContextNotFound
+		var cerr *CauseNotOKError
+		if errors.As(err, &cerr) {
+			cause = cerr.Cause
+		}
+		return []*ie.IE{ie.NewCause(cause, 0, 0, 0, nil), NewN26Indication(nil)}
+	}
+
+	out := []*ie.IE{ie.NewCause(CauseRequestAccepted, 0, 0, 0, nil)}
+	var ind *ie.IE
+	for _, i := range ies {
+		if i == nil {
+			continue
+		}
+		switch i.Type {
+		case ie.Cause:
+			continue
+		case ie.Indication:
+			ind = i
+			continue
+		}
+		out = append(out, i)
+	}
+	return append(out, NewN26Indication(ind))
+}
+
+// RequestN26Context sends Context Request to MME at raddr, with the Indication in ies
+// (or an empty one if not given) replaced by the one with the flags required for N26.
+//
+// The response is passed to the handler registered for Context Response, which can be
+// the one registered by EnableN26 with MapFromEPS.
+func (c *Conn) RequestN26Context(raddr net.Addr, ies ...*ie.IE) (uint32, error) {
+	var ind *ie.IE
+	reqIEs := make([]*ie.IE, 0, len(ies)+1)
+	for _, i := range ies {
+		if i == nil {
+			continue
+		}
+		if i.Type == ie.Indication {
+			ind = i
+			continue
+		}
+		reqIEs = append(reqIEs, i)
+	}
+	reqIEs = append(reqIEs, NewN26Indication(ind))
+
+	return c.sendMessageTo(context.Background(), message.NewContextRequest(0, 0, reqIEs...), raddr, nil)
+}
@@ -0,0 +1,329 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// OverloadInfo is the overload information received from a peer in the Overload
+// Control Information IE.
+type OverloadInfo struct {
+	// Peer is the address of the peer the information is received from.
+	Peer string
+
+	// Instance is the instance of the Overload Control Information IE, which tells
+	// the type of the node that originated it(e.g., 0 for P-GW, 1 for S-GW in
+	// Create Session Response).
+	Instance uint8
+
+	// APN is the APN the information is for, or empty if it is for the node.
+	APN string
+
+	SequenceNumber uint32
+
+	// Metric is the Overload Reduction Metric, which is the percentage of the
+	// requests to be reduced.
+	Metric uint8
+
+	// ValidUntil is the time the Period of Validity expires.
+	ValidUntil time.Time
+}
+
+// OverloadDecisionFunc decides whether to send a request toward a peer in overload.
+// info is the one with the largest Metric among the ones applicable to the request.
+//
+// It should return true to send the request, and false to reject it.
+type OverloadDecisionFunc func(peer net.Addr, msg message.Message, info *OverloadInfo) bool
+
+// EnableOverloadControl lets Conn honor the Overload Control Information received
+// from the peers, as the sender of the requests described in TS 29.274 12.3.
+//
+// The overload information is kept per peer, instance of the IE and APN, and is
+// updated only with the one with the newer Sequence Number. It is discarded when the
+// Period of Validity expires, or is updated with the Metric or the Period of Validity 0.
+// Only the information originated by the peer itself is kept. The ones relayed from
+// other nodes(e.g., P-GW's one in Create Session Response received by MME from S-GW)
+// are ignored, as they are not about the peer. On the interfaces other than S11, S4
+// and S5/S8, where no node relays them, all the information received is kept.
+//
+// While a peer is in overload, the initial messages that are the candidates for
+// throttling sent toward it(i.e., the ones that establish or modify the PDN connections
+// or bearers, such as Create Session Request, Create Bearer Request and Bearer Resource
+// Command) are passed to decide, and the ones rejected make the methods sending them
+// return ErrThrottledByOverload. The others, including the ones releasing resources, are
+// never throttled. If decide is nil, the requests are rejected randomly at the rate of
+// the Metric. The node-level information applies to all the candidates, and the
+// APN-level one only to Create Session Request with the APN.
+func (c *Conn) EnableOverloadControl(decide OverloadDecisionFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if decide == nil {
+		decide = throttleByMetric
+	}
+	c.overload = &overloadControl{
+		decide:  decide,
+		entries: map[overloadKey]*OverloadInfo{},
+	}
+}
+
+// DisableOverloadControl stops honoring the overload information and discards the
+// ones received.
+func (c *Conn) DisableOverloadControl() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.overload = nil
+}
+
+func (c *Conn) loadOverloadControl() *overloadControl {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.overload
+}
+
+// PeerOverloadInfo returns the overload information from peer that is valid now,
+// ordered by the instance and APN.
+func (c *Conn) PeerOverloadInfo(peer net.Addr) []*OverloadInfo {
+	oc := c.loadOverloadControl()
+	if oc == nil {
+		return nil
+	}
+	return oc.lookup(peer.String(), time.Now(), nil)
+}
+
+// updateOverload stores the Overload Control Information in msg received from peer.
+// raw is the bytes msg is parsed from, which is looked up for the IEs without
+// decoding the whole message again.
+func (c *Conn) updateOverload(peer net.Addr, raw []byte, msg message.Message) {
+	oc := c.loadOverloadControl()
+	if oc == nil {
+		return
+	}
+
+	l, err := message.ParseLazy(raw)
+	if err != nil {
+		return
+	}
+
+	var instances []uint8
+	_ = l.Walk(func(typ, instance uint8, payload []byte) bool {
+		if typ == ie.OverloadControlInformation {
+			instances = append(instances, instance)
+		}
+		return true
+	})
+	peerNode := c.overloadPeerNode()
+	for _, instance := range instances {
+		if peerNode != nodeUnknown && overloadOriginator(msg.MessageType(), instance) != peerNode {
+			continue
+		}
+
+		i, err := l.IE(ie.OverloadControlInformation, instance)
+		if err != nil {
+			continue
+		}
+		f, err := i.OverloadControlInformation()
+		if err != nil {
+			logf("failed to decode Overload Control Information from %s: %s", peer, err)
+			continue
+		}
+		oc.update(peer.String(), instance, f, time.Now())
+	}
+}
+
+// throttleOverload reports whether the request msg to peer should not be sent
+// due to the overload.
+func (c *Conn) throttleOverload(peer net.Addr, msg message.Message) bool {
+	if _, ok := overloadCandidates[msg.MessageType()]; !ok {
+		return false
+	}
+	oc := c.loadOverloadControl()
+	if oc == nil {
+		return false
+	}
+
+	var apn string
+	if csReq, ok := msg.(*message.CreateSessionRequest); ok && csReq.APN != nil {
+		apn, _ = csReq.APN.AccessPointName()
+	}
+
+	var worst *OverloadInfo
+	for _, info := range oc.lookup(peer.String(), time.Now(), func(info *OverloadInfo) bool {
+		return info.APN == "" || info.APN == apn
+	}) {
+		if worst == nil || info.Metric > worst.Metric {
+			worst = info
+		}
+	}
+	if worst == nil {
+		return false
+	}
+
+	return !oc.decide(peer, msg, worst)
+}
+
+// overloadCandidates is the initial messages that can be throttled while the peer is
+// in overload(TS 29.274 12.3.9). The ones releasing the resources are not included, as
+// dropping them leaves the state on the peer in overload.
+var overloadCandidates = map[uint8]struct{}{
+	message.MsgTypeCreateSessionRequest:     {},
+	message.MsgTypeBearerResourceCommand:    {},
+	message.MsgTypeModifyBearerCommand:      {},
+	message.MsgTypeCreateBearerRequest:      {},
+	message.MsgTypeUpdateBearerRequest:      {},
+	message.MsgTypeDownlinkDataNotification: {},
+}
+
+// The types of the nodes that originate the Overload Control Information.
+const (
+	nodeUnknown = iota
+	nodeMME     // MME or S4-SGSN
+	nodeSGW
+	nodePGW
+)
+
+// overloadOriginators is the types of the nodes that originate the Overload Control
+// Information IE in the message, by the instance of the IE(TS 29.274 7.2 and 7.3).
+var overloadOriginators = map[uint8][]int{
+	message.MsgTypeCreateSessionResponse:           {nodePGW, nodeSGW},
+	message.MsgTypeModifyBearerResponse:            {nodePGW, nodeSGW},
+	message.MsgTypeDeleteSessionResponse:           {nodePGW, nodeSGW},
+	message.MsgTypeCreateBearerRequest:             {nodePGW, nodeSGW},
+	message.MsgTypeUpdateBearerRequest:             {nodePGW, nodeSGW},
+	message.MsgTypeDeleteBearerRequest:             {nodePGW, nodeSGW},
+	message.MsgTypeModifyBearerFailureIndication:   {nodePGW, nodeSGW},
+	message.MsgTypeDeleteBearerFailureIndication:   {nodePGW, nodeSGW},
+	message.MsgTypeBearerResourceFailureIndication: {nodePGW, nodeSGW},
+	message.MsgTypeModifyBearerRequest:             {nodeMME, nodeSGW},
+	message.MsgTypeDeleteSessionRequest:            {nodeMME, nodeSGW},
+	message.MsgTypeCreateBearerResponse:            {nodeMME, nodeSGW},
+	message.MsgTypeUpdateBearerResponse:            {nodeMME, nodeSGW},
+	message.MsgTypeDeleteBearerResponse:            {nodeMME, nodeSGW},
+	message.MsgTypeModifyBearerCommand:             {nodeMME, nodeSGW},
+	message.MsgTypeDeleteBearerCommand:             {nodeMME, nodeSGW},
+	message.MsgTypeBearerResourceCommand:           {nodeMME, nodeSGW},
+	message.MsgTypeReleaseAccessBearersResponse:    {nodeSGW},
+	message.MsgTypeDownlinkDataNotification:        {nodeSGW},
+}
+
+// overloadOriginator returns the type of the node that originates the Overload Control
+// Information IE with instance in the message of msgType.
+func overloadOriginator(msgType, instance uint8) int {
+	nodes := overloadOriginators[msgType]
+	if int(instance) >= len(nodes) {
+		return nodeUnknown
+	}
+	return nodes[instance]
+}
+
+// overloadPeerNode returns the type of the peer node on the interface of Conn, or
+// nodeUnknown if no node relays the Overload Control Information on it.
+func (c *Conn) overloadPeerNode() int {
+	switch c.localIfType {
+	case IFTypeS11MMEGTPC, IFTypeS4SGSNGTPC, IFTypeS5S8PGWGTPC:
+		return nodeSGW
+	case IFTypeS11S4SGWGTPC:
+		return nodeMME
+	case IFTypeS5S8SGWGTPC:
+		return nodePGW
+	}
+	return nodeUnknown
+}
+
+func throttleByMetric(peer net.Addr, msg message.Message, info *OverloadInfo) bool {
+	return generateRandomUint32()%100 >= uint32(info.Metric)
+}
+
+type overloadKey struct {
+	peer     string
+	instance uint8
+	apn      string
+}
+
+type overloadControl struct {
+	mu      sync.Mutex
+	decide  OverloadDecisionFunc
+	entries map[overloadKey]*OverloadInfo
+}
+
+func (o *overloadControl) update(peer string, instance uint8, f *ie.OverloadControlInformationFields, now time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	apns := f.APNs
+	if len(apns) == 0 {
+		apns = []string{""}
+	}
+
+	for _, apn := range apns {
+		key := overloadKey{peer: peer, instance: instance, apn: apn}
+		if old, ok := o.entries[key]; ok && !newerOverloadSequence(f.SequenceNumber, old.SequenceNumber) {
+			continue
+		}
+		if f.Metric == 0 || f.PeriodOfValidity == 0 {
+			delete(o.entries, key)
+			continue
+		}
+
+		validUntil := now.Add(f.PeriodOfValidity)
+		if f.PeriodOfValidity == ie.EPCTimerInfinite {
+			validUntil = time.Time{}
+		}
+		o.entries[key] = &OverloadInfo{
+			Peer:           peer,
+			Instance:       instance,
+			APN:            apn,
+			SequenceNumber: f.SequenceNumber,
+			Metric:         f.Metric,
+			ValidUntil:     validUntil,
+		}
+	}
+}
+
+// lookup returns the copies of the information from peer valid at now that fn(if
+// given) returns true for, removing the expired ones.
+func (o *overloadControl) lookup(peer string, now time.Time, fn func(*OverloadInfo) bool) []*OverloadInfo {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var infos []*OverloadInfo
+	for key, info := range o.entries {
+		if key.peer != peer {
+			continue
+		}
+		if !info.ValidUntil.IsZero() && now.After(info.ValidUntil) {
+			delete(o.entries, key)
+			continue
+		}
+		if fn != nil && !fn(info) {
+			continue
+		}
+		cp := *info
+		infos = append(infos, &cp)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Instance != infos[j].Instance {
+			return infos[i].Instance < infos[j].Instance
+		}
+		return infos[i].APN < infos[j].APN
+	})
+	return infos
+}
+
+// newerOverloadSequence reports whether the Overload Control Sequence Number a is
+// newer than b, taking the wrap around into account.
+func newerOverloadSequence(a, b uint32) bool {
+	return int32(a-b) > 0
+}
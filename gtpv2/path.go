@@ -0,0 +1,471 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"context"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wmnsk/go-gtp/gtpv1"
+	v1ie "github.com/wmnsk/go-gtp/gtpv1/ie"
+	v1msg "github.com/wmnsk/go-gtp/gtpv1/message"
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// PathState represents the state of the path toward a peer.
+type PathState uint8
+
+// PathState definitions.
+const (
+	PathStateUnknown PathState = iota
+	PathStateUp
+	PathStateDown
+)
+
+// String returns the name of PathState.
+func (s PathState) String() string {
+	switch s {
+	case PathStateUp:
+		return "up"
+	case PathStateDown:
+		return "down"
+	default:
+		return "unknown"
+	}
+}
+
+// PathStateFunc is called when the state of a peer changes.
+type PathStateFunc func(peer *PathPeer, state PathState)
+
+// PeerRestartFunc is called when the restart of a peer is detected by the change
+// of the restart counter in the Recovery IE it sends over GTPv2-C.
+type PeerRestartFunc func(peer *PathPeer, restartCounter uint8)
+
+// path is the state of the path on either C-Plane or U-Plane.
+type path struct {
+	state    PathState
+	awaiting bool
+	missed   int
+}
+
+func (p *path) respond() {
+	p.state = PathStateUp
+	p.awaiting = false
+	p.missed = 0
+}
+
+func (p *path) probe(maxMissed int) {
+	if p.awaiting {
+		p.missed++
+		if p.missed >= maxMissed {
+			p.state = PathStateDown
+		}
+	}
+	p.awaiting = true
+}
+
+// PathPeer is a remote node supervised by PathManager, which may have the paths
+// on both GTPv2-C and GTPv1-U.
+type PathPeer struct {
+	// ID is the identifier of the peer given to AddPeer.
+	ID string
+	// CPlaneAddr is the address of the peer for GTPv2-C, or nil if not supervised.
+	CPlaneAddr net.Addr
+	// UPlaneAddr is the address of the peer for GTPv1-U, or nil if not supervised.
+	UPlaneAddr net.Addr
+
+	mu             sync.Mutex
+	cPath, uPath   *path
+	state          PathState
+	restartCounter uint8
+	restartKnown   bool
+}
+
+// State returns the state of the peer.
+func (p *PathPeer) State() PathState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.state
+}
+
+// RestartCounter returns the last restart counter received from the peer over
+// GTPv2-C, and whether it has been received.
+func (p *PathPeer) RestartCounter() (uint8, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.restartCounter, p.restartKnown
+}
+
+// updateState updates the state of the peer with the ones of the paths on the
+// planes given, and returns the new state if it is changed.
+//
+// The peer is down if any of the paths is down, and up if all the paths are up.
+func (p *PathPeer) updateState(cEnabled, uEnabled bool) (PathState, bool) {
+	var paths []*path
+	if p.cPath != nil && cEnabled {
+		paths = append(paths, p.cPath)
+	}
+	if p.uPath != nil && uEnabled {
+		paths = append(paths, p.uPath)
+	}
+
+	state := PathStateUnknown
+	if len(paths) > 0 {
+		state = PathStateUp
+	}
+	for _, pt := range paths {
+		if pt.state == PathStateDown {
+			state = PathStateDown
+			break
+		}
+		if pt.state == PathStateUnknown {
+			state = PathStateUnknown
+		}
+	}
+
+	if state == p.state {
+		return state, false
+	}
+	p.state = state
+	return state, true
+}
+
+// PathManager supervises the paths toward the peers with Echo Request/Response,
+// on both GTPv2-C Conn and GTPv1-U UPlaneConn, and signals the state per peer.
+//
+// This is useful for the nodes like S-GW that talk to the same peer on both
+// planes, so that a failure on either path is recognized as the one of the peer.
+// The restart of the peer is detected with the restart counter in the Recovery IE
+// received over GTPv2-C, as the one in GTPv1-U is always zero.
+type PathManager struct {
+	mu        sync.Mutex
+	interval  time.Duration
+	maxMissed int
+
+	cConn *Conn
+	uConn *gtpv1.UPlaneConn
+
+	peers  map[string]*PathPeer
+	cIndex map[string]*PathPeer
+	uIndex map[string]*PathPeer
+
+	stateFn   PathStateFunc
+	restartFn PeerRestartFunc
+}
+
+// NewPathManager creates a new PathManager that sends Echo Request to each path
+// every interval, and considers the path down if maxMissed Echo Requests in a row
+// are not responded.
+func NewPathManager(interval time.Duration, maxMissed int) *PathManager {
+	if maxMissed < 1 {
+		maxMissed = 1
+	}
+	return &PathManager{
+		interval:  interval,
+		maxMissed: maxMissed,
+		peers:     map[string]*PathPeer{},
+		cIndex:    map[string]*PathPeer{},
+		uIndex:    map[string]*PathPeer{},
+	}
+}
+
+// RegisterConn makes PathManager supervise the paths on GTPv2-C with c.
+//
+// The handlers for Echo Request and Echo Response on c are replaced with the ones
+// that watch the state of the paths, which behave the same as the default ones.
+func (m *PathManager) RegisterConn(c *Conn) {
+	m.mu.Lock()
+	m.cConn = c
+	m.mu.Unlock()
+
+	c.AddHandlers(map[uint8]HandlerFunc{
+		message.MsgTypeEchoRequest: func(c *Conn, senderAddr net.Addr, msg message.Message) error {
+			if req, ok := msg.(*message.EchoRequest); ok {
+				m.handleRecovery(senderAddr, req.Recovery)
+			}
+			return handleEchoRequest(c, senderAddr, msg)
+		},
+		message.MsgTypeEchoResponse: func(c *Conn, senderAddr net.Addr, msg message.Message) error {
+			res, ok := msg.(*message.EchoResponse)
+			if !ok {
+				return &UnexpectedTypeError{Msg: msg}
+			}
+			m.handleEchoResponse(true, senderAddr)
+			m.handleRecovery(senderAddr, res.Recovery)
+			return nil
+		},
+	})
+}
+
+// RegisterUPlaneConn makes PathManager supervise the paths on GTPv1-U with u.
+//
+// The handlers for Echo Request and Echo Response on u are replaced with the ones
+// that watch the state of the paths, which behave the same as the default ones.
+func (m *PathManager) RegisterUPlaneConn(u *gtpv1.UPlaneConn) {
+	m.mu.Lock()
+	m.uConn = u
+	m.mu.Unlock()
+
+	u.AddHandlers(map[uint8]gtpv1.HandlerFunc{
+		v1msg.MsgTypeEchoRequest: func(c gtpv1.Conn, senderAddr net.Addr, msg v1msg.Message) error {
+			if _, ok := msg.(*v1msg.EchoRequest); !ok {
+				return gtpv1.ErrUnexpectedType
+			}
+			return c.RespondTo(
+				senderAddr, msg, v1msg.NewEchoResponse(0, v1ie.NewRecovery(c.Restarts())),
+			)
+		},
+		v1msg.MsgTypeEchoResponse: func(c gtpv1.Conn, senderAddr net.Addr, msg v1msg.Message) error {
+			if _, ok := msg.(*v1msg.EchoResponse); !ok {
+				return gtpv1.ErrUnexpectedType
+			}
+			m.handleEchoResponse(false, senderAddr)
+			return nil
+		},
+	})
+}
+
+// SetPathStateHandler sets the function called when the state of a peer changes.
+func (m *PathManager) SetPathStateHandler(fn PathStateFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stateFn = fn
+}
+
+// SetPeerRestartHandler sets the function called when the restart of a peer is detected.
+func (m *PathManager) SetPeerRestartHandler(fn PeerRestartFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.restartFn = fn
+}
+
+// AddPeer adds a peer to be supervised, which has the address cAddr on GTPv2-C
+// and uAddr on GTPv1-U. Either of them can be nil if the peer does not have the
+// path on the plane. The peer with the same id is replaced.
+func (m *PathManager) AddPeer(id string, cAddr, uAddr net.Addr) *PathPeer {
+	p := &PathPeer{ID: id, CPlaneAddr: cAddr, UPlaneAddr: uAddr}
+	if cAddr != nil {
+		p.cPath = &path{}
+	}
+	if uAddr != nil {
+		p.uPath = &path{}
+	}
+
+	m.addPeer(p)
+	return p
+}
+
+func (m *PathManager) addPeer(p *PathPeer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.removePeer(p.ID)
+	m.peers[p.ID] = p
+	if p.CPlaneAddr != nil {
+		m.cIndex[hostOf(p.CPlaneAddr)] = p
+	}
+	if p.UPlaneAddr != nil {
+		m.uIndex[hostOf(p.UPlaneAddr)] = p
+	}
+}
+
+// checkpointPeers returns the PeerCheckpoints of all the peers sorted by ID.
+func (m *PathManager) checkpointPeers() []*PeerCheckpoint {
+	m.mu.Lock()
+	peers := make([]*PathPeer, 0, len(m.peers))
+	for _, p := range m.peers {
+		peers = append(peers, p)
+	}
+	m.mu.Unlock()
+
+	pcs := make([]*PeerCheckpoint, 0, len(peers))
+	for _, p := range peers {
+		pc := &PeerCheckpoint{ID: p.ID}
+		if p.CPlaneAddr != nil {
+			pc.CPlaneAddr = p.CPlaneAddr.String()
+		}
+		if p.UPlaneAddr != nil {
+			pc.UPlaneAddr = p.UPlaneAddr.String()
+		}
+		pc.RestartCounter, pc.RestartKnown = p.RestartCounter()
+		pcs = append(pcs, pc)
+	}
+	sort.Slice(pcs, func(i, j int) bool { return pcs[i].ID < pcs[j].ID })
+	return pcs
+}
+
+// RemovePeer stops supervising the peer.
+func (m *PathManager) RemovePeer(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.removePeer(id)
+}
+
+func (m *PathManager) removePeer(id string) {
+	p, ok := m.peers[id]
+	if !ok {
+		return
+	}
+	delete(m.peers, id)
+	if p.CPlaneAddr != nil {
+		delete(m.cIndex, hostOf(p.CPlaneAddr))
+	}
+	if p.UPlaneAddr != nil {
+		delete(m.uIndex, hostOf(p.UPlaneAddr))
+	}
+}
+
+// Peer returns the peer with the id given.
+func (m *PathManager) Peer(id string) (*PathPeer, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, ok := m.peers[id]
+	return p, ok
+}
+
+// Run sends Echo Request to the paths every interval until ctx is canceled.
+// This blocks, so it should be called in another goroutine.
+func (m *PathManager) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	m.probeAll()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			m.probeAll()
+		}
+	}
+}
+
+func (m *PathManager) probeAll() {
+	m.mu.Lock()
+	cConn, uConn := m.cConn, m.uConn
+	peers := make([]*PathPeer, 0, len(m.peers))
+	for _, p := range m.peers {
+		peers = append(peers, p)
+	}
+	m.mu.Unlock()
+
+	for _, p := range peers {
+		p.mu.Lock()
+		if p.cPath != nil && cConn != nil {
+			p.cPath.probe(m.maxMissed)
+		}
+		if p.uPath != nil && uConn != nil {
+			p.uPath.probe(m.maxMissed)
+		}
+		state, changed := p.updateState(cConn != nil, uConn != nil)
+		p.mu.Unlock()
+
+		if changed {
+			m.notifyState(p, state)
+		}
+
+		if p.CPlaneAddr != nil && cConn != nil {
+			if _, err := cConn.EchoRequest(p.CPlaneAddr); err != nil {
+				logf("failed to send Echo Request to %s: %v", p.CPlaneAddr, err)
+			}
+		}
+		if p.UPlaneAddr != nil && uConn != nil {
+			if err := uConn.EchoRequest(p.UPlaneAddr); err != nil {
+				logf("failed to send Echo Request to %s: %v", p.UPlaneAddr, err)
+			}
+		}
+	}
+}
+
+// handleEchoResponse marks the path to the sender up on C-Plane if cPlane is true,
+// otherwise on U-Plane.
+func (m *PathManager) handleEchoResponse(cPlane bool, senderAddr net.Addr) {
+	m.mu.Lock()
+	index := m.uIndex
+	if cPlane {
+		index = m.cIndex
+	}
+	p, ok := index[hostOf(senderAddr)]
+	cEnabled, uEnabled := m.cConn != nil, m.uConn != nil
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	p.mu.Lock()
+	if cPlane {
+		p.cPath.respond()
+	} else {
+		p.uPath.respond()
+	}
+	state, changed := p.updateState(cEnabled, uEnabled)
+	p.mu.Unlock()
+
+	if changed {
+		m.notifyState(p, state)
+	}
+}
+
+func (m *PathManager) handleRecovery(senderAddr net.Addr, rec *ie.IE) {
+	if rec == nil {
+		return
+	}
+	counter, err := rec.Recovery()
+	if err != nil {
+		return
+	}
+
+	m.mu.Lock()
+	p, ok := m.cIndex[hostOf(senderAddr)]
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	p.mu.Lock()
+	restarted := p.restartKnown && p.restartCounter != counter
+	p.restartCounter = counter
+	p.restartKnown = true
+	p.mu.Unlock()
+
+	if restarted {
+		m.mu.Lock()
+		fn := m.restartFn
+		m.mu.Unlock()
+		if fn != nil {
+			fn(p, counter)
+		}
+	}
+}
+
+func (m *PathManager) notifyState(p *PathPeer, state PathState) {
+	m.mu.Lock()
+	fn := m.stateFn
+	m.mu.Unlock()
+
+	if fn != nil {
+		fn(p, state)
+	}
+}
+
+// hostOf returns the IP address in addr, as the responses may come from the port
+// other than the one the requests are sent to.
+func hostOf(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
@@ -0,0 +1,122 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/wmnsk/go-gtp/gtpv1"
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+)
+
+func TestPathManager(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	listenC := func(addr string, counter uint8) *v2.Conn {
+		pc, err := net.ListenPacket("udp", addr+v2.GTPCPort)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := v2.NewConnWithPacketConn(pc, v2.IFTypeS5S8SGWGTPC, counter)
+		go func() {
+			if err := c.ListenAndServe(ctx); err != nil {
+				return
+			}
+		}()
+		return c
+	}
+	listenU := func(addr string) *gtpv1.UPlaneConn {
+		pc, err := net.ListenPacket("udp", addr+":2152")
+		if err != nil {
+			t.Fatal(err)
+		}
+		u := gtpv1.NewUPlaneConnWithPacketConn(pc)
+		go func() {
+			if err := u.ListenAndServe(ctx); err != nil {
+				return
+			}
+		}()
+		return u
+	}
+
+	localC, localU := listenC("127.0.0.35", 0), listenU("127.0.0.35")
+	defer localC.Close()
+	defer localU.Close()
+	peerC, peerU := listenC("127.0.0.36", 1), listenU("127.0.0.36")
+
+	stateCh := make(chan v2.PathState, 10)
+	restartCh := make(chan uint8, 10)
+
+	pm := v2.NewPathManager(50*time.Millisecond, 2)
+	pm.RegisterConn(localC)
+	pm.RegisterUPlaneConn(localU)
+	pm.SetPathStateHandler(func(peer *v2.PathPeer, state v2.PathState) {
+		stateCh <- state
+	})
+	pm.SetPeerRestartHandler(func(peer *v2.PathPeer, restartCounter uint8) {
+		restartCh <- restartCounter
+	})
+
+	cAddr, err := net.ResolveUDPAddr("udp", "127.0.0.36"+v2.GTPCPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uAddr, err := net.ResolveUDPAddr("udp", "127.0.0.36:2152")
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer := pm.AddPeer("pgw", cAddr, uAddr)
+
+	go func() {
+		if err := pm.Run(ctx); err != nil {
+			return
+		}
+	}()
+
+	waitState := func(want v2.PathState) {
+		t.Helper()
+		select {
+		case got := <-stateCh:
+			if got != want {
+				t.Fatalf("wrong state. want: %s, got: %s", want, got)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("timed out while waiting for the state to be %s", want)
+		}
+	}
+
+	waitState(v2.PathStateUp)
+	if counter, ok := peer.RestartCounter(); !ok || counter != 1 {
+		t.Errorf("wrong restart counter. want: 1, got: %d(%v)", counter, ok)
+	}
+
+	// the peer is down if either of the paths is down.
+	if err := peerU.Close(); err != nil {
+		t.Fatal(err)
+	}
+	waitState(v2.PathStateDown)
+
+	// the peer restarts with the restart counter incremented.
+	if err := peerC.Close(); err != nil {
+		t.Fatal(err)
+	}
+	peerC, peerU = listenC("127.0.0.36", 2), listenU("127.0.0.36")
+	defer peerC.Close()
+	defer peerU.Close()
+
+	select {
+	case got := <-restartCh:
+		if got != 2 {
+			t.Errorf("wrong restart counter. want: 2, got: %d", got)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out while waiting for the restart to be detected")
+	}
+	waitState(v2.PathStateUp)
+}
@@ -0,0 +1,229 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// maxRestartRecords is the number of RestartRecords kept per peer.
+const maxRestartRecords = 16
+
+// PathInfo is the quality of the path toward a peer measured with the Echo
+// Request/Response exchanges.
+type PathInfo struct {
+	// Peer is the IP address of the peer.
+	Peer string
+
+	// LastRTT is the round trip time measured with the last Echo Response, and
+	// SmoothedRTT and RTTVariation are calculated in the same way as TCP(RFC 6298).
+	LastRTT      time.Duration
+	SmoothedRTT  time.Duration
+	RTTVariation time.Duration
+
+	LastRequestTime  time.Time
+	LastResponseTime time.Time
+
+	// Sent and Received are the numbers of Echo Requests sent and Echo Responses
+	// received. Lost is the number of Echo Requests not responded until the next
+	// one is sent toward the peer.
+	Sent, Received, Lost uint64
+
+	// RestartCounters is the history of the restart counter in the Recovery IE
+	// received in Echo Response, in the order they are observed. Only the latest
+	// ones are kept.
+	RestartCounters []*RestartRecord
+}
+
+// RestartRecord is a restart counter observed in Echo Response.
+type RestartRecord struct {
+	Counter    uint8
+	ObservedAt time.Time
+}
+
+// PathInfoFunc is called with the PathInfo updated by Echo Request/Response.
+type PathInfoFunc func(c *Conn, info *PathInfo)
+
+// SetPathInfoHandler sets the function called every time the PathInfo of a peer is
+// updated, i.e., when an Echo Response is received or an Echo Request is found lost.
+// Giving nil stops it.
+//
+// This is useful to graph the health of the paths. The PathInfo given is a copy
+// that can be kept by the function.
+func (c *Conn) SetPathInfoHandler(fn PathInfoFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pathInfoFn = fn
+}
+
+// PathInfo returns the PathInfo of peer. The port of peer is ignored, as the
+// Echo Responses may come from the port other than the one Echo Requests are sent to.
+//
+// It returns false if no Echo Request has been sent to peer.
+func (c *Conn) PathInfo(peer net.Addr) (*PathInfo, bool) {
+	return c.paths.load(hostOf(peer))
+}
+
+// PathInfos returns the PathInfo of all the peers that Echo Request has been sent
+// to, ordered by the peer.
+func (c *Conn) PathInfos() []*PathInfo {
+	return c.paths.all()
+}
+
+// pathEchoSent records the Echo Request sent toward peer with seq.
+func (c *Conn) pathEchoSent(peer net.Addr, seq uint32) {
+	if info, lost := c.paths.sent(hostOf(peer), seq, time.Now()); lost {
+		c.notifyPathInfo(info)
+	}
+}
+
+// pathEchoReceived records the Echo Response received from peer.
+func (c *Conn) pathEchoReceived(peer net.Addr, res *message.EchoResponse) {
+	var counter *uint8
+	if res.Recovery != nil {
+		if v, err := res.Recovery.Recovery(); err == nil {
+			counter = &v
+		}
+	}
+
+	if info, ok := c.paths.received(hostOf(peer), res.Sequence(), counter, time.Now()); ok {
+		c.notifyPathInfo(info)
+	}
+}
+
+func (c *Conn) notifyPathInfo(info *PathInfo) {
+	c.mu.Lock()
+	fn := c.pathInfoFn
+	c.mu.Unlock()
+
+	if fn != nil {
+		fn(c, info)
+	}
+}
+
+type pathRecord struct {
+	info    PathInfo
+	pending map[uint32]time.Time
+}
+
+// snapshot returns a copy of the PathInfo.
+func (r *pathRecord) snapshot() *PathInfo {
+	info := r.info
+	info.RestartCounters = make([]*RestartRecord, len(r.info.RestartCounters))
+	for i, rec := range r.info.RestartCounters {
+		cp := *rec
+		info.RestartCounters[i] = &cp
+	}
+	return &info
+}
+
+type pathStats struct {
+	mu    sync.Mutex
+	peers map[string]*pathRecord
+}
+
+func (p *pathStats) load(peer string) (*PathInfo, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	r, ok := p.peers[peer]
+	if !ok {
+		return nil, false
+	}
+	return r.snapshot(), true
+}
+
+func (p *pathStats) all() []*PathInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	infos := make([]*PathInfo, 0, len(p.peers))
+	for _, r := range p.peers {
+		infos = append(infos, r.snapshot())
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Peer < infos[j].Peer })
+	return infos
+}
+
+// sent records the Echo Request, and counts the ones pending as lost. It returns
+// the PathInfo and true if any is lost.
+func (p *pathStats) sent(peer string, seq uint32, now time.Time) (*PathInfo, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.peers == nil {
+		p.peers = map[string]*pathRecord{}
+	}
+	r, ok := p.peers[peer]
+	if !ok {
+		r = &pathRecord{info: PathInfo{Peer: peer}}
+		p.peers[peer] = r
+	}
+
+	lost := len(r.pending)
+	r.info.Lost += uint64(lost)
+	r.info.Sent++
+	r.info.LastRequestTime = now
+	r.pending = map[uint32]time.Time{seq: now}
+
+	if lost == 0 {
+		return nil, false
+	}
+	return r.snapshot(), true
+}
+
+// received records the Echo Response with seq and the restart counter(if any).
+// It returns the PathInfo and true if the Echo Request is sent from Conn.
+func (p *pathStats) received(peer string, seq uint32, counter *uint8, now time.Time) (*PathInfo, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	r, ok := p.peers[peer]
+	if !ok {
+		return nil, false
+	}
+
+	r.info.Received++
+	r.info.LastResponseTime = now
+	if sentAt, ok := r.pending[seq]; ok {
+		delete(r.pending, seq)
+		r.updateRTT(now.Sub(sentAt))
+	}
+
+	if counter != nil {
+		history := r.info.RestartCounters
+		if n := len(history); n == 0 || history[n-1].Counter != *counter {
+			history = append(history, &RestartRecord{Counter: *counter, ObservedAt: now})
+			if len(history) > maxRestartRecords {
+				history = history[len(history)-maxRestartRecords:]
+			}
+			r.info.RestartCounters = history
+		}
+	}
+
+	return r.snapshot(), true
+}
+
+func (r *pathRecord) updateRTT(rtt time.Duration) {
+	r.info.LastRTT = rtt
+	if r.info.SmoothedRTT == 0 {
+		r.info.SmoothedRTT = rtt
+		r.info.RTTVariation = rtt / 2
+		return
+	}
+
+	diff := r.info.SmoothedRTT - rtt
+	if diff < 0 {
+		diff = -diff
+	}
+	r.info.RTTVariation = (3*r.info.RTTVariation + diff) / 4
+	r.info.SmoothedRTT = (7*r.info.SmoothedRTT + rtt) / 8
+}
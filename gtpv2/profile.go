@@ -0,0 +1,144 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// AMBR is the APN Aggregate Maximum Bit Rate in kbps.
+type AMBR struct {
+	UL, DL uint32
+}
+
+// BearerProfile is the QoS of a Bearer identified by EBI.
+type BearerProfile struct {
+	EBI uint8
+	*QoSProfile
+}
+
+// SubscriberProfile is a high-level model of the subscription used to configure
+// Sessions in domain terms, which can be converted to/from the IEs.
+type SubscriberProfile struct {
+	IMSI, MSISDN, APN string
+	AMBR              *AMBR
+	Bearers           []*BearerProfile
+}
+
+// ToIEs returns the IMSI, MSISDN, APN, AMBR and Bearer Context IEs with EBI and
+// Bearer QoS in it, built from the SubscriberProfile. The fields with zero value
+// are omitted.
+//
+// The IEs can be given to the methods that send messages as they are, e.g.,
+// CreateSession. CreateSessionRequest keeps all the Bearers, while the messages that
+// hold only one Bearer Context, like ModifyBearerRequest and CreateBearerRequest,
+// keep only the last one of them.
+func (p *SubscriberProfile) ToIEs() []*ie.IE {
+	var ies []*ie.IE
+	if p.IMSI != "" {
+		ies = append(ies, ie.NewIMSI(p.IMSI))
+	}
+	if p.MSISDN != "" {
+		ies = append(ies, ie.NewMSISDN(p.MSISDN))
+	}
+	if p.APN != "" {
+		ies = append(ies, ie.NewAccessPointName(p.APN))
+	}
+	if p.AMBR != nil {
+		ies = append(ies, ie.NewAggregateMaximumBitRate(p.AMBR.UL, p.AMBR.DL))
+	}
+
+	for _, br := range p.Bearers {
+		if br.QoSProfile == nil {
+			ies = append(ies, ie.NewBearerContext(ie.NewEPSBearerID(br.EBI)))
+			continue
+		}
+		ies = append(ies, ie.NewBearerContext(ie.NewEPSBearerID(br.EBI), br.ToIE()))
+	}
+	return ies
+}
+
+// SubscriberProfileFromMessage retrieves the SubscriberProfile from the IEs in the
+// message. The fields are left empty if the corresponding IEs are not found.
+//
+// Only the Bearer Contexts with instance 0 are looked at, which are the ones to be
+// created or modified in the messages like Create Session Request.
+func SubscriberProfileFromMessage(msg message.Message) (*SubscriberProfile, error) {
+	b, err := message.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	generic, err := message.ParseGeneric(b)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &SubscriberProfile{}
+	for _, i := range generic.IEs {
+		switch i.Type {
+		case ie.IMSI:
+			if p.IMSI, err = i.IMSI(); err != nil {
+				return nil, err
+			}
+		case ie.MSISDN:
+			if p.MSISDN, err = i.MSISDN(); err != nil {
+				return nil, err
+			}
+		case ie.AccessPointName:
+			if p.APN, err = i.AccessPointName(); err != nil {
+				return nil, err
+			}
+		case ie.AggregateMaximumBitRate:
+			f, err := i.AggregateMaximumBitRate()
+			if err != nil {
+				return nil, err
+			}
+			p.AMBR = &AMBR{UL: f.APNAMBRForUplink, DL: f.APNAMBRForDownlink}
+		case ie.BearerContext:
+			if i.Instance() != 0 {
+				continue
+			}
+			br, err := bearerProfileFromIE(i)
+			if err != nil {
+				return nil, err
+			}
+			p.Bearers = append(p.Bearers, br)
+		}
+	}
+	return p, nil
+}
+
+func bearerProfileFromIE(brCtxIE *ie.IE) (*BearerProfile, error) {
+	children, err := brCtxIE.BearerContext()
+	if err != nil {
+		return nil, err
+	}
+
+	br := &BearerProfile{}
+	for _, child := range children {
+		switch child.Type {
+		case ie.EPSBearerID:
+			if br.EBI, err = child.EPSBearerID(); err != nil {
+				return nil, err
+			}
+		case ie.BearerQoS:
+			if br.QoSProfile, err = QoSProfileFromIE(child); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return br, nil
+}
+
+// ToIE returns the Bearer QoS IE built from the QoSProfile.
+func (q *QoSProfile) ToIE() *ie.IE {
+	return newBearerQoSIE(q)
+}
+
+// QoSProfileFromIE retrieves the QoSProfile from Bearer QoS IE.
+func QoSProfileFromIE(qosIE *ie.IE) (*QoSProfile, error) {
+	return parseBearerQoSIE(qosIE)
+}
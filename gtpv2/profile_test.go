@@ -0,0 +1,65 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v2 "github.com/wmnsk/go-gtp/gtpv2"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+func TestSubscriberProfile(t *testing.T) {
+	profile := &v2.SubscriberProfile{
+		IMSI:   "123451234567890",
+		MSISDN: "8130900000000",
+		APN:    "some.apn.example",
+		AMBR:   &v2.AMBR{UL: 0x11111111, DL: 0x22222222},
+		Bearers: []*v2.BearerProfile{
+			{
+				EBI: 5,
+				QoSProfile: &v2.QoSProfile{
+					PCI: true, PL: 2, PVI: true, QCI: 9,
+					MBRUL: 0x11111111, MBRDL: 0x22222222,
+				},
+			}, {
+				EBI: 6,
+				QoSProfile: &v2.QoSProfile{
+					PL: 1, QCI: 1,
+					MBRUL: 0x33333333, MBRDL: 0x44444444,
+					GBRUL: 0x55555555, GBRDL: 0x66666666,
+				},
+			},
+		},
+	}
+
+	t.Run("generic", func(t *testing.T) {
+		msg := message.NewGeneric(message.MsgTypeCreateSessionRequest, 0, 0, profile.ToIEs()...)
+		got, err := v2.SubscriberProfileFromMessage(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff(profile, got); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("create-session-request", func(t *testing.T) {
+		single := *profile
+		single.Bearers = profile.Bearers[:1]
+
+		msg := message.NewCreateSessionRequest(0, 0, single.ToIEs()...)
+		got, err := v2.SubscriberProfileFromMessage(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff(&single, got); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
@@ -0,0 +1,198 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"container/list"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// EnableResponseCache makes the Conn suppress the retransmitted requests, keeping
+// the responses sent for window, up to size requests.
+//
+// TS 29.274 7.6 requires a node receiving the retransmitted request to send the
+// same response again instead of executing the procedure again. Once enabled, the
+// requests are identified by the peer, Sequence Number and message type, and;
+//
+//	the first one is passed to HandlerFunc as usual, and the response sent with
+//	RespondTo (or the automatic error response) is kept in the cache.
+//	the retransmitted one is not passed to HandlerFunc, and the response in the
+//	cache is sent again. If HandlerFunc has not responded yet, it is discarded.
+//
+// The response sent without RespondTo, e.g., WriteTo with the bytes crafted by
+// the user, is not kept. The requests are then discarded until expired.
+//
+// window should be long enough to cover the retransmissions by the peer, i.e.,
+// T3-RESPONSE * N3-REQUESTS. Calling this again replaces the cache. If size is less
+// than 1, the cache is disabled as DisableResponseCache does.
+func (c *Conn) EnableResponseCache(window time.Duration, size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if size < 1 {
+		c.respCache = nil
+		return
+	}
+	c.respCache = newResponseCache(window, size)
+}
+
+// DisableResponseCache turns off the response cache enabled by EnableResponseCache,
+// and discards the responses in it.
+func (c *Conn) DisableResponseCache() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.respCache = nil
+}
+
+// SuppressedRequests returns the number of retransmitted requests that are not
+// passed to HandlerFunc by the response cache.
+func (c *Conn) SuppressedRequests() uint64 {
+	rc := c.loadResponseCache()
+	if rc == nil {
+		return 0
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.suppressed
+}
+
+func (c *Conn) loadResponseCache() *responseCache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.respCache
+}
+
+// suppressDuplicate reports whether the request is a retransmission that should not
+// be handled, and sends the cached response again if available.
+func (c *Conn) suppressDuplicate(raddr net.Addr, msg message.Message) bool {
+	rc := c.loadResponseCache()
+	if rc == nil {
+		return false
+	}
+	if _, ok := errorResponseTypes[msg.MessageType()]; !ok {
+		return false
+	}
+
+	rsp, dup := rc.reserve(newResponseCacheKey(raddr, msg.MessageType(), msg.Sequence()))
+	if !dup {
+		return false
+	}
+	if rsp == nil {
+		logf("discarded retransmitted %s from %s: still being handled", msg.MessageTypeName(), raddr)
+		return true
+	}
+	if _, err := c.WriteTo(rsp, raddr); err != nil {
+		logf("failed to resend the response to %s: %s", raddr, err)
+	}
+	return true
+}
+
+// cacheResponse keeps the response to the request of reqType and seq, if the
+// response cache is enabled.
+func (c *Conn) cacheResponse(raddr net.Addr, reqType uint8, seq uint32, rsp []byte) {
+	rc := c.loadResponseCache()
+	if rc == nil {
+		return
+	}
+	if _, ok := errorResponseTypes[reqType]; !ok {
+		return
+	}
+
+	rc.store(newResponseCacheKey(raddr, reqType, seq), rsp)
+}
+
+type responseCacheKey struct {
+	peer    string
+	msgType uint8
+	seq     uint32
+}
+
+func newResponseCacheKey(raddr net.Addr, msgType uint8, seq uint32) responseCacheKey {
+	return responseCacheKey{peer: raddr.String(), msgType: msgType, seq: seq}
+}
+
+type responseCacheEntry struct {
+	key      responseCacheKey
+	received time.Time
+
+	// response is nil while the request is being handled.
+	response []byte
+}
+
+// responseCache keeps the entries in the order of arrival, so that the oldest ones
+// are always at the front to be expired or evicted first.
+type responseCache struct {
+	mu         sync.Mutex
+	window     time.Duration
+	size       int
+	entries    map[responseCacheKey]*list.Element
+	order      *list.List
+	suppressed uint64
+}
+
+func newResponseCache(window time.Duration, size int) *responseCache {
+	return &responseCache{
+		window:  window,
+		size:    size,
+		entries: map[responseCacheKey]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+// reserve looks up the entry for the request. If not found, it adds the entry to be
+// filled by store and returns false.
+func (rc *responseCache) reserve(key responseCacheKey) (rsp []byte, dup bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.prune(time.Now())
+	if elem, ok := rc.entries[key]; ok {
+		rc.suppressed++
+		return elem.Value.(*responseCacheEntry).response, true
+	}
+
+	rc.add(&responseCacheEntry{key: key, received: time.Now()})
+	return nil, false
+}
+
+func (rc *responseCache) store(key responseCacheKey, rsp []byte) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.prune(time.Now())
+	if elem, ok := rc.entries[key]; ok {
+		elem.Value.(*responseCacheEntry).response = rsp
+		return
+	}
+	rc.add(&responseCacheEntry{key: key, received: time.Now(), response: rsp})
+}
+
+func (rc *responseCache) add(entry *responseCacheEntry) {
+	rc.entries[entry.key] = rc.order.PushBack(entry)
+	for rc.order.Len() > rc.size {
+		rc.remove(rc.order.Front())
+	}
+}
+
+func (rc *responseCache) prune(now time.Time) {
+	for elem := rc.order.Front(); elem != nil; elem = rc.order.Front() {
+		if now.Sub(elem.Value.(*responseCacheEntry).received) < rc.window {
+			return
+		}
+		rc.remove(elem)
+	}
+}
+
+func (rc *responseCache) remove(elem *list.Element) {
+	rc.order.Remove(elem)
+	delete(rc.entries, elem.Value.(*responseCacheEntry).key)
+}
@@ -0,0 +1,75 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"net"
+)
+
+type outboundPacket struct {
+	payload []byte
+	addr    net.Addr
+}
+
+// EnableSendQueue makes the Conn send packets in background, with the queue that
+// can hold depth packets.
+//
+// Once enabled, WriteTo and the methods built on it(SendMessageTo, RespondTo, and
+// all the methods that send a message) return immediately after the packet is
+// queued, or ErrSendQueueFull if the queue is full. This lets callers apply their
+// own backpressure instead of being blocked by the socket. Errors on the actual
+// write are only logged.
+//
+// The queue cannot be disabled or resized once enabled. The packets left in the
+// queue are discarded when the Conn is closed.
+func (c *Conn) EnableSendQueue(depth int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sendQueue != nil {
+		return
+	}
+	c.sendQueue = make(chan *outboundPacket, depth)
+	go c.drainSendQueue(c.sendQueue)
+}
+
+// SendQueueLen returns the number of packets waiting in the queue to be sent.
+// It always returns 0 if the queue is not enabled with EnableSendQueue.
+func (c *Conn) SendQueueLen() int {
+	return len(c.loadSendQueue())
+}
+
+func (c *Conn) loadSendQueue() chan *outboundPacket {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.sendQueue
+}
+
+func (c *Conn) enqueue(q chan<- *outboundPacket, p []byte, addr net.Addr) (int, error) {
+	// copy p, as the caller may reuse it after WriteTo returns.
+	payload := make([]byte, len(p))
+	copy(payload, p)
+
+	select {
+	case q <- &outboundPacket{payload: payload, addr: addr}:
+		return len(p), nil
+	default:
+		return 0, ErrSendQueueFull
+	}
+}
+
+func (c *Conn) drainSendQueue(q <-chan *outboundPacket) {
+	for {
+		select {
+		case <-c.closed():
+			return
+		case pkt := <-q:
+			if _, err := c.pktConn.WriteTo(pkt.payload, pkt.addr); err != nil {
+				logf("failed to send a packet to %s on Conn %s: %s", pkt.addr, c.LocalAddr(), err)
+			}
+		}
+	}
+}
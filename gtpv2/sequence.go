@@ -0,0 +1,201 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"net"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// The spaces of Sequence Number defined in TS 29.274 7.6.
+//
+// The requests initiated by the node use the lower half, and the Command messages
+// use the upper half, whose MSB is set to 1. The triggered messages(responses and
+// the requests triggered by Command) copy the Sequence Number from the triggering one.
+const (
+	seqSpaceMask   uint32 = 0x7fffff
+	seqCommandFlag uint32 = 0x800000
+)
+
+// maxSequenceSpaces is the number of peers whose Sequence Numbers are kept. The one
+// used least recently is discarded to add a new one, and the next message toward it
+// uses 1 again.
+const maxSequenceSpaces = 4096
+
+// PeerSequence is the Sequence Numbers last used in the messages sent toward a peer.
+type PeerSequence struct {
+	Peer string `json:"peer"`
+
+	// Request is the one used in the requests initiated by the node, which is in the
+	// range of 0x000000-0x7fffff.
+	Request uint32 `json:"request"`
+
+	// Command is the one used in the Command messages, which is in the range of
+	// 0x800000-0xffffff, or 0 if no Command message is sent.
+	Command uint32 `json:"command"`
+}
+
+type sequenceSpace struct {
+	request, command uint32
+	used             uint64
+}
+
+// isCommand reports whether the message is a Command message, which uses the
+// dedicated space of Sequence Number.
+func isCommand(msgType uint8) bool {
+	switch msgType {
+	case message.MsgTypeModifyBearerCommand, message.MsgTypeDeleteBearerCommand, message.MsgTypeBearerResourceCommand:
+		return true
+	}
+	return false
+}
+
+// nextSequence allocates a new Sequence Number for the message of msgType to be
+// sent toward peer.
+func (c *Conn) nextSequence(peer net.Addr, msgType uint8) uint32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	space := c.sequenceSpace(peer.String())
+
+	var seq uint32
+	if isCommand(msgType) {
+		space.command = (space.command + 1) & seqSpaceMask
+		seq = space.command | seqCommandFlag
+	} else {
+		space.request = (space.request + 1) & seqSpaceMask
+		seq = space.request
+	}
+	c.sequence = seq
+
+	return seq
+}
+
+// releaseSequence gives back the Sequence Number allocated with nextSequence if the
+// message is not sent, as long as no other one is allocated after it.
+func (c *Conn) releaseSequence(peer net.Addr, msgType uint8, seq uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	space := c.sequenceSpace(peer.String())
+	if isCommand(msgType) {
+		if space.command|seqCommandFlag == seq {
+			space.command = (space.command - 1) & seqSpaceMask
+		}
+	} else if space.request == seq {
+		space.request = (space.request - 1) & seqSpaceMask
+	}
+	if c.sequence == seq {
+		c.sequence--
+	}
+}
+
+// sequenceSpace returns the space of Sequence Number for peer, discarding the one
+// used least recently if there are too many. c.mu must be held.
+func (c *Conn) sequenceSpace(peer string) *sequenceSpace {
+	if c.seqSpaces == nil {
+		c.seqSpaces = map[string]*sequenceSpace{}
+	}
+	space, ok := c.seqSpaces[peer]
+	if !ok {
+		if len(c.seqSpaces) >= maxSequenceSpaces {
+			c.evictSequenceSpace()
+		}
+		space = &sequenceSpace{}
+		c.seqSpaces[peer] = space
+	}
+	c.seqTick++
+	space.used = c.seqTick
+	return space
+}
+
+// evictSequenceSpace discards the space of Sequence Number used least recently.
+// c.mu must be held.
+func (c *Conn) evictSequenceSpace() {
+	var (
+		oldest string
+		used   uint64
+	)
+	for peer, space := range c.seqSpaces {
+		if oldest == "" || space.used < used {
+			oldest, used = peer, space.used
+		}
+	}
+	delete(c.seqSpaces, oldest)
+}
+
+// PeerSequence returns the Sequence Numbers last used toward peer.
+func (c *Conn) PeerSequence(peer net.Addr) *PeerSequence {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ps := &PeerSequence{Peer: peer.String()}
+	if space, ok := c.seqSpaces[ps.Peer]; ok {
+		ps.Request = space.request
+		if space.command != 0 {
+			ps.Command = space.command | seqCommandFlag
+		}
+	}
+	return ps
+}
+
+// PeerSequences returns the Sequence Numbers last used toward all the peers that
+// any message is sent to, ordered by the peer. Only the 4096 peers used most recently
+// are kept.
+func (c *Conn) PeerSequences() []*PeerSequence {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pss := make([]*PeerSequence, 0, len(c.seqSpaces))
+	for peer, space := range c.seqSpaces {
+		ps := &PeerSequence{Peer: peer, Request: space.request}
+		if space.command != 0 {
+			ps.Command = space.command | seqCommandFlag
+		}
+		pss = append(pss, ps)
+	}
+	sort.Slice(pss, func(i, j int) bool { return pss[i].Peer < pss[j].Peer })
+
+	return pss
+}
+
+// SetPeerSequence sets the Sequence Numbers last used toward the peer in ps, e.g.,
+// to continue from the values before the restart. The next message toward the
+// peer uses the value next to them.
+func (c *Conn) SetPeerSequence(ps *PeerSequence) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	space := c.sequenceSpace(ps.Peer)
+	space.request = ps.Request & seqSpaceMask
+	space.command = ps.Command & seqSpaceMask
+}
+
+// ResetPeerSequence resets the Sequence Numbers toward peer, e.g., when the peer
+// is restarted. The next message toward the peer uses 1.
+func (c *Conn) ResetPeerSequence(peer net.Addr) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.seqSpaces, peer.String())
+}
+
+// RetransmitTo sends the request sent with SendMessageTo again to addr, keeping
+// the Sequence Number in it so that the peer can detect the retransmission and the
+// response to any of them can be matched with the request.
+func (c *Conn) RetransmitTo(msg message.Message, addr net.Addr) error {
+	payload, err := message.Marshal(msg)
+	if err != nil {
+		return errors.Wrapf(err, "failed to retransmit %T", msg)
+	}
+
+	if _, err := c.WriteTo(payload, addr); err != nil {
+		return errors.Wrapf(err, "failed to retransmit %T", msg)
+	}
+	return nil
+}
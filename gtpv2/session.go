@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/wmnsk/go-gtp/gtpv2/ie"
 	"github.com/wmnsk/go-gtp/gtpv2/message"
 )
 
@@ -33,6 +34,20 @@ type Session struct {
 	*teidMap
 	*bearerMap
 
+	// ebiReserved is the EBIs allocated with AllocateEBI but not yet used by any Bearer.
+	ebiReserved map[uint8]struct{}
+
+	// fqCSIDs is the FQ-CSIDs associated with Session, keyed by the node type.
+	fqCSIDs map[uint8]*FQCSID
+
+	// conn is the Conn that Session is registered to, which is used to call the
+	// LifecycleHooks. trigger is the message that triggers the events.
+	conn    *Conn
+	trigger message.Message
+
+	// lastSeen is the last time a message for the Session is received.
+	lastSeen time.Time
+
 	// channel to store message passed by other Sessions
 	msgQueue chan message.Message
 
@@ -57,6 +72,8 @@ func NewSession(peerAddr net.Addr, sub *Subscriber) *Session {
 		peerAddrString: peerAddr.String(),
 		teidMap:        newTeidMap(),
 		bearerMap:      newBearerMap("default", &Bearer{QoSProfile: &QoSProfile{}}),
+		ebiReserved:    map[uint8]struct{}{},
+		fqCSIDs:        map[uint8]*FQCSID{},
 		Subscriber:     sub,
 		msgQueue:       make(chan message.Message, 1000),
 	}
@@ -65,15 +82,21 @@ func NewSession(peerAddr net.Addr, sub *Subscriber) *Session {
 }
 
 // Activate marks a Session active.
+//
+// OnSessionActivated in LifecycleHooks is called only if the Session is inactive.
 func (s *Session) Activate() error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	if s.IMSI == "" {
+		s.mu.Unlock()
 		return &RequiredParameterMissingError{"IMSI", "Session must have IMSI set"}
 	}
-
+	wasActive := s.isActive
 	s.isActive = true
+	s.mu.Unlock()
+
+	if !wasActive {
+		s.fireSessionEvent(func(h *LifecycleHooks) SessionHookFunc { return h.OnSessionActivated })
+	}
 	return nil
 }
 
@@ -124,6 +147,39 @@ func (s *Session) GetTEID(ifType uint8) (uint32, error) {
 	return 0, ErrTEIDNotFound
 }
 
+// GetFQCSID returns FQ-CSID of the node type given.
+func (s *Session) GetFQCSID(nodeType uint8) (*FQCSID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fqCSID, ok := s.fqCSIDs[nodeType]; ok {
+		return fqCSID, nil
+	}
+	return nil, ErrFQCSIDNotFound
+}
+
+// FQCSIDs returns all the FQ-CSIDs associated with Session, keyed by the node type.
+func (s *Session) FQCSIDs() map[uint8]*FQCSID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := make(map[uint8]*FQCSID, len(s.fqCSIDs))
+	for k, v := range s.fqCSIDs {
+		m[k] = v
+	}
+	return m
+}
+
+// setFQCSID sets FQ-CSID of the node type given and returns the old one if exists.
+func (s *Session) setFQCSID(nodeType uint8, fqCSID *FQCSID) *FQCSID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.fqCSIDs[nodeType]
+	s.fqCSIDs[nodeType] = fqCSID
+	return old
+}
+
 // PassMessageTo passes the message (typically "triggerred message") to the session
 // expecting to receive it.
 //
@@ -166,11 +222,21 @@ func (s *Session) WaitMessage(seq uint32, timeout time.Duration) (message.Messag
 // always available after created a Session.
 func (s *Session) AddBearer(name string, br *Bearer) {
 	s.bearerMap.store(name, br)
+	s.ReleaseEBI(br.EBI)
+	s.fireBearerEvent(func(h *LifecycleHooks) BearerHookFunc { return h.OnBearerAdded }, br)
 }
 
 // RemoveBearer removes a Bearer looked up by name.
 func (s *Session) RemoveBearer(name string) {
+	br, ok := s.bearerMap.load(name)
+	if !ok {
+		return
+	}
 	s.bearerMap.delete(name)
+	s.fireBearerEvent(func(h *LifecycleHooks) BearerHookFunc { return h.OnBearerRemoved }, br)
+	if c, _ := s.registeredConn(); c != nil {
+		c.releaseChargingID(br)
+	}
 }
 
 // RemoveBearerByEBI removes a Bearer looked up by name.
@@ -179,7 +245,7 @@ func (s *Session) RemoveBearerByEBI(ebi uint8) {
 	if err != nil {
 		return
 	}
-	s.bearerMap.delete(name)
+	s.RemoveBearer(name)
 }
 
 // GetDefaultBearer returns the default bearer.
@@ -265,7 +331,7 @@ func (s *Session) LookupEBIByTEID(teid uint32) uint8 {
 	var ebi uint8
 	s.bearerMap.rangeWithFunc(func(name, bearer interface{}) bool {
 		br := bearer.(*Bearer)
-		if teid == br.teidIn || teid == br.teidOut {
+		if teid == br.IncomingTEID() || teid == br.OutgoingTEID() {
 			ebi = br.EBI
 			return false
 		}
@@ -275,6 +341,83 @@ func (s *Session) LookupEBIByTEID(teid uint32) uint8 {
 	return ebi
 }
 
+// lookupBearerByContext returns the Bearer looked up by EBI in the Bearer Context IE given.
+func (s *Session) lookupBearerByContext(brCtxIE *ie.IE) (*Bearer, error) {
+	ebiIE, err := brCtxIE.FindByType(ie.EPSBearerID, 0)
+	if err != nil {
+		return nil, &RequiredIEMissingError{Type: ie.EPSBearerID}
+	}
+	ebi, err := ebiIE.EPSBearerID()
+	if err != nil {
+		return nil, err
+	}
+	return s.LookupBearerByEBI(ebi)
+}
+
+// updateBearerFTEIDs stores the F-TEIDs contained in the Bearer Context IE given
+// in the Session with their interface types.
+//
+// If bearer is not nil, the TEID and IP address in the F-TEID of the peer are also
+// set as the outgoing TEID and remote address of it. The F-TEID of the peer is the
+// first one with any of the interface types in peerIfTypes, or the one with instance
+// 0 if peerIfTypes is empty. The others, such as the ones of this node echoed back in
+// the responses, are never used for bearer.
+func (s *Session) updateBearerFTEIDs(brCtxIE *ie.IE, bearer *Bearer, peerIfTypes []uint8) error {
+	var peerFTEID *ie.IE
+	for _, child := range brCtxIE.ChildIEs {
+		if child.Type != ie.FullyQualifiedTEID {
+			continue
+		}
+
+		it, err := child.InterfaceType()
+		if err != nil {
+			return err
+		}
+		teid, err := child.TEID()
+		if err != nil {
+			return err
+		}
+		s.AddTEID(it, teid)
+
+		if peerFTEID == nil && isPeerFTEID(child.Instance(), it, peerIfTypes) {
+			peerFTEID = child
+		}
+	}
+
+	if bearer == nil || peerFTEID == nil {
+		return nil
+	}
+
+	teid, err := peerFTEID.TEID()
+	if err != nil {
+		return err
+	}
+	ip, err := peerFTEID.IPAddress()
+	if err != nil {
+		return err
+	}
+	addr, err := net.ResolveUDPAddr("udp", ip+GTPUPort)
+	if err != nil {
+		return err
+	}
+	bearer.SetRemoteAddress(addr)
+	bearer.SetOutgoingTEID(teid)
+
+	return nil
+}
+
+func isPeerFTEID(instance, ifType uint8, peerIfTypes []uint8) bool {
+	if len(peerIfTypes) == 0 {
+		return instance == 0
+	}
+	for _, it := range peerIfTypes {
+		if it == ifType {
+			return true
+		}
+	}
+	return false
+}
+
 type teidMap struct {
 	syncMap sync.Map
 }
@@ -296,6 +439,10 @@ func (t *teidMap) load(ifType uint8) (uint32, bool) {
 	return teid.(uint32), true
 }
 
+func (t *teidMap) rangeWithFunc(fn func(ifType, teid interface{}) bool) {
+	t.syncMap.Range(fn)
+}
+
 type bearerMap struct {
 	syncMap sync.Map
 }
@@ -354,3 +501,87 @@ func (s *Session) BearerCount() int {
 
 	return count
 }
+
+// SessionInfo is a snapshot of Session.
+//
+// It is a copy of the values at the time Info is called, and is safe to be read
+// without racing with the Conn that keeps updating the Session. The exported fields
+// of Bearers are copied as they are, so they should not be changed after the
+// Bearers are added to the Session; use the setters for the TEIDs and the remote
+// address instead.
+type SessionInfo struct {
+	IMSI, MSISDN, IMEI string
+	PeerAddr           net.Addr
+	Active             bool
+
+	// TEIDs are the TEIDs associated with Session, keyed by InterfaceType.
+	TEIDs map[uint8]uint32
+
+	// FQCSIDs are the FQ-CSIDs associated with Session, keyed by the node type.
+	FQCSIDs map[uint8]FQCSID
+
+	// Bearers are the Bearers in Session, keyed by the name.
+	Bearers map[string]BearerInfo
+}
+
+// BearerInfo is a snapshot of Bearer.
+type BearerInfo struct {
+	EBI                        uint8
+	APN, SubscriberIP          string
+	ChargingID                 uint32
+	IncomingTEID, OutgoingTEID uint32
+	RemoteAddress              net.Addr
+	QoSProfile                 QoSProfile
+}
+
+// Info returns a snapshot of Session.
+func (s *Session) Info() *SessionInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info := &SessionInfo{
+		PeerAddr: s.peerAddr,
+		Active:   s.isActive,
+		TEIDs:    map[uint8]uint32{},
+		FQCSIDs:  map[uint8]FQCSID{},
+		Bearers:  map[string]BearerInfo{},
+	}
+	if sub := s.Subscriber; sub != nil {
+		info.IMSI, info.MSISDN, info.IMEI = sub.IMSI, sub.MSISDN, sub.IMEI
+	}
+
+	s.teidMap.rangeWithFunc(func(k, v interface{}) bool {
+		info.TEIDs[k.(uint8)] = v.(uint32)
+		return true
+	})
+	for k, v := range s.fqCSIDs {
+		csids := make([]uint16, len(v.CSIDs))
+		copy(csids, v.CSIDs)
+		info.FQCSIDs[k] = FQCSID{NodeID: v.NodeID, CSIDs: csids}
+	}
+	s.bearerMap.rangeWithFunc(func(k, v interface{}) bool {
+		info.Bearers[k.(string)] = v.(*Bearer).info()
+		return true
+	})
+
+	return info
+}
+
+func (b *Bearer) info() BearerInfo {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bi := BearerInfo{
+		EBI:           b.EBI,
+		APN:           b.APN,
+		SubscriberIP:  b.SubscriberIP,
+		ChargingID:    b.ChargingID,
+		IncomingTEID:  b.teidIn,
+		OutgoingTEID:  b.teidOut,
+		RemoteAddress: b.raddr,
+	}
+	if b.QoSProfile != nil {
+		bi.QoSProfile = *b.QoSProfile
+	}
+	return bi
+}
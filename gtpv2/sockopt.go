@@ -0,0 +1,234 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"container/list"
+	"net"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// DSCP values commonly used for GTP-C.
+const (
+	DSCPCS5 uint8 = 40
+	DSCPCS6 uint8 = 48
+	DSCPEF  uint8 = 46
+)
+
+// SocketOptions is the set of options applied to the UDP socket opened by Conn.
+//
+// The IP version of the options are determined by the local address, so it is
+// recommended to use the network "udp4" or "udp6" explicitly when the options
+// are used with an unspecified address.
+type SocketOptions struct {
+	// ReadBuffer and WriteBuffer are the size of the receive/send buffer of the socket
+	// (SO_RCVBUF/SO_SNDBUF). 0 leaves the OS default.
+	ReadBuffer, WriteBuffer int
+
+	// DSCP is the DSCP value marked on the outgoing packets, e.g., DSCPCS5, which
+	// must be 63 or less. 0 leaves the packets unmarked.
+	DSCP uint8
+
+	// PacketInfo enables IP_PKTINFO(IPV6_RECVPKTINFO) to send the packets to a peer
+	// from the local address that the peer sent to last time. This is useful when
+	// listening on the unspecified address on a multihomed host. The addresses are
+	// kept for the 4096 peers received from most recently.
+	PacketInfo bool
+
+	// DontFragment sets DF bit on the outgoing IPv4 packets, and disables the
+	// fragmentation of IPv6 packets on the local host. This is supported only on
+	// Linux.
+	DontFragment bool
+}
+
+// ListenPacket opens a UDP socket with the options given. opts can be nil.
+//
+// This is what Conn uses internally when the options are given with SetSocketOptions
+// or DialWithSocketOptions. The net.PacketConn returned can also be passed to
+// NewConnWithPacketConn.
+func ListenPacket(network, address string, opts *SocketOptions) (net.PacketConn, error) {
+	pc, err := net.ListenPacket(network, address)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		return pc, nil
+	}
+
+	conn, err := opts.apply(pc)
+	if err != nil {
+		_ = pc.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (o *SocketOptions) apply(pc net.PacketConn) (net.PacketConn, error) {
+	udpConn, ok := pc.(*net.UDPConn)
+	if !ok {
+		return nil, errors.Errorf("socket options cannot be applied to %T", pc)
+	}
+	laddr, ok := udpConn.LocalAddr().(*net.UDPAddr)
+	if !ok {
+		return nil, errors.Errorf("unexpected local address: %s", udpConn.LocalAddr())
+	}
+	isV4 := laddr.IP.To4() != nil
+
+	if o.DSCP > 63 {
+		return nil, errors.Errorf("invalid DSCP: %d", o.DSCP)
+	}
+
+	if o.ReadBuffer > 0 {
+		if err := udpConn.SetReadBuffer(o.ReadBuffer); err != nil {
+			return nil, errors.Wrap(err, "failed to set SO_RCVBUF")
+		}
+	}
+	if o.WriteBuffer > 0 {
+		if err := udpConn.SetWriteBuffer(o.WriteBuffer); err != nil {
+			return nil, errors.Wrap(err, "failed to set SO_SNDBUF")
+		}
+	}
+
+	if o.DSCP > 0 {
+		var err error
+		if isV4 {
+			err = ipv4.NewConn(udpConn).SetTOS(int(o.DSCP << 2))
+		} else {
+			err = ipv6.NewConn(udpConn).SetTrafficClass(int(o.DSCP << 2))
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to set DSCP")
+		}
+	}
+
+	if o.DontFragment {
+		if err := setDontFragment(udpConn, isV4); err != nil {
+			return nil, errors.Wrap(err, "failed to set DF")
+		}
+	}
+
+	if o.PacketInfo {
+		return newPktInfoConn(udpConn, isV4)
+	}
+	return udpConn, nil
+}
+
+// maxPktInfoPeers is the number of peers whose local IP address is kept by pktInfoConn.
+const maxPktInfoPeers = 4096
+
+// pktInfoConn is a net.PacketConn that sends the packets to a peer from the local
+// address that the peer sent to last time.
+type pktInfoConn struct {
+	*net.UDPConn
+	p4 *ipv4.PacketConn
+	p6 *ipv6.PacketConn
+
+	// localIPs is the local IP address last used by the peer, keyed by the peer address.
+	// lru orders them by the time received, to discard the oldest one beyond
+	// maxPktInfoPeers.
+	mu       sync.Mutex
+	localIPs map[string]*list.Element
+	lru      *list.List
+}
+
+type localIPEntry struct {
+	peer string
+	ip   net.IP
+}
+
+func newPktInfoConn(udpConn *net.UDPConn, isV4 bool) (*pktInfoConn, error) {
+	c := &pktInfoConn{
+		UDPConn:  udpConn,
+		localIPs: map[string]*list.Element{},
+		lru:      list.New(),
+	}
+	if isV4 {
+		c.p4 = ipv4.NewPacketConn(udpConn)
+		if err := c.p4.SetControlMessage(ipv4.FlagDst, true); err != nil {
+			return nil, errors.Wrap(err, "failed to set IP_PKTINFO")
+		}
+		return c, nil
+	}
+
+	c.p6 = ipv6.NewPacketConn(udpConn)
+	if err := c.p6.SetControlMessage(ipv6.FlagDst, true); err != nil {
+		return nil, errors.Wrap(err, "failed to set IPV6_RECVPKTINFO")
+	}
+	return c, nil
+}
+
+// ReadFrom reads a packet and remembers the local IP address it is sent to.
+func (c *pktInfoConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	var (
+		n     int
+		dst   net.IP
+		raddr net.Addr
+		err   error
+	)
+	if c.p4 != nil {
+		var cm *ipv4.ControlMessage
+		n, cm, raddr, err = c.p4.ReadFrom(b)
+		if cm != nil {
+			dst = cm.Dst
+		}
+	} else {
+		var cm *ipv6.ControlMessage
+		n, cm, raddr, err = c.p6.ReadFrom(b)
+		if cm != nil {
+			dst = cm.Dst
+		}
+	}
+
+	if err == nil && raddr != nil && dst != nil {
+		c.storeLocalIP(raddr.String(), dst)
+	}
+	return n, raddr, err
+}
+
+// WriteTo writes a packet from the local IP address the peer sent to last time, if any.
+func (c *pktInfoConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	src, ok := c.loadLocalIP(addr.String())
+	if !ok {
+		return c.UDPConn.WriteTo(b, addr)
+	}
+
+	if c.p4 != nil {
+		return c.p4.WriteTo(b, &ipv4.ControlMessage{Src: src}, addr)
+	}
+	return c.p6.WriteTo(b, &ipv6.ControlMessage{Src: src}, addr)
+}
+
+func (c *pktInfoConn) storeLocalIP(peer string, ip net.IP) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.localIPs[peer]; ok {
+		e.Value.(*localIPEntry).ip = ip
+		c.lru.MoveToFront(e)
+		return
+	}
+
+	c.localIPs[peer] = c.lru.PushFront(&localIPEntry{peer: peer, ip: ip})
+	if c.lru.Len() > maxPktInfoPeers {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.localIPs, oldest.Value.(*localIPEntry).peer)
+	}
+}
+
+func (c *pktInfoConn) loadLocalIP(peer string) (net.IP, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.localIPs[peer]
+	if !ok {
+		return nil, false
+	}
+	return e.Value.(*localIPEntry).ip, true
+}
@@ -0,0 +1,17 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+//go:build !linux
+// +build !linux
+
+package gtpv2
+
+import (
+	"errors"
+	"net"
+)
+
+func setDontFragment(c *net.UDPConn, isV4 bool) error {
+	return errors.New("DontFragment is not supported on this platform")
+}
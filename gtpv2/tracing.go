@@ -0,0 +1,221 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// SpanKind is the kind of Span.
+type SpanKind uint8
+
+// SpanKind definitions.
+const (
+	// SpanKindClient is for the transaction initiated by Conn, which starts when
+	// the request is sent and ends when the response is received.
+	SpanKindClient SpanKind = iota + 1
+
+	// SpanKindServer is for the incoming message handled by HandlerFunc.
+	SpanKindServer
+)
+
+// SpanInfo is the information about the message that starts a Span, which is
+// typically set as the attributes of the Span.
+type SpanInfo struct {
+	Kind            SpanKind
+	MessageType     uint8
+	MessageTypeName string
+	Sequence        uint32
+	TEID            uint32
+	// IMSI is the IMSI of the Session the message is for, or empty if unknown.
+	IMSI string
+	Peer net.Addr
+}
+
+// Span is a unit of work traced by Tracer.
+type Span interface {
+	// End ends the Span.
+	//
+	// For SpanKindClient, res is the response received, and err is ErrTimeout if
+	// no response is received in time or the error on sending the request.
+	// For SpanKindServer, res is always nil and err is the one returned by HandlerFunc.
+	End(res message.Message, err error)
+}
+
+// Tracer starts the Spans for the GTPv2-C transactions on Conn.
+//
+// This is designed to be implemented easily with the tracing libraries such as
+// OpenTelemetry, without go-gtp depending on them.
+type Tracer interface {
+	// Start starts a new Span as a child of the one in ctx, if any, and returns the
+	// context that contains the new Span.
+	Start(ctx context.Context, info *SpanInfo) (context.Context, Span)
+}
+
+// SetTracer sets the Tracer to trace the messages sent and received on the Conn.
+// Giving nil disables tracing.
+//
+// The SpanKindClient Span for a request that no response comes for is ended with
+// ErrTimeout after timeout.
+//
+// The context that contains the Span for the incoming message can be retrieved with
+// HandlerContext in HandlerFunc, and the one for the outgoing request can be given
+// with SendMessageToContext.
+func (c *Conn) SetTracer(tracer Tracer, timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tracer = tracer
+	c.tracerTimeout = timeout
+}
+
+func (c *Conn) loadTracer() (Tracer, time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.tracer, c.tracerTimeout
+}
+
+// HandlerContext returns the context for the message being handled, which
+// contains the Span started by the Tracer set with SetTracer. This is expected to
+// be called in HandlerFunc to propagate the context to the subsequent work.
+//
+// It returns context.Background() if tracing is not enabled.
+func (c *Conn) HandlerContext(msg message.Message) context.Context {
+	if ctx, ok := c.handlerCtxs.Load(msg); ok {
+		return ctx.(context.Context)
+	}
+	return context.Background()
+}
+
+// isTriggered reports whether the message is a Triggered message, i.e., the one
+// sent in response to an Initial message.
+func isTriggered(msg message.Message) bool {
+	name := msg.MessageTypeName()
+	return strings.HasSuffix(name, "Response") ||
+		strings.HasSuffix(name, "Acknowledge") ||
+		strings.HasSuffix(name, "Failure Indication") ||
+		msg.MessageType() == message.MsgTypeVersionNotSupportedIndication
+}
+
+type txKey struct {
+	peer string
+	seq  uint32
+}
+
+// clientTx is the transaction initiated by Conn, which is being traced.
+type clientTx struct {
+	ctx   context.Context
+	span  Span
+	timer *time.Timer
+	once  sync.Once
+}
+
+func (tx *clientTx) end(res message.Message, err error) {
+	tx.once.Do(func() {
+		if tx.timer != nil {
+			tx.timer.Stop()
+		}
+		tx.span.End(res, err)
+	})
+}
+
+// startClientSpan starts the Span for the request to be sent, and returns a function
+// to end it when the request failed to be sent.
+func (c *Conn) startClientSpan(ctx context.Context, msg message.Message, raddr net.Addr, sess *Session) func(err error) {
+	tracer, timeout := c.loadTracer()
+	if tracer == nil || isTriggered(msg) {
+		return func(error) {}
+	}
+
+	info := &SpanInfo{
+		Kind:            SpanKindClient,
+		MessageType:     msg.MessageType(),
+		MessageTypeName: msg.MessageTypeName(),
+		Sequence:        msg.Sequence(),
+		TEID:            msg.TEID(),
+		IMSI:            imsiOf(msg, sess),
+		Peer:            raddr,
+	}
+	tx := &clientTx{}
+	tx.ctx, tx.span = tracer.Start(ctx, info)
+
+	key := txKey{peer: raddr.String(), seq: msg.Sequence()}
+	c.pendingTxs.Store(key, tx)
+	if timeout > 0 {
+		tx.timer = time.AfterFunc(timeout, func() {
+			c.pendingTxs.Delete(key)
+			tx.end(nil, ErrTimeout)
+		})
+	}
+
+	return func(err error) {
+		c.pendingTxs.Delete(key)
+		tx.end(nil, err)
+	}
+}
+
+// startHandlerSpan ends the Span for the request the incoming message responds to,
+// or starts a new Span for the incoming message. It returns a function to be called
+// when the HandlerFunc returns.
+func (c *Conn) startHandlerSpan(senderAddr net.Addr, msg message.Message) func(err error) {
+	tracer, _ := c.loadTracer()
+	if tracer == nil {
+		return func(error) {}
+	}
+
+	if isTriggered(msg) {
+		key := txKey{peer: senderAddr.String(), seq: msg.Sequence()}
+		if v, ok := c.pendingTxs.Load(key); ok {
+			c.pendingTxs.Delete(key)
+
+			tx := v.(*clientTx)
+			tx.end(msg, nil)
+
+			c.handlerCtxs.Store(msg, tx.ctx)
+			return func(error) { c.handlerCtxs.Delete(msg) }
+		}
+	}
+
+	var sess *Session
+	if teid := msg.TEID(); teid != 0 {
+		sess, _ = c.iteiSessionMap.load(teid)
+	}
+	ctx, span := tracer.Start(context.Background(), &SpanInfo{
+		Kind:            SpanKindServer,
+		MessageType:     msg.MessageType(),
+		MessageTypeName: msg.MessageTypeName(),
+		Sequence:        msg.Sequence(),
+		TEID:            msg.TEID(),
+		IMSI:            imsiOf(msg, sess),
+		Peer:            senderAddr,
+	})
+
+	c.handlerCtxs.Store(msg, ctx)
+	return func(err error) {
+		c.handlerCtxs.Delete(msg)
+		span.End(nil, err)
+	}
+}
+
+// imsiOf returns the IMSI of the Session, or the one in Create Session Request.
+func imsiOf(msg message.Message, sess *Session) string {
+	if sess != nil {
+		return sess.IMSI
+	}
+
+	if csReq, ok := msg.(*message.CreateSessionRequest); ok && csReq.IMSI != nil {
+		if imsi, err := csReq.IMSI.IMSI(); err == nil {
+			return imsi
+		}
+	}
+	return ""
+}
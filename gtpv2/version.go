@@ -0,0 +1,136 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package gtpv2
+
+import (
+	"net"
+
+	v1msg "github.com/wmnsk/go-gtp/gtpv1/message"
+	"github.com/wmnsk/go-gtp/gtpv2/message"
+)
+
+// VersionFallbackFunc is called when a peer responds to a request with GTPv1-C
+// Version Not Supported or GTPv2-C Version Not Supported Indication, which typically
+// means the peer speaks only GTPv1-C.
+//
+// seq is the Sequence Number of the request that is not supported, which can be
+// compared with the one returned from the methods that send the request, to retry
+// the procedure with GTPv1-C toward the peer. As GTPv1-C header cannot carry the
+// Sequence Number of GTPv2-C, the last one used in the requests toward the peer is
+// given when GTPv1-C Version Not Supported is received.
+type VersionFallbackFunc func(c *Conn, peer net.Addr, seq uint32)
+
+// SetVersionFallback sets the function called when a peer responds with Version Not
+// Supported. Giving nil removes it.
+func (c *Conn) SetVersionFallback(fn VersionFallbackFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.versionFallback = fn
+}
+
+// PeerSupportsV2 reports whether the peer supports GTPv2-C, which is false once the
+// peer responds with Version Not Supported.
+func (c *Conn) PeerSupportsV2(peer net.Addr) bool {
+	_, ok := c.v2UnsupportedPeers.Load(peer.String())
+	return !ok
+}
+
+// fallbackVersion marks peer as not supporting GTPv2-C and calls VersionFallbackFunc.
+// It reports whether the function is called.
+func (c *Conn) fallbackVersion(peer net.Addr, seq uint32) bool {
+	c.v2UnsupportedPeers.Store(peer.String(), struct{}{})
+
+	c.mu.Lock()
+	fallback := c.versionFallback
+	c.mu.Unlock()
+	if fallback == nil {
+		return false
+	}
+
+	fallback(c, peer, seq)
+	return true
+}
+
+// checkVersion reports whether the packet is GTPv2, before trying to parse it.
+//
+// GTPv1-C requests are responded with Version Not Supported Indication, and GTPv1-C
+// Version Not Supported triggers the fallback. The other packets are silently
+// discarded, so that the nodes never keep on responding to the responses each other.
+func (c *Conn) checkVersion(raddr net.Addr, raw []byte) bool {
+	if len(raw) < 1 {
+		return false
+	}
+
+	switch raw[0] >> 5 {
+	case 2:
+		return true
+	case 1:
+		// do nothing and go forward.
+	default:
+		return false
+	}
+
+	h, err := v1msg.ParseHeader(raw)
+	if err != nil {
+		return false
+	}
+	switch typ := h.MessageType(); {
+	case typ == v1msg.MsgTypeVersionNotSupported:
+		c.fallbackVersion(raddr, c.PeerSequence(raddr).Request)
+		return false
+	case !isV1Request(typ):
+		return false
+	}
+
+	// Sequence Number is copied from the request.
+	b, err := message.Marshal(message.NewVersionNotSupportedIndication(0, uint32(h.Sequence())))
+	if err != nil {
+		logf("failed to create Version Not Supported Indication: %s", err)
+		return false
+	}
+	if _, err := c.WriteTo(b, raddr); err != nil {
+		logf("failed to send Version Not Supported Indication to %s: %s", raddr, err)
+	}
+	return false
+}
+
+// isV1Request reports whether the GTPv1-C message of typ is a request, which expects
+// a response from the receiver.
+func isV1Request(typ uint8) bool {
+	switch typ {
+	case v1msg.MsgTypeEchoRequest,
+		v1msg.MsgTypeNodeAliveRequest,
+		v1msg.MsgTypeRedirectionRequest,
+		v1msg.MsgTypeCreatePDPContextRequest,
+		v1msg.MsgTypeUpdatePDPContextRequest,
+		v1msg.MsgTypeDeletePDPContextRequest,
+		v1msg.MsgTypeCreateAAPDPContextRequest,
+		v1msg.MsgTypeDeleteAAPDPContextRequest,
+		v1msg.MsgTypePDUNotificationRequest,
+		v1msg.MsgTypePDUNotificationRejectRequest,
+		v1msg.MsgTypeSendRoutingInfoRequest,
+		v1msg.MsgTypeFailureReportRequest,
+		v1msg.MsgTypeNoteMSPresentRequest,
+		v1msg.MsgTypeIdentificationRequest,
+		v1msg.MsgTypeSGSNContextRequest,
+		v1msg.MsgTypeForwardRelocationRequest,
+		v1msg.MsgTypeRelocationCancelRequest,
+		v1msg.MsgTypeUERegistrationQueryRequest,
+		v1msg.MsgTypeMBMSNotificationRequest,
+		v1msg.MsgTypeMBMSNotificationRejectRequest,
+		v1msg.MsgTypeCreateMBMSContextRequest,
+		v1msg.MsgTypeUpdateMBMSContextRequest,
+		v1msg.MsgTypeDeleteMBMSContextRequest,
+		v1msg.MsgTypeMBMSRegistrationRequest,
+		v1msg.MsgTypeMBMSDeRegistrationRequest,
+		v1msg.MsgTypeMBMSSessionStartRequest,
+		v1msg.MsgTypeMBMSSessionStopRequest,
+		v1msg.MsgTypeMBMSSessionUpdateRequest,
+		v1msg.MsgTypeDataRecordTransferRequest:
+		return true
+	}
+	return false
+}
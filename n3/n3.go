@@ -0,0 +1,440 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Package n3 provides the gNB-side and UPF-side endpoints of N3 interface on
+// top of gtpv1.UPlaneConn, for testing the 5G user plane.
+//
+// The tunnels are identified by the incoming TEID and QFI, and the T-PDUs are
+// sent and received with the PDU Session Container extension header defined in
+// TS 38.415; UL PDU SESSION INFORMATION from gNB and DL PDU SESSION INFORMATION
+// from UPF. The T-PDUs received are passed to the handlers registered for the
+// QFI in them.
+package n3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+
+	v1 "github.com/wmnsk/go-gtp/gtpv1"
+	"github.com/wmnsk/go-gtp/gtpv1/message"
+)
+
+// Error definitions.
+var (
+	ErrInvalidQFI            = errors.New("QFI must be within 6 bits")
+	ErrTunnelExists          = errors.New("tunnel already exists")
+	ErrUnknownTunnel         = errors.New("unknown tunnel")
+	ErrNoPDUSessionContainer = errors.New("no PDU Session Container in T-PDU")
+)
+
+// Role is the side of N3 interface an Endpoint works as.
+type Role uint8
+
+// Role definitions.
+const (
+	// RoleGNB sends UL PDU SESSION INFORMATION and receives DL one.
+	RoleGNB Role = iota
+	// RoleUPF sends DL PDU SESSION INFORMATION and receives UL one.
+	RoleUPF
+)
+
+// String returns the name of Role.
+func (r Role) String() string {
+	switch r {
+	case RoleGNB:
+		return "gNB"
+	case RoleUPF:
+		return "UPF"
+	default:
+		return fmt.Sprintf("Role(%d)", uint8(r))
+	}
+}
+
+// Tunnel is a QoS flow of a PDU session on N3.
+type Tunnel struct {
+	// ITEI is the TEID allocated by the Endpoint, which the peer sends the
+	// T-PDUs with.
+	ITEI uint32
+	// OTEI is the TEID allocated by the peer, which the Endpoint sends the
+	// T-PDUs with.
+	OTEI uint32
+	// Peer is the address of the peer, with the port.
+	Peer net.Addr
+	// QFI is the QoS Flow Identifier.
+	QFI uint8
+	// RQI is the Reflective QoS Indicator set in the T-PDUs sent by UPF.
+	// It is ignored on gNB.
+	RQI bool
+}
+
+// Packet is a T-PDU received on a Tunnel.
+type Packet struct {
+	Tunnel  *Tunnel
+	Sender  net.Addr
+	QFI     uint8
+	Payload []byte
+
+	// RQI, PPP and PPI are the ones in DL PDU SESSION INFORMATION, which are
+	// valid only on gNB.
+	RQI bool
+	PPP bool
+	PPI uint8
+}
+
+// HandlerFunc is called with the T-PDU received on a Tunnel.
+//
+// Each T-PDU is handled in its own goroutine, as the other messages on
+// gtpv1.UPlaneConn are.
+type HandlerFunc func(e *Endpoint, pkt *Packet)
+
+// ErrorIndicationFunc is called when an Error Indication is received from the
+// peer, with the Tunnels removed by it.
+type ErrorIndicationFunc func(e *Endpoint, removed []*Tunnel)
+
+// Endpoint is a gNB-side or UPF-side endpoint of N3 interface.
+//
+// It is safe for concurrent use.
+type Endpoint struct {
+	conn *v1.UPlaneConn
+	role Role
+
+	mu             sync.Mutex
+	tunnels        map[uint32]map[uint8]*Tunnel
+	handlers       map[uint8]HandlerFunc
+	defaultHandler HandlerFunc
+	errIndFn       ErrorIndicationFunc
+	echoWaiters    map[string][]chan struct{}
+}
+
+// NewEndpoint creates a new Endpoint working as role on conn.
+//
+// It replaces the handlers of T-PDU and Echo Response on conn, and sets the
+// function called on Error Indication. The caller is responsible for starting
+// conn with ListenAndServe.
+func NewEndpoint(conn *v1.UPlaneConn, role Role) *Endpoint {
+	e := &Endpoint{
+		conn:        conn,
+		role:        role,
+		tunnels:     map[uint32]map[uint8]*Tunnel{},
+		handlers:    map[uint8]HandlerFunc{},
+		echoWaiters: map[string][]chan struct{}{},
+	}
+
+	conn.AddHandler(message.MsgTypeTPDU, e.handleTPDU)
+	conn.AddHandler(message.MsgTypeEchoResponse, e.handleEchoResponse)
+	conn.SetErrorIndicationHandler(e.handleErrorIndication)
+	return e
+}
+
+// Conn returns the gtpv1.UPlaneConn the Endpoint works on.
+func (e *Endpoint) Conn() *v1.UPlaneConn {
+	return e.conn
+}
+
+// Role returns the Role of the Endpoint.
+func (e *Endpoint) Role() Role {
+	return e.role
+}
+
+// AddTunnel adds a copy of the Tunnel given. It returns ErrTunnelExists if the
+// Tunnel with the same ITEI and QFI exists.
+func (e *Endpoint) AddTunnel(t *Tunnel) error {
+	if t.QFI > 0x3f {
+		return ErrInvalidQFI
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	flows, ok := e.tunnels[t.ITEI]
+	if !ok {
+		flows = map[uint8]*Tunnel{}
+		e.tunnels[t.ITEI] = flows
+	}
+	if _, ok := flows[t.QFI]; ok {
+		return fmt.Errorf("%w: ITEI %#x, QFI %d", ErrTunnelExists, t.ITEI, t.QFI)
+	}
+
+	tun := *t
+	flows[t.QFI] = &tun
+	return nil
+}
+
+// RemoveTunnel removes the Tunnel with the ITEI and QFI given.
+func (e *Endpoint) RemoveTunnel(itei uint32, qfi uint8) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.tunnels[itei][qfi]; !ok {
+		return fmt.Errorf("%w: ITEI %#x, QFI %d", ErrUnknownTunnel, itei, qfi)
+	}
+	delete(e.tunnels[itei], qfi)
+	if len(e.tunnels[itei]) == 0 {
+		delete(e.tunnels, itei)
+	}
+	return nil
+}
+
+// RemoveTEID removes all the Tunnels with the ITEI given, i.e., the PDU session,
+// and returns them.
+func (e *Endpoint) RemoveTEID(itei uint32) []*Tunnel {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	removed := make([]*Tunnel, 0, len(e.tunnels[itei]))
+	for _, t := range e.tunnels[itei] {
+		removed = append(removed, t)
+	}
+	delete(e.tunnels, itei)
+
+	sortTunnels(removed)
+	return removed
+}
+
+// Tunnel returns the Tunnel with the ITEI and QFI given.
+func (e *Endpoint) Tunnel(itei uint32, qfi uint8) (*Tunnel, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	t, ok := e.tunnels[itei][qfi]
+	return t, ok
+}
+
+// Tunnels returns all the Tunnels, sorted by ITEI and QFI.
+func (e *Endpoint) Tunnels() []*Tunnel {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var tunnels []*Tunnel
+	for _, flows := range e.tunnels {
+		for _, t := range flows {
+			tunnels = append(tunnels, t)
+		}
+	}
+
+	sortTunnels(tunnels)
+	return tunnels
+}
+
+func sortTunnels(tunnels []*Tunnel) {
+	sort.Slice(tunnels, func(i, j int) bool {
+		if tunnels[i].ITEI != tunnels[j].ITEI {
+			return tunnels[i].ITEI < tunnels[j].ITEI
+		}
+		return tunnels[i].QFI < tunnels[j].QFI
+	})
+}
+
+// Handle registers the function called for the T-PDUs received with the QFI
+// given. Giving nil removes it.
+func (e *Endpoint) Handle(qfi uint8, fn HandlerFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if fn == nil {
+		delete(e.handlers, qfi)
+		return
+	}
+	e.handlers[qfi] = fn
+}
+
+// SetDefaultHandler sets the function called for the T-PDUs received with the
+// QFI that has no handler registered with Handle. Without it, such T-PDUs are
+// discarded.
+func (e *Endpoint) SetDefaultHandler(fn HandlerFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.defaultHandler = fn
+}
+
+// SetErrorIndicationHandler sets the function called when an Error Indication
+// is received. The Tunnels with the OTEI and the peer in it are removed before
+// fn is called, regardless of whether fn is set.
+func (e *Endpoint) SetErrorIndicationHandler(fn ErrorIndicationFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.errIndFn = fn
+}
+
+// Send sends the payload on the Tunnel with the ITEI and QFI given, with the PDU
+// Session Container for the Role of the Endpoint.
+func (e *Endpoint) Send(itei uint32, qfi uint8, payload []byte) error {
+	t, ok := e.Tunnel(itei, qfi)
+	if !ok {
+		return fmt.Errorf("%w: ITEI %#x, QFI %d", ErrUnknownTunnel, itei, qfi)
+	}
+
+	var (
+		ext *message.ExtensionHeader
+		err error
+	)
+	if e.role == RoleUPF {
+		ext, err = message.NewDLPDUSessionInformation(t.QFI, t.RQI).ToExtensionHeader()
+	} else {
+		ext, err = message.NewULPDUSessionInformation(t.QFI).ToExtensionHeader()
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = e.conn.WriteToGTPWithExtensionHeaders(t.OTEI, payload, t.Peer, ext)
+	return err
+}
+
+// Echo sends an Echo Request to peer and waits for the Echo Response until ctx
+// is done.
+func (e *Endpoint) Echo(ctx context.Context, peer net.Addr) error {
+	ch := make(chan struct{})
+	key := peer.String()
+
+	e.mu.Lock()
+	e.echoWaiters[key] = append(e.echoWaiters[key], ch)
+	e.mu.Unlock()
+	defer e.removeEchoWaiter(key, ch)
+
+	if err := e.conn.EchoRequest(peer); err != nil {
+		return err
+	}
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *Endpoint) removeEchoWaiter(key string, ch chan struct{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	waiters := e.echoWaiters[key]
+	for i, w := range waiters {
+		if w == ch {
+			e.echoWaiters[key] = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(e.echoWaiters[key]) == 0 {
+		delete(e.echoWaiters, key)
+	}
+}
+
+func (e *Endpoint) handleEchoResponse(c v1.Conn, senderAddr net.Addr, msg message.Message) error {
+	if _, ok := msg.(*message.EchoResponse); !ok {
+		return v1.ErrUnexpectedType
+	}
+
+	e.mu.Lock()
+	waiters := e.echoWaiters[senderAddr.String()]
+	delete(e.echoWaiters, senderAddr.String())
+	e.mu.Unlock()
+
+	for _, ch := range waiters {
+		close(ch)
+	}
+	return nil
+}
+
+func (e *Endpoint) handleTPDU(c v1.Conn, senderAddr net.Addr, msg message.Message) error {
+	pdu, ok := msg.(*message.TPDU)
+	if !ok {
+		return v1.ErrUnexpectedType
+	}
+
+	pkt, err := e.decapsulate(pdu)
+	if err != nil {
+		return err
+	}
+	pkt.Sender = senderAddr
+
+	e.mu.Lock()
+	flows, known := e.tunnels[pdu.TEID()]
+	t := flows[pkt.QFI]
+	fn, ok := e.handlers[pkt.QFI]
+	if !ok {
+		fn = e.defaultHandler
+	}
+	e.mu.Unlock()
+
+	if !known {
+		return e.conn.ErrorIndication(senderAddr, pdu)
+	}
+	if t == nil {
+		return fmt.Errorf("%w: ITEI %#x, QFI %d", ErrUnknownTunnel, pdu.TEID(), pkt.QFI)
+	}
+	if fn == nil {
+		return nil
+	}
+
+	pkt.Tunnel = t
+	fn(e, pkt)
+	return nil
+}
+
+// decapsulate takes the QFI and the other fields in the PDU Session Container
+// sent by the peer out of the T-PDU.
+func (e *Endpoint) decapsulate(pdu *message.TPDU) (*Packet, error) {
+	for _, ext := range pdu.Header.ExtensionHeaders {
+		if ext.Type != message.ExtHeaderTypePDUSessionContainer {
+			continue
+		}
+
+		if e.role == RoleUPF {
+			ul, err := message.ULPDUSessionInformationFromExtensionHeader(ext)
+			if err != nil {
+				return nil, err
+			}
+			return &Packet{QFI: ul.QFI, Payload: pdu.Payload}, nil
+		}
+
+		dl, err := message.DLPDUSessionInformationFromExtensionHeader(ext)
+		if err != nil {
+			return nil, err
+		}
+		return &Packet{QFI: dl.QFI, RQI: dl.RQI, PPP: dl.PPP, PPI: dl.PPI, Payload: pdu.Payload}, nil
+	}
+	return nil, ErrNoPDUSessionContainer
+}
+
+func (e *Endpoint) handleErrorIndication(u *v1.UPlaneConn, senderAddr net.Addr, ind *v1.ErrorIndicatedError, itei uint32, found bool) {
+	e.mu.Lock()
+	var removed []*Tunnel
+	for teid, flows := range e.tunnels {
+		for qfi, t := range flows {
+			if t.OTEI != ind.TEID || hostOf(t.Peer) != ind.Peer {
+				continue
+			}
+			removed = append(removed, t)
+			delete(flows, qfi)
+		}
+		if len(flows) == 0 {
+			delete(e.tunnels, teid)
+		}
+	}
+	fn := e.errIndFn
+	e.mu.Unlock()
+
+	if fn != nil && len(removed) != 0 {
+		sortTunnels(removed)
+		fn(e, removed)
+	}
+}
+
+func hostOf(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
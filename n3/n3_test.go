@@ -0,0 +1,142 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package n3_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/wmnsk/go-gtp/gtptest"
+	v1 "github.com/wmnsk/go-gtp/gtpv1"
+	"github.com/wmnsk/go-gtp/n3"
+)
+
+func setup(t *testing.T) (gnb, upf *n3.Endpoint) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	network := gtptest.NewNetwork()
+	listen := func(addr string, role n3.Role) *n3.Endpoint {
+		pc, err := network.ListenPacket("udp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn := v1.NewUPlaneConnWithPacketConn(pc)
+		t.Cleanup(func() { conn.Close() })
+
+		e := n3.NewEndpoint(conn, role)
+		go func() {
+			if err := conn.ListenAndServe(ctx); err != nil {
+				t.Log(err)
+			}
+		}()
+		return e
+	}
+	return listen("127.0.0.1:2152", n3.RoleGNB), listen("127.0.0.2:2152", n3.RoleUPF)
+}
+
+func TestEndpoint(t *testing.T) {
+	gnb, upf := setup(t)
+	gnbAddr, upfAddr := gnb.Conn().LocalAddr(), upf.Conn().LocalAddr()
+
+	for _, qfi := range []uint8{1, 9} {
+		if err := gnb.AddTunnel(&n3.Tunnel{ITEI: 0x100, OTEI: 0x200, Peer: upfAddr, QFI: qfi}); err != nil {
+			t.Fatal(err)
+		}
+		if err := upf.AddTunnel(&n3.Tunnel{ITEI: 0x200, OTEI: 0x100, Peer: gnbAddr, QFI: qfi, RQI: qfi == 9}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := gnb.AddTunnel(&n3.Tunnel{ITEI: 0x100, QFI: 1}); !errors.Is(err, n3.ErrTunnelExists) {
+		t.Errorf("want ErrTunnelExists, got %v", err)
+	}
+	if err := gnb.AddTunnel(&n3.Tunnel{ITEI: 0x101, QFI: 64}); !errors.Is(err, n3.ErrInvalidQFI) {
+		t.Errorf("want ErrInvalidQFI, got %v", err)
+	}
+
+	ulCh, dlCh := make(chan *n3.Packet, 1), make(chan *n3.Packet, 1)
+	upf.Handle(9, func(e *n3.Endpoint, pkt *n3.Packet) { ulCh <- pkt })
+	gnb.SetDefaultHandler(func(e *n3.Endpoint, pkt *n3.Packet) { dlCh <- pkt })
+
+	receive := func(ch chan *n3.Packet) *n3.Packet {
+		t.Helper()
+		select {
+		case pkt := <-ch:
+			return pkt
+		case <-time.After(time.Second):
+			t.Fatal("T-PDU not received")
+			return nil
+		}
+	}
+
+	t.Run("Uplink", func(t *testing.T) {
+		if err := gnb.Send(0x100, 9, []byte("uplink")); err != nil {
+			t.Fatal(err)
+		}
+		pkt := receive(ulCh)
+		if pkt.QFI != 9 || pkt.Tunnel.ITEI != 0x200 || string(pkt.Payload) != "uplink" {
+			t.Errorf("wrong packet received on UPF: %+v", pkt)
+		}
+
+		// no handler for QFI 1 on UPF.
+		if err := gnb.Send(0x100, 1, []byte("uplink")); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case pkt := <-ulCh:
+			t.Errorf("T-PDU with QFI 1 passed to handler for QFI 9: %+v", pkt)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("Downlink", func(t *testing.T) {
+		if err := upf.Send(0x200, 9, []byte("downlink")); err != nil {
+			t.Fatal(err)
+		}
+		pkt := receive(dlCh)
+		if pkt.QFI != 9 || !pkt.RQI || pkt.Tunnel.ITEI != 0x100 || string(pkt.Payload) != "downlink" {
+			t.Errorf("wrong packet received on gNB: %+v", pkt)
+		}
+	})
+
+	t.Run("Echo", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := gnb.Echo(ctx, upfAddr); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("ErrorIndication", func(t *testing.T) {
+		removedCh := make(chan []*n3.Tunnel, 1)
+		gnb.SetErrorIndicationHandler(func(e *n3.Endpoint, removed []*n3.Tunnel) {
+			removedCh <- removed
+		})
+
+		upf.RemoveTEID(0x200)
+		if err := gnb.Send(0x100, 1, []byte("uplink")); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case removed := <-removedCh:
+			if len(removed) != 2 {
+				t.Errorf("want 2 tunnels removed, got %d", len(removed))
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Error Indication not received")
+		}
+		if got := gnb.Tunnels(); len(got) != 0 {
+			t.Errorf("want no tunnel on gNB, got %d", len(got))
+		}
+		if err := gnb.Send(0x100, 1, nil); !errors.Is(err, n3.ErrUnknownTunnel) {
+			t.Errorf("want ErrUnknownTunnel, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,29 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package resolver
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound indicates that the name queried does not exist(NXDOMAIN).
+	ErrNotFound = errors.New("name not found")
+
+	// ErrNoCandidate indicates that no node matches the service and protocols given.
+	ErrNoCandidate = errors.New("no candidate found")
+)
+
+// InvalidResponseError indicates that the DNS response is not acceptable.
+type InvalidResponseError struct {
+	Msg   string
+	RCode uint16
+}
+
+// Error returns violating reason with RCODE.
+func (e *InvalidResponseError) Error() string {
+	return fmt.Sprintf("invalid DNS response: %s, RCODE: %d", e.Msg, e.RCode)
+}
@@ -0,0 +1,286 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package resolver
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+)
+
+// DNS parameters used in NAPTR query.
+const (
+	typeNAPTR uint16 = 35
+	classINET uint16 = 1
+
+	headerLen      = 12
+	maxPointerHops = 16
+)
+
+// DNS header flags and codes used in NAPTR query.
+const (
+	flagQR uint16 = 0x8000
+	flagTC uint16 = 0x0200
+	flagRD uint16 = 0x0100
+
+	rcodeMask     uint16 = 0x000f
+	rcodeNXDomain uint16 = 3
+)
+
+var errInvalidName = errors.New("invalid domain name")
+
+// NAPTR is a NAPTR resource record defined in RFC 3403.
+type NAPTR struct {
+	Order       uint16
+	Preference  uint16
+	Flags       string
+	Service     string
+	Regexp      string
+	Replacement string
+}
+
+// AppService returns the Application Service tag in the Service field,
+// e.g., "x-3gpp-pgw" in "x-3gpp-pgw:x-s5-gtp:x-s8-gtp".
+func (n *NAPTR) AppService() string {
+	return strings.SplitN(n.Service, ":", 2)[0]
+}
+
+// AppProtocols returns the Application Protocol tags in the Service field,
+// e.g., ["x-s5-gtp", "x-s8-gtp"] in "x-3gpp-pgw:x-s5-gtp:x-s8-gtp".
+func (n *NAPTR) AppProtocols() []string {
+	s := strings.Split(n.Service, ":")
+	return s[1:]
+}
+
+// Marshal serializes NAPTR into the RDATA of the resource record.
+//
+// Replacement is not compressed as required in RFC 3597.
+func (n *NAPTR) Marshal() ([]byte, error) {
+	b := make([]byte, n.MarshalLen())
+	if err := n.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// MarshalTo serializes NAPTR into the RDATA of the resource record.
+func (n *NAPTR) MarshalTo(b []byte) error {
+	if len(b) < n.MarshalLen() {
+		return io.ErrUnexpectedEOF
+	}
+
+	binary.BigEndian.PutUint16(b[0:2], n.Order)
+	binary.BigEndian.PutUint16(b[2:4], n.Preference)
+	offset := 4
+
+	for _, s := range []string{n.Flags, n.Service, n.Regexp} {
+		if len(s) > 0xff {
+			return errInvalidName
+		}
+		b[offset] = uint8(len(s))
+		copy(b[offset+1:], s)
+		offset += 1 + len(s)
+	}
+
+	name, err := appendName(nil, n.Replacement)
+	if err != nil {
+		return err
+	}
+	copy(b[offset:], name)
+	return nil
+}
+
+// MarshalLen returns the serial length of NAPTR.
+func (n *NAPTR) MarshalLen() int {
+	return 4 + 3 + len(n.Flags) + len(n.Service) + len(n.Regexp) + nameLen(n.Replacement)
+}
+
+// parseNAPTR decodes the RDATA at offset in msg as NAPTR.
+//
+// The whole message is required to decompress Replacement.
+func parseNAPTR(msg []byte, offset, end int) (*NAPTR, error) {
+	if end > len(msg) || offset+4 > end {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	n := &NAPTR{
+		Order:      binary.BigEndian.Uint16(msg[offset : offset+2]),
+		Preference: binary.BigEndian.Uint16(msg[offset+2 : offset+4]),
+	}
+	offset += 4
+
+	for _, s := range []*string{&n.Flags, &n.Service, &n.Regexp} {
+		if offset >= end {
+			return nil, io.ErrUnexpectedEOF
+		}
+		l := int(msg[offset])
+		if offset+1+l > end {
+			return nil, io.ErrUnexpectedEOF
+		}
+		*s = string(msg[offset+1 : offset+1+l])
+		offset += 1 + l
+	}
+
+	var err error
+	n.Replacement, _, err = readName(msg, offset)
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// newQuery creates a DNS query message with a single question.
+func newQuery(id uint16, name string, qtype uint16) ([]byte, error) {
+	b := make([]byte, headerLen, headerLen+nameLen(name)+4)
+	binary.BigEndian.PutUint16(b[0:2], id)
+	binary.BigEndian.PutUint16(b[2:4], flagRD)
+	binary.BigEndian.PutUint16(b[4:6], 1) // QDCOUNT
+
+	b, err := appendName(b, name)
+	if err != nil {
+		return nil, err
+	}
+
+	q := make([]byte, 4)
+	binary.BigEndian.PutUint16(q[0:2], qtype)
+	binary.BigEndian.PutUint16(q[2:4], classINET)
+	return append(b, q...), nil
+}
+
+// parseNAPTRResponse decodes a DNS response message and returns the NAPTR records
+// in the answer section. The records of other types are just ignored.
+//
+// truncated is true if the TC bit is set in the header, in which case the query
+// should be retried over TCP.
+func parseNAPTRResponse(id uint16, msg []byte) (naptrs []*NAPTR, truncated bool, err error) {
+	if len(msg) < headerLen {
+		return nil, false, io.ErrUnexpectedEOF
+	}
+
+	if got := binary.BigEndian.Uint16(msg[0:2]); got != id {
+		return nil, false, &InvalidResponseError{Msg: "ID mismatch"}
+	}
+	flags := binary.BigEndian.Uint16(msg[2:4])
+	if flags&flagQR == 0 {
+		return nil, false, &InvalidResponseError{Msg: "not a response"}
+	}
+	if flags&flagTC != 0 {
+		return nil, true, nil
+	}
+	switch rcode := flags & rcodeMask; rcode {
+	case 0:
+	case rcodeNXDomain:
+		return nil, false, ErrNotFound
+	default:
+		return nil, false, &InvalidResponseError{Msg: "error response", RCode: rcode}
+	}
+
+	qdcount := int(binary.BigEndian.Uint16(msg[4:6]))
+	ancount := int(binary.BigEndian.Uint16(msg[6:8]))
+	offset := headerLen
+
+	for i := 0; i < qdcount; i++ {
+		_, offset, err = readName(msg, offset)
+		if err != nil {
+			return nil, false, err
+		}
+		offset += 4 // QTYPE, QCLASS
+	}
+
+	for i := 0; i < ancount; i++ {
+		_, offset, err = readName(msg, offset)
+		if err != nil {
+			return nil, false, err
+		}
+		if offset+10 > len(msg) {
+			return nil, false, io.ErrUnexpectedEOF
+		}
+		rrtype := binary.BigEndian.Uint16(msg[offset : offset+2])
+		rdlen := int(binary.BigEndian.Uint16(msg[offset+8 : offset+10]))
+		offset += 10
+		if offset+rdlen > len(msg) {
+			return nil, false, io.ErrUnexpectedEOF
+		}
+
+		if rrtype == typeNAPTR {
+			n, err := parseNAPTR(msg, offset, offset+rdlen)
+			if err != nil {
+				return nil, false, err
+			}
+			naptrs = append(naptrs, n)
+		}
+		offset += rdlen
+	}
+	return naptrs, false, nil
+}
+
+// appendName appends the domain name in wire format to b without compression.
+func appendName(b []byte, name string) ([]byte, error) {
+	name = strings.TrimSuffix(name, ".")
+	if name == "" {
+		return append(b, 0), nil
+	}
+
+	for _, label := range strings.Split(name, ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return nil, errInvalidName
+		}
+		b = append(b, uint8(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0), nil
+}
+
+// nameLen returns the length of the domain name in wire format without compression.
+func nameLen(name string) int {
+	name = strings.TrimSuffix(name, ".")
+	if name == "" {
+		return 1
+	}
+	return len(name) + 2
+}
+
+// readName reads the domain name at offset in msg, following the compression
+// pointers if any. It returns the name and the offset next to the name.
+func readName(msg []byte, offset int) (string, int, error) {
+	var labels []string
+	next := -1
+	for hops := 0; ; {
+		if offset >= len(msg) {
+			return "", 0, io.ErrUnexpectedEOF
+		}
+
+		l := int(msg[offset])
+		switch l & 0xc0 {
+		case 0x00:
+			if l == 0 {
+				if next < 0 {
+					next = offset + 1
+				}
+				return strings.Join(labels, "."), next, nil
+			}
+			if offset+1+l > len(msg) {
+				return "", 0, io.ErrUnexpectedEOF
+			}
+			labels = append(labels, string(msg[offset+1:offset+1+l]))
+			offset += 1 + l
+		case 0xc0:
+			if offset+2 > len(msg) {
+				return "", 0, io.ErrUnexpectedEOF
+			}
+			if next < 0 {
+				next = offset + 2
+			}
+			if hops++; hops > maxPointerHops {
+				return "", 0, errInvalidName
+			}
+			offset = int(binary.BigEndian.Uint16(msg[offset:offset+2]) & 0x3fff)
+		default:
+			return "", 0, errInvalidName
+		}
+	}
+}
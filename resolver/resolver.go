@@ -0,0 +1,340 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Package resolver provides the DNS procedures to discover and select the peer nodes
+// in EPC, defined in 3GPP TS 29.303.
+//
+// The candidates are looked up with S-NAPTR(RFC 3958) with the FQDN built from APN
+// or TAI, and filtered by the Application Service and Application Protocols, then
+// resolved to the IP addresses via SRV and A/AAAA.
+package resolver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Application Service tags defined in TS 29.303 Table 19.4.3-1.
+const (
+	AppServicePGW  = "x-3gpp-pgw"
+	AppServiceSGW  = "x-3gpp-sgw"
+	AppServiceGGSN = "x-3gpp-ggsn"
+	AppServiceSGSN = "x-3gpp-sgsn"
+	AppServiceMME  = "x-3gpp-mme"
+	AppServiceMSC  = "x-3gpp-msc"
+)
+
+// Application Protocol tags defined in TS 29.303 Table 19.4.3-1.
+const (
+	AppProtoS2aGTP  = "x-s2a-gtp"
+	AppProtoS2bGTP  = "x-s2b-gtp"
+	AppProtoS5GTP   = "x-s5-gtp"
+	AppProtoS5PMIP  = "x-s5-pmip"
+	AppProtoS8GTP   = "x-s8-gtp"
+	AppProtoS8PMIP  = "x-s8-pmip"
+	AppProtoS3      = "x-s3"
+	AppProtoS4      = "x-s4"
+	AppProtoS10     = "x-s10"
+	AppProtoS11     = "x-s11"
+	AppProtoS12     = "x-s12"
+	AppProtoS16     = "x-s16"
+	AppProtoGn      = "x-gn"
+	AppProtoGp      = "x-gp"
+	AppProtoSv      = "x-sv"
+	AppProtoS1U     = "x-s1-u"
+	AppProtoS1MME   = "x-s1-mme"
+	AppProtoNqPrime = "x-nqprime"
+)
+
+// maxNAPTRDepth is the maximum number of non-terminal NAPTR records to follow.
+const maxNAPTRDepth = 8
+
+// APNFQDN returns the APN-FQDN defined in TS 29.303 4.3.2, e.g.,
+// "internet.apn.epc.mnc001.mcc001.3gppnetwork.org".
+//
+// apn is the APN Network Identifier. mnc is zero-padded to three digits.
+func APNFQDN(apn, mcc, mnc string) string {
+	return fmt.Sprintf("%s.apn.epc.mnc%s.mcc%s.3gppnetwork.org", apn, padMNC(mnc), mcc)
+}
+
+// TAIFQDN returns the TAI-FQDN defined in TS 29.303 19.4.2.3, e.g.,
+// "tac-lb34.tac-hb12.tac.epc.mnc001.mcc001.3gppnetwork.org" for TAC 0x1234.
+//
+// mnc is zero-padded to three digits.
+func TAIFQDN(tac uint16, mcc, mnc string) string {
+	return fmt.Sprintf(
+		"tac-lb%02x.tac-hb%02x.tac.epc.mnc%s.mcc%s.3gppnetwork.org",
+		tac&0xff, tac>>8, padMNC(mnc), mcc,
+	)
+}
+
+// padMNC pads the two-digit MNC with zero to be used in FQDN.
+func padMNC(mnc string) string {
+	if len(mnc) < 3 {
+		return strings.Repeat("0", 3-len(mnc)) + mnc
+	}
+	return mnc
+}
+
+// Candidate is a node selected by Resolver.
+//
+// Candidates are returned in the order to be tried; by Order and Preference of
+// NAPTR first, and then by Priority and Weight of SRV if resolved via SRV.
+type Candidate struct {
+	// Host is the hostname of the node, which is the Replacement of NAPTR or the
+	// Target of SRV.
+	Host string
+
+	// IPs are the addresses of the node resolved with A/AAAA.
+	IPs []net.IP
+
+	// Port is the port number given by SRV. It is zero if resolved without SRV.
+	Port uint16
+
+	// Order, Preference and Service are the ones of NAPTR the node comes from.
+	Order, Preference uint16
+	Service           string
+}
+
+// Resolver performs the DNS procedures to select the peer nodes.
+type Resolver struct {
+	server string
+
+	// Timeout is the time to wait for each DNS query. Default is 3 seconds.
+	Timeout time.Duration
+
+	resolver *net.Resolver
+}
+
+// New creates a new Resolver that sends queries to server, which should be in
+// "host:port" format.
+func New(server string) *Resolver {
+	r := &Resolver{
+		server:  server,
+		Timeout: 3 * time.Second,
+	}
+
+	// SRV and A/AAAA are resolved with the pure Go resolver in the standard library,
+	// forcing it to send queries to the same server.
+	r.resolver = &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			d := &net.Dialer{Timeout: r.Timeout}
+			return d.DialContext(ctx, network, r.server)
+		},
+	}
+	return r
+}
+
+// LookupNAPTR returns the NAPTR records of the name given, sorted by Order and
+// Preference.
+func (r *Resolver) LookupNAPTR(ctx context.Context, name string) ([]*NAPTR, error) {
+	id, err := generateID()
+	if err != nil {
+		return nil, err
+	}
+	query, err := newQuery(id, name, typeNAPTR)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.exchange(ctx, "udp", query)
+	if err != nil {
+		return nil, err
+	}
+	naptrs, truncated, err := parseNAPTRResponse(id, res)
+	if err != nil {
+		return nil, err
+	}
+
+	if truncated {
+		res, err = r.exchange(ctx, "tcp", query)
+		if err != nil {
+			return nil, err
+		}
+		naptrs, _, err = parseNAPTRResponse(id, res)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(naptrs, func(i, j int) bool {
+		if naptrs[i].Order != naptrs[j].Order {
+			return naptrs[i].Order < naptrs[j].Order
+		}
+		return naptrs[i].Preference < naptrs[j].Preference
+	})
+	return naptrs, nil
+}
+
+// Lookup performs S-NAPTR procedure with the FQDN given(typically the one built with
+// APNFQDN or TAIFQDN), and returns the Candidates that provides the Application
+// Service with any of the Application Protocols given.
+//
+// If no protocol is given, the Candidates are filtered only by the service.
+// The candidates whose address cannot be resolved are excluded from the result,
+// and ErrNoCandidate is returned if nothing is left.
+func (r *Resolver) Lookup(ctx context.Context, fqdn, service string, protocols ...string) ([]*Candidate, error) {
+	cs, err := r.lookup(ctx, fqdn, service, protocols, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(cs) == 0 {
+		return nil, ErrNoCandidate
+	}
+	return cs, nil
+}
+
+func (r *Resolver) lookup(ctx context.Context, fqdn, service string, protocols []string, depth int) ([]*Candidate, error) {
+	naptrs, err := r.LookupNAPTR(ctx, fqdn)
+	if err != nil {
+		return nil, err
+	}
+
+	var cs []*Candidate
+	for _, n := range naptrs {
+		switch strings.ToLower(n.Flags) {
+		case "a":
+			if !matchService(n, service, protocols) {
+				continue
+			}
+			ips, err := r.lookupIP(ctx, n.Replacement)
+			if err != nil {
+				continue
+			}
+			cs = append(cs, &Candidate{
+				Host: n.Replacement, IPs: ips,
+				Order: n.Order, Preference: n.Preference, Service: n.Service,
+			})
+		case "s":
+			if !matchService(n, service, protocols) {
+				continue
+			}
+			_, srvs, err := r.resolver.LookupSRV(ctx, "", "", n.Replacement)
+			if err != nil {
+				continue
+			}
+			for _, srv := range srvs {
+				ips, err := r.lookupIP(ctx, srv.Target)
+				if err != nil {
+					continue
+				}
+				cs = append(cs, &Candidate{
+					Host: strings.TrimSuffix(srv.Target, "."), IPs: ips, Port: srv.Port,
+					Order: n.Order, Preference: n.Preference, Service: n.Service,
+				})
+			}
+		case "":
+			// non-terminal NAPTR; the Service field may be empty, so it is checked
+			// only when present.
+			if n.Service != "" && !matchService(n, service, protocols) {
+				continue
+			}
+			if depth >= maxNAPTRDepth {
+				continue
+			}
+			sub, err := r.lookup(ctx, n.Replacement, service, protocols, depth+1)
+			if err != nil {
+				continue
+			}
+			cs = append(cs, sub...)
+		}
+	}
+	return cs, nil
+}
+
+func (r *Resolver) lookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	addrs, err := r.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	ips := make([]net.IP, len(addrs))
+	for i, addr := range addrs {
+		ips[i] = addr.IP
+	}
+	return ips, nil
+}
+
+// exchange sends a query to the server and returns the response.
+func (r *Resolver) exchange(ctx context.Context, network string, query []byte) ([]byte, error) {
+	d := &net.Dialer{Timeout: r.Timeout}
+	conn, err := d.DialContext(ctx, network, r.server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(r.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if network == "udp" {
+		if _, err := conn.Write(query); err != nil {
+			return nil, err
+		}
+		buf := make([]byte, 0xffff)
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	}
+
+	// over TCP, messages are prefixed with two-byte length field.
+	b := make([]byte, 2+len(query))
+	binary.BigEndian.PutUint16(b[0:2], uint16(len(query)))
+	copy(b[2:], query)
+	if _, err := conn.Write(b); err != nil {
+		return nil, err
+	}
+
+	l := make([]byte, 2)
+	if _, err := io.ReadFull(conn, l); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, binary.BigEndian.Uint16(l))
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// matchService reports whether the NAPTR provides the service with any of the protocols.
+func matchService(n *NAPTR, service string, protocols []string) bool {
+	if !strings.EqualFold(n.AppService(), service) {
+		return false
+	}
+	if len(protocols) == 0 {
+		return true
+	}
+
+	for _, got := range n.AppProtocols() {
+		for _, want := range protocols {
+			if strings.EqualFold(got, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func generateID() (uint16, error) {
+	b := make([]byte, 2)
+	if _, err := rand.Read(b); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(b), nil
+}
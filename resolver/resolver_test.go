@@ -0,0 +1,185 @@
+// Copyright 2019-2020 go-gtp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package resolver_test
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/wmnsk/go-gtp/resolver"
+)
+
+const (
+	typeA     uint16 = 1
+	typeSRV   uint16 = 33
+	typeNAPTR uint16 = 35
+)
+
+type record struct {
+	rrtype uint16
+	rdata  []byte
+}
+
+func TestFQDN(t *testing.T) {
+	if got, want := resolver.APNFQDN("internet", "001", "01"), "internet.apn.epc.mnc001.mcc001.3gppnetwork.org"; got != want {
+		t.Errorf("wrong APN-FQDN. want: %s, got: %s", want, got)
+	}
+	if got, want := resolver.TAIFQDN(0x1234, "001", "001"), "tac-lb34.tac-hb12.tac.epc.mnc001.mcc001.3gppnetwork.org"; got != want {
+		t.Errorf("wrong TAI-FQDN. want: %s, got: %s", want, got)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	apnFQDN := resolver.APNFQDN("internet", "001", "01")
+	records := map[string][]record{
+		apnFQDN: {
+			naptrRecord(t, 20, 10, "a", "x-3gpp-pgw:x-s5-gtp:x-s8-gtp", "topoff.pgw2.example.org"),
+			naptrRecord(t, 10, 10, "s", "x-3gpp-pgw:x-s5-gtp", "_nodes.pgw.example.org"),
+			naptrRecord(t, 10, 20, "a", "x-3gpp-pgw:x-s5-pmip", "topoff.pgw3.example.org"),
+			naptrRecord(t, 10, 10, "a", "x-3gpp-sgw:x-s5-gtp", "topoff.sgw1.example.org"),
+		},
+		"_nodes.pgw.example.org": {
+			srvRecord(10, 0, 2123, "topoff.pgw1.example.org"),
+		},
+		"topoff.pgw1.example.org": {{typeA, []byte{127, 0, 0, 21}}},
+		"topoff.pgw2.example.org": {{typeA, []byte{127, 0, 0, 22}}},
+		"topoff.pgw3.example.org": {{typeA, []byte{127, 0, 0, 23}}},
+		"topoff.sgw1.example.org": {{typeA, []byte{127, 0, 0, 24}}},
+	}
+
+	srvAddr := serveDNS(t, records)
+	r := resolver.New(srvAddr)
+
+	cs, err := r.Lookup(context.Background(), apnFQDN, resolver.AppServicePGW, resolver.AppProtoS5GTP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, c := range cs {
+		got = append(got, c.Host+"/"+c.IPs[0].String())
+	}
+	want := []string{"topoff.pgw1.example.org/127.0.0.21", "topoff.pgw2.example.org/127.0.0.22"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error(diff)
+	}
+	if cs[0].Port != 2123 {
+		t.Errorf("wrong port. want: %d, got: %d", 2123, cs[0].Port)
+	}
+
+	if _, err := r.Lookup(context.Background(), apnFQDN, resolver.AppServiceMME); err != resolver.ErrNoCandidate {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// serveDNS starts a DNS server that responds with the records given, and returns
+// its address. The server is closed when the test finishes.
+func serveDNS(t *testing.T, records map[string][]record) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, raddr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if res := respond(buf[:n], records); res != nil {
+				if _, err := conn.WriteTo(res, raddr); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+// respond creates a response to the query. The name in the question is expected
+// not to be compressed.
+func respond(query []byte, records map[string][]record) []byte {
+	if len(query) < 12 {
+		return nil
+	}
+
+	var labels []string
+	offset := 12
+	for offset < len(query) && query[offset] != 0 {
+		l := int(query[offset])
+		labels = append(labels, string(query[offset+1:offset+1+l]))
+		offset += 1 + l
+	}
+	qend := offset + 5
+	if qend > len(query) {
+		return nil
+	}
+	qtype := binary.BigEndian.Uint16(query[offset+1 : offset+3])
+	name := strings.ToLower(strings.Join(labels, "."))
+
+	var answers []record
+	for _, rr := range records[name] {
+		if rr.rrtype == qtype {
+			answers = append(answers, rr)
+		}
+	}
+
+	b := make([]byte, 12)
+	copy(b[0:2], query[0:2])
+	binary.BigEndian.PutUint16(b[2:4], 0x8180) // QR, RD, RA
+	binary.BigEndian.PutUint16(b[4:6], 1)
+	binary.BigEndian.PutUint16(b[6:8], uint16(len(answers)))
+	b = append(b, query[12:qend]...)
+
+	for _, rr := range answers {
+		h := make([]byte, 12)
+		binary.BigEndian.PutUint16(h[0:2], 0xc00c) // pointer to the name in question
+		binary.BigEndian.PutUint16(h[2:4], rr.rrtype)
+		binary.BigEndian.PutUint16(h[4:6], 1)
+		binary.BigEndian.PutUint32(h[6:10], 60)
+		binary.BigEndian.PutUint16(h[10:12], uint16(len(rr.rdata)))
+		b = append(b, h...)
+		b = append(b, rr.rdata...)
+	}
+	return b
+}
+
+func naptrRecord(t *testing.T, order, pref uint16, flags, service, replacement string) record {
+	t.Helper()
+
+	n := &resolver.NAPTR{
+		Order:       order,
+		Preference:  pref,
+		Flags:       flags,
+		Service:     service,
+		Replacement: replacement,
+	}
+	b, err := n.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return record{typeNAPTR, b}
+}
+
+func srvRecord(priority, weight, port uint16, target string) record {
+	b := make([]byte, 6)
+	binary.BigEndian.PutUint16(b[0:2], priority)
+	binary.BigEndian.PutUint16(b[2:4], weight)
+	binary.BigEndian.PutUint16(b[4:6], port)
+	for _, label := range strings.Split(target, ".") {
+		b = append(b, uint8(len(label)))
+		b = append(b, label...)
+	}
+	return record{typeSRV, append(b, 0)}
+}
@@ -8,6 +8,14 @@ package utils
 import (
 	"encoding/binary"
 	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// Error definitions.
+var (
+	ErrInvalidTBCD = errors.New("invalid TBCD string")
+	ErrInvalidPLMN = errors.New("invalid PLMN")
 )
 
 // StrToSwappedBytes returns swapped bits from a byte.
@@ -16,18 +24,17 @@ import (
 // The second parameter is the hex character(0-f) to fill the last digit when
 // handling a odd number. "f" is used In most cases.
 func StrToSwappedBytes(s, filler string) ([]byte, error) {
-	var raw []byte
-	var err error
-	if len(s)%2 == 0 {
-		raw, err = hex.DecodeString(s)
-	} else {
-		raw, err = hex.DecodeString(s + filler)
+	if len(s)%2 != 0 {
+		s += filler
 	}
-	if err != nil {
+
+	raw := make([]byte, hex.DecodedLen(len(s)))
+	if _, err := hex.Decode(raw, []byte(s)); err != nil {
 		return nil, err
 	}
 
-	return swap(raw), nil
+	swapInPlace(raw)
+	return raw, nil
 }
 
 // SwappedBytesToStr decodes raw swapped bytes into string.
@@ -47,15 +54,96 @@ func SwappedBytesToStr(raw []byte, cutLastDigit bool) string {
 	return s
 }
 
-func swap(raw []byte) []byte {
-	var swapped []byte
-	for n := range raw {
-		t := ((raw[n] >> 4) & 0xf) + ((raw[n] << 4) & 0xf0)
-		swapped = append(swapped, t)
+// tbcdDigits is the digits represented by each nibble in TBCD string.
+// 0xf is the filler and not included here.
+const tbcdDigits = "0123456789*#abc"
+
+// EncodeTBCD encodes the digits as TBCD(Telephony Binary Coded Decimal)
+// string defined in TS 29.002, which is used for IMSI, MSISDN, IMEI and so on.
+//
+// Two digits are packed in an octet with the first one in the lower nibble,
+// and the filler 0xf is put in the upper nibble of the last octet when the
+// number of digits is odd. The digits can contain "*", "#", "a", "b" and "c"
+// in addition to 0-9.
+func EncodeTBCD(digits string) ([]byte, error) {
+	b := make([]byte, (len(digits)+1)/2)
+	for n := 0; n < len(digits); n++ {
+		v := tbcdValue(digits[n])
+		if v < 0 {
+			return nil, fmt.Errorf("%w: %q at %d", ErrInvalidTBCD, digits[n], n)
+		}
+		if n%2 == 0 {
+			b[n/2] = byte(v)
+		} else {
+			b[n/2] |= byte(v) << 4
+		}
+	}
+	if len(digits)%2 != 0 {
+		b[len(b)-1] |= 0xf0
+	}
+	return b, nil
+}
+
+// DecodeTBCD decodes TBCD string into digits.
+//
+// The digits end at the first filler 0xf, and only the fillers can follow it,
+// so the odd number of digits are decoded without knowing the length.
+func DecodeTBCD(b []byte) (string, error) {
+	digits := make([]byte, 0, len(b)*2)
+	for n := 0; n < len(b)*2; n++ {
+		v := b[n/2] & 0x0f
+		if n%2 != 0 {
+			v = b[n/2] >> 4
+		}
+
+		if v == 0x0f {
+			for m := n + 1; m < len(b)*2; m++ {
+				if (m%2 == 0 && b[m/2]&0x0f != 0x0f) || (m%2 != 0 && b[m/2]>>4 != 0x0f) {
+					return "", fmt.Errorf("%w: digit after filler at %d", ErrInvalidTBCD, m)
+				}
+			}
+			break
+		}
+		digits = append(digits, tbcdDigits[v])
 	}
+	return string(digits), nil
+}
+
+func tbcdValue(c byte) int {
+	for n := 0; n < len(tbcdDigits); n++ {
+		if tbcdDigits[n] == c {
+			return n
+		}
+	}
+	return -1
+}
+
+func swap(raw []byte) []byte {
+	swapped := make([]byte, len(raw))
+	copy(swapped, raw)
+	swapInPlace(swapped)
 	return swapped
 }
 
+func swapInPlace(b []byte) {
+	for n := range b {
+		b[n] = ((b[n] >> 4) & 0xf) + ((b[n] << 4) & 0xf0)
+	}
+}
+
+// Grow extends b by n bytes and returns the extended slice. A new buffer is
+// allocated only when the capacity of b is not enough, so that the buffers
+// can be reused without allocating every time.
+func Grow(b []byte, n int) []byte {
+	l := len(b)
+	if cap(b)-l < n {
+		nb := make([]byte, l, l+n)
+		copy(nb, b)
+		b = nb
+	}
+	return b[:l+n]
+}
+
 // Uint24To32 converts 24bits-length []byte value into the uint32 with 8bits of zeros as prefix.
 // This function is used for the fields with 3 octets.
 func Uint24To32(b []byte) uint32 {
@@ -87,7 +175,17 @@ func Uint64To40(n uint64) []byte {
 }
 
 // EncodePLMN encodes MCC and MNC as BCD-encoded bytes.
+//
+// The MNC can be either 2 or 3 digits, and the filler 0xf is put in place of
+// the third digit of 2-digit MNC so that they can be distinguished in decoding.
 func EncodePLMN(mcc, mnc string) ([]byte, error) {
+	if len(mcc) != 3 || !isDecimal(mcc) {
+		return nil, fmt.Errorf("%w: MCC must be 3 digits: %q", ErrInvalidPLMN, mcc)
+	}
+	if (len(mnc) != 2 && len(mnc) != 3) || !isDecimal(mnc) {
+		return nil, fmt.Errorf("%w: MNC must be 2 or 3 digits: %q", ErrInvalidPLMN, mnc)
+	}
+
 	c, err := StrToSwappedBytes(mcc, "f")
 	if err != nil {
 		return nil, err
@@ -100,7 +198,8 @@ func EncodePLMN(mcc, mnc string) ([]byte, error) {
 	// 2-digit
 	b := make([]byte, 3)
 	if len(mnc) == 2 {
-		b = append(c, n...)
+		copy(b, c)
+		copy(b[len(c):], n)
 		return b, nil
 	}
 
@@ -113,17 +212,36 @@ func EncodePLMN(mcc, mnc string) ([]byte, error) {
 }
 
 // DecodePLMN decodes BCD-encoded bytes into MCC and MNC.
+//
+// The MNC is decoded as 2 digits if the third digit is the filler 0xf, and
+// 3 digits otherwise.
 func DecodePLMN(b []byte) (mcc, mnc string, err error) {
-	raw := hex.EncodeToString(b)
+	if len(b) < 3 {
+		return "", "", fmt.Errorf("%w: too short: %d bytes", ErrInvalidPLMN, len(b))
+	}
+
+	raw := hex.EncodeToString(b[:3])
 	mcc = string(raw[1]) + string(raw[0]) + string(raw[3])
 	mnc = string(raw[5]) + string(raw[4])
 	if string(raw[2]) != "f" {
 		mnc += string(raw[2])
 	}
 
+	if !isDecimal(mcc) || !isDecimal(mnc) {
+		return "", "", fmt.Errorf("%w: non-decimal digits in %x", ErrInvalidPLMN, b[:3])
+	}
 	return
 }
 
+func isDecimal(s string) bool {
+	for n := 0; n < len(s); n++ {
+		if s[n] < '0' || s[n] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 // ParseECI decodes ECI uint32 into e-NodeB ID and Cell ID.
 func ParseECI(eci uint32) (enbID uint32, cellID uint8, err error) {
 	buf := make([]byte, 4)
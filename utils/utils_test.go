@@ -5,6 +5,7 @@
 package utils_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -154,3 +155,108 @@ func TestPLMN(t *testing.T) {
 		})
 	}
 }
+
+func TestPLMNInvalid(t *testing.T) {
+	for _, c := range []struct{ mcc, mnc string }{
+		{"12", "45"}, {"1234", "45"}, {"123", "4"}, {"123", "4567"}, {"12a", "45"}, {"123", "4b"},
+	} {
+		if _, err := utils.EncodePLMN(c.mcc, c.mnc); !errors.Is(err, utils.ErrInvalidPLMN) {
+			t.Errorf("EncodePLMN(%q, %q): got %v, want ErrInvalidPLMN", c.mcc, c.mnc, err)
+		}
+	}
+
+	for _, b := range [][]byte{{0x21, 0xf3}, {0xa1, 0xf3, 0x54}, {0x21, 0x63, 0xf4}} {
+		if _, _, err := utils.DecodePLMN(b); !errors.Is(err, utils.ErrInvalidPLMN) {
+			t.Errorf("DecodePLMN(%x): got %v, want ErrInvalidPLMN", b, err)
+		}
+	}
+}
+
+func TestTBCD(t *testing.T) {
+	cases := []struct {
+		description string
+		digits      string
+		encoded     []byte
+	}{
+		{
+			"Odd",
+			"123451234567890",
+			[]byte{0x21, 0x43, 0x15, 0x32, 0x54, 0x76, 0x98, 0xf0},
+		}, {
+			"Even",
+			"12345123456789",
+			[]byte{0x21, 0x43, 0x15, 0x32, 0x54, 0x76, 0x98},
+		}, {
+			"Symbols",
+			"*#abc",
+			[]byte{0xba, 0xdc, 0xfe},
+		}, {
+			"Empty",
+			"",
+			[]byte{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run("Encode/"+c.description, func(t *testing.T) {
+			encoded, err := utils.EncodeTBCD(c.digits)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(encoded, c.encoded); diff != "" {
+				t.Error(diff)
+			}
+		})
+
+		t.Run("Decode/"+c.description, func(t *testing.T) {
+			digits, err := utils.DecodeTBCD(c.encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(digits, c.digits); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run("Decode/Padded", func(t *testing.T) {
+		digits, err := utils.DecodeTBCD([]byte{0x21, 0xf3, 0xff})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if digits != "123" {
+			t.Errorf("got %q, want %q", digits, "123")
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		if _, err := utils.EncodeTBCD("12x"); !errors.Is(err, utils.ErrInvalidTBCD) {
+			t.Errorf("got %v, want ErrInvalidTBCD", err)
+		}
+		if _, err := utils.DecodeTBCD([]byte{0x21, 0x3f}); !errors.Is(err, utils.ErrInvalidTBCD) {
+			t.Errorf("got %v, want ErrInvalidTBCD", err)
+		}
+	})
+}
+
+func TestGrow(t *testing.T) {
+	t.Run("Reuse", func(t *testing.T) {
+		buf := make([]byte, 2, 8)
+		b := utils.Grow(buf, 4)
+		if len(b) != 6 {
+			t.Errorf("wrong length: got %d, want %d", len(b), 6)
+		}
+		if &b[0] != &buf[0] {
+			t.Error("buffer is reallocated while its capacity is enough")
+		}
+	})
+
+	t.Run("Realloc", func(t *testing.T) {
+		b := utils.Grow([]byte{0xde, 0xad}, 4)
+		if diff := cmp.Diff(b, []byte{0xde, 0xad, 0x00, 0x00, 0x00, 0x00}); diff != "" {
+			t.Error(diff)
+		}
+	})
+}